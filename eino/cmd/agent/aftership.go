@@ -28,10 +28,12 @@ func newAfterShipChatModel(ctx context.Context, cfg Config) (model.ToolCallingCh
 	return chatModel, nil
 }
 
+const claudeModel = "anthropic.claude-3-5-sonnet-20241022-v2:0"
+
 func createClaudeChatModel(ctx context.Context) model.ToolCallingChatModel {
 	cfg := Config{
 		AIGCHubBaseURL: "http://data-aigc.as-in.io/v1/stub/vendors/AWS",
-		LLMModel:       "anthropic.claude-3-5-sonnet-20241022-v2:0",
+		LLMModel:       claudeModel,
 		APIKey:         os.Getenv("AM_API_KEY"),
 	}
 
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"eino/pkg/langfuse/api/resources/prompts/types"
+	"eino/pkg/langfuse/client"
 	"encoding/json"
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/cloudwego/eino-ext/devops"
@@ -17,7 +18,10 @@ func main() {
 	ctx := context.Background()
 
 	log.Printf("===setup agent tracing===\n")
-	setupTracing()
+	lf := setupTracing()
+	if lf != nil {
+		defer lf.Shutdown(ctx)
+	}
 
 	log.Printf("===setup debug console===\n")
 	_ = devops.Init(ctx)
@@ -29,7 +33,7 @@ func main() {
 
 	// 创建 LLM
 	log.Printf("===create llm===\n")
-	cm := createClaudeChatModel(ctx)
+	cm := traceChatModel(createClaudeChatModel(ctx), claudeModel)
 	log.Printf("create llm success\n\n")
 
 	// 绑定 Tools
@@ -44,7 +48,7 @@ func main() {
 	config.ToolCallingModel = cm
 	// 对于 Claude 模型，Eino 需要我们自己实现一个 tool checker，否则无法触发 MCP 调用
 	config.StreamToolCallChecker = claudeStreamToolChecker
-	config.ToolsConfig.Tools = tools
+	config.ToolsConfig.Tools = traceTools(tools)
 	agent, newAgentErr := react.NewAgent(ctx, config)
 	if newAgentErr != nil {
 		panic(newAgentErr)
@@ -53,11 +57,11 @@ func main() {
 	createGinServer(func(ctx context.Context, request *Request) (*schema.StreamReader[*schema.Message], error) {
 		log.Printf("===llm stream generate===\n")
 		log.Printf("request messages: %+v\n", request.Messages)
-		return startAgentFlow(agent, ctx, request, mainSystemMessage)
+		return startAgentFlow(agent, ctx, request, mainSystemMessage, lf)
 	})
 }
 
-func startAgentFlow(cm *react.Agent, ctx context.Context, request *Request, systems []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+func startAgentFlow(cm *react.Agent, ctx context.Context, request *Request, systems []*schema.Message, lf *client.Langfuse) (*schema.StreamReader[*schema.Message], error) {
 	// 这里可以添加更多的业务逻辑
 	log.Printf("Starting agent flow with chat model: %T\n", cm)
 
@@ -72,9 +76,10 @@ func startAgentFlow(cm *react.Agent, ctx context.Context, request *Request, syst
 
 	// 将 responseStream 存储到 context 中
 	ctx = context.WithValue(ctx, "responseStreamWriter", streamWriter)
+	ctx, run := startTracedRun(ctx, lf, allMessages)
 
 	// cm.Stream 的 streamReader 会自动读取并写入到 responseStream
-	go forwardStream(ctx, cm, allMessages, streamWriter)
+	go forwardStream(ctx, cm, allMessages, streamWriter, run)
 
 	return responseStream, nil
 }
@@ -131,17 +136,22 @@ func getSystemMessage(prompt *types.Prompt) []*schema.Message {
 }
 
 // forwardStream 优雅地将源流转发到目标流写入器
-func forwardStream(ctx context.Context, agent *react.Agent, messages []*schema.Message, writer *schema.StreamWriter[*schema.Message]) {
+func forwardStream(ctx context.Context, agent *react.Agent, messages []*schema.Message, writer *schema.StreamWriter[*schema.Message], run *tracedRun) {
 	defer writer.Close()
 
 	//正常来说，我们应该读取这里的 streamReader, 给到用户；
 	//之所以我没这样做，是因为 Claude 模型在触发 tool 调用的时候，tool request 在最后一个 chunk 才返回；
 	// react.go 这里没有很好地适配 claude 模型的这种特性，从而导致 claude 模型下 stream 打字机效果被破坏。
 	// 正式解法，可能需要重写 react.go，这里 demo 我简单在 #claudeStreamToolChecker 那边处理消息返回,从而解决这个问题。
-	_, err := agent.Stream(ctx, messages)
+	sr, err := agent.Stream(ctx, messages)
 	if err != nil {
 		log.Printf("Error creating stream: %v", err)
 		writer.Send(nil, err) // 将错误传播到 responseStream
+		run.finish(ctx, nil, err)
 		return
 	}
+
+	// The final answer only ends the run's trace; it already reached the client through the checker
+	answer, err := schema.ConcatMessageStream(sr)
+	run.finish(ctx, answer, err)
 }
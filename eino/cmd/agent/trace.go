@@ -1,27 +1,210 @@
 package main
 
 import (
-	"github.com/cloudwego/eino-ext/callbacks/langfuse"
-	"github.com/cloudwego/eino/callbacks"
+	"context"
+	"encoding/json"
+	"log"
 	"os"
+	"sync"
+
+	"eino/pkg/langfuse/agenttrace"
+	"eino/pkg/langfuse/client"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
 )
 
-func setupTracing() {
+const agentName = "eino-demo"
+
+// setupTracing creates the Langfuse client agent runs are traced with, or returns nil
+// when no credentials are set
+func setupTracing() *client.Langfuse {
 	publicKey := os.Getenv("LANGFUSE_PUBLIC_KEY")
 	secretKey := os.Getenv("LANGFUSE_SECRET_KEY")
 
 	if publicKey == "" || secretKey == "" {
+		return nil
+	}
+
+	lf, err := client.NewWithOptions(
+		client.WithHost("http://127.0.0.1:3000"),
+		client.WithCredentials(publicKey, secretKey),
+		client.WithRelease("v1.0.0"),
+	)
+	if err != nil {
+		log.Printf("Tracing disabled: %v", err)
+		return nil
+	}
+	return lf
+}
+
+// tracedRun is the agent run of one request. The react agent alternates model calls and
+// tool calls, so every model call starts a new step and tools run in the latest one.
+type tracedRun struct {
+	run *agenttrace.Run
+
+	mu    sync.Mutex
+	step  *agenttrace.Step
+	steps int
+
+	// streaming counts the generations still reading their stream
+	streaming sync.WaitGroup
+}
+
+type tracedRunKey struct{}
+
+// startTracedRun starts the run of a request, returning ctx unchanged without tracing
+func startTracedRun(ctx context.Context, lf *client.Langfuse, input []*schema.Message) (context.Context, *tracedRun) {
+	if lf == nil {
+		return ctx, nil
+	}
+	run := agenttrace.AgentRun(ctx, lf, agentName)
+	run.Trace().Public(true).Input(input)
+	r := &tracedRun{run: run}
+	return context.WithValue(ctx, tracedRunKey{}, r), r
+}
+
+func tracedRunFrom(ctx context.Context) *tracedRun {
+	r, _ := ctx.Value(tracedRunKey{}).(*tracedRun)
+	return r
+}
+
+// nextStep ends the current step and starts the next one
+func (r *tracedRun) nextStep(ctx context.Context) *agenttrace.Step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.step != nil {
+		r.step.End(ctx)
+	}
+	r.steps++
+	r.step = r.run.Step(r.steps)
+	return r.step
+}
+
+func (r *tracedRun) currentStep(ctx context.Context) *agenttrace.Step {
+	r.mu.Lock()
+	step := r.step
+	r.mu.Unlock()
+	if step == nil {
+		return r.nextStep(ctx)
+	}
+	return step
+}
+
+// finish ends the last step and the run; a nil run is not traced
+func (r *tracedRun) finish(ctx context.Context, result *schema.Message, err error) {
+	if r == nil {
 		return
 	}
+	r.streaming.Wait()
+	r.mu.Lock()
+	if r.step != nil {
+		r.step.End(ctx)
+	}
+	r.mu.Unlock()
+	if finishErr := r.run.Finish(ctx, result, err); finishErr != nil {
+		log.Printf("Error tracing agent run: %v", finishErr)
+	}
+}
 
-	cbh, _ := langfuse.NewLangfuseHandler(&langfuse.Config{
-		Host:      "http://127.0.0.1:3000",
-		Name:      "eino-demo",
-		Release:   "v1.0.0",
-		Public:    true,
-		SecretKey: secretKey,
-		PublicKey: publicKey,
-	})
+// tracedChatModel records every model call as the generation of a new step
+type tracedChatModel struct {
+	model.ToolCallingChatModel
+	name string
+}
 
-	callbacks.AppendGlobalHandlers(cbh)
+func traceChatModel(cm model.ToolCallingChatModel, name string) model.ToolCallingChatModel {
+	return &tracedChatModel{ToolCallingChatModel: cm, name: name}
+}
+
+func (m *tracedChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	bound, err := m.ToolCallingChatModel.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return traceChatModel(bound, m.name), nil
+}
+
+func (m *tracedChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	r := tracedRunFrom(ctx)
+	if r == nil {
+		return m.ToolCallingChatModel.Generate(ctx, input, opts...)
+	}
+	gen := r.nextStep(ctx).Generation(m.name)
+	gen.Builder().Input(input)
+
+	msg, err := m.ToolCallingChatModel.Generate(ctx, input, opts...)
+	endGeneration(ctx, gen, msg, err)
+	return msg, err
+}
+
+func (m *tracedChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	r := tracedRunFrom(ctx)
+	if r == nil {
+		return m.ToolCallingChatModel.Stream(ctx, input, opts...)
+	}
+	gen := r.nextStep(ctx).Generation(m.name)
+	gen.Builder().Input(input)
+
+	sr, err := m.ToolCallingChatModel.Stream(ctx, input, opts...)
+	if err != nil {
+		endGeneration(ctx, gen, nil, err)
+		return nil, err
+	}
+
+	// The generation ends with the whole message, read from a copy of the stream
+	copies := sr.Copy(2)
+	r.streaming.Add(1)
+	go func() {
+		defer r.streaming.Done()
+		msg, err := schema.ConcatMessageStream(copies[1])
+		endGeneration(ctx, gen, msg, err)
+	}()
+	return copies[0], nil
+}
+
+func endGeneration(ctx context.Context, gen *agenttrace.Generation, msg *schema.Message, err error) {
+	if msg != nil && msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		gen.Builder().UsageTokens(msg.ResponseMeta.Usage.PromptTokens, msg.ResponseMeta.Usage.CompletionTokens)
+	}
+	gen.End(ctx, msg, err)
+}
+
+// tracedTool records every call of the tool as a tool span of the current step
+type tracedTool struct {
+	tool.InvokableTool
+}
+
+// traceTools wraps the invokable tools; other tools are left as is
+func traceTools(tools []tool.BaseTool) []tool.BaseTool {
+	traced := make([]tool.BaseTool, len(tools))
+	for i, t := range tools {
+		traced[i] = t
+		if invokable, ok := t.(tool.InvokableTool); ok {
+			traced[i] = &tracedTool{InvokableTool: invokable}
+		}
+	}
+	return traced
+}
+
+func (t *tracedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	r := tracedRunFrom(ctx)
+	if r == nil {
+		return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+	info, err := t.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var input interface{} = argumentsInJSON
+	if json.Valid([]byte(argumentsInJSON)) {
+		input = json.RawMessage(argumentsInJSON)
+	}
+	output, err := r.currentStep(ctx).Tool(info.Name).Run(ctx, input, func(ctx context.Context) (interface{}, error) {
+		return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	})
+	result, _ := output.(string)
+	return result, err
 }
@@ -270,7 +270,7 @@ func (bp *BatchProcessor) ProcessBatch(ctx context.Context, queries []string, ba
 func main() {
 	// Initialize Langfuse client
 	langfuseClient, err := client.NewWithOptions(
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithHost("https://cloud.langfuse.com"),
 		client.WithDebug(true),
 		client.WithEnvironment("development"),
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"eino/pkg/langfuse/client"
+)
+
+// This example exposes SDK internals on a debug endpoint so operators can inspect a
+// running process without a metrics stack. Run with: go run examples/advanced/debug_handler.go
+//
+//	curl localhost:8080/debug/langfuse
+//	curl -X POST -H "Authorization: Bearer $LANGFUSE_DEBUG_TOKEN" localhost:8080/debug/langfuse/flush
+
+func main() {
+	langfuseClient, err := client.NewWithOptions(
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
+		client.WithHost("https://cloud.langfuse.com"),
+		client.WithEnvironment("development"),
+	)
+	if err != nil {
+		log.Fatal("Failed to create Langfuse client:", err)
+	}
+	defer langfuseClient.Shutdown(context.Background())
+
+	// Generate some activity so the stats are not empty
+	trace := langfuseClient.Trace("debug-handler-example")
+	if err := trace.End(context.Background()); err != nil {
+		log.Printf("Failed to submit trace: %v", err)
+	}
+
+	// The flush endpoint is only enabled when a token is configured
+	var opts []client.DebugHandlerOption
+	if token := os.Getenv("LANGFUSE_DEBUG_TOKEN"); token != "" {
+		opts = append(opts, client.WithFlushToken(token))
+	}
+
+	debugHandler := langfuseClient.DebugHandler(opts...)
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/langfuse", debugHandler)
+	mux.Handle("/debug/langfuse/", debugHandler)
+
+	fmt.Println("Serving Langfuse debug info on http://localhost:8080/debug/langfuse")
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
@@ -13,7 +13,7 @@ import (
 func main() {
 	// Initialize Langfuse client
 	langfuseClient, err := client.NewWithOptions(
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithHost("https://cloud.langfuse.com"),
 		client.WithDebug(true),
 		client.WithEnvironment("development"),
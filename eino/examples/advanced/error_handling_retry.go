@@ -287,7 +287,7 @@ func (rp *RetryableProcessor) calculateBackoff(attempt int) time.Duration {
 func main() {
 	// Initialize Langfuse client
 	langfuseClient, err := client.NewWithOptions(
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithHost("https://cloud.langfuse.com"),
 		client.WithDebug(true),
 		client.WithEnvironment("development"),
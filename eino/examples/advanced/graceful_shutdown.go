@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"eino/pkg/langfuse/client"
+)
+
+// This example flushes pending events when the process is asked to stop, e.g. by
+// Kubernetes sending SIGTERM. Run with: go run examples/advanced/graceful_shutdown.go
+// and stop it with Ctrl-C.
+
+func main() {
+	langfuseClient, err := client.NewWithOptions(
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
+		client.WithHost("https://cloud.langfuse.com"),
+		// Give the final flush at most 5 seconds; the signal is then passed on to the
+		// application's own handler below instead of exiting the process
+		client.WithSignalShutdown(5*time.Second, false, 0),
+	)
+	if err != nil {
+		log.Fatal("Failed to create Langfuse client:", err)
+	}
+
+	// Flush on SIGTERM or SIGINT; the application keeps its own signal handling below
+	stop := langfuseClient.ShutdownOnSignal()
+	defer stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		trace := langfuseClient.Trace("handle-request").WithInput(r.URL.Path)
+		fmt.Fprintln(w, "ok")
+		if err := trace.WithOutput("ok").End(r.Context()); err != nil {
+			log.Printf("Failed to submit trace: %v", err)
+		}
+	})
+
+	server := &http.Server{Addr: ":8080", Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	fmt.Println("Serving on http://localhost:8080, press Ctrl-C to stop")
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
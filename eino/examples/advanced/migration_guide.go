@@ -41,7 +41,7 @@ func main() {
 
 func initializeClient() *client.Langfuse {
 	langfuse, err := client.NewWithOptions(
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithHost("https://cloud.langfuse.com"),
 		client.WithDebug(true),
 		client.WithEnvironment("development"),
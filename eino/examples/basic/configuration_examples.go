@@ -60,8 +60,8 @@ func programmaticConfig() {
 
 	config := &client.Config{
 		Host:           "https://cloud.langfuse.com",
-		PublicKey:      "your-public-key",
-		SecretKey:      "your-secret-key",
+		PublicKey:      "pk-lf-your-public-key",
+		SecretKey:      "sk-lf-your-secret-key",
 		Debug:          true,
 		Enabled:        true,
 		Environment:    "development",
@@ -88,7 +88,7 @@ func configWithOptions() {
 
 	langfuseClient, err := client.NewWithOptions(
 		client.WithHost("https://cloud.langfuse.com"),
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithDebug(true),
 		client.WithEnvironment("staging"),
 		client.WithTimeout(15*time.Second),
@@ -112,7 +112,7 @@ func productionConfig() {
 	langfuseClient, err := client.NewWithOptions(
 		// Connection settings
 		client.WithHost("https://cloud.langfuse.com"),
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		
 		// Environment settings
 		client.WithEnvironment("production"),
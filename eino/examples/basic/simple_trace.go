@@ -14,7 +14,7 @@ func main() {
 	// Create a new Langfuse client with basic configuration
 	config, err := client.NewConfig(
 		client.WithHost("https://cloud.langfuse.com"),
-		client.WithCredentials("your-public-key", "your-secret-key"),
+		client.WithCredentials("pk-lf-your-public-key", "sk-lf-your-secret-key"),
 		client.WithDebug(true),
 		client.WithEnvironment("development"),
 	)
@@ -7,7 +7,6 @@ require (
 	github.com/AfterShip/gopkg v1.9.13
 	github.com/anthropics/anthropic-sdk-go v1.7.0
 	github.com/cloudwego/eino v0.4.1
-	github.com/cloudwego/eino-ext/callbacks/langfuse v0.0.0-20250804092122-8845979a2228
 	github.com/cloudwego/eino-ext/components/model/claude v0.1.2
 	github.com/cloudwego/eino-ext/devops v0.1.8
 	github.com/gin-gonic/gin v1.10.1
@@ -17,6 +16,7 @@ require (
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/time v0.6.0
 	google.golang.org/grpc v1.64.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -42,7 +42,6 @@ require (
 	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/cloudwego/base64x v0.1.5 // indirect
-	github.com/cloudwego/eino-ext/libs/acl/langfuse v0.0.0-20250804092122-8845979a2228 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
@@ -114,5 +113,4 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240722135656-d784300faade // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,212 @@
+// Package agenttrace models the loop of a tool-calling agent as a Langfuse trace: a span
+// per reasoning step holding the step's LLM call and tool calls, and hand-offs between
+// agents, instead of a flat list of observations.
+//
+// Example:
+//
+//	run := agenttrace.AgentRun(ctx, lf, "support-agent")
+//	for i := 1; i <= maxSteps; i++ {
+//		step := run.Step(i)
+//		gen := step.Generation("claude-sonnet-4")
+//		reply, err := model.Generate(ctx, messages)
+//		gen.End(ctx, reply, err)
+//		for _, call := range reply.ToolCalls {
+//			result, err := step.Tool(call.Name).Run(ctx, call.Arguments, func(ctx context.Context) (interface{}, error) {
+//				return tools[call.Name].Invoke(ctx, call.Arguments)
+//			})
+//			...
+//		}
+//		step.End(ctx)
+//	}
+//	err = run.Finish(ctx, answer, err)
+package agenttrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"eino/pkg/langfuse/client"
+	"eino/pkg/langfuse/middleware"
+)
+
+// Metadata keys set on the observations of a run
+const (
+	// AgentMetadataKey holds the name of the agent on the trace and on hand-offs
+	AgentMetadataKey = "agent"
+
+	// StepMetadataKey holds the step number on a step and everything recorded in it
+	StepMetadataKey = "agent_step"
+
+	// ToolMetadataKey holds the tool name on a tool span
+	ToolMetadataKey = "tool"
+
+	// HandoffMetadataKey holds the agent a hand-off passes control to
+	HandoffMetadataKey = "handoff_to"
+
+	// AutoClosedMetadataKey is set to true on observations that were still open when the
+	// run finished and were ended by Finish
+	AutoClosedMetadataKey = "auto_closed"
+)
+
+// autoClosedMessage is the status message of observations ended by Finish
+const autoClosedMessage = "not ended before the agent run finished"
+
+// Run is the trace of one agent run. Its methods are safe for concurrent use, so tools
+// of a step can run in parallel.
+type Run struct {
+	mu       sync.Mutex
+	trace    *client.TraceBuilder
+	name     string
+	current  *Step
+	open     []observation
+	errs     []error
+	finished bool
+}
+
+// observation is a step, generation or tool span that Finish ends if it is still open
+type observation interface {
+	autoClose(ctx context.Context) error
+}
+
+// AgentRun starts the trace of an agent run named name. The trace is sent when the run
+// begins so long runs show up in Langfuse while they execute; Finish ends it. With a
+// disabled client nothing is sent, and Tool.Run still calls its function.
+func AgentRun(ctx context.Context, lf *client.Langfuse, name string) *Run {
+	run := &Run{
+		name:  name,
+		trace: lf.Trace(name).AddMetadata(AgentMetadataKey, name),
+	}
+	if run.disabled() {
+		return run
+	}
+	run.record(run.trace.Begin(ctx))
+	return run
+}
+
+// Trace returns the trace of the run, for fields such as the user or session
+func (r *Run) Trace() *client.TraceBuilder {
+	return r.trace
+}
+
+// Context returns ctx carrying the run's trace, for code that traces through the
+// middleware package helpers
+func (r *Run) Context(ctx context.Context) context.Context {
+	return middleware.ContextWithTrace(ctx, r.trace)
+}
+
+// Step starts the span of reasoning step i. Steps are usually numbered from 1.
+func (r *Run) Step(i int) *Step {
+	step := &Step{run: r, index: i}
+	step.span = r.trace.Span(fmt.Sprintf("step %d", i)).AddMetadata(StepMetadataKey, i)
+	if !r.disabled() {
+		r.begin(step, step.span.Begin(context.Background()))
+	}
+
+	r.mu.Lock()
+	r.current = step
+	r.mu.Unlock()
+	return step
+}
+
+// Handoff records that the agent passes control to targetAgent, as a zero-length span
+// under the current step, or under the trace before the first step
+func (r *Run) Handoff(targetAgent string) {
+	if r.disabled() {
+		return
+	}
+
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+
+	name := "handoff " + targetAgent
+	var span *client.SpanBuilder
+	if current != nil {
+		span = current.span.ChildSpan(name).AddMetadata(StepMetadataKey, current.index)
+	} else {
+		span = r.trace.Span(name)
+	}
+	now := time.Now().UTC()
+	span.StartTime(now).EndTime(now).
+		AddMetadata(AgentMetadataKey, r.name).
+		AddMetadata(HandoffMetadataKey, targetAgent)
+	r.record(span.Submit(context.Background()))
+}
+
+// Finish ends the run with its result, or with err when the run failed. Steps,
+// generations and tool spans still open are ended first, marked with a warning level
+// and the auto_closed metadata key. Finish returns the errors met while recording the
+// run, such as a full queue; later calls return nil.
+func (r *Run) Finish(ctx context.Context, result interface{}, err error) error {
+	r.mu.Lock()
+	if r.finished {
+		r.mu.Unlock()
+		return nil
+	}
+	r.finished = true
+	open := r.open
+	r.open = nil
+	r.mu.Unlock()
+
+	if r.disabled() {
+		return nil
+	}
+
+	// Children were opened after their parents, so closing in reverse ends them first
+	for i := len(open) - 1; i >= 0; i-- {
+		r.record(open[i].autoClose(ctx))
+	}
+
+	if err != nil {
+		r.trace.WithErrorOutput(err)
+	} else {
+		r.trace.Output(result)
+	}
+	r.record(r.trace.End(ctx))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.errs...)
+}
+
+// disabled reports whether the client was disabled, leaving the trace without an ID
+func (r *Run) disabled() bool {
+	return r.trace.GetID() == ""
+}
+
+// begin tracks an observation that has begun, so Finish can end it
+func (r *Run) begin(o observation, err error) {
+	if err != nil {
+		r.record(err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.open = append(r.open, o)
+}
+
+// end stops tracking an observation ended by its owner
+func (r *Run) end(o observation, err error) {
+	r.mu.Lock()
+	for i, open := range r.open {
+		if open == o {
+			r.open = append(r.open[:i], r.open[i+1:]...)
+			break
+		}
+	}
+	r.mu.Unlock()
+	r.record(err)
+}
+
+// record keeps an error returned while recording the run, reported by Finish
+func (r *Run) record(err error) {
+	if err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
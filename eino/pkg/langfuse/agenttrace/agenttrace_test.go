@@ -0,0 +1,205 @@
+package agenttrace
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/client"
+	"eino/pkg/langfuse/config"
+	"eino/pkg/langfuse/middleware"
+)
+
+// recordingTransport keeps the submitted events in memory
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []ingestiontypes.IngestionEvent
+}
+
+func (r *recordingTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, events...)
+	return &ingestiontypes.IngestionResponse{Success: true, Timestamp: time.Now()}, nil
+}
+
+// bodies flushes lf and returns the recorded event bodies by ID, the fields of later
+// events overriding earlier ones
+func (r *recordingTransport) bodies(t *testing.T, lf *client.Langfuse) map[string]map[string]interface{} {
+	t.Helper()
+	require.NoError(t, lf.Flush(context.Background()))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range r.events {
+		var body map[string]interface{}
+		require.NoError(t, event.DecodeBody(&body))
+		id := body["id"].(string)
+		if bodies[id] == nil {
+			bodies[id] = make(map[string]interface{})
+		}
+		for k, v := range body {
+			bodies[id][k] = v
+		}
+	}
+	return bodies
+}
+
+func newRecordingLangfuse(t *testing.T) (*client.Langfuse, *recordingTransport) {
+	t.Helper()
+
+	transport := &recordingTransport{}
+	cfg := config.DefaultConfig()
+	cfg.Host = "http://localhost"
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.SkipInitialHealthCheck = true
+	cfg.FlushInterval = time.Hour
+	cfg.IngestionTransport = transport
+
+	lf, err := client.New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { lf.Shutdown(context.Background()) })
+	return lf, transport
+}
+
+func TestAgentRun(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	ctx := context.Background()
+
+	run := AgentRun(ctx, lf, "support-agent")
+	step := run.Step(1)
+	gen := step.Generation("claude-sonnet-4")
+	gen.Builder().UsageTokens(120, 30)
+	require.NoError(t, gen.End(ctx, "look up the order", nil))
+
+	tool := step.Tool("get_order")
+	var toolCtxSpan *client.SpanBuilder
+	output, err := tool.Run(ctx, map[string]interface{}{"order": "42"}, func(ctx context.Context) (interface{}, error) {
+		toolCtxSpan = middleware.GetSpanFromContext(ctx)
+		return map[string]interface{}{"status": "shipped"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "shipped"}, output)
+	assert.Same(t, tool.span, toolCtxSpan, "the tool function runs in the context of its span")
+
+	_, err = step.Tool("notify").Run(ctx, "order 42", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("smtp down")
+	})
+	assert.EqualError(t, err, "smtp down")
+	run.Handoff("billing-agent")
+	require.NoError(t, step.End(ctx))
+	require.NoError(t, run.Finish(ctx, "your order has shipped", nil))
+
+	bodies := transport.bodies(t, lf)
+	traceID := run.Trace().GetID()
+	require.Contains(t, bodies, traceID)
+	assert.Equal(t, "support-agent", bodies[traceID]["name"])
+	assert.Equal(t, "your order has shipped", bodies[traceID]["output"])
+
+	stepBody := bodies[step.Span().GetID()]
+	assert.Equal(t, "step 1", stepBody["name"])
+	assert.Nil(t, stepBody["parentObservationId"])
+	assert.Equal(t, float64(1), stepBody["metadata"].(map[string]interface{})[StepMetadataKey])
+
+	genBody := bodies[gen.Builder().GetID()]
+	assert.Equal(t, step.Span().GetID(), genBody["parentObservationId"])
+	assert.Equal(t, "claude-sonnet-4", genBody["model"])
+	assert.Equal(t, "look up the order", genBody["output"])
+	assert.NotNil(t, genBody["endTime"])
+
+	toolBody := bodies[tool.span.GetID()]
+	assert.Equal(t, "get_order", toolBody["name"])
+	assert.Equal(t, step.Span().GetID(), toolBody["parentObservationId"])
+	assert.Equal(t, map[string]interface{}{"order": "42"}, toolBody["input"])
+	assert.Equal(t, map[string]interface{}{"status": "shipped"}, toolBody["output"])
+	assert.Equal(t, "get_order", toolBody["metadata"].(map[string]interface{})[ToolMetadataKey])
+	assert.Equal(t, float64(1), toolBody["metadata"].(map[string]interface{})[StepMetadataKey])
+
+	var failed, handoff map[string]interface{}
+	for _, body := range bodies {
+		switch body["name"] {
+		case "notify":
+			failed = body
+		case "handoff billing-agent":
+			handoff = body
+		}
+	}
+	require.NotNil(t, failed)
+	assert.Equal(t, "ERROR", failed["level"])
+	assert.Equal(t, "smtp down", failed["statusMessage"])
+	require.NotNil(t, handoff)
+	assert.Equal(t, step.Span().GetID(), handoff["parentObservationId"])
+	assert.Equal(t, "billing-agent", handoff["metadata"].(map[string]interface{})[HandoffMetadataKey])
+
+	for id, body := range bodies {
+		if metadata, ok := body["metadata"].(map[string]interface{}); ok {
+			assert.NotContains(t, metadata, AutoClosedMetadataKey, "%s was ended by its owner", id)
+		}
+	}
+}
+
+func TestAgentRun_FinishClosesOpenObservations(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	ctx := context.Background()
+
+	run := AgentRun(ctx, lf, "research-agent")
+	done := run.Step(1)
+	require.NoError(t, done.End(ctx))
+	step := run.Step(2)
+	gen := step.Generation("claude-sonnet-4")
+
+	runErr := errors.New("step budget exhausted")
+	require.NoError(t, run.Finish(ctx, nil, runErr))
+	assert.NoError(t, run.Finish(ctx, nil, nil), "a second Finish is a no-op")
+	assert.NoError(t, step.End(ctx), "ending an auto-closed step is a no-op")
+
+	bodies := transport.bodies(t, lf)
+	for _, id := range []string{step.Span().GetID(), gen.Builder().GetID()} {
+		body := bodies[id]
+		assert.Equal(t, "WARNING", body["level"])
+		assert.Equal(t, true, body["metadata"].(map[string]interface{})[AutoClosedMetadataKey])
+		assert.NotNil(t, body["endTime"])
+	}
+	assert.NotContains(t, bodies[done.Span().GetID()]["metadata"], AutoClosedMetadataKey)
+	assert.Equal(t, runErr.Error(), bodies[run.Trace().GetID()]["output"].(map[string]interface{})["error"])
+}
+
+func TestTool_RunPanics(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	ctx := context.Background()
+	run := AgentRun(ctx, lf, "agent")
+	tool := run.Step(1).Tool("flaky")
+
+	assert.PanicsWithValue(t, "boom", func() {
+		tool.Run(ctx, nil, func(ctx context.Context) (interface{}, error) { panic("boom") })
+	})
+	require.NoError(t, run.Finish(ctx, nil, nil))
+
+	body := transport.bodies(t, lf)[tool.span.GetID()]
+	assert.Equal(t, "ERROR", body["level"])
+	assert.Equal(t, "tool flaky panicked: boom", body["statusMessage"])
+}
+
+func TestAgentRun_Disabled(t *testing.T) {
+	lf, err := client.NewWithOptions(client.WithCredentials("pk-test", "sk-test"), client.WithEnabled(false))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	run := AgentRun(ctx, lf, "agent")
+	step := run.Step(1)
+	step.Generation("model").End(ctx, "reply", nil)
+	output, err := step.Tool("echo").Run(ctx, "hi", func(ctx context.Context) (interface{}, error) { return "hi", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "hi", output)
+	run.Handoff("other")
+	assert.NoError(t, run.Finish(ctx, "done", nil))
+}
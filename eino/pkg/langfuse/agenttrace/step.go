@@ -0,0 +1,175 @@
+package agenttrace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"eino/pkg/langfuse/client"
+	"eino/pkg/langfuse/middleware"
+)
+
+// closer makes ending an observation exactly-once between its owner and Finish
+type closer struct {
+	mu    sync.Mutex
+	ended bool
+}
+
+// claim reports whether the caller is the first to end the observation
+func (c *closer) claim() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ended {
+		return false
+	}
+	c.ended = true
+	return true
+}
+
+// Step is the span of one reasoning step of a run
+type Step struct {
+	closer
+	run   *Run
+	index int
+	span  *client.SpanBuilder
+}
+
+// Index returns the step number
+func (s *Step) Index() int {
+	return s.index
+}
+
+// Span returns the span of the step
+func (s *Step) Span() *client.SpanBuilder {
+	return s.span
+}
+
+// Generation starts the generation of the step's LLM call to model. Set the prompt and
+// usage on Builder before calling End.
+func (s *Step) Generation(model string) *Generation {
+	gen := &Generation{run: s.run}
+	gen.builder = s.span.ChildGeneration("llm").
+		Model(model).
+		AddMetadata(StepMetadataKey, s.index)
+	if !s.run.disabled() {
+		s.run.begin(gen, gen.builder.Begin(context.Background()))
+	}
+	return gen
+}
+
+// Tool returns the tool call name of the step, recorded when it is run
+func (s *Step) Tool(name string) *Tool {
+	return &Tool{step: s, name: name}
+}
+
+// End ends the step
+func (s *Step) End(ctx context.Context) error {
+	if s.run.disabled() || !s.claim() {
+		return nil
+	}
+	err := s.span.End(ctx)
+	s.run.end(s, err)
+	return err
+}
+
+func (s *Step) autoClose(ctx context.Context) error {
+	if !s.claim() {
+		return nil
+	}
+	return markAutoClosed(s.span).End(ctx)
+}
+
+// Generation is the LLM call of a step
+type Generation struct {
+	closer
+	run     *Run
+	builder *client.GenerationBuilder
+}
+
+// Builder returns the generation, for the input, usage and model parameters
+func (g *Generation) Builder() *client.GenerationBuilder {
+	return g.builder
+}
+
+// End ends the generation with the model's output, or with err when the call failed
+func (g *Generation) End(ctx context.Context, output interface{}, err error) error {
+	if g.run.disabled() || !g.claim() {
+		return nil
+	}
+	if err != nil {
+		g.builder.WithErrorOutput(err)
+	} else {
+		g.builder.Output(output)
+	}
+	endErr := g.builder.End(ctx)
+	g.run.end(g, endErr)
+	return endErr
+}
+
+func (g *Generation) autoClose(ctx context.Context) error {
+	if !g.claim() {
+		return nil
+	}
+	return g.builder.AddMetadata(AutoClosedMetadataKey, true).
+		Warning().
+		StatusMessage(autoClosedMessage).
+		End(ctx)
+}
+
+// Tool is a tool call of a step
+type Tool struct {
+	closer
+	step *Step
+	name string
+	span *client.SpanBuilder
+}
+
+// Run calls fn as the tool, recording input and the value or error fn returns on the
+// tool's span. fn gets a context carrying the span, so observations it creates through
+// the middleware helpers nest under the tool. A panic in fn is recorded as an error
+// before it propagates. A Tool is meant to be run once.
+func (t *Tool) Run(ctx context.Context, input interface{}, fn func(ctx context.Context) (interface{}, error)) (output interface{}, err error) {
+	run := t.step.run
+	if run.disabled() {
+		return fn(ctx)
+	}
+
+	t.span = t.step.span.ChildSpan(t.name).
+		AddMetadata(StepMetadataKey, t.step.index).
+		AddMetadata(ToolMetadataKey, t.name).
+		Input(input)
+	run.begin(t, t.span.Begin(ctx))
+
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("tool %s panicked: %v", t.name, recovered)
+		}
+		if t.claim() {
+			if err != nil {
+				t.span.WithErrorOutput(err)
+			} else {
+				t.span.Output(output)
+			}
+			run.end(t, t.span.End(ctx))
+		}
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+	return fn(middleware.ContextWithTraceAndSpan(ctx, run.trace, t.span))
+}
+
+func (t *Tool) autoClose(ctx context.Context) error {
+	if !t.claim() {
+		return nil
+	}
+	return markAutoClosed(t.span).End(ctx)
+}
+
+// markAutoClosed flags a span ended by Finish rather than by its owner
+func markAutoClosed(span *client.SpanBuilder) *client.SpanBuilder {
+	return span.AddMetadata(AutoClosedMetadataKey, true).
+		Warning().
+		StatusMessage(autoClosedMessage)
+}
@@ -0,0 +1,426 @@
+// Package apitest records the API interactions of a Langfuse client once and replays
+// them, so tests can run against real API responses without network access or
+// credentials.
+//
+// Record against a live host:
+//
+//	cfg, err := client.NewConfig(
+//		client.WithCredentials(publicKey, secretKey),
+//		apitest.WithRecording("testdata/traces", apitest.RecordModeRecord),
+//	)
+//
+// then replay the saved responses in tests:
+//
+//	cfg, err := client.NewConfig(
+//		client.WithCredentials("pk-lf-test", "sk-lf-test"),
+//		apitest.WithRecording("testdata/traces", apitest.RecordModeReplay),
+//	)
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"eino/pkg/langfuse/config"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// RecordMode selects whether a Recorder saves or serves API interactions
+type RecordMode string
+
+const (
+	// RecordModeRecord sends requests to the API and saves every request/response pair
+	RecordModeRecord RecordMode = "record"
+
+	// RecordModeReplay serves responses from the saved interactions without sending requests
+	RecordModeReplay RecordMode = "replay"
+)
+
+// Redacted replaces secrets in recorded interactions
+const Redacted = "REDACTED"
+
+// recordingFile matches the names of recorded interactions, numbered in request order
+var recordingFile = regexp.MustCompile(`^\d{4,}\.json$`)
+
+// secretHeaders are replaced with Redacted in recorded requests and responses
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// secretFields are JSON object keys and query parameters whose values are replaced with
+// Redacted, compared case-insensitively without '_' and '-'
+var secretFields = []string{"authorization", "publickey", "secretkey", "apikey", "password", "token", "accesstoken"}
+
+// secretKeyPrefixes mark string values that are Langfuse secret keys wherever they appear
+var secretKeyPrefixes = []string{"sk-lf-"}
+
+// Interaction is a recorded request and the response it received
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request saved with an interaction
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+// RecordedResponse is the part of a response saved with an interaction
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+// recordedBody holds a body as JSON when it is valid JSON, and as text otherwise
+type recordedBody struct {
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"bodyText,omitempty"`
+}
+
+func newRecordedBody(data []byte) recordedBody {
+	if len(data) == 0 {
+		return recordedBody{}
+	}
+	if json.Valid(data) {
+		return recordedBody{Body: scrubJSON(data)}
+	}
+	return recordedBody{BodyText: string(data)}
+}
+
+func (b recordedBody) bytes() []byte {
+	if b.Body != nil {
+		return b.Body
+	}
+	return []byte(b.BodyText)
+}
+
+// Recorder is an http.RoundTripper that records API interactions to a directory, or
+// replays them from it. It is safe for concurrent use, though interactions are only
+// reproducible when requests are sent in the same order.
+type Recorder struct {
+	dir  string
+	mode RecordMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	recorded     int
+	interactions []*Interaction
+	replayed     []bool
+}
+
+// NewRecorder returns a Recorder for dir. In record mode, requests are sent with next
+// (http.DefaultTransport when nil) and saved as numbered JSON files, replacing any
+// earlier recording in dir. In replay mode, the recording in dir is loaded and next is
+// not used.
+func NewRecorder(dir string, mode RecordMode, next http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{dir: dir, mode: mode, next: next}
+	if r.next == nil {
+		r.next = http.DefaultTransport
+	}
+
+	switch mode {
+	case RecordModeRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("apitest: failed to create recording directory: %w", err)
+		}
+		files, err := recordingFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				return nil, fmt.Errorf("apitest: failed to remove earlier recording: %w", err)
+			}
+		}
+	case RecordModeReplay:
+		files, err := recordingFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("apitest: failed to read recording: %w", err)
+			}
+			var interaction Interaction
+			if err := json.Unmarshal(data, &interaction); err != nil {
+				return nil, fmt.Errorf("apitest: invalid recording %s: %w", file, err)
+			}
+			r.interactions = append(r.interactions, &interaction)
+		}
+		r.replayed = make([]bool, len(r.interactions))
+	default:
+		return nil, fmt.Errorf("apitest: unknown record mode %q", mode)
+	}
+	return r, nil
+}
+
+// WithRecording sends the client's API requests through a Recorder for dir. Fixtures are
+// recorded once against a live host with RecordModeRecord and replayed in tests with
+// RecordModeReplay.
+func WithRecording(dir string, mode RecordMode) config.ConfigOption {
+	return func(c *config.Config) error {
+		recorder, err := NewRecorder(dir, mode, c.HTTPTransport)
+		if err != nil {
+			return utils.NewConfigurationError("httpTransport", err.Error())
+		}
+		return config.WithHTTPTransport(recorder)(c)
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == RecordModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+// Unreplayed returns the recorded interactions that have not been served yet in replay
+// mode, so tests can check that the client sent every recorded request
+func (r *Recorder) Unreplayed() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unreplayed []*Interaction
+	for i, interaction := range r.interactions {
+		if !r.replayed[i] {
+			unreplayed = append(unreplayed, interaction)
+		}
+	}
+	return unreplayed
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("apitest: failed to read request body: %w", err)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("apitest: failed to read response body: %w", err)
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:       req.Method,
+			URL:          scrubURL(req.URL),
+			Header:       scrubHeader(req.Header),
+			recordedBody: newRecordedBody(requestBody),
+		},
+		Response: RecordedResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       scrubHeader(resp.Header),
+			recordedBody: newRecordedBody(responseBody),
+		},
+	}
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("apitest: failed to encode interaction: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorded++
+	file := filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.recorded))
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("apitest: failed to save interaction: %w", err)
+	}
+	return resp, nil
+}
+
+// replay serves the first recorded interaction matching the method, path and query of
+// req that has not been served yet
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := 0
+	for i, interaction := range r.interactions {
+		recordedURL, err := url.Parse(interaction.Request.URL)
+		if err != nil || matchKey(interaction.Request.Method, recordedURL) != key {
+			continue
+		}
+		matched++
+		if r.replayed[i] {
+			continue
+		}
+		r.replayed[i] = true
+
+		body := interaction.Response.bytes()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	if matched > 0 {
+		return nil, fmt.Errorf("apitest: all %d recorded responses for %s were already replayed", matched, key)
+	}
+	return nil, fmt.Errorf("apitest: no recorded response for %s in %s", key, r.dir)
+}
+
+// matchKey identifies a request by method, path and query, with query parameters sorted
+// and secret values redacted as in recordings. The host is ignored so recordings can be
+// replayed against any host.
+func matchKey(method string, u *url.URL) string {
+	query := scrubQuery(u.Query())
+	key := strings.ToUpper(method) + " " + u.Path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+// recordingFiles lists the recorded interactions in dir in request order
+func recordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("apitest: failed to read recording directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && recordingFile.MatchString(entry.Name()) {
+			files = append(files, entry.Name())
+		}
+	}
+	slices.SortFunc(files, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+		return strings.Compare(a, b)
+	})
+	for i, file := range files {
+		files[i] = filepath.Join(dir, file)
+	}
+	return files, nil
+}
+
+// readBody reads *body and replaces it with a reader over the same bytes
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func isSecretField(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	return slices.Contains(secretFields, name)
+}
+
+func isSecretValue(value string) bool {
+	for _, prefix := range secretKeyPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for _, name := range secretHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, Redacted)
+		}
+	}
+	return scrubbed
+}
+
+func scrubQuery(query url.Values) url.Values {
+	for name, values := range query {
+		for i, value := range values {
+			if isSecretField(name) || isSecretValue(value) {
+				values[i] = Redacted
+			}
+		}
+	}
+	return query
+}
+
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	scrubbed.User = nil
+	scrubbed.RawQuery = scrubQuery(u.Query()).Encode()
+	return scrubbed.String()
+}
+
+// scrubJSON redacts secret fields and values in a JSON document, keeping it unchanged
+// when there is nothing to redact
+func scrubJSON(data []byte) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	scrubbed, changed := scrubValue(value)
+	if !changed {
+		return data
+	}
+	encoded, err := json.Marshal(scrubbed)
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+func scrubValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		changed := false
+		for key, field := range v {
+			if isSecretField(key) && field != nil {
+				v[key] = Redacted
+				changed = true
+				continue
+			}
+			var fieldChanged bool
+			v[key], fieldChanged = scrubValue(field)
+			changed = changed || fieldChanged
+		}
+		return v, changed
+	case []interface{}:
+		changed := false
+		for i, item := range v {
+			var itemChanged bool
+			v[i], itemChanged = scrubValue(item)
+			changed = changed || itemChanged
+		}
+		return v, changed
+	case string:
+		if isSecretValue(v) {
+			return Redacted, true
+		}
+	}
+	return value, false
+}
@@ -0,0 +1,175 @@
+package apitest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api"
+	"eino/pkg/langfuse/api/apitest"
+	healthTypes "eino/pkg/langfuse/api/resources/health/types"
+	tracetypes "eino/pkg/langfuse/api/resources/traces/types"
+	"eino/pkg/langfuse/config"
+)
+
+const testSecretKey = "sk-lf-recorded-secret"
+
+// newLangfuseServer serves the health endpoint and a page of traces per name, one of
+// them leaking a key in its metadata
+func newLangfuseServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "OK", "version": "3.0.0"})
+	})
+	mux.HandleFunc("/api/public/traces", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"id":        name + "-1",
+				"name":      name,
+				"timestamp": "2024-05-01T10:00:00Z",
+				"metadata":  map[string]interface{}{"apiKey": "provider-key", "model": "claude"},
+			}},
+			"meta": map[string]interface{}{"page": 1, "limit": 50, "totalItems": 1, "totalPages": 1},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newRecordingClient(t *testing.T, host, dir string, mode apitest.RecordMode) *api.APIClient {
+	t.Helper()
+	cfg, err := config.NewConfig(
+		config.WithHost(host),
+		config.WithCredentials("pk-lf-recorded", testSecretKey),
+		apitest.WithRecording(dir, mode),
+	)
+	require.NoError(t, err)
+	client, err := api.NewAPIClient(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+// session runs the same API calls against client
+func session(t *testing.T, client *api.APIClient) (*healthTypes.HealthResponse, []*tracetypes.GetTracesResponse) {
+	t.Helper()
+	ctx := context.Background()
+
+	health, err := client.Health.Check(ctx)
+	require.NoError(t, err)
+
+	var pages []*tracetypes.GetTracesResponse
+	for _, name := range []string{"chat", "search", "chat"} {
+		limit := 50
+		page, err := client.Traces.List(ctx, &tracetypes.GetTracesRequest{Name: &name, Limit: &limit})
+		require.NoError(t, err)
+		pages = append(pages, page)
+	}
+	return health, pages
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := newLangfuseServer(t)
+	dir := filepath.Join(t.TempDir(), "fixtures")
+
+	recordedHealth, recordedPages := session(t, newRecordingClient(t, server.URL, dir, apitest.RecordModeRecord))
+	assert.Equal(t, "chat-1", recordedPages[0].Data[0].ID)
+	server.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 5, "the initial health check, the health check and three listings")
+
+	replayer, err := apitest.NewRecorder(dir, apitest.RecordModeReplay, nil)
+	require.NoError(t, err)
+	cfg, err := config.NewConfig(
+		config.WithHost("https://replay.example.com"),
+		config.WithCredentials("pk-lf-other", "sk-lf-other"),
+		config.WithHTTPTransport(replayer),
+		config.WithRetryConfig(0, 0, 0), // unmatched requests fail right away
+	)
+	require.NoError(t, err)
+	client, err := api.NewAPIClient(cfg)
+	require.NoError(t, err)
+
+	replayedHealth, replayedPages := session(t, client)
+	assert.Equal(t, recordedHealth, replayedHealth)
+	require.Len(t, replayedPages, len(recordedPages))
+	for i := range recordedPages {
+		assert.Equal(t, recordedPages[i].Data[0].ID, replayedPages[i].Data[0].ID)
+		assert.Equal(t, recordedPages[i].Meta, replayedPages[i].Meta)
+	}
+	assert.Equal(t, "claude", replayedPages[0].Data[0].Metadata["model"])
+	assert.Empty(t, replayer.Unreplayed())
+
+	t.Run("unmatched requests fail", func(t *testing.T) {
+		_, err := client.Traces.Get(context.Background(), "trace-1")
+		assert.ErrorContains(t, err, "no recorded response for GET /api/public/traces/trace-1")
+
+		name := "search"
+		_, err = client.Traces.List(context.Background(), &tracetypes.GetTracesRequest{Name: &name})
+		assert.ErrorContains(t, err, "no recorded response", "the query is matched too")
+
+		limit := 50
+		_, err = client.Traces.List(context.Background(), &tracetypes.GetTracesRequest{Name: &name, Limit: &limit})
+		assert.ErrorContains(t, err, "all 1 recorded responses for GET /api/public/traces?limit=50&name=search were already replayed")
+	})
+
+	t.Run("secrets are scrubbed", func(t *testing.T) {
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), testSecretKey)
+			assert.NotContains(t, string(data), "provider-key")
+		}
+
+		var interaction apitest.Interaction
+		data, err := os.ReadFile(filepath.Join(dir, "0003.json"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &interaction))
+		assert.Equal(t, http.MethodGet, interaction.Request.Method)
+		assert.Equal(t, apitest.Redacted, interaction.Request.Header.Get("Authorization"))
+		assert.Equal(t, http.StatusOK, interaction.Response.StatusCode)
+		var page tracetypes.GetTracesResponse
+		require.NoError(t, json.Unmarshal(interaction.Response.Body, &page))
+		assert.Equal(t, map[string]interface{}{"apiKey": apitest.Redacted, "model": "claude"}, page.Data[0].Metadata)
+	})
+}
+
+func TestRecorder_RecordReplacesEarlierRecording(t *testing.T) {
+	server := newLangfuseServer(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0009.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("fixtures"), 0o644))
+
+	client := newRecordingClient(t, server.URL, dir, apitest.RecordModeRecord)
+	_, err := client.Health.Check(context.Background())
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "0001.json"), filepath.Join(dir, "0002.json"), filepath.Join(dir, "README.md"),
+	}, files)
+}
+
+func TestWithRecording_Errors(t *testing.T) {
+	_, err := config.NewConfig(
+		config.WithCredentials("pk-lf-test", "sk-lf-test"),
+		apitest.WithRecording(filepath.Join(t.TempDir(), "missing"), apitest.RecordModeReplay),
+	)
+	assert.ErrorContains(t, err, "failed to read recording directory")
+
+	_, err = apitest.NewRecorder(t.TempDir(), "rewind", nil)
+	assert.EqualError(t, err, `apitest: unknown record mode "rewind"`)
+}
@@ -2,17 +2,23 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 
 	"eino/pkg/langfuse/api/core"
+	"eino/pkg/langfuse/api/resources/auditlogs"
 	"eino/pkg/langfuse/api/resources/datasets"
 	"eino/pkg/langfuse/api/resources/health"
+	healthTypes "eino/pkg/langfuse/api/resources/health/types"
 	"eino/pkg/langfuse/api/resources/ingestion"
 	"eino/pkg/langfuse/api/resources/models"
+	"eino/pkg/langfuse/api/resources/observations"
 	"eino/pkg/langfuse/api/resources/projects"
 	"eino/pkg/langfuse/api/resources/prompts"
 	"eino/pkg/langfuse/api/resources/scores"
@@ -29,7 +35,7 @@ type APIClient struct {
 	// Configuration
 	config *config.Config
 
-	// Resource clients
+	// Resource clients. Ingestion is nil when a custom ingestion transport is configured.
 	Health    *health.Client
 	Ingestion *ingestion.Client
 	Traces    *traces.Client
@@ -40,6 +46,13 @@ type APIClient struct {
 	Projects  *projects.Client
 	Prompts   *prompts.Client
 
+	// AuditLogs reads the organization audit trail and requires organization-admin credentials
+	AuditLogs *auditlogs.Client
+
+	// Observations lists observations, and creates and updates them in bulk through the
+	// ingestion API even when a custom ingestion transport is configured
+	Observations *observations.Client
+
 	// State management
 	mu     sync.RWMutex
 	closed bool
@@ -50,6 +63,18 @@ type APIClient struct {
 	healthCheckMu   sync.RWMutex
 }
 
+// scoresOptions enables the score-name registry when score names are configured
+func scoresOptions(cfg *config.Config) []scores.ClientOption {
+	if len(cfg.AllowedScoreNames) == 0 && cfg.ScoreNamesRefreshInterval <= 0 {
+		return nil
+	}
+	return []scores.ClientOption{scores.WithNameRegistry(scores.NameRegistryConfig{
+		Names:           cfg.AllowedScoreNames,
+		RefreshInterval: cfg.ScoreNamesRefreshInterval,
+		Strict:          cfg.StrictMode,
+	})}
+}
+
 // NewAPIClient creates a new API client with all resource clients initialized
 func NewAPIClient(config *config.Config) (*APIClient, error) {
 	if config == nil {
@@ -75,18 +100,28 @@ func NewAPIClient(config *config.Config) (*APIClient, error) {
 		client:    client,
 		config:    config,
 		Health:    health.NewClient(client),
-		Ingestion: ingestion.NewClient(client),
 		Traces:    traces.NewClient(client),
-		Scores:    scores.NewClient(client),
+		Scores:    scores.NewClient(client, scoresOptions(config)...),
 		Sessions:  sessions.NewClient(client),
 		Models:    models.NewClient(client),
 		Datasets:  datasets.NewClient(client),
 		Projects:  projects.NewClient(client),
-		Prompts:   prompts.NewClient(client),
+		Prompts:   prompts.NewClient(client, prompts.WithPromptCacheTTL(config.PromptCacheTTL)),
+		AuditLogs: auditlogs.NewClient(client),
 		closed:    false,
 		isHealthy: false,
 	}
 
+	ingestionClient := ingestion.NewClient(client,
+		ingestion.WithSDKInfo(config.SDKName, config.SDKVersion),
+		ingestion.WithSkipInvalidEvents(config.SkipInvalidEvents))
+	apiClient.Observations = observations.NewClient(client, ingestionClient)
+
+	// Events go through the custom transport instead, so the REST ingestion client is not needed
+	if config.IngestionTransport == nil {
+		apiClient.Ingestion = ingestionClient
+	}
+
 	// Perform initial health check if enabled
 	if !config.SkipInitialHealthCheck {
 		if err := apiClient.performInitialHealthCheck(); err != nil {
@@ -101,31 +136,35 @@ func NewAPIClient(config *config.Config) (*APIClient, error) {
 }
 
 // validateConfig validates the API client configuration
-func validateConfig(config *config.Config) error {
-	if config.Host == "" {
+func validateConfig(cfg *config.Config) error {
+	if cfg.Host == "" {
 		return fmt.Errorf("host is required")
 	}
 
-	if config.PublicKey == "" {
+	if cfg.PublicKey == "" {
 		return fmt.Errorf("public key is required")
 	}
 
-	if config.SecretKey == "" {
+	if cfg.SecretKey == "" {
 		return fmt.Errorf("secret key is required")
 	}
 
-	if config.RequestTimeout <= 0 {
+	if cfg.RequestTimeout <= 0 {
 		return fmt.Errorf("request timeout must be positive")
 	}
 
-	if config.RetryCount < 0 {
+	if cfg.RetryCount < 0 {
 		return fmt.Errorf("retry count cannot be negative")
 	}
 
-	if config.SampleRate < 0 || config.SampleRate > 1 {
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
 		return fmt.Errorf("sample rate must be between 0 and 1")
 	}
 
+	if cfg.APIVersion != "" && !config.IsSupportedAPIVersion(cfg.APIVersion) {
+		return fmt.Errorf("unsupported API version %q (supported: %s)", cfg.APIVersion, strings.Join(config.SupportedAPIVersions(), ", "))
+	}
+
 	return nil
 }
 
@@ -179,10 +218,17 @@ func (c *APIClient) GetLastHealthCheck() time.Time {
 
 // RefreshHealthStatus performs a new health check and updates the status
 func (c *APIClient) RefreshHealthStatus(ctx context.Context) error {
+	_, err := c.CheckHealth(ctx)
+	return err
+}
+
+// CheckHealth performs a new health check, updates the cached status and returns the
+// service's response
+func (c *APIClient) CheckHealth(ctx context.Context) (*healthTypes.HealthResponse, error) {
 	c.mu.RLock()
 	if c.closed {
 		c.mu.RUnlock()
-		return fmt.Errorf("client is closed")
+		return nil, fmt.Errorf("client is closed")
 	}
 	c.mu.RUnlock()
 
@@ -192,7 +238,7 @@ func (c *APIClient) RefreshHealthStatus(ctx context.Context) error {
 		c.lastHealthCheck = time.Now()
 		c.isHealthy = false
 		c.healthCheckMu.Unlock()
-		return err
+		return nil, err
 	}
 
 	c.healthCheckMu.Lock()
@@ -200,7 +246,7 @@ func (c *APIClient) RefreshHealthStatus(ctx context.Context) error {
 	c.isHealthy = response.IsHealthy()
 	c.healthCheckMu.Unlock()
 
-	return nil
+	return response, nil
 }
 
 // WaitForHealthy waits for the service to become healthy within the given timeout
@@ -283,6 +329,62 @@ func (c *APIClient) GetRestyClient() *resty.Client {
 	return c.client
 }
 
+// RoundTrip makes an authenticated request to an arbitrary API path, for endpoints the
+// SDK does not cover yet. It is a safety valve; prefer the typed resource clients.
+//
+// The path is resolved relative to the configured host and must not be an absolute URL.
+// A non-nil body is sent as JSON, and a non-empty response is unmarshalled into result
+// when result is non-nil. Authentication, retries, timeouts and interceptors from the
+// configuration apply as for every other request.
+//
+// Example:
+//
+//	var out struct{ Data []map[string]interface{} `json:"data"` }
+//	err := apiClient.RoundTrip(ctx, http.MethodGet, "/api/public/v2/new-endpoint", nil, &out)
+func (c *APIClient) RoundTrip(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	if method == "" {
+		return fmt.Errorf("method cannot be empty")
+	}
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if u, err := url.Parse(path); err != nil || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("path must be relative to the configured host: %s", path)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	request := c.client.R().SetContext(ctx)
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		request.SetHeader("Content-Type", "application/json").SetBody(payload)
+	}
+
+	resp, err := request.Execute(strings.ToUpper(method), path)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", strings.ToUpper(method), path, err)
+	}
+
+	if result != nil && len(resp.Body()) > 0 {
+		if err := json.Unmarshal(resp.Body(), result); err != nil {
+			return fmt.Errorf("failed to unmarshal response from %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
 // GetHost returns the API host
 func (c *APIClient) GetHost() string {
 	return c.config.Host
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func newRoundTripTestClient(t *testing.T, handler http.HandlerFunc) *APIClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.RetryCount = 2
+	cfg.RetryDelay = time.Millisecond
+	cfg.MaxRetryDelay = 5 * time.Millisecond
+	cfg.SkipInitialHealthCheck = true
+
+	client, err := NewAPIClient(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func TestAPIClient_RoundTrip(t *testing.T) {
+	client := newRoundTripTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "pk-test", user)
+		assert.Equal(t, "sk-test", pass)
+
+		switch r.URL.Path {
+		case "/api/public/v2/experiments":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "exp-1", body["name"])
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"exp-123","name":"exp-1"}`))
+		case "/api/public/v2/empty":
+			assert.Equal(t, http.MethodDelete, r.Method)
+			data, _ := io.ReadAll(r.Body)
+			assert.Empty(t, data)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	var created struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	err := client.RoundTrip(ctx, http.MethodPost, "/api/public/v2/experiments", map[string]string{"name": "exp-1"}, &created)
+	require.NoError(t, err)
+	assert.Equal(t, "exp-123", created.ID)
+	assert.Equal(t, "exp-1", created.Name)
+
+	// No body and an empty response are both fine
+	require.NoError(t, client.RoundTrip(ctx, http.MethodDelete, "api/public/v2/empty", nil, &created))
+
+	err = client.RoundTrip(ctx, http.MethodGet, "/api/public/v2/unknown", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestAPIClient_RoundTrip_Retries(t *testing.T) {
+	var attempts int32
+	client := newRoundTripTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	var result map[string]bool
+	require.NoError(t, client.RoundTrip(context.Background(), http.MethodGet, "/api/public/flaky", nil, &result))
+	assert.True(t, result["ok"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestAPIClient_RoundTrip_InvalidArguments(t *testing.T) {
+	client := newRoundTripTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL)
+	})
+	ctx := context.Background()
+
+	assert.Error(t, client.RoundTrip(ctx, "", "/api/public/x", nil, nil))
+	assert.Error(t, client.RoundTrip(ctx, http.MethodGet, "", nil, nil))
+	assert.Error(t, client.RoundTrip(ctx, http.MethodGet, "https://evil.example.com/steal", nil, nil))
+	assert.Error(t, client.RoundTrip(ctx, http.MethodGet, "//evil.example.com/steal", nil, nil))
+	assert.Error(t, client.RoundTrip(ctx, http.MethodPost, "/api/public/x", make(chan int), nil))
+
+	require.NoError(t, client.Close())
+	assert.Error(t, client.RoundTrip(ctx, http.MethodGet, "/api/public/x", nil, nil))
+}
@@ -2,12 +2,17 @@ package core
 
 import (
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 
 	"eino/pkg/langfuse/config"
 )
 
+// APIVersionHeader carries Config.APIVersion on every request
+const APIVersionHeader = "X-Langfuse-Api-Version"
+
 // ConfigureRestyClient configures a resty client with Langfuse-specific settings
 func ConfigureRestyClient(client *resty.Client, cfg *config.Config) error {
 	if client == nil {
@@ -25,27 +30,106 @@ func ConfigureRestyClient(client *resty.Client, cfg *config.Config) error {
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Accept", "application/json")
 
+	if cfg.APIVersion != "" {
+		client.SetHeader(APIVersionHeader, cfg.APIVersion)
+	}
+	if cfg.SDKName != "" {
+		client.SetHeader(SDKNameHeader, cfg.SDKName)
+	}
+	if cfg.SDKVersion != "" {
+		client.SetHeader(SDKVersionHeader, cfg.SDKVersion)
+	}
+
 	// Authentication
 	if cfg.PublicKey != "" && cfg.SecretKey != "" {
 		client.SetBasicAuth(cfg.PublicKey, cfg.SecretKey)
 	}
 
 	// Retry configuration using resty's built-in retry
-	if cfg.RetryCount > 0 {
+	if httpRetries, _ := cfg.RetryAttempts(); httpRetries > 0 {
 		client.
-			SetRetryCount(cfg.RetryCount).
+			SetRetryCount(httpRetries).
 			SetRetryWaitTime(cfg.RetryDelay).
 			SetRetryMaxWaitTime(cfg.MaxRetryDelay).
 			AddRetryCondition(createRetryCondition(cfg))
 	}
 
+	// A custom transport, such as a recording one, replaces resty's default
+	if cfg.HTTPTransport != nil {
+		client.SetTransport(cfg.HTTPTransport)
+	}
+
+	// Connection pool and keep-alive tuning
+	if err := configureTransport(client, cfg); err != nil {
+		return err
+	}
+
 	// Debug mode
 	if cfg.Debug {
 		client.SetDebug(true)
 	}
 
+	// Headers carried by the request context, registered first so that user-supplied
+	// interceptors can still override them
+	client.OnBeforeRequest(applyContextHeaders)
+
+	// User-supplied interceptors. Response interceptors are registered ahead of the
+	// error handler so they also observe failed responses.
+	for _, interceptor := range cfg.RequestInterceptors {
+		client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+			if err := interceptor(r); err != nil {
+				return &interceptorError{err: err}
+			}
+			return nil
+		})
+	}
+	for _, interceptor := range cfg.ResponseInterceptors {
+		client.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+			if err := interceptor(r); err != nil {
+				return &interceptorError{err: err}
+			}
+			return nil
+		})
+	}
+
 	// Error handling middleware
 	client.OnAfterResponse(createErrorHandler())
 
 	return nil
-}
\ No newline at end of file
+}
+// defaultDialTimeout matches the dial timeout of resty's default transport
+const defaultDialTimeout = 30 * time.Second
+
+// configureTransport applies connection pool and TCP keep-alive settings to the client's
+// HTTP transport. Unset values keep the transport defaults.
+func configureTransport(client *resty.Client, cfg *config.Config) error {
+	if cfg.MaxConnsPerHost == 0 && cfg.MaxIdleConns == 0 && cfg.IdleConnTimeout == 0 && cfg.TCPKeepAlive == 0 {
+		return nil
+	}
+
+	transport, err := client.Transport()
+	if err != nil {
+		return fmt.Errorf("cannot tune connection pool: %w", err)
+	}
+
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.MaxIdleConns > 0 {
+		// Every request goes to the same host, so the per-host limit is the one that matters
+		transport.MaxIdleConns = cfg.MaxIdleConns
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TCPKeepAlive != 0 {
+		dialer := &net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: cfg.TCPKeepAlive,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	return nil
+}
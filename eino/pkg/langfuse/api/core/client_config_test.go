@@ -1,6 +1,12 @@
 package core
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -128,4 +134,198 @@ func TestConfigureRestyClientDefaults(t *testing.T) {
 	if client.BaseURL != config.Host {
 		t.Errorf("Expected base URL '%s', got '%s'", config.Host, client.BaseURL)
 	}
-}
\ No newline at end of file
+}
+
+func TestConfigureRestyClientAPIVersion(t *testing.T) {
+	var gotVersion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVersion = r.Header.Get(APIVersionHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+
+	client := resty.New()
+	if err := ConfigureRestyClient(client, cfg); err != nil {
+		t.Fatalf("ConfigureRestyClient() failed: %v", err)
+	}
+	if _, err := client.R().Get("/api/public/health"); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if gotVersion != config.APIVersionV1 {
+		t.Errorf("Expected %s header '%s', got '%s'", APIVersionHeader, config.APIVersionV1, gotVersion)
+	}
+}
+
+func TestConfigureRestyClientInterceptors(t *testing.T) {
+	secret := []byte("proxy-secret")
+	sign := func(method, path string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(method + " " + path))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Signature")
+		w.Header().Set("X-Upstream", "mock")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var sawUpstream string
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	for _, opt := range []config.ConfigOption{
+		config.WithRequestInterceptor(func(r *resty.Request) error {
+			r.SetHeader("X-Signature", sign(r.Method, r.URL))
+			return nil
+		}),
+		config.WithResponseInterceptor(func(r *resty.Response) error {
+			sawUpstream = r.Header().Get("X-Upstream")
+			return nil
+		}),
+	} {
+		if err := opt(cfg); err != nil {
+			t.Fatalf("option failed: %v", err)
+		}
+	}
+
+	client := resty.New()
+	if err := ConfigureRestyClient(client, cfg); err != nil {
+		t.Fatalf("ConfigureRestyClient() failed: %v", err)
+	}
+
+	if _, err := client.R().Get("/api/public/health"); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if want := sign(http.MethodGet, "/api/public/health"); gotSignature != want {
+		t.Errorf("Expected signature '%s', got '%s'", want, gotSignature)
+	}
+	if sawUpstream != "mock" {
+		t.Errorf("Expected response interceptor to see upstream header, got '%s'", sawUpstream)
+	}
+}
+
+func TestConfigureRestyClientInterceptorAbortsRequest(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	errDenied := errors.New("signing key unavailable")
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	if err := config.WithRequestInterceptor(func(r *resty.Request) error {
+		return errDenied
+	})(cfg); err != nil {
+		t.Fatalf("option failed: %v", err)
+	}
+
+	client := resty.New()
+	if err := ConfigureRestyClient(client, cfg); err != nil {
+		t.Fatalf("ConfigureRestyClient() failed: %v", err)
+	}
+
+	_, err := client.R().Get("/api/public/health")
+	if !errors.Is(err, errDenied) {
+		t.Errorf("Expected interceptor error, got %v", err)
+	}
+	if called {
+		t.Error("Expected request to be aborted before reaching the server")
+	}
+
+	if err := config.WithRequestInterceptor(nil)(cfg); err == nil {
+		t.Error("Expected error for nil interceptor")
+	}
+}
+
+func TestConfigureRestyClientConnectionPool(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, opt := range []config.ConfigOption{
+		config.WithConnectionPoolConfig(64, 16, 45*time.Second),
+		config.WithKeepAlive(true, 20*time.Second),
+	} {
+		if err := opt(cfg); err != nil {
+			t.Fatalf("option failed: %v", err)
+		}
+	}
+
+	client := resty.New()
+	if err := ConfigureRestyClient(client, cfg); err != nil {
+		t.Fatalf("ConfigureRestyClient() failed: %v", err)
+	}
+
+	transport, err := client.Transport()
+	if err != nil {
+		t.Fatalf("Transport() failed: %v", err)
+	}
+	if transport.MaxConnsPerHost != 64 {
+		t.Errorf("Expected MaxConnsPerHost 64, got %d", transport.MaxConnsPerHost)
+	}
+	if transport.MaxIdleConns != 16 || transport.MaxIdleConnsPerHost != 16 {
+		t.Errorf("Expected 16 idle connections, got %d (%d per host)", transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 45*time.Second {
+		t.Errorf("Expected IdleConnTimeout 45s, got %s", transport.IdleConnTimeout)
+	}
+	if transport.DialContext == nil {
+		t.Error("Expected keep-alive dialer to be installed")
+	}
+}
+
+func TestConfigureRestyClientKeepsTransportDefaults(t *testing.T) {
+	client := resty.New()
+	before, err := client.Transport()
+	if err != nil {
+		t.Fatalf("Transport() failed: %v", err)
+	}
+	maxIdle := before.MaxIdleConnsPerHost
+
+	if err := ConfigureRestyClient(client, config.DefaultConfig()); err != nil {
+		t.Fatalf("ConfigureRestyClient() failed: %v", err)
+	}
+
+	after, _ := client.Transport()
+	if after.MaxConnsPerHost != 0 || after.MaxIdleConnsPerHost != maxIdle {
+		t.Errorf("Expected transport defaults to be kept, got MaxConnsPerHost=%d MaxIdleConnsPerHost=%d", after.MaxConnsPerHost, after.MaxIdleConnsPerHost)
+	}
+}
+
+func TestWithConnectionPoolConfigValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxConns     int
+		maxIdleConns int
+		idleTimeout  time.Duration
+		wantErr      bool
+	}{
+		{name: "valid", maxConns: 10, maxIdleConns: 5, idleTimeout: time.Minute},
+		{name: "equal limits", maxConns: 1, maxIdleConns: 1},
+		{name: "no idle connections", maxConns: 10, maxIdleConns: 0, wantErr: true},
+		{name: "fewer connections than idle", maxConns: 4, maxIdleConns: 5, wantErr: true},
+		{name: "negative idle timeout", maxConns: 10, maxIdleConns: 5, idleTimeout: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			err := config.WithConnectionPoolConfig(tt.maxConns, tt.maxIdleConns, tt.idleTimeout)(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithConnectionPoolConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.MaxConnsPerHost = 2
+	cfg.MaxIdleConns = 5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected Validate() to reject more idle connections than connections")
+	}
+}
@@ -1,10 +1,12 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/go-resty/resty/v2"
 
+	commonErrors "eino/pkg/langfuse/api/resources/commons/errors"
 	"eino/pkg/langfuse/config"
 )
 
@@ -12,6 +14,11 @@ import (
 func createRetryCondition(cfg *config.Config) resty.RetryConditionFunc {
 	return func(r *resty.Response, err error) bool {
 		if err != nil {
+			// Interceptor failures are deliberate aborts, not transient errors
+			var ie *interceptorError
+			if errors.As(err, &ie) {
+				return false
+			}
 			return true // Retry on network errors
 		}
 
@@ -21,6 +28,19 @@ func createRetryCondition(cfg *config.Config) resty.RetryConditionFunc {
 	}
 }
 
+// interceptorError wraps an error returned by a user-supplied interceptor
+type interceptorError struct {
+	err error
+}
+
+func (e *interceptorError) Error() string {
+	return fmt.Sprintf("interceptor aborted request: %v", e.err)
+}
+
+func (e *interceptorError) Unwrap() error {
+	return e.err
+}
+
 // parseHTTPError parses HTTP error responses
 func parseHTTPError(resp *resty.Response) error {
 	statusCode := resp.StatusCode()
@@ -30,7 +50,7 @@ func parseHTTPError(resp *resty.Response) error {
 	case 400:
 		return fmt.Errorf("bad request: %s", body)
 	case 401:
-		return fmt.Errorf("unauthorized: %s", body)
+		return commonErrors.NewUnauthorizedError(body)
 	case 403:
 		return fmt.Errorf("forbidden: %s", body)
 	case 404:
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Headers sent to help correlate SDK requests with application requests and to debug
+// them server-side
+const (
+	RequestIDHeader  = "X-Request-ID"
+	SDKNameHeader    = "X-Langfuse-Sdk-Name"
+	SDKVersionHeader = "X-Langfuse-Sdk-Version"
+	SDKMethodHeader  = "X-Langfuse-SDK-Method"
+)
+
+// requestHeadersKey is the context key of the headers added with WithHeader
+type requestHeadersKey struct{}
+
+// WithHeader returns a context carrying an extra header for every API request made with
+// it. The header map is copied, never modified in place, so contexts derived from the
+// same parent do not see each other's headers.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	current := RequestHeaders(ctx)
+	headers := make(http.Header, len(current)+1)
+	for k, v := range current {
+		headers[k] = v
+	}
+	headers.Set(key, value)
+	return context.WithValue(ctx, requestHeadersKey{}, headers)
+}
+
+// RequestHeaders returns the headers added to ctx with WithHeader, or nil. The result
+// must not be modified.
+func RequestHeaders(ctx context.Context) http.Header {
+	if ctx == nil {
+		return nil
+	}
+	headers, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return headers
+}
+
+// applyContextHeaders sets the headers carried by the request's context and the name of
+// the resource client method making the request
+func applyContextHeaders(c *resty.Client, r *resty.Request) error {
+	for key, values := range RequestHeaders(r.Context()) {
+		for i, value := range values {
+			if i == 0 {
+				r.Header.Set(key, value)
+			} else {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+
+	if method := callingMethod(); method != "" {
+		r.Header.Set(SDKMethodHeader, method)
+	}
+	return nil
+}
+
+// resourcesPackagePrefix is the import path prefix of the resource client packages
+var resourcesPackagePrefix = path.Dir(reflect.TypeOf(CircuitBreaker{}).PkgPath()) + "/resources/"
+
+// callingMethod returns the innermost exported resource client method on the stack,
+// such as "traces.List", or "" when the request was not made by a resource client
+func callingMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, resourcesPackagePrefix); ok {
+			// name looks like "traces.(*Client).List" or "traces.(*Client).List.func1"
+			pkg, rest, _ := strings.Cut(name, ".")
+			parts := strings.Split(rest, ".")
+			if len(parts) >= 2 {
+				method := parts[1]
+				if method != "" && unicode.IsUpper(rune(method[0])) {
+					return path.Base(pkg) + "." + method
+				}
+			}
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"context"
+
+	"eino/pkg/langfuse/api/core"
+)
+
+// WithRequestID returns a context whose API requests carry id in the X-Request-ID
+// header, so gateway logs can be correlated with the application request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return core.WithHeader(ctx, core.RequestIDHeader, id)
+}
+
+// WithHeader returns a context whose API requests carry an extra header. Headers only
+// apply to requests made with the returned context or contexts derived from it.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	return core.WithHeader(ctx, key, value)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/core"
+)
+
+// headerRecorder serves traces and sessions by ID and records the request headers by path
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers map[string]http.Header
+}
+
+func (h *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.headers[r.URL.Path] = r.Header.Clone()
+	h.mu.Unlock()
+
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id": %q}`, id)
+}
+
+func (h *headerRecorder) get(path string) http.Header {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.headers[path]
+}
+
+func TestContextHeaders(t *testing.T) {
+	recorder := &headerRecorder{headers: make(map[string]http.Header)}
+	client := newRoundTripTestClient(t, recorder.ServeHTTP)
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	ctx = WithHeader(ctx, "X-Tenant", "acme")
+
+	_, err := client.Traces.Get(ctx, "trace-1")
+	require.NoError(t, err)
+	_, err = client.Sessions.Get(ctx, "session-1")
+	require.NoError(t, err)
+
+	traceHeaders := recorder.get("/api/public/traces/trace-1")
+	assert.Equal(t, "req-123", traceHeaders.Get(core.RequestIDHeader))
+	assert.Equal(t, "acme", traceHeaders.Get("X-Tenant"))
+	assert.Equal(t, "traces.Get", traceHeaders.Get(core.SDKMethodHeader))
+	assert.Equal(t, "langfuse-go", traceHeaders.Get(core.SDKNameHeader))
+	assert.Equal(t, "1.0.0", traceHeaders.Get(core.SDKVersionHeader))
+
+	sessionHeaders := recorder.get("/api/public/sessions/session-1")
+	assert.Equal(t, "req-123", sessionHeaders.Get(core.RequestIDHeader))
+	assert.Equal(t, "acme", sessionHeaders.Get("X-Tenant"))
+	assert.Equal(t, "sessions.Get", sessionHeaders.Get(core.SDKMethodHeader))
+
+	// Requests made without the context do not carry its headers
+	_, err = client.Traces.Get(context.Background(), "trace-2")
+	require.NoError(t, err)
+	plainHeaders := recorder.get("/api/public/traces/trace-2")
+	assert.Empty(t, plainHeaders.Get(core.RequestIDHeader))
+	assert.Empty(t, plainHeaders.Get("X-Tenant"))
+	assert.Equal(t, "traces.Get", plainHeaders.Get(core.SDKMethodHeader))
+}
+
+func TestContextHeaders_DoNotLeak(t *testing.T) {
+	recorder := &headerRecorder{headers: make(map[string]http.Header)}
+	client := newRoundTripTestClient(t, recorder.ServeHTTP)
+
+	parent := WithHeader(context.Background(), "X-Tenant", "acme")
+	first := WithRequestID(parent, "req-1")
+	second := WithHeader(parent, "X-Other", "value")
+	assert.Empty(t, core.RequestHeaders(parent).Get(core.RequestIDHeader), "deriving a context leaves the parent untouched")
+	assert.Empty(t, core.RequestHeaders(second).Get(core.RequestIDHeader), "sibling contexts do not share headers")
+	assert.Empty(t, core.RequestHeaders(first).Get("X-Other"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("trace-%d", i)
+			_, err := client.Traces.Get(WithRequestID(parent, id), id)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		headers := recorder.get("/api/public/traces/" + id)
+		require.NotNil(t, headers)
+		assert.Equal(t, id, headers.Get(core.RequestIDHeader))
+		assert.Equal(t, "acme", headers.Get("X-Tenant"))
+	}
+}
@@ -0,0 +1,205 @@
+// Package auditlogs reads the organization audit trail. The audit log is only
+// available to organization-admin credentials; other credentials get
+// ErrOrganizationAdminRequired.
+package auditlogs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"eino/pkg/langfuse/api/resources/auditlogs/types"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	auditLogsBasePath = "/api/public/audit-logs"
+
+	// pageSize is the page size used when collecting every matching event
+	pageSize = 100
+)
+
+// ErrOrganizationAdminRequired is returned when the API rejects the credentials because
+// they are not organization-admin credentials
+var ErrOrganizationAdminRequired = errors.New("audit logs require organization-admin credentials")
+
+// Client handles audit log API operations
+type Client struct {
+	client *resty.Client
+}
+
+// NewClient creates a new audit logs client
+func NewClient(client *resty.Client) *Client {
+	return &Client{
+		client: client,
+	}
+}
+
+// List retrieves a page of audit log events based on the provided filters
+func (c *Client) List(ctx context.Context, req *types.GetAuditLogsRequest) (*types.GetAuditLogsResponse, error) {
+	if req == nil {
+		req = &types.GetAuditLogsRequest{}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+
+	// Build query parameters
+	queryParams := make(map[string]string)
+
+	if req.Page != nil {
+		queryParams["page"] = strconv.Itoa(*req.Page)
+	}
+
+	if req.Limit != nil {
+		queryParams["limit"] = strconv.Itoa(*req.Limit)
+	}
+
+	if req.Action != nil {
+		queryParams["action"] = *req.Action
+	}
+
+	if req.ActorID != nil {
+		queryParams["actorId"] = *req.ActorID
+	}
+
+	if req.ActorType != nil {
+		queryParams["actorType"] = *req.ActorType
+	}
+
+	if req.ResourceType != nil {
+		queryParams["resourceType"] = *req.ResourceType
+	}
+
+	if req.ResourceID != nil {
+		queryParams["resourceId"] = *req.ResourceID
+	}
+
+	if req.FromTimestamp != nil {
+		queryParams["fromTimestamp"] = req.FromTimestamp.Format("2006-01-02T15:04:05.000Z")
+	}
+
+	if req.ToTimestamp != nil {
+		queryParams["toTimestamp"] = req.ToTimestamp.Format("2006-01-02T15:04:05.000Z")
+	}
+
+	response := &types.GetAuditLogsResponse{}
+
+	request := c.client.R().
+		SetContext(ctx).
+		SetResult(response)
+
+	// Add query parameters
+	for key, value := range queryParams {
+		request.SetQueryParam(key, value)
+	}
+
+	resp, err := request.Get(auditLogsBasePath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusUnauthorized || resp.StatusCode() == http.StatusForbidden:
+		return nil, fmt.Errorf("failed to list audit logs: %w", ErrOrganizationAdminRequired)
+	case resp.IsError():
+		return nil, fmt.Errorf("failed to list audit logs: unexpected status %d", resp.StatusCode())
+	}
+
+	return response, nil
+}
+
+// GetByActor retrieves every audit log event performed by an actor
+func (c *Client) GetByActor(ctx context.Context, actorID string) ([]types.AuditLogEvent, error) {
+	if actorID == "" {
+		return nil, fmt.Errorf("actor ID cannot be empty")
+	}
+
+	var events []types.AuditLogEvent
+	err := c.forEachPage(ctx, &types.GetAuditLogsRequest{ActorID: &actorID}, func(page []types.AuditLogEvent) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetByResource retrieves every audit log event on a resource
+func (c *Client) GetByResource(ctx context.Context, resourceType, resourceID string) ([]types.AuditLogEvent, error) {
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+
+	if resourceID == "" {
+		return nil, fmt.Errorf("resource ID cannot be empty")
+	}
+
+	var events []types.AuditLogEvent
+	err := c.forEachPage(ctx, &types.GetAuditLogsRequest{ResourceType: &resourceType, ResourceID: &resourceID}, func(page []types.AuditLogEvent) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Export writes every audit log event matching req to w as JSON Lines, one event per
+// line, fetching one page at a time so large trails are not held in memory. The
+// request's Page is ignored and Limit sets the page size.
+func (c *Client) Export(ctx context.Context, req *types.GetAuditLogsRequest, w io.Writer) error {
+	if w == nil {
+		return fmt.Errorf("writer cannot be nil")
+	}
+
+	encoder := json.NewEncoder(w)
+	return c.forEachPage(ctx, req, func(page []types.AuditLogEvent) error {
+		for i := range page {
+			if err := encoder.Encode(&page[i]); err != nil {
+				return fmt.Errorf("failed to write audit log event %s: %w", page[i].ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// forEachPage calls fn with each page of events matching filter. The filter is copied,
+// so the caller's Page and Limit are left untouched.
+func (c *Client) forEachPage(ctx context.Context, filter *types.GetAuditLogsRequest, fn func(page []types.AuditLogEvent) error) error {
+	req := types.GetAuditLogsRequest{}
+	if filter != nil {
+		req = *filter
+	}
+
+	limit := pageSize
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+	req.Limit = &limit
+
+	for page := 1; ; page++ {
+		pageNum := page
+		req.Page = &pageNum
+		resp, err := c.List(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(resp.Data); err != nil {
+			return err
+		}
+
+		if len(resp.Data) < limit || (resp.Meta.TotalPages > 0 && page >= resp.Meta.TotalPages) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package auditlogs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/auditlogs/types"
+	paginationTypes "eino/pkg/langfuse/api/resources/utils/pagination/types"
+)
+
+// auditLogServer serves count events, paginated, and records the query of each request
+func auditLogServer(t *testing.T, count int, queries *[]map[string]string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, auditLogsBasePath, r.URL.Path)
+		query := map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		*queries = append(*queries, query)
+
+		page, _ := strconv.Atoi(query["page"])
+		limit, _ := strconv.Atoi(query["limit"])
+		response := types.GetAuditLogsResponse{Meta: paginationTypes.MetaResponse{Page: page, Limit: limit, TotalItems: count, TotalPages: (count + limit - 1) / limit}}
+		for i := (page - 1) * limit; i < count && i < page*limit; i++ {
+			response.Data = append(response.Data, types.AuditLogEvent{
+				ID:           fmt.Sprintf("event-%d", i),
+				Action:       "project.update",
+				ActorID:      query["actorId"],
+				ActorType:    "user",
+				ResourceType: "project",
+				ResourceID:   "project-1",
+				Timestamp:    time.Date(2024, 3, 15, 10, i, 0, 0, time.UTC),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(resty.New().SetBaseURL(server.URL))
+}
+
+func TestClient_List(t *testing.T) {
+	var queries []map[string]string
+	client := auditLogServer(t, 3, &queries)
+
+	page, limit := 1, 10
+	action := "project.update"
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	response, err := client.List(context.Background(), &types.GetAuditLogsRequest{Page: &page, Limit: &limit, Action: &action, FromTimestamp: &from})
+	require.NoError(t, err)
+	assert.Len(t, response.Data, 3)
+	assert.Equal(t, 3, response.Meta.TotalItems)
+	assert.Equal(t, map[string]string{
+		"page":          "1",
+		"limit":         "10",
+		"action":        "project.update",
+		"fromTimestamp": "2024-03-01T00:00:00.000Z",
+	}, queries[0])
+
+	t.Run("validation", func(t *testing.T) {
+		resourceID := "project-1"
+		_, err := client.List(context.Background(), &types.GetAuditLogsRequest{ResourceID: &resourceID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resourceType")
+		assert.Len(t, queries, 1)
+	})
+}
+
+func TestClient_GetByActorAndResource(t *testing.T) {
+	ctx := context.Background()
+	var queries []map[string]string
+	client := auditLogServer(t, 250, &queries)
+
+	events, err := client.GetByActor(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, events, 250)
+	assert.Equal(t, "event-249", events[249].ID)
+	require.Len(t, queries, 3, "every page is fetched")
+	assert.Equal(t, "user-1", queries[2]["actorId"])
+	assert.Equal(t, "3", queries[2]["page"])
+
+	queries = nil
+	events, err = client.GetByResource(ctx, "project", "project-1")
+	require.NoError(t, err)
+	assert.Len(t, events, 250)
+	assert.Equal(t, "project", queries[0]["resourceType"])
+	assert.Equal(t, "project-1", queries[0]["resourceId"])
+
+	_, err = client.GetByActor(ctx, "")
+	assert.Error(t, err)
+	_, err = client.GetByResource(ctx, "project", "")
+	assert.Error(t, err)
+}
+
+func TestClient_Export(t *testing.T) {
+	var queries []map[string]string
+	client := auditLogServer(t, 5, &queries)
+
+	limit := 2
+	req := &types.GetAuditLogsRequest{Limit: &limit}
+	var buf bytes.Buffer
+	require.NoError(t, client.Export(context.Background(), req, &buf))
+
+	var ids []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event types.AuditLogEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, []string{"event-0", "event-1", "event-2", "event-3", "event-4"}, ids)
+	assert.Len(t, queries, 3)
+	assert.Nil(t, req.Page, "the caller's request is not modified")
+}
+
+func TestClient_RequiresOrganizationAdmin(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+			client := NewClient(resty.New().SetBaseURL(server.URL))
+
+			_, err := client.List(context.Background(), nil)
+			assert.ErrorIs(t, err, ErrOrganizationAdminRequired)
+
+			err = client.Export(context.Background(), nil, &bytes.Buffer{})
+			assert.ErrorIs(t, err, ErrOrganizationAdminRequired)
+		})
+	}
+
+	t.Run("other errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		_, err := NewClient(resty.New().SetBaseURL(server.URL)).List(context.Background(), nil)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrOrganizationAdminRequired)
+		assert.Contains(t, err.Error(), "500")
+	})
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"time"
+
+	"eino/pkg/langfuse/api/resources/utils/pagination/types"
+)
+
+// AuditLogEvent represents an entry of the organization audit trail
+type AuditLogEvent struct {
+	// Unique identifier for the event
+	ID string `json:"id"`
+
+	// Action performed, e.g. "project.create" or "apiKey.delete"
+	Action string `json:"action"`
+
+	// Identifier of the user or API key that performed the action
+	ActorID string `json:"actorId"`
+
+	// Kind of actor, e.g. "user" or "apiKey"
+	ActorType string `json:"actorType"`
+
+	// Kind of resource acted on, e.g. "project" or "membership"
+	ResourceType string `json:"resourceType"`
+
+	// Identifier of the resource acted on
+	ResourceID string `json:"resourceId"`
+
+	// Additional details, such as the state before and after the action
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Timestamp when the action was performed
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// GetAuditLogsRequest represents a request to list audit log events
+type GetAuditLogsRequest struct {
+	Page          *int       `json:"page,omitempty"`
+	Limit         *int       `json:"limit,omitempty"`
+	Action        *string    `json:"action,omitempty"`
+	ActorID       *string    `json:"actorId,omitempty"`
+	ActorType     *string    `json:"actorType,omitempty"`
+	ResourceType  *string    `json:"resourceType,omitempty"`
+	ResourceID    *string    `json:"resourceId,omitempty"`
+	FromTimestamp *time.Time `json:"fromTimestamp,omitempty"`
+	ToTimestamp   *time.Time `json:"toTimestamp,omitempty"`
+}
+
+// GetAuditLogsResponse represents the response from listing audit log events
+type GetAuditLogsResponse struct {
+	Data []AuditLogEvent    `json:"data"`
+	Meta types.MetaResponse `json:"meta"`
+}
+
+// ValidationError represents a validation error
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate validates the GetAuditLogsRequest
+func (req *GetAuditLogsRequest) Validate() error {
+	if req.Limit != nil && (*req.Limit < 1 || *req.Limit > 1000) {
+		return &ValidationError{Field: "limit", Message: "limit must be between 1 and 1000"}
+	}
+
+	if req.Page != nil && *req.Page < 1 {
+		return &ValidationError{Field: "page", Message: "page must be greater than 0"}
+	}
+
+	if req.ResourceID != nil && req.ResourceType == nil {
+		return &ValidationError{Field: "resourceId", Message: "resourceId requires resourceType"}
+	}
+
+	if req.FromTimestamp != nil && req.ToTimestamp != nil && req.FromTimestamp.After(*req.ToTimestamp) {
+		return &ValidationError{Field: "timestamps", Message: "fromTimestamp cannot be after toTimestamp"}
+	}
+
+	return nil
+}
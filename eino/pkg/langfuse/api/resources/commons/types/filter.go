@@ -0,0 +1,54 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FilterQueryParam is the query parameter carrying the JSON filter of list endpoints
+const FilterQueryParam = "filter"
+
+// FilterCondition is one condition of the JSON filter accepted by the list endpoints
+type FilterCondition struct {
+	Type     string `json:"type"`
+	Column   string `json:"column"`
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// EncodeMetadataFilter encodes metadata key/value pairs as the value of the filter query
+// parameter, one equality condition on the metadata column per pair. Conditions are
+// ordered by key so the same filter always encodes the same way.
+func EncodeMetadataFilter(metadata map[string]string) (string, error) {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if key == "" {
+			return "", fmt.Errorf("metadata filter key cannot be empty")
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]FilterCondition, 0, len(keys))
+	for _, key := range keys {
+		conditions = append(conditions, FilterCondition{
+			Type:     "stringObject",
+			Column:   "metadata",
+			Key:      key,
+			Operator: "=",
+			Value:    metadata[key],
+		})
+	}
+
+	// Values are sent as typed, without escaping HTML characters such as &
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(conditions); err != nil {
+		return "", fmt.Errorf("failed to encode metadata filter: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
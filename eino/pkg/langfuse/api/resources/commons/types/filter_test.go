@@ -0,0 +1,23 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeMetadataFilter(t *testing.T) {
+	filter, err := EncodeMetadataFilter(map[string]string{"tenant": "a&b <c>", "region": "eu"})
+	require.NoError(t, err)
+	assert.Equal(t, `[{"type":"stringObject","column":"metadata","key":"region","operator":"=","value":"eu"},`+
+		`{"type":"stringObject","column":"metadata","key":"tenant","operator":"=","value":"a&b <c>"}]`, filter)
+
+	var conditions []FilterCondition
+	require.NoError(t, json.Unmarshal([]byte(filter), &conditions))
+	assert.Equal(t, "a&b <c>", conditions[1].Value)
+
+	_, err = EncodeMetadataFilter(map[string]string{"": "acme"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMorePages is returned by Paginator.NextPage after the last page
+var ErrNoMorePages = errors.New("no more pages")
+
+// Paginator walks the pages of a list endpoint, fetching one page per NextPage call.
+// Create it with NewPagePaginator for endpoints numbered by page, or NewCursorPaginator
+// for endpoints returning the cursor of the next page. A Paginator is not safe for
+// concurrent use.
+//
+// Example:
+//
+//	paginator := types.NewPagePaginator(func(page, limit int) ([]types.Score, int, error) {
+//		resp, err := client.List(ctx, &scoretypes.GetScoresRequest{Page: &page, Limit: &limit})
+//		if err != nil {
+//			return nil, 0, err
+//		}
+//		return resp.Data, resp.Meta.TotalPages, nil
+//	}, 100)
+//	for paginator.HasNextPage() {
+//		scores, err := paginator.NextPage(ctx)
+//		// ...
+//	}
+type Paginator[T any] struct {
+	// fetch returns the next page and whether another one follows it
+	fetch func() ([]T, bool, error)
+	done  bool
+}
+
+// NewPagePaginator returns a Paginator over an endpoint numbered by page, from page 1.
+// fetcher returns the items of a page and the total number of pages, or 0 when the
+// endpoint does not report it. Iteration ends after the reported last page, an empty
+// page, or a page with fewer than limit items.
+func NewPagePaginator[T any](fetcher func(page, limit int) ([]T, int, error), limit int) *Paginator[T] {
+	page := 1
+	return &Paginator[T]{fetch: func() ([]T, bool, error) {
+		items, totalPages, err := fetcher(page, limit)
+		if err != nil {
+			return nil, false, err
+		}
+		more := len(items) > 0 && (limit <= 0 || len(items) >= limit) && (totalPages <= 0 || page < totalPages)
+		page++
+		return items, more, nil
+	}}
+}
+
+// NewCursorPaginator returns a Paginator over an endpoint returning the cursor of the
+// next page. fetcher is called with a nil cursor for the first page, then with the
+// cursor it returned for the previous page; a nil or empty cursor ends iteration.
+func NewCursorPaginator[T any](fetcher func(cursor *string) ([]T, *string, error)) *Paginator[T] {
+	var cursor *string
+	return &Paginator[T]{fetch: func() ([]T, bool, error) {
+		items, next, err := fetcher(cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		cursor = next
+		return items, next != nil && *next != "", nil
+	}}
+}
+
+// HasNextPage reports whether NextPage has another page to fetch
+func (p *Paginator[T]) HasNextPage() bool {
+	return !p.done
+}
+
+// NextPage fetches the next page. After a failed fetch the same page is fetched again
+// by the next call; after the last page it returns ErrNoMorePages.
+func (p *Paginator[T]) NextPage(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, ErrNoMorePages
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, more, err := p.fetch()
+	if err != nil {
+		return nil, err
+	}
+	p.done = !more
+	return items, nil
+}
+
+// All fetches the remaining pages and returns their items in order
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.HasNextPage() {
+		items, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedItems serves items in pages of limit, reporting totalPages when withTotal is set
+func pagedItems(items []int, withTotal bool, calls *[]int) func(page, limit int) ([]int, int, error) {
+	return func(page, limit int) ([]int, int, error) {
+		*calls = append(*calls, page)
+		start := min((page-1)*limit, len(items))
+		end := min(start+limit, len(items))
+		totalPages := 0
+		if withTotal {
+			totalPages = (len(items) + limit - 1) / limit
+		}
+		return items[start:end], totalPages, nil
+	}
+}
+
+func TestPagePaginator(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+
+	tests := []struct {
+		name      string
+		items     []int
+		withTotal bool
+		calls     []int
+	}{
+		{name: "short last page", items: items, calls: []int{1, 2, 3}},
+		{name: "total pages", items: items[:6], withTotal: true, calls: []int{1, 2}},
+		{name: "full last page without total", items: items[:6], calls: []int{1, 2, 3}},
+		{name: "empty", items: nil, calls: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []int
+			paginator := NewPagePaginator(pagedItems(tt.items, tt.withTotal, &calls), 3)
+
+			all, err := paginator.All(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.items, all)
+			assert.Equal(t, tt.calls, calls)
+			assert.False(t, paginator.HasNextPage())
+
+			_, err = paginator.NextPage(context.Background())
+			assert.ErrorIs(t, err, ErrNoMorePages)
+		})
+	}
+}
+
+func TestPagePaginator_NextPage(t *testing.T) {
+	var calls []int
+	fail := true
+	fetch := pagedItems([]int{1, 2, 3, 4}, true, &calls)
+	paginator := NewPagePaginator(func(page, limit int) ([]int, int, error) {
+		if page == 2 && fail {
+			fail = false
+			return nil, 0, errors.New("unavailable")
+		}
+		return fetch(page, limit)
+	}, 2)
+	ctx := context.Background()
+
+	page, err := paginator.NextPage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, page)
+	assert.True(t, paginator.HasNextPage())
+
+	_, err = paginator.NextPage(ctx)
+	assert.EqualError(t, err, "unavailable")
+	assert.True(t, paginator.HasNextPage())
+
+	page, err = paginator.NextPage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, page, "a failed page is fetched again")
+	assert.False(t, paginator.HasNextPage())
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewPagePaginator(fetch, 2).NextPage(canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCursorPaginator(t *testing.T) {
+	pages := map[string][]string{"": {"a", "b"}, "c1": {"c", "d"}, "c2": {"e"}}
+	next := map[string]string{"": "c1", "c1": "c2"}
+
+	var cursors []string
+	paginator := NewCursorPaginator(func(cursor *string) ([]string, *string, error) {
+		key := ""
+		if cursor != nil {
+			key = *cursor
+		}
+		cursors = append(cursors, key)
+		if n, ok := next[key]; ok {
+			return pages[key], &n, nil
+		}
+		return pages[key], nil, nil
+	})
+
+	all, err := paginator.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, all)
+	assert.Equal(t, []string{"", "c1", "c2"}, cursors)
+
+	empty := ""
+	paginator = NewCursorPaginator(func(cursor *string) ([]string, *string, error) {
+		return []string{"only"}, &empty, nil
+	})
+	all, err = paginator.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"only"}, all, "an empty cursor ends iteration")
+}
@@ -0,0 +1,44 @@
+package types
+
+const (
+	// SortByQueryParam is the query parameter carrying the sort field of list endpoints
+	SortByQueryParam = "sortBy"
+
+	// SortOrderQueryParam is the query parameter carrying the sort direction of list endpoints
+	SortOrderQueryParam = "order"
+)
+
+// SortField is a field list endpoints can sort by. Each endpoint supports a subset of the
+// fields, checked by the Validate method of its request.
+type SortField string
+
+const (
+	SortFieldTimestamp SortField = "timestamp"
+	SortFieldStartTime SortField = "startTime"
+	SortFieldValue     SortField = "value"
+	SortFieldName      SortField = "name"
+)
+
+// SortOrder is the direction list endpoints sort in
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+// IsValid reports whether the order is asc or desc
+func (o SortOrder) IsValid() bool {
+	return o == SortOrderAsc || o == SortOrderDesc
+}
+
+// SetSortQueryParams adds the sort field and order to queryParams, skipping unset values
+// so the API's default ordering applies
+func SetSortQueryParams(queryParams map[string]string, field SortField, order SortOrder) {
+	if field != "" {
+		queryParams[SortByQueryParam] = string(field)
+	}
+	if order != "" {
+		queryParams[SortOrderQueryParam] = string(order)
+	}
+}
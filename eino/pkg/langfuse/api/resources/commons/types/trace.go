@@ -81,8 +81,20 @@ type Trace struct {
 	// Release version
 	Release *string `json:"release,omitempty"`
 
+	// Environment the trace was recorded in, e.g. "production"
+	Environment *string `json:"environment,omitempty"`
+
 	// Whether the trace is public
 	Public *bool `json:"public,omitempty"`
+
+	// Whether the trace is bookmarked in the UI
+	Bookmarked *bool `json:"bookmarked,omitempty"`
+
+	// Latency of the trace in seconds, computed by the server and only set when reading traces
+	Latency *float64 `json:"latency,omitempty"`
+
+	// TotalCost of the trace's observations, computed by the server and only set when reading traces
+	TotalCost *float64 `json:"totalCost,omitempty"`
 }
 
 // TraceCreateRequest represents a request to create a new trace
@@ -0,0 +1,447 @@
+package datasets
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/datasets/types"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+const (
+	defaultIteratorPageSize = 50
+	cacheFileExtension      = ".jsonl"
+)
+
+// ErrStaleCursor is returned when a cursor was produced for a different version
+// of the dataset or with a different page size than the current iterator.
+var ErrStaleCursor = errors.New("cursor does not match the current dataset version")
+
+var unsafeCacheChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// IteratorOptions configures a DatasetIterator
+type IteratorOptions struct {
+	// PageSize is the number of items fetched per API call (default 50, max 1000)
+	PageSize int
+
+	// Cursor resumes iteration from a position previously returned by Cursor()
+	Cursor string
+
+	// CacheDir enables a local JSONL cache of fetched items. A complete pass over the
+	// dataset is written to CacheDir, keyed by dataset name and version (updatedAt), and
+	// later iterators over the same unchanged dataset read from it instead of the API.
+	CacheDir string
+}
+
+// iteratorCursor is the decoded form of a cursor string
+type iteratorCursor struct {
+	Version  string `json:"v"`
+	Page     int    `json:"p"`
+	Offset   int    `json:"o"`
+	PageSize int    `json:"s"`
+	Total    int    `json:"n"`
+}
+
+// DatasetIterator lazily pages through the items of a dataset.
+//
+// The number of items is pinned to the total observed when iteration starts, so items
+// added to the dataset mid-iteration are not returned. Typical usage:
+//
+//	it, err := datasets.NewIterator(ctx, client.Datasets, "eval-set", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		item := it.Item()
+//		// ... evaluate item, persist it.Cursor() to resume after a crash
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type DatasetIterator struct {
+	ctx         context.Context
+	client      *Client
+	datasetName string
+	pageSize    int
+	version     string
+	total       int
+
+	// position is the absolute index of the next item to return
+	position int
+	buffer   []commonTypes.DatasetItem
+	offset   int
+	skip     int
+	nextPage int
+	current  *commonTypes.DatasetItem
+	err      error
+	done     bool
+
+	cacheDir    string
+	fromCache   bool
+	cachedItems []commonTypes.DatasetItem
+	cacheFile   *os.File
+	cacheBuf    *bufio.Writer
+}
+
+// NewIterator creates an iterator over the items of the named dataset.
+// The dataset is fetched once up front to determine its version.
+func NewIterator(ctx context.Context, client *Client, datasetName string, opts *IteratorOptions) (*DatasetIterator, error) {
+	if client == nil {
+		return nil, fmt.Errorf("datasets client cannot be nil")
+	}
+	if datasetName == "" {
+		return nil, fmt.Errorf("dataset name cannot be empty")
+	}
+	if opts == nil {
+		opts = &IteratorOptions{}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = defaultIteratorPageSize
+	}
+	if pageSize < 1 || pageSize > 1000 {
+		return nil, &types.ValidationError{Field: "pageSize", Message: "page size must be between 1 and 1000"}
+	}
+
+	dataset, err := client.Get(ctx, datasetName)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &DatasetIterator{
+		ctx:         ctx,
+		client:      client,
+		datasetName: datasetName,
+		pageSize:    pageSize,
+		version:     dataset.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		total:       -1,
+		nextPage:    1,
+		cacheDir:    opts.CacheDir,
+	}
+
+	if opts.Cursor != "" {
+		if err := it.Seek(opts.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	if it.cacheDir != "" {
+		if err := it.openCache(); err != nil {
+			return nil, err
+		}
+	}
+
+	return it, nil
+}
+
+// Next advances to the next item, returning false when iteration is complete or fails
+func (it *DatasetIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	if it.total >= 0 && it.position >= it.total {
+		it.finish()
+		return false
+	}
+
+	if it.offset >= len(it.buffer) {
+		if it.fromCache || !it.fetchPage() {
+			it.finish()
+			return false
+		}
+	}
+
+	item := it.buffer[it.offset]
+	it.current = &item
+	it.offset++
+	it.position++
+
+	if it.cacheBuf != nil {
+		if err := it.writeCacheItem(item); err != nil {
+			it.err = err
+			it.discardCache()
+			return false
+		}
+	}
+
+	return true
+}
+
+// Item returns the current item. It is only valid after a call to Next that returned true.
+func (it *DatasetIterator) Item() *commonTypes.DatasetItem {
+	return it.current
+}
+
+// Err returns the error, if any, that stopped iteration
+func (it *DatasetIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns an opaque string encoding the position after the current item.
+// Passing it to IteratorOptions.Cursor or Seek resumes iteration with the next item.
+func (it *DatasetIterator) Cursor() string {
+	c := iteratorCursor{
+		Version:  it.version,
+		Page:     it.position/it.pageSize + 1,
+		Offset:   it.position % it.pageSize,
+		PageSize: it.pageSize,
+		Total:    it.total,
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// CanSeek reports whether the cursor can be used to resume this iterator
+func (it *DatasetIterator) CanSeek(cursor string) bool {
+	_, err := it.decodeCursor(cursor)
+	return err == nil
+}
+
+// Seek repositions the iterator at the given cursor. Seeking discards any partially
+// written cache, since the cache is only persisted after a complete pass.
+func (it *DatasetIterator) Seek(cursor string) error {
+	c, err := it.decodeCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	it.discardCache()
+	it.position = (c.Page-1)*c.PageSize + c.Offset
+	it.total = c.Total
+	it.nextPage = c.Page
+	it.buffer = nil
+	it.offset = 0
+	it.skip = c.Offset
+	it.current = nil
+	it.done = false
+	it.err = nil
+
+	if it.fromCache {
+		// The whole dataset is already in memory; page boundaries do not apply
+		it.reloadFromCache()
+	}
+
+	return nil
+}
+
+// Total returns the pinned number of items, or -1 if no page has been fetched yet
+func (it *DatasetIterator) Total() int {
+	return it.total
+}
+
+// FromCache reports whether items are being served from the local cache
+func (it *DatasetIterator) FromCache() bool {
+	return it.fromCache
+}
+
+// Close releases resources held by the iterator. An incomplete cache file is removed.
+func (it *DatasetIterator) Close() error {
+	it.done = true
+	it.discardCache()
+	return nil
+}
+
+// fetchPage loads the next page from the API into the buffer
+func (it *DatasetIterator) fetchPage() bool {
+	page := it.nextPage
+	limit := it.pageSize
+
+	resp, err := it.client.ListItems(it.ctx, it.datasetName, &types.GetDatasetItemsRequest{
+		Page:  &page,
+		Limit: &limit,
+	})
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	// Pin the total on the first page; servers that omit meta leave iteration unbounded
+	// until an empty page is returned
+	if it.total < 0 && (resp.Meta.TotalItems > 0 || len(resp.Data) == 0) {
+		it.total = resp.Meta.TotalItems
+	}
+
+	// When resuming mid-page the already processed prefix is skipped
+	it.buffer = resp.Data
+	it.offset = it.skip
+	if it.offset > len(it.buffer) {
+		it.offset = len(it.buffer)
+	}
+	it.skip = 0
+	it.nextPage++
+
+	return it.offset < len(it.buffer)
+}
+
+// finish marks iteration as complete and persists the cache if a full pass was recorded
+func (it *DatasetIterator) finish() {
+	it.done = true
+	it.current = nil
+
+	if it.cacheBuf == nil {
+		return
+	}
+
+	if it.err != nil {
+		it.discardCache()
+		return
+	}
+
+	if err := it.cacheBuf.Flush(); err != nil {
+		it.err = fmt.Errorf("failed to write dataset cache: %w", err)
+		it.discardCache()
+		return
+	}
+
+	tmpPath := it.cacheFile.Name()
+	if err := it.cacheFile.Close(); err != nil {
+		it.err = fmt.Errorf("failed to write dataset cache: %w", err)
+		os.Remove(tmpPath)
+		it.cacheFile, it.cacheBuf = nil, nil
+		return
+	}
+	it.cacheFile, it.cacheBuf = nil, nil
+
+	if err := os.Rename(tmpPath, it.cachePath()); err != nil {
+		it.err = fmt.Errorf("failed to persist dataset cache: %w", err)
+		os.Remove(tmpPath)
+	}
+}
+
+// cachePath returns the cache file location for the current dataset version
+func (it *DatasetIterator) cachePath() string {
+	name := unsafeCacheChars.ReplaceAllString(it.datasetName, "_")
+	version := unsafeCacheChars.ReplaceAllString(it.version, "_")
+	return filepath.Join(it.cacheDir, name+"@"+version+cacheFileExtension)
+}
+
+// openCache either loads an existing cache for this version or starts recording a new one
+func (it *DatasetIterator) openCache() error {
+	items, err := readCacheFile(it.cachePath())
+	if err == nil {
+		it.fromCache = true
+		it.cachedItems = items
+		it.reloadFromCache()
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read dataset cache: %w", err)
+	}
+
+	// Only a pass that starts at the beginning can produce a complete cache
+	if it.position != 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(it.cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create dataset cache directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(it.cacheDir, filepath.Base(it.cachePath())+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create dataset cache: %w", err)
+	}
+
+	it.cacheFile = file
+	it.cacheBuf = bufio.NewWriter(file)
+	return nil
+}
+
+// reloadFromCache points the buffer at the cached items starting from the current position
+func (it *DatasetIterator) reloadFromCache() {
+	it.buffer = it.cachedItems
+	it.offset = it.position
+	if it.total < 0 || it.total > len(it.cachedItems) {
+		it.total = len(it.cachedItems)
+	}
+}
+
+// writeCacheItem appends a single item to the cache as a JSON line
+func (it *DatasetIterator) writeCacheItem(item commonTypes.DatasetItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode dataset item for cache: %w", err)
+	}
+	if _, err := it.cacheBuf.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write dataset cache: %w", err)
+	}
+	return nil
+}
+
+// discardCache abandons an in-progress cache file
+func (it *DatasetIterator) discardCache() {
+	if it.cacheFile == nil {
+		return
+	}
+	tmpPath := it.cacheFile.Name()
+	it.cacheFile.Close()
+	os.Remove(tmpPath)
+	it.cacheFile, it.cacheBuf = nil, nil
+}
+
+// decodeCursor parses and validates a cursor against the iterator state
+func (it *DatasetIterator) decodeCursor(cursor string) (*iteratorCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var c iteratorCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if c.Page < 1 || c.Offset < 0 || c.PageSize < 1 {
+		return nil, fmt.Errorf("invalid cursor: page %d, offset %d, page size %d", c.Page, c.Offset, c.PageSize)
+	}
+
+	if c.Version != it.version || c.PageSize != it.pageSize {
+		return nil, ErrStaleCursor
+	}
+
+	if c.Total >= 0 && (c.Page-1)*c.PageSize+c.Offset > c.Total {
+		return nil, fmt.Errorf("invalid cursor: position beyond total of %d items", c.Total)
+	}
+
+	return &c, nil
+}
+
+// readCacheFile loads all items from a JSONL cache file
+func readCacheFile(path string) ([]commonTypes.DatasetItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var items []commonTypes.DatasetItem
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item commonTypes.DatasetItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("corrupt cache line: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,236 @@
+package datasets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDatasetServer serves a single dataset whose items can be mutated during a test
+type fakeDatasetServer struct {
+	mu           sync.Mutex
+	items        []string
+	updatedAt    string
+	itemRequests int32
+}
+
+func newFakeDatasetServer(t *testing.T, itemCount int) (*fakeDatasetServer, *Client) {
+	fake := &fakeDatasetServer{updatedAt: "2024-01-15T12:00:00Z"}
+	for i := 0; i < itemCount; i++ {
+		fake.items = append(fake.items, fmt.Sprintf("item-%d", i))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/datasets/eval-set", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"ds-1","name":"eval-set","createdAt":"2024-01-01T00:00:00Z","updatedAt":%q}`, fake.updatedAt)
+	})
+	mux.HandleFunc("/api/public/datasets/eval-set/items", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fake.itemRequests, 1)
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start := (page - 1) * limit
+		end := start + limit
+		if start > len(fake.items) {
+			start = len(fake.items)
+		}
+		if end > len(fake.items) {
+			end = len(fake.items)
+		}
+
+		data := make([]map[string]interface{}, 0, end-start)
+		for _, id := range fake.items[start:end] {
+			data = append(data, map[string]interface{}{
+				"id":        id,
+				"datasetId": "ds-1",
+				"input":     map[string]string{"q": id},
+				"createdAt": "2024-01-01T00:00:00Z",
+				"updatedAt": "2024-01-01T00:00:00Z",
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": data,
+			"meta": map[string]int{"page": page, "limit": limit, "totalItems": len(fake.items)},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return fake, NewClient(resty.New().SetBaseURL(server.URL))
+}
+
+func (f *fakeDatasetServer) addItem(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = append(f.items, id)
+}
+
+func collectIDs(t *testing.T, it *DatasetIterator, max int) []string {
+	var ids []string
+	for (max < 0 || len(ids) < max) && it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+	require.NoError(t, it.Err())
+	return ids
+}
+
+func TestDatasetIterator_IteratesAllPages(t *testing.T) {
+	fake, client := newFakeDatasetServer(t, 6)
+
+	it, err := NewIterator(context.Background(), client, "eval-set", &IteratorOptions{PageSize: 2})
+	require.NoError(t, err)
+	defer it.Close()
+
+	ids := collectIDs(t, it, -1)
+	assert.Equal(t, []string{"item-0", "item-1", "item-2", "item-3", "item-4", "item-5"}, ids)
+	assert.Equal(t, 6, it.Total())
+	assert.False(t, it.Next())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&fake.itemRequests))
+}
+
+func TestDatasetIterator_ResumeFromCursor(t *testing.T) {
+	_, client := newFakeDatasetServer(t, 6)
+	ctx := context.Background()
+
+	first, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2})
+	require.NoError(t, err)
+
+	// Process two pages, then simulate a crash after saving the cursor
+	seen := collectIDs(t, first, 4)
+	cursor := first.Cursor()
+	first.Close()
+
+	resumed, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, Cursor: cursor})
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	seen = append(seen, collectIDs(t, resumed, -1)...)
+	assert.Equal(t, []string{"item-0", "item-1", "item-2", "item-3", "item-4", "item-5"}, seen)
+}
+
+func TestDatasetIterator_ResumeMidPage(t *testing.T) {
+	_, client := newFakeDatasetServer(t, 5)
+	ctx := context.Background()
+
+	first, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2})
+	require.NoError(t, err)
+	seen := collectIDs(t, first, 3)
+
+	resumed, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, Cursor: first.Cursor()})
+	require.NoError(t, err)
+	seen = append(seen, collectIDs(t, resumed, -1)...)
+
+	assert.Equal(t, []string{"item-0", "item-1", "item-2", "item-3", "item-4"}, seen)
+}
+
+func TestDatasetIterator_PinsTotal(t *testing.T) {
+	fake, client := newFakeDatasetServer(t, 4)
+
+	it, err := NewIterator(context.Background(), client, "eval-set", &IteratorOptions{PageSize: 2})
+	require.NoError(t, err)
+
+	ids := collectIDs(t, it, 1)
+	fake.addItem("late-item")
+	ids = append(ids, collectIDs(t, it, -1)...)
+
+	assert.Equal(t, []string{"item-0", "item-1", "item-2", "item-3"}, ids)
+	assert.Equal(t, 4, it.Total())
+}
+
+func TestDatasetIterator_CanSeek(t *testing.T) {
+	fake, client := newFakeDatasetServer(t, 4)
+	ctx := context.Background()
+
+	it, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2})
+	require.NoError(t, err)
+	collectIDs(t, it, 2)
+	cursor := it.Cursor()
+
+	assert.True(t, it.CanSeek(cursor))
+	assert.False(t, it.CanSeek("not-a-cursor"))
+
+	otherPageSize, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 3})
+	require.NoError(t, err)
+	assert.False(t, otherPageSize.CanSeek(cursor))
+
+	// A changed dataset invalidates old cursors
+	fake.mu.Lock()
+	fake.updatedAt = "2024-02-01T00:00:00Z"
+	fake.mu.Unlock()
+
+	_, err = NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, Cursor: cursor})
+	assert.ErrorIs(t, err, ErrStaleCursor)
+}
+
+func TestDatasetIterator_Cache(t *testing.T) {
+	fake, client := newFakeDatasetServer(t, 5)
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	first, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	assert.False(t, first.FromCache())
+	expected := collectIDs(t, first, -1)
+	require.Len(t, expected, 5)
+	requests := atomic.LoadInt32(&fake.itemRequests)
+
+	// An unchanged dataset is served entirely from the cache
+	second, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	assert.True(t, second.FromCache())
+	assert.Equal(t, expected, collectIDs(t, second, -1))
+	assert.Equal(t, requests, atomic.LoadInt32(&fake.itemRequests))
+
+	// Cursors work against cached items too
+	third, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	head := collectIDs(t, third, 3)
+	resumed, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir, Cursor: third.Cursor()})
+	require.NoError(t, err)
+	assert.Equal(t, expected, append(head, collectIDs(t, resumed, -1)...))
+	assert.Equal(t, requests, atomic.LoadInt32(&fake.itemRequests))
+
+	// Updating the dataset invalidates the cache
+	fake.mu.Lock()
+	fake.updatedAt = "2024-02-01T00:00:00Z"
+	fake.mu.Unlock()
+
+	fourth, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	assert.False(t, fourth.FromCache())
+	collectIDs(t, fourth, -1)
+	assert.Greater(t, atomic.LoadInt32(&fake.itemRequests), requests)
+}
+
+func TestDatasetIterator_PartialPassDoesNotCache(t *testing.T) {
+	_, client := newFakeDatasetServer(t, 4)
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	it, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	collectIDs(t, it, 2)
+	require.NoError(t, it.Close())
+
+	next, err := NewIterator(ctx, client, "eval-set", &IteratorOptions{PageSize: 2, CacheDir: cacheDir})
+	require.NoError(t, err)
+	assert.False(t, next.FromCache())
+}
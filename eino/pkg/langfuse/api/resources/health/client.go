@@ -26,10 +26,12 @@ func NewClient(client *resty.Client) *Client {
 	}
 }
 
-// Check performs a health check against the Langfuse API
+// Check performs a health check against the Langfuse API. Services reported without a
+// latency or check time get the round trip of the request and the time it completed.
 func (c *Client) Check(ctx context.Context) (*types.HealthResponse, error) {
 	response := &types.HealthResponse{}
 	
+	start := time.Now()
 	_, err := c.client.R().
 		SetContext(ctx).
 		SetResult(response).
@@ -39,6 +41,7 @@ func (c *Client) Check(ctx context.Context) (*types.HealthResponse, error) {
 		return nil, fmt.Errorf("health check request failed: %w", err)
 	}
 	
+	response.FillMissing(time.Since(start), time.Now())
 	return response, nil
 }
 
@@ -36,6 +36,24 @@ type ServiceHealth struct {
 	LastChecked time.Time    `json:"lastChecked,omitempty"`
 	Message     string       `json:"message,omitempty"`
 	ResponseTime *time.Duration `json:"responseTime,omitempty"`
+
+	// LatencyMs is the latency of the dependency in milliseconds
+	LatencyMs *float64 `json:"latencyMs,omitempty"`
+}
+
+// FillMissing sets LatencyMs and LastChecked of every service the server reported
+// without them, to the round trip of the health request and the time it completed
+func (hr *HealthResponse) FillMissing(roundTrip time.Duration, checkedAt time.Time) {
+	latencyMs := float64(roundTrip) / float64(time.Millisecond)
+	for name, service := range hr.Services {
+		if service.LatencyMs == nil {
+			service.LatencyMs = &latencyMs
+		}
+		if service.LastChecked.IsZero() {
+			service.LastChecked = checkedAt
+		}
+		hr.Services[name] = service
+	}
 }
 
 // IsHealthy returns true if the overall health status is healthy
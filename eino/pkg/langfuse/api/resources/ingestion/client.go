@@ -3,6 +3,9 @@ package ingestion
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -16,15 +19,83 @@ const (
 	healthBasePath    = "/api/public/health"
 )
 
+// SDK identification reported with every batch unless set with WithSDKInfo
+const (
+	defaultSDKName    = "langfuse-go"
+	defaultSDKVersion = "1.0.0"
+)
+
 // Client handles ingestion API operations
 type Client struct {
-	client *resty.Client
+	client      *resty.Client
+	sdkName     string
+	sdkVersion  string
+	skipInvalid bool
+
+	// warn receives the warning for each skipped invalid event (default log.Print)
+	warn func(message string)
+}
+
+// ClientOption configures the ingestion client
+type ClientOption func(*Client)
+
+// WithSDKInfo sets the SDK name and version reported in the metadata of every batch
+func WithSDKInfo(name, version string) ClientOption {
+	return func(c *Client) {
+		if name != "" {
+			c.sdkName = name
+		}
+		if version != "" {
+			c.sdkVersion = version
+		}
+	}
+}
+
+// WithSkipInvalidEvents makes SubmitBatch drop events missing a required field and log
+// them, instead of failing the whole batch
+func WithSkipInvalidEvents(skip bool) ClientOption {
+	return func(c *Client) {
+		c.skipInvalid = skip
+	}
 }
 
 // NewClient creates a new ingestion client
-func NewClient(client *resty.Client) *Client {
-	return &Client{
-		client: client,
+func NewClient(client *resty.Client, opts ...ClientOption) *Client {
+	c := &Client{
+		client:     client,
+		sdkName:    defaultSDKName,
+		sdkVersion: defaultSDKVersion,
+		warn:       func(message string) { log.Print(message) },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var (
+	hostnameOnce sync.Once
+	hostname     string
+)
+
+// injectTelemetry adds SDK and process information to the batch metadata so that
+// batches can be traced back to the pod and SDK build that sent them
+func (c *Client) injectTelemetry(req *types.IngestionRequest) {
+	hostnameOnce.Do(func() {
+		hostname, _ = os.Hostname()
+	})
+
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]interface{})
+	}
+	req.Metadata["sdk_name"] = c.sdkName
+	req.Metadata["sdk_version"] = c.sdkVersion
+	req.Metadata["sdk_language"] = "go"
+	req.Metadata["batch_id"] = utils.GenerateUUID()
+	req.Metadata["submitted_at"] = time.Now().UTC().Format(time.RFC3339)
+	req.Metadata["process_id"] = os.Getpid()
+	if hostname != "" {
+		req.Metadata["hostname"] = hostname
 	}
 }
 
@@ -65,8 +136,14 @@ func (c *Client) SubmitBatch(ctx context.Context, events []types.IngestionEvent)
 			len(events), types.MaxBatchSize)
 	}
 	
+	events, err := c.validateEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create request with metadata
 	req := types.NewIngestionRequest(events)
+	c.injectTelemetry(req)
 	
 	return c.Submit(ctx, req)
 }
@@ -82,12 +159,46 @@ func (c *Client) SubmitBatchWithMetadata(ctx context.Context, events []types.Ing
 			len(events), types.MaxBatchSize)
 	}
 	
+	events, err := c.validateEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create request with custom metadata
 	req := types.NewIngestionRequestWithMetadata(events, metadata)
+	c.injectTelemetry(req)
 	
 	return c.Submit(ctx, req)
 }
 
+// validateEvents checks the required fields of every event before the batch is sent, since
+// the server rejects a whole batch for a single malformed event. Invalid events fail the
+// batch, or are dropped and logged with WithSkipInvalidEvents.
+func (c *Client) validateEvents(events []types.IngestionEvent) ([]types.IngestionEvent, error) {
+	valid := make([]types.IngestionEvent, 0, len(events))
+	for i, event := range events {
+		err := event.Validate()
+		if err == nil {
+			valid = append(valid, event)
+			continue
+		}
+
+		if !c.skipInvalid {
+			return nil, fmt.Errorf("request validation failed: %w", &types.RequestValidationError{
+				Field:   fmt.Sprintf("batch[%d]", i),
+				Message: fmt.Sprintf("event %q is invalid: %v", event.ID, err),
+			})
+		}
+		c.warn(fmt.Sprintf("langfuse: skipping invalid ingestion event batch[%d] (id %q, type %q): %v",
+			i, event.ID, event.Type, err))
+	}
+
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("cannot submit batch: all %d events are invalid", len(events))
+	}
+	return valid, nil
+}
+
 // SubmitTrace submits a trace creation event
 func (c *Client) SubmitTrace(ctx context.Context, event *types.TraceCreateEvent) (*types.IngestionResponse, error) {
 	if event == nil {
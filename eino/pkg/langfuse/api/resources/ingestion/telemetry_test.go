@@ -0,0 +1,70 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func TestClient_SubmitBatch_InjectsTelemetry(t *testing.T) {
+	var metadata []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		metadata = append(metadata, body.Metadata)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success": true, "timestamp": "2024-01-15T12:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(resty.New().SetBaseURL(server.URL), WithSDKInfo("my-sdk", "2.3.4"))
+	events := []types.IngestionEvent{{
+		ID:        "event-1",
+		Type:      types.EventTypeTraceCreate,
+		Timestamp: time.Now(),
+		Body:      map[string]interface{}{"id": "trace-1", "name": "trace"},
+	}}
+
+	ctx := context.Background()
+	_, err := client.SubmitBatch(ctx, events)
+	require.NoError(t, err)
+	_, err = client.SubmitBatchWithMetadata(ctx, events, &types.IngestionBatchMetadata{ClientID: "worker-7"})
+	require.NoError(t, err)
+	require.Len(t, metadata, 2)
+
+	hostname, _ := os.Hostname()
+	for _, m := range metadata {
+		assert.Equal(t, "my-sdk", m["sdk_name"])
+		assert.Equal(t, "2.3.4", m["sdk_version"])
+		assert.Equal(t, "go", m["sdk_language"])
+		assert.EqualValues(t, os.Getpid(), m["process_id"])
+		assert.Equal(t, hostname, m["hostname"])
+		assert.NotEmpty(t, m["batch_id"])
+
+		submittedAt, err := time.Parse(time.RFC3339, m["submitted_at"].(string))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), submittedAt, time.Minute)
+	}
+	assert.NotEqual(t, metadata[0]["batch_id"], metadata[1]["batch_id"], "every batch gets its own ID")
+	assert.Equal(t, "worker-7", metadata[1]["client_id"], "caller metadata is kept")
+}
+
+func TestNewClient_DefaultSDKInfo(t *testing.T) {
+	client := NewClient(resty.New(), WithSDKInfo("", ""))
+
+	assert.Equal(t, defaultSDKName, client.sdkName)
+	assert.Equal(t, defaultSDKVersion, client.sdkVersion)
+}
@@ -0,0 +1,236 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// The New*IngestionEvent constructors wrap a typed event body into an IngestionEvent.
+// Unlike ToIngestionEvent they keep Type and Body consistent: each sets the body's event
+// type when it is empty, gives create events without an ID a generated one, stamps the
+// ingestion event with the current time, validates the body and stores it encoded as
+// JSON. The body is updated in place, so the caller sees the ID that was assigned.
+
+// NewTraceCreateIngestionEvent creates a trace-create ingestion event
+func NewTraceCreateIngestionEvent(event *TraceCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeTraceCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateTraceID()
+	}
+	setEventType(&event.Type, EventTypeTraceCreate)
+	return newIngestionEvent(event.ID, EventTypeTraceCreate, event)
+}
+
+// NewTraceUpdateIngestionEvent creates a trace-update ingestion event
+func NewTraceUpdateIngestionEvent(event *TraceUpdateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeTraceUpdate)
+	}
+	setEventType(&event.Type, EventTypeTraceUpdate)
+	return newIngestionEvent(event.ID, EventTypeTraceUpdate, event)
+}
+
+// NewObservationCreateIngestionEvent creates an observation-create ingestion event
+func NewObservationCreateIngestionEvent(event *ObservationCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeObservationCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateObservationID()
+	}
+	setEventType(&event.EventType, EventTypeObservationCreate)
+	return newIngestionEvent(event.ID, EventTypeObservationCreate, event)
+}
+
+// NewObservationUpdateIngestionEvent creates an observation-update ingestion event
+func NewObservationUpdateIngestionEvent(event *ObservationUpdateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeObservationUpdate)
+	}
+	setEventType(&event.EventType, EventTypeObservationUpdate)
+	return newIngestionEvent(event.ID, EventTypeObservationUpdate, event)
+}
+
+// NewSpanCreateIngestionEvent creates a span-create ingestion event
+func NewSpanCreateIngestionEvent(event *SpanCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeSpanCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateObservationID()
+	}
+	setEventType(&event.EventType, EventTypeSpanCreate)
+	return newIngestionEvent(event.ID, EventTypeSpanCreate, event)
+}
+
+// NewSpanUpdateIngestionEvent creates a span-update ingestion event
+func NewSpanUpdateIngestionEvent(event *SpanUpdateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeSpanUpdate)
+	}
+	setEventType(&event.EventType, EventTypeSpanUpdate)
+	return newIngestionEvent(event.ID, EventTypeSpanUpdate, event)
+}
+
+// NewGenerationCreateIngestionEvent creates a generation-create ingestion event
+func NewGenerationCreateIngestionEvent(event *GenerationCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeGenerationCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateObservationID()
+	}
+	setEventType(&event.EventType, EventTypeGenerationCreate)
+	return newIngestionEvent(event.ID, EventTypeGenerationCreate, event)
+}
+
+// NewGenerationUpdateIngestionEvent creates a generation-update ingestion event
+func NewGenerationUpdateIngestionEvent(event *GenerationUpdateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeGenerationUpdate)
+	}
+	setEventType(&event.EventType, EventTypeGenerationUpdate)
+	return newIngestionEvent(event.ID, EventTypeGenerationUpdate, event)
+}
+
+// NewEventCreateIngestionEvent creates an event-create ingestion event
+func NewEventCreateIngestionEvent(event *EventCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeEventCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateObservationID()
+	}
+	setEventType(&event.EventType, EventTypeEventCreate)
+	return newIngestionEvent(event.ID, EventTypeEventCreate, event)
+}
+
+// NewScoreCreateIngestionEvent creates a score-create ingestion event
+func NewScoreCreateIngestionEvent(event *ScoreCreateEvent) (IngestionEvent, error) {
+	if event == nil {
+		return IngestionEvent{}, nilBodyError(EventTypeScoreCreate)
+	}
+	if event.ID == "" {
+		event.ID = utils.GenerateScoreID()
+	}
+	setEventType(&event.EventType, EventTypeScoreCreate)
+	return newIngestionEvent(event.ID, EventTypeScoreCreate, event)
+}
+
+// NewIngestionEvent creates an ingestion event of eventType from one of the typed event
+// bodies, for callers that only know the event type at runtime. It fails when body is
+// not the body type of eventType, e.g. a score passed as a trace-create.
+func NewIngestionEvent(eventType EventType, body interface{}) (IngestionEvent, error) {
+	if bodyType, ok := typedBodyEventType(body); !ok || bodyType != eventType {
+		return IngestionEvent{}, &ValidationError{Field: "body", Message: fmt.Sprintf("body %T does not match event type %q", body, eventType)}
+	}
+
+	switch e := body.(type) {
+	case *TraceCreateEvent:
+		return NewTraceCreateIngestionEvent(e)
+	case *TraceUpdateEvent:
+		return NewTraceUpdateIngestionEvent(e)
+	case *ObservationCreateEvent:
+		return NewObservationCreateIngestionEvent(e)
+	case *ObservationUpdateEvent:
+		return NewObservationUpdateIngestionEvent(e)
+	case *SpanCreateEvent:
+		return NewSpanCreateIngestionEvent(e)
+	case *SpanUpdateEvent:
+		return NewSpanUpdateIngestionEvent(e)
+	case *GenerationCreateEvent:
+		return NewGenerationCreateIngestionEvent(e)
+	case *GenerationUpdateEvent:
+		return NewGenerationUpdateIngestionEvent(e)
+	case *EventCreateEvent:
+		return NewEventCreateIngestionEvent(e)
+	default:
+		return NewScoreCreateIngestionEvent(body.(*ScoreCreateEvent))
+	}
+}
+
+// DecodeBody decodes the event body into into, e.g. a *TraceCreateEvent for a
+// trace-create event. It works for bodies encoded by the constructors, typed bodies and
+// bodies decoded from JSON alike.
+func (e *IngestionEvent) DecodeBody(into interface{}) error {
+	if e.Body == nil {
+		return &ValidationError{Field: "body", Message: "body is required"}
+	}
+
+	data, ok := e.Body.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(e.Body); err != nil {
+			return fmt.Errorf("failed to encode %s body: %w", e.Type, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, into); err != nil {
+		return fmt.Errorf("failed to decode %s body: %w", e.Type, err)
+	}
+	return nil
+}
+
+// typedBodyEventType returns the event type whose body type body has
+func typedBodyEventType(body interface{}) (EventType, bool) {
+	switch body.(type) {
+	case *TraceCreateEvent:
+		return EventTypeTraceCreate, true
+	case *TraceUpdateEvent:
+		return EventTypeTraceUpdate, true
+	case *ObservationCreateEvent:
+		return EventTypeObservationCreate, true
+	case *ObservationUpdateEvent:
+		return EventTypeObservationUpdate, true
+	case *SpanCreateEvent:
+		return EventTypeSpanCreate, true
+	case *SpanUpdateEvent:
+		return EventTypeSpanUpdate, true
+	case *GenerationCreateEvent:
+		return EventTypeGenerationCreate, true
+	case *GenerationUpdateEvent:
+		return EventTypeGenerationUpdate, true
+	case *EventCreateEvent:
+		return EventTypeEventCreate, true
+	case *ScoreCreateEvent:
+		return EventTypeScoreCreate, true
+	default:
+		return "", false
+	}
+}
+
+// newIngestionEvent validates body and wraps its encoding into an ingestion event
+func newIngestionEvent(id string, eventType EventType, body interface{ Validate() error }) (IngestionEvent, error) {
+	if err := body.Validate(); err != nil {
+		return IngestionEvent{}, err
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return IngestionEvent{}, fmt.Errorf("failed to encode %s body: %w", eventType, err)
+	}
+
+	return IngestionEvent{
+		ID:        id,
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Body:      json.RawMessage(data),
+	}, nil
+}
+
+// setEventType sets an empty body event type field to eventType
+func setEventType(field *string, eventType EventType) {
+	if *field == "" {
+		*field = string(eventType)
+	}
+}
+
+// nilBodyError reports a nil body passed to the constructor of eventType
+func nilBodyError(eventType EventType) error {
+	return &ValidationError{Field: "body", Message: fmt.Sprintf("%s body is required", eventType)}
+}
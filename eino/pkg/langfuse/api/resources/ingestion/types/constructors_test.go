@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+func TestNewTraceCreateIngestionEvent(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := &TraceCreateEvent{TraceEvent: TraceEvent{Name: "agent-run", Timestamp: start, Tags: []string{"prod"}}}
+
+	before := time.Now().UTC()
+	event, err := NewTraceCreateIngestionEvent(body)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, body.ID, "a missing ID is generated")
+	assert.Equal(t, body.ID, event.ID)
+	assert.Equal(t, EventTypeTraceCreate, event.Type)
+	assert.Equal(t, "trace-create", body.Type)
+	assert.False(t, event.Timestamp.Before(before))
+	assert.IsType(t, json.RawMessage{}, event.Body)
+	require.NoError(t, event.Validate())
+
+	var decoded TraceCreateEvent
+	require.NoError(t, event.DecodeBody(&decoded))
+	assert.Equal(t, body.ID, decoded.ID)
+	assert.Equal(t, "agent-run", decoded.Name)
+	assert.Equal(t, []string{"prod"}, decoded.Tags)
+	assert.True(t, start.Equal(decoded.Timestamp))
+}
+
+func TestConstructorsRoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	observation := ObservationEvent{ID: "obs-1", TraceID: "trace-1", Type: commonTypes.ObservationTypeGeneration, Name: "llm", StartTime: start}
+
+	event, err := NewGenerationUpdateIngestionEvent(&GenerationUpdateEvent{ObservationEvent: observation})
+	require.NoError(t, err)
+	assert.Equal(t, EventTypeGenerationUpdate, event.Type)
+	assert.Equal(t, "obs-1", event.ID)
+
+	// The encoded body survives a round trip of the whole event
+	data, err := json.Marshal(&event)
+	require.NoError(t, err)
+	var received IngestionEvent
+	require.NoError(t, json.Unmarshal(data, &received))
+
+	var decoded GenerationUpdateEvent
+	require.NoError(t, received.DecodeBody(&decoded))
+	assert.Equal(t, "obs-1", decoded.ID)
+	assert.Equal(t, "trace-1", decoded.TraceID)
+	assert.Equal(t, commonTypes.ObservationTypeGeneration, decoded.Type)
+
+	score, err := NewScoreCreateIngestionEvent(CreateNumericScoreEvent("", "trace-1", "accuracy", 0.9))
+	require.NoError(t, err)
+	assert.NotEmpty(t, score.ID)
+
+	var decodedScore ScoreCreateEvent
+	require.NoError(t, score.DecodeBody(&decodedScore))
+	assert.Equal(t, score.ID, decodedScore.ID)
+	assert.Equal(t, 0.9, decodedScore.Value)
+}
+
+func TestConstructorsRejectMismatchedBodies(t *testing.T) {
+	start := time.Now().UTC()
+	score := CreateNumericScoreEvent("score-1", "trace-1", "accuracy", 1)
+
+	_, err := NewIngestionEvent(EventTypeTraceCreate, score)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match event type")
+
+	_, err = NewIngestionEvent(EventTypeScoreCreate, map[string]interface{}{"id": "score-1"})
+	require.Error(t, err, "untyped bodies have no type to check")
+
+	event, err := NewIngestionEvent(EventTypeScoreCreate, score)
+	require.NoError(t, err)
+	assert.Equal(t, EventTypeScoreCreate, event.Type)
+
+	// A body whose own type field names another event type fails validation
+	_, err = NewTraceCreateIngestionEvent(&TraceCreateEvent{TraceEvent: TraceEvent{ID: "t", Name: "n", Timestamp: start}, Type: "score-create"})
+	require.Error(t, err)
+
+	_, err = NewSpanCreateIngestionEvent(nil)
+	require.Error(t, err)
+
+	// Update events are never given a generated ID
+	_, err = NewTraceUpdateIngestionEvent(&TraceUpdateEvent{TraceEvent: TraceEvent{Name: "n", Timestamp: start}})
+	require.Error(t, err)
+}
+
+func TestIngestionEventValidateChecksTypedBody(t *testing.T) {
+	score := CreateNumericScoreEvent("score-1", "trace-1", "accuracy", 1)
+
+	event := score.ToIngestionEvent()
+	require.NoError(t, event.Validate())
+
+	event.Type = EventTypeTraceCreate
+	err := event.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "score-create")
+}
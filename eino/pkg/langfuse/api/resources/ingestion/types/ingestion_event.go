@@ -11,8 +11,25 @@ type IngestionEvent struct {
 	Type      EventType   `json:"type"`
 	Timestamp time.Time   `json:"timestamp"`
 	Body      interface{} `json:"body"`
+
+	// Source names the builder that created the event, one of the EventSource
+	// constants, for debugging and queue statistics. It is not sent to the API.
+	Source string `json:"-"`
+
+	// Project is the environment whose Langfuse project receives the event, when the
+	// client routes environments to projects with their own credentials. Empty means the
+	// default project. It is not sent to the API.
+	Project string `json:"-"`
 }
 
+// Sources of ingestion events created by the client's builders
+const (
+	EventSourceTrace      = "trace"
+	EventSourceSpan       = "span"
+	EventSourceGeneration = "generation"
+	EventSourceScore      = "score"
+)
+
 // EventType represents the type of ingestion event
 type EventType string
 
@@ -100,6 +117,11 @@ func (e *IngestionEvent) Validate() error {
 		return &ValidationError{Field: "body", Message: "body is required"}
 	}
 	
+	// A typed body must be the body of the event's type
+	if bodyType, ok := typedBodyEventType(e.Body); ok && bodyType != e.Type {
+		return &ValidationError{Field: "body", Message: "body of a " + string(bodyType) + " event cannot be sent as " + string(e.Type)}
+	}
+	
 	return nil
 }
 
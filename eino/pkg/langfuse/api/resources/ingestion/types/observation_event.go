@@ -27,6 +27,8 @@ type ObservationEvent struct {
 	Version              *string               `json:"version,omitempty"`
 	Metadata             map[string]interface{} `json:"metadata,omitempty"`
 	Environment          string                `json:"environment,omitempty"`
+	PromptName           *string               `json:"promptName,omitempty"`
+	PromptVersion        *int                  `json:"promptVersion,omitempty"`
 }
 
 // ObservationCreateEvent represents an observation creation event
@@ -355,6 +357,66 @@ func (e *ObservationUpdateEvent) Validate() error {
 	return nil
 }
 
+func (e *SpanCreateEvent) Validate() error {
+	if err := e.ObservationEvent.Validate(); err != nil {
+		return err
+	}
+	
+	if e.EventType != "span-create" {
+		return &ValidationError{Field: "type", Message: "event type must be 'span-create'"}
+	}
+	
+	return nil
+}
+
+func (e *SpanUpdateEvent) Validate() error {
+	if err := e.ObservationEvent.Validate(); err != nil {
+		return err
+	}
+	
+	if e.EventType != "span-update" {
+		return &ValidationError{Field: "type", Message: "event type must be 'span-update'"}
+	}
+	
+	return nil
+}
+
+func (e *GenerationCreateEvent) Validate() error {
+	if err := e.ObservationEvent.Validate(); err != nil {
+		return err
+	}
+	
+	if e.EventType != "generation-create" {
+		return &ValidationError{Field: "type", Message: "event type must be 'generation-create'"}
+	}
+	
+	return nil
+}
+
+func (e *GenerationUpdateEvent) Validate() error {
+	if err := e.ObservationEvent.Validate(); err != nil {
+		return err
+	}
+	
+	if e.EventType != "generation-update" {
+		return &ValidationError{Field: "type", Message: "event type must be 'generation-update'"}
+	}
+	
+	return nil
+}
+
+func (e *EventCreateEvent) Validate() error {
+	if err := e.ObservationEvent.Validate(); err != nil {
+		return err
+	}
+	
+	if e.EventType != "event-create" {
+		return &ValidationError{Field: "type", Message: "event type must be 'event-create'"}
+	}
+	
+	return nil
+}
+
 // Helper methods for observation event
 func (e *ObservationEvent) WithParent(parentID string) *ObservationEvent {
 	e.ParentObservationID = &parentID
@@ -404,4 +466,11 @@ func (e *ObservationEvent) WithEndTime(endTime time.Time) *ObservationEvent {
 func (e *ObservationEvent) WithCompletionStartTime(completionStartTime time.Time) *ObservationEvent {
 	e.CompletionStartTime = &completionStartTime
 	return e
+}
+
+// WithPromptReference sets PromptName and PromptVersion so the generation links to that prompt version
+func (e *ObservationEvent) WithPromptReference(promptName string, promptVersion int) *ObservationEvent {
+	e.PromptName = &promptName
+	e.PromptVersion = &promptVersion
+	return e
 }
\ No newline at end of file
@@ -21,6 +21,7 @@ type TraceEvent struct {
 	Release        *string               `json:"release,omitempty"`
 	Version        *string               `json:"version,omitempty"`
 	Public         *bool                 `json:"public,omitempty"`
+	Bookmarked     *bool                 `json:"bookmarked,omitempty"`
 	Timestamp      time.Time             `json:"timestamp"`
 	ParentTraceID  *string               `json:"parentTraceId,omitempty"`
 }
@@ -57,6 +58,7 @@ func NewTraceEvent(trace *types.Trace) *TraceEvent {
 		Release:     trace.Release,
 		Version:     trace.Version,
 		Public:      trace.Public,
+		Bookmarked:  trace.Bookmarked,
 		Timestamp:   trace.Timestamp,
 	}
 }
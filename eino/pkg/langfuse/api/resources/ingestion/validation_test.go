@@ -0,0 +1,92 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// batchWithInvalidEvent returns three events, the second of which has no timestamp
+func batchWithInvalidEvent() []types.IngestionEvent {
+	event := func(id string, timestamp time.Time) types.IngestionEvent {
+		return types.IngestionEvent{
+			ID:        id,
+			Type:      types.EventTypeTraceCreate,
+			Timestamp: timestamp,
+			Body:      map[string]interface{}{"id": "trace-" + id},
+		}
+	}
+	now := time.Now()
+	return []types.IngestionEvent{event("event-1", now), event("event-2", time.Time{}), event("event-3", now)}
+}
+
+// newBatchServer records the IDs of the events of every batch it receives
+func newBatchServer(t *testing.T) (*httptest.Server, *[][]string) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Batch []struct {
+				ID string `json:"id"`
+			} `json:"batch"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var ids []string
+		for _, event := range body.Batch {
+			ids = append(ids, event.ID)
+		}
+		batches = append(batches, ids)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success": true, "timestamp": "2024-01-15T12:00:00Z"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &batches
+}
+
+func TestClient_SubmitBatch_FailsOnInvalidEvent(t *testing.T) {
+	server, batches := newBatchServer(t)
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	response, err := client.SubmitBatch(context.Background(), batchWithInvalidEvent())
+	require.Error(t, err)
+	assert.Nil(t, response)
+
+	var validationErr *types.RequestValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "batch[1]", validationErr.Field)
+	assert.Contains(t, validationErr.Message, `"event-2"`)
+	assert.Contains(t, validationErr.Message, "timestamp is required")
+	assert.Empty(t, *batches, "nothing is sent")
+}
+
+func TestClient_SubmitBatch_SkipsInvalidEvents(t *testing.T) {
+	server, batches := newBatchServer(t)
+	client := NewClient(resty.New().SetBaseURL(server.URL), WithSkipInvalidEvents(true))
+	var warnings []string
+	client.warn = func(message string) { warnings = append(warnings, message) }
+
+	events := batchWithInvalidEvent()
+	response, err := client.SubmitBatchWithMetadata(context.Background(), events, &types.IngestionBatchMetadata{ClientID: "worker-7"})
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, [][]string{{"event-1", "event-3"}}, *batches)
+	assert.Len(t, events, 3, "the caller's batch is left as is")
+
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `batch[1] (id "event-2"`)
+	assert.Contains(t, warnings[0], "timestamp is required")
+
+	// A batch with nothing valid left is not sent
+	_, err = client.SubmitBatch(context.Background(), batchWithInvalidEvent()[1:2])
+	assert.ErrorContains(t, err, "all 1 events are invalid")
+	assert.Len(t, *batches, 1)
+}
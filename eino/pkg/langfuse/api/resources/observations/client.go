@@ -0,0 +1,137 @@
+// Package observations lists observations and creates and updates them in bulk through
+// the ingestion API, e.g. to reconstruct traces imported from another system.
+package observations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+
+	"eino/pkg/langfuse/api/resources/ingestion"
+	ingestionTypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/api/resources/observations/types"
+)
+
+// errNilEvent is reported for nil events of a bulk request
+var errNilEvent = errors.New("observation event cannot be nil")
+
+// Client handles observation-related API operations
+type Client struct {
+	client    *resty.Client
+	ingestion *ingestion.Client
+}
+
+// NewClient creates a new observations client reading through client and submitting
+// through ingestionClient
+func NewClient(client *resty.Client, ingestionClient *ingestion.Client) *Client {
+	return &Client{
+		client:    client,
+		ingestion: ingestionClient,
+	}
+}
+
+// pendingEvent is an event of a bulk request, converted for submission unless err is set
+type pendingEvent struct {
+	id    string
+	event ingestionTypes.IngestionEvent
+	err   error
+}
+
+// BulkCreate creates observations with one ingestion request per MaxBatchSize events,
+// instead of one request per observation.
+//
+// Invalid events are not sent and, like events the API rejects, are reported in
+// FailedEvents; neither stops the other events. A batch that cannot be sent counts all
+// its events as failed. When ctx is done, the counts so far are returned with ctx's error.
+func (c *Client) BulkCreate(ctx context.Context, events []*ingestionTypes.ObservationCreateEvent) (*types.BulkCreateResponse, error) {
+	pending := make([]pendingEvent, len(events))
+	for i, event := range events {
+		if event == nil {
+			pending[i] = pendingEvent{err: errNilEvent}
+			continue
+		}
+		pending[i] = pendingEvent{id: event.ID, event: event.ToIngestionEvent(), err: event.Validate()}
+	}
+
+	return c.submit(ctx, pending)
+}
+
+// BulkUpdate updates observations in batches, reporting failures like BulkCreate
+func (c *Client) BulkUpdate(ctx context.Context, events []*ingestionTypes.ObservationUpdateEvent) (*types.BulkUpdateResponse, error) {
+	pending := make([]pendingEvent, len(events))
+	for i, event := range events {
+		if event == nil {
+			pending[i] = pendingEvent{err: errNilEvent}
+			continue
+		}
+		pending[i] = pendingEvent{id: event.ID, event: event.ToIngestionEvent(), err: event.Validate()}
+	}
+
+	return c.submit(ctx, pending)
+}
+
+// submit sends the valid events in batches of at most MaxBatchSize
+func (c *Client) submit(ctx context.Context, pending []pendingEvent) (*types.BulkCreateResponse, error) {
+	if c.ingestion == nil {
+		return nil, fmt.Errorf("observations client has no ingestion client")
+	}
+
+	response := &types.BulkCreateResponse{}
+	valid := make([]ingestionTypes.IngestionEvent, 0, len(pending))
+	for _, p := range pending {
+		if p.err != nil {
+			response.FailedEvents = append(response.FailedEvents, types.BulkCreateError{EventID: p.id, Message: p.err.Error()})
+			continue
+		}
+		valid = append(valid, p.event)
+	}
+
+	var err error
+	for start := 0; start < len(valid) && err == nil; start += ingestionTypes.MaxBatchSize {
+		end := min(start+ingestionTypes.MaxBatchSize, len(valid))
+		err = c.submitBatch(ctx, valid[start:end], response)
+	}
+
+	response.Failed = len(response.FailedEvents)
+	return response, err
+}
+
+// submitBatch submits one batch, adding its outcome to response. It only returns an
+// error when ctx is done.
+func (c *Client) submitBatch(ctx context.Context, batch []ingestionTypes.IngestionEvent, response *types.BulkCreateResponse) error {
+	failAll := func(message string) {
+		for _, event := range batch {
+			response.FailedEvents = append(response.FailedEvents, types.BulkCreateError{EventID: event.ID, Message: message})
+		}
+	}
+
+	result, err := c.ingestion.SubmitBatch(ctx, batch)
+	if err != nil {
+		failAll(err.Error())
+		return ctx.Err()
+	}
+	if result == nil || (!result.Success && !result.HasErrors()) {
+		failAll("batch was rejected")
+		return nil
+	}
+
+	rejected := make(map[string]ingestionTypes.IngestionError, len(result.Errors))
+	for _, ingestionErr := range result.Errors {
+		rejected[ingestionErr.ID] = ingestionErr
+	}
+	for _, event := range batch {
+		ingestionErr, ok := rejected[event.ID]
+		if !ok {
+			response.Succeeded++
+			continue
+		}
+		message := ingestionErr.Message
+		if message == "" {
+			message = ingestionErr.ErrorText
+		}
+		response.FailedEvents = append(response.FailedEvents, types.BulkCreateError{EventID: event.ID, Status: ingestionErr.Status, Message: message})
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package observations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/ingestion"
+	ingestionTypes "eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// ingestionServer records the size of every batch and rejects the events in reject
+type ingestionServer struct {
+	mu      sync.Mutex
+	batches []int
+	types   map[string]int
+	reject  map[string]bool
+}
+
+func (s *ingestionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Batch []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"batch"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, len(body.Batch))
+
+	response := ingestionTypes.IngestionResponse{Success: true, Timestamp: time.Now()}
+	for _, event := range body.Batch {
+		s.types[event.Type]++
+		if s.reject[event.ID] {
+			response.AddError(ingestionTypes.IngestionError{ID: event.ID, Status: http.StatusBadRequest, Message: "invalid model"})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if response.HasErrors() {
+		w.WriteHeader(http.StatusMultiStatus)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func newTestClient(t *testing.T, reject ...string) (*Client, *ingestionServer) {
+	server := &ingestionServer{types: make(map[string]int), reject: make(map[string]bool)}
+	for _, id := range reject {
+		server.reject[id] = true
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	restClient := resty.New().SetBaseURL(httpServer.URL)
+	return NewClient(restClient, ingestion.NewClient(restClient)), server
+}
+
+func observation(id string) *commonTypes.Observation {
+	name := "span-" + id
+	return &commonTypes.Observation{
+		ID:        id,
+		TraceID:   "trace-1",
+		Type:      commonTypes.ObservationTypeSpan,
+		Name:      &name,
+		StartTime: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestClient_BulkCreate(t *testing.T) {
+	client, server := newTestClient(t, "obs-42")
+
+	var events []*ingestionTypes.ObservationCreateEvent
+	for i := 0; i < 250; i++ {
+		events = append(events, ingestionTypes.NewObservationCreateEvent(observation(fmt.Sprintf("obs-%d", i))))
+	}
+	invalid := ingestionTypes.NewObservationCreateEvent(observation("obs-invalid"))
+	invalid.TraceID = ""
+	events = append(events, invalid, nil)
+
+	response, err := client.BulkCreate(context.Background(), events)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{100, 100, 50}, server.batches, "events are sent in batches of MaxBatchSize")
+	assert.Equal(t, map[string]int{"observation-create": 250}, server.types)
+	assert.Equal(t, 249, response.Succeeded)
+	assert.Equal(t, 3, response.Failed)
+	require.Len(t, response.FailedEvents, 3)
+
+	failed := make(map[string]int)
+	for _, failure := range response.FailedEvents {
+		failed[failure.EventID] = failure.Status
+		assert.NotEmpty(t, failure.Message)
+	}
+	assert.Equal(t, map[string]int{"obs-42": http.StatusBadRequest, "obs-invalid": 0, "": 0}, failed)
+}
+
+func TestClient_BulkUpdate(t *testing.T) {
+	client, server := newTestClient(t)
+
+	events := []*ingestionTypes.ObservationUpdateEvent{
+		ingestionTypes.NewObservationUpdateEvent(observation("obs-1")),
+		ingestionTypes.NewObservationUpdateEvent(observation("obs-2")),
+	}
+	response, err := client.BulkUpdate(context.Background(), events)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{2}, server.batches)
+	assert.Equal(t, map[string]int{"observation-update": 2}, server.types)
+	assert.Equal(t, 2, response.Succeeded)
+	assert.Zero(t, response.Failed)
+}
+
+func TestClient_BulkCreate_Unreachable(t *testing.T) {
+	restClient := resty.New().SetBaseURL("http://127.0.0.1:1")
+	client := NewClient(restClient, ingestion.NewClient(restClient))
+
+	events := []*ingestionTypes.ObservationCreateEvent{
+		ingestionTypes.NewObservationCreateEvent(observation("obs-1")),
+	}
+	response, err := client.BulkCreate(context.Background(), events)
+	require.NoError(t, err, "a failed batch is reported in the response")
+	assert.Equal(t, 1, response.Failed)
+	assert.Zero(t, response.Succeeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.BulkCreate(ctx, events)
+	assert.ErrorIs(t, err, context.Canceled)
+}
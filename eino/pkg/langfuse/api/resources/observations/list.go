@@ -0,0 +1,102 @@
+package observations
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/observations/types"
+)
+
+const observationsBasePath = "/api/public/observations"
+
+// List retrieves a page of observations matching the request's filters
+func (c *Client) List(ctx context.Context, req *types.GetObservationsRequest) (*types.GetObservationsResponse, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("observations client has no API client")
+	}
+	if req == nil {
+		req = &types.GetObservationsRequest{}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+
+	queryParams, err := listQueryParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.GetObservationsResponse{}
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParams(queryParams).
+		SetResult(response).
+		Get(observationsBasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list observations: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to list observations: unexpected status %d", resp.StatusCode())
+	}
+
+	return response, nil
+}
+
+// ListByMetadata retrieves observations whose metadata has key set to value, e.g. all
+// observations tagged with a tenant
+func (c *Client) ListByMetadata(ctx context.Context, key, value string, limit int) (*types.GetObservationsResponse, error) {
+	if key == "" {
+		return nil, fmt.Errorf("metadata key cannot be empty")
+	}
+
+	return c.List(ctx, &types.GetObservationsRequest{
+		Metadata: map[string]string{key: value},
+		Limit:    &limit,
+	})
+}
+
+// listQueryParams builds the query parameters of a list request
+func listQueryParams(req *types.GetObservationsRequest) (map[string]string, error) {
+	queryParams := make(map[string]string)
+
+	if req.Page != nil {
+		queryParams["page"] = strconv.Itoa(*req.Page)
+	}
+	if req.Limit != nil {
+		queryParams["limit"] = strconv.Itoa(*req.Limit)
+	}
+	if req.Name != nil {
+		queryParams["name"] = *req.Name
+	}
+	if req.UserID != nil {
+		queryParams["userId"] = *req.UserID
+	}
+	if req.Type != nil {
+		queryParams["type"] = string(*req.Type)
+	}
+	if req.TraceID != nil {
+		queryParams["traceId"] = *req.TraceID
+	}
+	if req.ParentObservationID != nil {
+		queryParams["parentObservationId"] = *req.ParentObservationID
+	}
+	if req.FromStartTime != nil {
+		queryParams["fromStartTime"] = req.FromStartTime.UTC().Format("2006-01-02T15:04:05.000Z")
+	}
+	if req.ToStartTime != nil {
+		queryParams["toStartTime"] = req.ToStartTime.UTC().Format("2006-01-02T15:04:05.000Z")
+	}
+	if len(req.Metadata) > 0 {
+		filter, err := commonTypes.EncodeMetadataFilter(req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		queryParams[commonTypes.FilterQueryParam] = filter
+	}
+	commonTypes.SetSortQueryParams(queryParams, req.SortBy, req.SortOrder)
+
+	return queryParams, nil
+}
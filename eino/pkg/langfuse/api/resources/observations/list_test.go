@@ -0,0 +1,111 @@
+package observations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/observations/types"
+)
+
+func newListTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return NewClient(resty.New().SetBaseURL(httpServer.URL), nil)
+}
+
+func TestClient_ListByMetadata(t *testing.T) {
+	var rawQuery string
+	client := newListTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/observations", r.URL.Path)
+		rawQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": [
+				{"id": "obs-1", "traceId": "trace-1", "type": "GENERATION", "name": "llm", "startTime": "2024-01-01T12:00:00Z", "metadata": {"tenant": "a&b c/é"}},
+				{"id": "obs-2", "traceId": "trace-2", "type": "SPAN", "name": "tool", "startTime": "2024-01-01T12:01:00Z", "metadata": {"tenant": "a&b c/é"}}
+			],
+			"meta": {"page": 1, "limit": 2, "totalItems": 3, "totalPages": 2, "hasNextPage": true}
+		}`))
+	})
+
+	resp, err := client.ListByMetadata(context.Background(), "tenant", "a&b c/é", 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, "filter=%5B%7B%22type%22%3A%22stringObject%22%2C%22column%22%3A%22metadata%22%2C"+
+		"%22key%22%3A%22tenant%22%2C%22operator%22%3A%22%3D%22%2C%22value%22%3A%22a%26b+c%2F%C3%A9%22%7D%5D&limit=2", rawQuery)
+
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, "obs-1", resp.Data[0].ID)
+	assert.Equal(t, commonTypes.ObservationTypeGeneration, resp.Data[0].Type)
+	assert.Equal(t, "a&b c/é", resp.Data[1].Metadata["tenant"])
+	assert.Equal(t, 2, resp.Meta.TotalPages)
+	assert.True(t, resp.Meta.HasNextPage)
+}
+
+func TestClient_List(t *testing.T) {
+	var query map[string][]string
+	client := newListTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "meta": {"page": 2, "limit": 10, "totalItems": 10, "totalPages": 1}}`))
+	})
+
+	page, limit := 2, 10
+	generation := commonTypes.ObservationTypeGeneration
+	resp, err := client.List(context.Background(), &types.GetObservationsRequest{
+		Page:     &page,
+		Limit:    &limit,
+		Type:     &generation,
+		Metadata: map[string]string{"tenant": "acme", "region": "eu"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.Equal(t, 2, resp.Meta.Page)
+
+	assert.Equal(t, []string{"GENERATION"}, query["type"])
+	assert.Equal(t, []string{"2"}, query["page"])
+	assert.Equal(t, []string{`[{"type":"stringObject","column":"metadata","key":"region","operator":"=","value":"eu"},` +
+		`{"type":"stringObject","column":"metadata","key":"tenant","operator":"=","value":"acme"}]`}, query["filter"])
+
+	_, err = client.List(context.Background(), &types.GetObservationsRequest{Metadata: map[string]string{"": "acme"}})
+	assert.Error(t, err)
+	_, err = client.ListByMetadata(context.Background(), "", "acme", 10)
+	assert.Error(t, err)
+}
+
+func TestClient_List_Sort(t *testing.T) {
+	var query map[string][]string
+	requests := 0
+	client := newListTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "meta": {"page": 1, "limit": 50, "totalItems": 0, "totalPages": 0}}`))
+	})
+
+	_, err := client.List(context.Background(), &types.GetObservationsRequest{
+		SortBy:    commonTypes.SortFieldStartTime,
+		SortOrder: commonTypes.SortOrderDesc,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"startTime"}, query["sortBy"])
+	assert.Equal(t, []string{"desc"}, query["order"])
+
+	_, err = client.List(context.Background(), &types.GetObservationsRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, query, "sortBy")
+	assert.NotContains(t, query, "order")
+
+	_, err = client.List(context.Background(), &types.GetObservationsRequest{SortBy: commonTypes.SortFieldValue})
+	assert.ErrorContains(t, err, "sortBy")
+	_, err = client.List(context.Background(), &types.GetObservationsRequest{SortOrder: "up"})
+	assert.ErrorContains(t, err, "order")
+	assert.Equal(t, 2, requests, "invalid requests are not sent")
+}
@@ -0,0 +1,29 @@
+package types
+
+// BulkCreateResponse reports the outcome of a bulk observation request
+type BulkCreateResponse struct {
+	// Succeeded is the number of events the ingestion API accepted
+	Succeeded int `json:"succeeded"`
+
+	// Failed is the number of events that were invalid, rejected or could not be sent
+	Failed int `json:"failed"`
+
+	// FailedEvents describes every failed event
+	FailedEvents []BulkCreateError `json:"failedEvents,omitempty"`
+}
+
+// BulkUpdateResponse reports the outcome of a bulk observation update
+type BulkUpdateResponse = BulkCreateResponse
+
+// BulkCreateError describes an event of a bulk request that failed
+type BulkCreateError struct {
+	// EventID is the ID of the observation, empty for nil events
+	EventID string `json:"eventId"`
+
+	// Status is the HTTP status the API reported for the event, 0 when it was never
+	// accepted by the API (invalid or not sent)
+	Status int `json:"status,omitempty"`
+
+	// Message explains why the event failed
+	Message string `json:"message"`
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"slices"
+	"time"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	paginationTypes "eino/pkg/langfuse/api/resources/utils/pagination/types"
+)
+
+// GetObservationsRequest represents a request to list observations
+type GetObservationsRequest struct {
+	Page                *int                         `json:"page,omitempty"`
+	Limit               *int                         `json:"limit,omitempty"`
+	Name                *string                      `json:"name,omitempty"`
+	UserID              *string                      `json:"userId,omitempty"`
+	Type                *commonTypes.ObservationType `json:"type,omitempty"`
+	TraceID             *string                      `json:"traceId,omitempty"`
+	ParentObservationID *string                      `json:"parentObservationId,omitempty"`
+	FromStartTime       *time.Time                   `json:"fromStartTime,omitempty"`
+	ToStartTime         *time.Time                   `json:"toStartTime,omitempty"`
+
+	// Metadata matches observations whose metadata has all of these key/value pairs
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// SortBy orders the observations by start time or name, and SortOrder sets the direction
+	SortBy    commonTypes.SortField `json:"sortBy,omitempty"`
+	SortOrder commonTypes.SortOrder `json:"order,omitempty"`
+}
+
+// ObservationSortFields are the fields GetObservationsRequest.SortBy accepts
+var ObservationSortFields = []commonTypes.SortField{commonTypes.SortFieldStartTime, commonTypes.SortFieldName}
+
+// GetObservationsResponse represents the response from listing observations
+type GetObservationsResponse struct {
+	Data []commonTypes.Observation    `json:"data"`
+	Meta paginationTypes.MetaResponse `json:"meta"`
+}
+
+// ValidationError represents a validation error for observation requests
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate validates the GetObservationsRequest
+func (req *GetObservationsRequest) Validate() error {
+	if req.Limit != nil && (*req.Limit < 1 || *req.Limit > 1000) {
+		return &ValidationError{Field: "limit", Message: "limit must be between 1 and 1000"}
+	}
+
+	if req.Page != nil && *req.Page < 1 {
+		return &ValidationError{Field: "page", Message: "page must be greater than 0"}
+	}
+
+	if req.FromStartTime != nil && req.ToStartTime != nil && req.FromStartTime.After(*req.ToStartTime) {
+		return &ValidationError{Field: "startTime", Message: "fromStartTime cannot be after toStartTime"}
+	}
+
+	if _, ok := req.Metadata[""]; ok {
+		return &ValidationError{Field: "metadata", Message: "metadata filter key cannot be empty"}
+	}
+
+	if req.SortBy != "" && !slices.Contains(ObservationSortFields, req.SortBy) {
+		return &ValidationError{Field: "sortBy", Message: "sortBy must be startTime or name"}
+	}
+
+	if req.SortOrder != "" && !req.SortOrder.IsValid() {
+		return &ValidationError{Field: "order", Message: "order must be asc or desc"}
+	}
+
+	return nil
+}
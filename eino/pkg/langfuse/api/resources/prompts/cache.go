@@ -0,0 +1,102 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/prompts/types"
+)
+
+// ClientOption configures the prompts client
+type ClientOption func(*Client)
+
+// WithPromptCacheTTL keeps prompts fetched with GetByNameAndVersion in memory for ttl, so
+// repeated lookups of the same name and version don't hit the API. A ttl of zero or less
+// disables the cache.
+func WithPromptCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl <= 0 {
+			c.cache = nil
+			return
+		}
+		c.cache = newPromptCache(ttl)
+	}
+}
+
+type promptCacheKey struct {
+	name    string
+	version int
+}
+
+type promptCacheEntry struct {
+	prompt  *types.Prompt
+	expires time.Time
+}
+
+// promptCache is an in-memory cache of prompt versions; entries are dropped when they
+// are looked up after their TTL
+type promptCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[promptCacheKey]promptCacheEntry
+	now     func() time.Time
+}
+
+func newPromptCache(ttl time.Duration) *promptCache {
+	return &promptCache{
+		ttl:     ttl,
+		entries: make(map[promptCacheKey]promptCacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (pc *promptCache) get(key promptCacheKey) (*types.Prompt, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	entry, ok := pc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !pc.now().Before(entry.expires) {
+		delete(pc.entries, key)
+		return nil, false
+	}
+	return entry.prompt, true
+}
+
+func (pc *promptCache) put(key promptCacheKey, prompt *types.Prompt) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.entries[key] = promptCacheEntry{prompt: prompt, expires: pc.now().Add(pc.ttl)}
+}
+
+// GetByNameAndVersion retrieves a pinned version of a prompt, so the same prompt is used
+// across restarts whatever its labels point to. With WithPromptCacheTTL the prompt is
+// served from memory until the TTL expires; the returned prompt is then shared between
+// callers and must not be modified.
+func (c *Client) GetByNameAndVersion(ctx context.Context, name string, version int) (*types.Prompt, error) {
+	if name == "" {
+		return nil, fmt.Errorf("prompt name cannot be empty")
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("prompt version must be greater than 0")
+	}
+
+	key := promptCacheKey{name: name, version: version}
+	if c.cache != nil {
+		if prompt, ok := c.cache.get(key); ok {
+			return prompt, nil
+		}
+	}
+
+	prompt, err := c.Get(ctx, name, &version)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		c.cache.put(key, prompt)
+	}
+	return prompt, nil
+}
@@ -0,0 +1,89 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionServer serves every version of any prompt and counts the requests
+func newVersionServer(t *testing.T) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		version := r.URL.Query().Get("version")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"p-%s","name":"greeting","version":%s,"type":"chat","prompt":[{"role":"system","content":"v%s"}]}`, version, version, version)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_GetByNameAndVersion_Cache(t *testing.T) {
+	server, requests := newVersionServer(t)
+	client := NewClient(resty.New().SetBaseURL(server.URL), WithPromptCacheTTL(time.Minute))
+	ctx := context.Background()
+
+	first, err := client.GetByNameAndVersion(ctx, "greeting", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, first.Version)
+
+	second, err := client.GetByNameAndVersion(ctx, "greeting", 2)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests), "the second lookup is a cache hit")
+
+	// Versions are cached separately
+	third, err := client.GetByNameAndVersion(ctx, "greeting", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, third.Version)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+
+	_, err = client.GetByNameAndVersion(ctx, "greeting", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestClient_GetByNameAndVersion_Expiry(t *testing.T) {
+	server, requests := newVersionServer(t)
+	client := NewClient(resty.New().SetBaseURL(server.URL), WithPromptCacheTTL(time.Minute))
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	client.cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := client.GetByNameAndVersion(ctx, "greeting", 1)
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Second)
+	_, err = client.GetByNameAndVersion(ctx, "greeting", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	now = now.Add(time.Second)
+	_, err = client.GetByNameAndVersion(ctx, "greeting", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests), "an expired entry is fetched again")
+}
+
+func TestClient_GetByNameAndVersion_NoCache(t *testing.T) {
+	server, requests := newVersionServer(t)
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetByNameAndVersion(ctx, "greeting", 1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+
+	_, err := client.GetByNameAndVersion(ctx, "greeting", 0)
+	assert.EqualError(t, err, "prompt version must be greater than 0")
+}
@@ -20,13 +20,18 @@ const (
 // Client handles prompt-related API operations
 type Client struct {
 	client *resty.Client
+	cache  *promptCache
 }
 
 // NewClient creates a new prompts client
-func NewClient(client *resty.Client) *Client {
-	return &Client{
+func NewClient(client *resty.Client, opts ...ClientOption) *Client {
+	c := &Client{
 		client: client,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // List retrieves a list of prompts
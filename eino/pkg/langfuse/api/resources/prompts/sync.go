@@ -0,0 +1,518 @@
+package prompts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"eino/pkg/langfuse/api/resources/prompts/types"
+)
+
+const (
+	// LockFileName is the file in a synced directory recording the version and content
+	// hash of every prompt as last pulled or pushed
+	LockFileName = "prompts.lock"
+
+	// DefaultRequestDelay is the pause between API requests of SyncToDir and PushFromDir,
+	// keeping bulk syncs under the API rate limit
+	DefaultRequestDelay = 100 * time.Millisecond
+
+	// promptFileExt is the extension of prompt files
+	promptFileExt = ".yaml"
+
+	// syncPageSize is the number of prompts listed per request
+	syncPageSize = 100
+)
+
+// PromptFile is the YAML form of a prompt version in a synced directory. Labels are
+// informational: pushing a file does not move them, see WithPromoteLabel.
+type PromptFile struct {
+	Name    string              `yaml:"name"`
+	Version int                 `yaml:"version"`
+	Type    string              `yaml:"type"`
+	Prompt  []types.ChatMessage `yaml:"prompt"`
+	Config  *types.PromptConfig `yaml:"config,omitempty"`
+	Labels  []string            `yaml:"labels,omitempty"`
+}
+
+// contentHash hashes what makes up a prompt version: its type, messages and config
+func (f *PromptFile) contentHash() (string, error) {
+	data, err := json.Marshal(struct {
+		Type   string              `json:"type"`
+		Prompt []types.ChatMessage `json:"prompt"`
+		Config *types.PromptConfig `json:"config,omitempty"`
+	}{f.Type, f.Prompt, f.Config})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash prompt %s: %w", f.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// LockEntry records the version of a prompt a file was last synced with
+type LockEntry struct {
+	File    string `yaml:"file"`
+	Version int    `yaml:"version"`
+	Hash    string `yaml:"hash"`
+}
+
+// LockFile is the content of LockFileName, keyed by prompt name
+type LockFile struct {
+	Prompts map[string]LockEntry `yaml:"prompts"`
+}
+
+// PromptVersion identifies a prompt version written to a file or created remotely
+type PromptVersion struct {
+	Name    string
+	Version int
+}
+
+// Conflict is a prompt that was left alone because syncing it would lose changes
+type Conflict struct {
+	Name string
+	File string
+
+	// LockedVersion is the version the file was last synced with, 0 if never
+	LockedVersion int
+
+	// RemoteVersion is the latest version on the server, 0 if the prompt does not exist
+	RemoteVersion int
+
+	Reason string
+}
+
+// ConflictError is returned by SyncToDir and PushFromDir when prompts were skipped due
+// to conflicts. The other prompts were synced.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	descriptions := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		descriptions[i] = fmt.Sprintf("%s (%s)", conflict.Name, conflict.Reason)
+	}
+	return fmt.Sprintf("%d prompts conflict, rerun with force to overwrite: %s",
+		len(e.Conflicts), strings.Join(descriptions, ", "))
+}
+
+// SyncResult reports what SyncToDir or PushFromDir did
+type SyncResult struct {
+	// Updated are the versions written to files by SyncToDir, or created by PushFromDir
+	Updated []PromptVersion
+
+	// Unchanged are the names of prompts already in sync
+	Unchanged []string
+
+	// Conflicts are the prompts skipped, also returned as a ConflictError
+	Conflicts []Conflict
+}
+
+// SyncOption configures SyncToDir and PushFromDir
+type SyncOption func(*syncOptions)
+
+// syncOptions holds the settings applied by SyncOption
+type syncOptions struct {
+	requestDelay time.Duration
+	force        bool
+	promoteLabel string
+}
+
+// WithRequestDelay sets the pause between API requests, DefaultRequestDelay by default;
+// zero disables it. Rate-limited requests are also retried by the API client.
+func WithRequestDelay(delay time.Duration) SyncOption {
+	return func(o *syncOptions) {
+		o.requestDelay = delay
+	}
+}
+
+// WithForce overwrites instead of reporting conflicts: SyncToDir replaces locally
+// modified files, and PushFromDir creates versions on top of remote changes
+func WithForce() SyncOption {
+	return func(o *syncOptions) {
+		o.force = true
+	}
+}
+
+// WithPromoteLabel makes PushFromDir move label (e.g. "production") to every version it
+// creates
+func WithPromoteLabel(label string) SyncOption {
+	return func(o *syncOptions) {
+		o.promoteLabel = label
+	}
+}
+
+// pacer spaces out API requests
+type pacer struct {
+	delay time.Duration
+	last  time.Time
+}
+
+// wait blocks until delay has passed since the previous request
+func (p *pacer) wait(ctx context.Context) error {
+	if p.delay > 0 && !p.last.IsZero() {
+		timer := time.NewTimer(time.Until(p.last.Add(p.delay)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	p.last = time.Now()
+	return nil
+}
+
+// SyncToDir pulls the latest version of every prompt into dir, one YAML file per prompt,
+// and records the pulled versions in the lockfile. Use it with the API client's Prompts,
+// e.g. prompts.SyncToDir(ctx, apiClient.Prompts, "prompts").
+//
+// Files modified since they were last synced are not overwritten but reported as
+// conflicts, unless WithForce is given, so pulling never loses local edits that were not
+// pushed yet.
+func SyncToDir(ctx context.Context, client *Client, dir string, opts ...SyncOption) (*SyncResult, error) {
+	if client == nil {
+		return nil, fmt.Errorf("prompts client cannot be nil")
+	}
+	options := newSyncOptions(opts)
+	p := &pacer{delay: options.requestDelay}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create prompt directory: %w", err)
+	}
+	lock, err := loadLockFile(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := listLatestVersions(ctx, client, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	for _, prompt := range latest {
+		remote := &PromptFile{
+			Name:    prompt.Name,
+			Version: prompt.Version,
+			Type:    prompt.Type,
+			Prompt:  prompt.Prompt,
+			Config:  prompt.Config,
+			Labels:  prompt.Labels,
+		}
+		remoteHash, err := remote.contentHash()
+		if err != nil {
+			return result, err
+		}
+
+		entry, locked := lock.Prompts[prompt.Name]
+		file := promptFileName(prompt.Name)
+		if locked {
+			file = entry.File
+		}
+		path := filepath.Join(dir, file)
+
+		local, err := readPromptFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+		if local != nil && !options.force {
+			localHash, err := local.contentHash()
+			if err != nil {
+				return result, err
+			}
+			if locked && entry.Version == prompt.Version {
+				// Nothing new remotely; local edits are kept for the next push
+				result.Unchanged = append(result.Unchanged, prompt.Name)
+				continue
+			}
+			// Files unmodified since the last sync, or already matching the remote
+			// content, are safe to overwrite
+			if localHash != remoteHash && (!locked || localHash != entry.Hash) {
+				result.Conflicts = append(result.Conflicts, Conflict{
+					Name:          prompt.Name,
+					File:          file,
+					LockedVersion: entry.Version,
+					RemoteVersion: prompt.Version,
+					Reason:        "local file has changes that were not pushed",
+				})
+				continue
+			}
+		}
+
+		if err := writePromptFile(path, remote); err != nil {
+			return result, err
+		}
+		lock.Prompts[prompt.Name] = LockEntry{File: file, Version: prompt.Version, Hash: remoteHash}
+		if err := lock.save(dir); err != nil {
+			return result, err
+		}
+		result.Updated = append(result.Updated, PromptVersion{Name: prompt.Name, Version: prompt.Version})
+	}
+
+	return result, result.conflictError()
+}
+
+// PushFromDir creates a new version of every prompt whose file in dir differs from the
+// version recorded in the lockfile, then records the created versions. Prompts missing
+// from the lockfile are created as new prompts. Use it with the API client's Prompts,
+// like SyncToDir.
+//
+// A prompt with a remote version newer than the one in the lockfile was changed by
+// someone else since the last sync; it is reported as a conflict rather than overwritten
+// unless WithForce is given. Pull and merge to resolve it.
+func PushFromDir(ctx context.Context, client *Client, dir string, opts ...SyncOption) (*SyncResult, error) {
+	if client == nil {
+		return nil, fmt.Errorf("prompts client cannot be nil")
+	}
+	options := newSyncOptions(opts)
+	p := &pacer{delay: options.requestDelay}
+
+	lock, err := loadLockFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+promptFileExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt files: %w", err)
+	}
+	sort.Strings(paths)
+
+	result := &SyncResult{}
+	for _, path := range paths {
+		local, err := readPromptFile(path)
+		if err != nil {
+			return result, err
+		}
+		localHash, err := local.contentHash()
+		if err != nil {
+			return result, err
+		}
+
+		entry, locked := lock.Prompts[local.Name]
+		if locked && entry.Hash == localHash {
+			result.Unchanged = append(result.Unchanged, local.Name)
+			continue
+		}
+
+		remoteVersion, err := latestVersion(ctx, client, p, local.Name)
+		if err != nil {
+			return result, err
+		}
+		if remoteVersion > entry.Version && !options.force {
+			reason := fmt.Sprintf("remote version %d is newer than synced version %d", remoteVersion, entry.Version)
+			if !locked {
+				reason = fmt.Sprintf("prompt exists remotely at version %d but was never synced", remoteVersion)
+			}
+			result.Conflicts = append(result.Conflicts, Conflict{
+				Name:          local.Name,
+				File:          filepath.Base(path),
+				LockedVersion: entry.Version,
+				RemoteVersion: remoteVersion,
+				Reason:        reason,
+			})
+			continue
+		}
+
+		created, err := createVersion(ctx, client, p, local, options.promoteLabel)
+		if err != nil {
+			return result, err
+		}
+
+		local.Version = created.Version
+		local.Labels = created.Labels
+		if err := writePromptFile(path, local); err != nil {
+			return result, err
+		}
+		lock.Prompts[local.Name] = LockEntry{File: filepath.Base(path), Version: created.Version, Hash: localHash}
+		if err := lock.save(dir); err != nil {
+			return result, err
+		}
+		result.Updated = append(result.Updated, PromptVersion{Name: local.Name, Version: created.Version})
+	}
+
+	return result, result.conflictError()
+}
+
+// conflictError returns a ConflictError for the conflicts, or nil without any
+func (r *SyncResult) conflictError() error {
+	if len(r.Conflicts) == 0 {
+		return nil
+	}
+	return &ConflictError{Conflicts: r.Conflicts}
+}
+
+func newSyncOptions(opts []SyncOption) *syncOptions {
+	options := &syncOptions{requestDelay: DefaultRequestDelay}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// listLatestVersions lists every prompt page by page and keeps the latest version of
+// each, sorted by name
+func listLatestVersions(ctx context.Context, client *Client, p *pacer) ([]types.Prompt, error) {
+	latest := make(map[string]types.Prompt)
+	limit := syncPageSize
+	for page := 1; ; page++ {
+		if err := p.wait(ctx); err != nil {
+			return nil, err
+		}
+		response, err := client.List(ctx, &types.GetPromptsRequest{Page: &page, Limit: &limit})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prompt := range response.Data {
+			if current, ok := latest[prompt.Name]; !ok || prompt.Version > current.Version {
+				latest[prompt.Name] = prompt
+			}
+		}
+
+		// The server may cap the page size, so only the page count ends the listing
+		if len(response.Data) == 0 || page >= response.Meta.TotalPages {
+			break
+		}
+	}
+
+	prompts := make([]types.Prompt, 0, len(latest))
+	for _, prompt := range latest {
+		prompts = append(prompts, prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+// latestVersion returns the latest remote version of a prompt, 0 when it does not exist
+func latestVersion(ctx context.Context, client *Client, p *pacer, name string) (int, error) {
+	if err := p.wait(ctx); err != nil {
+		return 0, err
+	}
+	versions, err := client.GetVersions(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := 0
+	for _, version := range versions {
+		if version.Name == name && version.Version > latest {
+			latest = version.Version
+		}
+	}
+	return latest, nil
+}
+
+// createVersion creates a version from a file and promotes label to it when set
+func createVersion(ctx context.Context, client *Client, p *pacer, file *PromptFile, label string) (*types.Prompt, error) {
+	promptType := file.Type
+	if promptType == "" || promptType == types.PromptTypeChatMessage {
+		promptType = "chat"
+	}
+
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	created, err := client.CreateVersion(ctx, &types.CreatePromptVersionRequest{
+		Name:   file.Name,
+		Type:   promptType,
+		Prompt: file.Prompt,
+		Config: file.Config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if label == "" || slices.Contains(created.Labels, label) {
+		return created, nil
+	}
+
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	labels := append(slices.Clone(created.Labels), label)
+	promoted, err := client.SetVersionLabels(ctx, file.Name, created.Version, labels)
+	if err != nil {
+		return nil, fmt.Errorf("created version %d but failed to promote it: %w", created.Version, err)
+	}
+	return promoted, nil
+}
+
+// promptFileName is the file name of a prompt; names may contain slashes, which are escaped
+func promptFileName(name string) string {
+	return url.PathEscape(name) + promptFileExt
+}
+
+func readPromptFile(path string) (*PromptFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &PromptFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to decode prompt file %s: %w", path, err)
+	}
+	if file.Name == "" {
+		return nil, fmt.Errorf("prompt file %s has no name", path)
+	}
+	return file, nil
+}
+
+func writePromptFile(path string, file *PromptFile) error {
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt %s: %w", file.Name, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return nil
+}
+
+// loadLockFile reads the lockfile of dir, or returns an empty one if there is none yet
+func loadLockFile(dir string) (*LockFile, error) {
+	lock := &LockFile{}
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, lock); err != nil {
+			return nil, fmt.Errorf("failed to decode lockfile: %w", err)
+		}
+	}
+	if lock.Prompts == nil {
+		lock.Prompts = make(map[string]LockEntry)
+	}
+	return lock, nil
+}
+
+// save writes the lockfile through a temporary file, so an interrupted sync leaves the
+// previous lockfile intact
+func (l *LockFile) save(dir string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	path := filepath.Join(dir, LockFileName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"eino/pkg/langfuse/api/resources/prompts/types"
+)
+
+// fakePromptServer keeps prompt versions in memory and serves them two per page
+type fakePromptServer struct {
+	mu       sync.Mutex
+	versions map[string][]types.Prompt
+}
+
+func (s *fakePromptServer) add(name string, content string, labels ...string) types.Prompt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.versions == nil {
+		s.versions = make(map[string][]types.Prompt)
+	}
+	prompt := types.Prompt{
+		ID:      fmt.Sprintf("%s-%d", name, len(s.versions[name])+1),
+		Name:    name,
+		Version: len(s.versions[name]) + 1,
+		Type:    "chat",
+		Prompt:  []types.ChatMessage{{Role: "system", Content: content}},
+		Labels:  labels,
+	}
+	s.versions[name] = append(s.versions[name], prompt)
+	return prompt
+}
+
+func (s *fakePromptServer) latest(name string) types.Prompt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.versions[name]
+	return versions[len(versions)-1]
+}
+
+func (s *fakePromptServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == promptsBasePath:
+		var all []types.Prompt
+		for name, versions := range s.versions {
+			if filter := r.URL.Query().Get("name"); filter == "" || filter == name {
+				all = append(all, versions...)
+			}
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+		const pageSize = 2
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		start := min((page-1)*pageSize, len(all))
+		end := min(start+pageSize, len(all))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": all[start:end],
+			"meta": map[string]interface{}{"page": page, "limit": pageSize, "totalItems": len(all), "totalPages": (len(all) + pageSize - 1) / pageSize},
+		})
+
+	case r.Method == http.MethodPost && r.URL.Path == promptsBasePath:
+		var req types.CreatePromptVersionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		versions := s.versions[req.Name]
+		prompt := types.Prompt{
+			ID:      fmt.Sprintf("%s-%d", req.Name, len(versions)+1),
+			Name:    req.Name,
+			Version: len(versions) + 1,
+			Type:    req.Type,
+			Prompt:  req.Prompt,
+			Config:  req.Config,
+			Labels:  append(req.Labels, "latest"),
+		}
+		for i := range versions {
+			versions[i].Labels = slices.DeleteFunc(versions[i].Labels, func(label string) bool { return label == "latest" })
+		}
+		s.versions[req.Name] = append(versions, prompt)
+		json.NewEncoder(w).Encode(prompt)
+
+	case r.Method == http.MethodPatch && strings.Contains(r.URL.Path, "/versions/"):
+		rest := strings.TrimPrefix(r.URL.Path, promptsBasePath+"/")
+		name, versionText, _ := strings.Cut(rest, "/versions/")
+		version, _ := strconv.Atoi(versionText)
+		var req types.UpdatePromptVersionLabelsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		versions := s.versions[name]
+		for i := range versions {
+			versions[i].Labels = slices.DeleteFunc(versions[i].Labels, func(label string) bool {
+				return slices.Contains(req.NewLabels, label)
+			})
+		}
+		versions[version-1].Labels = req.NewLabels
+		json.NewEncoder(w).Encode(versions[version-1])
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newSyncTestClient(t *testing.T) (*fakePromptServer, *Client) {
+	fake := &fakePromptServer{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewClient(resty.New().SetBaseURL(server.URL))
+}
+
+func readTestPromptFile(t *testing.T, path string) *PromptFile {
+	t.Helper()
+	file, err := readPromptFile(path)
+	require.NoError(t, err)
+	return file
+}
+
+func readTestLockFile(t *testing.T, dir string) *LockFile {
+	t.Helper()
+	lock, err := loadLockFile(dir)
+	require.NoError(t, err)
+	return lock
+}
+
+// editPrompt changes the content of a prompt file as a developer would
+func editPrompt(t *testing.T, path, content string) {
+	t.Helper()
+	file := readTestPromptFile(t, path)
+	file.Prompt[0].Content = content
+	data, err := yaml.Marshal(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func TestSyncToDir(t *testing.T) {
+	fake, client := newSyncTestClient(t)
+	fake.add("greeting", "Say hello.")
+	fake.add("greeting", "Say hello politely.", "production", "latest")
+	fake.add("support/triage", "Classify the ticket.", "latest")
+	dir := t.TempDir()
+
+	delay := 20 * time.Millisecond
+	start := time.Now()
+	result, err := SyncToDir(context.Background(), client, dir, WithRequestDelay(delay))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), delay, "the two list pages are spaced out")
+	assert.Equal(t, []PromptVersion{{Name: "greeting", Version: 2}, {Name: "support/triage", Version: 1}}, result.Updated)
+
+	greeting := readTestPromptFile(t, filepath.Join(dir, "greeting.yaml"))
+	assert.Equal(t, 2, greeting.Version)
+	assert.Equal(t, "chat", greeting.Type)
+	assert.Equal(t, "Say hello politely.", greeting.Prompt[0].Content)
+	assert.Equal(t, []string{"production", "latest"}, greeting.Labels)
+	assert.Equal(t, "support/triage", readTestPromptFile(t, filepath.Join(dir, "support%2Ftriage.yaml")).Name)
+
+	lock := readTestLockFile(t, dir)
+	assert.Equal(t, 2, lock.Prompts["greeting"].Version)
+	assert.Equal(t, "support%2Ftriage.yaml", lock.Prompts["support/triage"].File)
+	assert.True(t, strings.HasPrefix(lock.Prompts["greeting"].Hash, "sha256:"))
+
+	result, err = SyncToDir(context.Background(), client, dir, WithRequestDelay(0))
+	require.NoError(t, err)
+	assert.Empty(t, result.Updated)
+	assert.Equal(t, []string{"greeting", "support/triage"}, result.Unchanged)
+}
+
+func TestPushFromDir(t *testing.T) {
+	fake, client := newSyncTestClient(t)
+	fake.add("greeting", "Say hello.", "production", "latest")
+	fake.add("support/triage", "Classify the ticket.", "latest")
+	dir := t.TempDir()
+	_, err := SyncToDir(context.Background(), client, dir, WithRequestDelay(0))
+	require.NoError(t, err)
+
+	editPrompt(t, filepath.Join(dir, "greeting.yaml"), "Say hello warmly.")
+	newPrompt := &PromptFile{Name: "farewell", Type: "chat", Prompt: []types.ChatMessage{{Role: "system", Content: "Say goodbye."}}}
+	require.NoError(t, writePromptFile(filepath.Join(dir, "farewell.yaml"), newPrompt))
+
+	result, err := PushFromDir(context.Background(), client, dir, WithRequestDelay(0), WithPromoteLabel("production"))
+	require.NoError(t, err)
+	assert.Equal(t, []PromptVersion{{Name: "farewell", Version: 1}, {Name: "greeting", Version: 2}}, result.Updated)
+	assert.Equal(t, []string{"support/triage"}, result.Unchanged)
+
+	remote := fake.latest("greeting")
+	assert.Equal(t, 2, remote.Version)
+	assert.Equal(t, "Say hello warmly.", remote.Prompt[0].Content)
+	assert.ElementsMatch(t, []string{"latest", "production"}, remote.Labels)
+	assert.NotContains(t, fake.versions["greeting"][0].Labels, "production", "the label moved to the new version")
+
+	greeting := readTestPromptFile(t, filepath.Join(dir, "greeting.yaml"))
+	assert.Equal(t, 2, greeting.Version)
+	assert.Equal(t, 2, readTestLockFile(t, dir).Prompts["greeting"].Version)
+
+	// Nothing left to push
+	result, err = PushFromDir(context.Background(), client, dir, WithRequestDelay(0))
+	require.NoError(t, err)
+	assert.Empty(t, result.Updated)
+	assert.Len(t, fake.versions["greeting"], 2)
+}
+
+func TestPushFromDir_Conflict(t *testing.T) {
+	fake, client := newSyncTestClient(t)
+	fake.add("greeting", "Say hello.", "latest")
+	dir := t.TempDir()
+	_, err := SyncToDir(context.Background(), client, dir, WithRequestDelay(0))
+	require.NoError(t, err)
+
+	// Someone else publishes a version while we edit ours
+	fake.add("greeting", "Say hi.")
+	path := filepath.Join(dir, "greeting.yaml")
+	editPrompt(t, path, "Say hello warmly.")
+
+	result, err := PushFromDir(context.Background(), client, dir, WithRequestDelay(0))
+	var conflictErr *ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, Conflict{
+		Name:          "greeting",
+		File:          "greeting.yaml",
+		LockedVersion: 1,
+		RemoteVersion: 2,
+		Reason:        "remote version 2 is newer than synced version 1",
+	}, result.Conflicts[0])
+	assert.Len(t, fake.versions["greeting"], 2, "the remote version is not clobbered")
+	assert.Equal(t, "Say hi.", fake.latest("greeting").Prompt[0].Content)
+
+	// Pulling does not overwrite the unpushed edit either
+	_, err = SyncToDir(context.Background(), client, dir, WithRequestDelay(0))
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "Say hello warmly.", readTestPromptFile(t, path).Prompt[0].Content)
+
+	result, err = PushFromDir(context.Background(), client, dir, WithRequestDelay(0), WithForce())
+	require.NoError(t, err)
+	assert.Equal(t, []PromptVersion{{Name: "greeting", Version: 3}}, result.Updated)
+	assert.Equal(t, "Say hello warmly.", fake.latest("greeting").Prompt[0].Content)
+}
@@ -62,40 +62,40 @@ type Prompt struct {
 // PromptConfig represents configuration for a prompt
 type PromptConfig struct {
 	// Model configuration
-	Model *string `json:"model,omitempty"`
+	Model *string `json:"model,omitempty" yaml:"model,omitempty"`
 
 	// Temperature for generation
-	Temperature *float64 `json:"temperature,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
 
 	// Maximum tokens to generate
-	MaxTokens *int `json:"maxTokens,omitempty"`
+	MaxTokens *int `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`
 
 	// Top-p sampling parameter
-	TopP *float64 `json:"topP,omitempty"`
+	TopP *float64 `json:"topP,omitempty" yaml:"topP,omitempty"`
 
 	// Frequency penalty
-	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty" yaml:"frequencyPenalty,omitempty"`
 
 	// Presence penalty
-	PresencePenalty *float64 `json:"presencePenalty,omitempty"`
+	PresencePenalty *float64 `json:"presencePenalty,omitempty" yaml:"presencePenalty,omitempty"`
 
 	// Stop sequences
-	Stop []string `json:"stop,omitempty"`
+	Stop []string `json:"stop,omitempty" yaml:"stop,omitempty"`
 
 	// Additional model parameters
-	ModelParameters map[string]interface{} `json:"modelParameters,omitempty"`
+	ModelParameters map[string]interface{} `json:"modelParameters,omitempty" yaml:"modelParameters,omitempty"`
 }
 
 // ChatMessage represents a message in a chat prompt
 type ChatMessage struct {
 	// Role of the message sender
-	Role string `json:"role"` // "system", "user", "assistant"
+	Role string `json:"role" yaml:"role"` // "system", "user", "assistant"
 
 	// Content of the message
-	Content string `json:"content"`
+	Content string `json:"content" yaml:"content"`
 
 	// type of the message
-	Type string `json:"type,omitempty"` // "chatmessage"
+	Type string `json:"type,omitempty" yaml:"type,omitempty"` // "chatmessage"
 }
 
 // GetPromptsRequest represents a request to list prompts
@@ -456,3 +456,39 @@ func (p *Prompt) HasTag(tag string) bool {
 	}
 	return false
 }
+
+// CreatePromptVersionRequest creates a new version of the prompt named Name, or its first
+// version when no prompt has that name yet
+type CreatePromptVersionRequest struct {
+	Name   string        `json:"name"`
+	Type   string        `json:"type"` // "text", "chat"
+	Prompt []ChatMessage `json:"prompt"`
+	Config *PromptConfig `json:"config,omitempty"`
+	Labels []string      `json:"labels,omitempty"`
+	Tags   []string      `json:"tags,omitempty"`
+}
+
+// Validate validates the CreatePromptVersionRequest
+func (req *CreatePromptVersionRequest) Validate() error {
+	if req.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+
+	if len(req.Prompt) == 0 {
+		return &ValidationError{Field: "prompt", Message: "at least one message is required"}
+	}
+
+	if req.Config != nil {
+		if err := req.Config.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdatePromptVersionLabelsRequest sets the labels of a prompt version. Labels are unique
+// per prompt, so they are moved from the version that had them.
+type UpdatePromptVersionLabelsRequest struct {
+	NewLabels []string `json:"newLabels"`
+}
@@ -0,0 +1,67 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"eino/pkg/langfuse/api/resources/prompts/types"
+)
+
+// promptVersionPath is the path of one version of a prompt, by name and version
+const promptVersionPath = promptsBasePath + "/%s/versions/%d"
+
+// CreateVersion creates a new version of a prompt from its messages. The version number
+// is assigned by the server: one more than the prompt's latest version, or 1 for a new
+// prompt.
+func (c *Client) CreateVersion(ctx context.Context, req *types.CreatePromptVersionRequest) (*types.Prompt, error) {
+	if req == nil {
+		return nil, fmt.Errorf("create request cannot be nil")
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+
+	response := &types.Prompt{}
+
+	_, err := c.client.R().
+		SetContext(ctx).
+		SetBody(req).
+		SetResult(response).
+		Post(promptsBasePath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create version of prompt %s: %w", req.Name, err)
+	}
+
+	return response, nil
+}
+
+// SetVersionLabels sets the labels of a prompt version, e.g. to promote it to
+// "production". The labels are removed from the version that had them.
+func (c *Client) SetVersionLabels(ctx context.Context, name string, version int, labels []string) (*types.Prompt, error) {
+	if name == "" {
+		return nil, fmt.Errorf("prompt name cannot be empty")
+	}
+
+	if version < 1 {
+		return nil, fmt.Errorf("prompt version must be greater than 0, got %d", version)
+	}
+
+	response := &types.Prompt{}
+
+	path := fmt.Sprintf(promptVersionPath, url.PathEscape(name), version)
+
+	_, err := c.client.R().
+		SetContext(ctx).
+		SetBody(&types.UpdatePromptVersionLabelsRequest{NewLabels: labels}).
+		SetResult(response).
+		Patch(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels of prompt %s version %d: %w", name, version, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,223 @@
+package scores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/scores/types"
+)
+
+const (
+	// DefaultMaxScansLimit is the default number of raw scores the client-side bucketing
+	// fallback reads before giving up
+	DefaultMaxScansLimit = 10000
+
+	// seriesPageSize is the page size used when reading raw scores for local bucketing
+	seriesPageSize = 100
+)
+
+// ErrMaxScansExceeded is returned when computing buckets locally would read more raw
+// scores than the limit set with WithMaxScansLimit
+var ErrMaxScansExceeded = errors.New("too many scores to aggregate locally")
+
+// WithMaxScansLimit bounds how many raw scores GetTimeBucketedAggregation reads when the
+// API cannot bucket scores itself (default DefaultMaxScansLimit)
+func WithMaxScansLimit(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxScans = n
+		}
+	}
+}
+
+// GetTimeBucketedAggregation aggregates scores into consecutive buckets of req.Interval,
+// for plotting score quality over time. Buckets are aligned to UTC hours, days or ISO
+// weeks and cover the requested time range, including buckets without scores.
+//
+// Older self-hosted deployments ignore the interval parameter. When the API returns no
+// buckets, the raw scores matching the request are paged through and bucketed by the
+// client instead; the result then has ComputedLocally set. That read is bounded by
+// WithMaxScansLimit and fails with ErrMaxScansExceeded beyond it, so narrow the time
+// range or filter by name for large projects.
+func (c *Client) GetTimeBucketedAggregation(ctx context.Context, req *types.GetScoreAggregationRequest) (*types.TimeBucketedAggregation, error) {
+	if req == nil || req.Interval == "" {
+		return nil, &types.ValidationError{Field: "interval", Message: "interval is required"}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+
+	// Buckets is left nil when the API does not know the interval parameter
+	var response struct {
+		Buckets []types.ScoreBucket `json:"buckets"`
+	}
+
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParams(aggregationQueryParams(req)).
+		SetResult(&response).
+		Get(scoresAggregationPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score aggregation: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusBadRequest || (!resp.IsError() && response.Buckets == nil):
+		return c.aggregateLocally(ctx, req)
+	case resp.IsError():
+		return nil, fmt.Errorf("failed to get score aggregation: unexpected status %d", resp.StatusCode())
+	}
+
+	return &types.TimeBucketedAggregation{Interval: req.Interval, Buckets: response.Buckets}, nil
+}
+
+// GetDailySeries returns the daily buckets of the scores named name between from and to
+func (c *Client) GetDailySeries(ctx context.Context, name string, from, to time.Time) (*types.TimeBucketedAggregation, error) {
+	if name == "" {
+		return nil, fmt.Errorf("score name cannot be empty")
+	}
+
+	return c.GetTimeBucketedAggregation(ctx, &types.GetScoreAggregationRequest{
+		Name:          &name,
+		FromTimestamp: &from,
+		ToTimestamp:   &to,
+		Interval:      types.AggregationIntervalDay,
+	})
+}
+
+// aggregateLocally pages through the scores matching req and buckets them
+func (c *Client) aggregateLocally(ctx context.Context, req *types.GetScoreAggregationRequest) (*types.TimeBucketedAggregation, error) {
+	var scores []commonTypes.Score
+
+	for page := 1; ; page++ {
+		limit := seriesPageSize
+		resp, err := c.List(ctx, &types.GetScoresRequest{
+			ProjectID:     req.ProjectID,
+			Page:          &page,
+			Limit:         &limit,
+			TraceID:       req.TraceID,
+			ObservationID: req.ObservationID,
+			Name:          req.Name,
+			FromTimestamp: req.FromTimestamp,
+			ToTimestamp:   req.ToTimestamp,
+			UserID:        req.UserID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		scores = append(scores, resp.Data...)
+		if len(scores) > c.maxScans {
+			return nil, fmt.Errorf("failed to aggregate scores: more than %d scores: %w", c.maxScans, ErrMaxScansExceeded)
+		}
+
+		if len(resp.Data) < limit || (resp.Meta.TotalPages > 0 && page >= resp.Meta.TotalPages) {
+			break
+		}
+	}
+
+	return &types.TimeBucketedAggregation{
+		Interval:        req.Interval,
+		Buckets:         bucketScores(scores, req.Interval, req.FromTimestamp, req.ToTimestamp),
+		ComputedLocally: true,
+	}, nil
+}
+
+// bucketScores groups scores into interval buckets from the bucket containing from (or
+// the oldest score) up to to (or the newest score)
+func bucketScores(scores []commonTypes.Score, interval types.AggregationInterval, from, to *time.Time) []types.ScoreBucket {
+	if len(scores) == 0 && (from == nil || to == nil) {
+		return []types.ScoreBucket{}
+	}
+
+	var first, last time.Time
+	for i, score := range scores {
+		if i == 0 || score.Timestamp.Before(first) {
+			first = score.Timestamp
+		}
+		if i == 0 || score.Timestamp.After(last) {
+			last = score.Timestamp
+		}
+	}
+	if from != nil {
+		first = *from
+	}
+
+	valuesByStart := make(map[time.Time][]float64)
+	countsByStart := make(map[time.Time]int)
+	for _, score := range scores {
+		start := bucketStart(score.Timestamp, interval)
+		countsByStart[start]++
+		var value float64
+		if json.Unmarshal(score.Value, &value) == nil {
+			valuesByStart[start] = append(valuesByStart[start], value)
+		}
+	}
+
+	buckets := []types.ScoreBucket{}
+	for start := bucketStart(first, interval); ; {
+		if (to != nil && !start.Before(*to)) || (to == nil && start.After(last)) {
+			break
+		}
+		end := nextBucket(start, interval)
+		bucket := types.ScoreBucket{Start: start, End: end, Count: countsByStart[start]}
+		summarize(&bucket, valuesByStart[start])
+		buckets = append(buckets, bucket)
+		start = end
+	}
+	return buckets
+}
+
+// summarize sets the statistics of a bucket from its numeric values
+func summarize(bucket *types.ScoreBucket, values []float64) {
+	if len(values) == 0 {
+		return
+	}
+
+	sort.Float64s(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	average := sum / float64(len(values))
+	minimum, maximum := values[0], values[len(values)-1]
+	// Nearest-rank percentile
+	p95 := values[int(math.Ceil(0.95*float64(len(values))))-1]
+
+	bucket.Average, bucket.Min, bucket.Max, bucket.P95 = &average, &minimum, &maximum, &p95
+}
+
+// bucketStart returns the start of the UTC bucket containing t; weeks start on Monday
+func bucketStart(t time.Time, interval types.AggregationInterval) time.Time {
+	t = t.UTC()
+	switch interval {
+	case types.AggregationIntervalHour:
+		return t.Truncate(time.Hour)
+	case types.AggregationIntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextBucket returns the start of the bucket following the one starting at start
+func nextBucket(start time.Time, interval types.AggregationInterval) time.Time {
+	switch interval {
+	case types.AggregationIntervalHour:
+		return start.Add(time.Hour)
+	case types.AggregationIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
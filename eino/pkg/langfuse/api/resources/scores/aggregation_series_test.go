@@ -0,0 +1,157 @@
+package scores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// seriesFixture returns 150 "accuracy" scores: 100 on March 1st valued 0.01 to 1.00,
+// and 50 on March 3rd valued 0.5, so the list spans two pages and March 2nd is empty
+func seriesFixture() []commonTypes.Score {
+	day1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+
+	var scores []commonTypes.Score
+	for i := 1; i <= 100; i++ {
+		scores = append(scores, commonTypes.Score{
+			ID:        fmt.Sprintf("score-%d", i),
+			Name:      "accuracy",
+			Timestamp: day1.Add(time.Duration(i) * time.Minute),
+			Value:     json.RawMessage(strconv.FormatFloat(float64(i)/100, 'f', 2, 64)),
+			DataType:  commonTypes.ScoreDataTypeNumeric,
+		})
+	}
+	for i := 0; i < 50; i++ {
+		scores = append(scores, commonTypes.Score{
+			ID:        fmt.Sprintf("score-late-%d", i),
+			Name:      "accuracy",
+			Timestamp: day3.Add(time.Duration(i) * time.Hour / 10),
+			Value:     json.RawMessage("0.5"),
+			DataType:  commonTypes.ScoreDataTypeNumeric,
+		})
+	}
+	return scores
+}
+
+// newLegacyAggregationServer serves scores like a deployment without bucketing support:
+// the aggregation endpoint ignores the interval and the scores list is paginated
+func newLegacyAggregationServer(t *testing.T, scores []commonTypes.Score) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case scoresAggregationPath:
+			w.Write([]byte(`{"data": []}`))
+		case scoresBasePath:
+			assert.Equal(t, "accuracy", r.URL.Query().Get("name"))
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			start := min((page-1)*limit, len(scores))
+			end := min(start+limit, len(scores))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": scores[start:end],
+				"meta": map[string]int{"page": page, "limit": limit, "totalItems": len(scores), "totalPages": (len(scores) + limit - 1) / limit},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_GetTimeBucketedAggregation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, scoresAggregationPath, r.URL.Path)
+		assert.Equal(t, "hour", r.URL.Query().Get("interval"))
+		assert.Equal(t, "latency", r.URL.Query().Get("name"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"interval": "hour", "buckets": [
+			{"start": "2024-03-01T10:00:00Z", "end": "2024-03-01T11:00:00Z", "count": 4, "average": 0.5, "min": 0.1, "max": 0.9, "p95": 0.9}
+		]}`))
+	}))
+	defer server.Close()
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	name := "latency"
+	series, err := client.GetTimeBucketedAggregation(context.Background(), &types.GetScoreAggregationRequest{
+		Name:     &name,
+		Interval: types.AggregationIntervalHour,
+	})
+	require.NoError(t, err)
+	assert.False(t, series.ComputedLocally)
+	require.Len(t, series.Buckets, 1)
+	assert.Equal(t, 4, series.Buckets[0].Count)
+	assert.Equal(t, 0.9, *series.Buckets[0].P95)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), series.Buckets[0].Start)
+
+	_, err = client.GetTimeBucketedAggregation(context.Background(), &types.GetScoreAggregationRequest{})
+	assert.Error(t, err, "the interval is required")
+	_, err = client.GetTimeBucketedAggregation(context.Background(), &types.GetScoreAggregationRequest{Interval: "month"})
+	assert.Error(t, err)
+}
+
+func TestClient_GetDailySeries_LocalFallback(t *testing.T) {
+	server := newLegacyAggregationServer(t, seriesFixture())
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	series, err := client.GetDailySeries(context.Background(), "accuracy", from, from.AddDate(0, 0, 4))
+	require.NoError(t, err)
+	assert.True(t, series.ComputedLocally)
+	assert.Equal(t, types.AggregationIntervalDay, series.Interval)
+	require.Len(t, series.Buckets, 4, "empty days are included")
+
+	first := series.Buckets[0]
+	assert.Equal(t, from, first.Start)
+	assert.Equal(t, from.AddDate(0, 0, 1), first.End)
+	assert.Equal(t, 100, first.Count)
+	assert.InDelta(t, 0.505, *first.Average, 1e-9)
+	assert.Equal(t, 0.01, *first.Min)
+	assert.Equal(t, 1.0, *first.Max)
+	assert.Equal(t, 0.95, *first.P95)
+
+	assert.Zero(t, series.Buckets[1].Count)
+	assert.Nil(t, series.Buckets[1].Average)
+
+	third := series.Buckets[2]
+	assert.Equal(t, 50, third.Count)
+	assert.Equal(t, 0.5, *third.Average)
+	assert.Equal(t, 0.5, *third.P95)
+
+	assert.Zero(t, series.Buckets[3].Count)
+}
+
+func TestClient_GetTimeBucketedAggregation_MaxScansLimit(t *testing.T) {
+	server := newLegacyAggregationServer(t, seriesFixture())
+	client := NewClient(resty.New().SetBaseURL(server.URL), WithMaxScansLimit(120))
+
+	name := "accuracy"
+	_, err := client.GetTimeBucketedAggregation(context.Background(), &types.GetScoreAggregationRequest{
+		Name:     &name,
+		Interval: types.AggregationIntervalWeek,
+	})
+	assert.ErrorIs(t, err, ErrMaxScansExceeded)
+}
+
+func TestBucketStart(t *testing.T) {
+	// Wednesday
+	ts := time.Date(2024, 3, 6, 15, 42, 0, 0, time.FixedZone("CET", 3600))
+
+	assert.Equal(t, time.Date(2024, 3, 6, 14, 0, 0, 0, time.UTC), bucketStart(ts, types.AggregationIntervalHour))
+	assert.Equal(t, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), bucketStart(ts, types.AggregationIntervalDay))
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), bucketStart(ts, types.AggregationIntervalWeek), "weeks start on Monday")
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"eino/pkg/langfuse/api/resources/scores/types"
@@ -18,22 +19,41 @@ const (
 	scoreByIDPath       = "/api/public/scores/%s"
 	scoresAggregationPath = "/api/public/scores/aggregation"
 	scoresStatsPath     = "/api/public/scores/stats"
+	scoresTimeSeriesPath = "/api/public/scores/timeseries"
+	scoreConfigsPath    = "/api/public/score-configs"
+)
+
+const (
+	// deletePageSize is the page size used when collecting the scores to delete
+	deletePageSize = 100
+	// deleteConcurrency bounds the number of in-flight delete requests
+	deleteConcurrency = 5
+	// configsPageSize is the page size used when loading score config names
+	configsPageSize = 100
 )
 
 // Client handles score-related API operations
 type Client struct {
-	client *resty.Client
+	client   *resty.Client
+	names    *nameRegistry
+	maxScans int
 }
 
 // NewClient creates a new scores client
-func NewClient(client *resty.Client) *Client {
-	return &Client{
-		client: client,
+func NewClient(client *resty.Client, opts ...ClientOption) *Client {
+	c := &Client{
+		client:   client,
+		maxScans: DefaultMaxScansLimit,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// Create creates a new score
-func (c *Client) Create(ctx context.Context, req *types.CreateScoreRequest) (*types.CreateScoreResponse, error) {
+// Create creates a new score. With a name registry configured, unknown score names are
+// rejected in strict mode and otherwise reported with the closest registered name.
+func (c *Client) Create(ctx context.Context, req *types.CreateScoreRequest, opts ...CreateOption) (*types.CreateScoreResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("create request cannot be nil")
 	}
@@ -42,6 +62,10 @@ func (c *Client) Create(ctx context.Context, req *types.CreateScoreRequest) (*ty
 		return nil, fmt.Errorf("request validation failed: %w", err)
 	}
 	
+	if err := c.CheckName(ctx, req.Name, opts...); err != nil {
+		return nil, err
+	}
+	
 	response := &types.CreateScoreResponse{}
 	
 	_, err := c.client.R().
@@ -118,6 +142,8 @@ func (c *Client) List(ctx context.Context, req *types.GetScoresRequest) (*types.
 		queryParams["source"] = *req.Source
 	}
 	
+	commonTypes.SetSortQueryParams(queryParams, req.SortBy, req.SortOrder)
+	
 	response := &types.GetScoresResponse{}
 	
 	request := c.client.R().
@@ -168,7 +194,7 @@ func (c *Client) Delete(ctx context.Context, scoreID string) error {
 	
 	path := fmt.Sprintf(scoreByIDPath, url.PathEscape(scoreID))
 	
-	_, err := c.client.R().
+	resp, err := c.client.R().
 		SetContext(ctx).
 		Delete(path)
 	
@@ -176,6 +202,10 @@ func (c *Client) Delete(ctx context.Context, scoreID string) error {
 		return fmt.Errorf("failed to delete score %s: %w", scoreID, err)
 	}
 	
+	if resp.IsError() {
+		return fmt.Errorf("failed to delete score %s: unexpected status %d", scoreID, resp.StatusCode())
+	}
+	
 	return nil
 }
 
@@ -189,7 +219,30 @@ func (c *Client) GetAggregation(ctx context.Context, req *types.GetScoreAggregat
 		return nil, fmt.Errorf("request validation failed: %w", err)
 	}
 	
-	// Build query parameters
+	queryParams := aggregationQueryParams(req)
+	
+	response := &types.GetScoreAggregationResponse{}
+	
+	request := c.client.R().
+		SetContext(ctx).
+		SetResult(response)
+	
+	// Add query parameters
+	for key, value := range queryParams {
+		request.SetQueryParam(key, value)
+	}
+	
+	_, err := request.Get(scoresAggregationPath)
+	
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score aggregation: %w", err)
+	}
+	
+	return response, nil
+}
+
+// aggregationQueryParams builds the query parameters of an aggregation request
+func aggregationQueryParams(req *types.GetScoreAggregationRequest) map[string]string {
 	queryParams := make(map[string]string)
 	
 	if req.ProjectID != "" {
@@ -224,24 +277,11 @@ func (c *Client) GetAggregation(ctx context.Context, req *types.GetScoreAggregat
 		queryParams["groupBy"] = strings.Join(req.GroupBy, ",")
 	}
 	
-	response := &types.GetScoreAggregationResponse{}
-	
-	request := c.client.R().
-		SetContext(ctx).
-		SetResult(response)
-	
-	// Add query parameters
-	for key, value := range queryParams {
-		request.SetQueryParam(key, value)
+	if req.Interval != "" {
+		queryParams["interval"] = string(req.Interval)
 	}
 	
-	_, err := request.Get(scoresAggregationPath)
-	
-	if err != nil {
-		return nil, fmt.Errorf("failed to get score aggregation: %w", err)
-	}
-	
-	return response, nil
+	return queryParams
 }
 
 // GetStats retrieves statistics for scores
@@ -378,6 +418,224 @@ func (c *Client) ListByObservation(ctx context.Context, observationID string, li
 	return c.List(ctx, req)
 }
 
+// DeleteByTrace deletes every score attached to a trace and returns the number deleted.
+// All pages are listed before deleting so that removals don't shift later pages.
+// Deletes run with bounded concurrency; on failure the count of successful deletes
+// is returned alongside the first error.
+func (c *Client) DeleteByTrace(ctx context.Context, traceID string) (int, error) {
+	if traceID == "" {
+		return 0, fmt.Errorf("trace ID cannot be empty")
+	}
+	
+	scoreIDs, err := c.listScoreIDs(ctx, &types.GetScoresRequest{TraceID: &traceID}, nil)
+	if err != nil {
+		return 0, err
+	}
+	
+	deleted, _, err := c.deleteScores(ctx, scoreIDs)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete scores for trace %s: %w", traceID, err)
+	}
+	
+	return deleted, nil
+}
+
+// BatchDelete deletes the scores listed in req.ScoreIDs together with every score
+// matching its TraceID, Name and Before filters. Matching scores are listed before any
+// is deleted, and deletes run with bounded concurrency.
+//
+// The response counts the deleted scores and lists the IDs that could not be deleted;
+// when any delete fails it is returned alongside an error wrapping the first failure.
+func (c *Client) BatchDelete(ctx context.Context, req *types.BatchDeleteScoresRequest) (*types.BatchDeleteScoresResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("batch delete request cannot be nil")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	
+	scoreIDs := req.ScoreIDs
+	if req.HasFilters() {
+		filter := &types.GetScoresRequest{
+			TraceID:     req.TraceID,
+			Name:        req.Name,
+			ToTimestamp: req.Before,
+		}
+		// The API's upper bound is inclusive, so scores at exactly Before are skipped here
+		var keep func(score commonTypes.Score) bool
+		if req.Before != nil {
+			before := *req.Before
+			keep = func(score commonTypes.Score) bool { return score.Timestamp.Before(before) }
+		}
+		
+		matched, err := c.listScoreIDs(ctx, filter, keep)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scores to delete: %w", err)
+		}
+		scoreIDs = append(append([]string(nil), req.ScoreIDs...), matched...)
+	}
+	
+	deleted, failed, err := c.deleteScores(ctx, dedupeIDs(scoreIDs))
+	response := &types.BatchDeleteScoresResponse{Deleted: deleted, Failed: failed}
+	if err != nil {
+		return response, fmt.Errorf("failed to delete %d of %d scores: %w", len(failed), deleted+len(failed), err)
+	}
+	
+	return response, nil
+}
+
+// deleteScores deletes scores with bounded concurrency, returning the number deleted, the
+// IDs that were not deleted and the first error encountered. Once ctx is done the
+// remaining IDs are reported as failed without being attempted.
+func (c *Client) deleteScores(ctx context.Context, scoreIDs []string) (int, []string, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		deleted  int
+		failed   []string
+		firstErr error
+	)
+	sem := make(chan struct{}, deleteConcurrency)
+	
+	for i, scoreID := range scoreIDs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return deleted, append(failed, scoreIDs[i:]...), ctx.Err()
+		}
+		
+		wg.Add(1)
+		go func(scoreID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			
+			err := c.Delete(ctx, scoreID)
+			
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, scoreID)
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			deleted++
+		}(scoreID)
+	}
+	
+	wg.Wait()
+	
+	return deleted, failed, firstErr
+}
+
+// ListConfigs retrieves a page of score configurations
+func (c *Client) ListConfigs(ctx context.Context, req *types.GetScoreConfigsRequest) (*types.GetScoreConfigsResponse, error) {
+	if req == nil {
+		req = &types.GetScoreConfigsRequest{}
+	}
+	
+	response := &types.GetScoreConfigsResponse{}
+	
+	request := c.client.R().
+		SetContext(ctx).
+		SetResult(response)
+	
+	if req.ProjectID != "" {
+		request.SetQueryParam("projectId", req.ProjectID)
+	}
+	if req.Page != nil {
+		request.SetQueryParam("page", strconv.Itoa(*req.Page))
+	}
+	if req.Limit != nil {
+		request.SetQueryParam("limit", strconv.Itoa(*req.Limit))
+	}
+	if req.DataType != nil {
+		request.SetQueryParam("dataType", string(*req.DataType))
+	}
+	if req.IsArchived != nil {
+		request.SetQueryParam("isArchived", strconv.FormatBool(*req.IsArchived))
+	}
+	
+	resp, err := request.Get(scoreConfigsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list score configs: %w", err)
+	}
+	
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to list score configs: unexpected status %d", resp.StatusCode())
+	}
+	
+	return response, nil
+}
+
+// scoreConfigNames returns the names of all score configs that are not archived
+func (c *Client) scoreConfigNames(ctx context.Context) ([]string, error) {
+	configs, err := commonTypes.NewPagePaginator(func(page, limit int) ([]types.ScoreConfig, int, error) {
+		resp, err := c.ListConfigs(ctx, &types.GetScoreConfigsRequest{Page: &page, Limit: &limit})
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Data, resp.Meta.TotalPages, nil
+	}, configsPageSize).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	var names []string
+	for _, config := range configs {
+		if !config.IsArchived {
+			names = append(names, config.Name)
+		}
+	}
+	
+	return names, nil
+}
+
+// listScoreIDs collects the IDs of all scores matching filter across every page,
+// skipping scores rejected by keep when it is set. The filter's Page and Limit are
+// overwritten.
+func (c *Client) listScoreIDs(ctx context.Context, filter *types.GetScoresRequest, keep func(score commonTypes.Score) bool) ([]string, error) {
+	scores, err := commonTypes.NewPagePaginator(func(page, limit int) ([]commonTypes.Score, int, error) {
+		filter.Page = &page
+		filter.Limit = &limit
+		resp, err := c.List(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Data, resp.Meta.TotalPages, nil
+	}, deletePageSize).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	var scoreIDs []string
+	seen := make(map[string]bool)
+	for _, score := range scores {
+		if !seen[score.ID] && (keep == nil || keep(score)) {
+			seen[score.ID] = true
+			scoreIDs = append(scoreIDs, score.ID)
+		}
+	}
+	
+	return scoreIDs, nil
+}
+
+// dedupeIDs returns ids without duplicates, keeping the first occurrence of each
+func dedupeIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
 // CreateNumeric creates a numeric score
 func (c *Client) CreateNumeric(ctx context.Context, traceID, name string, value float64) (*types.CreateScoreResponse, error) {
 	req := types.NewNumericScoreRequest(traceID, name, value)
@@ -2,14 +2,19 @@ package scores
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"eino/pkg/langfuse/api/resources/scores/types"
 	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
 	commonErrors "eino/pkg/langfuse/api/resources/commons/errors"
@@ -879,6 +884,294 @@ func TestClient_Exists(t *testing.T) {
 	}
 }
 
+func TestClient_DeleteByTrace(t *testing.T) {
+	const totalScores = 250
+	
+	var (
+		mu          sync.Mutex
+		deleted     = make(map[string]int)
+		listCalls   int
+		inFlight    int
+		maxInFlight int
+	)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "/api/public/scores", r.URL.Path)
+			assert.Equal(t, "trace-123", r.URL.Query().Get("traceId"))
+			
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			mu.Lock()
+			listCalls++
+			mu.Unlock()
+			
+			data := []map[string]interface{}{}
+			for i := (page - 1) * limit; i < page*limit && i < totalScores; i++ {
+				data = append(data, map[string]interface{}{
+					"id":      fmt.Sprintf("score-%d", i),
+					"traceId": "trace-123",
+					"name":    "accuracy",
+				})
+			}
+			
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": data,
+				"meta": map[string]int{
+					"page":       page,
+					"limit":      limit,
+					"totalItems": totalScores,
+					"totalPages": (totalScores + limit - 1) / limit,
+				},
+			})
+		case http.MethodDelete:
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			
+			time.Sleep(time.Millisecond)
+			
+			mu.Lock()
+			inFlight--
+			deleted[strings.TrimPrefix(r.URL.Path, "/api/public/scores/")]++
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	
+	count, err := client.DeleteByTrace(context.Background(), "trace-123")
+	require.NoError(t, err)
+	assert.Equal(t, totalScores, count)
+	
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, listCalls)
+	assert.Len(t, deleted, totalScores)
+	for i := 0; i < totalScores; i++ {
+		assert.Equal(t, 1, deleted[fmt.Sprintf("score-%d", i)], "score-%d should be deleted exactly once", i)
+	}
+	assert.LessOrEqual(t, maxInFlight, deleteConcurrency)
+	
+	_, err = client.DeleteByTrace(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestClient_BatchDelete(t *testing.T) {
+	before := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	scores := []map[string]interface{}{
+		{"id": "score-1", "traceId": "trace-1", "name": "accuracy", "timestamp": before.Add(-2 * time.Hour)},
+		{"id": "score-2", "traceId": "trace-1", "name": "accuracy", "timestamp": before.Add(-time.Hour)},
+		{"id": "score-3", "traceId": "trace-1", "name": "accuracy", "timestamp": before},
+		{"id": "score-4", "traceId": "trace-1", "name": "latency", "timestamp": before.Add(-time.Hour)},
+		{"id": "score-5", "traceId": "trace-2", "name": "accuracy", "timestamp": before.Add(-time.Hour)},
+	}
+	
+	var (
+		mu        sync.Mutex
+		deleted   map[string]int
+		listCalls int
+	)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			mu.Lock()
+			listCalls++
+			mu.Unlock()
+			
+			data := []map[string]interface{}{}
+			for _, score := range scores {
+				if traceID := query.Get("traceId"); traceID != "" && score["traceId"] != traceID {
+					continue
+				}
+				if name := query.Get("name"); name != "" && score["name"] != name {
+					continue
+				}
+				if to := query.Get("toTimestamp"); to != "" {
+					toTime, err := time.Parse(time.RFC3339, to)
+					require.NoError(t, err)
+					if score["timestamp"].(time.Time).After(toTime) {
+						continue
+					}
+				}
+				data = append(data, score)
+			}
+			
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": data,
+				"meta": map[string]int{"page": 1, "limit": 100, "totalItems": len(data), "totalPages": 1},
+			})
+		case http.MethodDelete:
+			id := strings.TrimPrefix(r.URL.Path, "/api/public/scores/")
+			if id == "score-fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			mu.Lock()
+			deleted[id]++
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	ctx := context.Background()
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		deleted = make(map[string]int)
+		listCalls = 0
+	}
+	
+	t.Run("filters expand to matching score IDs", func(t *testing.T) {
+		reset()
+		resp, err := client.BatchDelete(ctx, &types.BatchDeleteScoresRequest{
+			ScoreIDs: []string{"score-5", "score-1"},
+			TraceID:  stringPtr("trace-1"),
+			Name:     stringPtr("accuracy"),
+			Before:   &before,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &types.BatchDeleteScoresResponse{Deleted: 3}, resp)
+		
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, map[string]int{"score-1": 1, "score-2": 1, "score-5": 1}, deleted)
+		assert.Equal(t, 1, listCalls)
+	})
+	
+	t.Run("score IDs only", func(t *testing.T) {
+		reset()
+		resp, err := client.BatchDelete(ctx, &types.BatchDeleteScoresRequest{
+			ScoreIDs: []string{"score-4", "score-fail"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to delete 1 of 2 scores")
+		assert.Equal(t, 1, resp.Deleted)
+		assert.Equal(t, []string{"score-fail"}, resp.Failed)
+		
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 0, listCalls, "no listing without filters")
+	})
+	
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := client.BatchDelete(ctx, nil)
+		assert.Error(t, err)
+		
+		_, err = client.BatchDelete(ctx, &types.BatchDeleteScoresRequest{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one score ID or filter is required")
+		
+		_, err = client.BatchDelete(ctx, &types.BatchDeleteScoresRequest{ScoreIDs: []string{""}})
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_ListByModel(t *testing.T) {
+	const totalGenerations = 150
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		
+		switch r.URL.Path {
+		case "/api/public/observations":
+			assert.Equal(t, "GENERATION", query.Get("type"))
+			page, _ := strconv.Atoi(query.Get("page"))
+			limit, _ := strconv.Atoi(query.Get("limit"))
+			
+			// Every third generation uses gpt-4
+			data := []map[string]interface{}{}
+			for i := (page - 1) * limit; i < page*limit && i < totalGenerations; i++ {
+				model := "claude"
+				if i%3 == 0 {
+					model = "gpt-4"
+				}
+				data = append(data, map[string]interface{}{"id": fmt.Sprintf("obs-%d", i), "type": "GENERATION", "model": model})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": data,
+				"meta": map[string]int{"page": page, "limit": limit, "totalPages": (totalGenerations + limit - 1) / limit},
+			})
+		case "/api/public/scores":
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			
+			time.Sleep(time.Millisecond)
+			
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			
+			observationID := query.Get("observationId")
+			var index int
+			fmt.Sscanf(observationID, "obs-%d", &index)
+			assert.Zero(t, index%3, "scores requested for %s, which does not use gpt-4", observationID)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{
+					"id":            "score-" + observationID,
+					"observationId": observationID,
+					"name":          "quality",
+					"timestamp":     base.Add(time.Duration(index) * time.Minute),
+				}},
+			})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	
+	resp, err := client.ListByModel(context.Background(), "gpt-4", 20, WithParallelism(3))
+	require.NoError(t, err)
+	
+	require.Len(t, resp.Data, 20)
+	assert.Equal(t, "score-obs-147", resp.Data[0].ID, "newest score first")
+	for i := 1; i < len(resp.Data); i++ {
+		assert.True(t, resp.Data[i-1].Timestamp.After(resp.Data[i].Timestamp))
+	}
+	assert.Equal(t, 50, resp.Meta.TotalItems)
+	assert.Equal(t, 3, resp.Meta.TotalPages)
+	assert.True(t, resp.Meta.HasNextPage)
+	
+	mu.Lock()
+	assert.LessOrEqual(t, maxInFlight, 3)
+	mu.Unlock()
+	
+	_, err = client.ListByModel(context.Background(), "", 20)
+	assert.Error(t, err)
+	_, err = client.ListByModel(context.Background(), "gpt-4", 0)
+	assert.Error(t, err)
+}
+
 func TestClient_ContextPropagation(t *testing.T) {
 	// Create test server that verifies context
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,197 @@
+package scores
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"eino/pkg/langfuse/api/resources/scores/types"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	paginationTypes "eino/pkg/langfuse/api/resources/utils/pagination/types"
+)
+
+const (
+	observationsBasePath = "/api/public/observations"
+
+	// listByModelPageSize is the page size used when scanning generations for a model
+	listByModelPageSize = 100
+	// listByModelBatchSize is the number of observations each worker handles per job
+	listByModelBatchSize = 10
+	// defaultListByModelParallelism bounds the number of concurrent score lookups
+	defaultListByModelParallelism = 5
+)
+
+// ListByModelOption configures ListByModel
+type ListByModelOption func(*listByModelOptions)
+
+type listByModelOptions struct {
+	parallelism int
+}
+
+// WithParallelism sets how many batches of observations ListByModel queries concurrently
+func WithParallelism(n int) ListByModelOption {
+	return func(o *listByModelOptions) {
+		if n > 0 {
+			o.parallelism = n
+		}
+	}
+}
+
+// observationsPage is one page of the observations list endpoint
+type observationsPage struct {
+	Data []commonTypes.Observation   `json:"data"`
+	Meta paginationTypes.MetaResponse `json:"meta"`
+}
+
+// ListByModel retrieves the scores attached to generations that used the given model.
+//
+// The observations API cannot filter by model, so every generation is listed and
+// matched on its model name client-side; the observation IDs are then split into
+// batches whose scores are fetched with ListByObservation by a bounded worker pool
+// (see WithParallelism). The combined scores are sorted newest first and returned as
+// the first page of at most limit items, with Meta describing the full result.
+func (c *Client) ListByModel(ctx context.Context, modelName string, limit int, opts ...ListByModelOption) (*types.GetScoresResponse, error) {
+	if modelName == "" {
+		return nil, fmt.Errorf("model name cannot be empty")
+	}
+	if limit < 1 || limit > 1000 {
+		return nil, &types.ValidationError{Field: "limit", Message: "limit must be between 1 and 1000"}
+	}
+
+	options := listByModelOptions{parallelism: defaultListByModelParallelism}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	observationIDs, err := c.listObservationIDsByModel(ctx, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := c.listScoresByObservations(ctx, observationIDs, limit, options.parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scores for model %s: %w", modelName, err)
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Timestamp.After(scores[j].Timestamp)
+	})
+
+	total := len(scores)
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+
+	return &types.GetScoresResponse{
+		Data: scores,
+		Meta: paginationTypes.MetaResponse{
+			Page:        1,
+			Limit:       limit,
+			TotalItems:  total,
+			TotalPages:  (total + limit - 1) / limit,
+			HasNextPage: total > limit,
+		},
+	}, nil
+}
+
+// listObservationIDsByModel collects the IDs of all generations using modelName
+func (c *Client) listObservationIDsByModel(ctx context.Context, modelName string) ([]string, error) {
+	var observationIDs []string
+
+	for page := 1; ; page++ {
+		resp := &observationsPage{}
+		_, err := c.client.R().
+			SetContext(ctx).
+			SetQueryParams(map[string]string{
+				"type":  string(commonTypes.ObservationTypeGeneration),
+				"page":  strconv.Itoa(page),
+				"limit": strconv.Itoa(listByModelPageSize),
+			}).
+			SetResult(resp).
+			Get(observationsBasePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list observations for model %s: %w", modelName, err)
+		}
+
+		for _, observation := range resp.Data {
+			if observation.Model != nil && *observation.Model == modelName {
+				observationIDs = append(observationIDs, observation.ID)
+			}
+		}
+
+		if len(resp.Data) < listByModelPageSize || (resp.Meta.TotalPages > 0 && page >= resp.Meta.TotalPages) {
+			break
+		}
+	}
+
+	return observationIDs, nil
+}
+
+// listScoresByObservations fetches the scores of every observation, processing batches
+// of IDs with at most parallelism workers. The first error cancels the remaining work.
+func (c *Client) listScoresByObservations(ctx context.Context, observationIDs []string, limit, parallelism int) ([]commonTypes.Score, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []string)
+	go func() {
+		defer close(batches)
+		for start := 0; start < len(observationIDs); start += listByModelBatchSize {
+			end := start + listByModelBatchSize
+			if end > len(observationIDs) {
+				end = len(observationIDs)
+			}
+			select {
+			case batches <- observationIDs[start:end]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		scores   []commonTypes.Score
+		seen     = make(map[string]bool)
+		firstErr error
+	)
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				for _, observationID := range batch {
+					resp, err := c.ListByObservation(ctx, observationID, limit)
+
+					mu.Lock()
+					if err != nil {
+						if firstErr == nil {
+							firstErr = err
+							cancel()
+						}
+						mu.Unlock()
+						return
+					}
+					for _, score := range resp.Data {
+						if !seen[score.ID] {
+							seen[score.ID] = true
+							scores = append(scores, score)
+						}
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return scores, nil
+}
@@ -0,0 +1,208 @@
+package scores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownScoreName is returned by Create in strict mode when the score name is not
+// registered
+var ErrUnknownScoreName = errors.New("unknown score name")
+
+// maxSuggestionDistance is the largest edit distance at which a registered name is
+// suggested for an unknown one
+const maxSuggestionDistance = 2
+
+// NameRegistryConfig configures the score names Create accepts
+type NameRegistryConfig struct {
+	// Names are always accepted
+	Names []string
+
+	// RefreshInterval, when positive, also accepts the names of the project's score
+	// configs, reloaded once they are older than the interval
+	RefreshInterval time.Duration
+
+	// Strict rejects unknown names; otherwise they are sent after a warning
+	Strict bool
+
+	// Warn receives the warning for an unknown name in lenient mode (default log.Print)
+	Warn func(message string)
+}
+
+// ClientOption configures the scores client
+type ClientOption func(*Client)
+
+// WithNameRegistry makes Create check score names against a registry, catching typos
+// that would otherwise start a new score series
+func WithNameRegistry(cfg NameRegistryConfig) ClientOption {
+	return func(c *Client) {
+		c.names = newNameRegistry(cfg, c.scoreConfigNames)
+	}
+}
+
+// CreateOption configures a single Create call
+type CreateOption func(*createOptions)
+
+type createOptions struct {
+	allowUnregisteredName bool
+	environment           string
+}
+
+// AllowUnregisteredName lets a score through the name registry, for deliberately new
+// or one-off score names
+func AllowUnregisteredName() CreateOption {
+	return func(o *createOptions) {
+		o.allowUnregisteredName = true
+	}
+}
+
+// WithEnvironment names the environment of the scored trace, so a score sent through the
+// ingestion API goes to the Langfuse project of that environment like the trace did
+func WithEnvironment(environment string) CreateOption {
+	return func(o *createOptions) {
+		o.environment = environment
+	}
+}
+
+// EnvironmentOf returns the environment set with WithEnvironment, or "" if none is
+func EnvironmentOf(opts ...CreateOption) string {
+	var options createOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options.environment
+}
+
+// CheckName checks name against the registry configured with WithNameRegistry like
+// Create does, for scores submitted another way such as through the ingestion API. It
+// returns nil without a registry.
+func (c *Client) CheckName(ctx context.Context, name string, opts ...CreateOption) error {
+	var options createOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if c.names == nil || options.allowUnregisteredName {
+		return nil
+	}
+	return c.names.check(ctx, name)
+}
+
+// nameRegistry holds the accepted score names
+type nameRegistry struct {
+	static          map[string]struct{}
+	refreshInterval time.Duration
+	strict          bool
+	warn            func(message string)
+	load            func(ctx context.Context) ([]string, error)
+
+	mu       sync.Mutex
+	loaded   map[string]struct{}
+	loadedAt time.Time
+}
+
+func newNameRegistry(cfg NameRegistryConfig, load func(ctx context.Context) ([]string, error)) *nameRegistry {
+	r := &nameRegistry{
+		static:          make(map[string]struct{}, len(cfg.Names)),
+		refreshInterval: cfg.RefreshInterval,
+		strict:          cfg.Strict,
+		warn:            cfg.Warn,
+		load:            load,
+	}
+	for _, name := range cfg.Names {
+		r.static[name] = struct{}{}
+	}
+	if r.warn == nil {
+		r.warn = func(message string) { log.Print(message) }
+	}
+	return r
+}
+
+// check returns an error for an unknown name in strict mode, and otherwise warns about it
+func (r *nameRegistry) check(ctx context.Context, name string) error {
+	names := r.names(ctx)
+	if _, ok := names[name]; ok {
+		return nil
+	}
+
+	message := fmt.Sprintf("%q is not a registered score name", name)
+	if suggestion := closestName(name, names); suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	if r.strict {
+		return fmt.Errorf("%w: %s", ErrUnknownScoreName, message)
+	}
+	r.warn("langfuse: " + message)
+	return nil
+}
+
+// names returns the accepted names, reloading the score config names once they are
+// stale. A failed reload keeps the previous names until the next interval.
+func (r *nameRegistry) names(ctx context.Context) map[string]struct{} {
+	if r.refreshInterval <= 0 {
+		return r.static
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loaded == nil || time.Since(r.loadedAt) >= r.refreshInterval {
+		r.loadedAt = time.Now()
+		if loaded, err := r.load(ctx); err == nil {
+			r.loaded = make(map[string]struct{}, len(r.static)+len(loaded))
+			for name := range r.static {
+				r.loaded[name] = struct{}{}
+			}
+			for _, name := range loaded {
+				r.loaded[name] = struct{}{}
+			}
+		} else if r.loaded == nil {
+			return r.static
+		}
+	}
+	return r.loaded
+}
+
+// closestName returns the registered name nearest to name within maxSuggestionDistance,
+// preferring the alphabetically first on ties, or "" if there is none
+func closestName(name string, names map[string]struct{}) string {
+	candidates := make([]string, 0, len(names))
+	for candidate := range names {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range candidates {
+		if d := levenshtein(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(t)]
+}
@@ -0,0 +1,156 @@
+package scores
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// scoreServer accepts score creations and serves the given score configs
+type scoreServer struct {
+	*httptest.Server
+	created     atomic.Int32
+	configLoads atomic.Int32
+	configs     atomic.Value // []types.ScoreConfig
+}
+
+func newScoreServer(t *testing.T, configs ...types.ScoreConfig) *scoreServer {
+	s := &scoreServer{}
+	s.configs.Store(configs)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case scoresBasePath:
+			s.created.Add(1)
+			w.Write([]byte(`{"id":"score-1"}`))
+		case scoreConfigsPath:
+			s.configLoads.Add(1)
+			json.NewEncoder(w).Encode(types.GetScoreConfigsResponse{Data: s.configs.Load().([]types.ScoreConfig)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *scoreServer) client(cfg NameRegistryConfig) *Client {
+	return NewClient(resty.New().SetBaseURL(s.URL), WithNameRegistry(cfg))
+}
+
+func createScore(ctx context.Context, client *Client, name string, opts ...CreateOption) error {
+	_, err := client.Create(ctx, &types.CreateScoreRequest{TraceID: "trace-1", Name: name, Value: 0.9, DataType: commonTypes.ScoreDataTypeNumeric}, opts...)
+	return err
+}
+
+func TestClient_CreateNameRegistry(t *testing.T) {
+	ctx := context.Background()
+	names := []string{"accuracy", "helpfulness", "latency"}
+
+	t.Run("registered name", func(t *testing.T) {
+		server := newScoreServer(t)
+		var warnings []string
+		client := server.client(NameRegistryConfig{Names: names, Warn: func(message string) { warnings = append(warnings, message) }})
+
+		require.NoError(t, createScore(ctx, client, "accuracy"))
+		assert.Empty(t, warnings)
+		assert.Equal(t, int32(1), server.created.Load())
+	})
+
+	t.Run("near miss is sent with a suggestion in lenient mode", func(t *testing.T) {
+		server := newScoreServer(t)
+		var warnings []string
+		client := server.client(NameRegistryConfig{Names: names, Warn: func(message string) { warnings = append(warnings, message) }})
+
+		require.NoError(t, createScore(ctx, client, "accurracy"))
+		require.NoError(t, createScore(ctx, client, "throughput"))
+		assert.Equal(t, []string{
+			`langfuse: "accurracy" is not a registered score name (did you mean "accuracy"?)`,
+			`langfuse: "throughput" is not a registered score name`,
+		}, warnings)
+		assert.Equal(t, int32(2), server.created.Load())
+	})
+
+	t.Run("strict mode rejects unknown names", func(t *testing.T) {
+		server := newScoreServer(t)
+		client := server.client(NameRegistryConfig{Names: names, Strict: true})
+
+		err := createScore(ctx, client, "latancy")
+		require.ErrorIs(t, err, ErrUnknownScoreName)
+		assert.Contains(t, err.Error(), `did you mean "latency"?`)
+		assert.Zero(t, server.created.Load())
+	})
+
+	t.Run("bypass", func(t *testing.T) {
+		server := newScoreServer(t)
+		client := server.client(NameRegistryConfig{Names: names, Strict: true})
+
+		require.NoError(t, createScore(ctx, client, "experimental_metric", AllowUnregisteredName()))
+		assert.Equal(t, int32(1), server.created.Load())
+	})
+
+	t.Run("no registry", func(t *testing.T) {
+		server := newScoreServer(t)
+		client := NewClient(resty.New().SetBaseURL(server.URL))
+
+		require.NoError(t, createScore(ctx, client, "anything"))
+	})
+}
+
+func TestClient_CreateNameRegistryFromScoreConfigs(t *testing.T) {
+	ctx := context.Background()
+	server := newScoreServer(t,
+		types.ScoreConfig{Name: "correctness"},
+		types.ScoreConfig{Name: "retired", IsArchived: true},
+	)
+	client := server.client(NameRegistryConfig{Names: []string{"accuracy"}, RefreshInterval: 50 * time.Millisecond, Strict: true})
+
+	require.NoError(t, createScore(ctx, client, "accuracy"))
+	require.NoError(t, createScore(ctx, client, "correctness"))
+	assert.ErrorIs(t, createScore(ctx, client, "retired"), ErrUnknownScoreName, "archived configs are not registered")
+	assert.ErrorIs(t, createScore(ctx, client, "toxicity"), ErrUnknownScoreName)
+	assert.Equal(t, int32(1), server.configLoads.Load(), "configs are cached until the interval passes")
+
+	server.configs.Store([]types.ScoreConfig{{Name: "correctness"}, {Name: "toxicity"}})
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, createScore(ctx, client, "toxicity"))
+	assert.Equal(t, int32(2), server.configLoads.Load())
+}
+
+func TestClosestName(t *testing.T) {
+	names := map[string]struct{}{"accuracy": {}, "latency": {}, "cost": {}, "costs": {}}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "accurracy", expected: "accuracy"},
+		{name: "acuracy", expected: "accuracy"},
+		{name: "latnecy", expected: "latency"},
+		{name: "coast", expected: "cost"},
+		{name: "acc", expected: ""},
+		{name: "throughput", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, closestName(tt.name, names))
+		})
+	}
+
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 0, levenshtein("", ""))
+	assert.Equal(t, 4, levenshtein("", "cost"))
+	assert.Equal(t, 2, levenshtein("naïve", "nave!"), "runes, not bytes")
+}
@@ -0,0 +1,49 @@
+package scores
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// GetTimeSeriesData returns the count, average, minimum and maximum of the scores named
+// req.Name in consecutive buckets of req.BucketSize between req.FromTimestamp and
+// req.ToTimestamp, so a score's trend can be read in a single call.
+func (c *Client) GetTimeSeriesData(ctx context.Context, req *types.ScoreTimeSeriesRequest) (*types.ScoreTimeSeriesResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("time series request cannot be nil")
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+
+	queryParams := map[string]string{
+		"name":              req.Name,
+		"bucketSizeSeconds": strconv.FormatInt(int64(req.BucketSize.Seconds()), 10),
+		"fromTimestamp":     req.FromTimestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"toTimestamp":       req.ToTimestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+	if req.DataType != "" {
+		queryParams["dataType"] = string(req.DataType)
+	}
+
+	response := &types.ScoreTimeSeriesResponse{}
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParams(queryParams).
+		SetResult(response).
+		Get(scoresTimeSeriesPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score time series: %w", err)
+	}
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to get score time series: unexpected status %d", resp.StatusCode())
+	}
+
+	return response, nil
+}
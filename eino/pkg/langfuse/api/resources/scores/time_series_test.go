@@ -0,0 +1,87 @@
+package scores
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/scores/types"
+)
+
+func TestClient_GetTimeSeriesData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, scoresTimeSeriesPath, r.URL.Path)
+		query := r.URL.Query()
+		assert.Equal(t, "accuracy", query.Get("name"))
+		assert.Equal(t, "NUMERIC", query.Get("dataType"))
+		assert.Equal(t, "1800", query.Get("bucketSizeSeconds"))
+		assert.Equal(t, "2024-03-01T10:00:00.000Z", query.Get("fromTimestamp"))
+		assert.Equal(t, "2024-03-01T11:00:00.000Z", query.Get("toTimestamp"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"buckets": [
+			{"startTime": "2024-03-01T10:00:00Z", "count": 3, "average": 0.6, "min": 0.2, "max": 0.9},
+			{"startTime": "2024-03-01T10:30:00Z", "count": 0, "average": 0, "min": 0, "max": 0}
+		]}`))
+	}))
+	defer server.Close()
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	series, err := client.GetTimeSeriesData(context.Background(), &types.ScoreTimeSeriesRequest{
+		Name:          "accuracy",
+		DataType:      commonTypes.ScoreDataTypeNumeric,
+		BucketSize:    30 * time.Minute,
+		FromTimestamp: from,
+		ToTimestamp:   from.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.ScoreTimeBucket{
+		{StartTime: from, Count: 3, Average: 0.6, Min: 0.2, Max: 0.9},
+		{StartTime: from.Add(30 * time.Minute)},
+	}, series.Buckets)
+}
+
+func TestClient_GetTimeSeriesData_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	ctx := context.Background()
+
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	valid := types.ScoreTimeSeriesRequest{Name: "accuracy", BucketSize: time.Hour, FromTimestamp: from, ToTimestamp: from.Add(24 * time.Hour)}
+
+	_, err := client.GetTimeSeriesData(ctx, &valid)
+	assert.EqualError(t, err, "failed to get score time series: unexpected status 404")
+
+	invalid := []struct {
+		field  string
+		modify func(req *types.ScoreTimeSeriesRequest)
+	}{
+		{"name", func(req *types.ScoreTimeSeriesRequest) { req.Name = "" }},
+		{"dataType", func(req *types.ScoreTimeSeriesRequest) { req.DataType = "TEXT" }},
+		{"bucketSize", func(req *types.ScoreTimeSeriesRequest) { req.BucketSize = time.Millisecond }},
+		{"timestamps", func(req *types.ScoreTimeSeriesRequest) { req.FromTimestamp = time.Time{} }},
+		{"timestamps", func(req *types.ScoreTimeSeriesRequest) { req.ToTimestamp = req.FromTimestamp }},
+	}
+	for _, tc := range invalid {
+		req := valid
+		tc.modify(&req)
+		_, err := client.GetTimeSeriesData(ctx, &req)
+		var validationErr *types.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, tc.field, validationErr.Field)
+	}
+
+	_, err = client.GetTimeSeriesData(ctx, nil)
+	assert.Error(t, err)
+}
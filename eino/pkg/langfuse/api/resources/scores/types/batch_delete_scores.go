@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// BatchDeleteScoresRequest selects the scores to delete. ScoreIDs are deleted as given;
+// TraceID, Name and Before select further scores matching all of the filters that are
+// set. At least one score ID or filter is required.
+type BatchDeleteScoresRequest struct {
+	ScoreIDs []string   `json:"scoreIds,omitempty"`
+	TraceID  *string    `json:"traceId,omitempty"`
+	Name     *string    `json:"name,omitempty"`
+	Before   *time.Time `json:"before,omitempty"`
+}
+
+// BatchDeleteScoresResponse reports the outcome of a batch delete
+type BatchDeleteScoresResponse struct {
+	Deleted int      `json:"deleted"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+// HasFilters reports whether the request selects scores by filter in addition to ScoreIDs
+func (req *BatchDeleteScoresRequest) HasFilters() bool {
+	return req.TraceID != nil || req.Name != nil || req.Before != nil
+}
+
+// Validate validates the BatchDeleteScoresRequest
+func (req *BatchDeleteScoresRequest) Validate() error {
+	if len(req.ScoreIDs) == 0 && !req.HasFilters() {
+		return &ValidationError{Field: "scoreIds", Message: "at least one score ID or filter is required"}
+	}
+
+	for _, id := range req.ScoreIDs {
+		if id == "" {
+			return &ValidationError{Field: "scoreIds", Message: "score IDs cannot be empty"}
+		}
+	}
+
+	if req.TraceID != nil && *req.TraceID == "" {
+		return &ValidationError{Field: "traceId", Message: "traceId cannot be empty"}
+	}
+
+	if req.Name != nil && *req.Name == "" {
+		return &ValidationError{Field: "name", Message: "name cannot be empty"}
+	}
+
+	if req.Before != nil && req.Before.IsZero() {
+		return &ValidationError{Field: "before", Message: "before cannot be the zero time"}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"slices"
 	"time"
 
 	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
@@ -21,6 +22,18 @@ type GetScoresRequest struct {
 	ToTimestamp   *time.Time                 `json:"toTimestamp,omitempty"`
 	UserID        *string                    `json:"userId,omitempty"`
 	Source        *string                    `json:"source,omitempty"`
+
+	// SortBy orders the scores by timestamp, value or name, and SortOrder sets the
+	// direction; the API's default ordering applies when they are empty
+	SortBy    commonTypes.SortField `json:"sortBy,omitempty"`
+	SortOrder commonTypes.SortOrder `json:"order,omitempty"`
+}
+
+// ScoreSortFields are the fields GetScoresRequest.SortBy accepts
+var ScoreSortFields = []commonTypes.SortField{
+	commonTypes.SortFieldTimestamp,
+	commonTypes.SortFieldValue,
+	commonTypes.SortFieldName,
 }
 
 // GetScoresResponse represents the response from getting scores
@@ -50,6 +63,9 @@ type GetScoreAggregationRequest struct {
 	ToTimestamp   *time.Time `json:"toTimestamp,omitempty"`
 	UserID        *string    `json:"userId,omitempty"`
 	GroupBy       []string   `json:"groupBy,omitempty"`
+
+	// Interval splits the aggregation into time buckets of this size (optional)
+	Interval AggregationInterval `json:"interval,omitempty"`
 }
 
 // GetScoreAggregationResponse represents the response from getting score aggregations
@@ -134,6 +150,14 @@ func (req *GetScoresRequest) Validate() error {
 		return &ValidationError{Field: "timestamps", Message: "fromTimestamp cannot be after toTimestamp"}
 	}
 	
+	if req.SortBy != "" && !slices.Contains(ScoreSortFields, req.SortBy) {
+		return &ValidationError{Field: "sortBy", Message: "sortBy must be one of timestamp, value or name"}
+	}
+	
+	if req.SortOrder != "" && !req.SortOrder.IsValid() {
+		return &ValidationError{Field: "order", Message: "order must be asc or desc"}
+	}
+	
 	return nil
 }
 
@@ -159,6 +183,10 @@ func (req *GetScoreAggregationRequest) Validate() error {
 		}
 	}
 	
+	if req.Interval != "" && !req.Interval.IsValid() {
+		return &ValidationError{Field: "interval", Message: "interval must be one of hour, day or week"}
+	}
+	
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/utils/pagination/types"
 )
 
 // ScoreConfig represents a score configuration
@@ -39,6 +40,12 @@ type GetScoreConfigsRequest struct {
 	IsArchived *bool  `json:"isArchived,omitempty"`
 }
 
+// GetScoreConfigsResponse represents the response from getting score configurations
+type GetScoreConfigsResponse struct {
+	Data []ScoreConfig      `json:"data"`
+	Meta types.MetaResponse `json:"meta"`
+}
+
 // CreateScoreConfigRequest represents a request to create a score configuration
 type CreateScoreConfigRequest struct {
 	Name        string                    `json:"name"`
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"time"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// ScoreTimeSeriesRequest selects the scores of a time series and the size of its
+// buckets. Buckets start at FromTimestamp and the last one ends at or after ToTimestamp.
+type ScoreTimeSeriesRequest struct {
+	Name string
+
+	// DataType restricts the series to scores of this data type when set
+	DataType commonTypes.ScoreDataType
+
+	BucketSize    time.Duration
+	FromTimestamp time.Time
+	ToTimestamp   time.Time
+}
+
+// Validate validates the ScoreTimeSeriesRequest
+func (req *ScoreTimeSeriesRequest) Validate() error {
+	if req.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+
+	switch req.DataType {
+	case "", commonTypes.ScoreDataTypeNumeric, commonTypes.ScoreDataTypeBoolean, commonTypes.ScoreDataTypeCategorical:
+	default:
+		return &ValidationError{Field: "dataType", Message: "invalid data type: " + string(req.DataType)}
+	}
+
+	if req.BucketSize < time.Second {
+		return &ValidationError{Field: "bucketSize", Message: "bucketSize must be at least one second"}
+	}
+
+	if req.FromTimestamp.IsZero() || req.ToTimestamp.IsZero() {
+		return &ValidationError{Field: "timestamps", Message: "fromTimestamp and toTimestamp are required"}
+	}
+
+	if !req.FromTimestamp.Before(req.ToTimestamp) {
+		return &ValidationError{Field: "timestamps", Message: "fromTimestamp must be before toTimestamp"}
+	}
+
+	return nil
+}
+
+// ScoreTimeBucket summarizes the scores of one bucket of a time series. Average, Min
+// and Max are zero when Count is.
+type ScoreTimeBucket struct {
+	StartTime time.Time `json:"startTime"`
+	Count     int       `json:"count"`
+	Average   float64   `json:"average"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+}
+
+// ScoreTimeSeriesResponse is a score time series, oldest bucket first
+type ScoreTimeSeriesResponse struct {
+	Buckets []ScoreTimeBucket `json:"buckets"`
+}
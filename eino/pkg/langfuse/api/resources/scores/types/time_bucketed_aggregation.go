@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// AggregationInterval is the size of the time buckets of a score aggregation
+type AggregationInterval string
+
+const (
+	AggregationIntervalHour AggregationInterval = "hour"
+	AggregationIntervalDay  AggregationInterval = "day"
+	AggregationIntervalWeek AggregationInterval = "week"
+)
+
+// IsValid returns true if the interval is a supported bucket size
+func (i AggregationInterval) IsValid() bool {
+	switch i {
+	case AggregationIntervalHour, AggregationIntervalDay, AggregationIntervalWeek:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScoreBucket summarizes the scores of one time bucket. The statistics only cover
+// numeric values and are nil when the bucket has none.
+type ScoreBucket struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Count   int       `json:"count"`
+	Average *float64  `json:"average,omitempty"`
+	Min     *float64  `json:"min,omitempty"`
+	Max     *float64  `json:"max,omitempty"`
+	P95     *float64  `json:"p95,omitempty"`
+}
+
+// TimeBucketedAggregation is a score aggregation split into consecutive time buckets,
+// oldest first
+type TimeBucketedAggregation struct {
+	Interval AggregationInterval `json:"interval"`
+	Buckets  []ScoreBucket       `json:"buckets"`
+
+	// ComputedLocally is true when the API did not support bucketing and the buckets
+	// were computed by the client from the raw scores
+	ComputedLocally bool `json:"-"`
+}
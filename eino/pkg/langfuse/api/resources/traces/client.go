@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"eino/pkg/langfuse/api/resources/traces/types"
@@ -22,6 +23,10 @@ const (
 // Client handles trace-related API operations
 type Client struct {
 	client *resty.Client
+
+	// watchErrs receives poll errors from every Watch on this client
+	watchErrsOnce sync.Once
+	watchErrs     chan error
 }
 
 // NewClient creates a new traces client
@@ -33,12 +38,21 @@ func NewClient(client *resty.Client) *Client {
 
 // List retrieves a list of traces based on the provided filters
 func (c *Client) List(ctx context.Context, req *types.GetTracesRequest) (*types.GetTracesResponse, error) {
+	response := &types.GetTracesResponse{}
+	if err := c.list(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// list sends the list request of req and decodes the page into response
+func (c *Client) list(ctx context.Context, req *types.GetTracesRequest, response interface{}) error {
 	if req == nil {
 		req = &types.GetTracesRequest{}
 	}
 	
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("request validation failed: %w", err)
+		return fmt.Errorf("request validation failed: %w", err)
 	}
 	
 	// Build query parameters
@@ -48,7 +62,9 @@ func (c *Client) List(ctx context.Context, req *types.GetTracesRequest) (*types.
 		queryParams["projectId"] = req.ProjectID
 	}
 	
-	if req.Page != nil {
+	if req.Cursor != nil {
+		queryParams["cursor"] = *req.Cursor
+	} else if req.Page != nil {
 		queryParams["page"] = strconv.Itoa(*req.Page)
 	}
 	
@@ -84,7 +100,27 @@ func (c *Client) List(ctx context.Context, req *types.GetTracesRequest) (*types.
 		queryParams["tags"] = strings.Join(req.Tags, ",")
 	}
 	
-	response := &types.GetTracesResponse{}
+	if req.Bookmarked != nil {
+		queryParams["bookmarked"] = strconv.FormatBool(*req.Bookmarked)
+	}
+	
+	if req.Environment != nil && *req.Environment != "" {
+		queryParams["environment"] = *req.Environment
+	}
+	
+	if req.Release != nil && *req.Release != "" {
+		queryParams["release"] = *req.Release
+	}
+	
+	if len(req.Metadata) > 0 {
+		filter, err := commonTypes.EncodeMetadataFilter(req.Metadata)
+		if err != nil {
+			return err
+		}
+		queryParams[commonTypes.FilterQueryParam] = filter
+	}
+	
+	commonTypes.SetSortQueryParams(queryParams, req.SortBy, req.SortOrder)
 	
 	request := c.client.R().
 		SetContext(ctx).
@@ -98,10 +134,10 @@ func (c *Client) List(ctx context.Context, req *types.GetTracesRequest) (*types.
 	_, err := request.Get(tracesBasePath)
 	
 	if err != nil {
-		return nil, fmt.Errorf("failed to list traces: %w", err)
+		return fmt.Errorf("failed to list traces: %w", err)
 	}
 	
-	return response, nil
+	return nil
 }
 
 // Get retrieves a specific trace by ID
@@ -201,6 +237,18 @@ func (c *Client) Update(ctx context.Context, req *types.UpdateTraceRequest) (*co
 	return response, nil
 }
 
+// SetBookmarked bookmarks or un-bookmarks a trace, as analysts do in the UI
+func (c *Client) SetBookmarked(ctx context.Context, traceID string, bookmarked bool) (*commonTypes.Trace, error) {
+	if traceID == "" {
+		return nil, fmt.Errorf("trace ID cannot be empty")
+	}
+	
+	return c.Update(ctx, &types.UpdateTraceRequest{
+		TraceID:    traceID,
+		Bookmarked: &bookmarked,
+	})
+}
+
 // Delete deletes a trace by ID
 func (c *Client) Delete(ctx context.Context, traceID string) (*types.DeleteTraceResponse, error) {
 	if traceID == "" {
@@ -277,16 +325,21 @@ func (c *Client) GetStats(ctx context.Context, req *types.GetTraceStatsRequest)
 }
 
 // ListPaginated retrieves traces with enhanced pagination and filtering
-func (c *Client) ListPaginated(ctx context.Context, req *types.PaginatedTracesRequest) (*types.GetTracesResponse, error) {
+func (c *Client) ListPaginated(ctx context.Context, req *types.PaginatedTracesRequest) (*types.PaginatedTracesResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("paginated request cannot be nil")
 	}
 	
-	// Convert to standard GetTracesRequest
+	// Convert to standard GetTracesRequest; a zero page or limit is left to the server default
 	getReq := &types.GetTracesRequest{
 		ProjectID: req.ProjectID,
-		Page:      &req.Page,
-		Limit:     &req.Limit,
+		Cursor:    req.Cursor,
+	}
+	if req.Page != 0 && req.Cursor == nil {
+		getReq.Page = &req.Page
+	}
+	if req.Limit != 0 {
+		getReq.Limit = &req.Limit
 	}
 	
 	if req.SortOrder != "" {
@@ -312,7 +365,71 @@ func (c *Client) ListPaginated(ctx context.Context, req *types.PaginatedTracesRe
 		getReq.ToTimestamp = req.Filter.ToTimestamp
 	}
 	
-	return c.List(ctx, getReq)
+	response := &types.PaginatedTracesResponse{}
+	if err := c.list(ctx, getReq, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// pageCursorPrefix marks the cursors IterateAll makes up from page numbers, for servers
+// that do not return cursors
+const pageCursorPrefix = "page:"
+
+// IterateAll calls fn for every trace matching req, following NextCursor from page to
+// page, or the page numbers when the server does not return cursors. It stops at the
+// first error of fn, which it returns, or after the last page. req is not modified.
+func (c *Client) IterateAll(ctx context.Context, req *types.PaginatedTracesRequest, fn func(*commonTypes.Trace) error) error {
+	if req == nil {
+		return fmt.Errorf("paginated request cannot be nil")
+	}
+	if fn == nil {
+		return fmt.Errorf("iteration function cannot be nil")
+	}
+	
+	// Every page is requested from the cursor the paginator passes in. Servers that do
+	// not return cursors are paged by number, carried as a cursor with pageCursorPrefix.
+	paginator := commonTypes.NewCursorPaginator(func(cursor *string) ([]commonTypes.Trace, *string, error) {
+		pageReq := *req
+		if cursor != nil {
+			if page, ok := strings.CutPrefix(*cursor, pageCursorPrefix); ok {
+				pageReq.Page, _ = strconv.Atoi(page)
+			} else {
+				pageReq.Cursor = cursor
+			}
+		}
+		
+		response, err := c.ListPaginated(ctx, &pageReq)
+		if err != nil {
+			return nil, nil, err
+		}
+		
+		switch {
+		case response.NextCursor != nil && *response.NextCursor != "":
+			if pageReq.Cursor != nil && *response.NextCursor == *pageReq.Cursor {
+				return nil, nil, fmt.Errorf("server returned cursor %q again as the next cursor", *pageReq.Cursor)
+			}
+			return response.Data, response.NextCursor, nil
+		case pageReq.Cursor == nil && len(response.Data) > 0 && response.Meta.Page < response.Meta.TotalPages:
+			next := pageCursorPrefix + strconv.Itoa(response.Meta.Page+1)
+			return response.Data, &next, nil
+		default:
+			return response.Data, nil, nil
+		}
+	})
+	
+	for paginator.HasNextPage() {
+		traces, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range traces {
+			if err := fn(&traces[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // Exists checks if a trace exists
@@ -359,4 +476,57 @@ func (c *Client) ListByUser(ctx context.Context, userID string, limit int) (*typ
 	}
 	
 	return c.List(ctx, req)
-}
\ No newline at end of file
+}
+
+// ListByEnvironment retrieves traces recorded in a specific environment
+func (c *Client) ListByEnvironment(ctx context.Context, environment string, limit int) (*types.GetTracesResponse, error) {
+	if environment == "" {
+		return nil, fmt.Errorf("environment cannot be empty")
+	}
+	
+	req := &types.GetTracesRequest{
+		Environment: &environment,
+		Limit:       &limit,
+	}
+	
+	return c.List(ctx, req)
+}
+
+// ListByRelease retrieves traces recorded by a specific release
+func (c *Client) ListByRelease(ctx context.Context, release string, limit int) (*types.GetTracesResponse, error) {
+	if release == "" {
+		return nil, fmt.Errorf("release cannot be empty")
+	}
+	
+	req := &types.GetTracesRequest{
+		Release: &release,
+		Limit:   &limit,
+	}
+	
+	return c.List(ctx, req)
+}
+
+// ListByMetadata retrieves traces whose metadata has key set to value
+func (c *Client) ListByMetadata(ctx context.Context, key, value string, limit int) (*types.GetTracesResponse, error) {
+	if key == "" {
+		return nil, fmt.Errorf("metadata key cannot be empty")
+	}
+	
+	req := &types.GetTracesRequest{
+		Metadata: map[string]string{key: value},
+		Limit:    &limit,
+	}
+	
+	return c.List(ctx, req)
+}
+
+// ListBookmarked retrieves bookmarked traces
+func (c *Client) ListBookmarked(ctx context.Context, limit int) (*types.GetTracesResponse, error) {
+	bookmarked := true
+	req := &types.GetTracesRequest{
+		Bookmarked: &bookmarked,
+		Limit:      &limit,
+	}
+	
+	return c.List(ctx, req)
+}
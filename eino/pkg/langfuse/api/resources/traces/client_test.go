@@ -864,6 +864,156 @@ func TestClient_ListByUser(t *testing.T) {
 	}
 }
 
+func TestClient_ListBookmarked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/traces", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("bookmarked"))
+		assert.Equal(t, "25", r.URL.Query().Get("limit"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"id": "trace-1", "timestamp": "2024-01-15T12:00:00Z", "bookmarked": true}], "meta": {"page": 1, "limit": 25, "totalItems": 1, "totalPages": 1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	response, err := client.ListBookmarked(context.Background(), 25)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, true, *response.Data[0].Bookmarked)
+	}
+}
+
+func TestClient_List_BookmarkedFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		bookmarked *bool
+		expected   string
+	}{
+		{name: "bookmarked", bookmarked: boolPtr(true), expected: "true"},
+		{name: "not bookmarked", bookmarked: boolPtr(false), expected: "false"},
+		{name: "unset", bookmarked: nil, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, present := r.URL.Query()["bookmarked"]
+				assert.Equal(t, tt.bookmarked != nil, present)
+				assert.Equal(t, tt.expected, r.URL.Query().Get("bookmarked"))
+				w.Write([]byte(`{"data": [], "meta": {}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(resty.New().SetBaseURL(server.URL))
+			_, err := client.List(context.Background(), &types.GetTracesRequest{Bookmarked: tt.bookmarked})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_List_EnvironmentAndReleaseFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment *string
+		release     *string
+	}{
+		{name: "both set", environment: stringPtr("production"), release: stringPtr("v1.4.2")},
+		{name: "environment only", environment: stringPtr("staging")},
+		{name: "release only", release: stringPtr("2024.01.15+abc123")},
+		{name: "empty values omitted", environment: stringPtr(""), release: stringPtr("")},
+		{name: "unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				for param, value := range map[string]*string{"environment": tt.environment, "release": tt.release} {
+					_, present := query[param]
+					if value == nil || *value == "" {
+						assert.False(t, present, "%s should be omitted", param)
+					} else {
+						assert.Equal(t, *value, query.Get(param))
+					}
+				}
+				w.Write([]byte(`{"data": [], "meta": {}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(resty.New().SetBaseURL(server.URL))
+			_, err := client.List(context.Background(), &types.GetTracesRequest{Environment: tt.environment, Release: tt.release})
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("invalid environment", func(t *testing.T) {
+		client := NewClient(resty.New())
+		_, err := client.List(context.Background(), &types.GetTracesRequest{Environment: stringPtr("prod env")})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "environment")
+	})
+}
+
+func TestClient_ListByEnvironmentAndRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/traces", r.URL.Path)
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": [{"id": "trace-1", "timestamp": "2024-01-15T12:00:00Z", "environment": %q, "release": %q}], "meta": {}}`,
+			r.URL.Query().Get("environment"), r.URL.Query().Get("release"))
+	}))
+	defer server.Close()
+
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	response, err := client.ListByEnvironment(context.Background(), "production", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, "production", *response.Data[0].Environment)
+	}
+
+	response, err = client.ListByRelease(context.Background(), "v1.4.2", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, "v1.4.2", *response.Data[0].Release)
+	}
+
+	_, err = client.ListByEnvironment(context.Background(), "", 10)
+	assert.Error(t, err)
+	_, err = client.ListByRelease(context.Background(), "", 10)
+	assert.Error(t, err)
+}
+
+func TestClient_SetBookmarked(t *testing.T) {
+	for _, bookmarked := range []bool{true, false} {
+		t.Run(fmt.Sprintf("bookmarked=%t", bookmarked), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, "/api/public/traces/trace-123", r.URL.Path)
+
+				var body map[string]interface{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, map[string]interface{}{"traceId": "trace-123", "bookmarked": bookmarked}, body)
+
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"id": "trace-123", "timestamp": "2024-01-15T12:00:00Z", "bookmarked": %t}`, bookmarked)
+			}))
+			defer server.Close()
+
+			client := NewClient(resty.New().SetBaseURL(server.URL))
+
+			trace, err := client.SetBookmarked(context.Background(), "trace-123", bookmarked)
+			assert.NoError(t, err)
+			assert.Equal(t, bookmarked, *trace.Bookmarked)
+		})
+	}
+
+	_, err := NewClient(resty.New()).SetBookmarked(context.Background(), "", true)
+	assert.Error(t, err)
+}
+
 func TestClient_ContextPropagation(t *testing.T) {
 	// Create test server that verifies context
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -899,4 +1049,8 @@ func intPtr(i int) *int {
 
 func timePtr(t time.Time) *time.Time {
 	return &t
-}
\ No newline at end of file
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
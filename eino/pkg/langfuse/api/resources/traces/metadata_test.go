@@ -0,0 +1,46 @@
+package traces
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/traces/types"
+)
+
+func TestClient_ListByMetadata(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/traces", r.URL.Path)
+		rawQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": [{"id": "trace-1", "name": "agent-run", "timestamp": "2024-01-01T12:00:00Z", "metadata": {"tenant": "acme & co"}}],
+			"meta": {"page": 1, "limit": 1, "totalItems": 2, "totalPages": 2, "hasNextPage": true}
+		}`))
+	}))
+	defer server.Close()
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	resp, err := client.ListByMetadata(context.Background(), "tenant", "acme & co", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "filter=%5B%7B%22type%22%3A%22stringObject%22%2C%22column%22%3A%22metadata%22%2C"+
+		"%22key%22%3A%22tenant%22%2C%22operator%22%3A%22%3D%22%2C%22value%22%3A%22acme+%26+co%22%7D%5D&limit=1", rawQuery)
+
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "trace-1", resp.Data[0].ID)
+	assert.Equal(t, "acme & co", resp.Data[0].Metadata["tenant"])
+	assert.Equal(t, 2, resp.Meta.TotalItems)
+	assert.True(t, resp.Meta.HasNextPage)
+
+	_, err = client.ListByMetadata(context.Background(), "", "acme", 1)
+	assert.Error(t, err)
+	_, err = client.List(context.Background(), &types.GetTracesRequest{Metadata: map[string]string{"": "acme"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package traces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/traces/types"
+)
+
+// newCursorServer serves total traces two per page, chaining pages by cursor when
+// cursors is set and by page number otherwise, and records the query of every request
+func newCursorServer(t *testing.T, total int, cursors bool) (*Client, *[]map[string]string) {
+	var queries []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		queries = append(queries, query)
+
+		start := 0
+		if cursor := query["cursor"]; cursor != "" {
+			start, _ = strconv.Atoi(cursor)
+		} else if page, _ := strconv.Atoi(query["page"]); page > 1 {
+			start = (page - 1) * 2
+		}
+		end := min(start+2, total)
+
+		data := ""
+		for i := start; i < end; i++ {
+			if i > start {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"id":"trace-%d","timestamp":"2024-01-01T00:00:00Z"}`, i)
+		}
+		nextCursor := ""
+		if cursors && end < total {
+			nextCursor = fmt.Sprintf(`,"nextCursor":"%d"`, end)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"page":%d,"limit":2,"totalItems":%d,"totalPages":%d}%s}`,
+			data, start/2+1, total, (total+1)/2, nextCursor)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(resty.New().SetBaseURL(server.URL)), &queries
+}
+
+func collectIDs(client *Client, req *types.PaginatedTracesRequest) ([]string, error) {
+	var ids []string
+	err := client.IterateAll(context.Background(), req, func(trace *commonTypes.Trace) error {
+		ids = append(ids, trace.ID)
+		return nil
+	})
+	return ids, err
+}
+
+func TestClient_ListPaginated_Cursor(t *testing.T) {
+	client, queries := newCursorServer(t, 5, true)
+	cursor := "2"
+
+	response, err := client.ListPaginated(context.Background(), &types.PaginatedTracesRequest{Page: 4, Limit: 2, Cursor: &cursor})
+	require.NoError(t, err)
+	assert.Equal(t, "trace-2", response.Data[0].ID)
+	require.NotNil(t, response.NextCursor)
+	assert.Equal(t, "4", *response.NextCursor)
+	assert.Equal(t, map[string]string{"cursor": "2", "limit": "2"}, (*queries)[0], "the cursor replaces the page")
+}
+
+func TestClient_IterateAll(t *testing.T) {
+	client, queries := newCursorServer(t, 5, true)
+	req := &types.PaginatedTracesRequest{Limit: 2}
+
+	ids, err := collectIDs(client, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trace-0", "trace-1", "trace-2", "trace-3", "trace-4"}, ids)
+	assert.Equal(t, []map[string]string{
+		{"limit": "2"},
+		{"limit": "2", "cursor": "2"},
+		{"limit": "2", "cursor": "4"},
+	}, *queries)
+	assert.Nil(t, req.Cursor, "the request is not modified")
+}
+
+func TestClient_IterateAll_PageNumbers(t *testing.T) {
+	client, queries := newCursorServer(t, 5, false)
+
+	ids, err := collectIDs(client, &types.PaginatedTracesRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, ids, 5)
+	assert.Len(t, *queries, 3)
+	assert.Equal(t, "3", (*queries)[2]["page"])
+}
+
+func TestClient_IterateAll_StopsOnError(t *testing.T) {
+	client, queries := newCursorServer(t, 5, true)
+	errStop := errors.New("stop")
+
+	var seen int
+	err := client.IterateAll(context.Background(), &types.PaginatedTracesRequest{Limit: 2}, func(trace *commonTypes.Trace) error {
+		seen++
+		if trace.ID == "trace-2" {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 3, seen)
+	assert.Len(t, *queries, 2, "no page is fetched after the error")
+}
+
+func TestClient_IterateAll_RepeatedCursor(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"id":"trace-0","timestamp":"2024-01-01T00:00:00Z"}],"meta":{"page":1,"limit":1,"totalItems":2,"totalPages":2},"nextCursor":"stuck"}`)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+
+	ids, err := collectIDs(client, &types.PaginatedTracesRequest{Limit: 1})
+	assert.ErrorContains(t, err, `cursor "stuck"`)
+	assert.Equal(t, []string{"trace-0"}, ids)
+	assert.Equal(t, 2, requests, "iteration stops at the first repeated cursor")
+}
@@ -0,0 +1,150 @@
+package traces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// maxTagUpdateAttempts bounds how often AddTags and RemoveTags re-read a trace whose
+// tags changed between their read and their write
+const maxTagUpdateAttempts = 3
+
+// ErrTagsConflict is returned by AddTags and RemoveTags when the trace kept changing
+// concurrently and the tags could not be updated
+var ErrTagsConflict = errors.New("trace tags were modified concurrently")
+
+// errPreconditionFailed reports that the trace changed since its ETag was read
+var errPreconditionFailed = errors.New("precondition failed")
+
+// tagsPatch is the body of a tags-only update. Tags is never omitted, so an empty list
+// clears the trace's tags.
+type tagsPatch struct {
+	TraceID string   `json:"traceId"`
+	Tags    []string `json:"tags"`
+}
+
+// AddTags adds tags to a trace, keeping its existing tags and skipping duplicates.
+//
+// The trace is read and then patched. When the API returns an ETag the patch is
+// conditional on it and retried if the trace changed in between; otherwise a concurrent
+// tag update made between the two requests can be lost.
+func (c *Client) AddTags(ctx context.Context, traceID string, tags []string) error {
+	return c.updateTags(ctx, traceID, func(current []string) []string {
+		return uniqueTags(append(slices.Clone(current), tags...))
+	})
+}
+
+// RemoveTags removes tags from a trace, keeping the others. It reads and patches the
+// trace like AddTags.
+func (c *Client) RemoveTags(ctx context.Context, traceID string, tags []string) error {
+	return c.updateTags(ctx, traceID, func(current []string) []string {
+		return slices.DeleteFunc(slices.Clone(current), func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	})
+}
+
+// SetTags replaces all tags of a trace; an empty list removes them
+func (c *Client) SetTags(ctx context.Context, traceID string, tags []string) error {
+	if traceID == "" {
+		return fmt.Errorf("trace ID cannot be empty")
+	}
+
+	return c.patchTags(ctx, traceID, uniqueTags(tags), "")
+}
+
+// updateTags applies change to the trace's current tags and patches the result
+func (c *Client) updateTags(ctx context.Context, traceID string, change func(current []string) []string) error {
+	if traceID == "" {
+		return fmt.Errorf("trace ID cannot be empty")
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, etag, err := c.getTags(ctx, traceID)
+		if err != nil {
+			return err
+		}
+
+		updated := change(current)
+		if slices.Equal(updated, current) {
+			return nil
+		}
+
+		err = c.patchTags(ctx, traceID, updated, etag)
+		if !errors.Is(err, errPreconditionFailed) {
+			return err
+		}
+		if attempt == maxTagUpdateAttempts {
+			return fmt.Errorf("failed to update tags of trace %s: %w", traceID, ErrTagsConflict)
+		}
+	}
+}
+
+// getTags returns the trace's tags and its ETag, if the API sent one
+func (c *Client) getTags(ctx context.Context, traceID string) ([]string, string, error) {
+	var trace struct {
+		Tags []string `json:"tags"`
+	}
+
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&trace).
+		Get(fmt.Sprintf(traceByIDPath, url.PathEscape(traceID)))
+
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get trace %s: %w", traceID, err)
+	}
+
+	if resp.IsError() {
+		return nil, "", fmt.Errorf("failed to get trace %s: unexpected status %d", traceID, resp.StatusCode())
+	}
+
+	return trace.Tags, resp.Header().Get("ETag"), nil
+}
+
+// patchTags replaces the trace's tags, conditional on etag when it is not empty
+func (c *Client) patchTags(ctx context.Context, traceID string, tags []string, etag string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	request := c.client.R().
+		SetContext(ctx).
+		SetBody(&tagsPatch{TraceID: traceID, Tags: tags})
+	if etag != "" {
+		request.SetHeader("If-Match", etag)
+	}
+
+	resp, err := request.Patch(fmt.Sprintf(traceByIDPath, url.PathEscape(traceID)))
+
+	if err != nil {
+		return fmt.Errorf("failed to update tags of trace %s: %w", traceID, err)
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusPreconditionFailed:
+		return errPreconditionFailed
+	case resp.IsError():
+		return fmt.Errorf("failed to update tags of trace %s: unexpected status %d", traceID, resp.StatusCode())
+	}
+
+	return nil
+}
+
+// uniqueTags returns tags without duplicates or empty tags, in first-seen order
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		unique = append(unique, tag)
+	}
+	return unique
+}
@@ -0,0 +1,123 @@
+package traces
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagServer stores the tags of trace-1 and versions them with an ETag
+type tagServer struct {
+	mu      sync.Mutex
+	tags    []string
+	version int
+	patches []map[string]interface{}
+
+	// etags controls whether ETags are sent and If-Match is honoured
+	etags bool
+	// interfere is called after each read, e.g. to change the trace concurrently
+	interfere func(s *tagServer)
+}
+
+func (s *tagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != "/api/public/traces/trace-1" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	etag := strconv.Quote(strconv.Itoa(s.version))
+
+	switch r.Method {
+	case http.MethodGet:
+		if s.etags {
+			w.Header().Set("ETag", etag)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "trace-1", "tags": s.tags})
+		if s.interfere != nil {
+			s.interfere(s)
+		}
+	case http.MethodPatch:
+		if s.etags && r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.patches = append(s.patches, body)
+		s.tags = nil
+		for _, tag := range body["tags"].([]interface{}) {
+			s.tags = append(s.tags, tag.(string))
+		}
+		s.version++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "trace-1"}`))
+	}
+}
+
+func newTagTestClient(t *testing.T, server *tagServer) *Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(resty.New().SetBaseURL(httpServer.URL))
+}
+
+func TestClient_Tags(t *testing.T) {
+	ctx := context.Background()
+	server := &tagServer{tags: []string{"prod", "checkout"}}
+	client := newTagTestClient(t, server)
+
+	require.NoError(t, client.AddTags(ctx, "trace-1", []string{"checkout", "slow", "slow"}))
+	assert.Equal(t, []string{"prod", "checkout", "slow"}, server.tags)
+
+	require.NoError(t, client.RemoveTags(ctx, "trace-1", []string{"prod", "missing"}))
+	assert.Equal(t, []string{"checkout", "slow"}, server.tags)
+
+	// Nothing changes, so nothing is patched
+	require.NoError(t, client.AddTags(ctx, "trace-1", []string{"slow"}))
+	require.NoError(t, client.RemoveTags(ctx, "trace-1", []string{"missing"}))
+	assert.Len(t, server.patches, 2)
+
+	require.NoError(t, client.SetTags(ctx, "trace-1", nil))
+	assert.Equal(t, map[string]interface{}{"traceId": "trace-1", "tags": []interface{}{}}, server.patches[2], "an empty list clears the tags")
+
+	assert.Error(t, client.AddTags(ctx, "", []string{"x"}))
+	assert.Error(t, client.AddTags(ctx, "unknown", []string{"x"}))
+}
+
+func TestClient_Tags_OptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries after a concurrent change", func(t *testing.T) {
+		server := &tagServer{tags: []string{"prod"}, etags: true}
+		server.interfere = func(s *tagServer) {
+			// Another writer adds a tag right after the first read
+			s.interfere = nil
+			s.tags = append(s.tags, "reviewed")
+			s.version++
+		}
+		client := newTagTestClient(t, server)
+
+		require.NoError(t, client.AddTags(ctx, "trace-1", []string{"slow"}))
+		assert.Equal(t, []string{"prod", "reviewed", "slow"}, server.tags, "the concurrent change is kept")
+	})
+
+	t.Run("gives up when the trace keeps changing", func(t *testing.T) {
+		server := &tagServer{tags: []string{"prod"}, etags: true}
+		server.interfere = func(s *tagServer) { s.version++ }
+		client := newTagTestClient(t, server)
+
+		err := client.RemoveTags(ctx, "trace-1", []string{"prod"})
+		assert.ErrorIs(t, err, ErrTagsConflict)
+		assert.Equal(t, []string{"prod"}, server.tags)
+	})
+}
@@ -2,10 +2,12 @@ package types
 
 import (
 	"encoding/json"
+	"slices"
 	"time"
 
 	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
 	"eino/pkg/langfuse/api/resources/utils/pagination/types"
+	"eino/pkg/langfuse/internal/utils"
 )
 
 // GetTracesRequest represents a request to get traces
@@ -20,8 +22,24 @@ type GetTracesRequest struct {
 	ToTimestamp   *time.Time `json:"toTimestamp,omitempty"`
 	OrderBy       *string    `json:"orderBy,omitempty"`
 	Tags          []string   `json:"tags,omitempty"`
+	Bookmarked    *bool      `json:"bookmarked,omitempty"`
+	Environment   *string    `json:"environment,omitempty"`
+	Release       *string    `json:"release,omitempty"`
+
+	// Metadata matches traces whose metadata has all of these key/value pairs
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// SortBy orders the traces by timestamp or name, and SortOrder sets the direction
+	SortBy    commonTypes.SortField `json:"sortBy,omitempty"`
+	SortOrder commonTypes.SortOrder `json:"order,omitempty"`
+
+	// Cursor continues a listing after the page that returned it, and is sent instead of Page
+	Cursor *string `json:"cursor,omitempty"`
 }
 
+// TraceSortFields are the fields GetTracesRequest.SortBy accepts
+var TraceSortFields = []commonTypes.SortField{commonTypes.SortFieldTimestamp, commonTypes.SortFieldName}
+
 // GetTracesResponse represents the response from getting traces
 type GetTracesResponse struct {
 	Data []commonTypes.Trace `json:"data"`
@@ -69,6 +87,7 @@ type UpdateTraceRequest struct {
 	Release     *string                `json:"release,omitempty"`
 	Version     *string                `json:"version,omitempty"`
 	Public      *bool                  `json:"public,omitempty"`
+	Bookmarked  *bool                  `json:"bookmarked,omitempty"`
 }
 
 // TraceWithObservations represents a trace with its observations
@@ -144,6 +163,20 @@ type PaginatedTracesRequest struct {
 	SortOrder TraceSortOrder `json:"sortOrder,omitempty"`
 	Page      int            `json:"page,omitempty"`
 	Limit     int            `json:"limit,omitempty"`
+
+	// Cursor is the NextCursor of the previous page. Listing by cursor is faster than by
+	// page on large projects; Page is ignored when it is set.
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+// PaginatedTracesResponse is a page of traces listed with PaginatedTracesRequest
+type PaginatedTracesResponse struct {
+	Data []commonTypes.Trace `json:"data"`
+	Meta types.MetaResponse  `json:"meta"`
+
+	// NextCursor lists the page after this one; nil on the last page or when the server
+	// only pages by number
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
 // Validate validates the GetTracesRequest
@@ -160,6 +193,24 @@ func (req *GetTracesRequest) Validate() error {
 		return &ValidationError{Field: "timestamps", Message: "fromTimestamp cannot be after toTimestamp"}
 	}
 
+	if req.Environment != nil {
+		if err := utils.ValidateEnvironment(*req.Environment, "environment"); err != nil {
+			return &ValidationError{Field: err.Field, Message: err.Message}
+		}
+	}
+
+	if _, ok := req.Metadata[""]; ok {
+		return &ValidationError{Field: "metadata", Message: "metadata filter key cannot be empty"}
+	}
+
+	if req.SortBy != "" && !slices.Contains(TraceSortFields, req.SortBy) {
+		return &ValidationError{Field: "sortBy", Message: "sortBy must be timestamp or name"}
+	}
+
+	if req.SortOrder != "" && !req.SortOrder.IsValid() {
+		return &ValidationError{Field: "order", Message: "order must be asc or desc"}
+	}
+
 	return nil
 }
 
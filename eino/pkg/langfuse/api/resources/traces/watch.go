@@ -0,0 +1,136 @@
+package traces
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/traces/types"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+const (
+	// watchBufferSize is the capacity of the trace and error channels used by Watch
+	watchBufferSize = 100
+
+	// watchSeenCapacity bounds the number of trace IDs remembered by a single Watch
+	watchSeenCapacity = 10000
+
+	// defaultWatchPollInterval is used when Watch is called with a non-positive interval
+	defaultWatchPollInterval = 5 * time.Second
+)
+
+// Watch polls List with the given filter every pollInterval and emits each trace the
+// first time it is seen. The returned channel is closed once ctx is done.
+//
+// The channel is buffered; if the consumer falls behind, the oldest undelivered traces
+// are dropped so polling never blocks. Poll errors are reported on WatchErrors and do
+// not stop the watch. Seen trace IDs are kept in a bounded LRU, so a trace that falls
+// out of it and is listed again will be emitted again.
+func (c *Client) Watch(ctx context.Context, filter *types.GetTracesRequest, pollInterval time.Duration) <-chan *commonTypes.Trace {
+	if pollInterval <= 0 {
+		pollInterval = defaultWatchPollInterval
+	}
+
+	out := make(chan *commonTypes.Trace, watchBufferSize)
+	errs := c.watchErrors()
+
+	go func() {
+		defer close(out)
+
+		seen := newSeenSet(watchSeenCapacity)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			c.pollTraces(ctx, filter, seen, out, errs)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return out
+}
+
+// WatchErrors returns the channel receiving poll errors from every Watch on this client.
+// Like the trace channel it is buffered and drops the oldest errors when full.
+func (c *Client) WatchErrors() <-chan error {
+	return c.watchErrors()
+}
+
+func (c *Client) watchErrors() chan error {
+	c.watchErrsOnce.Do(func() {
+		c.watchErrs = make(chan error, watchBufferSize)
+	})
+	return c.watchErrs
+}
+
+// pollTraces lists traces once and emits the ones not seen before
+func (c *Client) pollTraces(ctx context.Context, filter *types.GetTracesRequest, seen *seenSet, out chan *commonTypes.Trace, errs chan error) {
+	response, err := c.List(ctx, filter)
+	if err != nil {
+		if ctx.Err() == nil {
+			sendDropOldest(errs, err)
+		}
+		return
+	}
+
+	for i := range response.Data {
+		trace := &response.Data[i]
+		if seen.add(trace.ID) {
+			sendDropOldest(out, trace)
+		}
+	}
+}
+
+// sendDropOldest sends v without blocking, discarding the oldest buffered value if the
+// channel is full
+func sendDropOldest[T any](ch chan T, v T) {
+	for {
+		select {
+		case ch <- v:
+			return
+		default:
+		}
+
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// seenSet is a fixed-capacity set of IDs that evicts the least recently seen ID
+type seenSet struct {
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+func newSeenSet(capacity int) *seenSet {
+	return &seenSet{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// add marks id as seen and reports whether it was new
+func (s *seenSet) add(id string) bool {
+	if elem, ok := s.items[id]; ok {
+		s.order.MoveToFront(elem)
+		return false
+	}
+
+	s.items[id] = s.order.PushFront(id)
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(string))
+	}
+	return true
+}
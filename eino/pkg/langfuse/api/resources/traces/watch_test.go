@@ -0,0 +1,102 @@
+package traces
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/traces/types"
+)
+
+func TestClient_Watch(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user-1", r.URL.Query().Get("userId"))
+
+		// Each poll returns the previous traces plus one new trace
+		n := int(atomic.AddInt32(&polls, 1))
+		data := ""
+		for i := 1; i <= n; i++ {
+			if i > 1 {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"id":"trace-%d","timestamp":"2024-01-01T00:00:00Z"}`, i)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":[%s]}`, data)
+	}))
+	defer server.Close()
+
+	client := NewClient(resty.New().SetBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userID := "user-1"
+	traces := client.Watch(ctx, &types.GetTracesRequest{UserID: &userID}, 5*time.Millisecond)
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case trace := <-traces:
+			assert.Equal(t, "trace-"+strconv.Itoa(i), trace.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for trace")
+		}
+	}
+
+	cancel()
+	for range traces {
+		// Drain until the watch closes the channel
+	}
+}
+
+func TestClient_Watch_Errors(t *testing.T) {
+	client := NewClient(resty.New().SetBaseURL("http://127.0.0.1:0"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	invalidLimit := 0
+	traces := client.Watch(ctx, &types.GetTracesRequest{Limit: &invalidLimit}, 5*time.Millisecond)
+
+	select {
+	case err := <-client.WatchErrors():
+		assert.Contains(t, err.Error(), "limit")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for poll error")
+	}
+
+	cancel()
+	_, open := <-traces
+	assert.False(t, open)
+}
+
+func TestSendDropOldest(t *testing.T) {
+	ch := make(chan int, 3)
+	for i := 1; i <= 5; i++ {
+		sendDropOldest(ch, i)
+	}
+
+	require.Len(t, ch, 3)
+	assert.Equal(t, []int{3, 4, 5}, []int{<-ch, <-ch, <-ch})
+}
+
+func TestSeenSet(t *testing.T) {
+	seen := newSeenSet(2)
+
+	assert.True(t, seen.add("a"))
+	assert.True(t, seen.add("b"))
+	assert.False(t, seen.add("a"))
+
+	// "b" is now the least recently seen and is evicted
+	assert.True(t, seen.add("c"))
+	assert.True(t, seen.add("b"))
+	assert.False(t, seen.add("c"))
+}
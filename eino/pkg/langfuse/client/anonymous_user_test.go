@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+func TestTraceBuilder_WithAnonymousUser(t *testing.T) {
+	ctx := context.Background()
+	withSalt := func(cfg *config.Config) { cfg.UserIDHashSalt = "deployment-salt" }
+
+	lf, recorder := newPayloadTestLangfuse(t, withSalt)
+	trace := lf.Trace("chat").UserID("alice@example.com").WithAnonymousUser(true)
+	require.NoError(t, trace.Begin(ctx))
+	require.NoError(t, trace.Output("hi").End(ctx))
+
+	bodies := flushedBodies(t, lf, recorder)
+	hashed := utils.HashUserID("alice@example.com", "deployment-salt")
+	assert.Equal(t, hashed, bodies["trace-create"]["userId"])
+	assert.Equal(t, hashed, bodies["trace-update"]["userId"])
+	assert.Equal(t, "alice@example.com", trace.GetUserID(), "the builder keeps the original ID")
+
+	t.Run("stable across traces", func(t *testing.T) {
+		other := lf.Trace("chat").UserID("alice@example.com").WithAnonymousUser(true)
+		assert.Equal(t, hashed, *other.toTraceEvent().UserID)
+	})
+
+	t.Run("not anonymized by default", func(t *testing.T) {
+		trace := lf.Trace("chat").UserID("bob")
+		assert.Equal(t, "bob", *trace.toTraceEvent().UserID)
+
+		trace.WithAnonymousUser(false)
+		assert.Equal(t, "bob", *trace.toTraceEvent().UserID)
+	})
+
+	t.Run("requires a salt", func(t *testing.T) {
+		lf, _ := newPayloadTestLangfuse(t)
+		trace := lf.Trace("chat").UserID("alice@example.com").WithAnonymousUser(true)
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, trace.Submit(ctx), &validationErr)
+		assert.Equal(t, "userId", validationErr.Field)
+		assert.Nil(t, trace.toTraceEvent().UserID, "the original ID is never sent")
+
+		require.NoError(t, lf.Trace("no-user").WithAnonymousUser(true).Submit(ctx))
+	})
+}
+
+func TestWithUserIDHashSalt(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, WithUserIDHashSalt("salt")(cfg))
+	assert.Equal(t, "salt", cfg.UserIDHashSalt)
+
+	err := WithUserIDHashSalt("")(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "userIdHashSalt")
+}
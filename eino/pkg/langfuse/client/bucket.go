@@ -0,0 +1,65 @@
+package client
+
+import "hash/fnv"
+
+const (
+	// BucketMetadataKey holds the experiment bucket set with WithBucket
+	BucketMetadataKey = "experimentBucket"
+
+	// BucketTagPrefix prefixes the bucket in the tag set with WithBucket, e.g.
+	// "bucket:prompt-v2"
+	BucketTagPrefix = "bucket:"
+)
+
+// Bucket assigns userID to one of buckets, such as the arms of a prompt experiment. The
+// same user always lands in the same bucket, on any host, and users spread evenly over
+// the buckets. Assignment uses rendezvous hashing, so adding a bucket only moves the
+// users that the new bucket takes, and removing one only moves the users it had.
+// Returns "" when buckets is empty.
+//
+// Example:
+//
+//	arm := lf.Bucket(userID, []string{"control", "prompt-v2"})
+//	trace := lf.Trace("chat").WithUserID(userID).WithBucket(arm)
+func (lf *Langfuse) Bucket(userID string, buckets []string) string {
+	var chosen string
+	var best uint64
+	for i, bucket := range buckets {
+		// The bucket with the highest weight for the user wins
+		if weight := stableHash(bucket, userID); i == 0 || weight > best {
+			chosen, best = bucket, weight
+		}
+	}
+	return chosen
+}
+
+// stableHash hashes parts, separated by zero bytes, to a 64-bit value that is the same
+// in every process. FNV alone mixes the last bytes poorly, which skews IDs that differ
+// only at the end, so the splitmix64 finalizer spreads the result over the whole range.
+func stableHash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for i, part := range parts {
+		if i > 0 {
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(part))
+	}
+
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// WithBucket records the experiment bucket of the trace, as returned by Bucket, under
+// metadata["experimentBucket"] and as a "bucket:<bucket>" tag, so evaluation scores can
+// be sliced by experiment arm. An empty bucket is ignored.
+func (tb *TraceBuilder) WithBucket(bucket string) *TraceBuilder {
+	if bucket == "" {
+		return tb
+	}
+	return tb.AddMetadata(BucketMetadataKey, bucket).AddTag(BucketTagPrefix + bucket)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket_Deterministic(t *testing.T) {
+	lf := &Langfuse{}
+	buckets := []string{"control", "prompt-v2", "prompt-v3"}
+
+	for i := 0; i < 100; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		bucket := lf.Bucket(userID, buckets)
+		assert.Contains(t, buckets, bucket)
+		assert.Equal(t, bucket, lf.Bucket(userID, buckets))
+		assert.Equal(t, bucket, lf.Bucket(userID, []string{"prompt-v3", "control", "prompt-v2"}),
+			"the order of the buckets does not matter")
+	}
+
+	assert.Empty(t, lf.Bucket("user-1", nil))
+	assert.Equal(t, "only", lf.Bucket("user-1", []string{"only"}))
+}
+
+func TestBucket_Distribution(t *testing.T) {
+	lf := &Langfuse{}
+	buckets := []string{"a", "b", "c", "d"}
+	const users = 20000
+
+	counts := make(map[string]int)
+	for i := 0; i < users; i++ {
+		counts[lf.Bucket(fmt.Sprintf("user-%d", i), buckets)]++
+	}
+
+	expected := users / len(buckets)
+	for _, bucket := range buckets {
+		assert.InDelta(t, expected, counts[bucket], float64(expected)*0.05, "bucket %s", bucket)
+	}
+}
+
+func TestBucket_AddingBucketMovesFewUsers(t *testing.T) {
+	lf := &Langfuse{}
+	before := []string{"a", "b", "c"}
+	after := append(before, "d")
+	const users = 10000
+
+	moved := 0
+	for i := 0; i < users; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		if was, is := lf.Bucket(userID, before), lf.Bucket(userID, after); was != is {
+			assert.Equal(t, "d", is, "users only move to the new bucket")
+			moved++
+		}
+	}
+	assert.InDelta(t, users/4, moved, users*0.02)
+}
+
+func TestTraceBuilder_WithBucket(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	require.NoError(t, lf.Trace("chat").WithBucket("prompt-v2").WithBucket("").Submit(ctx))
+
+	body := flushedBodies(t, lf, recorder)["trace-create"]
+	require.NotNil(t, body)
+	assert.Equal(t, "prompt-v2", body["metadata"].(map[string]interface{})[BucketMetadataKey])
+	assert.Equal(t, []interface{}{"bucket:prompt-v2"}, body["tags"])
+}
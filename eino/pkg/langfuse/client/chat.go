@@ -0,0 +1,174 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Chat content block types
+const (
+	ChatContentText       = "text"
+	ChatContentToolUse    = "tool_use"
+	ChatContentToolResult = "tool_result"
+)
+
+// ChatMessage is one message of a chat input, rendered by Langfuse as a conversation
+type ChatMessage struct {
+	Role    string             `json:"role"`
+	Content []ChatContentBlock `json:"content"`
+}
+
+// ChatContentBlock is a structured part of a chat message. Text blocks use Text,
+// tool_use blocks ID, Name and Input, and tool_result blocks ToolUseID, Content and
+// IsError. Blocks of other types are kept in their original JSON form in Raw.
+type ChatContentBlock struct {
+	Type      string             `json:"type"`
+	Text      string             `json:"text,omitempty"`
+	ID        string             `json:"id,omitempty"`
+	Name      string             `json:"name,omitempty"`
+	Input     interface{}        `json:"input,omitempty"`
+	ToolUseID string             `json:"tool_use_id,omitempty"`
+	Content   []ChatContentBlock `json:"content,omitempty"`
+	IsError   bool               `json:"is_error,omitempty"`
+	Raw       json.RawMessage    `json:"-"`
+}
+
+// MarshalJSON encodes the block, or its original JSON for blocks kept in Raw
+func (b ChatContentBlock) MarshalJSON() ([]byte, error) {
+	if b.Raw != nil {
+		return b.Raw, nil
+	}
+	type plain ChatContentBlock
+	return json.Marshal(plain(b))
+}
+
+// FromAnthropicMessages converts Anthropic message params into chat messages, keeping
+// each message's role and content blocks instead of flattening them to a string
+func FromAnthropicMessages(msgs []anthropic.MessageParam) []ChatMessage {
+	messages := make([]ChatMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		blocks := make([]ChatContentBlock, 0, len(msg.Content))
+		for _, content := range msg.Content {
+			blocks = append(blocks, fromAnthropicBlock(content))
+		}
+		messages = append(messages, ChatMessage{Role: string(msg.Role), Content: blocks})
+	}
+	return messages
+}
+
+// fromAnthropicBlock converts a content block of an Anthropic message
+func fromAnthropicBlock(content anthropic.ContentBlockParamUnion) ChatContentBlock {
+	switch {
+	case content.OfText != nil:
+		return ChatContentBlock{Type: ChatContentText, Text: content.OfText.Text}
+	case content.OfToolUse != nil:
+		return ChatContentBlock{
+			Type:  ChatContentToolUse,
+			ID:    content.OfToolUse.ID,
+			Name:  content.OfToolUse.Name,
+			Input: content.OfToolUse.Input,
+		}
+	case content.OfToolResult != nil:
+		result := content.OfToolResult
+		block := ChatContentBlock{
+			Type:      ChatContentToolResult,
+			ToolUseID: result.ToolUseID,
+			IsError:   result.IsError.Value,
+		}
+		for _, part := range result.Content {
+			if part.OfText != nil {
+				block.Content = append(block.Content, ChatContentBlock{Type: ChatContentText, Text: part.OfText.Text})
+			} else {
+				block.Content = append(block.Content, rawBlock(part))
+			}
+		}
+		return block
+	default:
+		return rawBlock(content)
+	}
+}
+
+// rawBlock keeps a block the chat schema has no fields for as its Anthropic JSON
+func rawBlock(content interface{}) ChatContentBlock {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return ChatContentBlock{Type: "unknown"}
+	}
+	var typed struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(data, &typed)
+	return ChatContentBlock{Type: typed.Type, Raw: data}
+}
+
+// WithChatInput sets the input to a chat conversation, preserving each message's role
+// and content blocks
+func (gb *GenerationBuilder) WithChatInput(messages []ChatMessage) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithChatInput")
+		return gb
+	}
+	gb.input = append([]ChatMessage(nil), messages...)
+	return gb
+}
+
+// FunctionCallOutputType is the output type of generations whose response is a function
+// or tool call rather than text. It is recorded under the "outputType" metadata key.
+const FunctionCallOutputType = "function_call"
+
+// ToolCall is a single tool invocation requested by a model
+type ToolCall struct {
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// functionCallOutput is the recorded output of one function or tool call
+type functionCallOutput struct {
+	Type      string                 `json:"type"`
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// WithFunctionCallOutput sets the output to a single function call made by the model
+// and marks the generation's output type as a function call
+func (gb *GenerationBuilder) WithFunctionCallOutput(name string, args map[string]interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithFunctionCallOutput")
+		return gb
+	}
+	gb.output = functionCallOutput{Type: FunctionCallOutputType, Name: name, Arguments: args}
+	gb.setOutputType(FunctionCallOutputType)
+	return gb
+}
+
+// WithToolCallOutput sets the output to the tool calls of a model response that
+// invokes several tools at once, in the order the model made them
+func (gb *GenerationBuilder) WithToolCallOutput(calls []ToolCall) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithToolCallOutput")
+		return gb
+	}
+	output := make([]functionCallOutput, len(calls))
+	for i, call := range calls {
+		output[i] = functionCallOutput{Type: FunctionCallOutputType, ID: call.ID, Name: call.Name, Arguments: call.Arguments}
+	}
+	gb.output = output
+	gb.setOutputType(FunctionCallOutputType)
+	return gb
+}
+
+func (gb *GenerationBuilder) setOutputType(outputType string) {
+	if gb.metadata == nil {
+		gb.metadata = make(map[string]interface{})
+	}
+	gb.metadata["outputType"] = outputType
+}
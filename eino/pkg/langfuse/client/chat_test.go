@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromAnthropicMessages(t *testing.T) {
+	msgs := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Where is order #1001?")),
+		anthropic.NewAssistantMessage(
+			anthropic.NewTextBlock("Let me look that up."),
+			anthropic.NewToolUseBlock("toolu_01", map[string]interface{}{"order": "1001"}, "track_order"),
+		),
+		anthropic.NewUserMessage(
+			anthropic.NewToolResultBlock("toolu_01", "in transit", false),
+			anthropic.NewImageBlockBase64("image/png", "iVBORw0KGgo="),
+		),
+	}
+
+	messages := FromAnthropicMessages(msgs)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "assistant", messages[1].Role)
+	assert.Equal(t, ChatContentToolUse, messages[1].Content[1].Type)
+	assert.Equal(t, "image", messages[2].Content[1].Type)
+
+	lf, recorder := newPayloadTestLangfuse(t)
+	generation := NewGenerationBuilder(lf, "trace-1").Name("support-agent").WithChatInput(messages)
+	require.NoError(t, generation.Submit(context.Background()))
+
+	body := flushedBodies(t, lf, recorder)["generation-create"]
+	input, err := json.Marshal(body["input"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"role": "user", "content": [{"type": "text", "text": "Where is order #1001?"}]},
+		{"role": "assistant", "content": [
+			{"type": "text", "text": "Let me look that up."},
+			{"type": "tool_use", "id": "toolu_01", "name": "track_order", "input": {"order": "1001"}}
+		]},
+		{"role": "user", "content": [
+			{"type": "tool_result", "tool_use_id": "toolu_01", "content": [{"type": "text", "text": "in transit"}]},
+			{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgo="}}
+		]}
+	]`, string(input))
+}
+
+func TestGenerationBuilder_WithChatInput(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	messages := []ChatMessage{{Role: "user", Content: []ChatContentBlock{{Type: ChatContentText, Text: "hi"}}}}
+
+	generation := NewGenerationBuilder(lf, "trace-1").WithChatInput(messages)
+	messages[0].Role = "assistant"
+	assert.Equal(t, "user", generation.input.([]ChatMessage)[0].Role, "the messages slice is copied")
+
+	t.Run("tool error", func(t *testing.T) {
+		block := FromAnthropicMessages([]anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewToolResultBlock("toolu_02", "timeout", true)),
+		})[0].Content[0]
+
+		data, err := json.Marshal(block)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "tool_result", "tool_use_id": "toolu_02", "is_error": true, "content": [{"type": "text", "text": "timeout"}]}`, string(data))
+	})
+}
+
+func TestGenerationBuilder_WithFunctionCallOutput(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	single := NewGenerationBuilder(lf, "trace-1").Name("single").
+		WithFunctionCallOutput("track_order", map[string]interface{}{"order": "1001"})
+	require.NoError(t, single.Submit(context.Background()))
+
+	body := flushedBodies(t, lf, recorder)["generation-create"]
+	output, err := json.Marshal(body["output"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "function_call", "name": "track_order", "arguments": {"order": "1001"}}`, string(output))
+	assert.Equal(t, FunctionCallOutputType, body["metadata"].(map[string]interface{})["outputType"])
+
+	t.Run("multiple tool calls", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		multi := NewGenerationBuilder(lf, "trace-1").Name("multi").WithToolCallOutput([]ToolCall{
+			{ID: "toolu_01", Name: "track_order", Arguments: map[string]interface{}{"order": "1001"}},
+			{ID: "toolu_02", Name: "get_weather", Arguments: map[string]interface{}{"city": "Paris"}},
+		})
+		require.NoError(t, multi.Submit(context.Background()))
+
+		body := flushedBodies(t, lf, recorder)["generation-create"]
+		output, err := json.Marshal(body["output"])
+		require.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"type": "function_call", "id": "toolu_01", "name": "track_order", "arguments": {"order": "1001"}},
+			{"type": "function_call", "id": "toolu_02", "name": "get_weather", "arguments": {"city": "Paris"}}
+		]`, string(output))
+		assert.Equal(t, FunctionCallOutputType, body["metadata"].(map[string]interface{})["outputType"])
+	})
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hammer runs mutate repeatedly from several goroutines, each of which then calls end,
+// and asserts that exactly one end call succeeds. Run with -race.
+func hammer(t *testing.T, mutate func(goroutine, i int), end func() error) {
+	t.Helper()
+	const goroutines, iterations = 8, 50
+
+	var wg sync.WaitGroup
+	errs := make([]error, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				mutate(g, i)
+			}
+			errs[g] = end()
+		}(g)
+	}
+	wg.Wait()
+
+	ended := 0
+	for _, err := range errs {
+		if err == nil {
+			ended++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrAlreadyEnded)
+	}
+	assert.Equal(t, 1, ended, "End succeeds exactly once")
+}
+
+func TestBuilders_ConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	lf := newStrictTestLangfuse(t, true)
+
+	t.Run("trace", func(t *testing.T) {
+		trace := lf.Trace("trace")
+		hammer(t, func(g, i int) {
+			key := fmt.Sprintf("goroutine-%d", g)
+			trace.AddMetadata(key, i).WithOutput(i).AddTag(key).Version(key)
+			_ = trace.GetID()
+		}, func() error { return trace.End(ctx) })
+	})
+
+	t.Run("span", func(t *testing.T) {
+		span := lf.Trace("trace").Span("span")
+		hammer(t, func(g, i int) {
+			span.AddMetadata(fmt.Sprintf("goroutine-%d", g), i).WithKV("attempt", i).Output(i).Warning()
+			_ = span.GetName()
+		}, func() error { return span.End(ctx) })
+	})
+
+	t.Run("generation", func(t *testing.T) {
+		generation := lf.Trace("trace").Generation("generation")
+		hammer(t, func(g, i int) {
+			generation.Output(fmt.Sprintf("chunk %d", i)).AddMetadata(fmt.Sprintf("goroutine-%d", g), i).
+				Temperature(0.5).UsageTokens(i, i)
+			_ = generation.GetUsage()
+		}, func() error { return generation.End(ctx) })
+	})
+
+	t.Run("children created concurrently", func(t *testing.T) {
+		trace := lf.Trace("trace")
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				span := trace.Span("span")
+				span.ChildGeneration("generation").Output("done").End(ctx)
+				span.End(ctx)
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, trace.End(ctx))
+	})
+}
+
+func TestGetStats_ConcurrentCreation(t *testing.T) {
+	lf := newStrictTestLangfuse(t, false)
+	const goroutines, iterations = 8, 100
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				lf.Trace("trace")
+				lf.Span("span")
+				lf.Generation("generation")
+				_ = lf.GetStats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(goroutines*iterations), stats.TracesCreated)
+	assert.Equal(t, int64(goroutines*iterations), stats.SpansCreated)
+	assert.Equal(t, int64(goroutines*iterations), stats.GenerationsCreated)
+	assert.False(t, stats.LastActivity.Before(stats.CreatedAt))
+}
+
+func BenchmarkTrace_Concurrent(b *testing.B) {
+	lf := newTestLangfuse(b, http.NotFoundHandler())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lf.Trace("trace")
+		}
+	})
+}
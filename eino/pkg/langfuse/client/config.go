@@ -5,6 +5,50 @@ import "eino/pkg/langfuse/config"
 // Re-export config types and functions for backward compatibility
 type Config = config.Config
 type ConfigOption = config.ConfigOption
+type EventMiddleware = config.EventMiddleware
+type ContextExtractor = config.ContextExtractor
+type IngestionTransport = config.IngestionTransport
+type OutputFormatter = config.OutputFormatter
+type ErrorHandler = config.ErrorHandler
+type TimeFormat = config.TimeFormat
+type PayloadMode = config.PayloadMode
+type ProjectCredentials = config.ProjectCredentials
+type TimeAnomalyPolicy = config.TimeAnomalyPolicy
+type SamplingRule = config.SamplingRule
+type SamplingMatch = config.SamplingMatch
+type HealthThresholds = config.HealthThresholds
+
+// Supported metadata time formats
+const (
+	TimeFormatRFC3339Nano = config.TimeFormatRFC3339Nano
+	TimeFormatEpochMillis = config.TimeFormatEpochMillis
+)
+
+// Supported payload modes
+const (
+	PayloadModeFull         = config.PayloadModeFull
+	PayloadModeMetadataOnly = config.PayloadModeMetadataOnly
+	PayloadModeInputOnly    = config.PayloadModeInputOnly
+	PayloadModeOutputOnly   = config.PayloadModeOutputOnly
+)
+
+// Langfuse Cloud regions and their hosts
+const (
+	RegionEU = config.RegionEU
+	RegionUS = config.RegionUS
+	HostEU   = config.HostEU
+	HostUS   = config.HostUS
+)
+
+// Supported time anomaly policies
+const (
+	TimeAnomalyClamp  = config.TimeAnomalyClamp
+	TimeAnomalySwap   = config.TimeAnomalySwap
+	TimeAnomalyReject = config.TimeAnomalyReject
+)
+
+// DefaultProject is the key of the project of the main credentials in ClientStats.Projects
+const DefaultProject = config.DefaultProject
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
@@ -29,16 +73,71 @@ func DefaultConfig() *Config {
 // Configuration option functions
 var (
 	WithHost        = config.WithHost
+	WithRegion      = config.WithRegion
+	WithAPIVersion  = config.WithAPIVersion
 	WithCredentials = config.WithCredentials
 	WithPublicKey   = config.WithPublicKey
 	WithSecretKey   = config.WithSecretKey
+
+	WithCredentialsFromFiles = config.WithCredentialsFromFiles
+
 	WithTimeout     = config.WithTimeout
 	WithRetryConfig = config.WithRetryConfig
 	WithQueueConfig = config.WithQueueConfig
 	WithDebug       = config.WithDebug
 	WithEnabled     = config.WithEnabled
 	WithBatchMode   = config.WithBatchMode
+	WithStrictMode  = config.WithStrictMode
 	WithRelease     = config.WithRelease
 	WithEnvironment = config.WithEnvironment
+	WithProjectID   = config.WithProjectID
+	WithDefaultTags = config.WithDefaultTags
 	WithUserAgent   = config.WithUserAgent
+
+	WithBatchRetries     = config.WithBatchRetries
+	WithMaxBatchAttempts = config.WithMaxBatchAttempts
+
+	WithProjectCredentials = config.WithProjectCredentials
+
+	WithDebugRingBuffer = config.WithDebugRingBuffer
+
+	WithMetadataTimeFormat = config.WithMetadataTimeFormat
+	WithOutputFormatter    = config.WithOutputFormatter
+	WithDeltaUpdates       = config.WithDeltaUpdates
+	WithCoalesceUpdates    = config.WithCoalesceUpdates
+	WithSkipInvalidEvents  = config.WithSkipInvalidEvents
+	WithPromptCacheTTL     = config.WithPromptCacheTTL
+	WithErrorPropagation   = config.WithErrorPropagation
+
+	WithPayloadMode                = config.WithPayloadMode
+	WithPayloadModeOverrideAllowed = config.WithPayloadModeOverrideAllowed
+	WithTimeAnomalyPolicy          = config.WithTimeAnomalyPolicy
+	WithUserIDHashSalt             = config.WithUserIDHashSalt
+
+	WithAllowedScoreNames = config.WithAllowedScoreNames
+	WithScoreConfigNames  = config.WithScoreConfigNames
+
+	WithRejectWhenQueueFull = config.WithRejectWhenQueueFull
+	WithErrorHandler        = config.WithErrorHandler
+
+	WithSampleRate    = config.WithSampleRate
+	WithSamplingRules = config.WithSamplingRules
+
+	WithShutdownGracePeriod = config.WithShutdownGracePeriod
+	WithForceEndOnShutdown  = config.WithForceEndOnShutdown
+	WithSignalShutdown      = config.WithSignalShutdown
+
+	WithHealthThresholds = config.WithHealthThresholds
+	WithHealthPolling    = config.WithHealthPolling
+
+	WithConnectionPoolConfig = config.WithConnectionPoolConfig
+	WithKeepAlive            = config.WithKeepAlive
+
+	WithRequestInterceptor  = config.WithRequestInterceptor
+	WithResponseInterceptor = config.WithResponseInterceptor
+	WithEventMiddleware     = config.WithEventMiddleware
+	WithContextExtractor    = config.WithContextExtractor
+	WithIngestionTransport  = config.WithIngestionTransport
+	WithHTTPTransport       = config.WithHTTPTransport
+	WithSecondaryHost       = config.WithSecondaryHost
 )
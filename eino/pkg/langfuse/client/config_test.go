@@ -1,12 +1,19 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"eino/pkg/langfuse/config"
 	"eino/pkg/langfuse/internal/utils"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,6 +37,8 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 10*time.Second, config.FlushInterval)
 	assert.Equal(t, 1000, config.QueueSize)
 	assert.Equal(t, 1, config.WorkerCount)
+	assert.Equal(t, 1, config.BatchRetries)
+	assert.Equal(t, 8, config.MaxBatchAttempts)
 
 	// Test Feature flags
 	assert.False(t, config.Debug)
@@ -177,7 +186,7 @@ func TestConfig_Validate(t *testing.T) {
 		name        string
 		setupConfig func() *Config
 		expectError bool
-		errorField  string
+		errorFields []string
 	}{
 		{
 			name: "valid config",
@@ -197,7 +206,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "publicKey",
+			errorFields: []string{"publicKey"},
 		},
 		{
 			name: "missing secret key",
@@ -207,7 +216,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "secretKey",
+			errorFields: []string{"secretKey"},
 		},
 		{
 			name: "empty host",
@@ -219,7 +228,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "host",
+			errorFields: []string{"host"},
 		},
 		{
 			name: "host without protocol",
@@ -231,7 +240,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "host",
+			errorFields: []string{"host"},
 		},
 		{
 			name: "zero timeout",
@@ -243,7 +252,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "timeout",
+			errorFields: []string{"timeout"},
 		},
 		{
 			name: "negative timeout",
@@ -255,7 +264,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "timeout",
+			errorFields: []string{"timeout"},
 		},
 		{
 			name: "zero flush at",
@@ -267,7 +276,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "flushAt",
+			errorFields: []string{"flushAt"},
 		},
 		{
 			name: "negative flush interval",
@@ -279,7 +288,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "flushInterval",
+			errorFields: []string{"flushInterval"},
 		},
 		{
 			name: "zero queue size",
@@ -291,7 +300,34 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "queueSize",
+			errorFields: []string{"queueSize"},
+		},
+		{
+			name: "missing credentials and host",
+			setupConfig: func() *Config {
+				config := DefaultConfig()
+				config.Host = ""
+				return config
+			},
+			expectError: true,
+			errorFields: []string{"publicKey", "secretKey", "host"},
+		},
+		{
+			name: "invalid host and queue settings",
+			setupConfig: func() *Config {
+				config := DefaultConfig()
+				config.PublicKey = "pk_test_12345"
+				config.SecretKey = "sk_test_67890"
+				config.Host = "cloud.langfuse.com"
+				config.Timeout = 0
+				config.FlushAt = -1
+				config.FlushInterval = 0
+				config.QueueSize = 0
+				config.WorkerCount = 0
+				return config
+			},
+			expectError: true,
+			errorFields: []string{"host", "timeout", "flushAt", "flushInterval", "queueSize", "workerCount"},
 		},
 		{
 			name: "zero worker count",
@@ -303,7 +339,7 @@ func TestConfig_Validate(t *testing.T) {
 				return config
 			},
 			expectError: true,
-			errorField:  "workerCount",
+			errorFields: []string{"workerCount"},
 		},
 	}
 
@@ -313,14 +349,14 @@ func TestConfig_Validate(t *testing.T) {
 			err := config.Validate()
 
 			if tt.expectError {
-				assert.Error(t, err)
-				if tt.errorField != "" {
-					configErr, ok := err.(*utils.ConfigurationError)
-					assert.True(t, ok, "Expected ConfigurationError")
-					assert.Equal(t, tt.errorField, configErr.Parameter)
+				require.NotNil(t, err)
+				var fields []string
+				for _, fieldErr := range *err {
+					fields = append(fields, fieldErr.Field)
 				}
+				assert.Equal(t, tt.errorFields, fields)
 			} else {
-				assert.NoError(t, err)
+				assert.Nil(t, err)
 			}
 		})
 	}
@@ -364,9 +400,22 @@ func TestNewConfig(t *testing.T) {
 		)
 
 		assert.Error(t, err)
-		configErr, ok := err.(*utils.ConfigurationError)
-		assert.True(t, ok)
-		assert.Equal(t, "secretKey", configErr.Parameter)
+		var validationErrs *utils.ValidationErrors
+		require.True(t, errors.As(err, &validationErrs))
+		require.Len(t, *validationErrs, 1)
+		assert.Equal(t, "secretKey", (*validationErrs)[0].Field)
+	})
+
+	t.Run("valid config has no typed nil error", func(t *testing.T) {
+		clearLangfuseEnvVars()
+
+		config, err := NewConfig(WithCredentials("pk_test_12345", "sk_test_67890"))
+		assert.True(t, err == nil, "NewConfig returned a non-nil error %#v", err)
+		require.NotNil(t, config)
+
+		validationErrs := config.Validate()
+		assert.Nil(t, validationErrs)
+		assert.True(t, validationErrs == nil)
 	})
 
 	t.Run("option error", func(t *testing.T) {
@@ -410,6 +459,26 @@ func TestConfigOptions(t *testing.T) {
 			expectError: true,
 			validate:    nil,
 		},
+		{
+			name:        "WithBatchRetries valid",
+			option:      WithBatchRetries(0),
+			expectError: false,
+			validate: func(t *testing.T, config *Config) {
+				assert.Equal(t, 0, config.BatchRetries)
+			},
+		},
+		{
+			name:        "WithBatchRetries negative",
+			option:      WithBatchRetries(-1),
+			expectError: true,
+			validate:    nil,
+		},
+		{
+			name:        "WithMaxBatchAttempts negative",
+			option:      WithMaxBatchAttempts(-1),
+			expectError: true,
+			validate:    nil,
+		},
 		{
 			name:        "WithCredentials valid",
 			option:      WithCredentials("pk_test", "sk_test"),
@@ -676,6 +745,222 @@ func TestConfig_MultipleOptions(t *testing.T) {
 	assert.Equal(t, "test-agent/1.0.0", config.HTTPUserAgent)
 }
 
+func TestConfig_HostNormalization(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		wantHost    string
+		wantWarning bool
+	}{
+		{name: "plain host", host: "https://cloud.langfuse.com", wantHost: "https://cloud.langfuse.com"},
+		{name: "trailing slash", host: "https://cloud.langfuse.com/", wantHost: "https://cloud.langfuse.com"},
+		{name: "api path", host: "https://cloud.langfuse.com/api/public", wantHost: "https://cloud.langfuse.com", wantWarning: true},
+		{name: "project url", host: "https://us.cloud.langfuse.com/project/abc?tab=traces", wantHost: "https://us.cloud.langfuse.com", wantWarning: true},
+		{name: "port kept", host: "http://localhost:3000/", wantHost: "http://localhost:3000"},
+		{name: "no protocol left for validation", host: "cloud.langfuse.com/api", wantHost: "cloud.langfuse.com/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			require.NoError(t, WithHost(tt.host)(config))
+
+			assert.Equal(t, tt.wantHost, config.Host)
+			if tt.wantWarning {
+				require.Len(t, config.Warnings, 1)
+				assert.Contains(t, config.Warnings[0], "contains a path, query or fragment")
+				assert.Contains(t, config.Warnings[0], tt.wantHost)
+			} else {
+				assert.Empty(t, config.Warnings)
+			}
+		})
+	}
+
+	t.Run("environment variable", func(t *testing.T) {
+		originalVars := saveEnvironmentVars()
+		defer restoreEnvironmentVars(originalVars)
+		clearLangfuseEnvVars()
+
+		os.Setenv("LANGFUSE_HOST", "https://cloud.langfuse.com/api/public/ingestion")
+
+		config := DefaultConfig()
+		require.NoError(t, config.LoadFromEnvironment())
+		assert.Equal(t, "https://cloud.langfuse.com", config.Host)
+		assert.Len(t, config.Warnings, 1)
+	})
+
+	t.Run("logged by New", func(t *testing.T) {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		lf, err := NewWithOptions(
+			WithCredentials("pk-lf-12345", "sk-lf-67890"),
+			WithHost("https://cloud.langfuse.com/api/public"),
+			WithEnabled(false),
+		)
+		require.NoError(t, err)
+		defer lf.Shutdown(context.Background())
+		assert.Contains(t, logs.String(), "langfuse: host \"https://cloud.langfuse.com/api/public\" contains a path")
+	})
+}
+
+func TestWithRegion(t *testing.T) {
+	for region, host := range map[string]string{"eu": HostEU, "us": HostUS, "US": HostUS} {
+		t.Run(region, func(t *testing.T) {
+			config := DefaultConfig()
+			require.NoError(t, WithRegion(region)(config))
+			assert.Equal(t, host, config.Host)
+		})
+	}
+
+	t.Run("unknown region", func(t *testing.T) {
+		err := WithRegion("ap")(DefaultConfig())
+
+		var configErr *utils.ConfigurationError
+		require.True(t, errors.As(err, &configErr))
+		assert.Equal(t, "region", configErr.Parameter)
+		assert.Contains(t, err.Error(), `expected "eu" or "us", got "ap"`)
+		assert.Contains(t, err.Error(), "WithHost")
+	})
+
+	t.Run("region of well-known hosts", func(t *testing.T) {
+		assert.Equal(t, RegionEU, config.RegionForHost("https://cloud.langfuse.com"))
+		assert.Equal(t, RegionUS, config.RegionForHost("https://US.cloud.langfuse.com/"))
+		assert.Equal(t, "", config.RegionForHost("https://langfuse.example.com"))
+		assert.Equal(t, "", config.RegionForHost("http://localhost:3000"))
+	})
+}
+
+func TestWithAPIVersion(t *testing.T) {
+	config := DefaultConfig()
+	require.NoError(t, WithAPIVersion("v1")(config))
+	assert.Equal(t, "v1", config.APIVersion)
+
+	err := WithAPIVersion("v2")(config)
+	var configErr *utils.ConfigurationError
+	require.True(t, errors.As(err, &configErr))
+	assert.Equal(t, "apiVersion", configErr.Parameter)
+	assert.Contains(t, err.Error(), "expected v1, got v2")
+
+	t.Run("rejected at validation", func(t *testing.T) {
+		config := DefaultConfig()
+		config.PublicKey = "pk-test"
+		config.SecretKey = "sk-test"
+		config.APIVersion = "v2"
+
+		errs := config.Validate()
+		require.NotNil(t, errs)
+		assert.Contains(t, errs.Error(), "apiVersion")
+	})
+
+	t.Run("from the environment", func(t *testing.T) {
+		clearLangfuseEnvVars()
+		defer os.Unsetenv("LANGFUSE_API_VERSION")
+		os.Setenv("LANGFUSE_API_VERSION", "V1")
+
+		config := DefaultConfig()
+		require.NoError(t, config.LoadFromEnvironment())
+		assert.Equal(t, "v1", config.APIVersion)
+	})
+}
+
+func TestConfig_KeyFormatErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      ConfigOption
+		wantParam   string
+		wantMessage string
+	}{
+		{
+			name:        "public key without prefix",
+			option:      WithPublicKey("lf-12345"),
+			wantParam:   "publicKey",
+			wantMessage: `expected a key starting with "pk-lf-", got "lf-..."`,
+		},
+		{
+			name:        "secret key without prefix",
+			option:      WithSecretKey("lf-67890"),
+			wantParam:   "secretKey",
+			wantMessage: `expected a key starting with "sk-lf-", got "lf-..."`,
+		},
+		{
+			name:        "secret key passed as public key",
+			option:      WithPublicKey("sk-lf-very-secret"),
+			wantParam:   "publicKey",
+			wantMessage: "are the public and secret keys swapped?",
+		},
+		{
+			name:        "public key passed as secret key",
+			option:      WithSecretKey("pk-lf-12345"),
+			wantParam:   "secretKey",
+			wantMessage: "are the public and secret keys swapped?",
+		},
+		{
+			name:        "swapped credentials",
+			option:      WithCredentials("sk-lf-67890", "pk-lf-12345"),
+			wantParam:   "publicKey",
+			wantMessage: "are the public and secret keys swapped?",
+		},
+		{
+			name:        "invalid secret key in credentials",
+			option:      WithCredentials("pk-lf-12345", "secret"),
+			wantParam:   "secretKey",
+			wantMessage: `got "sec..."`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.option(DefaultConfig())
+
+			var configErr *utils.ConfigurationError
+			require.True(t, errors.As(err, &configErr))
+			assert.Equal(t, tt.wantParam, configErr.Parameter)
+			assert.Contains(t, err.Error(), tt.wantMessage)
+			assert.NotContains(t, err.Error(), "very-secret", "keys must not be echoed in errors")
+		})
+	}
+
+	t.Run("swapped environment variables", func(t *testing.T) {
+		originalVars := saveEnvironmentVars()
+		defer restoreEnvironmentVars(originalVars)
+		clearLangfuseEnvVars()
+
+		os.Setenv("LANGFUSE_PUBLIC_KEY", "sk-lf-very-secret")
+		os.Setenv("LANGFUSE_SECRET_KEY", "pk-lf-12345")
+
+		config := DefaultConfig()
+		require.NoError(t, config.LoadFromEnvironment())
+		errs := config.Validate()
+		require.NotNil(t, errs)
+		require.Len(t, *errs, 2)
+		assert.Equal(t, "publicKey", (*errs)[0].Field)
+		assert.Equal(t, "secretKey", (*errs)[1].Field)
+		assert.Contains(t, errs.Error(), "are the public and secret keys swapped?")
+		assert.NotContains(t, errs.Error(), "very-secret", "keys must not be echoed in errors")
+	})
+
+	t.Run("struct literal keys are checked by Validate", func(t *testing.T) {
+		config := DefaultConfig()
+		config.PublicKey = "lf-12345"
+		config.SecretKey = "sk-lf-67890"
+
+		errs := config.Validate()
+		require.NotNil(t, errs)
+		require.Len(t, *errs, 1)
+		assert.Equal(t, "publicKey", (*errs)[0].Field)
+		assert.Contains(t, (*errs)[0].Message, `a key starting with "pk-lf-"`)
+	})
+
+	t.Run("langfuse keys accepted", func(t *testing.T) {
+		config := DefaultConfig()
+		require.NoError(t, WithCredentials("pk-lf-12345", "sk-lf-67890")(config))
+		assert.Equal(t, "pk-lf-12345", config.PublicKey)
+		assert.Equal(t, "sk-lf-67890", config.SecretKey)
+	})
+}
+
 func TestConfig_EdgeCases(t *testing.T) {
 	t.Run("empty string environment variables", func(t *testing.T) {
 		// Save original env vars
@@ -741,6 +1026,8 @@ func saveEnvironmentVars() map[string]string {
 		"LANGFUSE_HOST":           os.Getenv("LANGFUSE_HOST"),
 		"LANGFUSE_PUBLIC_KEY":     os.Getenv("LANGFUSE_PUBLIC_KEY"),
 		"LANGFUSE_SECRET_KEY":     os.Getenv("LANGFUSE_SECRET_KEY"),
+		config.PublicKeyFileEnv:   os.Getenv(config.PublicKeyFileEnv),
+		config.SecretKeyFileEnv:   os.Getenv(config.SecretKeyFileEnv),
 		"LANGFUSE_TIMEOUT":        os.Getenv("LANGFUSE_TIMEOUT"),
 		"LANGFUSE_RETRY_COUNT":    os.Getenv("LANGFUSE_RETRY_COUNT"),
 		"LANGFUSE_FLUSH_AT":       os.Getenv("LANGFUSE_FLUSH_AT"),
@@ -771,6 +1058,8 @@ func clearLangfuseEnvVars() {
 		"LANGFUSE_HOST",
 		"LANGFUSE_PUBLIC_KEY",
 		"LANGFUSE_SECRET_KEY",
+		config.PublicKeyFileEnv,
+		config.SecretKeyFileEnv,
 		"LANGFUSE_TIMEOUT",
 		"LANGFUSE_RETRY_COUNT",
 		"LANGFUSE_FLUSH_AT",
@@ -782,9 +1071,134 @@ func clearLangfuseEnvVars() {
 		"LANGFUSE_BATCH_MODE",
 		"LANGFUSE_RELEASE",
 		"LANGFUSE_ENVIRONMENT",
+		"LANGFUSE_API_VERSION",
+		"LANGFUSE_PAYLOAD_MODE",
 	}
 
 	for _, env := range envVars {
 		os.Unsetenv(env)
 	}
-}
\ No newline at end of file
+}
+func TestWithScoreNames(t *testing.T) {
+	config := DefaultConfig()
+	names := []string{"accuracy", "latency"}
+	require.NoError(t, WithAllowedScoreNames(names)(config))
+	names[0] = "changed"
+	assert.Equal(t, []string{"accuracy", "latency"}, config.AllowedScoreNames)
+
+	var configErr *utils.ConfigurationError
+	require.True(t, errors.As(WithAllowedScoreNames([]string{"accuracy", ""})(config), &configErr))
+	assert.Equal(t, "allowedScoreNames", configErr.Parameter)
+
+	require.NoError(t, WithScoreConfigNames(5*time.Minute)(config))
+	assert.Equal(t, 5*time.Minute, config.ScoreNamesRefreshInterval)
+
+	require.True(t, errors.As(WithScoreConfigNames(0)(config), &configErr))
+	assert.Equal(t, "scoreNamesRefreshInterval", configErr.Parameter)
+}
+
+func TestConfig_Clone(t *testing.T) {
+	original, err := NewConfig(
+		WithCredentials("pk-test", "sk-test"),
+		WithAllowedScoreNames([]string{"accuracy", "helpfulness"}),
+		WithRequestInterceptor(func(req *resty.Request) error { return nil }),
+	)
+	require.NoError(t, err)
+	original.Warnings = []string{"path stripped from host"}
+
+	clone := original.Clone()
+	assert.Equal(t, original.Host, clone.Host)
+	assert.Equal(t, original.AllowedScoreNames, clone.AllowedScoreNames)
+
+	clone.AllowedScoreNames[0] = "correctness"
+	clone.AllowedScoreNames = append(clone.AllowedScoreNames, "tone")
+	clone.Warnings[0] = "changed"
+	clone.RequestInterceptors[0] = nil
+	clone.Host = "https://us.cloud.langfuse.com"
+
+	assert.Equal(t, []string{"accuracy", "helpfulness"}, original.AllowedScoreNames)
+	assert.Equal(t, []string{"path stripped from host"}, original.Warnings)
+	assert.NotNil(t, original.RequestInterceptors[0])
+	assert.Equal(t, "https://cloud.langfuse.com", original.Host)
+
+	t.Run("with options", func(t *testing.T) {
+		clone, err := original.CloneWithOptions(WithRelease("v2"), WithAllowedScoreNames([]string{"tone"}))
+		require.NoError(t, err)
+		assert.Equal(t, "v2", clone.Release)
+		assert.Equal(t, []string{"tone"}, clone.AllowedScoreNames)
+		assert.Empty(t, original.Release)
+		assert.Equal(t, []string{"accuracy", "helpfulness"}, original.AllowedScoreNames)
+
+		_, err = original.CloneWithOptions(WithUserIDHashSalt(""))
+		assert.Error(t, err)
+
+		// Options that leave the configuration invalid are rejected too
+		_, err = original.CloneWithOptions(func(c *config.Config) error { c.FlushAt = 0; return nil })
+		assert.Error(t, err)
+	})
+}
+
+// writeKeyFile writes content to a file named name in dir and returns its path
+func writeKeyFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestCredentialsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	publicKeyPath := writeKeyFile(t, dir, "public-key", "pk-lf-from-file\n")
+	secretKeyPath := writeKeyFile(t, dir, "secret-key", "  sk-lf-from-file\r\n\n")
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("LANGFUSE_PUBLIC_KEY", "")
+		t.Setenv("LANGFUSE_SECRET_KEY", "")
+		t.Setenv(config.PublicKeyFileEnv, publicKeyPath)
+		t.Setenv(config.SecretKeyFileEnv, secretKeyPath)
+
+		cfg, err := NewConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "pk-lf-from-file", cfg.PublicKey, "the trailing newline is trimmed")
+		assert.Equal(t, "sk-lf-from-file", cfg.SecretKey)
+	})
+
+	t.Run("files take precedence over inline keys", func(t *testing.T) {
+		t.Setenv("LANGFUSE_PUBLIC_KEY", "pk-lf-inline")
+		t.Setenv("LANGFUSE_SECRET_KEY", "sk-lf-inline")
+		t.Setenv(config.PublicKeyFileEnv, "")
+		t.Setenv(config.SecretKeyFileEnv, secretKeyPath)
+
+		cfg, err := NewConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "pk-lf-inline", cfg.PublicKey)
+		assert.Equal(t, "sk-lf-from-file", cfg.SecretKey)
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		t.Setenv(config.PublicKeyFileEnv, filepath.Join(dir, "missing"))
+		t.Setenv(config.SecretKeyFileEnv, "")
+
+		_, err := NewConfig()
+		var configErr *utils.ConfigurationError
+		require.ErrorAs(t, err, &configErr)
+		assert.Equal(t, "publicKey", configErr.Parameter)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		t.Setenv(config.PublicKeyFileEnv, "")
+		t.Setenv(config.SecretKeyFileEnv, "")
+
+		cfg, err := NewConfig(WithCredentialsFromFiles(publicKeyPath, secretKeyPath))
+		require.NoError(t, err)
+		assert.Equal(t, "pk-lf-from-file", cfg.PublicKey)
+		assert.Equal(t, "sk-lf-from-file", cfg.SecretKey)
+
+		_, err = NewConfig(WithCredentialsFromFiles(secretKeyPath, publicKeyPath))
+		assert.ErrorContains(t, err, "swapped", "keys read from files are checked")
+
+		empty := writeKeyFile(t, dir, "empty", "\n")
+		_, err = NewConfig(WithCredentialsFromFiles(publicKeyPath, empty))
+		assert.ErrorContains(t, err, "is empty")
+	})
+}
@@ -0,0 +1,91 @@
+package client
+
+import "context"
+
+type contextKey string
+
+const (
+	traceContextKey      contextKey = "langfuse_trace_builder"
+	spanContextKey       contextKey = "langfuse_span_builder"
+	generationContextKey contextKey = "langfuse_generation_builder"
+)
+
+// ContextWithTrace returns a copy of ctx carrying trace
+func ContextWithTrace(ctx context.Context, trace *TraceBuilder) context.Context {
+	return context.WithValue(ctx, traceContextKey, trace)
+}
+
+// ContextWithSpan returns a copy of ctx carrying span as the current span
+func ContextWithSpan(ctx context.Context, span *SpanBuilder) context.Context {
+	return context.WithValue(ctx, spanContextKey, span)
+}
+
+// ContextWithGeneration returns a copy of ctx carrying generation
+func ContextWithGeneration(ctx context.Context, generation *GenerationBuilder) context.Context {
+	return context.WithValue(ctx, generationContextKey, generation)
+}
+
+// TraceFromContext returns the trace carried by ctx, or nil
+func TraceFromContext(ctx context.Context) *TraceBuilder {
+	trace, _ := ctx.Value(traceContextKey).(*TraceBuilder)
+	return trace
+}
+
+// SpanFromContext returns the current span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *SpanBuilder {
+	span, _ := ctx.Value(spanContextKey).(*SpanBuilder)
+	return span
+}
+
+// GenerationFromContext returns the generation carried by ctx, or nil
+func GenerationFromContext(ctx context.Context) *GenerationBuilder {
+	generation, _ := ctx.Value(generationContextKey).(*GenerationBuilder)
+	return generation
+}
+
+// NewSpanInContext creates a span and returns it with a copy of ctx carrying it as the
+// current span, so code called with that context nests its observations under it. The
+// span is a child of the current span in ctx, else of the trace in ctx; with neither it
+// is a standalone span as created by Span. The span is not submitted until it is ended.
+//
+// Example:
+//
+//	span, ctx := lf.NewSpanInContext(ctx, "db")
+//	defer span.End(ctx)
+//	rows, err := db.QueryContext(ctx, query)
+//
+// If the client is disabled, the returned context carries a no-op span builder.
+func (lf *Langfuse) NewSpanInContext(ctx context.Context, name string) (*SpanBuilder, context.Context) {
+	var span *SpanBuilder
+	switch parent, trace := SpanFromContext(ctx), TraceFromContext(ctx); {
+	case lf.isDisabled():
+		span = newDisabledSpanBuilder(name)
+	case parent != nil:
+		span = parent.ChildSpan(name)
+	case trace != nil:
+		span = trace.Span(name)
+	default:
+		span = lf.Span(name)
+	}
+	return span, ContextWithSpan(ctx, span)
+}
+
+// NewGenerationInContext creates a generation like NewSpanInContext creates a span, and
+// returns it with a copy of ctx carrying it. Generations have no children, so the current
+// span of the returned context is left as it was.
+//
+// If the client is disabled, the returned context carries a no-op generation builder.
+func (lf *Langfuse) NewGenerationInContext(ctx context.Context, name string) (*GenerationBuilder, context.Context) {
+	var generation *GenerationBuilder
+	switch parent, trace := SpanFromContext(ctx), TraceFromContext(ctx); {
+	case lf.isDisabled():
+		generation = newDisabledGenerationBuilder(name)
+	case parent != nil:
+		generation = parent.ChildGeneration(name)
+	case trace != nil:
+		generation = trace.Generation(name)
+	default:
+		generation = lf.Generation(name)
+	}
+	return generation, ContextWithGeneration(ctx, generation)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flushedBodiesByID flushes the client and returns the body of every event by its id
+func flushedBodiesByID(t *testing.T, lf *Langfuse, recorder *ingestionRecorder) map[string]map[string]interface{} {
+	require.NoError(t, lf.Flush(context.Background()))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		bodies[body["id"].(string)] = body
+	}
+	return bodies
+}
+
+func TestNewSpanInContext(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	trace := lf.Trace("request")
+	ctx := ContextWithTrace(context.Background(), trace)
+
+	outer, ctx := lf.NewSpanInContext(ctx, "handler")
+	assert.Same(t, outer, SpanFromContext(ctx))
+	assert.Same(t, trace, TraceFromContext(ctx), "the trace is kept")
+
+	inner, innerCtx := lf.NewSpanInContext(ctx, "db")
+	assert.Same(t, inner, SpanFromContext(innerCtx))
+	assert.Same(t, outer, SpanFromContext(ctx), "the parent context is unchanged")
+
+	generation, genCtx := lf.NewGenerationInContext(innerCtx, "llm")
+	assert.Same(t, generation, GenerationFromContext(genCtx))
+	assert.Same(t, inner, SpanFromContext(genCtx), "a generation does not become the current span")
+
+	require.NoError(t, generation.End(ctx))
+	require.NoError(t, inner.End(ctx))
+	require.NoError(t, outer.End(ctx))
+	require.NoError(t, trace.End(ctx))
+
+	bodies := flushedBodiesByID(t, lf, recorder)
+	for _, id := range []string{outer.GetID(), inner.GetID(), generation.GetID()} {
+		require.Contains(t, bodies, id)
+		assert.Equal(t, trace.GetID(), bodies[id]["traceId"])
+	}
+	assert.Nil(t, bodies[outer.GetID()]["parentObservationId"], "the first span is a child of the trace")
+	assert.Equal(t, outer.GetID(), bodies[inner.GetID()]["parentObservationId"])
+	assert.Equal(t, inner.GetID(), bodies[generation.GetID()]["parentObservationId"])
+}
+
+func TestNewSpanInContext_Standalone(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+
+	span, ctx := lf.NewSpanInContext(context.Background(), "job")
+	assert.NotEmpty(t, span.GetTraceID())
+	assert.Same(t, span, SpanFromContext(ctx))
+	assert.Nil(t, TraceFromContext(ctx))
+
+	generation, ctx := lf.NewGenerationInContext(context.Background(), "llm")
+	assert.NotEmpty(t, generation.GetTraceID())
+	assert.Same(t, generation, GenerationFromContext(ctx))
+	assert.Nil(t, SpanFromContext(ctx))
+}
+
+func TestNewSpanInContext_Disabled(t *testing.T) {
+	lf, err := NewWithOptions(WithCredentials("pk-test", "sk-test"), WithEnabled(false))
+	require.NoError(t, err)
+
+	span, ctx := lf.NewSpanInContext(context.Background(), "db")
+	assert.Empty(t, span.GetID())
+	assert.Same(t, span, SpanFromContext(ctx))
+
+	generation, ctx := lf.NewGenerationInContext(ctx, "llm")
+	assert.Empty(t, generation.GetID())
+	assert.Same(t, generation, GenerationFromContext(ctx))
+}
@@ -0,0 +1,303 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	tracetypes "eino/pkg/langfuse/api/resources/traces/types"
+)
+
+const (
+	// ConversationTurnTraceName names the traces started by ConversationRecorder.Turn
+	ConversationTurnTraceName = "conversation-turn"
+
+	// ConversationTurnMetadataKey holds the turn index of a conversation turn trace
+	ConversationTurnMetadataKey = "conversationTurn"
+
+	// ConversationTurnTagPrefix prefixes the turn index in the tag of a conversation turn
+	// trace, e.g. "turn:3"
+	ConversationTurnTagPrefix = "turn:"
+
+	// ConversationHistoryMetadataKey holds the HistorySnapshot of a conversation turn trace
+	ConversationHistoryMetadataKey = "conversationHistory"
+
+	// defaultFullSnapshotEvery is how often the history is stored in full unless
+	// WithFullSnapshotEvery is given
+	defaultFullSnapshotEvery = 10
+)
+
+// HistorySnapshot is the message history of a conversation turn, as stored in the
+// metadata of its trace under ConversationHistoryMetadataKey. A full snapshot holds the
+// whole history; a delta only holds the messages added since the snapshot of BaseTurn,
+// whose history it extends.
+type HistorySnapshot struct {
+	Turn int `json:"turn"`
+
+	// BaseTurn is the turn whose history this snapshot extends, or -1 for a full snapshot
+	BaseTurn int `json:"baseTurn"`
+
+	// PrefixLength and PrefixHash describe the messages carried over from BaseTurn, so a
+	// broken chain is detected when the history is reconstructed
+	PrefixLength int    `json:"prefixLength,omitempty"`
+	PrefixHash   string `json:"prefixHash,omitempty"`
+
+	// Messages are the encoded ChatMessages of the snapshot
+	Messages []json.RawMessage `json:"messages"`
+
+	// Hash identifies the whole history at this turn
+	Hash string `json:"hash"`
+}
+
+// Full reports whether the snapshot holds the whole history
+func (s *HistorySnapshot) Full() bool {
+	return s.BaseTurn < 0
+}
+
+// ConversationOption configures a ConversationRecorder
+type ConversationOption func(*ConversationRecorder)
+
+// WithFullSnapshotEvery stores the history in full every n turns, and as a delta in
+// between (default 10). Smaller values keep reconstruction chains short at the cost of
+// larger traces; 1 stores every snapshot in full.
+func WithFullSnapshotEvery(n int) ConversationOption {
+	return func(r *ConversationRecorder) {
+		if n > 0 {
+			r.fullEvery = n
+		}
+	}
+}
+
+// ConversationRecorder records the turns of a chat session as traces, each with a
+// snapshot of the message history the model saw. To keep traces small, snapshots are
+// stored as deltas against the previous one, with a full snapshot every few turns;
+// ReconstructHistory rebuilds the history of any turn from the chain.
+//
+// The recorder only remembers the length and hash of the last snapshot, so its memory
+// does not grow with the conversation. It is safe for concurrent use, though turns are
+// expected to be recorded one after the other.
+type ConversationRecorder struct {
+	client    *Langfuse
+	sessionID string
+	fullEvery int
+
+	mu    sync.Mutex
+	turn  int
+	trace *TraceBuilder
+
+	// The last snapshot, which the next one is a delta against; lastTurn is -1 before any
+	lastTurn int
+	lastLen  int
+	lastHash string
+}
+
+// Conversation returns a recorder for the turns of the session sessionID. Turns are
+// numbered by the recorder, so each session should be recorded by a single recorder.
+//
+// Example:
+//
+//	rec := langfuse.Conversation(sessionID)
+//	trace := rec.Turn(userMessage)
+//	rec.SnapshotHistory(messages)
+//	// ... call the model with messages
+//	trace.End(ctx)
+func (lf *Langfuse) Conversation(sessionID string, opts ...ConversationOption) *ConversationRecorder {
+	r := &ConversationRecorder{
+		client:    lf,
+		sessionID: sessionID,
+		fullEvery: defaultFullSnapshotEvery,
+		turn:      -1,
+		lastTurn:  -1,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Turn starts the next turn, numbered from 0, and returns its trace with the session,
+// the user message as input, the turn index in metadata and a "turn:<index>" tag. The
+// caller ends the trace.
+func (r *ConversationRecorder) Turn(userMessage interface{}) *TraceBuilder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.turn++
+	r.trace = r.client.Trace(ConversationTurnTraceName).
+		WithSessionID(r.sessionID).
+		WithInput(userMessage).
+		AddMetadata(ConversationTurnMetadataKey, r.turn).
+		AddTag(ConversationTurnTagPrefix + strconv.Itoa(r.turn))
+	return r.trace
+}
+
+// SnapshotHistory stores the message history of the current turn in its trace. The
+// snapshot is a delta against the previous one when messages extend its history, and
+// full on every WithFullSnapshotEvery-th turn or when the history was rewritten. A second
+// snapshot in the same turn replaces the first and is stored in full.
+func (r *ConversationRecorder) SnapshotHistory(messages []ChatMessage) error {
+	encoded, err := encodeHistory(messages)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.trace == nil {
+		return fmt.Errorf("no conversation turn started")
+	}
+
+	snapshot := &HistorySnapshot{Turn: r.turn, BaseTurn: -1, Messages: encoded, Hash: hashHistory(encoded)}
+	if r.extendsLast(encoded) {
+		snapshot.BaseTurn = r.lastTurn
+		snapshot.PrefixLength = r.lastLen
+		snapshot.PrefixHash = r.lastHash
+		snapshot.Messages = encoded[r.lastLen:]
+	}
+	r.trace.AddMetadata(ConversationHistoryMetadataKey, snapshot)
+
+	r.lastTurn, r.lastLen, r.lastHash = r.turn, len(encoded), snapshot.Hash
+	return nil
+}
+
+// extendsLast reports whether the snapshot of history can be a delta against the last one
+func (r *ConversationRecorder) extendsLast(history []json.RawMessage) bool {
+	return r.lastTurn >= 0 && r.lastTurn < r.turn && r.turn%r.fullEvery != 0 &&
+		len(history) >= r.lastLen && hashHistory(history[:r.lastLen]) == r.lastHash
+}
+
+// encodeHistory encodes messages in canonical JSON, with object keys sorted, so the
+// hashes computed when recording match those of the snapshots read back from the API
+func encodeHistory(messages []ChatMessage) ([]json.RawMessage, error) {
+	encoded := make([]json.RawMessage, len(messages))
+	for i, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message %d: %w", i, err)
+		}
+		if encoded[i], err = canonicalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to encode message %d: %w", i, err)
+		}
+	}
+	return encoded, nil
+}
+
+func canonicalJSON(data []byte) (json.RawMessage, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// hashHistory hashes encoded messages in order
+func hashHistory(messages []json.RawMessage) string {
+	h := sha256.New()
+	for _, message := range messages {
+		h.Write(message)
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// ReconstructHistory rebuilds the message history of a turn from the conversation turn
+// traces of its session, following the snapshot chain back to the last full snapshot.
+// It fails if a snapshot of the chain is missing or does not match the one it extends.
+func ReconstructHistory(traces []commonTypes.Trace, turn int) ([]ChatMessage, error) {
+	snapshots := make(map[int]*HistorySnapshot)
+	for i := range traces {
+		snapshot, err := historySnapshotOf(&traces[i])
+		if err != nil {
+			return nil, fmt.Errorf("trace %s: %w", traces[i].ID, err)
+		}
+		if snapshot != nil {
+			snapshots[snapshot.Turn] = snapshot
+		}
+	}
+
+	snapshot, ok := snapshots[turn]
+	if !ok {
+		return nil, fmt.Errorf("no history snapshot for turn %d", turn)
+	}
+	chain := []*HistorySnapshot{snapshot}
+	for !snapshot.Full() {
+		if snapshot.BaseTurn >= snapshot.Turn {
+			return nil, fmt.Errorf("history of turn %d extends later turn %d", snapshot.Turn, snapshot.BaseTurn)
+		}
+		base, ok := snapshots[snapshot.BaseTurn]
+		if !ok {
+			return nil, fmt.Errorf("history of turn %d extends turn %d, which has no snapshot", snapshot.Turn, snapshot.BaseTurn)
+		}
+		snapshot = base
+		chain = append(chain, snapshot)
+	}
+
+	var history []json.RawMessage
+	for i := len(chain) - 1; i >= 0; i-- {
+		snapshot := chain[i]
+		if !snapshot.Full() && (len(history) != snapshot.PrefixLength || hashHistory(history) != snapshot.PrefixHash) {
+			return nil, fmt.Errorf("history of turn %d does not extend the history of turn %d", snapshot.Turn, snapshot.BaseTurn)
+		}
+		history = append(history, snapshot.Messages...)
+		if hashHistory(history) != snapshot.Hash {
+			return nil, fmt.Errorf("history of turn %d does not match its hash", snapshot.Turn)
+		}
+	}
+
+	messages := make([]ChatMessage, len(history))
+	for i, message := range history {
+		if err := json.Unmarshal(message, &messages[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode message %d: %w", i, err)
+		}
+	}
+	return messages, nil
+}
+
+// historySnapshotOf decodes the history snapshot in the metadata of trace, if it has one
+func historySnapshotOf(trace *commonTypes.Trace) (*HistorySnapshot, error) {
+	value, ok := trace.Metadata[ConversationHistoryMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot HistorySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid history snapshot: %w", err)
+	}
+	for i, message := range snapshot.Messages {
+		if snapshot.Messages[i], err = canonicalJSON(message); err != nil {
+			return nil, fmt.Errorf("invalid history snapshot: %w", err)
+		}
+	}
+	return &snapshot, nil
+}
+
+// ConversationHistory fetches the traces of the session sessionID and reconstructs the
+// message history of a turn recorded with a ConversationRecorder; see ReconstructHistory.
+func (lf *Langfuse) ConversationHistory(ctx context.Context, sessionID string, turn int) ([]ChatMessage, error) {
+	if lf.isDisabled() {
+		return nil, fmt.Errorf("client is disabled")
+	}
+
+	var traces []commonTypes.Trace
+	req := &tracetypes.PaginatedTracesRequest{Filter: &tracetypes.TraceFilter{
+		SessionIDs: []string{sessionID},
+		Names:      []string{ConversationTurnTraceName},
+	}}
+	err := lf.apiClient.Traces.IterateAll(ctx, req, func(trace *commonTypes.Trace) error {
+		traces = append(traces, *trace)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list traces of session %s: %w", sessionID, err)
+	}
+	return ReconstructHistory(traces, turn)
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// conversationHistories returns the history of each of turns turns, adding a user and
+// an assistant message per turn; the assistant asks for a tool in every third turn
+func conversationHistories(turns int) [][]ChatMessage {
+	histories := make([][]ChatMessage, turns)
+	var history []ChatMessage
+	for turn := range histories {
+		history = append(history, ChatMessage{Role: "user", Content: []ChatContentBlock{
+			{Type: ChatContentText, Text: fmt.Sprintf("question %d", turn)},
+		}})
+		answer := ChatContentBlock{Type: ChatContentText, Text: fmt.Sprintf("answer %d", turn)}
+		if turn%3 == 2 {
+			answer = ChatContentBlock{Type: ChatContentToolUse, ID: fmt.Sprintf("call-%d", turn), Name: "search",
+				Input: struct {
+					Query string `json:"query"`
+					Limit int    `json:"limit"`
+				}{Query: "turn", Limit: turn}}
+		}
+		history = append(history, ChatMessage{Role: "assistant", Content: []ChatContentBlock{answer}})
+		histories[turn] = append([]ChatMessage(nil), history...)
+	}
+	return histories
+}
+
+// recordConversation records a turn per history and returns the turn traces as they
+// would be read back from the API
+func recordConversation(t *testing.T, histories [][]ChatMessage, opts ...ConversationOption) []commonTypes.Trace {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	rec := lf.Conversation("session-1", opts...)
+	ids := make([]string, len(histories))
+	for turn, history := range histories {
+		trace := rec.Turn(history[len(history)-2])
+		require.NoError(t, rec.SnapshotHistory(history))
+		require.NoError(t, trace.Submit(ctx))
+		ids[turn] = trace.GetID()
+	}
+
+	bodies := flushedBodiesByID(t, lf, recorder)
+	traces := make([]commonTypes.Trace, len(ids))
+	for turn, id := range ids {
+		data, err := json.Marshal(bodies[id])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &traces[turn]))
+	}
+	return traces
+}
+
+// snapshotOf decodes the history snapshot of a turn trace
+func snapshotOf(t *testing.T, trace commonTypes.Trace) *HistorySnapshot {
+	t.Helper()
+	snapshot, err := historySnapshotOf(&trace)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot, "trace %s has no history snapshot", trace.ID)
+	return snapshot
+}
+
+// assertSameHistory compares messages by their JSON encoding, as tool inputs come back
+// from the API as maps
+func assertSameHistory(t *testing.T, expected, actual []ChatMessage, msgAndArgs ...interface{}) {
+	t.Helper()
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON), msgAndArgs...)
+}
+
+func TestConversationRecorder_DeltaEncoding(t *testing.T) {
+	traces := recordConversation(t, conversationHistories(4))
+
+	for turn, trace := range traces {
+		assert.Equal(t, ConversationTurnTraceName, *trace.Name)
+		assert.Equal(t, "session-1", *trace.SessionID)
+		assert.Contains(t, trace.Tags, fmt.Sprintf("turn:%d", turn))
+		assert.EqualValues(t, turn, trace.Metadata[ConversationTurnMetadataKey])
+		assert.JSONEq(t, fmt.Sprintf(`{"role":"user","content":[{"type":"text","text":"question %d"}]}`, turn), string(trace.Input))
+
+		snapshot := snapshotOf(t, trace)
+		assert.Equal(t, turn, snapshot.Turn)
+		if turn == 0 {
+			assert.True(t, snapshot.Full())
+			assert.Len(t, snapshot.Messages, 2)
+			continue
+		}
+
+		previous := snapshotOf(t, traces[turn-1])
+		assert.False(t, snapshot.Full())
+		assert.Equal(t, turn-1, snapshot.BaseTurn)
+		assert.Equal(t, 2*turn, snapshot.PrefixLength)
+		assert.Equal(t, previous.Hash, snapshot.PrefixHash)
+		assert.Len(t, snapshot.Messages, 2, "only the messages of the turn are stored")
+		assert.JSONEq(t, fmt.Sprintf(`{"role":"user","content":[{"type":"text","text":"question %d"}]}`, turn), string(snapshot.Messages[0]))
+	}
+}
+
+func TestConversationRecorder_PeriodicFullSnapshots(t *testing.T) {
+	histories := conversationHistories(8)
+
+	// Turn 4 rewrites the history, e.g. after summarizing older messages
+	summary := ChatMessage{Role: "user", Content: []ChatContentBlock{{Type: ChatContentText, Text: "summary of turns 0-3"}}}
+	for turn := 4; turn < len(histories); turn++ {
+		histories[turn] = append([]ChatMessage{summary}, histories[turn][8:]...)
+	}
+
+	traces := recordConversation(t, histories, WithFullSnapshotEvery(3))
+
+	var full []int
+	for turn, trace := range traces {
+		if snapshotOf(t, trace).Full() {
+			full = append(full, turn)
+		}
+	}
+	assert.Equal(t, []int{0, 3, 4, 6}, full, "every third turn and the rewritten history are stored in full")
+}
+
+func TestReconstructHistory(t *testing.T) {
+	histories := conversationHistories(8)
+	histories[5] = histories[5][2:] // the oldest exchange is dropped from the context
+	traces := recordConversation(t, histories, WithFullSnapshotEvery(4))
+
+	for turn, history := range histories {
+		reconstructed, err := ReconstructHistory(traces, turn)
+		require.NoError(t, err, "turn %d", turn)
+		assertSameHistory(t, history, reconstructed, "turn %d", turn)
+	}
+
+	t.Run("missing turn", func(t *testing.T) {
+		_, err := ReconstructHistory(append(traces[:2:2], traces[3:]...), 3)
+		assert.EqualError(t, err, "history of turn 3 extends turn 2, which has no snapshot")
+
+		_, err = ReconstructHistory(traces, 8)
+		assert.EqualError(t, err, "no history snapshot for turn 8")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]commonTypes.Trace(nil), traces...)
+		snapshot := snapshotOf(t, tampered[1])
+		snapshot.Messages[0] = json.RawMessage(`{"role":"user","content":[{"type":"text","text":"edited"}]}`)
+		tampered[1].Metadata = map[string]interface{}{ConversationHistoryMetadataKey: snapshot}
+
+		_, err := ReconstructHistory(tampered, 1)
+		assert.EqualError(t, err, "history of turn 1 does not match its hash")
+		_, err = ReconstructHistory(tampered, 2)
+		assert.EqualError(t, err, "history of turn 1 does not match its hash")
+		_, err = ReconstructHistory(tampered, 4)
+		assert.NoError(t, err, "a full snapshot starts a new chain")
+	})
+}
+
+func TestConversationRecorder_SnapshotHistory(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	rec := lf.Conversation("session-1")
+	histories := conversationHistories(2)
+
+	assert.EqualError(t, rec.SnapshotHistory(histories[0]), "no conversation turn started")
+
+	rec.Turn("hi")
+	require.NoError(t, rec.SnapshotHistory(histories[0]))
+	trace := rec.Turn("next")
+	require.NoError(t, rec.SnapshotHistory(histories[1][:3]))
+	require.NoError(t, rec.SnapshotHistory(histories[1]))
+	require.NoError(t, trace.Submit(context.Background()))
+
+	body := flushedBodiesByID(t, lf, recorder)[trace.GetID()]
+	snapshot := body["metadata"].(map[string]interface{})[ConversationHistoryMetadataKey].(map[string]interface{})
+	assert.EqualValues(t, -1, snapshot["baseTurn"], "a second snapshot of a turn is stored in full")
+	assert.Len(t, snapshot["messages"], 4)
+}
+
+func TestLangfuse_ConversationHistory(t *testing.T) {
+	histories := conversationHistories(5)
+	traces := recordConversation(t, histories, WithFullSnapshotEvery(3))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/traces", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "session-1", r.URL.Query().Get("sessionId"))
+		assert.Equal(t, ConversationTurnTraceName, r.URL.Query().Get("name"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": traces,
+			"meta": map[string]interface{}{"page": 1, "limit": 50, "totalItems": len(traces), "totalPages": 1},
+		})
+	})
+	lf := newTestLangfuse(t, mux)
+
+	history, err := lf.ConversationHistory(context.Background(), "session-1", 4)
+	require.NoError(t, err)
+	assertSameHistory(t, histories[4], history)
+}
@@ -0,0 +1,287 @@
+package client
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+const (
+	// redactedValue replaces credentials in the debug output
+	redactedValue = "[REDACTED]"
+
+	// debugFlushTimeout bounds how long a /flush request waits for the queue
+	debugFlushTimeout = 30 * time.Second
+)
+
+// DebugHandlerOption configures the handler returned by DebugHandler
+type DebugHandlerOption func(*debugHandler)
+
+// WithFlushToken enables the POST /flush sub-path, guarded by the given token.
+// Requests must send it as "Authorization: Bearer <token>". Without a token the
+// flush endpoint is disabled.
+func WithFlushToken(token string) DebugHandlerOption {
+	return func(h *debugHandler) {
+		h.flushToken = token
+	}
+}
+
+// DebugSnapshot is the JSON document served by DebugHandler
+type DebugSnapshot struct {
+	SDK     DebugSDKInfo     `json:"sdk"`
+	Build   DebugBuildInfo   `json:"build"`
+	Enabled bool             `json:"enabled"`
+	Closed  bool             `json:"closed"`
+	Stats   ClientStats      `json:"stats"`
+	Queue   *DebugQueueStats `json:"queue,omitempty"`
+	Health  DebugHealth      `json:"health"`
+	Config  DebugConfig      `json:"config"`
+
+	// RecentEvents holds the last enqueued events when WithDebugRingBuffer is set
+	RecentEvents []types.IngestionEvent `json:"recentEvents,omitempty"`
+}
+
+// DebugSDKInfo identifies the SDK build
+type DebugSDKInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DebugBuildInfo describes the running binary
+type DebugBuildInfo struct {
+	GoVersion  string `json:"goVersion"`
+	MainModule string `json:"mainModule,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+// DebugQueueStats is a snapshot of the ingestion queue statistics
+type DebugQueueStats struct {
+	EventsQueued     int64     `json:"eventsQueued"`
+	EventsProcessed  int64     `json:"eventsProcessed"`
+	EventsFailed     int64     `json:"eventsFailed"`
+	EventsDropped    int64     `json:"eventsDropped"`
+	EventsCoalesced  int64     `json:"eventsCoalesced"`
+	BatchesSubmitted int64     `json:"batchesSubmitted"`
+	BatchesFailed    int64     `json:"batchesFailed"`
+	AverageFlushTime string    `json:"averageFlushTime"`
+	LastFlushTime    time.Time `json:"lastFlushTime"`
+	QueueSize        int       `json:"queueSize"`
+	MaxQueueSize     int       `json:"maxQueueSize"`
+	Pending          int       `json:"pending"`
+	ActiveWorkers    int       `json:"activeWorkers"`
+	EventLatencyP50  string    `json:"eventLatencyP50"`
+	EventLatencyP95  string    `json:"eventLatencyP95"`
+	EventLatencyMax  string    `json:"eventLatencyMax"`
+
+	EventsBySource map[string]int64 `json:"eventsBySource,omitempty"`
+}
+
+// DebugHealth reports the result of the most recent health check
+type DebugHealth struct {
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"lastCheck"`
+}
+
+// DebugConfig is the client configuration with credentials redacted
+type DebugConfig struct {
+	Host           string   `json:"host"`
+	PublicKey      string   `json:"publicKey"`
+	SecretKey      string   `json:"secretKey"`
+	Environment    string   `json:"environment,omitempty"`
+	Release        string   `json:"release,omitempty"`
+	Timeout        string   `json:"timeout"`
+	RequestTimeout string   `json:"requestTimeout"`
+	RetryCount     int      `json:"retryCount"`
+	BatchRetries   int      `json:"batchRetries"`
+	FlushAt        int      `json:"flushAt"`
+	FlushInterval  string   `json:"flushInterval"`
+	QueueSize      int      `json:"queueSize"`
+	SampleRate     float64  `json:"sampleRate"`
+	Debug          bool     `json:"debug"`
+	StrictMode     bool     `json:"strictMode"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// debugHandler serves the debug snapshot and the optional flush trigger
+type debugHandler struct {
+	client     *Langfuse
+	flushToken string
+}
+
+// DebugHandler returns an http.Handler exposing SDK internals as JSON for troubleshooting.
+//
+// GET returns a DebugSnapshot combining client statistics, queue statistics, health
+// status, version information and the configuration with credentials redacted.
+// POST to the "/flush" sub-path triggers a manual flush when WithFlushToken is set.
+//
+// Mounting is left to the caller:
+//
+//	h := lf.DebugHandler(client.WithFlushToken(os.Getenv("LANGFUSE_DEBUG_TOKEN")))
+//	mux.Handle("/debug/langfuse", h)
+//	mux.Handle("/debug/langfuse/", h)
+func (lf *Langfuse) DebugHandler(opts ...DebugHandlerOption) http.Handler {
+	h := &debugHandler{client: lf}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/flush") {
+		h.serveFlush(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeDebugJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	writeDebugJSON(w, http.StatusOK, h.client.DebugSnapshot())
+}
+
+// serveFlush handles POST /flush
+func (h *debugHandler) serveFlush(w http.ResponseWriter, r *http.Request) {
+	if h.flushToken == "" {
+		writeDebugJSON(w, http.StatusNotFound, map[string]string{"error": "flush endpoint is disabled"})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeDebugJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.flushToken)) != 1 {
+		writeDebugJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), debugFlushTimeout)
+	defer cancel()
+
+	if err := h.client.Flush(ctx); err != nil {
+		writeDebugJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeDebugJSON(w, http.StatusOK, map[string]interface{}{"flushed": true})
+}
+
+// DebugSnapshot returns the document served by DebugHandler
+func (lf *Langfuse) DebugSnapshot() *DebugSnapshot {
+	cfg := lf.GetConfig()
+
+	lf.mu.RLock()
+	closed := lf.closed
+	lf.mu.RUnlock()
+
+	snapshot := &DebugSnapshot{
+		SDK: DebugSDKInfo{
+			Name:    cfg.SDKName,
+			Version: cfg.SDKVersion,
+		},
+		Build:   buildInfo(),
+		Enabled: cfg.Enabled,
+		Closed:  closed,
+		Stats:   *lf.GetStats(),
+		Config:  redactConfig(cfg),
+
+		RecentEvents: lf.RecentEvents(),
+	}
+
+	if lf.queue != nil {
+		qs := lf.queue.Stats()
+		snapshot.Queue = &DebugQueueStats{
+			EventsQueued:     qs.EventsQueued,
+			EventsProcessed:  qs.EventsProcessed,
+			EventsFailed:     qs.EventsFailed,
+			EventsDropped:    qs.EventsDropped,
+			EventsCoalesced:  qs.EventsCoalesced,
+			BatchesSubmitted: qs.BatchesSubmitted,
+			BatchesFailed:    qs.BatchesFailed,
+			AverageFlushTime: qs.AverageFlushTime.String(),
+			LastFlushTime:    qs.LastFlushTime,
+			QueueSize:        qs.QueueSize,
+			MaxQueueSize:     qs.MaxQueueSize,
+			Pending:          lf.queue.Size(),
+			ActiveWorkers:    qs.ActiveWorkers,
+			EventLatencyP50:  qs.EventLatencyP50.String(),
+			EventLatencyP95:  qs.EventLatencyP95.String(),
+			EventLatencyMax:  qs.EventLatencyMax.String(),
+			EventsBySource:   qs.EventsBySource,
+		}
+	}
+
+	if lf.apiClient != nil {
+		snapshot.Health = DebugHealth{
+			Healthy:   lf.apiClient.IsHealthy(),
+			LastCheck: lf.apiClient.GetLastHealthCheck(),
+		}
+	}
+
+	return snapshot
+}
+
+// redactConfig copies the reportable configuration, hiding credentials
+func redactConfig(cfg *Config) DebugConfig {
+	return DebugConfig{
+		Host:           cfg.Host,
+		PublicKey:      redactCredential(cfg.PublicKey),
+		SecretKey:      redactCredential(cfg.SecretKey),
+		Environment:    cfg.Environment,
+		Release:        cfg.Release,
+		Timeout:        cfg.Timeout.String(),
+		RequestTimeout: cfg.RequestTimeout.String(),
+		RetryCount:     cfg.RetryCount,
+		BatchRetries:   cfg.BatchRetries,
+		FlushAt:        cfg.FlushAt,
+		FlushInterval:  cfg.FlushInterval.String(),
+		QueueSize:      cfg.QueueSize,
+		SampleRate:     cfg.SampleRate,
+		Debug:          cfg.Debug,
+		StrictMode:     cfg.StrictMode,
+		Warnings:       cfg.Warnings,
+	}
+}
+
+// redactCredential hides a credential while still showing whether it is set
+func redactCredential(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// buildInfo reports the Go version and main module of the running binary
+func buildInfo() DebugBuildInfo {
+	info := DebugBuildInfo{GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.MainModule = bi.Main.Path
+		info.Version = bi.Main.Version
+	}
+	return info
+}
+
+// writeDebugJSON writes v as an indented JSON response
+func writeDebugJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestDebugHandler_Snapshot(t *testing.T) {
+	lf := newTestLangfuse(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.PublicKey = "pk-lf-very-public"
+		cfg.SecretKey = "sk-lf-very-secret"
+		cfg.Environment = "staging"
+	})
+	lf.Trace("one")
+	lf.Trace("two")
+
+	server := httptest.NewServer(lf.DebugHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/langfuse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var raw map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&raw))
+	for _, key := range []string{"sdk", "build", "enabled", "closed", "stats", "queue", "health", "config"} {
+		assert.Contains(t, raw, key)
+	}
+
+	data, err := json.Marshal(raw)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "pk-lf-very-public")
+	assert.NotContains(t, string(data), "sk-lf-very-secret")
+
+	cfg := raw["config"].(map[string]interface{})
+	assert.Equal(t, redactedValue, cfg["publicKey"])
+	assert.Equal(t, redactedValue, cfg["secretKey"])
+	assert.Equal(t, "staging", cfg["environment"])
+
+	stats := raw["stats"].(map[string]interface{})
+	assert.Equal(t, float64(2), stats["tracesCreated"])
+
+	sdk := raw["sdk"].(map[string]interface{})
+	assert.Equal(t, "langfuse-go", sdk["name"])
+
+	post, err := http.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	post.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, post.StatusCode)
+}
+
+func TestDebugHandler_Flush(t *testing.T) {
+	var batches int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/ingestion", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&batches, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`{"successes":[],"errors":[]}`))
+	})
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.FlushInterval = time.Hour
+	})
+
+	require.NoError(t, lf.Trace("pending").Submit(context.Background()))
+
+	server := httptest.NewServer(lf.DebugHandler(WithFlushToken("let-me-in")))
+	defer server.Close()
+
+	flush := func(token string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/debug/langfuse/flush", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, flush(""))
+	assert.Equal(t, http.StatusUnauthorized, flush("wrong"))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&batches))
+
+	assert.Equal(t, http.StatusOK, flush("let-me-in"))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&batches) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	resp, err := http.Get(server.URL + "/debug/langfuse/flush")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestDebugHandler_FlushDisabledWithoutToken(t *testing.T) {
+	lf := newTestLangfuse(t, http.NotFoundHandler())
+
+	server := httptest.NewServer(lf.DebugHandler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/flush", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// defaultState holds the package-level client returned by Default. A new once is
+// installed whenever SetDefault resets the default.
+type defaultState struct {
+	mu   sync.RWMutex
+	once *sync.Once
+	lf   *Langfuse
+	err  error
+}
+
+var defaultClient = &defaultState{once: &sync.Once{}}
+
+// newDefaultClient creates the default client from the environment, replaced in tests
+var newDefaultClient = func() (*Langfuse, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	// A client disabled through LANGFUSE_ENABLED needs no credentials
+	if !cfg.Enabled {
+		return newDisabledClient(cfg), nil
+	}
+	return New(cfg)
+}
+
+// Default returns the package-level client, creating it from environment variables on
+// first use, much like log.Default. Concurrent first calls create the client exactly
+// once.
+//
+// If the client cannot be created, e.g. because LANGFUSE_PUBLIC_KEY is not set, Default
+// returns a disabled client whose builders are no-ops, and Ready reports the error.
+// The default client is never shut down implicitly: call CloseDefault before the
+// process exits to flush pending events.
+//
+// Example:
+//
+//	if err := client.Ready(); err != nil {
+//		log.Printf("Langfuse tracing disabled: %v", err)
+//	}
+//	defer client.CloseDefault(context.Background())
+//
+//	trace := client.Trace("my-operation")
+func Default() *Langfuse {
+	for {
+		defaultClient.mu.RLock()
+		once := defaultClient.once
+		defaultClient.mu.RUnlock()
+
+		once.Do(func() { defaultClient.initialize(once) })
+
+		defaultClient.mu.RLock()
+		lf := defaultClient.lf
+		defaultClient.mu.RUnlock()
+		// nil only if SetDefault(nil) reset the default meanwhile
+		if lf != nil {
+			return lf
+		}
+	}
+}
+
+// initialize creates the default client unless SetDefault replaced once meanwhile
+func (d *defaultState) initialize(once *sync.Once) {
+	lf, err := newDefaultClient()
+	if err != nil {
+		err = fmt.Errorf("failed to initialize default client: %w", err)
+		lf = newDisabledClient(disabledConfig())
+	}
+
+	d.mu.Lock()
+	current := d.once == once
+	if current {
+		d.lf, d.err = lf, err
+	}
+	d.mu.Unlock()
+
+	if !current {
+		lf.Shutdown(context.Background())
+	}
+}
+
+// Ready creates the default client if needed and returns the error that prevented it
+// from being created, or nil if it is usable
+func Ready() error {
+	Default()
+
+	defaultClient.mu.RLock()
+	defer defaultClient.mu.RUnlock()
+	return defaultClient.err
+}
+
+// SetDefault replaces the default client and returns the previous one, which is not
+// shut down. Passing nil makes the next call to Default create a client from the
+// environment again. It is meant for tests:
+//
+//	previous := client.SetDefault(testClient)
+//	defer client.SetDefault(previous)
+func SetDefault(lf *Langfuse) *Langfuse {
+	defaultClient.mu.Lock()
+	defer defaultClient.mu.Unlock()
+
+	previous := defaultClient.lf
+	defaultClient.lf, defaultClient.err = lf, nil
+	defaultClient.once = &sync.Once{}
+	if lf != nil {
+		// Mark the new default as initialized so Default returns it as is
+		defaultClient.once.Do(func() {})
+	}
+	return previous
+}
+
+// CloseDefault shuts down the default client, flushing pending events. It does nothing
+// if the default client was never used. Afterwards the package-level helpers are no-ops.
+func CloseDefault(ctx context.Context) error {
+	defaultClient.mu.RLock()
+	lf := defaultClient.lf
+	defaultClient.mu.RUnlock()
+
+	if lf == nil {
+		return nil
+	}
+	return lf.Shutdown(ctx)
+}
+
+// Trace creates a trace builder on the default client
+func Trace(name string) *TraceBuilder {
+	return Default().Trace(name)
+}
+
+// Span creates a span builder on the default client
+func Span(name string) *SpanBuilder {
+	return Default().Span(name)
+}
+
+// Generation creates a generation builder on the default client
+func Generation(name string) *GenerationBuilder {
+	return Default().Generation(name)
+}
+
+// Score submits a score with the default client
+func Score(score *types.Score, opts ...ScoreOption) error {
+	return Default().Score(score, opts...)
+}
+
+// disabledConfig returns the configuration of a default client that could not be created
+func disabledConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Enabled = false
+	return cfg
+}
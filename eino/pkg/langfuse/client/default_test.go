@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// resetDefault gives the test a fresh, uninitialized default client, created by
+// newClient when set
+func resetDefault(t *testing.T, newClient func() (*Langfuse, error)) {
+	t.Helper()
+
+	previousState, previousNew := defaultClient, newDefaultClient
+	defaultClient = &defaultState{once: &sync.Once{}}
+	if newClient != nil {
+		newDefaultClient = newClient
+	}
+	t.Cleanup(func() {
+		CloseDefault(context.Background())
+		defaultClient, newDefaultClient = previousState, previousNew
+	})
+}
+
+func TestDefault_LazyInitialization(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	var calls atomic.Int32
+	resetDefault(t, func() (*Langfuse, error) {
+		calls.Add(1)
+		return lf, nil
+	})
+
+	require.NoError(t, CloseDefault(context.Background()))
+	assert.Zero(t, calls.Load(), "closing an unused default does not create it")
+
+	assert.Same(t, lf, Default())
+	assert.Same(t, lf, Default())
+	assert.NoError(t, Ready())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDefault_ConcurrentFirstUse(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	var calls atomic.Int32
+	resetDefault(t, func() (*Langfuse, error) {
+		calls.Add(1)
+		return lf, nil
+	})
+
+	var wg sync.WaitGroup
+	clients := make([]*Langfuse, 50)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i] = Default()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, client := range clients {
+		assert.Same(t, lf, client)
+	}
+}
+
+func TestDefault_MissingCredentials(t *testing.T) {
+	t.Setenv("LANGFUSE_PUBLIC_KEY", "")
+	t.Setenv("LANGFUSE_SECRET_KEY", "")
+	t.Setenv("LANGFUSE_ENABLED", "")
+	resetDefault(t, nil)
+
+	err := Ready()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "public key is required")
+
+	lf := Default()
+	require.NotNil(t, lf)
+	assert.False(t, lf.IsEnabled())
+
+	ctx := context.Background()
+	assert.NotPanics(t, func() {
+		trace := Trace("chat").WithUserID("user-1")
+		trace.Span("retrieve").End(ctx)
+		trace.Generation("llm").End(ctx)
+		Span("standalone").End(ctx)
+		Generation("standalone").End(ctx)
+		trace.End(ctx)
+	})
+	assert.NoError(t, Score(&types.Score{TraceID: "trace-1", Name: "accuracy", Value: json.RawMessage(`1`)}))
+	assert.NoError(t, CloseDefault(ctx))
+}
+
+func TestDefault_DisabledThroughEnvironment(t *testing.T) {
+	t.Setenv("LANGFUSE_PUBLIC_KEY", "")
+	t.Setenv("LANGFUSE_SECRET_KEY", "")
+	t.Setenv("LANGFUSE_ENABLED", "false")
+	resetDefault(t, nil)
+
+	assert.NoError(t, Ready())
+	assert.False(t, Default().IsEnabled())
+}
+
+func TestSetDefault(t *testing.T) {
+	created, _ := newPayloadTestLangfuse(t)
+	var calls atomic.Int32
+	resetDefault(t, func() (*Langfuse, error) {
+		calls.Add(1)
+		return created, nil
+	})
+
+	lf, recorder := newPayloadTestLangfuse(t)
+	assert.Nil(t, SetDefault(lf))
+	assert.Same(t, lf, Default())
+	assert.Zero(t, calls.Load(), "a replaced default is not created from the environment")
+
+	require.NoError(t, Trace("swapped").End(context.Background()))
+	bodies := flushedBodies(t, lf, recorder)
+	assert.Equal(t, "swapped", bodies["trace-update"]["name"])
+
+	assert.Same(t, lf, SetDefault(nil))
+	assert.Same(t, created, Default(), "the default is created again after a reset")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCloseDefault(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	resetDefault(t, func() (*Langfuse, error) { return lf, nil })
+
+	require.NoError(t, Trace("before-close").End(context.Background()))
+	require.NoError(t, CloseDefault(context.Background()))
+
+	recorder.mu.Lock()
+	assert.NotEmpty(t, recorder.events, "closing flushes pending events")
+	recorder.mu.Unlock()
+
+	assert.False(t, Default().IsEnabled())
+	assert.NotPanics(t, func() { Trace("after-close").End(context.Background()) })
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// deltaKeyFields are always sent in delta updates so the server can match the update
+// to the object created earlier
+var deltaKeyFields = map[string]bool{
+	"id":      true,
+	"traceId": true,
+	"type":    true,
+}
+
+// deltaSnapshot holds the JSON encoding of every field sent in a builder's create event.
+//
+// Fields are compared by their JSON encoding rather than by value, so values that were
+// mutated in place after the create event (maps, slices, pointers) are still detected
+// as changed.
+type deltaSnapshot map[string]json.RawMessage
+
+// deltaUpdates reports whether update events should only carry changed fields
+func (lf *Langfuse) deltaUpdates() bool {
+	return lf != nil && lf.config != nil && lf.config.DeltaUpdates
+}
+
+// takeDeltaSnapshot records the fields of a create event body. If the body cannot be
+// encoded the snapshot is empty, so later updates fall back to full events.
+func takeDeltaSnapshot(body interface{}) deltaSnapshot {
+	fields, err := encodeFields(body)
+	if err != nil {
+		return deltaSnapshot{}
+	}
+	return deltaSnapshot(fields)
+}
+
+// diff returns an event body holding only the key fields and the fields whose encoding
+// differs from the snapshot. The original body is returned if it cannot be encoded.
+func (s deltaSnapshot) diff(body interface{}) interface{} {
+	if s == nil {
+		return body
+	}
+
+	fields, err := encodeFields(body)
+	if err != nil {
+		return body
+	}
+
+	for name, value := range fields {
+		if deltaKeyFields[name] {
+			continue
+		}
+		if previous, ok := s[name]; ok && bytes.Equal(previous, value) {
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+// encodeFields encodes an event body into its top-level JSON fields
+func encodeFields(body interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// newDeltaTestLangfuse creates a delta-mode client recording every ingested event
+func newDeltaTestLangfuse(t *testing.T) (*Langfuse, *ingestionRecorder) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.DeltaUpdates = true
+	})
+	return lf, recorder
+}
+
+// flushedBodies flushes the client and returns the recorded event bodies by event type
+func flushedBodies(t *testing.T, lf *Langfuse, recorder *ingestionRecorder) map[string]map[string]interface{} {
+	require.NoError(t, lf.Flush(context.Background()))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range recorder.events {
+		bodies[event["type"].(string)] = event["body"].(map[string]interface{})
+	}
+	return bodies
+}
+
+func TestDeltaUpdates_SpanOmitsUnchangedInput(t *testing.T) {
+	lf, recorder := newDeltaTestLangfuse(t)
+	ctx := context.Background()
+	largeInput := strings.Repeat("context ", 10000)
+
+	span := lf.Trace("delta").Span("retrieve").Input(largeInput)
+	require.NoError(t, span.Begin(ctx))
+
+	span.Output("answer")
+	require.NoError(t, span.End(ctx))
+
+	bodies := flushedBodies(t, lf, recorder)
+
+	create := bodies["span-create"]
+	require.NotNil(t, create)
+	assert.Equal(t, largeInput, create["input"])
+
+	update := bodies["span-update"]
+	require.NotNil(t, update)
+	assert.Equal(t, span.GetID(), update["id"])
+	assert.Equal(t, span.GetTraceID(), update["traceId"])
+	assert.Equal(t, "answer", update["output"])
+	assert.NotNil(t, update["endTime"])
+	assert.NotContains(t, update, "input")
+	assert.NotContains(t, update, "name")
+	assert.NotContains(t, update, "startTime")
+}
+
+func TestDeltaUpdates_GenerationDetectsInPlaceChanges(t *testing.T) {
+	lf, recorder := newDeltaTestLangfuse(t)
+	ctx := context.Background()
+
+	generation := lf.Generation("completion").
+		Input(map[string]interface{}{"prompt": "hello"}).
+		AddMetadata("attempt", 1)
+	require.NoError(t, generation.Begin(ctx))
+
+	// Mutating the same metadata map after the create event is still a change
+	generation.AddMetadata("attempt", 2)
+	generation.Output("world")
+	require.NoError(t, generation.End(ctx))
+
+	update := flushedBodies(t, lf, recorder)["generation-update"]
+	require.NotNil(t, update)
+	assert.Equal(t, "world", update["output"])
+	metadata := update["metadata"].(map[string]interface{})
+	assert.Equal(t, float64(2), metadata["attempt"])
+	assert.Contains(t, metadata, LatencyMetadataKey)
+	assert.NotContains(t, update, "input")
+}
+
+func TestDeltaUpdates_Trace(t *testing.T) {
+	lf, recorder := newDeltaTestLangfuse(t)
+	ctx := context.Background()
+
+	trace := lf.Trace("delta-trace").Input(map[string]interface{}{"question": "why?"})
+	require.NoError(t, trace.Begin(ctx))
+
+	// A second create is still rejected while the trace is open for its update
+	assert.Error(t, trace.Submit(ctx))
+
+	trace.Output("because")
+	require.NoError(t, trace.Update(ctx))
+
+	update := flushedBodies(t, lf, recorder)["trace-update"]
+	require.NotNil(t, update)
+	assert.Equal(t, trace.GetID(), update["id"])
+	assert.Equal(t, "because", update["output"])
+	assert.NotContains(t, update, "input")
+
+	// The update ends the trace
+	assert.Error(t, trace.Update(ctx))
+}
+
+func TestDeltaUpdates_SubmitEndsBuilder(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.DeltaUpdates = true
+		cfg.ShutdownGracePeriod = 5 * time.Second
+		cfg.ForceEndOnShutdown = true
+	})
+	ctx := context.Background()
+
+	trace := lf.Trace("delta-submit")
+	span := trace.Span("retrieve")
+	generation := span.ChildGeneration("answer")
+	require.NoError(t, generation.Submit(ctx))
+	require.NoError(t, span.Submit(ctx))
+	require.NoError(t, trace.Submit(ctx))
+	assert.Empty(t, lf.UnendedBuilders())
+
+	// Submit is final in delta mode too
+	assert.Error(t, span.Update(ctx))
+
+	start := time.Now()
+	require.NoError(t, lf.Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second, "shutdown should not wait for submitted builders")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.events, 3)
+	for _, event := range recorder.events {
+		assert.Contains(t, event["type"], "-create")
+		metadata, _ := event["body"].(map[string]interface{})["metadata"].(map[string]interface{})
+		assert.NotContains(t, metadata, ForcedEndMetadataKey)
+	}
+}
+
+func TestDeltaSnapshot_Diff(t *testing.T) {
+	snapshot := takeDeltaSnapshot(map[string]interface{}{"id": "obs-1", "input": "same", "output": "old"})
+
+	diff := snapshot.diff(map[string]interface{}{"id": "obs-1", "input": "same", "output": "new", "endTime": "now"})
+	data, err := encodeFields(diff)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"id", "output", "endTime"}, keysOf(data))
+
+	// Without a snapshot the body is sent unchanged
+	var none deltaSnapshot
+	body := map[string]interface{}{"id": "obs-1"}
+	assert.Equal(t, body, none.diff(body))
+}
+
+func keysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
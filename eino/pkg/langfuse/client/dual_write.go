@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"eino/pkg/langfuse/api"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// SecondaryStats counts the batches copied to the secondary host configured with
+// WithSecondaryHost. They are kept apart from the primary counts in ClientStats, since
+// secondary failures never fail a batch.
+type SecondaryStats struct {
+	// BatchesSubmitted is the number of batches the secondary host accepted
+	BatchesSubmitted int64 `json:"batchesSubmitted"`
+
+	// BatchesFailed is the number of batches the secondary host rejected or that could
+	// not be sent to it
+	BatchesFailed int64 `json:"batchesFailed"`
+
+	// EventsSubmitted is the number of events in accepted batches
+	EventsSubmitted int64 `json:"eventsSubmitted"`
+
+	// EventsFailed is the number of events in failed batches
+	EventsFailed int64 `json:"eventsFailed"`
+
+	// LastError is the error of the most recent failed batch
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorAt is when the most recent batch failed
+	LastErrorAt time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// dualWriteTransport submits every batch to the primary and secondary destinations at the
+// same time and reports the primary's result
+type dualWriteTransport struct {
+	primary   IngestionTransport
+	secondary IngestionTransport
+
+	// logf reports secondary failures (default log.Printf)
+	logf func(format string, args ...interface{})
+
+	mu    sync.Mutex
+	stats SecondaryStats
+}
+
+// newSecondaryTransport creates the REST ingestion client of the secondary host
+func newSecondaryTransport(cfg *config.Config) (IngestionTransport, error) {
+	secondaryConfig := cfg.Clone()
+	secondaryConfig.Host = cfg.SecondaryHost
+	secondaryConfig.PublicKey = cfg.SecondaryPublicKey
+	secondaryConfig.SecretKey = cfg.SecondarySecretKey
+	secondaryConfig.IngestionTransport = nil
+	secondaryConfig.SkipInitialHealthCheck = true
+	secondaryConfig.RequireHealthyStart = false
+
+	apiClient, err := api.NewAPIClient(secondaryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secondary API client: %w", err)
+	}
+	return apiClient.Ingestion, nil
+}
+
+func (t *dualWriteTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	secondaryDone := make(chan struct{})
+	go func() {
+		defer close(secondaryDone)
+		t.submitSecondary(ctx, events)
+	}()
+
+	response, err := t.primary.SubmitBatch(ctx, events)
+	<-secondaryDone
+	return response, err
+}
+
+// submitSecondary sends the batch to the secondary host, recording the outcome
+func (t *dualWriteTransport) submitSecondary(ctx context.Context, events []ingestiontypes.IngestionEvent) {
+	response, err := t.secondary.SubmitBatch(ctx, events)
+	if err == nil && (response == nil || !response.Success) {
+		err = fmt.Errorf("batch of %d events was rejected", len(events))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err == nil {
+		t.stats.BatchesSubmitted++
+		t.stats.EventsSubmitted += int64(len(events))
+		return
+	}
+
+	t.stats.BatchesFailed++
+	t.stats.EventsFailed += int64(len(events))
+	t.stats.LastError = err.Error()
+	t.stats.LastErrorAt = time.Now()
+
+	logf := t.logf
+	if logf == nil {
+		logf = log.Printf
+	}
+	logf("langfuse: secondary host submission failed: %v", err)
+}
+
+// snapshot returns a copy of the secondary statistics
+func (t *dualWriteTransport) snapshot() SecondaryStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
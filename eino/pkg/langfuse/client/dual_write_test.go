@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// newDualWriteTestLangfuse creates a client whose secondary host is served by secondary
+func newDualWriteTestLangfuse(t *testing.T, secondary http.Handler) (*Langfuse, *ingestionRecorder, *[]string) {
+	server := httptest.NewServer(secondary)
+	t.Cleanup(server.Close)
+
+	lf, primary := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		require.NoError(t, WithSecondaryHost(server.URL, "pk-lf-secondary", "sk-lf-secondary")(cfg))
+	})
+	var logged []string
+	lf.secondary.logf = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+	return lf, primary, &logged
+}
+
+func TestSecondaryHost_DualWrite(t *testing.T) {
+	secondary := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", secondary)
+	lf, primary, logged := newDualWriteTestLangfuse(t, mux)
+
+	require.NoError(t, lf.Trace("checkout").ID("trace-1").Submit(context.Background()))
+	require.Contains(t, flushedBodies(t, lf, primary), "trace-create")
+
+	secondary.mu.Lock()
+	require.Len(t, secondary.events, 1, "the secondary host receives the batch")
+	assert.Equal(t, "trace-1", secondary.events[0]["body"].(map[string]interface{})["id"])
+	secondary.mu.Unlock()
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(1), stats.EventsSubmitted)
+	require.NotNil(t, stats.Secondary)
+	assert.Equal(t, int64(1), stats.Secondary.BatchesSubmitted)
+	assert.Equal(t, int64(1), stats.Secondary.EventsSubmitted)
+	assert.Empty(t, *logged)
+}
+
+func TestSecondaryHost_FailureDoesNotFailPrimary(t *testing.T) {
+	lf, primary, logged := newDualWriteTestLangfuse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	require.NoError(t, lf.Trace("checkout").Submit(context.Background()))
+	require.Contains(t, flushedBodies(t, lf, primary), "trace-create")
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(1), stats.EventsSubmitted)
+	assert.Zero(t, stats.EventsFailed)
+	assert.NoError(t, lf.stats.flushErr())
+
+	require.NotNil(t, stats.Secondary)
+	assert.Equal(t, int64(1), stats.Secondary.BatchesFailed)
+	assert.Equal(t, int64(1), stats.Secondary.EventsFailed)
+	assert.NotEmpty(t, stats.Secondary.LastError)
+	require.Len(t, *logged, 1)
+	assert.Contains(t, (*logged)[0], "secondary host")
+}
+
+func TestWithSecondaryHost(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, WithSecondaryHost("https://langfuse.internal/api", "pk-lf-1", "sk-lf-1")(cfg))
+	assert.Equal(t, "https://langfuse.internal", cfg.SecondaryHost)
+	assert.Len(t, cfg.Warnings, 1)
+
+	assert.Error(t, WithSecondaryHost("", "pk-lf-1", "sk-lf-1")(cfg))
+	assert.Error(t, WithSecondaryHost("https://langfuse.internal", "sk-lf-1", "pk-lf-1")(cfg))
+
+	cfg = DefaultConfig()
+	cfg.PublicKey, cfg.SecretKey = "pk-lf-1", "sk-lf-1"
+	cfg.SecondaryHost = "https://langfuse.internal"
+	errs := cfg.Validate()
+	require.NotNil(t, errs)
+	assert.Contains(t, errs.Error(), "secondary host requires a public and a secret key")
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// errorHandlerBufferSize is how many errors wait for a slow ErrorHandler before new
+// ones are discarded
+const errorHandlerBufferSize = 256
+
+// FlushError is passed to the ErrorHandler when an attempt to submit a batch fails.
+// The batch is retried until its attempts run out, then reported as a DeadLetterError;
+// a batch failing client-side validation is not retried.
+type FlushError struct {
+	// BatchSize is the number of events in the batch
+	BatchSize int
+
+	// Attempt numbers the failed attempt, from 0
+	Attempt int
+
+	Err error
+}
+
+// Error implements the error interface
+func (e *FlushError) Error() string {
+	return fmt.Sprintf("submitting batch of %d events failed (attempt %d): %v", e.BatchSize, e.Attempt+1, e.Err)
+}
+
+// Unwrap returns the error of the attempt
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// DeadLetterError is passed to the ErrorHandler when a batch failed its last attempt
+// and its events are lost
+type DeadLetterError struct {
+	// BatchSize is the number of events lost
+	BatchSize int
+
+	// Err is the error of the last attempt
+	Err error
+}
+
+// Error implements the error interface
+func (e *DeadLetterError) Error() string {
+	return fmt.Sprintf("batch of %d events dropped after its last attempt: %v", e.BatchSize, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *DeadLetterError) Unwrap() error {
+	return e.Err
+}
+
+// DroppedEventError is passed to the ErrorHandler when an event is dropped before it is
+// submitted, because the queue was full or the ingestion API rejected it
+type DroppedEventError struct {
+	EventID   string
+	EventType ingestiontypes.EventType
+
+	// Reason is "queue_full" or "ingestion_error: " followed by the API's message
+	Reason string
+}
+
+// Error implements the error interface
+func (e *DroppedEventError) Error() string {
+	return fmt.Sprintf("%s event %s dropped: %s", e.EventType, e.EventID, e.Reason)
+}
+
+// Unwrap returns ErrQueueFull for events dropped because the queue was full
+func (e *DroppedEventError) Unwrap() error {
+	if e.Reason == "queue_full" {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// errorDispatcher passes asynchronous errors to the ErrorHandler from its own goroutine,
+// so the queue hooks reporting them never wait on the handler
+type errorDispatcher struct {
+	handler ErrorHandler
+	errs    chan error
+
+	// done is closed on shutdown; errors already buffered are still delivered
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// discarded counts the errors dropped because the buffer was full
+	discarded atomic.Int64
+}
+
+func newErrorDispatcher(handler ErrorHandler) *errorDispatcher {
+	d := &errorDispatcher{
+		handler: handler,
+		errs:    make(chan error, errorHandlerBufferSize),
+		done:    make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+func (d *errorDispatcher) run() {
+	for {
+		select {
+		case err := <-d.errs:
+			d.handler(err)
+		case <-d.done:
+			for {
+				select {
+				case err := <-d.errs:
+					d.handler(err)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// report queues err for the handler, discarding it if the handler is too far behind
+func (d *errorDispatcher) report(err error) {
+	if d == nil {
+		return
+	}
+	select {
+	case d.errs <- err:
+	default:
+		d.discarded.Add(1)
+	}
+}
+
+// reportDrop reports an event dropped by the queue. Events dropped with their batch
+// after its last attempt are left to the DeadLetterError of the batch.
+func (d *errorDispatcher) reportDrop(event ingestiontypes.IngestionEvent, reason string) {
+	if d == nil || reason == "max_retries_exceeded" {
+		return
+	}
+	d.report(&DroppedEventError{EventID: event.ID, EventType: event.Type, Reason: reason})
+}
+
+// discardedCount returns the number of errors the handler never received
+func (d *errorDispatcher) discardedCount() int64 {
+	if d == nil {
+		return 0
+	}
+	return d.discarded.Load()
+}
+
+// stop lets the dispatcher deliver the errors already buffered and exit
+func (d *errorDispatcher) stop() {
+	if d == nil {
+		return
+	}
+	d.doneOnce.Do(func() { close(d.done) })
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// failingTransport fails every batch with err
+type failingTransport struct {
+	err error
+}
+
+func (f failingTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	return nil, f.err
+}
+
+// newErrorHandlerTestLangfuse creates a client whose batches all fail and whose
+// ErrorHandler is handler
+func newErrorHandlerTestLangfuse(t *testing.T, handler func(error), configure func(*config.Config)) *Langfuse {
+	return newTransportTestLangfuse(t, failingTransport{err: errors.New("connection refused")}, func(cfg *config.Config) {
+		cfg.ErrorHandler = handler
+		cfg.RetryWaitTime = time.Millisecond
+		if configure != nil {
+			configure(cfg)
+		}
+	})
+}
+
+// receiveErrors waits for n errors sent on errs
+func receiveErrors(t *testing.T, errs <-chan error, n int) []error {
+	t.Helper()
+	received := make([]error, 0, n)
+	for len(received) < n {
+		select {
+		case err := <-errs:
+			received = append(received, err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of %d errors: %v", len(received), n, received)
+		}
+	}
+	return received
+}
+
+func TestErrorHandler_FlushFailureAndDeadLetter(t *testing.T) {
+	errs := make(chan error, 10)
+	lf := newErrorHandlerTestLangfuse(t, func(err error) { errs <- err }, func(cfg *config.Config) {
+		cfg.BatchRetries = 1
+	})
+	ctx := context.Background()
+
+	require.NoError(t, lf.Trace("a").Submit(ctx))
+	require.NoError(t, lf.Trace("b").Submit(ctx))
+	require.NoError(t, lf.Flush(ctx))
+
+	received := receiveErrors(t, errs, 3)
+	for i, err := range received[:2] {
+		var flushErr *FlushError
+		require.ErrorAs(t, err, &flushErr)
+		assert.Equal(t, FlushError{BatchSize: 2, Attempt: i, Err: flushErr.Err}, *flushErr)
+		assert.EqualError(t, flushErr.Err, "connection refused")
+	}
+
+	var deadLetter *DeadLetterError
+	require.ErrorAs(t, received[2], &deadLetter)
+	assert.Equal(t, 2, deadLetter.BatchSize)
+	assert.EqualError(t, deadLetter.Err, "connection refused")
+	assert.Equal(t, "batch of 2 events dropped after its last attempt: connection refused", deadLetter.Error())
+
+	select {
+	case err := <-errs:
+		t.Fatalf("events lost with their batch were also reported: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestErrorHandler_DroppedEvent(t *testing.T) {
+	errs := make(chan error, 10)
+	lf := newErrorHandlerTestLangfuse(t, func(err error) { errs <- err }, func(cfg *config.Config) {
+		cfg.QueueSize = 1
+		cfg.FlushAt = 100
+		cfg.FlushInterval = time.Hour
+	})
+	ctx := context.Background()
+
+	first := lf.Trace("first")
+	require.NoError(t, first.Submit(ctx))
+	require.NoError(t, lf.Trace("second").Submit(ctx))
+
+	var dropped *DroppedEventError
+	require.ErrorAs(t, receiveErrors(t, errs, 1)[0], &dropped)
+	assert.ErrorIs(t, dropped, ErrQueueFull)
+	assert.Equal(t, ingestiontypes.EventTypeTraceCreate, dropped.EventType)
+	assert.Equal(t, "queue_full", dropped.Reason)
+	assert.Contains(t, dropped.Error(), "dropped: queue_full")
+}
+
+func TestErrorHandler_SlowHandlerDoesNotStallQueue(t *testing.T) {
+	release := make(chan struct{})
+	lf := newErrorHandlerTestLangfuse(t, func(err error) { <-release }, func(cfg *config.Config) {
+		cfg.QueueSize = 1
+		cfg.FlushAt = 100
+		cfg.FlushInterval = time.Hour
+	})
+	defer close(release)
+	ctx := context.Background()
+
+	const traces = errorHandlerBufferSize + 50
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < traces; i++ {
+			assert.NoError(t, lf.Trace("chat").Submit(ctx))
+		}
+		assert.NoError(t, lf.Flush(ctx))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a blocked error handler stalled the queue")
+	}
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(1), stats.EventsFailed, "the queued event was still flushed")
+	assert.GreaterOrEqual(t, stats.ErrorsDiscarded, int64(traces-1-errorHandlerBufferSize-1),
+		"errors beyond the buffer are counted, not delivered")
+}
+
+func TestErrorHandler_NotConfigured(t *testing.T) {
+	lf := newErrorHandlerTestLangfuse(t, nil, nil)
+	require.NoError(t, lf.Trace("chat").Submit(context.Background()))
+	require.NoError(t, lf.Flush(context.Background()))
+
+	assert.Nil(t, lf.errorHandler)
+	assert.Zero(t, lf.GetStats().ErrorsDiscarded)
+
+	_, err := NewConfig(WithCredentials("pk-lf-test", "sk-lf-test"), WithErrorHandler(nil))
+	assert.Error(t, err)
+}
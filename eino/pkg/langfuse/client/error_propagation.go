@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+const (
+	// ErrorTag is added to a trace when one of its spans or generations ends with level ERROR
+	ErrorTag = "error"
+
+	// ErrorCountMetadataKey holds the number of spans and generations of a trace that
+	// ended with level ERROR
+	ErrorCountMetadataKey = "error_count"
+
+	// MaxLevelMetadataKey holds the highest level above DEFAULT that a span or generation
+	// of a trace ended with
+	MaxLevelMetadataKey = "max_level"
+)
+
+// errorPropagation reports whether failed children mark their trace
+func (lf *Langfuse) errorPropagation() bool {
+	return lf != nil && lf.config != nil && lf.config.ErrorPropagation
+}
+
+// levelRank orders observation levels by severity
+func levelRank(level commonTypes.ObservationLevel) int {
+	switch level {
+	case commonTypes.ObservationLevelError:
+		return 3
+	case commonTypes.ObservationLevelWarning:
+		return 2
+	case commonTypes.ObservationLevelDefault:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// HasErrors reports whether a span or generation created from the trace, directly or
+// nested under another span, has ended with level ERROR. It is always false when error
+// propagation is disabled.
+func (tb *TraceBuilder) HasErrors() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.childErrors > 0
+}
+
+// childEnded records the level a span or generation of the trace ended with. An ERROR
+// adds ErrorTag and counts under ErrorCountMetadataKey, and WARNING or ERROR raise
+// MaxLevelMetadataKey. While the trace is open, its own events carry the changes; once it
+// has been submitted, they are sent in a trace-update of their own. The caller may hold
+// the child's lock, never the trace's.
+func (tb *TraceBuilder) childEnded(level commonTypes.ObservationLevel) error {
+	if tb == nil || !tb.client.errorPropagation() || levelRank(level) <= levelRank(commonTypes.ObservationLevelDefault) {
+		return nil
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.metadata == nil {
+		tb.metadata = make(map[string]interface{})
+	}
+	changed := false
+	if levelRank(level) > levelRank(tb.childLevel) {
+		tb.childLevel = level
+		tb.metadata[MaxLevelMetadataKey] = string(level)
+		changed = true
+	}
+	if level == commonTypes.ObservationLevelError {
+		tb.childErrors++
+		tb.metadata[ErrorCountMetadataKey] = tb.childErrors
+		if !slices.Contains(tb.tags, ErrorTag) {
+			tb.tags = append(tb.tags, ErrorTag)
+		}
+		changed = true
+	}
+	if !changed || !tb.submitted {
+		return nil
+	}
+	return tb.sendChildErrors()
+}
+
+// sendChildErrors enqueues a trace-update carrying only the error tag and metadata, for
+// children that end after the trace. The caller must hold tb.mu.
+func (tb *TraceBuilder) sendChildErrors() error {
+	metadata := map[string]interface{}{MaxLevelMetadataKey: string(tb.childLevel)}
+	if tb.childErrors > 0 {
+		metadata[ErrorCountMetadataKey] = tb.childErrors
+	}
+	updateEvent := &types.TraceUpdateEvent{
+		TraceEvent: types.TraceEvent{
+			ID:          tb.id,
+			Name:        tb.name,
+			Metadata:    metadata,
+			Tags:        tb.client.withDefaultTags(tb.tags),
+			Timestamp:   tb.timestamp,
+			Environment: tb.environment,
+		},
+		Type: "trace-update",
+	}
+
+	ingestionEvent, err := types.NewTraceUpdateIngestionEvent(updateEvent)
+	if err != nil {
+		return fmt.Errorf("invalid trace %s: %w", tb.id, err)
+	}
+	ingestionEvent.Source = types.EventSourceTrace
+	ingestionEvent.Project = tb.environment
+	if err := tb.enqueue(ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue trace %s: %w", tb.id, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// eventsOf flushes the client and returns the events recorded for id, in order
+func eventsOf(t *testing.T, lf *Langfuse, recorder *ingestionRecorder, id string) []map[string]interface{} {
+	require.NoError(t, lf.Flush(context.Background()))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	var events []map[string]interface{}
+	for _, event := range recorder.events {
+		if event["body"].(map[string]interface{})["id"] == id {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestErrorPropagation(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	trace := lf.Trace("agent").WithTags("chat")
+	span := trace.Span("plan")
+	require.NoError(t, span.ChildToolCall("search").WithErrorOutput(errors.New("timeout")).End(ctx))
+	assert.True(t, trace.HasErrors(), "nested children count")
+	require.NoError(t, trace.Generation("answer").Warning().End(ctx))
+	require.NoError(t, trace.Generation("retry").Error().End(ctx))
+	require.NoError(t, span.End(ctx))
+	require.NoError(t, trace.End(ctx))
+
+	events := eventsOf(t, lf, recorder, trace.GetID())
+	require.Len(t, events, 1)
+	body := events[0]["body"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"chat", ErrorTag}, body["tags"])
+	metadata := body["metadata"].(map[string]interface{})
+	assert.EqualValues(t, 2, metadata[ErrorCountMetadataKey])
+	assert.Equal(t, "ERROR", metadata[MaxLevelMetadataKey])
+
+	t.Run("warnings only", func(t *testing.T) {
+		trace := lf.Trace("agent")
+		require.NoError(t, trace.Span("lookup").End(ctx))
+		require.NoError(t, trace.Span("fallback").Warning().End(ctx))
+		require.NoError(t, trace.End(ctx))
+		assert.False(t, trace.HasErrors())
+
+		body := eventsOf(t, lf, recorder, trace.GetID())[0]["body"].(map[string]interface{})
+		assert.Nil(t, body["tags"])
+		assert.Equal(t, map[string]interface{}{MaxLevelMetadataKey: "WARNING"}, body["metadata"])
+	})
+
+	t.Run("no failed children", func(t *testing.T) {
+		trace := lf.Trace("agent")
+		require.NoError(t, trace.Span("lookup").End(ctx))
+		require.NoError(t, trace.End(ctx))
+
+		body := eventsOf(t, lf, recorder, trace.GetID())[0]["body"].(map[string]interface{})
+		assert.Nil(t, body["metadata"])
+	})
+}
+
+func TestErrorPropagation_ChildEndsAfterTrace(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	trace := lf.Trace("agent").WithTags("chat").WithOutput("partial answer")
+	late := trace.Generation("summarize")
+	require.NoError(t, trace.End(ctx))
+	require.NoError(t, late.Error().End(ctx))
+	assert.True(t, trace.HasErrors())
+
+	events := eventsOf(t, lf, recorder, trace.GetID())
+	require.Len(t, events, 2, "the late failure is sent in a trace-update")
+	first := events[0]["body"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"chat"}, first["tags"])
+
+	update := events[1]
+	assert.Equal(t, "trace-update", update["type"])
+	body := update["body"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"chat", ErrorTag}, body["tags"])
+	assert.Equal(t, map[string]interface{}{ErrorCountMetadataKey: float64(1), MaxLevelMetadataKey: "ERROR"}, body["metadata"])
+	assert.Nil(t, body["output"], "only the error fields are sent")
+
+	t.Run("concurrent ends", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			trace := lf.Trace("agent")
+			span := trace.Span("tool")
+			done := make(chan error)
+			go func() { done <- span.Error().End(ctx) }()
+			require.NoError(t, trace.End(ctx))
+			require.NoError(t, <-done)
+
+			var tagged bool
+			for _, event := range eventsOf(t, lf, recorder, trace.GetID()) {
+				tags, _ := event["body"].(map[string]interface{})["tags"].([]interface{})
+				tagged = tagged || assert.ObjectsAreEqual([]interface{}{ErrorTag}, tags)
+			}
+			assert.True(t, tagged, "the failure is reported whichever ends first")
+		}
+	})
+}
+
+func TestErrorPropagation_Disabled(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		cfg.ErrorPropagation = false
+	})
+	ctx := context.Background()
+
+	trace := lf.Trace("agent")
+	late := trace.Span("late")
+	require.NoError(t, trace.Span("tool").Error().End(ctx))
+	require.NoError(t, trace.End(ctx))
+	require.NoError(t, late.Error().End(ctx))
+	assert.False(t, trace.HasErrors())
+
+	events := eventsOf(t, lf, recorder, trace.GetID())
+	require.Len(t, events, 1)
+	body := events[0]["body"].(map[string]interface{})
+	assert.Nil(t, body["tags"])
+	assert.Nil(t, body["metadata"])
+
+	cfg, err := NewConfig(WithCredentials("pk-lf-test", "sk-lf-test"))
+	require.NoError(t, err)
+	assert.True(t, cfg.ErrorPropagation, "propagation is on by default")
+	cfg, err = NewConfig(WithCredentials("pk-lf-test", "sk-lf-test"), WithErrorPropagation(false))
+	require.NoError(t, err)
+	assert.False(t, cfg.ErrorPropagation)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	scoreTypes "eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// FeedbackScoreName is the score name of user feedback submitted without one
+const FeedbackScoreName = "user-feedback"
+
+// Ratings of thumbs up/down feedback, recorded as BOOLEAN scores
+const (
+	FeedbackPositive = "positive"
+	FeedbackNegative = "negative"
+)
+
+// UserFeedbackRequest is an end user's rating of a trace, such as a thumbs up or a star
+// rating
+type UserFeedbackRequest struct {
+	TraceID string
+
+	// UserID identifies the rating user. When set, a later rating by the same user
+	// replaces the earlier one instead of adding a score.
+	UserID string
+
+	// Rating is FeedbackPositive, FeedbackNegative or a number such as "4" or "0.8"
+	Rating string
+
+	Comment *string
+
+	// ScoreName is the name of the score (default FeedbackScoreName)
+	ScoreName string
+}
+
+// toCreateScoreRequest converts the feedback to a score: BOOLEAN for thumbs up/down and
+// NUMERIC for a numeric rating
+func (r *UserFeedbackRequest) toCreateScoreRequest() (*scoreTypes.CreateScoreRequest, error) {
+	if r.TraceID == "" {
+		return nil, &ValidationError{Field: "traceId", Message: "trace ID cannot be empty"}
+	}
+
+	req := &scoreTypes.CreateScoreRequest{
+		TraceID: r.TraceID,
+		Name:    r.ScoreName,
+		Comment: r.Comment,
+	}
+	if req.Name == "" {
+		req.Name = FeedbackScoreName
+	}
+
+	switch r.Rating {
+	case FeedbackPositive, FeedbackNegative:
+		req.Value = r.Rating == FeedbackPositive
+		req.DataType = types.ScoreDataTypeBoolean
+	default:
+		value, err := strconv.ParseFloat(r.Rating, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, &ValidationError{Field: "rating", Message: fmt.Sprintf("rating must be %q, %q or a number, got %q", FeedbackPositive, FeedbackNegative, r.Rating)}
+		}
+		req.Value = value
+		req.DataType = types.ScoreDataTypeNumeric
+	}
+
+	if r.UserID != "" {
+		id := fmt.Sprintf("feedback-%016x", stableHash(r.TraceID, req.Name, r.UserID))
+		req.ID = &id
+	}
+	return req, nil
+}
+
+// SubmitFeedback records an end user's rating of a trace as a score, right away like
+// Score. Thumbs up/down become BOOLEAN scores and numeric ratings NUMERIC ones.
+//
+// Example:
+//
+//	err := langfuse.SubmitFeedback(ctx, &UserFeedbackRequest{
+//		TraceID: traceID,
+//		UserID:  userID,
+//		Rating:  FeedbackPositive,
+//	})
+//
+// If the client is disabled, this method returns nil without error.
+func (lf *Langfuse) SubmitFeedback(ctx context.Context, req *UserFeedbackRequest) error {
+	if req == nil {
+		return fmt.Errorf("feedback request cannot be nil")
+	}
+	scoreReq, err := req.toCreateScoreRequest()
+	if err != nil {
+		return fmt.Errorf("feedback validation failed: %w", err)
+	}
+	if lf.isDisabled() {
+		return nil
+	}
+
+	if _, err := lf.apiClient.Scores.Create(ctx, scoreReq); err != nil {
+		return fmt.Errorf("failed to submit feedback: %w", err)
+	}
+	lf.root().stats.touch()
+	return nil
+}
+
+// FeedbackCollector submits the feedback buttons of one trace, such as the thumbs
+// up/down shown under a chat answer
+type FeedbackCollector struct {
+	client    *Langfuse
+	traceID   string
+	scoreName string
+	userID    string
+}
+
+// NewFeedbackCollector returns a collector for feedback on traceID, recorded under
+// scoreName (default FeedbackScoreName).
+//
+// Example:
+//
+//	feedback := langfuse.NewFeedbackCollector(traceID, "answer-helpful").ForUser(userID)
+//	// when the user clicks thumbs up
+//	err := feedback.Positive()
+func (lf *Langfuse) NewFeedbackCollector(traceID, scoreName string) *FeedbackCollector {
+	return &FeedbackCollector{client: lf, traceID: traceID, scoreName: scoreName}
+}
+
+// ForUser returns a collector for the feedback of userID, whose later clicks replace
+// their earlier rating
+func (c *FeedbackCollector) ForUser(userID string) *FeedbackCollector {
+	user := *c
+	user.userID = userID
+	return &user
+}
+
+// Positive records a thumbs up
+func (c *FeedbackCollector) Positive() error {
+	return c.submit(FeedbackPositive)
+}
+
+// Negative records a thumbs down
+func (c *FeedbackCollector) Negative() error {
+	return c.submit(FeedbackNegative)
+}
+
+// Rate records a numeric rating, such as a number of stars
+func (c *FeedbackCollector) Rate(value float64) error {
+	return c.submit(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// submit sends the rating, bounded by the client's request timeout like Score
+func (c *FeedbackCollector) submit(rating string) error {
+	ctx := context.Background()
+	if !c.client.isDisabled() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.client.config.RequestTimeout)
+		defer cancel()
+	}
+	return c.client.SubmitFeedback(ctx, &UserFeedbackRequest{
+		TraceID:   c.traceID,
+		UserID:    c.userID,
+		Rating:    rating,
+		ScoreName: c.scoreName,
+	})
+}
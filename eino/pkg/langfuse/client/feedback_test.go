@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeedbackTestLangfuse returns a client whose created scores are returned by scores
+func newFeedbackTestLangfuse(t *testing.T) (*Langfuse, func() []map[string]interface{}) {
+	var mu sync.Mutex
+	var created []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/scores", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		created = append(created, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"score-1"}`))
+	})
+
+	return newTestLangfuse(t, mux), func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return created
+	}
+}
+
+func TestLangfuse_SubmitFeedback(t *testing.T) {
+	lf, scores := newFeedbackTestLangfuse(t)
+	ctx := context.Background()
+	comment := "wrong order number"
+
+	require.NoError(t, lf.SubmitFeedback(ctx, &UserFeedbackRequest{TraceID: "trace-1", Rating: FeedbackPositive}))
+	require.NoError(t, lf.SubmitFeedback(ctx, &UserFeedbackRequest{
+		TraceID: "trace-1", Rating: FeedbackNegative, Comment: &comment, ScoreName: "thumbs",
+	}))
+	require.NoError(t, lf.SubmitFeedback(ctx, &UserFeedbackRequest{TraceID: "trace-1", Rating: "4.5", ScoreName: "stars"}))
+
+	created := scores()
+	require.Len(t, created, 3)
+	assert.Equal(t, map[string]interface{}{
+		"traceId": "trace-1", "name": FeedbackScoreName, "value": true, "dataType": "BOOLEAN",
+	}, created[0])
+	assert.Equal(t, map[string]interface{}{
+		"traceId": "trace-1", "name": "thumbs", "value": false, "dataType": "BOOLEAN", "comment": comment,
+	}, created[1])
+	assert.Equal(t, map[string]interface{}{
+		"traceId": "trace-1", "name": "stars", "value": 4.5, "dataType": "NUMERIC",
+	}, created[2])
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, req := range []*UserFeedbackRequest{
+			nil,
+			{Rating: FeedbackPositive},
+			{TraceID: "trace-1", Rating: "great"},
+			{TraceID: "trace-1", Rating: "NaN"},
+			{TraceID: "trace-1"},
+		} {
+			assert.Error(t, lf.SubmitFeedback(ctx, req), "%+v", req)
+		}
+		assert.Len(t, scores(), 3, "invalid feedback is not sent")
+	})
+}
+
+func TestFeedbackCollector(t *testing.T) {
+	lf, scores := newFeedbackTestLangfuse(t)
+
+	feedback := lf.NewFeedbackCollector("trace-1", "helpful")
+	alice, bob := feedback.ForUser("alice"), feedback.ForUser("bob")
+	require.NoError(t, feedback.Positive())
+	require.NoError(t, alice.Positive())
+	require.NoError(t, alice.Negative())
+	require.NoError(t, bob.Rate(3))
+	assert.Error(t, bob.Rate(math.Inf(1)))
+
+	created := scores()
+	require.Len(t, created, 4)
+	assert.Nil(t, created[0]["id"], "anonymous feedback always adds a score")
+	assert.Equal(t, true, created[1]["value"])
+	assert.Equal(t, false, created[2]["value"])
+	assert.Equal(t, created[1]["id"], created[2]["id"], "a user's new rating replaces the earlier one")
+	assert.NotEqual(t, created[1]["id"], created[3]["id"])
+	assert.Equal(t, map[string]interface{}{
+		"id": created[3]["id"], "traceId": "trace-1", "name": "helpful", "value": 3.0, "dataType": "NUMERIC",
+	}, created[3])
+
+	other := lf.NewFeedbackCollector("trace-2", "helpful").ForUser("alice")
+	require.NoError(t, other.Positive())
+	assert.NotEqual(t, created[1]["id"], scores()[4]["id"], "IDs differ per trace")
+}
+
+func TestFeedback_DisabledClient(t *testing.T) {
+	lf, err := NewWithOptions(WithCredentials("pk-lf-test", "sk-lf-test"), WithEnabled(false))
+	require.NoError(t, err)
+
+	assert.NoError(t, lf.NewFeedbackCollector("trace-1", "").Positive())
+	assert.NoError(t, lf.SubmitFeedback(context.Background(), &UserFeedbackRequest{TraceID: "trace-1", Rating: "5"}))
+	assert.Error(t, lf.SubmitFeedback(context.Background(), &UserFeedbackRequest{TraceID: "trace-1", Rating: "great"}),
+		"invalid feedback is reported even when disabled")
+}
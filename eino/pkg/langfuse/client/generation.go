@@ -2,15 +2,20 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"eino/pkg/langfuse/api/resources/commons/types"
 	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	prompttypes "eino/pkg/langfuse/api/resources/prompts/types"
 	"eino/pkg/langfuse/internal/utils"
 )
 
 // GenerationBuilder provides a fluent API for building LLM generation observations
 type GenerationBuilder struct {
+	mu                   sync.Mutex // guards every field, so the generation can be shared across goroutines
 	id                   string
 	traceID              string
 	parentObservationID  *string
@@ -23,17 +28,28 @@ type GenerationBuilder struct {
 	input                interface{}
 	output               interface{}
 	usage                *types.Usage
+	estimatedUsage       *types.Usage // usage set by WithEstimatedTokens, replaced by reported usage
 	metadata             map[string]interface{}
 	level                types.ObservationLevel
 	statusMessage        *string
 	version              *string
+	promptName           *string
+	promptVersion        *int
+	paramsErr            *ValidationError
 	client               *Langfuse
 	submitted            bool
+	err                  error
+	snapshot             deltaSnapshot
+	payloadMode          PayloadMode
+	environment          string
+	begun                bool
+	trace                *TraceBuilder // trace the generation was created under, notified when it ends
+	noop                 bool // rate-limited: every operation succeeds without sending anything
 }
 
 // NewGenerationBuilder creates a new GenerationBuilder instance
 func NewGenerationBuilder(client *Langfuse, traceID string) *GenerationBuilder {
-	return &GenerationBuilder{
+	gb := &GenerationBuilder{
 		id:              utils.GenerateObservationID(),
 		traceID:         traceID,
 		startTime:       time.Now().UTC(),
@@ -42,20 +58,30 @@ func NewGenerationBuilder(client *Langfuse, traceID string) *GenerationBuilder {
 		metadata:        make(map[string]interface{}),
 		modelParameters: make(map[string]interface{}),
 	}
+
+	client.registerBuilder(gb)
+	return gb
 }
 
 // ID sets the generation ID
 func (gb *GenerationBuilder) ID(id string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("ID")
 		return gb
 	}
+	gb.client.renameGenerationUsage(gb.traceID, gb.id, id)
 	gb.id = id
 	return gb
 }
 
 // ParentObservationID sets the parent observation ID
 func (gb *GenerationBuilder) ParentObservationID(parentID string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("ParentObservationID")
 		return gb
 	}
 	gb.parentObservationID = &parentID
@@ -64,7 +90,10 @@ func (gb *GenerationBuilder) ParentObservationID(parentID string) *GenerationBui
 
 // Name sets the generation name
 func (gb *GenerationBuilder) Name(name string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Name")
 		return gb
 	}
 	gb.name = name
@@ -73,7 +102,10 @@ func (gb *GenerationBuilder) Name(name string) *GenerationBuilder {
 
 // StartTime sets the start time
 func (gb *GenerationBuilder) StartTime(startTime time.Time) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("StartTime")
 		return gb
 	}
 	gb.startTime = startTime.UTC()
@@ -82,7 +114,10 @@ func (gb *GenerationBuilder) StartTime(startTime time.Time) *GenerationBuilder {
 
 // EndTime sets the end time
 func (gb *GenerationBuilder) EndTime(endTime time.Time) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("EndTime")
 		return gb
 	}
 	endTimeUTC := endTime.UTC()
@@ -92,7 +127,10 @@ func (gb *GenerationBuilder) EndTime(endTime time.Time) *GenerationBuilder {
 
 // CompletionStartTime sets the completion start time for streaming responses
 func (gb *GenerationBuilder) CompletionStartTime(completionStartTime time.Time) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("CompletionStartTime")
 		return gb
 	}
 	completionStartTimeUTC := completionStartTime.UTC()
@@ -102,7 +140,10 @@ func (gb *GenerationBuilder) CompletionStartTime(completionStartTime time.Time)
 
 // Model sets the model name
 func (gb *GenerationBuilder) Model(model string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Model")
 		return gb
 	}
 	gb.model = &model
@@ -111,16 +152,23 @@ func (gb *GenerationBuilder) Model(model string) *GenerationBuilder {
 
 // ModelParameters sets the model parameters
 func (gb *GenerationBuilder) ModelParameters(params map[string]interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("ModelParameters")
 		return gb
 	}
 	gb.modelParameters = params
+	gb.paramsErr = nil
 	return gb
 }
 
 // AddModelParameter adds a single model parameter
 func (gb *GenerationBuilder) AddModelParameter(key string, value interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("AddModelParameter")
 		return gb
 	}
 	if gb.modelParameters == nil {
@@ -157,7 +205,10 @@ func (gb *GenerationBuilder) PresencePenalty(penalty float64) *GenerationBuilder
 
 // Input sets the input data
 func (gb *GenerationBuilder) Input(input interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Input")
 		return gb
 	}
 	gb.input = input
@@ -166,7 +217,10 @@ func (gb *GenerationBuilder) Input(input interface{}) *GenerationBuilder {
 
 // Output sets the output data
 func (gb *GenerationBuilder) Output(output interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Output")
 		return gb
 	}
 	gb.output = output
@@ -175,34 +229,49 @@ func (gb *GenerationBuilder) Output(output interface{}) *GenerationBuilder {
 
 // Usage sets the usage statistics
 func (gb *GenerationBuilder) Usage(usage *types.Usage) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Usage")
 		return gb
 	}
 	gb.usage = usage
+	gb.client.recordGenerationUsage(gb)
 	return gb
 }
 
 // UsageTokens sets usage with token counts
 func (gb *GenerationBuilder) UsageTokens(inputTokens, outputTokens int) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("UsageTokens")
 		return gb
 	}
 	gb.usage = types.NewUsage(inputTokens, outputTokens)
+	gb.client.recordGenerationUsage(gb)
 	return gb
 }
 
 // UsageWithCost sets usage with token counts and cost information
 func (gb *GenerationBuilder) UsageWithCost(inputTokens, outputTokens int, inputCost, outputCost float64) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("UsageWithCost")
 		return gb
 	}
 	gb.usage = types.NewUsageWithCost(inputTokens, outputTokens, inputCost, outputCost)
+	gb.client.recordGenerationUsage(gb)
 	return gb
 }
 
 // Metadata sets the metadata map
 func (gb *GenerationBuilder) Metadata(metadata map[string]interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Metadata")
 		return gb
 	}
 	gb.metadata = metadata
@@ -211,7 +280,10 @@ func (gb *GenerationBuilder) Metadata(metadata map[string]interface{}) *Generati
 
 // AddMetadata adds a single metadata key-value pair
 func (gb *GenerationBuilder) AddMetadata(key string, value interface{}) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("AddMetadata")
 		return gb
 	}
 	if gb.metadata == nil {
@@ -223,7 +295,10 @@ func (gb *GenerationBuilder) AddMetadata(key string, value interface{}) *Generat
 
 // Level sets the observation level
 func (gb *GenerationBuilder) Level(level types.ObservationLevel) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Level")
 		return gb
 	}
 	gb.level = level
@@ -247,47 +322,113 @@ func (gb *GenerationBuilder) Error() *GenerationBuilder {
 
 // StatusMessage sets the status message
 func (gb *GenerationBuilder) StatusMessage(message string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("StatusMessage")
 		return gb
 	}
 	gb.statusMessage = &message
 	return gb
 }
 
+// WithErrorOutput marks the generation as failed: the level is set to ERROR and the
+// status message and output to the message of err. A nil err is ignored.
+func (gb *GenerationBuilder) WithErrorOutput(err error) *GenerationBuilder {
+	if err == nil {
+		return gb
+	}
+	return gb.Error().StatusMessage(err.Error()).Output(errorOutput(err))
+}
+
 // Version sets the version
 func (gb *GenerationBuilder) Version(version string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	if gb.submitted {
+		gb.recordMisuse("Version")
 		return gb
 	}
 	gb.version = &version
 	return gb
 }
 
+// WithPromptReference links the generation to a managed prompt by name and version
+func (gb *GenerationBuilder) WithPromptReference(promptName string, promptVersion int) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithPromptReference")
+		return gb
+	}
+	gb.promptName = &promptName
+	gb.promptVersion = &promptVersion
+	return gb
+}
+
+// WithPromptReferenceFromPrompt links the generation to an already-fetched prompt
+func (gb *GenerationBuilder) WithPromptReferenceFromPrompt(prompt *prompttypes.Prompt) *GenerationBuilder {
+	if prompt == nil {
+		return gb
+	}
+	return gb.WithPromptReference(prompt.Name, prompt.Version)
+}
+
 // GetID returns the generation ID
 func (gb *GenerationBuilder) GetID() string {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	return gb.id
 }
 
 // GetTraceID returns the trace ID
 func (gb *GenerationBuilder) GetTraceID() string {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	return gb.traceID
 }
 
 // GetName returns the generation name
 func (gb *GenerationBuilder) GetName() string {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	return gb.name
 }
 
 // GetModel returns the model name
 func (gb *GenerationBuilder) GetModel() *string {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	return gb.model
 }
 
 // GetUsage returns the usage statistics
 func (gb *GenerationBuilder) GetUsage() *types.Usage {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
 	return gb.usage
 }
 
+// Err returns misuse recorded in strict mode, such as modifying the generation after it was ended
+func (gb *GenerationBuilder) Err() error {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	return gb.err
+}
+
+// recordMisuse records a call to method after submission when strict mode is enabled
+func (gb *GenerationBuilder) recordMisuse(method string) {
+	if !gb.client.strictMode() {
+		return
+	}
+	gb.err = errors.Join(gb.err, fmt.Errorf("%w: %s called on generation %s", ErrModifiedAfterEnd, method, gb.id))
+}
+
+// alreadyEnded returns the strict mode error for ending the generation more than once
+func (gb *GenerationBuilder) alreadyEnded() error {
+	return fmt.Errorf("generation %s: %w", gb.id, ErrAlreadyEnded)
+}
+
 // validate performs validation on the generation builder
 func (gb *GenerationBuilder) validate() error {
 	if gb.id == "" {
@@ -305,6 +446,10 @@ func (gb *GenerationBuilder) validate() error {
 	if gb.startTime.IsZero() {
 		return &ValidationError{Field: "startTime", Message: "start time is required"}
 	}
+
+	if gb.paramsErr != nil {
+		return gb.paramsErr
+	}
 	
 	// Validate end time if set
 	if gb.endTime != nil && gb.endTime.Before(gb.startTime) {
@@ -334,6 +479,16 @@ func (gb *GenerationBuilder) validate() error {
 		}
 	}
 	
+	// Validate prompt reference if present
+	if gb.promptName != nil {
+		if *gb.promptName == "" {
+			return &ValidationError{Field: "promptName", Message: "prompt name cannot be empty"}
+		}
+		if gb.promptVersion != nil && *gb.promptVersion < 1 {
+			return &ValidationError{Field: "promptVersion", Message: "prompt version must be positive"}
+		}
+	}
+	
 	return nil
 }
 
@@ -350,13 +505,16 @@ func (gb *GenerationBuilder) toObservationEvent() *ingestiontypes.ObservationEve
 		CompletionStartTime:  gb.completionStartTime,
 		Model:                gb.model,
 		ModelParameters:      gb.modelParameters,
-		Input:                gb.input,
-		Output:               gb.output,
+		Input:                gb.client.serializeInput(gb.input, gb.payloadMode),
+		Output:               gb.client.serializeOutput(gb.output, gb.payloadMode),
 		Usage:                gb.usage,
-		Metadata:             gb.metadata,
+		Metadata:             gb.client.serializeMetadata(gb.metadata),
 		Level:                gb.level,
 		StatusMessage:        gb.statusMessage,
 		Version:              gb.version,
+		PromptName:           gb.promptName,
+		PromptVersion:        gb.promptVersion,
+		Environment:          gb.environment,
 	}
 }
 
@@ -370,15 +528,32 @@ func (gb *GenerationBuilder) toGenerationCreateEvent() *ingestiontypes.Generatio
 
 // toGenerationUpdateEvent converts the builder to a GenerationUpdateEvent
 func (gb *GenerationBuilder) toGenerationUpdateEvent() *ingestiontypes.GenerationUpdateEvent {
-	return &ingestiontypes.GenerationUpdateEvent{
+	event := &ingestiontypes.GenerationUpdateEvent{
 		ObservationEvent: *gb.toObservationEvent(),
 		EventType:        "generation-update",
 	}
+	// The update event is what ends the generation, so this is where the output is normalized
+	event.Output = gb.client.serializeFinalOutput(gb.output, gb.payloadMode)
+	if gb.endTime != nil {
+		event.Metadata = withGenerationTiming(event.Metadata, gb.startTime, gb.completionStartTime, *gb.endTime, gb.usage)
+	}
+	return event
 }
 
 // Submit submits the generation to the ingestion queue
 func (gb *GenerationBuilder) Submit(ctx context.Context) error {
+	if gb.noop {
+		return nil
+	}
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.begun {
+		return &ValidationError{Field: "state", Message: "generation already begun"}
+	}
 	if gb.submitted {
+		if gb.client.strictMode() {
+			return gb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "generation already submitted"}
 	}
 	
@@ -387,19 +562,79 @@ func (gb *GenerationBuilder) Submit(ctx context.Context) error {
 	}
 	
 	event := gb.toGenerationCreateEvent()
-	ingestionEvent := event.ToIngestionEvent()
+	ingestionEvent, err := ingestiontypes.NewGenerationCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid generation %s: %w", gb.id, err)
+	}
+	ingestionEvent.Source = ingestiontypes.EventSourceGeneration
+	ingestionEvent.Project = gb.environment
 	
-	if err := gb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := gb.client.enqueueForTrace(gb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue generation %s: %w", gb.id, err)
 	}
 	
 	gb.submitted = true
+	gb.client.deregisterBuilder(gb)
+	return gb.trace.childEnded(gb.level)
+}
+
+// Begin enqueues the generation-create event right away and keeps the generation open, so it is
+// visible in Langfuse while it runs. End, EndAt or Update then send the remaining
+// fields as a generation-update.
+func (gb *GenerationBuilder) Begin(ctx context.Context) error {
+	if gb.noop {
+		return nil
+	}
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.begun {
+		return &ValidationError{Field: "state", Message: "generation already begun"}
+	}
+	if gb.submitted {
+		if gb.client.strictMode() {
+			return gb.alreadyEnded()
+		}
+		return &ValidationError{Field: "state", Message: "generation already submitted"}
+	}
+	
+	if err := gb.validate(); err != nil {
+		return err
+	}
+	
+	event := gb.toGenerationCreateEvent()
+	ingestionEvent, err := ingestiontypes.NewGenerationCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid generation %s: %w", gb.id, err)
+	}
+	ingestionEvent.Source = ingestiontypes.EventSourceGeneration
+	ingestionEvent.Project = gb.environment
+	if err := gb.client.enqueueForTrace(gb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue generation %s: %w", gb.id, err)
+	}
+	
+	gb.begun = true
+	if gb.client.deltaUpdates() {
+		gb.snapshot = takeDeltaSnapshot(event)
+	}
 	return nil
 }
 
 // Update updates an existing generation
 func (gb *GenerationBuilder) Update(ctx context.Context) error {
+	if gb.noop {
+		return nil
+	}
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	return gb.update(ctx)
+}
+
+// update sends the generation-update event; the caller holds gb.mu
+func (gb *GenerationBuilder) update(ctx context.Context) error {
 	if gb.submitted {
+		if gb.client.strictMode() {
+			return gb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "generation already submitted"}
 	}
 	
@@ -408,14 +643,21 @@ func (gb *GenerationBuilder) Update(ctx context.Context) error {
 	}
 	
 	event := gb.toGenerationUpdateEvent()
-	ingestionEvent := event.ToIngestionEvent()
+	ingestionEvent, err := ingestiontypes.NewGenerationUpdateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid generation %s: %w", gb.id, err)
+	}
+	ingestionEvent.Body = gb.snapshot.diff(ingestionEvent.Body)
+	ingestionEvent.Source = ingestiontypes.EventSourceGeneration
+	ingestionEvent.Project = gb.environment
 	
-	if err := gb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := gb.client.enqueueForTrace(gb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue generation %s: %w", gb.id, err)
 	}
 	
 	gb.submitted = true
-	return nil
+	gb.client.deregisterBuilder(gb)
+	return gb.trace.childEnded(gb.level)
 }
 
 // End ends the generation with the current timestamp and submits it
@@ -423,10 +665,31 @@ func (gb *GenerationBuilder) End(ctx context.Context) error {
 	return gb.EndAt(ctx, time.Now().UTC())
 }
 
-// EndAt ends the generation with a specific timestamp and submits it
+// EndAt ends the generation with a specific timestamp and submits it. The metadata sent
+// with the end records the generation's latency under LatencyMetadataKey and, when the
+// output token count is known, its throughput under TokensPerSecondMetadataKey.
 func (gb *GenerationBuilder) EndAt(ctx context.Context, endTime time.Time) error {
-	gb.EndTime(endTime)
-	return gb.Update(ctx)
+	if gb.noop {
+		return nil
+	}
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+
+	// Checking and ending under one lock makes End exactly-once for concurrent callers
+	if gb.submitted {
+		if gb.client.strictMode() {
+			return gb.alreadyEnded()
+		}
+	} else {
+		endTimeUTC := endTime.UTC()
+		fixes, err := gb.client.normalizeTimes("startTime", &gb.startTime, &endTimeUTC)
+		if err != nil {
+			return err
+		}
+		gb.endTime = &endTimeUTC
+		gb.metadata = withTimeAnomalies(gb.metadata, fixes)
+	}
+	return gb.update(ctx)
 }
 
 // Stream starts streaming mode by setting completion start time
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"eino/pkg/langfuse/api/resources/commons/types"
+	prompttypes "eino/pkg/langfuse/api/resources/prompts/types"
 	"eino/pkg/langfuse/internal/queue"
 )
 
@@ -461,6 +463,46 @@ func TestGenerationBuilder_EventConversion(t *testing.T) {
 	assert.Equal(t, generation.id, updateEvent.ObservationEvent.ID)
 }
 
+func TestGenerationBuilder_PromptReference(t *testing.T) {
+	client := createTestClient(t)
+	
+	generation := NewGenerationBuilder(client, "trace-id").
+		Name("test-generation").
+		WithPromptReference("summarize", 3)
+	
+	obsEvent := generation.toObservationEvent()
+	require.NotNil(t, obsEvent.PromptName)
+	require.NotNil(t, obsEvent.PromptVersion)
+	assert.Equal(t, "summarize", *obsEvent.PromptName)
+	assert.Equal(t, 3, *obsEvent.PromptVersion)
+	
+	data, err := json.Marshal(generation.toGenerationCreateEvent())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"promptName":"summarize"`)
+	assert.Contains(t, string(data), `"promptVersion":3`)
+	
+	// From an already-fetched prompt
+	prompt := &prompttypes.Prompt{Name: "classify", Version: 7}
+	generation = NewGenerationBuilder(client, "trace-id").
+		Name("test-generation").
+		WithPromptReferenceFromPrompt(prompt)
+	assert.Equal(t, "classify", *generation.promptName)
+	assert.Equal(t, 7, *generation.promptVersion)
+	
+	// A nil prompt leaves the generation unlinked
+	generation = NewGenerationBuilder(client, "trace-id").WithPromptReferenceFromPrompt(nil)
+	assert.Nil(t, generation.promptName)
+	assert.Nil(t, generation.promptVersion)
+	
+	// Invalid versions are rejected
+	generation = NewGenerationBuilder(client, "trace-id").
+		Name("test-generation").
+		WithPromptReference("summarize", 0)
+	err = generation.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "promptVersion")
+}
+
 func TestGenerationBuilder_ConcurrentAccess(t *testing.T) {
 	client := createTestClient(t)
 	generation := NewGenerationBuilder(client, "trace-id")
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"math"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// Generation metadata keys computed when a generation ends
+const (
+	// LatencyMetadataKey holds the milliseconds between the start and end of a generation
+	LatencyMetadataKey = "latencyMs"
+
+	// TokensPerSecondMetadataKey holds the output tokens per second of a generation
+	TokensPerSecondMetadataKey = "tokensPerSecond"
+)
+
+// withGenerationTiming returns metadata with the latency of a generation ending at
+// endTime and, when its output token count is known, its throughput, leaving the
+// original map untouched.
+//
+// For a streamed generation the throughput is measured from the completion start,
+// since the time to the first token is not spent producing output. A generation that
+// took no time has no throughput.
+func withGenerationTiming(metadata map[string]interface{}, startTime time.Time, completionStartTime *time.Time, endTime time.Time, usage *types.Usage) map[string]interface{} {
+	stamped := make(map[string]interface{}, len(metadata)+2)
+	for k, v := range metadata {
+		stamped[k] = v
+	}
+	stamped[LatencyMetadataKey] = endTime.Sub(startTime).Milliseconds()
+
+	if usage == nil || usage.Output == nil {
+		return stamped
+	}
+
+	outputStart := startTime
+	if completionStartTime != nil && completionStartTime.Before(endTime) {
+		outputStart = *completionStartTime
+	}
+	if seconds := endTime.Sub(outputStart).Seconds(); seconds > 0 {
+		stamped[TokensPerSecondMetadataKey] = math.Round(float64(*usage.Output)/seconds*100) / 100
+	}
+	return stamped
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+func TestGenerationBuilder_EndComputesTiming(t *testing.T) {
+	start := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		build           func(gb *GenerationBuilder) *GenerationBuilder
+		end             time.Time
+		latencyMs       float64
+		tokensPerSecond interface{}
+	}{
+		{
+			name:            "known output tokens",
+			build:           func(gb *GenerationBuilder) *GenerationBuilder { return gb.UsageTokens(10, 200) },
+			end:             start.Add(2 * time.Second),
+			latencyMs:       2000,
+			tokensPerSecond: 100.0,
+		},
+		{
+			name: "streamed generation measures throughput from the first token",
+			build: func(gb *GenerationBuilder) *GenerationBuilder {
+				return gb.UsageTokens(10, 100).StreamAt(start.Add(500 * time.Millisecond))
+			},
+			end:             start.Add(2500 * time.Millisecond),
+			latencyMs:       2500,
+			tokensPerSecond: 50.0,
+		},
+		{
+			name:            "fractional throughput is rounded",
+			build:           func(gb *GenerationBuilder) *GenerationBuilder { return gb.UsageTokens(0, 10) },
+			end:             start.Add(3 * time.Second),
+			latencyMs:       3000,
+			tokensPerSecond: 3.33,
+		},
+		{
+			name:      "unknown output tokens",
+			build:     func(gb *GenerationBuilder) *GenerationBuilder { return gb },
+			end:       start.Add(1500 * time.Millisecond),
+			latencyMs: 1500,
+		},
+		{
+			name:      "zero duration",
+			build:     func(gb *GenerationBuilder) *GenerationBuilder { return gb.UsageTokens(10, 20) },
+			end:       start,
+			latencyMs: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf, recorder := newPayloadTestLangfuse(t)
+
+			generation := tt.build(lf.Trace("chat").Generation("llm").StartTime(start).AddMetadata("tenant", "acme"))
+			require.NoError(t, generation.EndAt(context.Background(), tt.end))
+
+			body := flushedBodies(t, lf, recorder)["generation-update"]
+			require.NotNil(t, body)
+			metadata := body["metadata"].(map[string]interface{})
+			assert.Equal(t, "acme", metadata["tenant"])
+			assert.Equal(t, tt.latencyMs, metadata[LatencyMetadataKey])
+			if tt.tokensPerSecond == nil {
+				assert.NotContains(t, metadata, TokensPerSecondMetadataKey)
+			} else {
+				assert.Equal(t, tt.tokensPerSecond, metadata[TokensPerSecondMetadataKey])
+			}
+
+			// The builder's own metadata is left untouched
+			assert.NotContains(t, generation.metadata, LatencyMetadataKey)
+		})
+	}
+}
+
+func TestWithGenerationTiming_CompletionStartAfterEnd(t *testing.T) {
+	start := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	completionStart := start.Add(3 * time.Second)
+
+	stamped := withGenerationTiming(nil, start, &completionStart, start.Add(2*time.Second), types.NewUsage(0, 10))
+	assert.Equal(t, int64(2000), stamped[LatencyMetadataKey])
+	assert.Equal(t, 5.0, stamped[TokensPerSecondMetadataKey])
+}
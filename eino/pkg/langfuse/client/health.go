@@ -0,0 +1,380 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	commonErrors "eino/pkg/langfuse/api/resources/commons/errors"
+	healthTypes "eino/pkg/langfuse/api/resources/health/types"
+	"eino/pkg/langfuse/config"
+)
+
+// HealthStatus is the status of the client or one of its components
+type HealthStatus = healthTypes.HealthStatus
+
+// Health statuses reported by HealthCheckDetailed
+const (
+	HealthStatusHealthy   = healthTypes.HealthStatusHealthy
+	HealthStatusDegraded  = healthTypes.HealthStatusDegraded
+	HealthStatusUnhealthy = healthTypes.HealthStatusUnhealthy
+)
+
+// Health report component names
+const (
+	HealthComponentAPI         = "api"
+	HealthComponentIngestion   = "ingestion"
+	HealthComponentQueue       = "queue"
+	HealthComponentCredentials = "credentials"
+)
+
+// queueDegradedFill is the fraction of QueueSize at which the queue is reported as degraded
+const queueDegradedFill = 0.8
+
+// HealthReport is the result of HealthCheckDetailed
+type HealthReport struct {
+	// Overall is the worst status of all components
+	Overall HealthStatus `json:"overall"`
+
+	// Components holds the status of each checked component, keyed by component name
+	Components map[string]ComponentHealth `json:"components"`
+
+	// Latency is the total time taken by the check
+	Latency time.Duration `json:"latency"`
+
+	// Timestamp is when the check started
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ComponentHealth is the status of a single component of a HealthReport
+type ComponentHealth struct {
+	Status       HealthStatus  `json:"status"`
+	Message      string        `json:"message,omitempty"`
+	ResponseTime time.Duration `json:"responseTime"`
+
+	err error
+}
+
+// Err returns an error describing every component that is not healthy, or nil if the
+// report is healthy. Errors returned by a component's check are wrapped.
+func (r *HealthReport) Err() error {
+	if r.Overall == HealthStatusHealthy {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.Components))
+	for name := range r.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		component := r.Components[name]
+		switch {
+		case component.Status == HealthStatusHealthy:
+		case component.err != nil:
+			errs = append(errs, fmt.Errorf("%s %s: %w", name, component.Status, component.err))
+		default:
+			errs = append(errs, fmt.Errorf("%s %s: %s", name, component.Status, component.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthCheckDetailed checks the components the client depends on and reports the status
+// of each of them:
+//   - "api": the Langfuse health endpoint is reachable and reports itself healthy
+//   - "credentials": the configured keys are accepted by an authenticated endpoint
+//   - "ingestion": the most recent batch submission succeeded, or the custom
+//     IngestionTransport's HealthChecker passes
+//   - "queue": pending events are below 80% of QueueSize and have waited less than
+//     twice the FlushInterval
+//
+// When a custom IngestionTransport is configured the REST API may not be reachable, so
+// the "api" and "credentials" components are not checked. The returned error is only
+// non-nil if the check could not run at all; use HealthReport.Err for failed components.
+func (lf *Langfuse) HealthCheckDetailed(ctx context.Context) (*HealthReport, error) {
+	if lf.isDisabled() {
+		return nil, fmt.Errorf("client is disabled")
+	}
+
+	report := &HealthReport{
+		Overall:    HealthStatusHealthy,
+		Components: make(map[string]ComponentHealth),
+		Timestamp:  time.Now(),
+	}
+
+	checks := map[string]func(context.Context) ComponentHealth{
+		HealthComponentIngestion: lf.checkIngestionHealth,
+		HealthComponentQueue:     lf.checkQueueHealth,
+	}
+	if lf.config.IngestionTransport == nil {
+		checks[HealthComponentAPI] = lf.checkAPIHealth
+		checks[HealthComponentCredentials] = lf.checkCredentialsHealth
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) ComponentHealth) {
+			defer wg.Done()
+
+			start := time.Now()
+			component := check(ctx)
+			component.ResponseTime = time.Since(start)
+
+			mu.Lock()
+			report.Components[name] = component
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if healthSeverity(component.Status) > healthSeverity(report.Overall) {
+			report.Overall = component.Status
+		}
+	}
+	report.Latency = time.Since(report.Timestamp)
+
+	return report, nil
+}
+
+// checkAPIHealth checks that the health endpoint is reachable and healthy
+func (lf *Langfuse) checkAPIHealth(ctx context.Context) ComponentHealth {
+	response, err := lf.apiClient.CheckHealth(ctx)
+	if err != nil {
+		return ComponentHealth{Status: HealthStatusUnhealthy, Message: err.Error(), err: err}
+	}
+
+	switch response.Status {
+	case HealthStatusHealthy:
+		return ComponentHealth{Status: HealthStatusHealthy}
+	case HealthStatusDegraded:
+		return ComponentHealth{Status: HealthStatusDegraded, Message: "service reported degraded"}
+	default:
+		return ComponentHealth{Status: HealthStatusUnhealthy, Message: fmt.Sprintf("service reported %q", response.Status)}
+	}
+}
+
+// checkCredentialsHealth checks that the configured keys are accepted by listing projects
+func (lf *Langfuse) checkCredentialsHealth(ctx context.Context) ComponentHealth {
+	if _, err := lf.apiClient.Projects.List(ctx, nil); err != nil {
+		err = withRegionHint(err, lf.config.Host)
+		return ComponentHealth{Status: HealthStatusUnhealthy, Message: err.Error(), err: err}
+	}
+	return ComponentHealth{Status: HealthStatusHealthy}
+}
+
+// withRegionHint adds a hint to an unauthorized error from a Langfuse Cloud host: keys
+// only work in the region their project lives in, so valid keys used against the other
+// region's host are rejected
+func withRegionHint(err error, host string) error {
+	var unauthorized *commonErrors.UnauthorizedError
+	if !errors.As(err, &unauthorized) {
+		return err
+	}
+
+	switch config.RegionForHost(host) {
+	case config.RegionEU:
+		return fmt.Errorf("%w (hint: %s is the EU region; if the project was created on %s use WithRegion(%q))",
+			err, host, config.HostUS, config.RegionUS)
+	case config.RegionUS:
+		return fmt.Errorf("%w (hint: %s is the US region; if the project was created on %s use WithRegion(%q))",
+			err, host, config.HostEU, config.RegionEU)
+	default:
+		return err
+	}
+}
+
+// checkIngestionHealth reports the custom transport's health check, or the outcome of the
+// most recent batch submission
+func (lf *Langfuse) checkIngestionHealth(ctx context.Context) ComponentHealth {
+	if lf.config.IngestionTransport != nil {
+		checker, ok := lf.config.IngestionTransport.(HealthChecker)
+		if !ok {
+			return ComponentHealth{Status: HealthStatusHealthy, Message: "transport has no health check"}
+		}
+		if err := checker.HealthCheck(ctx); err != nil {
+			return ComponentHealth{Status: HealthStatusUnhealthy, Message: err.Error(), err: err}
+		}
+		return ComponentHealth{Status: HealthStatusHealthy}
+	}
+
+	if err := lf.root().stats.flushErr(); err != nil {
+		return ComponentHealth{Status: HealthStatusUnhealthy, Message: "last batch submission failed: " + err.Error(), err: err}
+	}
+	return ComponentHealth{Status: HealthStatusHealthy}
+}
+
+// checkQueueHealth reports the queue depth and how long the oldest event has been waiting
+func (lf *Langfuse) checkQueueHealth(ctx context.Context) ComponentHealth {
+	if lf.queue.IsClosed() {
+		return ComponentHealth{Status: HealthStatusUnhealthy, Message: "queue is closed", err: ErrQueueClosed}
+	}
+
+	depth := lf.queue.Size()
+	age := lf.queue.OldestEventAge()
+	message := fmt.Sprintf("%d/%d events pending, oldest %s", depth, lf.config.QueueSize, age.Round(time.Millisecond))
+
+	if float64(depth) >= queueDegradedFill*float64(lf.config.QueueSize) ||
+		(lf.config.FlushInterval > 0 && age > 2*lf.config.FlushInterval) {
+		return ComponentHealth{Status: HealthStatusDegraded, Message: message}
+	}
+	return ComponentHealth{Status: HealthStatusHealthy, Message: message}
+}
+
+// healthSeverity orders statuses from healthy to unhealthy
+func healthSeverity(status HealthStatus) int {
+	switch status {
+	case HealthStatusHealthy:
+		return 0
+	case HealthStatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// SDKHealth is the result of HealthReport: one view of whether the SDK can deliver
+// events, meant to back a service's own readiness endpoint
+type SDKHealth struct {
+	// Status is the worst status reached by any check under the configured
+	// HealthThresholds
+	Status HealthStatus `json:"status"`
+
+	// Reasons explains every check that is not healthy
+	Reasons []string `json:"reasons,omitempty"`
+
+	// APIReachable reports whether the health endpoint answered, and APIStatus the
+	// status it reported. Both are unset when a custom IngestionTransport is configured.
+	APIReachable bool          `json:"apiReachable"`
+	APIStatus    HealthStatus  `json:"apiStatus,omitempty"`
+	APILatency   time.Duration `json:"apiLatency"`
+
+	// CredentialsValid reports whether the keys are accepted by an authenticated endpoint
+	CredentialsValid bool `json:"credentialsValid"`
+
+	QueueDepth    int `json:"queueDepth"`
+	QueueCapacity int `json:"queueCapacity"`
+
+	// ConsecutiveFlushFailures counts the batch submissions that failed since the last
+	// successful one
+	ConsecutiveFlushFailures int64 `json:"consecutiveFlushFailures"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// degrade raises the status to status, recording why
+func (h *SDKHealth) degrade(status HealthStatus, reason string) {
+	if healthSeverity(status) > healthSeverity(h.Status) {
+		h.Status = status
+	}
+	h.Reasons = append(h.Reasons, reason)
+}
+
+// HealthReport combines API health, credential validity, queue depth and flush failures
+// into a single SDK health status, derived with the configured HealthThresholds.
+//
+// Example:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//		health, err := langfuse.HealthReport(r.Context())
+//		if err != nil || health.Status == client.HealthStatusUnhealthy {
+//			w.WriteHeader(http.StatusServiceUnavailable)
+//		}
+//		json.NewEncoder(w).Encode(health)
+//	})
+//
+// When a custom IngestionTransport is configured the REST API may not be reachable, so
+// the API and credentials are not checked.
+func (lf *Langfuse) HealthReport(ctx context.Context) (*SDKHealth, error) {
+	if lf.isDisabled() {
+		return nil, fmt.Errorf("client is disabled")
+	}
+
+	thresholds := lf.config.HealthThresholds
+	health := &SDKHealth{
+		Status:                   HealthStatusHealthy,
+		QueueDepth:               lf.queue.Size(),
+		QueueCapacity:            lf.config.QueueSize,
+		ConsecutiveFlushFailures: lf.root().stats.flushFailures.Load(),
+		Timestamp:                time.Now(),
+	}
+
+	if lf.config.IngestionTransport == nil {
+		var (
+			wg          sync.WaitGroup
+			credentials ComponentHealth
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			credentials = lf.checkCredentialsHealth(ctx)
+		}()
+		lf.checkSDKAPIHealth(ctx, health, thresholds)
+		wg.Wait()
+
+		health.CredentialsValid = credentials.Status == HealthStatusHealthy
+		if !health.CredentialsValid {
+			health.degrade(HealthStatusUnhealthy, "credentials rejected: "+credentials.Message)
+		}
+	}
+
+	if lf.queue.IsClosed() {
+		health.degrade(HealthStatusUnhealthy, "queue is closed")
+	} else if health.QueueCapacity > 0 {
+		fill := float64(health.QueueDepth) / float64(health.QueueCapacity)
+		reason := fmt.Sprintf("queue %d/%d full", health.QueueDepth, health.QueueCapacity)
+		switch {
+		case thresholds.QueueUnhealthyFill > 0 && fill >= thresholds.QueueUnhealthyFill:
+			health.degrade(HealthStatusUnhealthy, reason)
+		case thresholds.QueueDegradedFill > 0 && fill >= thresholds.QueueDegradedFill:
+			health.degrade(HealthStatusDegraded, reason)
+		}
+	}
+
+	failures := health.ConsecutiveFlushFailures
+	reason := fmt.Sprintf("%d consecutive batch submissions failed", failures)
+	switch {
+	case thresholds.FlushFailuresUnhealthy > 0 && failures >= int64(thresholds.FlushFailuresUnhealthy):
+		health.degrade(HealthStatusUnhealthy, reason)
+	case thresholds.FlushFailuresDegraded > 0 && failures >= int64(thresholds.FlushFailuresDegraded):
+		health.degrade(HealthStatusDegraded, reason)
+	}
+
+	return health, nil
+}
+
+// checkSDKAPIHealth calls the health endpoint and records its reachability, status and
+// round trip in health
+func (lf *Langfuse) checkSDKAPIHealth(ctx context.Context, health *SDKHealth, thresholds config.HealthThresholds) {
+	start := time.Now()
+	response, err := lf.apiClient.CheckHealth(ctx)
+	health.APILatency = time.Since(start)
+	if err != nil {
+		health.degrade(HealthStatusUnhealthy, "API unreachable: "+err.Error())
+		return
+	}
+
+	health.APIReachable = true
+	health.APIStatus = response.Status
+	switch response.Status {
+	case HealthStatusHealthy:
+	case HealthStatusDegraded:
+		health.degrade(HealthStatusDegraded, "API reported degraded")
+	default:
+		health.degrade(HealthStatusUnhealthy, fmt.Sprintf("API reported %q", response.Status))
+	}
+
+	if thresholds.APILatencyDegraded > 0 && health.APILatency > thresholds.APILatencyDegraded {
+		health.degrade(HealthStatusDegraded, fmt.Sprintf("API latency %s", health.APILatency.Round(time.Millisecond)))
+	}
+}
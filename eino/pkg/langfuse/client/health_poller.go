@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// healthPoller polls the health endpoint and pauses the queue's batch submission while
+// the API reports itself unhealthy, set up by WithHealthPolling
+type healthPoller struct {
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+}
+
+// startHealthPolling starts polling when HealthPollInterval is set. A custom
+// IngestionTransport does not deliver through the REST API, so its health endpoint says
+// nothing about submission and is not polled.
+func (lf *Langfuse) startHealthPolling() {
+	if lf.config.HealthPollInterval <= 0 || lf.config.IngestionTransport != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lf.healthPoller = &healthPoller{cancel: cancel}
+	t := lf.timeSource().NewTicker(lf.config.HealthPollInterval)
+
+	go func() {
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+			}
+
+			if lf.pollHealth(ctx) {
+				lf.queue.Resume()
+			} else if ctx.Err() == nil {
+				lf.queue.Pause()
+			}
+		}
+	}()
+}
+
+// pollHealth reports whether submission should go on: the API answered and is not
+// unhealthy. A degraded API still accepts events.
+func (lf *Langfuse) pollHealth(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, lf.config.RequestTimeout)
+	defer cancel()
+
+	response, err := lf.apiClient.CheckHealth(ctx)
+	if err != nil {
+		return false
+	}
+	return response.Status == HealthStatusHealthy || response.Status == HealthStatusDegraded
+}
+
+// stop ends polling; a paused queue is still flushed by the shutdown that follows
+func (p *healthPoller) stop() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(p.cancel)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestLangfuse_HealthPolling(t *testing.T) {
+	var status atomic.Value
+	status.Store("healthy")
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"` + status.Load().(string) + `"}`))
+	})
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.HealthPollInterval = 10 * time.Millisecond
+	})
+	ctx := context.Background()
+
+	submitted := func() int {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return len(recorder.events)
+	}
+
+	require.NoError(t, lf.Trace("before-outage").Submit(ctx))
+	require.NoError(t, lf.Flush(ctx))
+	require.Eventually(t, func() bool { return submitted() == 1 }, time.Second, 5*time.Millisecond)
+
+	status.Store("unhealthy")
+	require.Eventually(t, func() bool { return lf.GetStats().SubmissionPaused }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, lf.Trace("during-outage").Submit(ctx))
+	require.NoError(t, lf.Flush(ctx))
+	assert.Equal(t, 1, submitted(), "nothing is submitted while the API is unhealthy")
+	assert.Equal(t, 1, lf.queue.Size(), "events are buffered")
+
+	status.Store("degraded")
+	require.Eventually(t, func() bool { return !lf.GetStats().SubmissionPaused }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return submitted() == 2 }, time.Second, 5*time.Millisecond,
+		"buffered events are flushed once the API recovers")
+}
+
+func TestLangfuse_HealthPolling_UnreachableAPI(t *testing.T) {
+	var down atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/health", func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			hijacked, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			hijacked.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"healthy"}`))
+	})
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.HealthPollInterval = 10 * time.Millisecond
+	})
+
+	down.Store(true)
+	require.Eventually(t, func() bool { return lf.GetStats().SubmissionPaused }, time.Second, 5*time.Millisecond)
+
+	down.Store(false)
+	require.Eventually(t, func() bool { return !lf.GetStats().SubmissionPaused }, time.Second, 5*time.Millisecond)
+}
+
+func TestWithHealthPolling_Validation(t *testing.T) {
+	_, err := NewWithOptions(WithCredentials("pk-lf-test", "sk-lf-test"), WithHealthPolling(0))
+	assert.ErrorContains(t, err, "health poll interval must be positive")
+}
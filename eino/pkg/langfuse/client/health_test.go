@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonErrors "eino/pkg/langfuse/api/resources/commons/errors"
+	"eino/pkg/langfuse/config"
+)
+
+// newHealthMux serves the health and projects endpoints with the given status codes
+func newHealthMux(healthStatus string, projectsCode int) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"` + healthStatus + `","timestamp":"2024-01-01T12:00:00Z"}`))
+	})
+	mux.HandleFunc("/api/public/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(projectsCode)
+		if projectsCode == http.StatusOK {
+			w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		w.Write([]byte(`{"message":"invalid credentials"}`))
+	})
+	return mux
+}
+
+func TestLangfuse_HealthCheckDetailed(t *testing.T) {
+	lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusOK))
+
+	report, err := lf.HealthCheckDetailed(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, HealthStatusHealthy, report.Overall)
+	assert.ElementsMatch(t,
+		[]string{HealthComponentAPI, HealthComponentIngestion, HealthComponentQueue, HealthComponentCredentials},
+		componentNames(report.Components))
+	for name, component := range report.Components {
+		assert.Equal(t, HealthStatusHealthy, component.Status, name)
+	}
+	assert.False(t, report.Timestamp.IsZero())
+	assert.Positive(t, report.Latency)
+	assert.NoError(t, report.Err())
+	assert.NoError(t, lf.HealthCheck(context.Background()))
+}
+
+func TestLangfuse_HealthCheckDetailed_ComponentFailures(t *testing.T) {
+	t.Run("credentials rejected", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusUnauthorized))
+
+		report, err := lf.HealthCheckDetailed(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, HealthStatusUnhealthy, report.Overall)
+		assert.Equal(t, HealthStatusUnhealthy, report.Components[HealthComponentCredentials].Status)
+		assert.Equal(t, HealthStatusHealthy, report.Components[HealthComponentAPI].Status)
+
+		err = lf.HealthCheck(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), HealthComponentCredentials)
+
+		var unauthorized *commonErrors.UnauthorizedError
+		assert.ErrorAs(t, err, &unauthorized)
+		assert.NotContains(t, err.Error(), "hint:", "self-hosted deployments have no region")
+	})
+
+	t.Run("api degraded", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("degraded", http.StatusOK))
+
+		report, err := lf.HealthCheckDetailed(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, HealthStatusDegraded, report.Overall)
+		assert.Equal(t, HealthStatusDegraded, report.Components[HealthComponentAPI].Status)
+		assert.Error(t, lf.HealthCheck(context.Background()))
+	})
+
+	t.Run("queue backlog", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusOK), func(cfg *config.Config) {
+			cfg.QueueSize = 5
+			cfg.FlushAt = 100
+			cfg.FlushInterval = time.Hour
+		})
+		for i := 0; i < 4; i++ {
+			require.NoError(t, lf.Trace("pending").Submit(context.Background()))
+		}
+
+		report, err := lf.HealthCheckDetailed(context.Background())
+		require.NoError(t, err)
+
+		queueHealth := report.Components[HealthComponentQueue]
+		assert.Equal(t, HealthStatusDegraded, queueHealth.Status)
+		assert.Contains(t, queueHealth.Message, "4/5 events pending")
+		assert.Equal(t, HealthStatusDegraded, report.Overall)
+	})
+
+	t.Run("batch submission failed", func(t *testing.T) {
+		mux := newHealthMux("healthy", http.StatusOK)
+		mux.HandleFunc("/api/public/ingestion", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		lf := newTestLangfuse(t, mux)
+
+		require.NoError(t, lf.Trace("lost").Submit(context.Background()))
+		require.NoError(t, lf.Flush(context.Background()))
+
+		require.Eventually(t, func() bool {
+			report, err := lf.HealthCheckDetailed(context.Background())
+			return err == nil && report.Components[HealthComponentIngestion].Status == HealthStatusUnhealthy
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestLangfuse_HealthReport(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusOK))
+
+		health, err := lf.HealthReport(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, HealthStatusHealthy, health.Status)
+		assert.Empty(t, health.Reasons)
+		assert.True(t, health.APIReachable)
+		assert.Equal(t, HealthStatusHealthy, health.APIStatus)
+		assert.Positive(t, health.APILatency)
+		assert.True(t, health.CredentialsValid)
+		assert.Equal(t, 1000, health.QueueCapacity)
+		assert.Zero(t, health.ConsecutiveFlushFailures)
+	})
+
+	t.Run("degraded when the queue is 90% full", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusOK), func(cfg *config.Config) {
+			cfg.QueueSize = 10
+			cfg.FlushAt = 100
+			cfg.FlushInterval = time.Hour
+		})
+		for i := 0; i < 9; i++ {
+			require.NoError(t, lf.Trace("pending").Submit(context.Background()))
+		}
+
+		health, err := lf.HealthReport(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, HealthStatusDegraded, health.Status)
+		assert.Equal(t, 9, health.QueueDepth)
+		assert.Equal(t, []string{"queue 9/10 full"}, health.Reasons)
+	})
+
+	t.Run("unhealthy when credentials are rejected", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusUnauthorized))
+
+		health, err := lf.HealthReport(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, HealthStatusUnhealthy, health.Status)
+		assert.True(t, health.APIReachable)
+		assert.False(t, health.CredentialsValid)
+		require.Len(t, health.Reasons, 1)
+		assert.Contains(t, health.Reasons[0], "credentials rejected")
+	})
+
+	t.Run("consecutive flush failures", func(t *testing.T) {
+		lf := newTestLangfuse(t, newHealthMux("healthy", http.StatusOK), func(cfg *config.Config) {
+			cfg.HealthThresholds.FlushFailuresDegraded = 2
+			cfg.HealthThresholds.FlushFailuresUnhealthy = 3
+		})
+		stats := lf.root().stats
+		failure := errors.New("ingestion unavailable")
+
+		stats.recordSubmission(1, failure)
+		health, err := lf.HealthReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusHealthy, health.Status, "below the configured threshold")
+
+		stats.recordSubmission(1, failure)
+		health, err = lf.HealthReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusDegraded, health.Status)
+
+		stats.recordSubmission(1, failure)
+		health, err = lf.HealthReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusUnhealthy, health.Status)
+		assert.Equal(t, int64(3), health.ConsecutiveFlushFailures)
+
+		stats.recordSubmission(1, nil)
+		health, err = lf.HealthReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusHealthy, health.Status, "a successful submission resets the count")
+	})
+
+	t.Run("invalid thresholds", func(t *testing.T) {
+		_, err := NewWithOptions(WithCredentials("pk-lf-test", "sk-lf-test"),
+			WithHealthThresholds(HealthThresholds{QueueDegradedFill: 1.5}))
+		assert.ErrorContains(t, err, "queue fill must be between 0 and 1")
+	})
+}
+
+func TestHealthCheck_ServiceLatency(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"healthy","services":{
+			"database":{"status":"healthy","latencyMs":12.5,"lastChecked":"2024-01-01T12:00:00Z"},
+			"cache":{"status":"healthy"}}}`))
+	})
+	lf := newTestLangfuse(t, mux)
+
+	before := time.Now()
+	response, err := lf.API().Health.Check(context.Background())
+	require.NoError(t, err)
+
+	database := response.Services["database"]
+	require.NotNil(t, database.LatencyMs)
+	assert.Equal(t, 12.5, *database.LatencyMs)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), database.LastChecked)
+
+	cache := response.Services["cache"]
+	require.NotNil(t, cache.LatencyMs, "the round trip is used when the server reports no latency")
+	assert.Positive(t, *cache.LatencyMs)
+	assert.False(t, cache.LastChecked.Before(before))
+}
+
+func TestWithRegionHint(t *testing.T) {
+	unauthorized := fmt.Errorf("failed to list projects: %w", commonErrors.NewUnauthorizedError("invalid credentials"))
+
+	t.Run("eu host", func(t *testing.T) {
+		err := withRegionHint(unauthorized, config.HostEU)
+		assert.ErrorIs(t, err, unauthorized)
+		assert.Contains(t, err.Error(), "https://cloud.langfuse.com is the EU region")
+		assert.Contains(t, err.Error(), `use WithRegion("us")`)
+	})
+
+	t.Run("us host", func(t *testing.T) {
+		err := withRegionHint(unauthorized, config.HostUS)
+		assert.Contains(t, err.Error(), "https://us.cloud.langfuse.com is the US region")
+		assert.Contains(t, err.Error(), `use WithRegion("eu")`)
+	})
+
+	t.Run("self-hosted", func(t *testing.T) {
+		assert.Equal(t, unauthorized, withRegionHint(unauthorized, "https://langfuse.example.com"))
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		err := errors.New("connection refused")
+		assert.Equal(t, err, withRegionHint(err, config.HostEU))
+	})
+}
+
+func componentNames(components map[string]ComponentHealth) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	return names
+}
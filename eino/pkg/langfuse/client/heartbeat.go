@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// HeartbeatMetadataKey is the trace metadata key updated by WithHeartbeat
+const HeartbeatMetadataKey = "lastHeartbeat"
+
+// clock abstracts the time source used for heartbeats so tests can drive them
+type clock interface {
+	NewTicker(interval time.Duration) ticker
+}
+
+// ticker is the part of time.Ticker used by heartbeats
+type ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) NewTicker(interval time.Duration) ticker {
+	return realTicker{time.NewTicker(interval)}
+}
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// timeSource returns the client's clock, falling back to the system clock
+func (lf *Langfuse) timeSource() clock {
+	if lf == nil || lf.clock == nil {
+		return realClock{}
+	}
+	return lf.clock
+}
+
+// startHeartbeat periodically enqueues a trace-update carrying only the heartbeat
+// metadata, until stopHeartbeat is called or the queue is closed. The fields sent are
+// captured when the heartbeat starts, so the goroutine never needs the builder's lock.
+func (tb *TraceBuilder) startHeartbeat() {
+	// Heartbeats would make a trace of an uncommitted transaction visible
+	if tb.heartbeat <= 0 || tb.stopHeartbeat != nil || tb.client.tx != nil {
+		return
+	}
+
+	lf := tb.client
+	t := lf.timeSource().NewTicker(tb.heartbeat)
+	done := make(chan struct{})
+	id, name, timestamp, environment := tb.id, tb.name, tb.timestamp, tb.environment
+
+	go func() {
+		defer t.Stop()
+		for {
+			var now time.Time
+			select {
+			case <-done:
+				return
+			case now = <-t.C():
+			}
+			lf.touchUsageRollup(id)
+
+			event := &types.TraceUpdateEvent{
+				TraceEvent: types.TraceEvent{
+					ID:        id,
+					Name:      name,
+					Timestamp: timestamp,
+					Metadata:  lf.serializeMetadata(map[string]interface{}{HeartbeatMetadataKey: now.UTC()}),
+				},
+				Type: "trace-update",
+			}
+			ingestionEvent, err := types.NewTraceUpdateIngestionEvent(event)
+			if err != nil {
+				return
+			}
+			ingestionEvent.Source = types.EventSourceTrace
+			ingestionEvent.Project = environment
+			if err := lf.queue.Enqueue(ingestionEvent); errors.Is(err, ErrQueueClosed) {
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	tb.stopHeartbeat = func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// endHeartbeat stops the heartbeat, if one is running
+func (tb *TraceBuilder) endHeartbeat() {
+	if tb.stopHeartbeat != nil {
+		tb.stopHeartbeat()
+	}
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// fakeClock is a clock whose tickers only fire when the test advances it
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	clock    *fakeClock
+	interval time.Duration
+	next     time.Time
+	c        chan time.Time
+	stop     chan struct{}
+	stopped  bool
+}
+
+func (c *fakeClock) NewTicker(interval time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, interval: interval, next: c.now.Add(interval), c: make(chan time.Time), stop: make(chan struct{})}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// advance moves the clock forward, delivering every tick that falls due to its receiver
+func (c *fakeClock) advance(d time.Duration) {
+	type tick struct {
+		ticker *fakeTicker
+		at     time.Time
+	}
+
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []tick
+	for _, t := range c.tickers {
+		for !t.stopped && !t.next.After(c.now) {
+			due = append(due, tick{ticker: t, at: t.next})
+			t.next = t.next.Add(t.interval)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, tick := range due {
+		select {
+		case tick.ticker.c <- tick.at:
+		case <-tick.ticker.stop:
+		case <-time.After(100 * time.Millisecond):
+			// Nobody is receiving
+		}
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if !t.stopped {
+		t.stopped = true
+		close(t.stop)
+	}
+}
+
+// newHeartbeatTestLangfuse creates a client driven by a fake clock that remembers the
+// events it enqueues
+func newHeartbeatTestLangfuse(t *testing.T) (*Langfuse, *fakeClock) {
+	clk := &fakeClock{now: time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)}
+	lf := newTestLangfuse(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.DebugRingBufferSize = 50
+		cfg.FlushAt = 100
+		cfg.FlushInterval = time.Hour
+	})
+	lf.clock = clk
+	return lf, clk
+}
+
+// eventTypes returns the type of each event
+func eventTypes(events []types.IngestionEvent) []types.EventType {
+	eventTypes := make([]types.EventType, 0, len(events))
+	for _, event := range events {
+		eventTypes = append(eventTypes, event.Type)
+	}
+	return eventTypes
+}
+
+// eventBody returns the JSON form of an event body
+func eventBody(t *testing.T, event types.IngestionEvent) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(event.Body)
+	require.NoError(t, err)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	return body
+}
+
+func TestTraceBuilder_Begin(t *testing.T) {
+	ctx := context.Background()
+	lf, clk := newHeartbeatTestLangfuse(t)
+
+	trace := lf.Trace("batch-run").Input("job-42").WithHeartbeat(time.Minute)
+	require.NoError(t, trace.Begin(ctx))
+
+	span := trace.Span("step-1")
+	require.NoError(t, span.Begin(ctx))
+	generation := span.ChildGeneration("summarize")
+	require.NoError(t, generation.Begin(ctx))
+
+	clk.advance(time.Minute)
+	require.Eventually(t, func() bool { return len(lf.RecentEvents()) == 4 }, time.Second, time.Millisecond)
+
+	require.NoError(t, generation.Output("summary").End(ctx))
+	require.NoError(t, span.End(ctx))
+	require.NoError(t, trace.Output("done").End(ctx))
+
+	events := lf.RecentEvents()
+	assert.Equal(t, []types.EventType{
+		types.EventTypeTraceCreate,
+		types.EventTypeSpanCreate,
+		types.EventTypeGenerationCreate,
+		types.EventTypeTraceUpdate,
+		types.EventTypeGenerationUpdate,
+		types.EventTypeSpanUpdate,
+		types.EventTypeTraceUpdate,
+	}, eventTypes(events))
+
+	create := eventBody(t, events[0])
+	assert.Equal(t, "job-42", create["input"])
+	assert.NotContains(t, create, "output")
+
+	heartbeat := eventBody(t, events[3])
+	assert.Equal(t, map[string]interface{}{HeartbeatMetadataKey: "2024-03-15T10:01:00Z"}, heartbeat["metadata"])
+	assert.NotContains(t, heartbeat, "input")
+
+	final := eventBody(t, events[6])
+	assert.Equal(t, "done", final["output"])
+	assert.Equal(t, "batch-run", final["name"])
+
+	t.Run("heartbeat stops when the trace ends", func(t *testing.T) {
+		clk.advance(time.Hour)
+		time.Sleep(20 * time.Millisecond)
+		assert.Len(t, lf.RecentEvents(), 7)
+	})
+}
+
+func TestTraceBuilder_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	lf, clk := newHeartbeatTestLangfuse(t)
+
+	trace := lf.Trace("batch-run").WithHeartbeat(10 * time.Minute)
+	require.NoError(t, trace.Begin(ctx))
+
+	for i := 0; i < 3; i++ {
+		clk.advance(10 * time.Minute)
+	}
+	require.Eventually(t, func() bool { return len(lf.RecentEvents()) == 4 }, time.Second, time.Millisecond)
+
+	var heartbeats []interface{}
+	for _, event := range lf.RecentEvents()[1:] {
+		assert.Equal(t, types.EventTypeTraceUpdate, event.Type)
+		heartbeats = append(heartbeats, eventBody(t, event)["metadata"].(map[string]interface{})[HeartbeatMetadataKey])
+	}
+	assert.Equal(t, []interface{}{"2024-03-15T10:10:00Z", "2024-03-15T10:20:00Z", "2024-03-15T10:30:00Z"}, heartbeats)
+
+	t.Run("no heartbeat without Begin", func(t *testing.T) {
+		lf, clk := newHeartbeatTestLangfuse(t)
+		require.NoError(t, lf.Trace("short").WithHeartbeat(time.Minute).Submit(ctx))
+
+		clk.advance(time.Hour)
+		assert.Len(t, lf.RecentEvents(), 1)
+	})
+}
+
+func TestBuilders_BeginPreventsDoubleCreate(t *testing.T) {
+	ctx := context.Background()
+	lf, _ := newHeartbeatTestLangfuse(t)
+
+	trace := lf.Trace("run")
+	require.NoError(t, trace.Begin(ctx))
+	assert.Error(t, trace.Begin(ctx))
+	assert.Error(t, trace.Submit(ctx))
+
+	span := trace.Span("step")
+	require.NoError(t, span.Begin(ctx))
+	assert.Error(t, span.Begin(ctx))
+	assert.Error(t, span.Submit(ctx))
+
+	generation := trace.Generation("llm")
+	require.NoError(t, generation.Begin(ctx))
+	assert.Error(t, generation.Submit(ctx))
+
+	submitted := trace.Span("submitted")
+	require.NoError(t, submitted.Submit(ctx))
+	assert.Error(t, submitted.Begin(ctx))
+
+	assert.Equal(t, []types.EventType{
+		types.EventTypeTraceCreate,
+		types.EventTypeSpanCreate,
+		types.EventTypeGenerationCreate,
+		types.EventTypeSpanCreate,
+	}, eventTypes(lf.RecentEvents()))
+}
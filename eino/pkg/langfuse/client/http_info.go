@@ -0,0 +1,140 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HTTPMetadataKey is the metadata key under which HTTP spans record their HTTPInfo
+const HTTPMetadataKey = "http"
+
+// redactedHeaderValue replaces the value of headers that carry credentials
+const redactedHeaderValue = "[REDACTED]"
+
+// HTTPInfo holds the standard attributes of an HTTP call. The JSON names follow the
+// OpenTelemetry semantic conventions for HTTP spans.
+type HTTPInfo struct {
+	Method     string `json:"http.request.method,omitempty"`
+	URL        string `json:"url.full,omitempty"`
+	Path       string `json:"url.path,omitempty"`
+	StatusCode int    `json:"http.response.status_code,omitempty"`
+}
+
+// sensitiveHeaders are recorded with their value redacted
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// WithHTTPInfo records the method, URL and status code of an HTTP call under
+// metadata["http"]. Credentials in the URL's user info are dropped; a status code of 0
+// is left out, for calls that got no response.
+func (sb *SpanBuilder) WithHTTPInfo(method, rawURL string, statusCode int) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.submitted {
+		sb.recordMisuse("WithHTTPInfo")
+		return sb
+	}
+	info := sb.httpInfo()
+	info.Method = strings.ToUpper(method)
+	info.URL = stripUserInfo(rawURL)
+	info.StatusCode = statusCode
+	sb.metadata[HTTPMetadataKey] = info
+	return sb
+}
+
+// WithHTTPRequest sets the input to the method, URL path and headers of req and records
+// the method and path under metadata["http"]. Only the path of the URL is kept, as the
+// host and query may carry credentials, and credential headers are redacted.
+func (sb *SpanBuilder) WithHTTPRequest(req *http.Request) *SpanBuilder {
+	if req == nil {
+		return sb
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.submitted {
+		sb.recordMisuse("WithHTTPRequest")
+		return sb
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var path string
+	if req.URL != nil {
+		path = req.URL.Path
+	}
+
+	info := sb.httpInfo()
+	info.Method = method
+	info.Path = path
+	sb.metadata[HTTPMetadataKey] = info
+	sb.input = map[string]interface{}{
+		"method":  method,
+		"path":    path,
+		"headers": httpHeaders(req.Header),
+	}
+	return sb
+}
+
+// WithHTTPResponse sets the output to the status code and headers of resp and records
+// the status code under metadata["http"], with credential headers redacted
+func (sb *SpanBuilder) WithHTTPResponse(resp *http.Response) *SpanBuilder {
+	if resp == nil {
+		return sb
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.submitted {
+		sb.recordMisuse("WithHTTPResponse")
+		return sb
+	}
+	info := sb.httpInfo()
+	info.StatusCode = resp.StatusCode
+	sb.metadata[HTTPMetadataKey] = info
+	sb.output = map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"headers":     httpHeaders(resp.Header),
+	}
+	return sb
+}
+
+// httpInfo returns the HTTPInfo recorded so far, so the request and response methods
+// can each fill in their part. The caller must hold sb.mu.
+func (sb *SpanBuilder) httpInfo() HTTPInfo {
+	if sb.metadata == nil {
+		sb.metadata = make(map[string]interface{})
+	}
+	info, _ := sb.metadata[HTTPMetadataKey].(HTTPInfo)
+	return info
+}
+
+// httpHeaders flattens headers into a map, joining repeated values and redacting those
+// that carry credentials
+func httpHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		name = http.CanonicalHeaderKey(name)
+		if sensitiveHeaders[name] {
+			headers[name] = redactedHeaderValue
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// stripUserInfo removes the user and password from rawURL, leaving unparseable URLs as is
+func stripUserInfo(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+	parsed.User = nil
+	return parsed.String()
+}
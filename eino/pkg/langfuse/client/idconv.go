@@ -0,0 +1,16 @@
+package client
+
+import "eino/pkg/langfuse/internal/utils/idconv"
+
+// Trace ID conversion helpers for correlating Langfuse traces with external systems.
+// See TraceBuilder.WithTraceID for how builders apply the same normalization.
+var (
+	ToLangfuseTraceID    = idconv.ToLangfuseTraceID
+	FromHexTraceID       = idconv.FromHexTraceID
+	IsHexTraceID         = idconv.IsHexTraceID
+	TraceIDToUUID        = idconv.ToUUID
+	NewSpanIDFromTraceID = idconv.NewSpanIDFromTraceID
+
+	// ErrInvalidTraceID is returned by the conversion helpers for inputs that cannot be safely normalized
+	ErrInvalidTraceID = idconv.ErrInvalidTraceID
+)
@@ -0,0 +1,370 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// ValidationErrors collects every problem found while validating an import
+type ValidationErrors = utils.ValidationErrors
+
+// ImportTraceSpec describes a complete, already finished trace to import,
+// for example when migrating telemetry from another tracing system.
+//
+// Unlike the builders, every ID is supplied by the caller so that the imported
+// data keeps its original identity and parent/child relationships.
+type ImportTraceSpec struct {
+	ID        string
+	Name      string
+	UserID    *string
+	SessionID *string
+	Input     interface{}
+	Output    interface{}
+	Metadata  map[string]interface{}
+	Tags      []string
+	Release   *string
+	Version   *string
+	Public    *bool
+
+	// StartTime is the trace timestamp and the lower bound for every observation
+	StartTime time.Time
+
+	// EndTime is optional; when set it is the upper bound for every observation
+	EndTime *time.Time
+
+	// Observations is a flat list; the hierarchy is expressed through ParentID
+	Observations []ImportObservation
+}
+
+// ImportObservation is a single span, generation or event within an ImportTraceSpec
+type ImportObservation struct {
+	ID string
+
+	// ParentID references another observation in the same spec; empty for top-level observations
+	ParentID string
+
+	Type                types.ObservationType
+	Name                string
+	StartTime           time.Time
+	EndTime             *time.Time
+	CompletionStartTime *time.Time
+	Model               *string
+	ModelParameters     map[string]interface{}
+	Input               interface{}
+	Output              interface{}
+	Usage               *types.Usage
+	Level               types.ObservationLevel
+	StatusMessage       *string
+	Version             *string
+	Metadata            map[string]interface{}
+}
+
+// ImportOption configures ImportTrace
+type ImportOption func(*importOptions)
+
+// importOptions holds the settings applied by ImportOption
+type importOptions struct {
+	synchronous bool
+}
+
+// WithSynchronousImport submits the imported events directly to the ingestion API and
+// waits for the result, instead of enqueuing them for the background flush
+func WithSynchronousImport() ImportOption {
+	return func(o *importOptions) {
+		o.synchronous = true
+	}
+}
+
+// Validate checks the spec as a whole and reports every problem found: missing or
+// duplicate IDs, unknown parents, parent cycles, observations outside the trace time
+// bounds and inconsistent usage. It returns nil when the spec is valid.
+func (s *ImportTraceSpec) Validate() *ValidationErrors {
+	var errs ValidationErrors
+
+	if s.ID == "" {
+		errs.Add("id", "trace id is required")
+	}
+	if s.Name == "" {
+		errs.Add("name", "trace name is required")
+	}
+	if s.StartTime.IsZero() {
+		errs.Add("startTime", "trace start time is required")
+	}
+	if s.EndTime != nil && !s.StartTime.IsZero() && s.EndTime.Before(s.StartTime) {
+		errs.Add("endTime", "trace end time cannot be before start time")
+	}
+
+	ids := make(map[string]int, len(s.Observations))
+	for i, obs := range s.Observations {
+		field := fmt.Sprintf("observations[%d]", i)
+		if obs.ID == "" {
+			errs.Add(field+".id", "observation id is required")
+			continue
+		}
+		if first, exists := ids[obs.ID]; exists {
+			errs.AddError(utils.ValidationError{
+				Field:   field + ".id",
+				Message: fmt.Sprintf("duplicate observation id (first used by observations[%d])", first),
+				Value:   obs.ID,
+			})
+			continue
+		}
+		ids[obs.ID] = i
+	}
+
+	for i, obs := range s.Observations {
+		field := fmt.Sprintf("observations[%d]", i)
+
+		switch obs.Type {
+		case types.ObservationTypeSpan, types.ObservationTypeGeneration, types.ObservationTypeEvent:
+		default:
+			errs.AddError(utils.ValidationError{Field: field + ".type", Message: "unsupported observation type", Value: string(obs.Type)})
+		}
+
+		if obs.ParentID != "" {
+			if obs.ParentID == obs.ID {
+				errs.Add(field+".parentId", "observation cannot be its own parent")
+			} else if _, ok := ids[obs.ParentID]; !ok {
+				errs.AddError(utils.ValidationError{Field: field + ".parentId", Message: "parent observation does not exist", Value: obs.ParentID})
+			}
+		}
+
+		if obs.StartTime.IsZero() {
+			errs.Add(field+".startTime", "observation start time is required")
+		} else if !s.StartTime.IsZero() && obs.StartTime.Before(s.StartTime) {
+			errs.Add(field+".startTime", "observation starts before the trace")
+		}
+		if obs.EndTime != nil {
+			if !obs.StartTime.IsZero() && obs.EndTime.Before(obs.StartTime) {
+				errs.Add(field+".endTime", "end time cannot be before start time")
+			}
+			if s.EndTime != nil && obs.EndTime.After(*s.EndTime) {
+				errs.Add(field+".endTime", "observation ends after the trace")
+			}
+		} else if s.EndTime != nil && obs.StartTime.After(*s.EndTime) {
+			errs.Add(field+".startTime", "observation starts after the trace ends")
+		}
+
+		if obs.Usage != nil {
+			if err := utils.ValidateUsage(obs.Usage.Input, obs.Usage.Output, obs.Usage.Total, field+".usage"); err != nil {
+				errs.AddError(*err)
+			}
+		}
+	}
+
+	if _, cyclic := s.orderObservations(); len(cyclic) > 0 {
+		for _, i := range cyclic {
+			errs.Add(fmt.Sprintf("observations[%d].parentId", i), "observation is part of a parent cycle")
+		}
+	}
+
+	if !errs.HasErrors() {
+		return nil
+	}
+	return &errs
+}
+
+// orderObservations returns observation indexes ordered so that every parent comes
+// before its children, keeping the input order among siblings. Observations whose
+// parent chain never reaches a top-level observation are returned as cyclic.
+func (s *ImportTraceSpec) orderObservations() (ordered []int, cyclic []int) {
+	known := make(map[string]bool, len(s.Observations))
+	for _, obs := range s.Observations {
+		known[obs.ID] = true
+	}
+
+	children := make(map[string][]int)
+	var roots []int
+	for i, obs := range s.Observations {
+		// Unknown parents are reported separately; treat them as top-level here
+		if obs.ParentID == "" || obs.ParentID == obs.ID || !known[obs.ParentID] {
+			roots = append(roots, i)
+			continue
+		}
+		children[obs.ParentID] = append(children[obs.ParentID], i)
+	}
+
+	visited := make([]bool, len(s.Observations))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		ordered = append(ordered, i)
+		for _, child := range children[s.Observations[i].ID] {
+			visit(child)
+		}
+	}
+	for _, i := range roots {
+		visit(i)
+	}
+
+	for i := range s.Observations {
+		if !visited[i] {
+			cyclic = append(cyclic, i)
+		}
+	}
+	return ordered, cyclic
+}
+
+// ImportTrace validates an ImportTraceSpec and converts it into ingestion events: the
+// trace first, followed by its observations with every parent ahead of its children.
+//
+// By default the events are enqueued like any other SDK event. With
+// WithSynchronousImport they are submitted directly and any API error is returned.
+//
+// Validation failures are returned as a *ValidationErrors listing every problem in the
+// spec; nothing is enqueued or submitted in that case.
+//
+// Example:
+//
+//	err := langfuse.ImportTrace(ctx, &client.ImportTraceSpec{
+//		ID:        legacy.TraceID,
+//		Name:      legacy.Operation,
+//		StartTime: legacy.Start,
+//		Observations: []client.ImportObservation{
+//			{ID: "root", Type: types.ObservationTypeSpan, Name: "handler", StartTime: legacy.Start},
+//			{ID: "llm", ParentID: "root", Type: types.ObservationTypeGeneration, Name: "completion", StartTime: legacy.Start},
+//		},
+//	}, client.WithSynchronousImport())
+func (lf *Langfuse) ImportTrace(ctx context.Context, spec *ImportTraceSpec, opts ...ImportOption) error {
+	if spec == nil {
+		return &ValidationError{Field: "spec", Message: "import spec cannot be nil"}
+	}
+
+	if lf.isDisabled() {
+		return nil
+	}
+
+	if errs := spec.Validate(); errs != nil {
+		return errs
+	}
+
+	options := &importOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	events := lf.importEvents(spec)
+
+	if options.synchronous {
+		if err := lf.submitImportEvents(ctx, events); err != nil {
+			return err
+		}
+	} else {
+		for _, event := range events {
+			if err := lf.queue.Enqueue(event); err != nil {
+				return fmt.Errorf("failed to enqueue imported event %s: %w", event.ID, err)
+			}
+		}
+	}
+
+	lf.recordImportStats(spec)
+	return nil
+}
+
+// importEvents converts a validated spec into ordered ingestion events
+func (lf *Langfuse) importEvents(spec *ImportTraceSpec) []ingestiontypes.IngestionEvent {
+	trace := &ingestiontypes.TraceCreateEvent{
+		TraceEvent: ingestiontypes.TraceEvent{
+			ID:        spec.ID,
+			Name:      spec.Name,
+			UserID:    spec.UserID,
+			SessionID: spec.SessionID,
+			Input:     lf.serializeInput(spec.Input, ""),
+			Output:    lf.serializeOutput(spec.Output, ""),
+			Metadata:  lf.serializeMetadata(spec.Metadata),
+			Tags:      spec.Tags,
+			Release:   spec.Release,
+			Version:   spec.Version,
+			Public:    spec.Public,
+			Timestamp: spec.StartTime,
+		},
+		Type: "trace-create",
+	}
+
+	events := make([]ingestiontypes.IngestionEvent, 0, len(spec.Observations)+1)
+	events = append(events, trace.ToIngestionEvent())
+
+	ordered, _ := spec.orderObservations()
+	for _, i := range ordered {
+		events = append(events, lf.importObservationEvent(spec.ID, &spec.Observations[i]))
+	}
+	return events
+}
+
+// importObservationEvent converts a single observation into its create event
+func (lf *Langfuse) importObservationEvent(traceID string, obs *ImportObservation) ingestiontypes.IngestionEvent {
+	event := ingestiontypes.ObservationEvent{
+		ID:                  obs.ID,
+		TraceID:             traceID,
+		Type:                obs.Type,
+		Name:                obs.Name,
+		StartTime:           obs.StartTime,
+		EndTime:             obs.EndTime,
+		CompletionStartTime: obs.CompletionStartTime,
+		Model:               obs.Model,
+		ModelParameters:     obs.ModelParameters,
+		Input:               lf.serializeInput(obs.Input, ""),
+		Output:              lf.serializeOutput(obs.Output, ""),
+		Usage:               obs.Usage,
+		Level:               obs.Level,
+		StatusMessage:       obs.StatusMessage,
+		Version:             obs.Version,
+		Metadata:            lf.serializeMetadata(obs.Metadata),
+	}
+	if obs.ParentID != "" {
+		parentID := obs.ParentID
+		event.ParentObservationID = &parentID
+	}
+
+	switch obs.Type {
+	case types.ObservationTypeGeneration:
+		return (&ingestiontypes.GenerationCreateEvent{ObservationEvent: event, EventType: "generation-create"}).ToIngestionEvent()
+	case types.ObservationTypeEvent:
+		return (&ingestiontypes.EventCreateEvent{ObservationEvent: event, EventType: "event-create"}).ToIngestionEvent()
+	default:
+		return (&ingestiontypes.SpanCreateEvent{ObservationEvent: event, EventType: "span-create"}).ToIngestionEvent()
+	}
+}
+
+// submitImportEvents sends events to the ingestion API in order, split into batches
+func (lf *Langfuse) submitImportEvents(ctx context.Context, events []ingestiontypes.IngestionEvent) error {
+	for start := 0; start < len(events); start += ingestiontypes.MaxBatchSize {
+		end := start + ingestiontypes.MaxBatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+
+		resp, err := lf.transport.SubmitBatch(ctx, events[start:end])
+		if err != nil {
+			return fmt.Errorf("failed to submit imported events: %w", err)
+		}
+		if resp != nil && resp.HasErrors() {
+			first := resp.Errors[0]
+			return fmt.Errorf("ingestion rejected %d imported events (first %s: %s)", resp.ErrorCount(), first.ID, first.Message)
+		}
+	}
+	return nil
+}
+
+// recordImportStats counts imported traces and observations in the client statistics
+func (lf *Langfuse) recordImportStats(spec *ImportTraceSpec) {
+	root := lf.root()
+	root.stats.tracesCreated.Add(1)
+	for _, obs := range spec.Observations {
+		switch obs.Type {
+		case types.ObservationTypeGeneration:
+			root.stats.generationsCreated.Add(1)
+		case types.ObservationTypeSpan:
+			root.stats.spansCreated.Add(1)
+		}
+	}
+	root.stats.touch()
+}
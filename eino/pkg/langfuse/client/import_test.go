@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// importFixture builds a 50-observation trace: 5 top-level spans, each with 3 child
+// generations that each have 2 events. Observations are listed children-first so that
+// ImportTrace has to reorder them.
+func importFixture(start time.Time) *ImportTraceSpec {
+	end := start.Add(time.Hour)
+	spec := &ImportTraceSpec{
+		ID:        "legacy-trace",
+		Name:      "legacy-request",
+		StartTime: start,
+		EndTime:   &end,
+	}
+
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+
+	var spans, generations, events []ImportObservation
+	for s := 0; s < 5; s++ {
+		spanID := fmt.Sprintf("span-%d", s)
+		spans = append(spans, ImportObservation{
+			ID: spanID, Type: types.ObservationTypeSpan, Name: spanID,
+			StartTime: *at(s * 10), EndTime: at(s*10 + 9),
+		})
+		for g := 0; g < 3; g++ {
+			genID := fmt.Sprintf("gen-%d-%d", s, g)
+			generations = append(generations, ImportObservation{
+				ID: genID, ParentID: spanID, Type: types.ObservationTypeGeneration, Name: genID,
+				StartTime: *at(s*10 + g), EndTime: at(s*10 + g + 1),
+				Usage: &types.Usage{Input: intPtr(10), Output: intPtr(5), Total: intPtr(15)},
+			})
+			for e := 0; e < 2; e++ {
+				eventID := fmt.Sprintf("event-%d-%d-%d", s, g, e)
+				events = append(events, ImportObservation{
+					ID: eventID, ParentID: genID, Type: types.ObservationTypeEvent, Name: eventID,
+					StartTime: *at(s*10 + g),
+				})
+			}
+		}
+	}
+
+	spec.Observations = append(append(append(spec.Observations, events...), generations...), spans...)
+	return spec
+}
+
+// ingestionRecorder captures the events received by the ingestion endpoint
+type ingestionRecorder struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (r *ingestionRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Batch []map[string]interface{} `json:"batch"`
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	r.mu.Lock()
+	r.events = append(r.events, body.Batch...)
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"success":true,"timestamp":"2024-01-01T12:00:00Z"}`))
+}
+
+func TestLangfuse_ImportTrace_Synchronous(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+
+	spec := importFixture(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.Len(t, spec.Observations, 50)
+
+	require.NoError(t, lf.ImportTrace(context.Background(), spec, WithSynchronousImport()))
+
+	recorder.mu.Lock()
+	events := recorder.events
+	recorder.mu.Unlock()
+	require.Len(t, events, 51)
+
+	assert.Equal(t, "trace-create", events[0]["type"])
+
+	// Every parent is sent before its children
+	position := make(map[string]int)
+	counts := make(map[string]int)
+	for i, event := range events[1:] {
+		body := event["body"].(map[string]interface{})
+		id := body["id"].(string)
+		position[id] = i
+		counts[event["type"].(string)]++
+
+		assert.Equal(t, "legacy-trace", body["traceId"])
+		if parentID, ok := body["parentObservationId"].(string); ok {
+			parentPos, seen := position[parentID]
+			require.True(t, seen, "parent %s of %s sent after child", parentID, id)
+			assert.Less(t, parentPos, i)
+		}
+	}
+	assert.Equal(t, map[string]int{"span-create": 5, "generation-create": 15, "event-create": 30}, counts)
+
+	// Siblings keep their input order
+	assert.Less(t, position["span-0"], position["span-1"])
+	assert.Less(t, position["gen-0-0"], position["gen-0-1"])
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(1), stats.TracesCreated)
+	assert.Equal(t, int64(5), stats.SpansCreated)
+	assert.Equal(t, int64(15), stats.GenerationsCreated)
+}
+
+func TestLangfuse_ImportTrace_Enqueue(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux(), func(cfg *Config) {
+		cfg.FlushAt = 1000
+		cfg.FlushInterval = time.Hour
+	})
+
+	spec := importFixture(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, lf.ImportTrace(context.Background(), spec))
+
+	assert.Equal(t, 51, lf.queue.Size())
+}
+
+func TestLangfuse_ImportTrace_ReportsAllErrors(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := importFixture(start)
+
+	spec.Observations[0].ParentID = "missing-parent"
+	spec.Observations[1].ID = spec.Observations[2].ID
+	spec.Observations[3].StartTime = start.Add(-time.Minute)
+	spec.Observations[30].Usage = &types.Usage{Input: intPtr(10), Output: intPtr(5), Total: intPtr(20)}
+	after := start.Add(2 * time.Hour)
+	spec.Observations[49].EndTime = &after
+
+	err := lf.ImportTrace(context.Background(), spec)
+	require.Error(t, err)
+
+	var errs *ValidationErrors
+	require.True(t, errors.As(err, &errs))
+
+	fields := make([]string, 0, len(*errs))
+	for _, e := range *errs {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"observations[0].parentId",
+		"observations[2].id",
+		"observations[3].startTime",
+		"observations[30].usage.total",
+		"observations[49].endTime",
+	}, fields)
+
+	// Nothing is enqueued when validation fails
+	assert.Equal(t, 0, lf.queue.Size())
+}
+
+func TestImportTraceSpec_Validate_Cycle(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := &ImportTraceSpec{
+		ID:        "trace",
+		Name:      "cyclic",
+		StartTime: start,
+		Observations: []ImportObservation{
+			{ID: "a", ParentID: "b", Type: types.ObservationTypeSpan, StartTime: start},
+			{ID: "b", ParentID: "a", Type: types.ObservationTypeSpan, StartTime: start},
+			{ID: "c", Type: types.ObservationTypeSpan, StartTime: start},
+		},
+	}
+
+	errs := spec.Validate()
+	require.NotNil(t, errs)
+	assert.Len(t, *errs, 2)
+	assert.Equal(t, "observations[0].parentId", (*errs)[0].Field)
+	assert.Equal(t, "observations[1].parentId", (*errs)[1].Field)
+}
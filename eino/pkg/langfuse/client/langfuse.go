@@ -32,17 +32,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"eino/pkg/langfuse/api"
 	"eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/scores"
 	scoreTypes "eino/pkg/langfuse/api/resources/scores/types"
 	"eino/pkg/langfuse/config"
 	"eino/pkg/langfuse/internal/queue"
 	"eino/pkg/langfuse/internal/utils"
 )
 
+// Queue errors, wrapped by Submit, Update and End when an event cannot be enqueued
+var (
+	// ErrQueueClosed is returned after the client has been shut down
+	ErrQueueClosed = queue.ErrQueueClosed
+
+	// ErrQueueFull is returned when the queue is at capacity and RejectWhenQueueFull is set
+	ErrQueueFull = queue.ErrQueueFull
+)
+
+// ErrUnknownScoreName is returned by Score in strict mode for a name missing from the
+// registry configured with WithAllowedScoreNames or WithScoreConfigNames
+var ErrUnknownScoreName = scores.ErrUnknownScoreName
+
+// ScoreOption configures a single Score call
+type ScoreOption = scores.CreateOption
+
+// AllowUnregisteredName lets a score through the score-name registry
+var AllowUnregisteredName = scores.AllowUnregisteredName
+
+// ScoreEnvironment names the environment of the scored trace for ScoreAsync, so the score
+// goes to the same project as a trace routed with TraceBuilder.WithEnvironment
+var ScoreEnvironment = scores.WithEnvironment
+
 // Langfuse is the main SDK client providing high-level builder APIs and direct API access.
 //
 // The client manages traces, spans, generations, and scores through a fluent builder pattern
@@ -65,13 +92,62 @@ type Langfuse struct {
 	apiClient *api.APIClient
 	queue     *queue.IngestionQueue
 
+	// transport delivers event batches: the REST ingestion client unless a custom
+	// IngestionTransport is configured
+	transport IngestionTransport
+
+	// secondary copies batches to the secondary host, nil unless one is configured
+	secondary *dualWriteTransport
+
+	// projects routes events to the projects of their environments, nil unless project
+	// credentials are configured
+	projects *projectTransport
+
 	// State management
 	mu     sync.RWMutex
 	closed bool
 
 	// Statistics
-	stats   *ClientStats
-	statsMu sync.RWMutex
+	stats *clientCounters
+
+	// Live builder registry, only populated in strict mode or when shutdown tracks builders
+	registry *builderRegistry
+
+	// Generation usage per open trace, summed onto the trace when it ends
+	usage *usageRollup
+
+	// Active session of each user, used by GetOrCreateSession
+	sessions *sessionCache
+
+	// Most recently enqueued events, only kept when WithDebugRingBuffer is set
+	recentEvents *eventRing
+
+	// Time source for trace heartbeats; nil means the system clock
+	clock clock
+
+	// Open transaction buffering the events of this client's builders, set on the
+	// derived client of a Transaction
+	tx *Transaction
+
+	// Caps the rate at which Trace, Span and Generation create builders, set by
+	// WithRateLimit and shared with clients derived from this one
+	limiter *rate.Limiter
+
+	// Decides which traces are sent, nil when every trace is
+	sampler *sampler
+
+	// Passes asynchronous errors to the ErrorHandler, nil when none is configured. Only
+	// the root client has one, as the queue hooks report to it.
+	errorHandler *errorDispatcher
+
+	// Pauses submission while the API is unhealthy, nil unless WithHealthPolling is set
+	healthPoller *healthPoller
+
+	// Derived clients created by WithUserID/WithSessionID share the parent's
+	// queue, statistics and lifecycle, and pre-set these values on new traces
+	parent           *Langfuse
+	defaultUserID    *string
+	defaultSessionID *string
 }
 
 // ClientStats represents comprehensive usage statistics for the Langfuse client.
@@ -102,6 +178,35 @@ type ClientStats struct {
 
 	// CreatedAt is the timestamp when the client was created
 	CreatedAt time.Time `json:"createdAt"`
+
+	// OldestEventAge is how long the front-most queued event has been waiting to be
+	// submitted, or zero when the queue is empty. A growing age means the backend is not
+	// keeping up, which queue depth alone does not show.
+	OldestEventAge time.Duration `json:"oldestEventAge"`
+
+	// Secondary counts the batches copied to the host configured with WithSecondaryHost,
+	// nil when none is configured
+	Secondary *SecondaryStats `json:"secondary,omitempty"`
+
+	// Projects counts the batches sent to each project by environment, with the default
+	// project under DefaultProject, nil unless project credentials are configured
+	Projects map[string]ProjectStats `json:"projects,omitempty"`
+
+	// RateLimitedEvents is the number of Trace, Span and Generation calls that returned a
+	// no-op builder because they exceeded the limit set with WithRateLimit
+	RateLimitedEvents int64 `json:"rateLimitedEvents"`
+
+	// Sampling counts the traces kept and dropped by each sampling rule, nil unless
+	// SamplingRules or a SampleRate below 1 are configured
+	Sampling *SamplingStats `json:"sampling,omitempty"`
+
+	// ErrorsDiscarded is the number of asynchronous errors not passed to the
+	// ErrorHandler because it fell too far behind
+	ErrorsDiscarded int64 `json:"errorsDiscarded"`
+
+	// SubmissionPaused reports that WithHealthPolling found the API unhealthy, so events
+	// are buffered instead of submitted
+	SubmissionPaused bool `json:"submissionPaused"`
 }
 
 // New creates a new Langfuse client instance with the provided configuration.
@@ -141,6 +246,9 @@ func New(config *config.Config) (*Langfuse, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
+	for _, warning := range config.Warnings {
+		log.Printf("langfuse: %s", warning)
+	}
 
 	// Skip if SDK is disabled
 	if !config.Enabled {
@@ -157,32 +265,89 @@ func New(config *config.Config) (*Langfuse, error) {
 	client := &Langfuse{
 		config:    config,
 		apiClient: apiClient,
+		transport: config.IngestionTransport,
+		usage:     newUsageRollup(),
+		sessions:  newSessionCache(),
 		closed:    false,
-		stats: &ClientStats{
-			CreatedAt: time.Now(),
-		},
+		stats:     newClientCounters(),
+		sampler:   newSampler(config),
+	}
+
+	if config.StrictMode || config.ShutdownGracePeriod > 0 || config.ForceEndOnShutdown {
+		client.registry = newBuilderRegistry()
+	}
+	if config.DebugRingBufferSize > 0 {
+		client.recentEvents = newEventRing(config.DebugRingBufferSize)
+	}
+
+	// Set up the transports before starting the error dispatcher, since they can fail
+	if client.transport == nil {
+		client.transport = apiClient.Ingestion
+	}
+	if len(config.Projects) > 0 {
+		projects, err := newProjectTransport(config, client.transport)
+		if err != nil {
+			return nil, err
+		}
+		client.projects = projects
+		client.transport = projects
+	}
+	if config.SecondaryHost != "" {
+		secondary, err := newSecondaryTransport(config)
+		if err != nil {
+			if client.projects != nil {
+				client.projects.close()
+			}
+			return nil, err
+		}
+		client.secondary = &dualWriteTransport{primary: client.transport, secondary: secondary}
+		client.transport = client.secondary
+	}
+
+	if config.ErrorHandler != nil {
+		client.errorHandler = newErrorDispatcher(config.ErrorHandler)
 	}
 
-	// Create ingestion queue with proper configuration and event hooks
+	// Create ingestion queue with proper configuration and event hooks. Batch retries
+	// come on top of the HTTP retries of every attempt.
+	_, batchRetries := config.RetryAttempts()
 	queueConfig := &queue.QueueConfig{
-		FlushAt:       config.FlushAt,
-		FlushInterval: config.FlushInterval,
-		MaxRetries:    config.RetryCount,
-		RetryBackoff:  config.RetryWaitTime,
-		MaxQueueSize:  config.QueueSize,
-		OnFlushEnd: func(batchSize int, success bool, err error) {
-			client.statsMu.Lock()
-			client.stats.LastActivity = time.Now()
+		FlushAt:         config.FlushAt,
+		FlushInterval:   config.FlushInterval,
+		MaxRetries:      batchRetries,
+		RetryBackoff:    config.RetryWaitTime,
+		MaxQueueSize:    config.QueueSize,
+		CoalesceUpdates: config.CoalesceUpdates,
+		RejectWhenFull:  config.RejectWhenQueueFull,
+		WorkerCount:     config.WorkerCount,
+		OnFlushEnd: func(batchSize int, success bool, err error, _ time.Duration) {
 			if success {
-				client.stats.EventsSubmitted += int64(batchSize)
-			} else {
-				client.stats.EventsFailed += int64(batchSize)
+				err = nil
+			} else if err == nil {
+				err = fmt.Errorf("batch of %d events was rejected", batchSize)
+			}
+			client.stats.recordSubmission(batchSize, err)
+			if err != nil {
+				client.errorHandler.report(&DeadLetterError{BatchSize: batchSize, Err: err})
 			}
-			client.statsMu.Unlock()
 		},
 	}
+	if client.errorHandler != nil {
+		queueConfig.OnSubmitError = func(attempt, batchSize int, err error) {
+			client.errorHandler.report(&FlushError{BatchSize: batchSize, Attempt: attempt, Err: err})
+		}
+		queueConfig.OnEventDrop = client.errorHandler.reportDrop
+	}
 
-	client.queue = queue.NewIngestionQueue(apiClient.Ingestion, queueConfig)
+	for _, mw := range config.EventMiddleware {
+		queueConfig.Middleware = append(queueConfig.Middleware, queue.EventMiddleware(mw))
+	}
+	if client.recentEvents != nil {
+		queueConfig.OnEnqueue = client.recentEvents.add
+	}
+
+	client.queue = queue.NewIngestionQueue(client.transport, queueConfig)
+	client.startHealthPolling()
 
 	return client, nil
 }
@@ -226,9 +391,7 @@ func newDisabledClient(config *Config) *Langfuse {
 	return &Langfuse{
 		config: config,
 		closed: true, // Mark as closed to prevent operations
-		stats: &ClientStats{
-			CreatedAt: time.Now(),
-		},
+		stats:  newClientCounters(),
 	}
 }
 
@@ -263,21 +426,30 @@ func newDisabledClient(config *Config) *Langfuse {
 // The name should be descriptive and consistent across similar operations to enable
 // effective grouping and analysis in the Langfuse UI.
 //
-// If the client is disabled, returns a no-op trace builder that accepts all operations
-// but performs no actual work.
+// If the client is disabled or its WithRateLimit limit is exceeded, returns a no-op
+// trace builder that accepts all operations but performs no actual work.
 func (lf *Langfuse) Trace(name string) *TraceBuilder {
 	if lf.isDisabled() {
 		return newDisabledTraceBuilder(name)
 	}
+	if !lf.allowBuilder() {
+		return newNoopTraceBuilder(name)
+	}
 
-	lf.statsMu.Lock()
-	lf.stats.TracesCreated++
-	lf.stats.LastActivity = time.Now()
-	lf.statsMu.Unlock()
+	root := lf.root()
+	root.stats.tracesCreated.Add(1)
+	root.stats.touch()
 
 	builder := NewTraceBuilder(lf)
 	builder.Name(name)
 
+	if lf.defaultUserID != nil {
+		builder.UserID(*lf.defaultUserID)
+	}
+	if lf.defaultSessionID != nil {
+		builder.SessionID(*lf.defaultSessionID)
+	}
+
 	return builder
 }
 
@@ -312,19 +484,22 @@ func (lf *Langfuse) Trace(name string) *TraceBuilder {
 //		log.Printf("Failed to submit span: %v", err)
 //	}
 //
-// If the client is disabled, returns a no-op span builder.
+// If the client is disabled or its WithRateLimit limit is exceeded, returns a no-op
+// span builder.
 func (lf *Langfuse) Span(name string) *SpanBuilder {
 	if lf.isDisabled() {
 		return newDisabledSpanBuilder(name)
 	}
+	if !lf.allowBuilder() {
+		return newNoopSpanBuilder(utils.GenerateTraceID(), name)
+	}
 
 	// Create a trace automatically for standalone spans
 	traceID := utils.GenerateTraceID()
 
-	lf.statsMu.Lock()
-	lf.stats.SpansCreated++
-	lf.stats.LastActivity = time.Now()
-	lf.statsMu.Unlock()
+	root := lf.root()
+	root.stats.spansCreated.Add(1)
+	root.stats.touch()
 
 	builder := NewSpanBuilder(lf, traceID)
 	builder.Name(name)
@@ -368,19 +543,22 @@ func (lf *Langfuse) Span(name string) *SpanBuilder {
 //		log.Printf("Failed to submit generation: %v", err)
 //	}
 //
-// If the client is disabled, returns a no-op generation builder.
+// If the client is disabled or its WithRateLimit limit is exceeded, returns a no-op
+// generation builder.
 func (lf *Langfuse) Generation(name string) *GenerationBuilder {
 	if lf.isDisabled() {
 		return newDisabledGenerationBuilder(name)
 	}
+	if !lf.allowBuilder() {
+		return newNoopGenerationBuilder(utils.GenerateTraceID(), name)
+	}
 
 	// Create a trace automatically for standalone generations
 	traceID := utils.GenerateTraceID()
 
-	lf.statsMu.Lock()
-	lf.stats.GenerationsCreated++
-	lf.stats.LastActivity = time.Now()
-	lf.statsMu.Unlock()
+	root := lf.root()
+	root.stats.generationsCreated.Add(1)
+	root.stats.touch()
 
 	builder := NewGenerationBuilder(lf, traceID)
 	builder.Name(name)
@@ -396,7 +574,8 @@ func (lf *Langfuse) Generation(name string) *GenerationBuilder {
 //
 // Unlike traces and generations which use builders, scores are submitted immediately
 // and synchronously. This method is useful for programmatic evaluation and automated
-// scoring workflows.
+// scoring workflows. Use ScoreAsync to send a score through the ingestion queue, with
+// its batching and retries, instead.
 //
 // Example:
 //
@@ -413,11 +592,13 @@ func (lf *Langfuse) Generation(name string) *GenerationBuilder {
 //	}
 //
 // The score must have a valid TraceID referencing an existing trace. The Name should
-// be consistent across similar evaluations to enable analysis and aggregation.
+// be consistent across similar evaluations to enable analysis and aggregation. When
+// score names are registered, an unknown name is rejected in strict mode and otherwise
+// logged with the closest registered name; pass AllowUnregisteredName to skip the check.
 //
 // Returns an error if the score is invalid, the trace doesn't exist, or submission fails.
 // If the client is disabled, this method returns nil without error.
-func (lf *Langfuse) Score(score *types.Score) error {
+func (lf *Langfuse) Score(score *types.Score, opts ...ScoreOption) error {
 	if lf.isDisabled() {
 		return nil
 	}
@@ -429,38 +610,74 @@ func (lf *Langfuse) Score(score *types.Score) error {
 	ctx, cancel := context.WithTimeout(context.Background(), lf.config.RequestTimeout)
 	defer cancel()
 
-	// Convert Score to CreateScoreRequest
-	var value interface{}
-	if score.Value != nil {
-		if err := json.Unmarshal(score.Value, &value); err != nil {
-			return fmt.Errorf("failed to unmarshal score value: %w", err)
-		}
+	req, err := createScoreRequest(score)
+	if err != nil {
+		return err
 	}
 
-	req := &scoreTypes.CreateScoreRequest{
-		TraceID:       score.TraceID,
-		ObservationID: score.ObservationID,
-		Name:          score.Name,
-		Value:         value,
-		DataType:      score.DataType,
-		Comment:       score.Comment,
-		ConfigID:      score.ConfigID,
+	_, err = lf.apiClient.Scores.Create(ctx, req, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create score: %w", err)
 	}
 
-	if score.ID != "" {
-		req.ID = &score.ID
+	root := lf.root()
+	root.stats.touch()
+
+	return nil
+}
+
+// ForkTrace creates a new trace seeded from an existing server-side trace.
+//
+// The source trace is fetched from the API and its input and metadata are copied into
+// a fresh TraceBuilder with a newly generated ID. The returned builder has not been
+// submitted, so callers can attach new spans and generations before ending it. This is
+// useful for A/B testing prompts or models against the same input.
+//
+// Example:
+//
+//	fork, err := client.ForkTrace(ctx, "trace-123", "summarize-prompt-v2")
+//	if err != nil {
+//		return err
+//	}
+//	span := fork.Span("summarize-with-new-prompt")
+//	// ... run the experiment and record results on span
+//	span.End(ctx)
+//	fork.End(ctx)
+//
+// The forked trace records the source trace ID under the "forkedFromTraceId" metadata key.
+// If the client is disabled, returns a no-op trace builder.
+func (lf *Langfuse) ForkTrace(ctx context.Context, sourceTraceID, newName string) (*TraceBuilder, error) {
+	if sourceTraceID == "" {
+		return nil, fmt.Errorf("source trace ID cannot be empty")
 	}
 
-	_, err := lf.apiClient.Scores.Create(ctx, req)
+	if lf.isDisabled() {
+		return newDisabledTraceBuilder(newName), nil
+	}
+
+	source, err := lf.apiClient.Traces.Get(ctx, sourceTraceID)
 	if err != nil {
-		return fmt.Errorf("failed to create score: %w", err)
+		return nil, fmt.Errorf("failed to fetch source trace: %w", err)
 	}
 
-	lf.statsMu.Lock()
-	lf.stats.LastActivity = time.Now()
-	lf.statsMu.Unlock()
+	var input interface{}
+	if len(source.Input) > 0 {
+		if err := json.Unmarshal(source.Input, &input); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal source trace input: %w", err)
+		}
+	}
 
-	return nil
+	metadata := utils.CloneMetadata(source.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["forkedFromTraceId"] = sourceTraceID
+
+	builder := lf.Trace(newName).
+		Input(input).
+		Metadata(metadata)
+
+	return builder, nil
 }
 
 // API returns the underlying API client for direct API access
@@ -481,19 +698,36 @@ func (lf *Langfuse) GetConfig() *Config {
 	return &configCopy
 }
 
-// GetStats returns current client statistics
+// GetStats returns current client statistics.
+//
+// The counters are read one at a time without stopping the goroutines that update
+// them, so the snapshot is not transactional: taken under load, TracesCreated may
+// already include a trace whose events EventsSubmitted does not count yet.
 func (lf *Langfuse) GetStats() *ClientStats {
-	lf.statsMu.RLock()
-	defer lf.statsMu.RUnlock()
+	root := lf.root()
+	statsCopy := root.stats.snapshot()
 
-	// Return a copy to prevent modification
-	statsCopy := *lf.stats
+	if root.queue != nil {
+		statsCopy.OldestEventAge = root.queue.OldestEventAge()
+		statsCopy.SubmissionPaused = root.queue.IsPaused()
+	}
+	if root.secondary != nil {
+		secondary := root.secondary.snapshot()
+		statsCopy.Secondary = &secondary
+	}
+	if root.projects != nil {
+		statsCopy.Projects = root.projects.snapshot()
+	}
+	if root.sampler != nil {
+		statsCopy.Sampling = root.sampler.snapshot()
+	}
+	statsCopy.ErrorsDiscarded = root.errorHandler.discardedCount()
 	return &statsCopy
 }
 
 // IsEnabled returns whether the client is enabled and operational
 func (lf *Langfuse) IsEnabled() bool {
-	return lf.config.Enabled && !lf.closed
+	return !lf.isDisabled()
 }
 
 // IsHealthy returns whether the underlying API client is healthy
@@ -519,14 +753,29 @@ func (lf *Langfuse) Flush(ctx context.Context) error {
 
 // Shutdown gracefully shuts down the client, flushing pending events
 func (lf *Langfuse) Shutdown(ctx context.Context) error {
+	// Derived clients share the parent's queue, so shutting one down shuts down the parent
+	if lf.parent != nil {
+		return lf.parent.Shutdown(ctx)
+	}
+
+	lf.mu.RLock()
+	closed := lf.closed
+	lf.mu.RUnlock()
+	if closed {
+		return nil
+	}
+
+	// Open builders are ended without holding the lock, since ending them enqueues events
+	shutdownError := lf.endOpenBuilders(ctx)
+
 	lf.mu.Lock()
 	defer lf.mu.Unlock()
 
 	if lf.closed {
-		return nil
+		return shutdownError
 	}
 
-	var shutdownError error
+	lf.healthPoller.stop()
 
 	// Flush pending events first
 	if lf.queue != nil {
@@ -554,26 +803,57 @@ func (lf *Langfuse) Shutdown(ctx context.Context) error {
 			}
 		}
 	}
+	if lf.projects != nil {
+		if err := lf.projects.close(); err != nil {
+			if shutdownError != nil {
+				shutdownError = fmt.Errorf("%w; project API client close error: %v", shutdownError, err)
+			} else {
+				shutdownError = fmt.Errorf("failed to close project API clients: %w", err)
+			}
+		}
+	}
 
 	lf.closed = true
+	lf.errorHandler.stop()
+	lf.clearUsageRollup()
+
+	// In strict mode, report builders that were created but never ended
+	if unended := lf.UnendedBuilders(); lf.strictMode() && len(unended) > 0 {
+		unendedError := &UnendedBuildersError{Builders: unended}
+		if shutdownError != nil {
+			shutdownError = fmt.Errorf("%w; %v", shutdownError, unendedError)
+		} else {
+			shutdownError = unendedError
+		}
+	}
+
 	return shutdownError
 }
 
-// HealthCheck performs a health check against the Langfuse API
+// HealthCheck performs a health check of the client and the Langfuse API, returning an
+// error unless every component is healthy. See HealthCheckDetailed for the breakdown.
+//
+// When a custom IngestionTransport is configured, the API is not checked and the
+// transport is checked if it implements HealthChecker.
 func (lf *Langfuse) HealthCheck(ctx context.Context) error {
-	if lf.isDisabled() {
-		return fmt.Errorf("client is disabled")
+	report, err := lf.HealthCheckDetailed(ctx)
+	if err != nil {
+		return err
 	}
-
-	return lf.apiClient.TestConnection(ctx)
+	return report.Err()
 }
 
-// WaitForHealthy waits for the service to become healthy within the given timeout
+// WaitForHealthy waits for the service to become healthy within the given timeout.
+// With a custom IngestionTransport it follows the same rules as HealthCheck.
 func (lf *Langfuse) WaitForHealthy(ctx context.Context, checkInterval time.Duration) error {
 	if lf.isDisabled() {
 		return fmt.Errorf("client is disabled")
 	}
 
+	if lf.config.IngestionTransport != nil {
+		return lf.waitForTransportHealthy(ctx, checkInterval)
+	}
+
 	return lf.apiClient.WaitForHealthy(ctx, checkInterval)
 }
 
@@ -589,6 +869,10 @@ func (lf *Langfuse) GetEnvironment() string {
 
 // isDisabled checks if the client is disabled or closed
 func (lf *Langfuse) isDisabled() bool {
+	if lf.parent != nil && lf.parent.isDisabled() {
+		return true
+	}
+
 	lf.mu.RLock()
 	defer lf.mu.RUnlock()
 
@@ -616,6 +900,31 @@ func (lf *Langfuse) validateScore(score *types.Score) error {
 	return nil
 }
 
+// createScoreRequest converts a score into a request of the scores API
+func createScoreRequest(score *types.Score) (*scoreTypes.CreateScoreRequest, error) {
+	var value interface{}
+	if score.Value != nil {
+		if err := json.Unmarshal(score.Value, &value); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal score value: %w", err)
+		}
+	}
+
+	req := &scoreTypes.CreateScoreRequest{
+		TraceID:       score.TraceID,
+		ObservationID: score.ObservationID,
+		Name:          score.Name,
+		Value:         value,
+		DataType:      score.DataType,
+		Comment:       score.Comment,
+		ConfigID:      score.ConfigID,
+	}
+
+	if score.ID != "" {
+		req.ID = &score.ID
+	}
+	return req, nil
+}
+
 // Disabled builder constructors that return no-op builders
 func newDisabledTraceBuilder(name string) *TraceBuilder {
 	return &TraceBuilder{
@@ -647,16 +956,76 @@ func (lf *Langfuse) WithTimeout(timeout time.Duration) *Langfuse {
 	}
 
 	// Create a copy of the config with the new timeout
-	newConfig := *lf.config
+	newConfig := lf.config.Clone()
 	newConfig.RequestTimeout = timeout
 
 	// Create a new client instance with the updated config
 	newClient := *lf
-	newClient.config = &newConfig
+	newClient.config = newConfig
 
 	return &newClient
 }
 
+// WithUserID returns a client that pre-sets the given user ID on every trace it creates.
+//
+// The returned client shares the queue, statistics and lifecycle of the original client,
+// so it is cheap to create per request. The user ID can still be overridden per trace
+// with TraceBuilder.WithUserID.
+//
+// Example:
+//
+//	userClient := langfuse.WithUserID(session.UserID)
+//	trace := userClient.Trace("chat-turn") // UserID already set
+func (lf *Langfuse) WithUserID(userID string) *Langfuse {
+	derived := lf.derive()
+	derived.defaultUserID = &userID
+	return derived
+}
+
+// WithSessionID returns a client that pre-sets the given session ID on every trace it creates.
+// Like WithUserID, the returned client shares state with the original client and the
+// session ID can be overridden per trace with TraceBuilder.WithSessionID.
+func (lf *Langfuse) WithSessionID(sessionID string) *Langfuse {
+	derived := lf.derive()
+	derived.defaultSessionID = &sessionID
+	return derived
+}
+
+// derive creates a client sharing this client's resources and trace defaults
+func (lf *Langfuse) derive() *Langfuse {
+	lf.mu.RLock()
+	configCopy := lf.config.Clone()
+	lf.mu.RUnlock()
+
+	return &Langfuse{
+		config:           configCopy,
+		apiClient:        lf.apiClient,
+		queue:            lf.queue,
+		transport:        lf.transport,
+		secondary:        lf.secondary,
+		projects:         lf.projects,
+		stats:            lf.stats,
+		registry:         lf.registry,
+		usage:            lf.usage,
+		sessions:         lf.sessions,
+		recentEvents:     lf.recentEvents,
+		clock:            lf.clock,
+		limiter:          lf.limiter,
+		sampler:          lf.sampler,
+		parent:           lf.root(),
+		defaultUserID:    lf.defaultUserID,
+		defaultSessionID: lf.defaultSessionID,
+	}
+}
+
+// root returns the client that owns the shared queue, statistics and lifecycle
+func (lf *Langfuse) root() *Langfuse {
+	if lf.parent != nil {
+		return lf.parent
+	}
+	return lf
+}
+
 // WithContext returns operations that can be performed with a specific context
 // This is a convenience method for context-aware operations
 func (lf *Langfuse) WithContext(ctx context.Context) *ContextualOperations {
@@ -672,6 +1041,22 @@ type ContextualOperations struct {
 	client *Langfuse
 }
 
+// Trace creates a trace whose metadata is seeded with the values returned by the
+// configured context extractors for the provided context
+func (co *ContextualOperations) Trace(name string) *TraceBuilder {
+	builder := co.client.Trace(name)
+	if co.client.isDisabled() {
+		return builder
+	}
+
+	for _, extract := range co.client.config.ContextExtractors {
+		for key, value := range extract(co.ctx) {
+			builder.AddMetadata(key, value)
+		}
+	}
+	return builder
+}
+
 // Flush flushes queued events using the provided context
 func (co *ContextualOperations) Flush() error {
 	return co.client.Flush(co.ctx)
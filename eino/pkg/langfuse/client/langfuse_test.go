@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/config"
+)
+
+func TestLangfuse_ForkTrace(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/traces/source-trace", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":        "source-trace",
+			"name":      "original",
+			"timestamp": "2024-01-01T00:00:00Z",
+			"input":     map[string]interface{}{"question": "What is Langfuse?"},
+			"metadata":  map[string]interface{}{"experiment": "baseline", "temperature": 0.2},
+		})
+	})
+	lf := newTestLangfuse(t, mux)
+
+	fork, err := lf.ForkTrace(context.Background(), "source-trace", "forked")
+	require.NoError(t, err)
+	require.NotNil(t, fork)
+
+	assert.Equal(t, "forked", fork.GetName())
+	assert.NotEmpty(t, fork.GetID())
+	assert.NotEqual(t, "source-trace", fork.GetID())
+	assert.Equal(t, map[string]interface{}{"question": "What is Langfuse?"}, fork.input)
+	assert.Equal(t, "baseline", fork.metadata["experiment"])
+	assert.Equal(t, 0.2, fork.metadata["temperature"])
+	assert.Equal(t, "source-trace", fork.metadata["forkedFromTraceId"])
+	assert.False(t, fork.submitted)
+
+	// Spans attached to the fork belong to the new trace
+	span := fork.Span("rerun")
+	assert.Equal(t, fork.GetID(), span.traceID)
+}
+
+func TestLangfuse_ForkTrace_Errors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/traces/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"trace not found"}`))
+	})
+	lf := newTestLangfuse(t, mux)
+
+	_, err := lf.ForkTrace(context.Background(), "", "forked")
+	assert.Error(t, err)
+
+	_, err = lf.ForkTrace(context.Background(), "missing", "forked")
+	assert.Error(t, err)
+}
+
+func TestBuilders_QueueErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full", func(t *testing.T) {
+		lf := newTestLangfuse(t, http.NewServeMux(), func(cfg *config.Config) {
+			cfg.QueueSize = 1
+			cfg.FlushAt = 100
+			cfg.RejectWhenQueueFull = true
+		})
+
+		require.NoError(t, lf.Trace("first").Submit(ctx))
+
+		trace := lf.Trace("second")
+		err := trace.End(ctx)
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.Contains(t, err.Error(), trace.GetID())
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		lf := newTestLangfuse(t, http.NewServeMux())
+		trace := lf.Trace("late")
+		span := trace.Span("late-span")
+		generation := trace.Generation("late-generation")
+		require.NoError(t, lf.Shutdown(ctx))
+
+		assert.ErrorIs(t, trace.End(ctx), ErrQueueClosed)
+		assert.ErrorIs(t, span.End(ctx), ErrQueueClosed)
+		assert.ErrorIs(t, generation.End(ctx), ErrQueueClosed)
+	})
+}
+
+func TestLangfuse_WithUserIDAndSessionID(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	userClient := lf.WithUserID("user-1")
+	trace := userClient.Trace("request")
+	assert.Equal(t, "user-1", *trace.userID)
+	assert.Nil(t, trace.sessionID)
+
+	// Defaults chain and can be overridden per trace
+	sessionClient := userClient.WithSessionID("session-1")
+	trace = sessionClient.Trace("request")
+	assert.Equal(t, "user-1", *trace.userID)
+	assert.Equal(t, "session-1", *trace.sessionID)
+
+	trace = sessionClient.Trace("request").WithUserID("user-2").WithSessionID("session-2")
+	assert.Equal(t, "user-2", *trace.userID)
+	assert.Equal(t, "session-2", *trace.sessionID)
+
+	// The original client is left untouched
+	trace = lf.Trace("request")
+	assert.Nil(t, trace.userID)
+	assert.Nil(t, trace.sessionID)
+
+	// Derived clients share the queue and statistics of the original
+	assert.Same(t, lf.queue, sessionClient.queue)
+	assert.Equal(t, int64(4), lf.GetStats().TracesCreated)
+	assert.Equal(t, int64(4), sessionClient.GetStats().TracesCreated)
+
+	// Shutting down the original disables the derived clients
+	require.NoError(t, lf.Shutdown(context.Background()))
+	assert.False(t, userClient.IsEnabled())
+	assert.False(t, sessionClient.IsEnabled())
+}
+
+func TestLangfuse_ScoreNameRegistry(t *testing.T) {
+	var created []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/scores", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		created = append(created, body["name"].(string))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"score-1"}`))
+	})
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.AllowedScoreNames = []string{"accuracy"}
+		cfg.StrictMode = true
+	})
+
+	score := func(name string) *types.Score {
+		return &types.Score{TraceID: "trace-1", Name: name, Value: json.RawMessage("0.9"), DataType: types.ScoreDataTypeNumeric}
+	}
+	require.NoError(t, lf.Score(score("accuracy")))
+
+	err := lf.Score(score("accurracy"))
+	require.ErrorIs(t, err, ErrUnknownScoreName)
+	assert.Contains(t, err.Error(), `did you mean "accuracy"?`)
+
+	require.NoError(t, lf.Score(score("accurracy"), AllowUnregisteredName()))
+	assert.Equal(t, []string{"accuracy", "accurracy"}, created)
+}
+
+// newTestLangfuse creates a client backed by an httptest server running the given handler
+func newTestLangfuse(t testing.TB, handler http.Handler, configure ...func(*config.Config)) *Langfuse {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.RetryCount = 0
+	cfg.BatchRetries = 0
+	cfg.SkipInitialHealthCheck = true
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	lf, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		lf.Shutdown(ctx)
+	})
+
+	return lf
+}
+
+type tenantKey struct{}
+
+func TestContextualOperations_Trace(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		cfg.ContextExtractors = []ContextExtractor{
+			func(ctx context.Context) map[string]interface{} {
+				tenant, _ := ctx.Value(tenantKey{}).(string)
+				return map[string]interface{}{"tenant": tenant, "source": "extractor"}
+			},
+			func(ctx context.Context) map[string]interface{} {
+				return map[string]interface{}{"source": "correlation"}
+			},
+		}
+	})
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	trace := lf.WithContext(ctx).Trace("request").AddMetadata("route", "/orders")
+	require.NoError(t, trace.Submit(ctx))
+
+	body := flushedBodies(t, lf, recorder)["trace-create"]
+	assert.Equal(t, "request", body["name"])
+	assert.Equal(t, map[string]interface{}{
+		"tenant": "acme",
+		"source": "correlation",
+		"route":  "/orders",
+	}, body["metadata"])
+}
+
+func TestWithDefaultTags(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		require.NoError(t, WithDefaultTags("team:platform", "service:agent")(cfg))
+	})
+	ctx := context.Background()
+
+	require.NoError(t, lf.Trace("checkout").Tags("service:agent", "checkout").Submit(ctx))
+	body := flushedBodies(t, lf, recorder)["trace-create"]
+	assert.Equal(t, []interface{}{"team:platform", "service:agent", "checkout"}, body["tags"])
+
+	cfg := DefaultConfig()
+	assert.Error(t, WithDefaultTags("team platform")(cfg))
+	assert.Error(t, WithDefaultTags("")(cfg))
+	assert.Empty(t, cfg.DefaultTags)
+}
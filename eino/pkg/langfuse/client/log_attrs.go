@@ -0,0 +1,60 @@
+package client
+
+import "log/slog"
+
+// Keys of the log attributes returned by LogAttrs and LogFields
+const (
+	LogTraceIDKey       = "trace_id"
+	LogObservationIDKey = "observation_id"
+)
+
+// LogAttrs returns the trace ID as a slog attribute, to correlate log lines with the
+// trace, e.g. logger.With(trace.LogAttrs()...). Builders of a disabled client have no
+// ID and return no attributes.
+func (tb *TraceBuilder) LogAttrs() []slog.Attr {
+	return logAttrs(tb.GetID(), "")
+}
+
+// LogFields returns the attributes of LogAttrs as a map, for loggers other than slog
+func (tb *TraceBuilder) LogFields() map[string]string {
+	return logFields(tb.GetID(), "")
+}
+
+// LogAttrs returns the trace ID and the span ID as observation_id, as slog attributes
+func (sb *SpanBuilder) LogAttrs() []slog.Attr {
+	return logAttrs(sb.GetTraceID(), sb.GetID())
+}
+
+// LogFields returns the attributes of LogAttrs as a map
+func (sb *SpanBuilder) LogFields() map[string]string {
+	return logFields(sb.GetTraceID(), sb.GetID())
+}
+
+// LogAttrs returns the trace ID and the generation ID as observation_id, as slog attributes
+func (gb *GenerationBuilder) LogAttrs() []slog.Attr {
+	return logAttrs(gb.GetTraceID(), gb.GetID())
+}
+
+// LogFields returns the attributes of LogAttrs as a map
+func (gb *GenerationBuilder) LogFields() map[string]string {
+	return logFields(gb.GetTraceID(), gb.GetID())
+}
+
+func logAttrs(traceID, observationID string) []slog.Attr {
+	var attrs []slog.Attr
+	if traceID != "" {
+		attrs = append(attrs, slog.String(LogTraceIDKey, traceID))
+	}
+	if observationID != "" {
+		attrs = append(attrs, slog.String(LogObservationIDKey, observationID))
+	}
+	return attrs
+}
+
+func logFields(traceID, observationID string) map[string]string {
+	fields := make(map[string]string, 2)
+	for _, attr := range logAttrs(traceID, observationID) {
+		fields[attr.Key] = attr.Value.String()
+	}
+	return fields
+}
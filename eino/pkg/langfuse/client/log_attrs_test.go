@@ -0,0 +1,33 @@
+package client
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogAttrs(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	trace := lf.Trace("request")
+	span := trace.Span("retrieve")
+	generation := span.ChildGeneration("llm")
+
+	assert.Equal(t, []slog.Attr{slog.String("trace_id", trace.GetID())}, trace.LogAttrs())
+	assert.Equal(t, map[string]string{"trace_id": trace.GetID()}, trace.LogFields())
+
+	assert.Equal(t, []slog.Attr{
+		slog.String("trace_id", trace.GetID()),
+		slog.String("observation_id", span.GetID()),
+	}, span.LogAttrs())
+	assert.Equal(t, map[string]string{
+		"trace_id":       trace.GetID(),
+		"observation_id": generation.GetID(),
+	}, generation.LogFields())
+}
+
+func TestLogAttrs_Disabled(t *testing.T) {
+	lf := newDisabledClient(DefaultConfig())
+	assert.Empty(t, lf.Trace("request").LogAttrs())
+	assert.Empty(t, lf.Span("retrieve").LogFields())
+}
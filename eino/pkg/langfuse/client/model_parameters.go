@@ -0,0 +1,88 @@
+package client
+
+// ModelParameters holds the sampling parameters of a generation. Nil fields are left out
+// of the recorded modelParameters object.
+type ModelParameters struct {
+	Temperature      *float64
+	TopP             *float64
+	TopK             *int
+	MaxTokens        *int
+	FrequencyPenalty *float64
+	PresencePenalty  *float64
+	StopSequences    []string
+	Seed             *int64
+
+	// ResponseFormat is the requested output format, such as "json" or
+	// map[string]interface{}{"type": "json_object"}
+	ResponseFormat interface{}
+
+	// Extra holds any other provider-specific parameters. Keys that clash with a typed
+	// field are ignored.
+	Extra map[string]interface{}
+}
+
+// validate checks the typed parameters against the ranges accepted by model providers
+func (p ModelParameters) validate() *ValidationError {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return &ValidationError{Field: "modelParameters.temperature", Message: "temperature must be between 0 and 2"}
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return &ValidationError{Field: "modelParameters.top_p", Message: "top_p must be greater than 0 and at most 1"}
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return &ValidationError{Field: "modelParameters.max_tokens", Message: "max_tokens must be positive"}
+	}
+	return nil
+}
+
+// toMap returns the parameters as a modelParameters object, using the same keys as the
+// Temperature, TopP and similar builder methods
+func (p ModelParameters) toMap() map[string]interface{} {
+	params := make(map[string]interface{}, len(p.Extra)+9)
+	for key, value := range p.Extra {
+		params[key] = value
+	}
+	if p.Temperature != nil {
+		params["temperature"] = *p.Temperature
+	}
+	if p.TopP != nil {
+		params["top_p"] = *p.TopP
+	}
+	if p.TopK != nil {
+		params["top_k"] = *p.TopK
+	}
+	if p.MaxTokens != nil {
+		params["max_tokens"] = *p.MaxTokens
+	}
+	if p.FrequencyPenalty != nil {
+		params["frequency_penalty"] = *p.FrequencyPenalty
+	}
+	if p.PresencePenalty != nil {
+		params["presence_penalty"] = *p.PresencePenalty
+	}
+	if p.StopSequences != nil {
+		params["stop_sequences"] = p.StopSequences
+	}
+	if p.Seed != nil {
+		params["seed"] = *p.Seed
+	}
+	if p.ResponseFormat != nil {
+		params["response_format"] = p.ResponseFormat
+	}
+	return params
+}
+
+// WithModelParams sets the model name and its typed parameters, replacing any parameters
+// set before. Out-of-range parameters make Submit, Begin and Update fail.
+func (gb *GenerationBuilder) WithModelParams(name string, params ModelParameters) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithModelParams")
+		return gb
+	}
+	gb.model = &name
+	gb.modelParameters = params.toMap()
+	gb.paramsErr = params.validate()
+	return gb
+}
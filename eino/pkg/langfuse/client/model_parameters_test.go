@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerationBuilder_WithModelParams(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	temperature, topK, maxTokens := 0.7, 40, 1024
+	seed := int64(7)
+
+	generation := NewGenerationBuilder(lf, "trace-1").Name("chat").WithModelParams("claude-sonnet", ModelParameters{
+		Temperature:   &temperature,
+		TopK:          &topK,
+		MaxTokens:     &maxTokens,
+		StopSequences: []string{"\n\nHuman:"},
+		Seed:          &seed,
+		Extra:         map[string]interface{}{"temperature": 1.5, "thinking": "enabled"},
+	})
+	require.NoError(t, generation.Submit(context.Background()))
+
+	body := flushedBodies(t, lf, recorder)["generation-create"]
+	assert.Equal(t, "claude-sonnet", body["model"])
+	assert.Equal(t, map[string]interface{}{
+		"temperature":    0.7,
+		"top_k":          float64(40),
+		"max_tokens":     float64(1024),
+		"stop_sequences": []interface{}{"\n\nHuman:"},
+		"seed":           float64(7),
+		"thinking":       "enabled",
+	}, body["modelParameters"], "only set fields are sent and typed fields win over Extra")
+}
+
+func TestGenerationBuilder_WithModelParams_Validation(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	float := func(f float64) *float64 { return &f }
+	integer := func(i int) *int { return &i }
+
+	tests := []struct {
+		name   string
+		params ModelParameters
+		field  string
+	}{
+		{"temperature lower bound", ModelParameters{Temperature: float(0)}, ""},
+		{"temperature upper bound", ModelParameters{Temperature: float(2)}, ""},
+		{"temperature below range", ModelParameters{Temperature: float(-0.1)}, "modelParameters.temperature"},
+		{"temperature above range", ModelParameters{Temperature: float(2.1)}, "modelParameters.temperature"},
+		{"top_p upper bound", ModelParameters{TopP: float(1)}, ""},
+		{"top_p zero", ModelParameters{TopP: float(0)}, "modelParameters.top_p"},
+		{"top_p above range", ModelParameters{TopP: float(1.01)}, "modelParameters.top_p"},
+		{"max_tokens positive", ModelParameters{MaxTokens: integer(1)}, ""},
+		{"max_tokens zero", ModelParameters{MaxTokens: integer(0)}, "modelParameters.max_tokens"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewGenerationBuilder(lf, "trace-1").Name("chat").WithModelParams("model", tt.params).Submit(context.Background())
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+
+	t.Run("replaced by map parameters", func(t *testing.T) {
+		generation := NewGenerationBuilder(lf, "trace-1").Name("chat").
+			WithModelParams("model", ModelParameters{MaxTokens: integer(0)}).
+			ModelParameters(map[string]interface{}{"max_tokens": 10})
+		assert.NoError(t, generation.Submit(context.Background()))
+	})
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// newPayloadTestLangfuse creates a client recording every ingested event
+func newPayloadTestLangfuse(t *testing.T, configure ...func(cfg *config.Config)) (*Langfuse, *ingestionRecorder) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	return newTestLangfuse(t, mux, configure...), recorder
+}
+
+// recordedBody flushes the client and returns the JSON body of the only event of eventType
+func recordedBody(t *testing.T, lf *Langfuse, recorder *ingestionRecorder, eventType string) string {
+	t.Helper()
+	body, ok := flushedBodies(t, lf, recorder)[eventType]
+	require.True(t, ok, "no %s event was sent", eventType)
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPayloadMode_SerializedBodies(t *testing.T) {
+	start := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	end := start.Add(time.Second)
+
+	const (
+		input    = `{"prompt":"my card number is 4111"}`
+		output   = `"the card is valid"`
+		redacted = `{"redacted":true}`
+	)
+
+	tests := []struct {
+		mode          PayloadMode
+		input, output string
+	}{
+		{mode: "", input: input, output: output},
+		{mode: PayloadModeFull, input: input, output: output},
+		{mode: PayloadModeMetadataOnly, input: redacted, output: redacted},
+		{mode: PayloadModeInputOnly, input: input, output: redacted},
+		{mode: PayloadModeOutputOnly, input: redacted, output: output},
+	}
+
+	for _, tt := range tests {
+		name := string(tt.mode)
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+				cfg.PayloadMode = tt.mode
+			})
+			trace := lf.Trace("checkout").
+				ID("trace-1").
+				Timestamp(start).
+				Input(map[string]interface{}{"prompt": "my card number is 4111"}).
+				Output("the card is valid").
+				Metadata(map[string]interface{}{"step": "verify"})
+			require.NoError(t, trace.EndAt(ctx, end))
+
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"id": "trace-1",
+				"name": "checkout",
+				"input": %s,
+				"output": %s,
+				"metadata": {"step": "verify"},
+				"timestamp": "2024-03-15T10:30:00Z"
+			}`, tt.input, tt.output), recordedBody(t, lf, recorder, "trace-update"))
+
+			lf, recorder = newPayloadTestLangfuse(t, func(cfg *config.Config) {
+				cfg.PayloadMode = tt.mode
+			})
+			span := NewSpanBuilder(lf, "trace-1").
+				Name("lookup").
+				ID("span-1").
+				StartTime(start).
+				Input(map[string]interface{}{"prompt": "my card number is 4111"}).
+				Output("the card is valid")
+			require.NoError(t, span.EndAt(ctx, end))
+
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"id": "span-1",
+				"traceId": "trace-1",
+				"type": "SPAN",
+				"name": "lookup",
+				"startTime": "2024-03-15T10:30:00Z",
+				"endTime": "2024-03-15T10:30:01Z",
+				"input": %s,
+				"output": %s,
+				"level": "DEFAULT"
+			}`, tt.input, tt.output), recordedBody(t, lf, recorder, "span-update"))
+		})
+	}
+}
+
+func TestPayloadMode_RedactsEveryEvent(t *testing.T) {
+	ctx := context.Background()
+	formatterCalls := 0
+	lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		cfg.PayloadMode = PayloadModeMetadataOnly
+		cfg.OutputFormatter = func(output interface{}) interface{} {
+			formatterCalls++
+			return output
+		}
+	})
+
+	trace := lf.Trace("agent").Input("secret question")
+	require.NoError(t, trace.Submit(ctx))
+
+	generation := trace.Generation("llm").Input("secret prompt").Output("secret answer")
+	require.NoError(t, generation.Submit(ctx))
+
+	bodies := flushedBodies(t, lf, recorder)
+	assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["trace-create"]["input"])
+	assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["generation-create"]["input"])
+	assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["generation-create"]["output"])
+	assert.Zero(t, formatterCalls, "withheld outputs are not formatted")
+
+	t.Run("unset payloads stay unset", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+			cfg.PayloadMode = PayloadModeMetadataOnly
+		})
+		require.NoError(t, lf.Trace("empty").Submit(ctx))
+
+		body := flushedBodies(t, lf, recorder)["trace-create"]
+		assert.NotContains(t, body, "input")
+		assert.NotContains(t, body, "output")
+	})
+}
+
+func TestPayloadMode_Precedence(t *testing.T) {
+	tests := []struct {
+		name            string
+		configMode      PayloadMode
+		traceMode       PayloadMode
+		overrideAllowed bool
+		expected        PayloadMode
+	}{
+		{name: "config only", configMode: PayloadModeInputOnly, expected: PayloadModeInputOnly},
+		{name: "trace narrows config", configMode: PayloadModeFull, traceMode: PayloadModeMetadataOnly, expected: PayloadModeMetadataOnly},
+		{name: "trace cannot widen config", configMode: PayloadModeMetadataOnly, traceMode: PayloadModeFull, expected: PayloadModeMetadataOnly},
+		{name: "disjoint modes withhold both", configMode: PayloadModeInputOnly, traceMode: PayloadModeOutputOnly, expected: PayloadModeMetadataOnly},
+		{name: "trace narrows one side", configMode: PayloadModeOutputOnly, traceMode: PayloadModeFull, expected: PayloadModeOutputOnly},
+		{name: "override widens", configMode: PayloadModeMetadataOnly, traceMode: PayloadModeFull, overrideAllowed: true, expected: PayloadModeFull},
+		{name: "override narrows", configMode: PayloadModeFull, traceMode: PayloadModeInputOnly, overrideAllowed: true, expected: PayloadModeInputOnly},
+		{name: "override without trace mode", configMode: PayloadModeOutputOnly, overrideAllowed: true, expected: PayloadModeOutputOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := newTestLangfuse(t, http.NewServeMux(), func(cfg *config.Config) {
+				cfg.PayloadMode = tt.configMode
+				cfg.PayloadModeOverrideAllowed = tt.overrideAllowed
+			})
+			assert.Equal(t, tt.expected, lf.payloadMode(tt.traceMode))
+		})
+	}
+}
+
+func TestTraceBuilder_WithPayloadMode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("inherited by spans and generations", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		trace := lf.Trace("sensitive").WithPayloadMode(PayloadModeMetadataOnly).Input("question")
+		span := trace.Span("retrieve").Input("query")
+		generation := span.ChildGeneration("answer").Output("reply")
+		require.NoError(t, trace.Submit(ctx))
+		require.NoError(t, span.Submit(ctx))
+		require.NoError(t, generation.Submit(ctx))
+
+		bodies := flushedBodies(t, lf, recorder)
+		assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["trace-create"]["input"])
+		assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["span-create"]["input"])
+		assert.Equal(t, map[string]interface{}{"redacted": true}, bodies["generation-create"]["output"])
+
+		other := lf.Trace("regular").Input("question")
+		assert.Equal(t, "question", other.toTraceEvent().Input, "other traces keep the configured mode")
+	})
+
+	t.Run("exception from a restrictive config", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+			cfg.PayloadMode = PayloadModeMetadataOnly
+			cfg.PayloadModeOverrideAllowed = true
+		})
+		require.NoError(t, lf.Trace("debugging").WithPayloadMode(PayloadModeFull).Input("question").Submit(ctx))
+
+		assert.Equal(t, "question", flushedBodies(t, lf, recorder)["trace-create"]["input"])
+	})
+
+	t.Run("unknown mode is rejected", func(t *testing.T) {
+		lf := newTestLangfuse(t, http.NewServeMux())
+		trace := lf.Trace("typo").WithPayloadMode("none").Input("question")
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, trace.Submit(ctx), &validationErr)
+		assert.Equal(t, "payloadMode", validationErr.Field)
+		assert.Equal(t, map[string]interface{}{"redacted": true}, trace.toTraceEvent().Input)
+	})
+}
+
+func TestConfig_PayloadMode(t *testing.T) {
+	t.Run("option", func(t *testing.T) {
+		cfg := DefaultConfig()
+		assert.Equal(t, PayloadModeFull, cfg.PayloadMode)
+
+		require.NoError(t, WithPayloadMode(PayloadModeOutputOnly)(cfg))
+		assert.Equal(t, PayloadModeOutputOnly, cfg.PayloadMode)
+
+		require.NoError(t, WithPayloadModeOverrideAllowed(true)(cfg))
+		assert.True(t, cfg.PayloadModeOverrideAllowed)
+
+		err := WithPayloadMode("redacted")(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "payloadMode")
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		defer os.Unsetenv("LANGFUSE_PAYLOAD_MODE")
+
+		os.Setenv("LANGFUSE_PAYLOAD_MODE", "Metadata-Only")
+		cfg := DefaultConfig()
+		require.NoError(t, cfg.LoadFromEnvironment())
+		assert.Equal(t, PayloadModeMetadataOnly, cfg.PayloadMode)
+
+		os.Setenv("LANGFUSE_PAYLOAD_MODE", "nothing")
+		cfg = DefaultConfig()
+		cfg.PublicKey = "pk-test"
+		cfg.SecretKey = "sk-test"
+		require.NoError(t, cfg.LoadFromEnvironment())
+		errs := cfg.Validate()
+		require.NotNil(t, errs)
+		assert.Contains(t, errs.Error(), "payloadMode")
+	})
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// PIIType identifies a category of personally identifiable information
+type PIIType = utils.PIIType
+
+// Re-exported PII types
+const (
+	EmailAddress = utils.EmailAddress
+	PhoneNumber  = utils.PhoneNumber
+	CreditCard   = utils.CreditCard
+	SSN          = utils.SSN
+	IPAddress    = utils.IPAddress
+)
+
+// PIIDetectionMiddleware returns event middleware that scans the input and output of every
+// ingestion event for PII and calls notify with the detected types. Events are passed
+// through unchanged, so detection can feed audit logging without blocking submission.
+//
+// Example:
+//
+//	lf, err := client.NewWithOptions(
+//		client.WithCredentials(publicKey, secretKey),
+//		client.WithEventMiddleware(client.PIIDetectionMiddleware(func(event ingestiontypes.IngestionEvent, found []client.PIIType) {
+//			log.Printf("event %s contains PII: %v", event.ID, found)
+//		})),
+//	)
+func PIIDetectionMiddleware(notify func(ingestiontypes.IngestionEvent, []PIIType)) EventMiddleware {
+	return func(event ingestiontypes.IngestionEvent) ingestiontypes.IngestionEvent {
+		if notify == nil {
+			return event
+		}
+
+		if found := detectEventPII(event); len(found) > 0 {
+			notify(event, found)
+		}
+		return event
+	}
+}
+
+// detectEventPII returns the PII types found in an event body's input and output fields
+func detectEventPII(event ingestiontypes.IngestionEvent) []PIIType {
+	if event.Body == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(event.Body)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	var text string
+	for _, key := range []string{"input", "output"} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		// Decode plain strings so JSON escaping doesn't interfere with pattern matching
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			text += s + "\n"
+		} else {
+			text += string(raw) + "\n"
+		}
+	}
+
+	return utils.ExtractPIITypes(text)
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func TestPIIDetectionMiddleware(t *testing.T) {
+	var notified []PIIType
+	var notifiedEvent ingestiontypes.IngestionEvent
+	calls := 0
+
+	middleware := PIIDetectionMiddleware(func(event ingestiontypes.IngestionEvent, found []PIIType) {
+		calls++
+		notifiedEvent = event
+		notified = found
+	})
+
+	trace := NewTraceBuilder(nil).
+		Name("support-chat").
+		Input(map[string]interface{}{"message": "my email is jane@example.com"}).
+		Output("we will call you at (555) 123-4567").
+		Metadata(map[string]interface{}{"note": "ssn 123-45-6789 in metadata is ignored"})
+	event := trace.toTraceCreateEvent().ToIngestionEvent()
+
+	result := middleware(event)
+
+	assert.Equal(t, event, result, "events pass through unchanged")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, event.ID, notifiedEvent.ID)
+	assert.Equal(t, []PIIType{EmailAddress, PhoneNumber}, notified)
+
+	// Clean events do not trigger the callback
+	clean := NewTraceBuilder(nil).Name("clean").Input("hello").toTraceCreateEvent().ToIngestionEvent()
+	middleware(clean)
+	assert.Equal(t, 1, calls)
+
+	// A nil callback is tolerated
+	assert.Equal(t, event, PIIDetectionMiddleware(nil)(event))
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"eino/pkg/langfuse/api"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// ProjectStats counts the batches sent to one Langfuse project when environments are
+// routed to projects with WithProjectCredentials
+type ProjectStats struct {
+	// BatchesSubmitted is the number of batches the project accepted
+	BatchesSubmitted int64 `json:"batchesSubmitted"`
+
+	// BatchesFailed is the number of batches the project rejected or that could not be
+	// sent to it, counting every attempt
+	BatchesFailed int64 `json:"batchesFailed"`
+
+	// EventsSubmitted is the number of events in accepted batches
+	EventsSubmitted int64 `json:"eventsSubmitted"`
+
+	// EventsFailed is the number of events in failed batches
+	EventsFailed int64 `json:"eventsFailed"`
+}
+
+// projectTransport sends every event with the credentials of the project of its
+// environment. The queue already batches projects separately; batches mixing projects,
+// such as those of a transaction, are split.
+type projectTransport struct {
+	defaultTransport IngestionTransport
+	projects         map[string]IngestionTransport
+	apiClients       []*api.APIClient // clients of the projects, closed on shutdown
+
+	// logf reports events of environments without project credentials (default log.Printf)
+	logf func(format string, args ...interface{})
+
+	mu     sync.Mutex
+	stats  map[string]*ProjectStats
+	warned map[string]bool
+}
+
+// newProjectTransport creates the REST ingestion client of every configured project.
+// Events of the default project go to defaultTransport.
+func newProjectTransport(cfg *config.Config, defaultTransport IngestionTransport) (*projectTransport, error) {
+	t := &projectTransport{
+		defaultTransport: defaultTransport,
+		projects:         make(map[string]IngestionTransport, len(cfg.Projects)),
+		stats:            make(map[string]*ProjectStats, len(cfg.Projects)+1),
+		warned:           make(map[string]bool),
+	}
+
+	for environment, credentials := range cfg.Projects {
+		projectConfig := cfg.Clone()
+		projectConfig.PublicKey = credentials.PublicKey
+		projectConfig.SecretKey = credentials.SecretKey
+		projectConfig.IngestionTransport = nil
+		projectConfig.SkipInitialHealthCheck = true
+		projectConfig.RequireHealthyStart = false
+
+		apiClient, err := api.NewAPIClient(projectConfig)
+		if err != nil {
+			t.close()
+			return nil, fmt.Errorf("failed to create API client of project %s: %w", environment, err)
+		}
+		t.projects[environment] = apiClient.Ingestion
+		t.apiClients = append(t.apiClients, apiClient)
+	}
+	return t, nil
+}
+
+// close closes the API clients of the projects
+func (t *projectTransport) close() error {
+	var errs []error
+	for _, apiClient := range t.apiClients {
+		if err := apiClient.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *projectTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	groups, order := t.groupByProject(events)
+	if len(order) == 1 {
+		return t.submit(ctx, order[0], groups[order[0]])
+	}
+
+	merged := &ingestiontypes.IngestionResponse{Success: true}
+	var errs []error
+	for _, project := range order {
+		response, err := t.submit(ctx, project, groups[project])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+		}
+		if response == nil || !response.Success {
+			merged.Success = false
+		}
+		if response != nil {
+			merged.Errors = append(merged.Errors, response.Errors...)
+			merged.Timestamp = response.Timestamp
+		}
+	}
+	return merged, errors.Join(errs...)
+}
+
+// groupByProject splits events by the project they are sent to, returning the projects
+// in the order they first appear
+func (t *projectTransport) groupByProject(events []ingestiontypes.IngestionEvent) (map[string][]ingestiontypes.IngestionEvent, []string) {
+	groups := make(map[string][]ingestiontypes.IngestionEvent)
+	var order []string
+	for _, event := range events {
+		project := t.projectOf(event)
+		if _, ok := groups[project]; !ok {
+			order = append(order, project)
+		}
+		groups[project] = append(groups[project], event)
+	}
+	return groups, order
+}
+
+// projectOf returns the project an event is sent to, warning once per environment that
+// has no project credentials
+func (t *projectTransport) projectOf(event ingestiontypes.IngestionEvent) string {
+	if event.Project == "" {
+		return DefaultProject
+	}
+	if _, ok := t.projects[event.Project]; ok {
+		return event.Project
+	}
+
+	t.mu.Lock()
+	warn := !t.warned[event.Project]
+	t.warned[event.Project] = true
+	t.mu.Unlock()
+	if warn {
+		logf := t.logf
+		if logf == nil {
+			logf = log.Printf
+		}
+		logf("langfuse: no project credentials for environment %q, sending its events to the %s project", event.Project, DefaultProject)
+	}
+	return DefaultProject
+}
+
+// submit sends events to a project, recording the outcome
+func (t *projectTransport) submit(ctx context.Context, project string, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	transport := t.defaultTransport
+	if project != DefaultProject {
+		transport = t.projects[project]
+	}
+	response, err := transport.SubmitBatch(ctx, events)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats[project]
+	if stats == nil {
+		stats = &ProjectStats{}
+		t.stats[project] = stats
+	}
+	if err == nil && response != nil && response.Success {
+		stats.BatchesSubmitted++
+		stats.EventsSubmitted += int64(len(events))
+	} else {
+		stats.BatchesFailed++
+		stats.EventsFailed += int64(len(events))
+	}
+	return response, err
+}
+
+// snapshot returns a copy of the statistics of the projects events were sent to
+func (t *projectTransport) snapshot() map[string]ProjectStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make(map[string]ProjectStats, len(t.stats))
+	for project, s := range t.stats {
+		stats[project] = *s
+	}
+	return stats
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// projectRecorder records the ingestion batches of every project by public key
+type projectRecorder struct {
+	mu        sync.Mutex
+	recorders map[string]*ingestionRecorder
+	batches   map[string]int
+}
+
+func (p *projectRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	publicKey, _, _ := req.BasicAuth()
+
+	p.mu.Lock()
+	recorder := p.recorders[publicKey]
+	if recorder == nil {
+		recorder = &ingestionRecorder{}
+		p.recorders[publicKey] = recorder
+	}
+	p.batches[publicKey]++
+	p.mu.Unlock()
+
+	recorder.ServeHTTP(w, req)
+}
+
+// traceNames returns the names of the traces received with publicKey
+func (p *projectRecorder) traceNames(publicKey string) []string {
+	p.mu.Lock()
+	recorder := p.recorders[publicKey]
+	p.mu.Unlock()
+	if recorder == nil {
+		return nil
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	var names []string
+	for _, event := range recorder.events {
+		if name, ok := event["body"].(map[string]interface{})["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func newProjectTestLangfuse(t *testing.T) (*Langfuse, *projectRecorder, *[]string) {
+	recorder := &projectRecorder{recorders: make(map[string]*ingestionRecorder), batches: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		require.NoError(t, WithProjectCredentials("staging", "pk-lf-staging", "sk-lf-staging")(cfg))
+	})
+	var logged []string
+	lf.projects.logf = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+	return lf, recorder, &logged
+}
+
+func TestProjects_RouteByEnvironment(t *testing.T) {
+	lf, recorder, logged := newProjectTestLangfuse(t)
+	ctx := context.Background()
+	defaultKey := lf.config.PublicKey
+
+	staging := lf.Trace("staging-trace").WithEnvironment("staging")
+	require.NoError(t, staging.Submit(ctx))
+	require.NoError(t, staging.Span("staging-span").Submit(ctx))
+	require.NoError(t, lf.Trace("default-trace").Submit(ctx))
+	require.NoError(t, lf.Trace("production-trace").WithEnvironment("production").Submit(ctx))
+	require.NoError(t, lf.Trace("production-trace-2").WithEnvironment("production").Submit(ctx))
+	require.NoError(t, lf.ScoreAsync(&types.Score{TraceID: staging.GetID(), Name: "staging-score", Value: json.RawMessage("1"), DataType: types.ScoreDataTypeNumeric}, ScoreEnvironment("staging")))
+	require.NoError(t, lf.ScoreAsync(&types.Score{TraceID: staging.GetID(), Name: "default-score", Value: json.RawMessage("1"), DataType: types.ScoreDataTypeNumeric}))
+	require.NoError(t, lf.Flush(ctx))
+
+	assert.ElementsMatch(t, []string{"staging-trace", "staging-span", "staging-score"}, recorder.traceNames("pk-lf-staging"))
+	assert.ElementsMatch(t, []string{"default-trace", "production-trace", "production-trace-2", "default-score"}, recorder.traceNames(defaultKey))
+
+	require.Len(t, *logged, 1, "environments without credentials are reported once")
+	assert.Contains(t, (*logged)[0], `"production"`)
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(7), stats.EventsSubmitted)
+	require.Contains(t, stats.Projects, "staging")
+	require.Contains(t, stats.Projects, DefaultProject)
+	assert.Equal(t, int64(3), stats.Projects["staging"].EventsSubmitted)
+	assert.Equal(t, int64(4), stats.Projects[DefaultProject].EventsSubmitted)
+	assert.Equal(t, int64(recorder.batches["pk-lf-staging"]), stats.Projects["staging"].BatchesSubmitted)
+	assert.Zero(t, stats.Projects["staging"].BatchesFailed)
+}
+
+func TestProjects_EnvironmentIsSentAndInherited(t *testing.T) {
+	lf, recorder, _ := newProjectTestLangfuse(t)
+	ctx := context.Background()
+
+	trace := lf.Trace("chat").WithEnvironment("staging")
+	generation := trace.Span("retrieve").ChildGeneration("llm")
+	require.NoError(t, generation.Submit(ctx))
+	require.NoError(t, trace.Submit(ctx))
+	require.NoError(t, lf.Flush(ctx))
+
+	recorder.mu.Lock()
+	staging := recorder.recorders["pk-lf-staging"]
+	recorder.mu.Unlock()
+	require.NotNil(t, staging)
+
+	staging.mu.Lock()
+	defer staging.mu.Unlock()
+	require.Len(t, staging.events, 2)
+	for _, event := range staging.events {
+		assert.Equal(t, "staging", event["body"].(map[string]interface{})["environment"])
+	}
+}
+
+func TestProjects_ShutdownClosesProjectClients(t *testing.T) {
+	lf, _, _ := newProjectTestLangfuse(t)
+	apiClients := lf.projects.apiClients
+	require.Len(t, apiClients, 1)
+
+	require.NoError(t, lf.Shutdown(context.Background()))
+	assert.True(t, apiClients[0].IsClosed())
+}
+
+func TestProjects_SplitsMixedBatches(t *testing.T) {
+	lf, _, _ := newProjectTestLangfuse(t)
+	primary := &memoryTransport{}
+	lf.projects.defaultTransport = primary
+	staging := &memoryTransport{}
+	lf.projects.projects["staging"] = staging
+
+	events := []ingestiontypes.IngestionEvent{
+		{ID: "event-1"},
+		{ID: "event-2", Project: "staging"},
+		{ID: "event-3"},
+	}
+	response, err := lf.projects.SubmitBatch(context.Background(), events)
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+
+	require.Len(t, primary.events, 2)
+	assert.Equal(t, "event-3", primary.events[1].ID)
+	require.Len(t, staging.events, 1)
+	assert.Equal(t, "event-2", staging.events[0].ID)
+	assert.Equal(t, int64(1), lf.GetStats().Projects["staging"].BatchesSubmitted)
+}
+
+func TestProjects_NotConfigured(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	require.NoError(t, lf.Trace("chat").WithEnvironment("staging").Submit(context.Background()))
+	require.NoError(t, lf.Flush(context.Background()))
+
+	assert.Nil(t, lf.projects)
+	assert.Nil(t, lf.GetStats().Projects)
+}
+
+func TestProjects_SDKInfoRedactsCredentials(t *testing.T) {
+	lf, _, _ := newProjectTestLangfuse(t)
+
+	projects, ok := lf.GetSDKInfo().ConfigSummary["Projects"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"PublicKey": redactedValue, "SecretKey": redactedValue}, projects["staging"])
+}
+
+func TestWithProjectCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, WithProjectCredentials("staging", "pk-lf-staging", "sk-lf-staging")(cfg))
+	assert.Equal(t, ProjectCredentials{PublicKey: "pk-lf-staging", SecretKey: "sk-lf-staging"}, cfg.Projects["staging"])
+
+	tests := []struct {
+		name                              string
+		environment, publicKey, secretKey string
+	}{
+		{"empty environment", "", "pk-lf-a", "sk-lf-a"},
+		{"default environment", DefaultProject, "pk-lf-a", "sk-lf-a"},
+		{"invalid public key", "staging", "invalid", "sk-lf-a"},
+		{"invalid secret key", "staging", "pk-lf-a", "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, WithProjectCredentials(tt.environment, tt.publicKey, tt.secretKey)(DefaultConfig()))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"math"
+
+	"golang.org/x/time/rate"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// WithRateLimit returns a client sharing this client's resources whose Trace, Span and
+// Generation calls create at most rps builders per second, with bursts of up to one
+// second's worth. Calls over the limit return a no-op builder immediately and are
+// counted in ClientStats.RateLimitedEvents. A no-op builder has an ID but sends nothing:
+// ending it and its spans and generations succeeds, and EndAndURL returns an empty URL. Clients derived from the returned one share
+// its limit. A non-positive rps returns a client without a limit.
+//
+// Example:
+//
+//	limited := lf.WithRateLimit(100)
+//	trace := limited.Trace("handle-request") // no-op beyond 100 traces per second
+func (lf *Langfuse) WithRateLimit(rps float64) *Langfuse {
+	derived := lf.derive()
+	if rps <= 0 {
+		derived.limiter = nil
+		return derived
+	}
+
+	burst := max(1, int(math.Ceil(rps)))
+	derived.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	return derived
+}
+
+// allowBuilder reports whether the rate limit, if any, lets a new builder be created,
+// counting the calls it rejects
+func (lf *Langfuse) allowBuilder() bool {
+	if lf.limiter == nil || lf.limiter.Allow() {
+		return true
+	}
+
+	root := lf.root()
+	root.stats.rateLimitedEvents.Add(1)
+	return false
+}
+
+// newNoopTraceBuilder returns the builder of a rate-limited Trace call: it sends nothing,
+// and ending it or any span or generation created from it succeeds. It still has an ID,
+// so log fields and IDs passed downstream stay consistent.
+func newNoopTraceBuilder(name string) *TraceBuilder {
+	return &TraceBuilder{id: utils.GenerateTraceID(), name: name, noop: true}
+}
+
+// newNoopSpanBuilder returns the builder of a rate-limited span of traceID
+func newNoopSpanBuilder(traceID, name string) *SpanBuilder {
+	return &SpanBuilder{id: utils.GenerateObservationID(), traceID: traceID, name: name, noop: true}
+}
+
+// newNoopGenerationBuilder returns the builder of a rate-limited generation of traceID
+func newNoopGenerationBuilder(traceID, name string) *GenerationBuilder {
+	return &GenerationBuilder{id: utils.GenerateObservationID(), traceID: traceID, name: name, noop: true}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLangfuse_WithRateLimit(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	// A rate of 0.001 per second allows a burst of one builder and then no more during
+	// the test
+	limited := lf.WithRateLimit(0.001)
+	require.NoError(t, limited.Trace("allowed").End(ctx))
+
+	// Rate-limited builders and their children succeed without sending anything
+	trace := limited.Trace("limited-trace").Input("question")
+	assert.NoError(t, trace.Begin(ctx))
+	assert.NoError(t, trace.Update(ctx))
+	span := trace.Span("limited-child-span")
+	assert.NoError(t, span.ChildSpan("limited-grandchild").End(ctx))
+	assert.NoError(t, span.ChildGeneration("limited-grandchild").UsageTokens(10, 5).Submit(ctx))
+	assert.NoError(t, span.End(ctx))
+	assert.NoError(t, trace.Generation("limited-child-generation").EndAt(ctx, time.Now()))
+	assert.NoError(t, trace.End(ctx))
+
+	// They still have IDs, shared with their children
+	assert.NotEmpty(t, trace.GetID())
+	assert.Equal(t, trace.GetID(), trace.LogFields()[LogTraceIDKey])
+	assert.Equal(t, trace.GetID(), span.GetTraceID())
+	assert.NotEmpty(t, span.GetID())
+	assert.Equal(t, trace.GetID(), span.ChildGeneration("limited-grandchild").LogFields()[LogTraceIDKey])
+
+	url, err := limited.Trace("limited-url").EndAndURL(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, url)
+
+	assert.NoError(t, limited.Span("limited-span").Submit(ctx))
+	assert.NoError(t, limited.Generation("limited-generation").Begin(ctx))
+	assert.NoError(t, limited.WithUserID("user-1").Trace("limited-derived").EndAt(ctx, time.Now()))
+
+	// The original client is not limited
+	require.NoError(t, lf.Trace("unlimited").End(ctx))
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(5), stats.RateLimitedEvents, "children of a rate-limited trace are not counted again")
+	assert.Equal(t, int64(2), stats.TracesCreated)
+	assert.Zero(t, stats.SpansCreated)
+	assert.Zero(t, stats.GenerationsCreated)
+	assert.Empty(t, lf.UnendedBuilders())
+
+	bodies := flushedBodies(t, lf, recorder)
+	require.Len(t, recorder.events, 2)
+	assert.Equal(t, "unlimited", bodies["trace-update"]["name"])
+}
+
+func TestLangfuse_WithRateLimit_Burst(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+
+	limited := lf.WithRateLimit(2.5)
+	for i := 0; i < 10; i++ {
+		limited.Generation("llm")
+	}
+
+	stats := lf.GetStats()
+	assert.Equal(t, int64(3), stats.GenerationsCreated, "bursts of up to one second's worth are allowed")
+	assert.Equal(t, int64(7), stats.RateLimitedEvents)
+}
+
+func TestLangfuse_WithRateLimit_NonPositive(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+
+	unlimited := lf.WithRateLimit(0)
+	for i := 0; i < 100; i++ {
+		unlimited.Span("retrieve")
+	}
+
+	stats := lf.GetStats()
+	assert.Zero(t, stats.RateLimitedEvents)
+	assert.Equal(t, int64(100), stats.SpansCreated)
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"sync"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// eventRing keeps the most recently enqueued events, overwriting the oldest once full
+type eventRing struct {
+	mu     sync.Mutex
+	events []types.IngestionEvent
+	next   int
+	full   bool
+}
+
+func newEventRing(size int) *eventRing {
+	return &eventRing{events: make([]types.IngestionEvent, size)}
+}
+
+// add records an event, replacing the oldest one when the ring is full
+func (r *eventRing) add(event types.IngestionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = event
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the recorded events, oldest first
+func (r *eventRing) snapshot() []types.IngestionEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]types.IngestionEvent(nil), r.events[:r.next]...)
+	}
+	events := make([]types.IngestionEvent, 0, len(r.events))
+	events = append(events, r.events[r.next:]...)
+	return append(events, r.events[:r.next]...)
+}
+
+// RecentEvents returns the last events added to the queue, oldest first, after event
+// middleware has run. The number of events kept is set by WithDebugRingBuffer; without
+// it RecentEvents returns nil.
+//
+// The buffer is meant for in-process debugging, e.g. behind an admin endpoint; it is
+// also included in DebugSnapshot.
+func (lf *Langfuse) RecentEvents() []types.IngestionEvent {
+	if lf.recentEvents == nil {
+		return nil
+	}
+	return lf.recentEvents.snapshot()
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// eventBodyIDs returns the "id" field of each event body
+func eventBodyIDs(t *testing.T, events []types.IngestionEvent) []string {
+	t.Helper()
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		raw, err := json.Marshal(event.Body)
+		require.NoError(t, err)
+		var body struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &body))
+		ids = append(ids, body.ID)
+	}
+	return ids
+}
+
+func TestLangfuse_RecentEvents(t *testing.T) {
+	lf := newTestLangfuse(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		require.NoError(t, config.WithDebugRingBuffer(3)(cfg))
+		cfg.FlushAt = 100
+		cfg.FlushInterval = time.Hour
+	})
+
+	assert.Empty(t, lf.RecentEvents())
+
+	for i := 1; i <= 7; i++ {
+		require.NoError(t, lf.Trace("recent").ID(fmt.Sprintf("trace-%d", i)).Submit(context.Background()))
+	}
+
+	recent := lf.RecentEvents()
+	require.Len(t, recent, 3)
+	assert.Equal(t, []string{"trace-5", "trace-6", "trace-7"}, eventBodyIDs(t, recent))
+
+	t.Run("returned slice is a copy", func(t *testing.T) {
+		recent[0] = types.IngestionEvent{}
+		assert.Equal(t, []string{"trace-5", "trace-6", "trace-7"}, eventBodyIDs(t, lf.RecentEvents()))
+	})
+
+	t.Run("shared with derived clients and the debug handler", func(t *testing.T) {
+		require.NoError(t, lf.WithUserID("user-1").Trace("recent").ID("trace-8").Submit(context.Background()))
+		assert.Equal(t, []string{"trace-6", "trace-7", "trace-8"}, eventBodyIDs(t, lf.RecentEvents()))
+
+		rec := httptest.NewRecorder()
+		lf.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/langfuse", nil))
+		var snapshot struct {
+			RecentEvents []map[string]interface{} `json:"recentEvents"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+		assert.Len(t, snapshot.RecentEvents, 3)
+	})
+}
+
+func TestLangfuse_RecentEvents_Disabled(t *testing.T) {
+	lf := newTestLangfuse(t, http.NotFoundHandler())
+	require.NoError(t, lf.Trace("not-recorded").Submit(context.Background()))
+
+	assert.Nil(t, lf.RecentEvents())
+	assert.Nil(t, lf.DebugSnapshot().RecentEvents)
+}
+
+func TestEventRing_Concurrent(t *testing.T) {
+	ring := newEventRing(10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ring.add(types.IngestionEvent{ID: "event"})
+				ring.snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, ring.snapshot(), 10)
+}
+
+func TestLangfuse_EventSources(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		require.NoError(t, config.WithDebugRingBuffer(10)(cfg))
+		cfg.FlushInterval = time.Hour
+	})
+	ctx := context.Background()
+
+	trace := lf.Trace("chat")
+	require.NoError(t, trace.Submit(ctx))
+	require.NoError(t, trace.Span("retrieve").End(ctx))
+	require.NoError(t, trace.Generation("llm").End(ctx))
+	require.NoError(t, lf.ScoreAsync(&commonTypes.Score{
+		TraceID:  trace.GetID(),
+		Name:     "accuracy",
+		Value:    json.RawMessage(`1`),
+		DataType: commonTypes.ScoreDataTypeNumeric,
+	}))
+
+	var generations []types.EventType
+	for _, event := range lf.RecentEvents() {
+		if event.Source == types.EventSourceGeneration {
+			generations = append(generations, event.Type)
+		}
+	}
+	assert.Equal(t, []types.EventType{types.EventTypeGenerationUpdate}, generations)
+
+	assert.Equal(t, map[string]int64{
+		types.EventSourceTrace:      1,
+		types.EventSourceSpan:       1,
+		types.EventSourceGeneration: 1,
+		types.EventSourceScore:      1,
+	}, lf.DebugSnapshot().Queue.EventsBySource)
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestConfig_RetryAttempts(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		retryCount, batchRetries, maxAttempt int
+		wantHTTP, wantBatch                  int
+	}{
+		{"defaults", 3, 1, 8, 3, 1},
+		{"no cap", 3, 4, 0, 3, 4},
+		{"cap reduces batch retries", 3, 4, 10, 3, 1},
+		{"cap below one request's retries", 5, 2, 3, 2, 0},
+		{"single attempt", 3, 1, 1, 0, 0},
+		{"no retries", 0, 0, 8, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.RetryCount = tt.retryCount
+			cfg.BatchRetries = tt.batchRetries
+			cfg.MaxBatchAttempts = tt.maxAttempt
+
+			httpRetries, batchRetries := cfg.RetryAttempts()
+			assert.Equal(t, tt.wantHTTP, httpRetries)
+			assert.Equal(t, tt.wantBatch, batchRetries)
+			if tt.maxAttempt > 0 {
+				assert.LessOrEqual(t, (httpRetries+1)*(batchRetries+1), tt.maxAttempt)
+			}
+		})
+	}
+}
+
+func TestLangfuse_BatchAttempts(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		retryCount, batchRetries, maxAttempts int
+		wantRequests                          int64
+	}{
+		{"http and batch retries multiply", 2, 1, 0, 6},
+		{"batch retries only", 0, 2, 0, 3},
+		{"http retries only", 2, 0, 0, 3},
+		{"combined cap", 2, 3, 7, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			lf := newTestLangfuse(t, handler, func(cfg *config.Config) {
+				cfg.RetryCount = tt.retryCount
+				cfg.RetryDelay = time.Millisecond
+				cfg.MaxRetryDelay = time.Millisecond
+				cfg.RetryWaitTime = time.Millisecond
+				cfg.BatchRetries = tt.batchRetries
+				cfg.MaxBatchAttempts = tt.maxAttempts
+			})
+
+			require.NoError(t, lf.Trace("checkout").Submit(context.Background()))
+			require.NoError(t, lf.Flush(context.Background()))
+
+			assert.Equal(t, tt.wantRequests, requests.Load())
+			assert.Equal(t, int64(1), lf.GetStats().EventsFailed)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// samplingDecisionCacheSize caps the trace decisions kept so the spans and generations
+// of a trace get the decision of its rule; the oldest are forgotten first
+const samplingDecisionCacheSize = 10000
+
+// SamplingStats counts the sampling decisions made on traces
+type SamplingStats struct {
+	// Rules counts the traces of each sampling rule, in the order the rules were configured
+	Rules []SamplingRuleStats `json:"rules"`
+
+	// Fallback counts the traces that matched no rule and were sampled with SampleRate
+	Fallback SamplingRuleStats `json:"fallback"`
+}
+
+// SamplingRuleStats counts the traces a sampling rule decided on
+type SamplingRuleStats struct {
+	Matched int64 `json:"matched"`
+	Kept    int64 `json:"kept"`
+	Dropped int64 `json:"dropped"`
+}
+
+// samplingTarget holds the trace fields sampling rules match on
+type samplingTarget struct {
+	name        string
+	tags        []string
+	userID      string
+	environment string
+}
+
+// samplingRule is a configured rule with its name pattern compiled
+type samplingRule struct {
+	SamplingRule
+	name *regexp.Regexp
+}
+
+func (r *samplingRule) matches(target *samplingTarget) bool {
+	match := r.Match
+	return (r.name == nil || r.name.MatchString(target.name)) &&
+		(match.Tag == "" || slices.Contains(target.tags, match.Tag)) &&
+		(match.UserIDPrefix == "" || strings.HasPrefix(target.userID, match.UserIDPrefix)) &&
+		(match.Environment == "" || match.Environment == target.environment)
+}
+
+type samplingCounters struct {
+	kept    atomic.Int64
+	dropped atomic.Int64
+}
+
+func (c *samplingCounters) snapshot() SamplingRuleStats {
+	kept, dropped := c.kept.Load(), c.dropped.Load()
+	return SamplingRuleStats{Matched: kept + dropped, Kept: kept, Dropped: dropped}
+}
+
+// sampler decides which traces are sent. The decision of a trace is made once, by the
+// first rule matching it or SampleRate, and remembered so every event of the trace
+// shares it. A trace is kept when the hash of its ID falls below the rate, so the same
+// trace ID and rate always give the same decision, in any process.
+type sampler struct {
+	rules    []samplingRule
+	fallback float64
+
+	ruleCounters     []samplingCounters
+	fallbackCounters samplingCounters
+
+	mu        sync.Mutex
+	decisions map[string]bool
+	order     []string // trace IDs of decisions, oldest first
+}
+
+// newSampler returns the sampler of cfg, or nil when every trace is kept
+func newSampler(cfg *Config) *sampler {
+	if len(cfg.SamplingRules) == 0 && cfg.SampleRate >= 1 {
+		return nil
+	}
+	s := &sampler{
+		rules:        make([]samplingRule, len(cfg.SamplingRules)),
+		fallback:     cfg.SampleRate,
+		ruleCounters: make([]samplingCounters, len(cfg.SamplingRules)),
+		decisions:    make(map[string]bool),
+	}
+	for i, rule := range cfg.SamplingRules {
+		s.rules[i].SamplingRule = rule
+		if rule.Match.NamePattern != "" {
+			// Validated with the configuration
+			s.rules[i].name = regexp.MustCompile(rule.Match.NamePattern)
+		}
+	}
+	return s
+}
+
+// keep reports whether the events of the trace traceID are sent. The first call for a
+// trace decides; target is the trace to match the rules on, or nil for an observation
+// whose trace is not known, which is sampled with SampleRate.
+func (s *sampler) keep(traceID string, target *samplingTarget) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kept, ok := s.decisions[traceID]; ok {
+		return kept
+	}
+
+	rate, counters := s.fallback, &s.fallbackCounters
+	if target != nil {
+		for i := range s.rules {
+			if s.rules[i].matches(target) {
+				rate, counters = s.rules[i].Rate, &s.ruleCounters[i]
+				break
+			}
+		}
+	}
+
+	kept := sampleTraceID(traceID, rate)
+	if kept {
+		counters.kept.Add(1)
+	} else {
+		counters.dropped.Add(1)
+	}
+
+	if len(s.order) >= samplingDecisionCacheSize {
+		delete(s.decisions, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.decisions[traceID] = kept
+	s.order = append(s.order, traceID)
+	return kept
+}
+
+func (s *sampler) snapshot() *SamplingStats {
+	stats := &SamplingStats{
+		Rules:    make([]SamplingRuleStats, len(s.ruleCounters)),
+		Fallback: s.fallbackCounters.snapshot(),
+	}
+	for i := range s.ruleCounters {
+		stats.Rules[i] = s.ruleCounters[i].snapshot()
+	}
+	return stats
+}
+
+// sampleTraceID reports whether a trace is kept at rate, from the hash of its ID
+func sampleTraceID(traceID string, rate float64) bool {
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+	return float64(stableHash(traceID)) < rate*math.MaxUint64
+}
+
+// keepTrace reports whether the events of the trace traceID are sent; see sampler.keep
+func (lf *Langfuse) keepTrace(traceID string, target *samplingTarget) bool {
+	if lf == nil || lf.sampler == nil {
+		return true
+	}
+	return lf.sampler.keep(traceID, target)
+}
+
+// enqueueForTrace queues an event of the trace traceID unless the trace is sampled out
+func (lf *Langfuse) enqueueForTrace(traceID string, event ingestiontypes.IngestionEvent) error {
+	if !lf.keepTrace(traceID, nil) {
+		return nil
+	}
+	return lf.enqueue(event)
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func newSamplingTestLangfuse(t *testing.T, sampleRate float64, rules ...SamplingRule) (*Langfuse, *ingestionRecorder) {
+	return newPayloadTestLangfuse(t, func(cfg *config.Config) {
+		cfg.SampleRate = sampleRate
+		cfg.SamplingRules = rules
+	})
+}
+
+// sentTraceIDs flushes the client and returns the trace ID of every event sent
+func sentTraceIDs(t *testing.T, lf *Langfuse, recorder *ingestionRecorder) map[string]bool {
+	require.NoError(t, lf.Flush(context.Background()))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	ids := make(map[string]bool)
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		if traceID, ok := body["traceId"].(string); ok {
+			ids[traceID] = true
+		} else {
+			ids[body["id"].(string)] = true
+		}
+	}
+	return ids
+}
+
+func TestSampling_FirstMatchingRuleWins(t *testing.T) {
+	ctx := context.Background()
+	lf, recorder := newSamplingTestLangfuse(t, 1,
+		SamplingRule{Match: SamplingMatch{NamePattern: "^checkout"}, Rate: 1},
+		SamplingRule{Match: SamplingMatch{NamePattern: "checkout"}, Rate: 0},
+	)
+
+	checkout := lf.Trace("checkout-v2")
+	preCheckout := lf.Trace("pre-checkout")
+	other := lf.Trace("search")
+	for _, trace := range []*TraceBuilder{checkout, preCheckout, other} {
+		require.NoError(t, trace.End(ctx))
+	}
+
+	sent := sentTraceIDs(t, lf, recorder)
+	assert.True(t, sent[checkout.GetID()])
+	assert.False(t, sent[preCheckout.GetID()], "the second rule drops what the first does not match")
+	assert.True(t, sent[other.GetID()], "unmatched traces use SampleRate")
+
+	sampling := lf.GetStats().Sampling
+	require.NotNil(t, sampling)
+	assert.Equal(t, []SamplingRuleStats{
+		{Matched: 1, Kept: 1},
+		{Matched: 1, Dropped: 1},
+	}, sampling.Rules)
+	assert.Equal(t, SamplingRuleStats{Matched: 1, Kept: 1}, sampling.Fallback)
+}
+
+func TestSampling_MatchFields(t *testing.T) {
+	ctx := context.Background()
+	keep := func(match SamplingMatch) SamplingRule { return SamplingRule{Match: match, Rate: 1} }
+	lf, recorder := newSamplingTestLangfuse(t, 0,
+		keep(SamplingMatch{NamePattern: "^health(check)?$"}),
+		keep(SamplingMatch{Tag: "vip"}),
+		keep(SamplingMatch{UserIDPrefix: "staff-"}),
+		keep(SamplingMatch{Environment: "staging"}),
+		keep(SamplingMatch{NamePattern: "^chat$", Tag: "debug"}),
+	)
+
+	kept := []*TraceBuilder{
+		lf.Trace("health"),
+		lf.Trace("healthcheck"),
+		lf.Trace("chat").AddTag("vip"),
+		lf.Trace("chat").UserID("staff-42"),
+		lf.Trace("chat").WithEnvironment("staging"),
+		lf.Trace("chat").AddTag("debug"),
+	}
+	dropped := []*TraceBuilder{
+		lf.Trace("healthchecks"),
+		lf.Trace("chat").UserID("user-staff-42"),
+		lf.Trace("chat-v2").AddTag("debug"),
+		lf.Trace("chat"),
+	}
+	for _, trace := range append(kept, dropped...) {
+		require.NoError(t, trace.End(ctx))
+	}
+
+	sent := sentTraceIDs(t, lf, recorder)
+	for _, trace := range kept {
+		assert.True(t, sent[trace.GetID()], "trace %s should be kept", trace.GetName())
+	}
+	for _, trace := range dropped {
+		assert.False(t, sent[trace.GetID()], "trace %s should be dropped", trace.GetName())
+	}
+	assert.Equal(t, SamplingRuleStats{Matched: 4, Dropped: 4}, lf.GetStats().Sampling.Fallback)
+}
+
+func TestSampling_ObservationsFollowTheirTrace(t *testing.T) {
+	ctx := context.Background()
+	lf, recorder := newSamplingTestLangfuse(t, 0,
+		SamplingRule{Match: SamplingMatch{Tag: "keep"}, Rate: 1},
+	)
+
+	kept := lf.Trace("kept").AddTag("keep")
+	dropped := lf.Trace("dropped")
+	for _, trace := range []*TraceBuilder{kept, dropped} {
+		span := trace.Span("step")
+		require.NoError(t, span.ChildGeneration("llm").End(ctx))
+		require.NoError(t, span.End(ctx))
+		require.NoError(t, trace.Generation("summary").End(ctx))
+	}
+	require.NoError(t, kept.End(ctx))
+	require.NoError(t, dropped.End(ctx))
+
+	require.NoError(t, lf.Flush(ctx))
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.events, 4, "the trace, span and generations of the kept trace")
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		if body["id"] != kept.GetID() {
+			assert.Equal(t, kept.GetID(), body["traceId"])
+		}
+	}
+}
+
+func TestSampling_Deterministic(t *testing.T) {
+	rules := []SamplingRule{{Match: SamplingMatch{NamePattern: "^sampled$"}, Rate: 0.3}}
+	first := newSampler(&config.Config{SampleRate: 0.5, SamplingRules: rules})
+	second := newSampler(&config.Config{SampleRate: 0.5, SamplingRules: rules})
+	target := &samplingTarget{name: "sampled"}
+
+	const traces = 10000
+	kept := 0
+	for i := 0; i < traces; i++ {
+		traceID := fmt.Sprintf("trace-%d", i)
+		decision := first.keep(traceID, target)
+		assert.Equal(t, decision, second.keep(traceID, target), "trace %s", traceID)
+		assert.Equal(t, decision, first.keep(traceID, nil), "the first decision of a trace sticks")
+		if decision {
+			kept++
+		}
+	}
+	assert.InDelta(t, traces*0.3, kept, traces*0.02)
+
+	stats := first.snapshot()
+	assert.Equal(t, int64(traces), stats.Rules[0].Matched)
+	assert.Equal(t, int64(kept), stats.Rules[0].Kept)
+	assert.Zero(t, stats.Fallback.Matched)
+}
+
+func TestSampling_NoSamplerByDefault(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+	assert.Nil(t, lf.sampler)
+	assert.Nil(t, lf.GetStats().Sampling)
+}
+
+func TestSampling_Validation(t *testing.T) {
+	_, err := NewConfig(
+		WithCredentials("pk-lf-test", "sk-lf-test"),
+		WithSamplingRules([]SamplingRule{
+			{Match: SamplingMatch{NamePattern: "^checkout"}, Rate: 1},
+			{Match: SamplingMatch{NamePattern: "(unclosed"}, Rate: 1},
+		}),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "samplingRules[1].match.namePattern")
+
+	_, err = NewConfig(
+		WithCredentials("pk-lf-test", "sk-lf-test"),
+		WithSamplingRules([]SamplingRule{{Rate: 1.5}}),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "samplingRules[0].rate")
+
+	_, err = NewConfig(WithCredentials("pk-lf-test", "sk-lf-test"), WithSampleRate(-0.1))
+	assert.Error(t, err)
+
+	cfg := DefaultConfig()
+	cfg.PublicKey, cfg.SecretKey = "pk-lf-test", "sk-lf-test"
+	cfg.SamplingRules = []SamplingRule{{Match: SamplingMatch{NamePattern: "["}}}
+	errs := cfg.Validate()
+	require.NotNil(t, errs)
+	assert.Contains(t, errs.Error(), "samplingRules[0].match.namePattern")
+}
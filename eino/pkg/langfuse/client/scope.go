@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// PanicEventName is the name of the error-level event recorded when the function passed
+// to TraceFunc, SpanFunc or GenerationFunc panics
+const PanicEventName = "panic"
+
+// TraceFunc creates a trace named name, calls fn with it and ends the trace when fn
+// returns, so callers need neither defer End nor record errors themselves. The trace
+// metadata is seeded from ctx by the configured context extractors, as with
+// WithContext(ctx).Trace. If fn returns an error, the trace output is set to it with
+// WithErrorOutput.
+//
+// If fn panics, the panic is recorded as an error-level event of the trace, the trace is
+// ended with the panic as its error output and the panic is resumed.
+//
+// Returns the error of fn, or the error of ending the trace if fn succeeded. When the
+// client is disabled or rate limited, fn gets a no-op builder and only its error is
+// returned.
+//
+// Example:
+//
+//	err := client.TraceFunc(ctx, "checkout", func(trace *TraceBuilder) error {
+//		trace.WithUserID(userID).WithInput(cart)
+//		order, err := placeOrder(ctx, cart)
+//		trace.WithOutput(order)
+//		return err
+//	})
+func (lf *Langfuse) TraceFunc(ctx context.Context, name string, fn func(trace *TraceBuilder) error) error {
+	trace := lf.WithContext(ctx).Trace(name)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			trace.mu.Lock()
+			traceID, environment := trace.id, trace.environment
+			trace.mu.Unlock()
+
+			trace.client.recordPanic(recovered, traceID, "", environment, ingestiontypes.EventSourceTrace)
+			trace.WithErrorOutput(panicError(recovered)).End(ctx)
+			panic(recovered)
+		}
+	}()
+
+	err := fn(trace)
+	if trace.client == nil {
+		return err
+	}
+	trace.WithErrorOutput(err)
+	if endErr := trace.End(ctx); err == nil {
+		return endErr
+	}
+	return err
+}
+
+// SpanFunc is the TraceFunc analog for a standalone span. If fn returns an error, the
+// span is ended with WithErrorOutput, at level ERROR.
+func (lf *Langfuse) SpanFunc(ctx context.Context, name string, fn func(span *SpanBuilder) error) error {
+	span := lf.Span(name)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			span.mu.Lock()
+			traceID, spanID, environment := span.traceID, span.id, span.environment
+			span.mu.Unlock()
+
+			span.client.recordPanic(recovered, traceID, spanID, environment, ingestiontypes.EventSourceSpan)
+			span.WithErrorOutput(panicError(recovered)).End(ctx)
+			panic(recovered)
+		}
+	}()
+
+	err := fn(span)
+	if span.client == nil {
+		return err
+	}
+	span.WithErrorOutput(err)
+	if endErr := span.End(ctx); err == nil {
+		return endErr
+	}
+	return err
+}
+
+// GenerationFunc is the TraceFunc analog for a standalone generation. If fn returns an
+// error, the generation is ended with WithErrorOutput, at level ERROR.
+func (lf *Langfuse) GenerationFunc(ctx context.Context, name string, fn func(generation *GenerationBuilder) error) error {
+	generation := lf.Generation(name)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			generation.mu.Lock()
+			traceID, generationID, environment := generation.traceID, generation.id, generation.environment
+			generation.mu.Unlock()
+
+			generation.client.recordPanic(recovered, traceID, generationID, environment, ingestiontypes.EventSourceGeneration)
+			generation.WithErrorOutput(panicError(recovered)).End(ctx)
+			panic(recovered)
+		}
+	}()
+
+	err := fn(generation)
+	if generation.client == nil {
+		return err
+	}
+	generation.WithErrorOutput(err)
+	if endErr := generation.End(ctx); err == nil {
+		return endErr
+	}
+	return err
+}
+
+// errorOutput is the output of a builder that failed with err
+func errorOutput(err error) map[string]interface{} {
+	return map[string]interface{}{"error": err.Error()}
+}
+
+// panicError describes a recovered panic
+func panicError(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
+
+// recordPanic enqueues an error-level event carrying the recovered panic and the stack
+// of the panicking goroutine, nested under parentID when it is set. Failures are ignored,
+// since the panic is resumed right after.
+func (lf *Langfuse) recordPanic(recovered interface{}, traceID, parentID, environment, source string) {
+	if lf == nil || lf.isDisabled() {
+		return
+	}
+
+	message := panicError(recovered).Error()
+	now := time.Now()
+	event := &ingestiontypes.EventCreateEvent{
+		ObservationEvent: ingestiontypes.ObservationEvent{
+			ID:            utils.GenerateObservationID(),
+			TraceID:       traceID,
+			Type:          types.ObservationTypeEvent,
+			Name:          PanicEventName,
+			StartTime:     now,
+			EndTime:       &now,
+			Level:         types.ObservationLevelError,
+			StatusMessage: &message,
+			Metadata:      map[string]interface{}{"stack": string(debug.Stack())},
+			Environment:   environment,
+		},
+	}
+	if parentID != "" {
+		event.ParentObservationID = &parentID
+	}
+
+	ingestionEvent, err := ingestiontypes.NewEventCreateIngestionEvent(event)
+	if err != nil {
+		return
+	}
+	ingestionEvent.Source = source
+	ingestionEvent.Project = environment
+	lf.enqueueForTrace(traceID, ingestionEvent)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/config"
+)
+
+func TestLangfuse_TraceFunc(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		err := lf.TraceFunc(ctx, "checkout", func(trace *TraceBuilder) error {
+			trace.WithOutput("ok")
+			return nil
+		})
+		require.NoError(t, err)
+
+		body := flushedBodies(t, lf, recorder)["trace-update"]
+		assert.Equal(t, "checkout", body["name"])
+		assert.Equal(t, "ok", body["output"])
+	})
+
+	t.Run("error", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		failure := errors.New("card declined")
+		err := lf.TraceFunc(ctx, "checkout", func(trace *TraceBuilder) error {
+			return failure
+		})
+		assert.Same(t, failure, err)
+
+		body := flushedBodies(t, lf, recorder)["trace-update"]
+		assert.Equal(t, map[string]interface{}{"error": "card declined"}, body["output"])
+	})
+
+	t.Run("context extractors", func(t *testing.T) {
+		type tenantKey struct{}
+		lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+			cfg.ContextExtractors = append(cfg.ContextExtractors, func(ctx context.Context) map[string]interface{} {
+				return map[string]interface{}{"tenant": ctx.Value(tenantKey{})}
+			})
+		})
+		ctx := context.WithValue(ctx, tenantKey{}, "acme")
+		require.NoError(t, lf.TraceFunc(ctx, "checkout", func(trace *TraceBuilder) error { return nil }))
+
+		body := flushedBodies(t, lf, recorder)["trace-update"]
+		assert.Equal(t, "acme", body["metadata"].(map[string]interface{})["tenant"])
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		var traceID string
+		assert.PanicsWithValue(t, "out of stock", func() {
+			lf.TraceFunc(ctx, "checkout", func(trace *TraceBuilder) error {
+				traceID = trace.GetID()
+				panic("out of stock")
+			})
+		})
+
+		bodies := flushedBodies(t, lf, recorder)
+		assert.Equal(t, map[string]interface{}{"error": "panic: out of stock"}, bodies["trace-update"]["output"])
+
+		event := bodies["event-create"]
+		require.NotNil(t, event, "the panic is recorded as an event")
+		assert.Equal(t, PanicEventName, event["name"])
+		assert.Equal(t, traceID, event["traceId"])
+		assert.Equal(t, "ERROR", event["level"])
+		assert.Equal(t, "panic: out of stock", event["statusMessage"])
+		assert.Contains(t, event["metadata"].(map[string]interface{})["stack"], "TestLangfuse_TraceFunc")
+	})
+}
+
+func TestLangfuse_SpanFunc(t *testing.T) {
+	ctx := context.Background()
+	lf, recorder := newPayloadTestLangfuse(t)
+
+	err := lf.SpanFunc(ctx, "retrieve", func(span *SpanBuilder) error {
+		span.WithInput("query")
+		return errors.New("index unavailable")
+	})
+	require.EqualError(t, err, "index unavailable")
+
+	body := flushedBodies(t, lf, recorder)["span-update"]
+	assert.Equal(t, "retrieve", body["name"])
+	assert.Equal(t, "ERROR", body["level"])
+	assert.Equal(t, "index unavailable", body["statusMessage"])
+	assert.Equal(t, map[string]interface{}{"error": "index unavailable"}, body["output"])
+	assert.NotNil(t, body["endTime"])
+}
+
+func TestLangfuse_GenerationFunc_Panic(t *testing.T) {
+	ctx := context.Background()
+	lf, recorder := newPayloadTestLangfuse(t)
+	panicErr := errors.New("nil response")
+
+	var generationID string
+	assert.PanicsWithError(t, "nil response", func() {
+		lf.GenerationFunc(ctx, "llm", func(generation *GenerationBuilder) error {
+			generationID = generation.GetID()
+			panic(panicErr)
+		})
+	})
+
+	bodies := flushedBodies(t, lf, recorder)
+	assert.Equal(t, "ERROR", bodies["generation-update"]["level"])
+	assert.Equal(t, "panic: nil response", bodies["generation-update"]["statusMessage"])
+	assert.Equal(t, generationID, bodies["event-create"]["parentObservationId"], "the panic event is nested under the generation")
+}
+
+func TestLangfuse_TraceFunc_Disabled(t *testing.T) {
+	lf := newDisabledClient(DefaultConfig())
+	failure := errors.New("failed")
+
+	called := false
+	require.NoError(t, lf.TraceFunc(context.Background(), "noop", func(trace *TraceBuilder) error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+	assert.Same(t, failure, lf.SpanFunc(context.Background(), "noop", func(*SpanBuilder) error { return failure }))
+	assert.Panics(t, func() {
+		lf.GenerationFunc(context.Background(), "noop", func(*GenerationBuilder) error { panic("boom") })
+	})
+}
+
+func TestWithErrorOutput_NilIsIgnored(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+
+	span := lf.Span("retrieve").WithOutput("documents").WithErrorOutput(nil)
+	event := span.toObservationEvent()
+	assert.Equal(t, "documents", event.Output)
+	assert.NotEqual(t, types.ObservationLevelError, event.Level)
+	assert.Nil(t, event.StatusMessage)
+
+	trace := lf.Trace("chat").WithOutput("answer").WithErrorOutput(nil)
+	assert.Equal(t, "answer", trace.toTraceEvent().Output)
+}
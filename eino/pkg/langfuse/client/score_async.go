@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/api/resources/scores"
+)
+
+// ScoreAsync enqueues a score as a score-create ingestion event and returns without
+// waiting for the API. Unlike Score, the score is sent with the next batch and retried
+// with backoff on transient errors like traces are, so a brief outage does not fail the
+// caller.
+//
+// The score is validated before it is enqueued, including the value against the data
+// type and the name against a registry configured with WithAllowedScoreNames or
+// WithScoreConfigNames, so invalid scores still fail synchronously. Errors that only
+// the API can detect, such as a trace that does not exist, are not reported to the
+// caller; they show up in the client statistics and the flush hooks.
+//
+// When environments are routed to projects with WithProjectCredentials, pass the trace's
+// environment with ScoreEnvironment so the score reaches the trace's project; scores
+// without one go to the default project.
+//
+// If the client is disabled, this method returns nil without error.
+func (lf *Langfuse) ScoreAsync(score *types.Score, opts ...ScoreOption) error {
+	if lf.isDisabled() {
+		return nil
+	}
+
+	if err := lf.validateScore(score); err != nil {
+		return fmt.Errorf("score validation failed: %w", err)
+	}
+
+	req, err := createScoreRequest(score)
+	if err != nil {
+		return err
+	}
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("score validation failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lf.config.RequestTimeout)
+	defer cancel()
+	if err := lf.apiClient.Scores.CheckName(ctx, score.Name, opts...); err != nil {
+		return err
+	}
+
+	timestamp := score.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	event, err := ingestiontypes.NewScoreCreateIngestionEvent(&ingestiontypes.ScoreCreateEvent{
+		ScoreEvent: ingestiontypes.ScoreEvent{
+			ID:            score.ID,
+			TraceID:       score.TraceID,
+			ObservationID: score.ObservationID,
+			Name:          score.Name,
+			Value:         req.Value,
+			DataType:      score.DataType,
+			Comment:       score.Comment,
+			ConfigID:      score.ConfigID,
+			Timestamp:     timestamp,
+			Source:        ingestiontypes.ScoreSourceSDK,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("score validation failed: %w", err)
+	}
+	event.Source = ingestiontypes.EventSourceScore
+	event.Project = scores.EnvironmentOf(opts...)
+
+	if err := lf.enqueue(event); err != nil {
+		return fmt.Errorf("failed to enqueue score %s: %w", event.ID, err)
+	}
+
+	root := lf.root()
+	root.stats.touch()
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/config"
+)
+
+func numericScore(name string, value string) *types.Score {
+	return &types.Score{
+		TraceID:  "trace-1",
+		Name:     name,
+		Value:    json.RawMessage(value),
+		DataType: types.ScoreDataTypeNumeric,
+	}
+}
+
+func TestLangfuse_ScoreAsync_RetriesTransientErrors(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	var attempts, scoreCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		recorder.ServeHTTP(w, r)
+	}))
+	mux.Handle("/api/public/scores", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scoreCalls.Add(1)
+	}))
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.FlushInterval = time.Hour
+		cfg.RetryCount = 2
+		cfg.RetryDelay = time.Millisecond
+		cfg.MaxRetryDelay = 5 * time.Millisecond
+		cfg.RetryWaitTime = 10 * time.Millisecond
+	})
+
+	require.NoError(t, lf.ScoreAsync(numericScore("accuracy", "0.9")))
+	assert.Zero(t, attempts.Load(), "the score is only enqueued")
+
+	bodies := flushedBodies(t, lf, recorder)
+	assert.GreaterOrEqual(t, attempts.Load(), int32(2))
+	assert.Zero(t, scoreCalls.Load(), "async scores go through the ingestion API")
+
+	body, ok := bodies["score-create"]
+	require.True(t, ok, "the score is delivered after the failed attempt")
+	assert.Equal(t, "trace-1", body["traceId"])
+	assert.Equal(t, "accuracy", body["name"])
+	assert.Equal(t, 0.9, body["value"])
+	assert.Equal(t, "NUMERIC", body["dataType"])
+	assert.NotEmpty(t, body["id"])
+}
+
+func TestLangfuse_ScoreAsync_ValidatesSynchronously(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	lf := newTestLangfuse(t, recorder, func(cfg *config.Config) {
+		cfg.StrictMode = true
+		cfg.AllowedScoreNames = []string{"accuracy"}
+	})
+
+	tests := []struct {
+		name  string
+		score *types.Score
+	}{
+		{"nil score", nil},
+		{"missing trace ID", &types.Score{Name: "accuracy", Value: json.RawMessage(`1`), DataType: types.ScoreDataTypeNumeric}},
+		{"missing value", &types.Score{TraceID: "trace-1", Name: "accuracy", DataType: types.ScoreDataTypeNumeric}},
+		{"value not matching data type", numericScore("accuracy", `"high"`)},
+		{"missing data type", &types.Score{TraceID: "trace-1", Name: "accuracy", Value: json.RawMessage(`1`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, lf.ScoreAsync(tt.score), "score validation failed")
+		})
+	}
+
+	err := lf.ScoreAsync(numericScore("acuracy", "1"))
+	assert.ErrorIs(t, err, ErrUnknownScoreName)
+	assert.NoError(t, lf.ScoreAsync(numericScore("acuracy", "1"), AllowUnregisteredName()))
+
+	bodies := flushedBodies(t, lf, recorder)
+	require.Len(t, recorder.events, 1, "only the allowed score is sent")
+	assert.Equal(t, "acuracy", bodies["score-create"]["name"])
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+	scoreTypes "eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// scoreTrendBuckets is the number of buckets GetScoreTrend splits its window into
+const scoreTrendBuckets = 24
+
+// GetScoreTrend returns the numeric scores named scoreName over the last window, split
+// into 24 equal buckets (of at least one second) ending now. For other bucket sizes or
+// time ranges, call GetTimeSeriesData on API().Scores.
+//
+// Example:
+//
+//	trend, err := langfuse.GetScoreTrend(ctx, "accuracy", 24*time.Hour)
+//	if err != nil {
+//		return err
+//	}
+//	for _, bucket := range trend.Buckets {
+//		fmt.Printf("%s  %.2f (%d scores)\n", bucket.StartTime.Format(time.Kitchen), bucket.Average, bucket.Count)
+//	}
+func (lf *Langfuse) GetScoreTrend(ctx context.Context, scoreName string, window time.Duration) (*scoreTypes.ScoreTimeSeriesResponse, error) {
+	if lf.isDisabled() {
+		return nil, fmt.Errorf("client is disabled")
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("score trend window must be positive")
+	}
+
+	to := time.Now()
+	return lf.apiClient.Scores.GetTimeSeriesData(ctx, &scoreTypes.ScoreTimeSeriesRequest{
+		Name:          scoreName,
+		DataType:      types.ScoreDataTypeNumeric,
+		BucketSize:    max((window / scoreTrendBuckets).Truncate(time.Second), time.Second),
+		FromTimestamp: to.Add(-window),
+		ToTimestamp:   to,
+	})
+}
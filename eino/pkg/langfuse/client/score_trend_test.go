@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLangfuse_GetScoreTrend(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/scores/timeseries", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "accuracy", query.Get("name"))
+		assert.Equal(t, "NUMERIC", query.Get("dataType"))
+		assert.Equal(t, "3600", query.Get("bucketSizeSeconds"))
+
+		from, err := time.Parse(time.RFC3339, query.Get("fromTimestamp"))
+		require.NoError(t, err)
+		to, err := time.Parse(time.RFC3339, query.Get("toTimestamp"))
+		require.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, to.Sub(from))
+		assert.WithinDuration(t, time.Now(), to, time.Minute)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"buckets": [{"startTime": "2024-03-01T10:00:00Z", "count": 2, "average": 0.75, "min": 0.5, "max": 1}]}`))
+	})
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	trend, err := lf.GetScoreTrend(ctx, "accuracy", 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, trend.Buckets, 1)
+	assert.Equal(t, 2, trend.Buckets[0].Count)
+	assert.Equal(t, 0.75, trend.Buckets[0].Average)
+
+	_, err = lf.GetScoreTrend(ctx, "accuracy", 0)
+	assert.Error(t, err)
+	_, err = lf.GetScoreTrend(ctx, "", time.Hour)
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+
+	"eino/pkg/langfuse/internal/queue"
+)
+
+// QueueStats is a snapshot of the ingestion queue statistics
+type QueueStats = queue.QueueStats
+
+// credentialConfigFields are the configuration fields redacted from ConfigSummary
+var credentialConfigFields = map[string]bool{
+	"PublicKey":          true,
+	"SecretKey":          true,
+	"SecondaryPublicKey": true,
+	"SecondarySecretKey": true,
+	"UserIDHashSalt":     true,
+}
+
+// SDKInfo describes the running SDK instance, for attaching to support tickets
+type SDKInfo struct {
+	SDKVersion string `json:"sdkVersion"`
+	SDKName    string `json:"sdkName"`
+	GoVersion  string `json:"goVersion"`
+	GOARCH     string `json:"goarch"`
+	GOOS       string `json:"goos"`
+
+	// ConfigSummary holds every configuration field by name. Credentials are replaced
+	// with "[REDACTED]" when set, durations are formatted as strings, and hooks such as
+	// middleware or interceptors are reported by count or type only.
+	ConfigSummary map[string]interface{} `json:"configSummary"`
+
+	Stats *ClientStats `json:"stats"`
+
+	// QueueStats is nil for a disabled client
+	QueueStats *QueueStats `json:"queueStats,omitempty"`
+
+	// Uptime is the time since the client was created
+	Uptime time.Duration `json:"uptime"`
+}
+
+// GetSDKInfo returns the SDK version, runtime, configuration and statistics of the client.
+//
+// Example:
+//
+//	if data, err := lf.GetSDKInfo().ToJSON(); err == nil {
+//		log.Printf("langfuse sdk info: %s", data)
+//	}
+func (lf *Langfuse) GetSDKInfo() *SDKInfo {
+	cfg := lf.GetConfig()
+	stats := lf.GetStats()
+
+	info := &SDKInfo{
+		SDKVersion:    cfg.SDKVersion,
+		SDKName:       cfg.SDKName,
+		GoVersion:     runtime.Version(),
+		GOARCH:        runtime.GOARCH,
+		GOOS:          runtime.GOOS,
+		ConfigSummary: summarizeConfig(cfg),
+		Stats:         stats,
+		Uptime:        time.Since(stats.CreatedAt),
+	}
+
+	if q := lf.root().queue; q != nil {
+		qs := q.Stats()
+		info.QueueStats = &qs
+	}
+	return info
+}
+
+// ToJSON encodes the info as indented JSON, with the uptime formatted as a string
+func (info *SDKInfo) ToJSON() ([]byte, error) {
+	type Alias SDKInfo
+	return json.MarshalIndent(&struct {
+		*Alias
+		Uptime string `json:"uptime"`
+	}{
+		Alias:  (*Alias)(info),
+		Uptime: info.Uptime.String(),
+	}, "", "  ")
+}
+
+// summarizeConfig maps each configuration field to a value safe to log
+func summarizeConfig(cfg *Config) map[string]interface{} {
+	summary := make(map[string]interface{})
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := v.Field(i)
+		if credentialConfigFields[field.Name] {
+			summary[field.Name] = redactCredential(value.String())
+			continue
+		}
+		if projects, ok := value.Interface().(map[string]ProjectCredentials); ok {
+			summary[field.Name] = summarizeProjects(projects)
+			continue
+		}
+		summary[field.Name] = summarizeConfigValue(value)
+	}
+	return summary
+}
+
+// summarizeProjects lists the environments routed to their own project, with redacted
+// credentials
+func summarizeProjects(projects map[string]ProjectCredentials) map[string]interface{} {
+	if projects == nil {
+		return nil
+	}
+	summary := make(map[string]interface{}, len(projects))
+	for environment, credentials := range projects {
+		summary[environment] = map[string]string{
+			"PublicKey": redactCredential(credentials.PublicKey),
+			"SecretKey": redactCredential(credentials.SecretKey),
+		}
+	}
+	return summary
+}
+
+// summarizeConfigValue converts a configuration value into a JSON-friendly form
+func summarizeConfigValue(value reflect.Value) interface{} {
+	if d, ok := value.Interface().(time.Duration); ok {
+		return d.String()
+	}
+
+	switch value.Kind() {
+	case reflect.Func:
+		return !value.IsNil()
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return fmt.Sprintf("%T", value.Interface())
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Func {
+			return value.Len()
+		}
+		// Copy so the summary does not alias the configuration
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(copied, value)
+		return copied.Interface()
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestGetSDKInfo(t *testing.T) {
+	lf := newTestLangfuse(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.PublicKey = "pk-lf-very-public"
+		cfg.SecretKey = "sk-lf-very-secret"
+		cfg.Environment = "staging"
+		cfg.DefaultTags = []string{"team:platform"}
+		cfg.EventMiddleware = []EventMiddleware{PIIDetectionMiddleware(nil)}
+	})
+	lf.Trace("one")
+
+	info := lf.GetSDKInfo()
+	assert.Equal(t, "langfuse-go", info.SDKName)
+	assert.NotEmpty(t, info.SDKVersion)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS, info.GOOS)
+	assert.Equal(t, runtime.GOARCH, info.GOARCH)
+	assert.Equal(t, int64(1), info.Stats.TracesCreated)
+	require.NotNil(t, info.QueueStats)
+	assert.Greater(t, info.Uptime, time.Duration(0))
+
+	summary := info.ConfigSummary
+	assert.Equal(t, redactedValue, summary["PublicKey"])
+	assert.Equal(t, redactedValue, summary["SecretKey"])
+	assert.Equal(t, "staging", summary["Environment"])
+	assert.Equal(t, []string{"team:platform"}, summary["DefaultTags"])
+	assert.Equal(t, 1, summary["EventMiddleware"])
+	assert.Equal(t, lf.GetConfig().FlushInterval.String(), summary["FlushInterval"])
+
+	data, err := info.ToJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "pk-lf-very-public")
+	assert.NotContains(t, string(data), "sk-lf-very-secret")
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.IsType(t, "", decoded["uptime"])
+	assert.Contains(t, decoded, "queueStats")
+}
+
+func TestGetSDKInfo_DisabledClient(t *testing.T) {
+	cfg, err := config.NewConfig(config.WithCredentials("pk-lf-test", "sk-lf-test"), config.WithEnabled(false))
+	require.NoError(t, err)
+	lf, err := New(cfg)
+	require.NoError(t, err)
+
+	info := lf.GetSDKInfo()
+	assert.Nil(t, info.QueueStats)
+	assert.Equal(t, redactedValue, info.ConfigSummary["SecretKey"])
+
+	_, err = info.ToJSON()
+	assert.NoError(t, err)
+}
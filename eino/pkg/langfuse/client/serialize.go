@@ -0,0 +1,110 @@
+package client
+
+import (
+	"time"
+
+	"eino/pkg/langfuse/config"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// timeConverter returns the function used to serialize time.Time values found in
+// metadata, input and output, according to the configured MetadataTimeFormat
+func (lf *Langfuse) timeConverter() func(time.Time) interface{} {
+	format := config.TimeFormatRFC3339Nano
+	if lf != nil && lf.config != nil && lf.config.MetadataTimeFormat != "" {
+		format = lf.config.MetadataTimeFormat
+	}
+
+	if format == config.TimeFormatEpochMillis {
+		return func(t time.Time) interface{} {
+			return t.UnixMilli()
+		}
+	}
+	return func(t time.Time) interface{} {
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// serializeValue snapshots an input or output value, converting nested times
+func (lf *Langfuse) serializeValue(value interface{}) interface{} {
+	return utils.ConvertTimes(value, lf.timeConverter())
+}
+
+// serializeMetadata snapshots a metadata map, converting nested times
+func (lf *Langfuse) serializeMetadata(metadata map[string]interface{}) map[string]interface{} {
+	return utils.ConvertMetadataTimes(metadata, lf.timeConverter())
+}
+
+// formatOutput applies the configured OutputFormatter to an output sent when a builder ends
+func (lf *Langfuse) formatOutput(output interface{}) interface{} {
+	if output == nil || lf == nil || lf.config == nil || lf.config.OutputFormatter == nil {
+		return output
+	}
+	return lf.config.OutputFormatter(output)
+}
+
+// payloadMode returns the payload mode for a builder whose trace requested traceMode
+// (empty when the trace did not set one). The trace mode replaces the configured one
+// when PayloadModeOverrideAllowed is set; otherwise the more restrictive of the two wins.
+func (lf *Langfuse) payloadMode(traceMode config.PayloadMode) config.PayloadMode {
+	mode := config.PayloadModeFull
+	overrideAllowed := false
+	if lf != nil && lf.config != nil {
+		if lf.config.PayloadMode != "" {
+			mode = lf.config.PayloadMode
+		}
+		overrideAllowed = lf.config.PayloadModeOverrideAllowed
+	}
+
+	if traceMode == "" {
+		return mode
+	}
+	if overrideAllowed {
+		return traceMode
+	}
+	return mode.Restrict(traceMode)
+}
+
+// userIDHashSalt returns the configured salt for anonymized user IDs
+func (lf *Langfuse) userIDHashSalt() string {
+	if lf == nil || lf.config == nil {
+		return ""
+	}
+	return lf.config.UserIDHashSalt
+}
+
+// redactedPayload stands in for an input or output withheld by the payload mode
+func redactedPayload() map[string]interface{} {
+	return map[string]interface{}{"redacted": true}
+}
+
+// serializeInput snapshots an input, or redacts it when the payload mode withholds inputs
+func (lf *Langfuse) serializeInput(input interface{}, traceMode config.PayloadMode) interface{} {
+	if input == nil {
+		return nil
+	}
+	if !lf.payloadMode(traceMode).SendsInput() {
+		return redactedPayload()
+	}
+	return lf.serializeValue(input)
+}
+
+// serializeOutput snapshots an output, or redacts it when the payload mode withholds outputs
+func (lf *Langfuse) serializeOutput(output interface{}, traceMode config.PayloadMode) interface{} {
+	if output == nil {
+		return nil
+	}
+	if !lf.payloadMode(traceMode).SendsOutput() {
+		return redactedPayload()
+	}
+	return lf.serializeValue(output)
+}
+
+// serializeFinalOutput is serializeOutput for the event that ends a builder, where the
+// OutputFormatter is applied. Withheld outputs never reach the formatter.
+func (lf *Langfuse) serializeFinalOutput(output interface{}, traceMode config.PayloadMode) interface{} {
+	if output == nil || !lf.payloadMode(traceMode).SendsOutput() {
+		return lf.serializeOutput(output, traceMode)
+	}
+	return lf.serializeValue(lf.formatOutput(output))
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestMetadataTimeFormat(t *testing.T) {
+	startedAt := time.Date(2024, 3, 15, 10, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   TimeFormat
+		expected string
+	}{
+		{
+			name:     "default RFC3339Nano",
+			format:   "",
+			expected: `{"startedAt":"2024-03-15T10:30:00.123456789Z","nested":{"at":"2024-03-15T10:30:00.123456789Z"},"list":["2024-03-15T10:30:00.123456789Z",1]}`,
+		},
+		{
+			name:     "RFC3339Nano",
+			format:   TimeFormatRFC3339Nano,
+			expected: `{"startedAt":"2024-03-15T10:30:00.123456789Z","nested":{"at":"2024-03-15T10:30:00.123456789Z"},"list":["2024-03-15T10:30:00.123456789Z",1]}`,
+		},
+		{
+			name:     "epoch millis",
+			format:   TimeFormatEpochMillis,
+			expected: `{"startedAt":1710498600123,"nested":{"at":1710498600123},"list":[1710498600123,1]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := newTestLangfuse(t, http.NewServeMux(), func(cfg *config.Config) {
+				cfg.MetadataTimeFormat = tt.format
+			})
+
+			metadata := map[string]interface{}{
+				"startedAt": startedAt,
+				"nested":    map[string]interface{}{"at": &startedAt},
+				"list":      []interface{}{startedAt, 1},
+			}
+
+			trace := lf.Trace("timed").Metadata(metadata)
+			span := trace.Span("step").Metadata(metadata)
+			generation := lf.Generation("llm").Metadata(metadata)
+
+			for _, got := range []map[string]interface{}{
+				trace.toTraceEvent().Metadata,
+				span.toObservationEvent().Metadata,
+				generation.toObservationEvent().Metadata,
+			} {
+				data, err := json.Marshal(got)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.expected, string(data))
+			}
+
+			// The builder's own metadata is left untouched
+			assert.Equal(t, startedAt, trace.metadata["startedAt"])
+		})
+	}
+}
+
+func TestMetadataTimeFormat_InputOutput(t *testing.T) {
+	at := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	lf := newTestLangfuse(t, http.NewServeMux(), func(cfg *config.Config) {
+		cfg.MetadataTimeFormat = TimeFormatEpochMillis
+	})
+
+	trace := lf.Trace("timed").
+		Input(map[string]interface{}{"requestedAt": at}).
+		Output([]interface{}{at})
+
+	event := trace.toTraceEvent()
+	input, err := json.Marshal(event.Input)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"requestedAt":1710498600000}`, string(input))
+
+	output, err := json.Marshal(event.Output)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[1710498600000]`, string(output))
+}
+
+func TestWithMetadataTimeFormat(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, WithMetadataTimeFormat(TimeFormatEpochMillis)(cfg))
+	assert.Equal(t, TimeFormatEpochMillis, cfg.MetadataTimeFormat)
+
+	assert.Error(t, WithMetadataTimeFormat("unix")(cfg))
+	assert.Equal(t, TimeFormatEpochMillis, cfg.MetadataTimeFormat)
+}
+
+// sampleOutputFormatter decodes JSON strings and wraps plain text with its content type
+func sampleOutputFormatter(output interface{}) interface{} {
+	text, ok := output.(string)
+	if !ok {
+		return output
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+		return map[string]interface{}{"contentType": "json", "content": decoded}
+	}
+	return map[string]interface{}{"contentType": "text", "content": text}
+}
+
+func TestOutputFormatter(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		require.NoError(t, config.WithOutputFormatter(sampleOutputFormatter)(cfg))
+	})
+	ctx := context.Background()
+
+	trace := lf.Trace("agent").Output("Here is **your** answer")
+	span := trace.Span("tool").Output(`{"temperature": 21, "unit": "C"}`)
+	generation := trace.Generation("llm").Output(map[string]interface{}{"already": "structured"})
+	require.NoError(t, span.End(ctx))
+	require.NoError(t, generation.End(ctx))
+	require.NoError(t, trace.End(ctx))
+	require.NoError(t, lf.Shutdown(ctx))
+
+	outputs := make(map[string]interface{})
+	recorder.mu.Lock()
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		outputs[body["id"].(string)] = body["output"]
+	}
+	recorder.mu.Unlock()
+
+	assert.Equal(t, map[string]interface{}{
+		"contentType": "text",
+		"content":     "Here is **your** answer",
+	}, outputs[trace.GetID()])
+	assert.Equal(t, map[string]interface{}{
+		"contentType": "json",
+		"content":     map[string]interface{}{"temperature": float64(21), "unit": "C"},
+	}, outputs[span.GetID()])
+	assert.Equal(t, map[string]interface{}{"already": "structured"}, outputs[generation.GetID()])
+
+	// The builder keeps the raw output
+	assert.Equal(t, "Here is **your** answer", trace.output)
+}
+
+func TestOutputFormatter_DefaultIsIdentity(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	span := lf.Trace("agent").Span("tool").Output(`{"raw": true}`)
+	assert.Equal(t, `{"raw": true}`, span.toSpanUpdateEvent().Output)
+	assert.Error(t, config.WithOutputFormatter(nil)(config.DefaultConfig()))
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+const (
+	// defaultSessionTTL is how long a session stays active without activity unless
+	// WithSessionTTL is given
+	defaultSessionTTL = 30 * time.Minute
+
+	// sessionLookupLimit is the number of a user's sessions inspected for an active one
+	sessionLookupLimit = 50
+)
+
+// SessionOption configures GetOrCreateSession
+type SessionOption func(*sessionOptions)
+
+// sessionOptions holds the settings applied by SessionOption
+type sessionOptions struct {
+	ttl time.Duration
+}
+
+// WithSessionTTL sets how long after its last activity a session is still considered
+// active and reused (default 30 minutes)
+func WithSessionTTL(d time.Duration) SessionOption {
+	return func(o *sessionOptions) {
+		o.ttl = d
+	}
+}
+
+// GetOrCreateSession returns the ID of the user's active session, creating a session if
+// the user has none. A session is active if it was created or updated within the TTL;
+// when several are, the most recent one is returned.
+//
+// Session IDs are cached per user for the TTL, so repeated calls do not hit the API.
+// Concurrent calls for the same user share a single lookup, so they never create
+// more than one session.
+//
+// Example:
+//
+//	sessionID, err := langfuse.GetOrCreateSession(ctx, user.ID, client.WithSessionTTL(time.Hour))
+//	if err != nil {
+//		return err
+//	}
+//	trace := langfuse.Trace("chat-turn").WithUserID(user.ID).WithSessionID(sessionID)
+func (lf *Langfuse) GetOrCreateSession(ctx context.Context, userID string, opts ...SessionOption) (sessionID string, err error) {
+	if lf.isDisabled() {
+		return "", fmt.Errorf("client is disabled")
+	}
+	if userID == "" {
+		return "", fmt.Errorf("user ID cannot be empty")
+	}
+
+	options := sessionOptions{ttl: defaultSessionTTL}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.ttl <= 0 {
+		return "", fmt.Errorf("session TTL must be positive")
+	}
+
+	return lf.sessions.get(userID, options.ttl, func() (string, time.Time, error) {
+		return lf.findOrCreateSession(ctx, userID, options.ttl)
+	})
+}
+
+// findOrCreateSession looks up the user's most recent active session and creates one
+// if there is none. It returns the session ID and the time of its last activity.
+func (lf *Langfuse) findOrCreateSession(ctx context.Context, userID string, ttl time.Duration) (string, time.Time, error) {
+	response, err := lf.apiClient.Sessions.ListByUser(ctx, userID, sessionLookupLimit)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to look up sessions of user %s: %w", userID, err)
+	}
+
+	now := time.Now()
+	var (
+		latestID       string
+		latestActivity time.Time
+	)
+	for _, session := range response.Data {
+		activity := sessionActivity(session)
+		if session.ID == "" || now.Sub(activity) >= ttl {
+			continue
+		}
+		if latestID == "" || activity.After(latestActivity) {
+			latestID, latestActivity = session.ID, activity
+		}
+	}
+	if latestID != "" {
+		return latestID, latestActivity, nil
+	}
+
+	session, err := lf.apiClient.Sessions.CreateForUser(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create session for user %s: %w", userID, err)
+	}
+	if session.ID == "" {
+		return "", time.Time{}, fmt.Errorf("created session for user %s has no ID", userID)
+	}
+	return session.ID, now, nil
+}
+
+// sessionActivity returns the time of a session's last known activity
+func sessionActivity(session commonTypes.Session) time.Time {
+	if session.UpdatedAt.After(session.CreatedAt) {
+		return session.UpdatedAt
+	}
+	return session.CreatedAt
+}
+
+// sessionCache holds the active session of each user, shared by derived clients
+type sessionCache struct {
+	mu       sync.Mutex
+	entries  map[string]cachedSession
+	inflight map[string]*sessionLookup
+}
+
+// cachedSession is a session ID and the time of its last known activity
+type cachedSession struct {
+	id         string
+	activityAt time.Time
+}
+
+// sessionLookup is a lookup in progress, waited on by concurrent callers for the same user
+type sessionLookup struct {
+	done      chan struct{}
+	sessionID string
+	err       error
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{
+		entries:  make(map[string]cachedSession),
+		inflight: make(map[string]*sessionLookup),
+	}
+}
+
+// get returns the cached session of userID if it is still within ttl, and otherwise runs
+// lookup once for all concurrent callers and caches its result
+func (c *sessionCache) get(userID string, ttl time.Duration, lookup func() (string, time.Time, error)) (string, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[userID]; ok && time.Since(entry.activityAt) < ttl {
+		c.mu.Unlock()
+		return entry.id, nil
+	}
+	if call, ok := c.inflight[userID]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.sessionID, call.err
+	}
+	call := &sessionLookup{done: make(chan struct{})}
+	c.inflight[userID] = call
+	c.mu.Unlock()
+
+	sessionID, activityAt, err := lookup()
+
+	c.mu.Lock()
+	delete(c.inflight, userID)
+	if err == nil {
+		c.entries[userID] = cachedSession{id: sessionID, activityAt: activityAt}
+	}
+	c.mu.Unlock()
+
+	call.sessionID, call.err = sessionID, err
+	close(call.done)
+	return sessionID, err
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// sessionServer serves the sessions endpoint from an in-memory list of sessions
+type sessionServer struct {
+	mu       sync.Mutex
+	sessions []commonTypes.Session
+	lists    int32
+	creates  int32
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		atomic.AddInt32(&s.lists, 1)
+		userID := r.URL.Query().Get("userId")
+		data := []commonTypes.Session{}
+		for _, session := range s.sessions {
+			if session.UserID != nil && *session.UserID == userID {
+				data = append(data, session)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	case http.MethodPost:
+		atomic.AddInt32(&s.creates, 1)
+		var req struct {
+			UserID *string `json:"userId"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		now := time.Now()
+		session := commonTypes.Session{ID: "created-" + *req.UserID, UserID: req.UserID, CreatedAt: now, UpdatedAt: now}
+		s.sessions = append(s.sessions, session)
+		json.NewEncoder(w).Encode(session)
+	}
+}
+
+func (s *sessionServer) add(id, userID string, activity time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = append(s.sessions, commonTypes.Session{ID: id, UserID: &userID, CreatedAt: activity.Add(-time.Hour), UpdatedAt: activity})
+}
+
+func newSessionTestClient(t *testing.T) (*Langfuse, *sessionServer) {
+	server := &sessionServer{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/sessions", server)
+	return newTestLangfuse(t, mux), server
+}
+
+func TestLangfuse_GetOrCreateSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reuses the most recent active session", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+		server.add("stale", "user-1", time.Now().Add(-2*time.Hour))
+		server.add("older", "user-1", time.Now().Add(-10*time.Minute))
+		server.add("recent", "user-1", time.Now().Add(-time.Minute))
+		server.add("other-user", "user-2", time.Now())
+
+		sessionID, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "recent", sessionID)
+		assert.EqualValues(t, 0, atomic.LoadInt32(&server.creates))
+	})
+
+	t.Run("creates a session when none is active", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+		server.add("stale", "user-1", time.Now().Add(-2*time.Hour))
+
+		sessionID, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "created-user-1", sessionID)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&server.creates))
+	})
+
+	t.Run("ttl decides which sessions are active", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+		server.add("stale", "user-1", time.Now().Add(-2*time.Hour))
+
+		sessionID, err := lf.GetOrCreateSession(ctx, "user-1", WithSessionTTL(3*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "stale", sessionID)
+	})
+
+	t.Run("caches the session per user", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+
+		first, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		second, err := lf.WithUserID("user-1").GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		other, err := lf.GetOrCreateSession(ctx, "user-2")
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&server.lists), "the second call for user-1 is served from the cache")
+		assert.EqualValues(t, 2, atomic.LoadInt32(&server.creates))
+	})
+
+	t.Run("cached session expires with the ttl", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+
+		_, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = lf.GetOrCreateSession(ctx, "user-1", WithSessionTTL(10*time.Millisecond))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&server.lists))
+		assert.EqualValues(t, 2, atomic.LoadInt32(&server.creates))
+	})
+
+	t.Run("concurrent callers create one session", func(t *testing.T) {
+		lf, server := newSessionTestClient(t)
+
+		var wg sync.WaitGroup
+		ids := make([]string, 10)
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id, err := lf.GetOrCreateSession(ctx, "user-1")
+				assert.NoError(t, err)
+				ids[i] = id
+			}(i)
+		}
+		wg.Wait()
+
+		for _, id := range ids {
+			assert.Equal(t, "created-user-1", id)
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt32(&server.creates))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		lf, _ := newSessionTestClient(t)
+
+		_, err := lf.GetOrCreateSession(ctx, "")
+		assert.Error(t, err)
+		_, err = lf.GetOrCreateSession(ctx, "user-1", WithSessionTTL(0))
+		assert.Error(t, err)
+	})
+
+	t.Run("lookup errors are not cached", func(t *testing.T) {
+		var fail atomic.Bool
+		fail.Store(true)
+		server := &sessionServer{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/public/sessions", func(w http.ResponseWriter, r *http.Request) {
+			if fail.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			server.ServeHTTP(w, r)
+		})
+		lf := newTestLangfuse(t, mux)
+
+		_, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to look up sessions of user user-1")
+
+		fail.Store(false)
+		sessionID, err := lf.GetOrCreateSession(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "created-user-1", sessionID)
+	})
+}
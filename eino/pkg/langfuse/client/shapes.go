@@ -0,0 +1,84 @@
+package client
+
+// ObservationKindMetadataKey holds the shape of observations created with LLMCall,
+// ToolCall and Retrieval, so they can be filtered consistently across services
+const ObservationKindMetadataKey = "observationKind"
+
+// Observation kinds set under ObservationKindMetadataKey
+const (
+	ObservationKindLLM       = "llm"
+	ObservationKindTool      = "tool"
+	ObservationKindRetrieval = "retrieval"
+)
+
+// ToolNameMetadataKey holds the tool name of observations created with ToolCall
+const ToolNameMetadataKey = "toolName"
+
+// LLMCall creates a standalone generation for a call to model, marked with the "llm"
+// observation kind. Set the prompt with Input or WithChatInput, then the completion and
+// usage before ending it.
+//
+// Example:
+//
+//	llm := langfuse.LLMCall("answer", "claude-sonnet-4").WithChatInput(messages)
+//	// ... call the model
+//	llm.Output(reply).UsageTokens(inputTokens, outputTokens).End(ctx)
+func (lf *Langfuse) LLMCall(name, model string) *GenerationBuilder {
+	return asLLMCall(lf.Generation(name), model)
+}
+
+// ToolCall creates a standalone span for a call of the tool name, marked with the "tool"
+// observation kind and the tool name in metadata. Set the arguments as input and the
+// tool result as output.
+func (lf *Langfuse) ToolCall(name string) *SpanBuilder {
+	return asToolCall(lf.Span(name), name)
+}
+
+// Retrieval creates a standalone span for a retrieval step, such as a vector search,
+// marked with the "retrieval" observation kind. Set the query as input and the
+// retrieved documents as output.
+func (lf *Langfuse) Retrieval(name string) *SpanBuilder {
+	return asRetrieval(lf.Span(name))
+}
+
+// LLMCall creates a generation within this trace, like Langfuse.LLMCall
+func (tb *TraceBuilder) LLMCall(name, model string) *GenerationBuilder {
+	return asLLMCall(tb.Generation(name), model)
+}
+
+// ToolCall creates a span within this trace, like Langfuse.ToolCall
+func (tb *TraceBuilder) ToolCall(name string) *SpanBuilder {
+	return asToolCall(tb.Span(name), name)
+}
+
+// Retrieval creates a span within this trace, like Langfuse.Retrieval
+func (tb *TraceBuilder) Retrieval(name string) *SpanBuilder {
+	return asRetrieval(tb.Span(name))
+}
+
+// ChildLLMCall creates a generation nested under this span, like Langfuse.LLMCall
+func (sb *SpanBuilder) ChildLLMCall(name, model string) *GenerationBuilder {
+	return asLLMCall(sb.ChildGeneration(name), model)
+}
+
+// ChildToolCall creates a span nested under this span, like Langfuse.ToolCall
+func (sb *SpanBuilder) ChildToolCall(name string) *SpanBuilder {
+	return asToolCall(sb.ChildSpan(name), name)
+}
+
+// ChildRetrieval creates a span nested under this span, like Langfuse.Retrieval
+func (sb *SpanBuilder) ChildRetrieval(name string) *SpanBuilder {
+	return asRetrieval(sb.ChildSpan(name))
+}
+
+func asLLMCall(gb *GenerationBuilder, model string) *GenerationBuilder {
+	return gb.Model(model).AddMetadata(ObservationKindMetadataKey, ObservationKindLLM)
+}
+
+func asToolCall(sb *SpanBuilder, name string) *SpanBuilder {
+	return sb.AddMetadata(ObservationKindMetadataKey, ObservationKindTool).AddMetadata(ToolNameMetadataKey, name)
+}
+
+func asRetrieval(sb *SpanBuilder) *SpanBuilder {
+	return sb.AddMetadata(ObservationKindMetadataKey, ObservationKindRetrieval)
+}
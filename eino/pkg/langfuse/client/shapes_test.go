@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShapes_StandaloneConstructors(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	llm := lf.LLMCall("answer", "claude-sonnet-4")
+	tool := lf.ToolCall("web-search")
+	retrieval := lf.Retrieval("vector-search")
+	require.NoError(t, llm.Submit(ctx))
+	require.NoError(t, tool.Submit(ctx))
+	require.NoError(t, retrieval.Submit(ctx))
+
+	bodies := flushedBodiesByID(t, lf, recorder)
+
+	body := bodies[llm.GetID()]
+	require.NotNil(t, body)
+	assert.Equal(t, "GENERATION", body["type"])
+	assert.Equal(t, "answer", body["name"])
+	assert.Equal(t, "claude-sonnet-4", body["model"])
+	assert.Equal(t, map[string]interface{}{ObservationKindMetadataKey: "llm"}, body["metadata"])
+
+	body = bodies[tool.GetID()]
+	require.NotNil(t, body)
+	assert.Equal(t, "SPAN", body["type"])
+	assert.Equal(t, map[string]interface{}{ObservationKindMetadataKey: "tool", ToolNameMetadataKey: "web-search"}, body["metadata"])
+
+	body = bodies[retrieval.GetID()]
+	require.NotNil(t, body)
+	assert.Equal(t, "SPAN", body["type"])
+	assert.Equal(t, map[string]interface{}{ObservationKindMetadataKey: "retrieval"}, body["metadata"])
+}
+
+func TestShapes_NestedConstructors(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+
+	trace := lf.Trace("rag")
+	pipeline := trace.Span("pipeline")
+	type observation struct {
+		id, parent, kind string
+		submit           func(context.Context) error
+	}
+	var observations []observation
+	add := func(id, parent, kind string, submit func(context.Context) error) {
+		observations = append(observations, observation{id, parent, kind, submit})
+	}
+
+	retrieval := trace.Retrieval("vector-search").Input("what is langfuse?").AddMetadata("topK", 5)
+	add(retrieval.GetID(), "", "retrieval", retrieval.Submit)
+	tool := trace.ToolCall("calculator")
+	add(tool.GetID(), "", "tool", tool.Submit)
+	llm := trace.LLMCall("answer", "claude-sonnet-4")
+	add(llm.GetID(), "", "llm", llm.Submit)
+	childRetrieval := pipeline.ChildRetrieval("rerank")
+	add(childRetrieval.GetID(), pipeline.GetID(), "retrieval", childRetrieval.Submit)
+	childTool := pipeline.ChildToolCall("calculator")
+	add(childTool.GetID(), pipeline.GetID(), "tool", childTool.Submit)
+	childLLM := pipeline.ChildLLMCall("summarize", "claude-haiku-4")
+	add(childLLM.GetID(), pipeline.GetID(), "llm", childLLM.Submit)
+
+	for _, observation := range observations {
+		require.NoError(t, observation.submit(ctx))
+	}
+	bodies := flushedBodiesByID(t, lf, recorder)
+
+	for _, observation := range observations {
+		body := bodies[observation.id]
+		require.NotNil(t, body, observation.id)
+		assert.Equal(t, trace.GetID(), body["traceId"])
+		assert.Equal(t, observation.kind, body["metadata"].(map[string]interface{})[ObservationKindMetadataKey])
+		if observation.parent != "" {
+			assert.Equal(t, observation.parent, body["parentObservationId"])
+		}
+	}
+	assert.EqualValues(t, 5, bodies[retrieval.GetID()]["metadata"].(map[string]interface{})["topK"], "defaults can be extended")
+	assert.Equal(t, "claude-haiku-4", bodies[childLLM.GetID()]["model"])
+}
+
+func TestShapes_DisabledClient(t *testing.T) {
+	lf := newDisabledClient(DefaultConfig())
+
+	assert.NoError(t, lf.LLMCall("answer", "claude-sonnet-4").Err())
+	assert.NoError(t, lf.ToolCall("web-search").Err())
+	assert.NoError(t, lf.Retrieval("vector-search").Err())
+}
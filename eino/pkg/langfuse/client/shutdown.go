@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// ForcedEndMetadataKey is the metadata key set on builders ended by Shutdown
+	ForcedEndMetadataKey = "forcedEnd"
+
+	// ForcedEndShutdown is the ForcedEndMetadataKey value for builders ended by Shutdown
+	ForcedEndShutdown = "shutdown"
+)
+
+// endOpenBuilders waits up to the configured grace period for open builders to be ended
+// and then, if enabled, force-ends the ones that remain. It runs before the queue is
+// flushed so the resulting events are still sent.
+func (lf *Langfuse) endOpenBuilders(ctx context.Context) error {
+	if lf.registry == nil {
+		return nil
+	}
+
+	if grace := lf.config.ShutdownGracePeriod; grace > 0 {
+		lf.registry.waitEmpty(ctx, grace)
+	}
+
+	if !lf.config.ForceEndOnShutdown {
+		return nil
+	}
+
+	var errs []error
+	for _, b := range lf.registry.drain() {
+		if err := b.forceEnd(ctx); err != nil {
+			desc := b.describe()
+			errs = append(errs, fmt.Errorf("failed to end %s %q (%s): %w", desc.Kind, desc.Name, desc.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// waitEmpty blocks until every registered builder has been ended, the timeout elapses or
+// ctx is done
+func (r *builderRegistry) waitEmpty(ctx context.Context, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		r.mu.Lock()
+		if len(r.live) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		if r.drained == nil {
+			r.drained = make(chan struct{})
+		}
+		drained := r.drained
+		r.mu.Unlock()
+
+		select {
+		case <-drained:
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// drain removes every registered builder and returns them, oldest first. Removing them
+// up front means concurrent Shutdown calls never end the same builder twice.
+func (r *builderRegistry) drain() []trackedBuilder {
+	r.mu.Lock()
+	builders := make([]trackedBuilder, 0, len(r.live))
+	createdAt := make(map[trackedBuilder]time.Time, len(r.live))
+	for b, t := range r.live {
+		builders = append(builders, b)
+		createdAt[b] = t
+	}
+	r.live = make(map[trackedBuilder]time.Time)
+	r.mu.Unlock()
+
+	// Parents are created before their children, so they are ended first
+	sort.Slice(builders, func(i, j int) bool {
+		return createdAt[builders[i]].Before(createdAt[builders[j]])
+	})
+	return builders
+}
+
+func (tb *TraceBuilder) forceEnd(ctx context.Context) error {
+	return tb.AddMetadata(ForcedEndMetadataKey, ForcedEndShutdown).End(ctx)
+}
+
+func (sb *SpanBuilder) forceEnd(ctx context.Context) error {
+	return sb.AddMetadata(ForcedEndMetadataKey, ForcedEndShutdown).End(ctx)
+}
+
+func (gb *GenerationBuilder) forceEnd(ctx context.Context) error {
+	return gb.AddMetadata(ForcedEndMetadataKey, ForcedEndShutdown).End(ctx)
+}
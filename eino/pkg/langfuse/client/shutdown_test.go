@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// recordedBodies returns the recorded event bodies keyed by object ID
+func recordedBodies(recorder *ingestionRecorder) map[string]map[string]interface{} {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		bodies[body["id"].(string)] = body
+	}
+	return bodies
+}
+
+func TestLangfuse_Shutdown_ForceEndsOpenBuilders(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.ForceEndOnShutdown = true
+	})
+
+	trace := lf.Trace("deploy")
+	span := trace.Span("in-progress")
+
+	require.NoError(t, lf.Shutdown(context.Background()))
+
+	bodies := recordedBodies(recorder)
+	for _, id := range []string{trace.GetID(), span.GetID()} {
+		body := bodies[id]
+		require.NotNil(t, body, "no event for %s", id)
+		assert.Equal(t, ForcedEndShutdown, body["metadata"].(map[string]interface{})[ForcedEndMetadataKey])
+	}
+	assert.NotNil(t, bodies[span.GetID()]["endTime"])
+	assert.Empty(t, lf.UnendedBuilders())
+}
+
+func TestLangfuse_Shutdown_WaitsForGracePeriod(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.ShutdownGracePeriod = 5 * time.Second
+		cfg.ForceEndOnShutdown = true
+	})
+
+	generation := lf.Generation("finishing")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		generation.End(context.Background())
+	}()
+
+	start := time.Now()
+	require.NoError(t, lf.Shutdown(context.Background()))
+	assert.Less(t, time.Since(start), 5*time.Second, "shutdown should stop waiting once builders end")
+
+	body := recordedBodies(recorder)[generation.GetID()]
+	require.NotNil(t, body)
+	assert.NotContains(t, body["metadata"], ForcedEndMetadataKey, "a builder ended during the grace period is not marked")
+}
+
+func TestLangfuse_Shutdown_WithoutForceEnd(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.ShutdownGracePeriod = 10 * time.Millisecond
+	})
+
+	span := lf.Trace("deploy").Span("abandoned")
+
+	// Open builders are not reported as an error outside strict mode
+	require.NoError(t, lf.Shutdown(context.Background()))
+	assert.NotContains(t, recordedBodies(recorder), span.GetID())
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultSignalShutdownTimeout is used when Config.SignalShutdownTimeout is not set
+const defaultSignalShutdownTimeout = 10 * time.Second
+
+// Process hooks, replaced in tests
+var (
+	exitProcess = os.Exit
+	raiseSignal = func(sig os.Signal) error {
+		process, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+		return process.Signal(sig)
+	}
+)
+
+// ShutdownOnSignal flushes pending events before the process exits. When one of signals
+// (default SIGTERM and SIGINT) is received, Shutdown is called with a deadline of
+// Config.SignalShutdownTimeout. Afterwards the process exits with Config.ExitCode if
+// Config.ExitAfterShutdown is set; otherwise the handler is removed and the signal is
+// raised again so the process terminates as it would have without it.
+//
+// The handler only registers its own channel with os/signal, so it can be combined with
+// the application's signal handling (e.g. signal.NotifyContext); note that re-raising
+// delivers the signal to the application's handlers a second time. Shutdown runs once
+// no matter how many signals arrive. The returned stop function removes the handler.
+//
+// Example:
+//
+//	lf, _ := client.New(cfg)
+//	stop := lf.ShutdownOnSignal()
+//	defer stop()
+func (lf *Langfuse) ShutdownOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	handler := lf.newSignalHandler(func() { signal.Stop(ch) })
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case sig := <-ch:
+				handler.handle(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// signalHandler shuts the client down on the first signal it handles
+type signalHandler struct {
+	shutdown          func(ctx context.Context) error
+	release           func()
+	timeout           time.Duration
+	exitAfterShutdown bool
+	exitCode          int
+	once              sync.Once
+}
+
+// newSignalHandler creates a handler for the client; release removes the signal
+// registration before the signal is raised again
+func (lf *Langfuse) newSignalHandler(release func()) *signalHandler {
+	return &signalHandler{
+		shutdown:          lf.Shutdown,
+		release:           release,
+		timeout:           lf.signalShutdownTimeout(),
+		exitAfterShutdown: lf.config.ExitAfterShutdown,
+		exitCode:          lf.config.ExitCode,
+	}
+}
+
+// signalShutdownTimeout returns the configured deadline of shutdowns triggered by signals
+func (lf *Langfuse) signalShutdownTimeout() time.Duration {
+	if lf.config.SignalShutdownTimeout <= 0 {
+		return defaultSignalShutdownTimeout
+	}
+	return lf.config.SignalShutdownTimeout
+}
+
+// handle shuts down on the first call and ignores later ones
+func (h *signalHandler) handle(sig os.Signal) {
+	h.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		defer cancel()
+
+		// The process is going down either way, so a failed or timed out flush is not fatal
+		h.shutdown(ctx)
+
+		if h.exitAfterShutdown {
+			exitProcess(h.exitCode)
+			return
+		}
+
+		h.release()
+		raiseSignal(sig)
+	})
+}
+
+// GracefulShutdown shuts the client down when one of signals (default SIGTERM and
+// SIGINT) is received, within timeout (Config.SignalShutdownTimeout when not positive),
+// and sends the result of Shutdown on the returned channel, which is then closed. Unlike
+// ShutdownOnSignal it neither exits nor raises the signal again: the caller decides how
+// to stop, typically after receiving from the channel.
+//
+// Call it once per client, usually from main; every call registers its own handler and
+// shuts the client down again. Once the first signal is handled, the handler is removed,
+// so a second signal terminates the process as usual.
+//
+// Example:
+//
+//	done := lf.GracefulShutdown(10 * time.Second)
+//	go server.ListenAndServe()
+//	if err := <-done; err != nil {
+//		log.Printf("langfuse shutdown: %v", err)
+//	}
+func (lf *Langfuse) GracefulShutdown(timeout time.Duration, signals ...os.Signal) <-chan error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	if timeout <= 0 {
+		timeout = lf.signalShutdownTimeout()
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	result := make(chan error, 1)
+	go func() {
+		defer close(result)
+		<-ch
+		signal.Stop(ch)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		result <- lf.Shutdown(ctx)
+	}()
+	return result
+}
+
+// GracefulShutdownBlocking blocks until one of signals is received and the client is
+// shut down, returning the result of Shutdown. It suits simple scripts and jobs; see
+// GracefulShutdown, including calling it only once.
+func (lf *Langfuse) GracefulShutdownBlocking(timeout time.Duration, signals ...os.Signal) error {
+	return <-lf.GracefulShutdown(timeout, signals...)
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// stubProcessHooks replaces exitProcess and raiseSignal for the duration of the test
+func stubProcessHooks(t *testing.T) (exitCodes chan int, raised chan os.Signal) {
+	exitCodes = make(chan int, 10)
+	raised = make(chan os.Signal, 10)
+
+	originalExit, originalRaise := exitProcess, raiseSignal
+	exitProcess = func(code int) { exitCodes <- code }
+	raiseSignal = func(sig os.Signal) error {
+		raised <- sig
+		return nil
+	}
+	t.Cleanup(func() {
+		exitProcess, raiseSignal = originalExit, originalRaise
+	})
+	return exitCodes, raised
+}
+
+func TestSignalHandler_ShutsDownOnce(t *testing.T) {
+	_, raised := stubProcessHooks(t)
+
+	var shutdowns, releases int32
+	handler := &signalHandler{
+		shutdown: func(ctx context.Context) error {
+			atomic.AddInt32(&shutdowns, 1)
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline, "shutdown should be bounded by the timeout")
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+		release: func() { atomic.AddInt32(&releases, 1) },
+		timeout: time.Second,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.handle(syscall.SIGTERM)
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&shutdowns))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&releases))
+	require.Len(t, raised, 1)
+	assert.Equal(t, syscall.SIGTERM, <-raised)
+}
+
+func TestLangfuse_ShutdownOnSignal(t *testing.T) {
+	exitCodes, raised := stubProcessHooks(t)
+
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		require.NoError(t, config.WithSignalShutdown(time.Second, true, 3)(cfg))
+	})
+
+	// The application's own handling keeps working next to the helper
+	appCtx, appStop := signal.NotifyContext(context.Background(), syscall.SIGHUP)
+	defer appStop()
+
+	stop := lf.ShutdownOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	require.NoError(t, lf.Trace("before-exit").Submit(context.Background()))
+
+	// A single signal is sent: once both handlers are stopped, a signal still in flight
+	// would terminate the test binary. Repeated signals are covered by the handler test.
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+
+	select {
+	case code := <-exitCodes:
+		assert.Equal(t, 3, code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown was not triggered by the signal")
+	}
+	<-appCtx.Done()
+
+	assert.Empty(t, raised, "the signal is not re-raised when exiting")
+	assert.False(t, lf.IsEnabled())
+
+	recorder.mu.Lock()
+	assert.Len(t, recorder.events, 1, "pending events are flushed before exit")
+	recorder.mu.Unlock()
+}
+
+// notifySafetyNet keeps os.Interrupt from terminating the test binary while the test
+// sends it, including signals still in flight when the test ends
+func notifySafetyNet(t *testing.T) {
+	safetyNet := make(chan os.Signal, 10)
+	signal.Notify(safetyNet, os.Interrupt)
+	t.Cleanup(func() {
+		time.Sleep(50 * time.Millisecond)
+		signal.Stop(safetyNet)
+	})
+}
+
+func TestLangfuse_GracefulShutdown(t *testing.T) {
+	notifySafetyNet(t)
+
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+
+	done := lf.GracefulShutdown(time.Second, os.Interrupt)
+	require.NoError(t, lf.Trace("before-exit").Submit(context.Background()))
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(os.Interrupt))
+
+	select {
+	case err, ok := <-done:
+		require.True(t, ok)
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown was not triggered by the signal")
+	}
+	_, open := <-done
+	assert.False(t, open, "the channel is closed after the result")
+	assert.False(t, lf.IsEnabled())
+
+	recorder.mu.Lock()
+	assert.Len(t, recorder.events, 1, "pending events are flushed")
+	recorder.mu.Unlock()
+}
+
+func TestLangfuse_GracefulShutdownBlocking(t *testing.T) {
+	notifySafetyNet(t)
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	result := make(chan error, 1)
+	go func() { result <- lf.GracefulShutdownBlocking(time.Second, os.Interrupt) }()
+
+	// The handler is registered asynchronously, so signal until it has handled one
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case err := <-result:
+			assert.NoError(t, err)
+			assert.False(t, lf.IsEnabled())
+			return
+		case <-ticker.C:
+			require.NoError(t, process.Signal(os.Interrupt))
+		case <-deadline:
+			t.Fatal("GracefulShutdownBlocking did not return")
+		}
+	}
+}
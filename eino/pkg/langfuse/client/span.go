@@ -2,6 +2,10 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"eino/pkg/langfuse/api/resources/commons/types"
@@ -11,6 +15,7 @@ import (
 
 // SpanBuilder provides a fluent API for building span observations
 type SpanBuilder struct {
+	mu                   sync.Mutex // guards every field, so the span can be shared across goroutines
 	id                   string
 	traceID              string
 	parentObservationID  *string
@@ -25,11 +30,19 @@ type SpanBuilder struct {
 	version              *string
 	client               *Langfuse
 	submitted            bool
+	err                  error
+	snapshot             deltaSnapshot
+	payloadMode          PayloadMode
+	environment          string
+	begun                bool
+	trace                *TraceBuilder // trace the span was created under, notified when it ends
+	attrErr              *ValidationError
+	noop                 bool // rate-limited: every operation succeeds without sending anything
 }
 
 // NewSpanBuilder creates a new SpanBuilder instance
 func NewSpanBuilder(client *Langfuse, traceID string) *SpanBuilder {
-	return &SpanBuilder{
+	sb := &SpanBuilder{
 		id:        utils.GenerateObservationID(),
 		traceID:   traceID,
 		startTime: time.Now().UTC(),
@@ -37,11 +50,17 @@ func NewSpanBuilder(client *Langfuse, traceID string) *SpanBuilder {
 		client:    client,
 		metadata:  make(map[string]interface{}),
 	}
+
+	client.registerBuilder(sb)
+	return sb
 }
 
 // ID sets the span ID
 func (sb *SpanBuilder) ID(id string) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("ID")
 		return sb
 	}
 	sb.id = id
@@ -50,7 +69,10 @@ func (sb *SpanBuilder) ID(id string) *SpanBuilder {
 
 // ParentObservationID sets the parent observation ID
 func (sb *SpanBuilder) ParentObservationID(parentID string) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("ParentObservationID")
 		return sb
 	}
 	sb.parentObservationID = &parentID
@@ -59,7 +81,10 @@ func (sb *SpanBuilder) ParentObservationID(parentID string) *SpanBuilder {
 
 // Name sets the span name
 func (sb *SpanBuilder) Name(name string) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Name")
 		return sb
 	}
 	sb.name = name
@@ -68,7 +93,10 @@ func (sb *SpanBuilder) Name(name string) *SpanBuilder {
 
 // StartTime sets the start time
 func (sb *SpanBuilder) StartTime(startTime time.Time) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("StartTime")
 		return sb
 	}
 	sb.startTime = startTime.UTC()
@@ -77,7 +105,10 @@ func (sb *SpanBuilder) StartTime(startTime time.Time) *SpanBuilder {
 
 // EndTime sets the end time
 func (sb *SpanBuilder) EndTime(endTime time.Time) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("EndTime")
 		return sb
 	}
 	endTimeUTC := endTime.UTC()
@@ -87,7 +118,10 @@ func (sb *SpanBuilder) EndTime(endTime time.Time) *SpanBuilder {
 
 // Input sets the input data
 func (sb *SpanBuilder) Input(input interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Input")
 		return sb
 	}
 	sb.input = input
@@ -96,7 +130,10 @@ func (sb *SpanBuilder) Input(input interface{}) *SpanBuilder {
 
 // Output sets the output data
 func (sb *SpanBuilder) Output(output interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Output")
 		return sb
 	}
 	sb.output = output
@@ -105,7 +142,10 @@ func (sb *SpanBuilder) Output(output interface{}) *SpanBuilder {
 
 // Metadata sets the metadata map
 func (sb *SpanBuilder) Metadata(metadata map[string]interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Metadata")
 		return sb
 	}
 	sb.metadata = metadata
@@ -114,7 +154,10 @@ func (sb *SpanBuilder) Metadata(metadata map[string]interface{}) *SpanBuilder {
 
 // AddMetadata adds a single metadata key-value pair
 func (sb *SpanBuilder) AddMetadata(key string, value interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("AddMetadata")
 		return sb
 	}
 	if sb.metadata == nil {
@@ -124,9 +167,75 @@ func (sb *SpanBuilder) AddMetadata(key string, value interface{}) *SpanBuilder {
 	return sb
 }
 
+// WithAttributes merges attributes into the metadata, keeping existing keys that are not
+// in attributes, whereas Metadata replaces it. Attributes whose value cannot be encoded
+// as JSON are left out and reported when the span is submitted.
+func (sb *SpanBuilder) WithAttributes(attributes map[string]interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.submitted {
+		sb.recordMisuse("WithAttributes")
+		return sb
+	}
+	for key, value := range attributes {
+		sb.setAttribute(key, value)
+	}
+	return sb
+}
+
+// WithKV merges alternating key/value pairs into the metadata like WithAttributes:
+//
+//	span.WithKV("user", userID, "attempt", 2)
+//
+// Keys must be strings. An odd number of arguments, in which case nothing is merged, or
+// a non-string key is reported when the span is submitted.
+func (sb *SpanBuilder) WithKV(pairs ...interface{}) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.submitted {
+		sb.recordMisuse("WithKV")
+		return sb
+	}
+	if len(pairs)%2 != 0 {
+		sb.recordAttributeError(fmt.Sprintf("WithKV expects key/value pairs, got %d arguments", len(pairs)))
+		return sb
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			sb.recordAttributeError(fmt.Sprintf("WithKV key at position %d is a %T, not a string", i, pairs[i]))
+			continue
+		}
+		sb.setAttribute(key, pairs[i+1])
+	}
+	return sb
+}
+
+// setAttribute adds a metadata entry if its value can be encoded as JSON
+func (sb *SpanBuilder) setAttribute(key string, value interface{}) {
+	if _, err := json.Marshal(value); err != nil {
+		sb.recordAttributeError(fmt.Sprintf("attribute %q is not JSON-serializable: %v", key, err))
+		return
+	}
+	if sb.metadata == nil {
+		sb.metadata = make(map[string]interface{})
+	}
+	sb.metadata[key] = value
+}
+
+// recordAttributeError keeps the first invalid attribute, reported on submit
+func (sb *SpanBuilder) recordAttributeError(message string) {
+	if sb.attrErr == nil {
+		sb.attrErr = &ValidationError{Field: "metadata", Message: message}
+	}
+}
+
 // Level sets the observation level
 func (sb *SpanBuilder) Level(level types.ObservationLevel) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Level")
 		return sb
 	}
 	sb.level = level
@@ -150,7 +259,10 @@ func (sb *SpanBuilder) Error() *SpanBuilder {
 
 // StatusMessage sets the status message
 func (sb *SpanBuilder) StatusMessage(message string) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("StatusMessage")
 		return sb
 	}
 	sb.statusMessage = &message
@@ -159,7 +271,10 @@ func (sb *SpanBuilder) StatusMessage(message string) *SpanBuilder {
 
 // Version sets the version
 func (sb *SpanBuilder) Version(version string) *SpanBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	if sb.submitted {
+		sb.recordMisuse("Version")
 		return sb
 	}
 	sb.version = &version
@@ -168,16 +283,22 @@ func (sb *SpanBuilder) Version(version string) *SpanBuilder {
 
 // GetID returns the span ID
 func (sb *SpanBuilder) GetID() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	return sb.id
 }
 
 // GetTraceID returns the trace ID
 func (sb *SpanBuilder) GetTraceID() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	return sb.traceID
 }
 
 // GetName returns the span name
 func (sb *SpanBuilder) GetName() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
 	return sb.name
 }
 
@@ -225,15 +346,76 @@ func (sb *SpanBuilder) WithStatusMessage(message string) *SpanBuilder {
 	return sb.StatusMessage(message)
 }
 
+// WithErrorOutput marks the span as failed: the level is set to ERROR and the status
+// message and output to the message of err. A nil err is ignored.
+func (sb *SpanBuilder) WithErrorOutput(err error) *SpanBuilder {
+	if err == nil {
+		return sb
+	}
+	return sb.Error().StatusMessage(err.Error()).Output(errorOutput(err))
+}
+
 // ChildSpan creates a child span (placeholder - needs full implementation)
 func (sb *SpanBuilder) ChildSpan(name string) *SpanBuilder {
+	if sb.noop {
+		return newNoopSpanBuilder(sb.traceID, name)
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.client.touchUsageRollup(sb.traceID)
 	childSpan := NewSpanBuilder(sb.client, sb.traceID)
 	childSpan.ParentObservationID(sb.id)
+	childSpan.payloadMode = sb.payloadMode
+	childSpan.environment = sb.environment
+	childSpan.trace = sb.trace
 	return childSpan.Name(name)
 }
 
+// ChildGeneration creates a generation nested under this span
+func (sb *SpanBuilder) ChildGeneration(name string) *GenerationBuilder {
+	if sb.noop {
+		return newNoopGenerationBuilder(sb.traceID, name)
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.client == nil {
+		return newDisabledGenerationBuilder(name)
+	}
+	sb.client.touchUsageRollup(sb.traceID)
+	generation := NewGenerationBuilder(sb.client, sb.traceID)
+	generation.ParentObservationID(sb.id)
+	generation.payloadMode = sb.payloadMode
+	generation.environment = sb.environment
+	generation.trace = sb.trace
+	return generation.Name(name)
+}
+
+// Err returns misuse recorded in strict mode, such as modifying the span after it was ended
+func (sb *SpanBuilder) Err() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.err
+}
+
+// recordMisuse records a call to method after submission when strict mode is enabled
+func (sb *SpanBuilder) recordMisuse(method string) {
+	if !sb.client.strictMode() {
+		return
+	}
+	sb.err = errors.Join(sb.err, fmt.Errorf("%w: %s called on span %s", ErrModifiedAfterEnd, method, sb.id))
+}
+
+// alreadyEnded returns the strict mode error for ending the span more than once
+func (sb *SpanBuilder) alreadyEnded() error {
+	return fmt.Errorf("span %s: %w", sb.id, ErrAlreadyEnded)
+}
+
 // validate performs validation on the span builder
 func (sb *SpanBuilder) validate() error {
+	if sb.attrErr != nil {
+		return sb.attrErr
+	}
+
 	if sb.id == "" {
 		return &ValidationError{Field: "id", Message: "span id is required"}
 	}
@@ -268,12 +450,13 @@ func (sb *SpanBuilder) toObservationEvent() *ingestiontypes.ObservationEvent {
 		Name:                sb.name,
 		StartTime:           sb.startTime,
 		EndTime:             sb.endTime,
-		Input:               sb.input,
-		Output:              sb.output,
-		Metadata:            sb.metadata,
+		Input:               sb.client.serializeInput(sb.input, sb.payloadMode),
+		Output:              sb.client.serializeOutput(sb.output, sb.payloadMode),
+		Metadata:            sb.client.serializeMetadata(sb.metadata),
 		Level:               sb.level,
 		StatusMessage:       sb.statusMessage,
 		Version:             sb.version,
+		Environment:         sb.environment,
 	}
 }
 
@@ -287,15 +470,29 @@ func (sb *SpanBuilder) toSpanCreateEvent() *ingestiontypes.SpanCreateEvent {
 
 // toSpanUpdateEvent converts the builder to a SpanUpdateEvent
 func (sb *SpanBuilder) toSpanUpdateEvent() *ingestiontypes.SpanUpdateEvent {
-	return &ingestiontypes.SpanUpdateEvent{
+	event := &ingestiontypes.SpanUpdateEvent{
 		ObservationEvent: *sb.toObservationEvent(),
 		EventType:        "span-update",
 	}
+	// The update event is what ends the span, so this is where the output is normalized
+	event.Output = sb.client.serializeFinalOutput(sb.output, sb.payloadMode)
+	return event
 }
 
 // Submit submits the span to the ingestion queue
 func (sb *SpanBuilder) Submit(ctx context.Context) error {
+	if sb.noop {
+		return nil
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.begun {
+		return &ValidationError{Field: "state", Message: "span already begun"}
+	}
 	if sb.submitted {
+		if sb.client.strictMode() {
+			return sb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "span already submitted"}
 	}
 	
@@ -304,19 +501,79 @@ func (sb *SpanBuilder) Submit(ctx context.Context) error {
 	}
 	
 	event := sb.toSpanCreateEvent()
-	ingestionEvent := event.ToIngestionEvent()
+	ingestionEvent, err := ingestiontypes.NewSpanCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid span %s: %w", sb.id, err)
+	}
+	ingestionEvent.Source = ingestiontypes.EventSourceSpan
+	ingestionEvent.Project = sb.environment
 	
-	if err := sb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := sb.client.enqueueForTrace(sb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue span %s: %w", sb.id, err)
 	}
 	
 	sb.submitted = true
+	sb.client.deregisterBuilder(sb)
+	return sb.trace.childEnded(sb.level)
+}
+
+// Begin enqueues the span-create event right away and keeps the span open, so it is
+// visible in Langfuse while it runs. End, EndAt or Update then send the remaining
+// fields as a span-update.
+func (sb *SpanBuilder) Begin(ctx context.Context) error {
+	if sb.noop {
+		return nil
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.begun {
+		return &ValidationError{Field: "state", Message: "span already begun"}
+	}
+	if sb.submitted {
+		if sb.client.strictMode() {
+			return sb.alreadyEnded()
+		}
+		return &ValidationError{Field: "state", Message: "span already submitted"}
+	}
+	
+	if err := sb.validate(); err != nil {
+		return err
+	}
+	
+	event := sb.toSpanCreateEvent()
+	ingestionEvent, err := ingestiontypes.NewSpanCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid span %s: %w", sb.id, err)
+	}
+	ingestionEvent.Source = ingestiontypes.EventSourceSpan
+	ingestionEvent.Project = sb.environment
+	if err := sb.client.enqueueForTrace(sb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue span %s: %w", sb.id, err)
+	}
+	
+	sb.begun = true
+	if sb.client.deltaUpdates() {
+		sb.snapshot = takeDeltaSnapshot(event)
+	}
 	return nil
 }
 
 // Update updates an existing span
 func (sb *SpanBuilder) Update(ctx context.Context) error {
+	if sb.noop {
+		return nil
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.update(ctx)
+}
+
+// update sends the span-update event; the caller holds sb.mu
+func (sb *SpanBuilder) update(ctx context.Context) error {
 	if sb.submitted {
+		if sb.client.strictMode() {
+			return sb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "span already submitted"}
 	}
 	
@@ -325,14 +582,21 @@ func (sb *SpanBuilder) Update(ctx context.Context) error {
 	}
 	
 	event := sb.toSpanUpdateEvent()
-	ingestionEvent := event.ToIngestionEvent()
+	ingestionEvent, err := ingestiontypes.NewSpanUpdateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid span %s: %w", sb.id, err)
+	}
+	ingestionEvent.Body = sb.snapshot.diff(ingestionEvent.Body)
+	ingestionEvent.Source = ingestiontypes.EventSourceSpan
+	ingestionEvent.Project = sb.environment
 	
-	if err := sb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := sb.client.enqueueForTrace(sb.traceID, ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue span %s: %w", sb.id, err)
 	}
 	
 	sb.submitted = true
-	return nil
+	sb.client.deregisterBuilder(sb)
+	return sb.trace.childEnded(sb.level)
 }
 
 // End ends the span with the current timestamp and submits it
@@ -342,6 +606,25 @@ func (sb *SpanBuilder) End(ctx context.Context) error {
 
 // EndAt ends the span with a specific timestamp and submits it
 func (sb *SpanBuilder) EndAt(ctx context.Context, endTime time.Time) error {
-	sb.EndTime(endTime)
-	return sb.Update(ctx)
+	if sb.noop {
+		return nil
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	// Checking and ending under one lock makes End exactly-once for concurrent callers
+	if sb.submitted {
+		if sb.client.strictMode() {
+			return sb.alreadyEnded()
+		}
+	} else {
+		endTimeUTC := endTime.UTC()
+		fixes, err := sb.client.normalizeTimes("startTime", &sb.startTime, &endTimeUTC)
+		if err != nil {
+			return err
+		}
+		sb.endTime = &endTimeUTC
+		sb.metadata = withTimeAnomalies(sb.metadata, fixes)
+	}
+	return sb.update(ctx)
 }
\ No newline at end of file
@@ -146,6 +146,74 @@ func TestSpanBuilder_MetadataHandling(t *testing.T) {
 	assert.Equal(t, newMetadata, span2.metadata)
 }
 
+func TestSpanBuilder_WithAttributes(t *testing.T) {
+	client := createTestClient(t)
+	span := NewSpanBuilder(client, "trace-id").
+		Name("attributes").
+		AddMetadata("route", "/checkout").
+		WithAttributes(map[string]interface{}{"user": "u-1", "attempt": 1}).
+		WithAttributes(map[string]interface{}{"attempt": 2, "cached": true})
+
+	// Attributes merge into the metadata instead of replacing it
+	assert.Equal(t, map[string]interface{}{
+		"route":   "/checkout",
+		"user":    "u-1",
+		"attempt": 2,
+		"cached":  true,
+	}, span.metadata)
+	require.NoError(t, span.validate())
+
+	span.WithAttributes(map[string]interface{}{"callback": func() {}, "region": "eu"})
+	assert.NotContains(t, span.metadata, "callback")
+	assert.Equal(t, "eu", span.metadata["region"])
+
+	err := span.validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "metadata", validationErr.Field)
+	assert.Contains(t, validationErr.Message, "callback")
+}
+
+func TestSpanBuilder_WithKV(t *testing.T) {
+	client := createTestClient(t)
+
+	t.Run("pairs", func(t *testing.T) {
+		span := NewSpanBuilder(client, "trace-id").
+			Name("kv").
+			AddMetadata("route", "/checkout").
+			WithKV("user", "u-1", "attempt", 2).
+			WithKV()
+
+		assert.Equal(t, map[string]interface{}{"route": "/checkout", "user": "u-1", "attempt": 2}, span.metadata)
+		assert.NoError(t, span.validate())
+	})
+
+	t.Run("odd number of arguments", func(t *testing.T) {
+		span := NewSpanBuilder(client, "trace-id").Name("kv").WithKV("user", "u-1", "attempt")
+
+		assert.Empty(t, span.metadata, "nothing is merged")
+		err := span.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 arguments")
+	})
+
+	t.Run("non-string key", func(t *testing.T) {
+		span := NewSpanBuilder(client, "trace-id").Name("kv").WithKV(42, "answer", "user", "u-1")
+
+		assert.Equal(t, map[string]interface{}{"user": "u-1"}, span.metadata)
+		err := span.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "int")
+	})
+
+	t.Run("value that is not JSON-serializable", func(t *testing.T) {
+		span := NewSpanBuilder(client, "trace-id").Name("kv").WithKV("updates", make(chan int))
+
+		assert.NotContains(t, span.metadata, "updates")
+		assert.Error(t, span.validate())
+	})
+}
+
 func TestSpanBuilder_LevelHandling(t *testing.T) {
 	client := createTestClient(t)
 	
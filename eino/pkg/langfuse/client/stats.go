@@ -0,0 +1,80 @@
+package client
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clientCounters holds the statistics GetStats reports. Every field is updated
+// atomically, so creating builders from many goroutines does not contend on a lock.
+type clientCounters struct {
+	tracesCreated      atomic.Int64
+	spansCreated       atomic.Int64
+	generationsCreated atomic.Int64
+	eventsEnqueued     atomic.Int64
+	eventsSubmitted    atomic.Int64
+	eventsFailed       atomic.Int64
+	rateLimitedEvents  atomic.Int64
+
+	// lastActivity is the time of the last SDK activity in Unix nanoseconds, 0 before any
+	lastActivity atomic.Int64
+
+	// lastFlushErr is the error of the most recent batch submission, nil if it succeeded
+	lastFlushErr atomic.Pointer[error]
+
+	// flushFailures counts the batch submissions that failed since the last success
+	flushFailures atomic.Int64
+
+	createdAt time.Time
+}
+
+func newClientCounters() *clientCounters {
+	return &clientCounters{createdAt: time.Now()}
+}
+
+// touch records SDK activity now
+func (c *clientCounters) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// recordSubmission counts a batch of size events submitted with the outcome err
+func (c *clientCounters) recordSubmission(size int, err error) {
+	c.touch()
+	if err == nil {
+		c.eventsSubmitted.Add(int64(size))
+		c.lastFlushErr.Store(nil)
+		c.flushFailures.Store(0)
+		return
+	}
+	c.eventsFailed.Add(int64(size))
+	c.lastFlushErr.Store(&err)
+	c.flushFailures.Add(1)
+}
+
+// flushErr returns the error of the most recent batch submission
+func (c *clientCounters) flushErr() error {
+	if err := c.lastFlushErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// snapshot reads the counters into a ClientStats. Each counter is read atomically but
+// not together with the others, so a snapshot taken while events are recorded may be
+// off by the events in flight.
+func (c *clientCounters) snapshot() ClientStats {
+	stats := ClientStats{
+		TracesCreated:      c.tracesCreated.Load(),
+		SpansCreated:       c.spansCreated.Load(),
+		GenerationsCreated: c.generationsCreated.Load(),
+		EventsEnqueued:     c.eventsEnqueued.Load(),
+		EventsSubmitted:    c.eventsSubmitted.Load(),
+		EventsFailed:       c.eventsFailed.Load(),
+		RateLimitedEvents:  c.rateLimitedEvents.Load(),
+		CreatedAt:          c.createdAt,
+	}
+	if nanos := c.lastActivity.Load(); nanos != 0 {
+		stats.LastActivity = time.Unix(0, nanos)
+	}
+	return stats
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Strict mode errors
+var (
+	// ErrAlreadyEnded is returned in strict mode when a builder is ended or submitted more than once
+	ErrAlreadyEnded = errors.New("builder already ended")
+
+	// ErrModifiedAfterEnd is recorded in strict mode when a builder is modified after it was ended
+	ErrModifiedAfterEnd = errors.New("builder modified after end")
+)
+
+// LiveBuilder describes a builder that was created but never ended
+type LiveBuilder struct {
+	Kind      string
+	ID        string
+	TraceID   string
+	Name      string
+	CreatedAt time.Time
+}
+
+// UnendedBuildersError is returned by Shutdown in strict mode when builders were never ended
+type UnendedBuildersError struct {
+	Builders []LiveBuilder
+}
+
+// Error implements the error interface
+func (e *UnendedBuildersError) Error() string {
+	names := make([]string, 0, len(e.Builders))
+	for _, b := range e.Builders {
+		names = append(names, fmt.Sprintf("%s %q (%s)", b.Kind, b.Name, b.ID))
+	}
+	return fmt.Sprintf("%d builders were never ended: %s", len(e.Builders), strings.Join(names, ", "))
+}
+
+// trackedBuilder is implemented by builders that can be held in the live builder registry
+type trackedBuilder interface {
+	describe() LiveBuilder
+	forceEnd(ctx context.Context) error
+}
+
+// builderRegistry tracks builders between creation and submission. Builders are removed
+// explicitly when they are submitted, so ended builders are never retained.
+type builderRegistry struct {
+	mu   sync.Mutex
+	live map[trackedBuilder]time.Time
+
+	// drained is closed when the last live builder is removed, waking waitEmpty
+	drained chan struct{}
+}
+
+func newBuilderRegistry() *builderRegistry {
+	return &builderRegistry{live: make(map[trackedBuilder]time.Time)}
+}
+
+// strictMode reports whether strict mode is enabled for the client
+func (lf *Langfuse) strictMode() bool {
+	return lf != nil && lf.config != nil && lf.config.StrictMode
+}
+
+// registerBuilder adds a newly created builder to the registry, if the client tracks builders
+func (lf *Langfuse) registerBuilder(b trackedBuilder) {
+	if lf == nil || lf.registry == nil {
+		return
+	}
+
+	lf.registry.mu.Lock()
+	lf.registry.live[b] = time.Now()
+	lf.registry.mu.Unlock()
+}
+
+// deregisterBuilder removes a submitted builder from the registry
+func (lf *Langfuse) deregisterBuilder(b trackedBuilder) {
+	if lf == nil || lf.registry == nil {
+		return
+	}
+
+	lf.registry.mu.Lock()
+	delete(lf.registry.live, b)
+	if len(lf.registry.live) == 0 && lf.registry.drained != nil {
+		close(lf.registry.drained)
+		lf.registry.drained = nil
+	}
+	lf.registry.mu.Unlock()
+}
+
+// UnendedBuilders returns the builders created but not yet ended, oldest first.
+// It always returns nil unless strict mode or shutdown builder tracking is enabled.
+func (lf *Langfuse) UnendedBuilders() []LiveBuilder {
+	if lf.registry == nil {
+		return nil
+	}
+
+	lf.registry.mu.Lock()
+	builders := make([]LiveBuilder, 0, len(lf.registry.live))
+	for b, createdAt := range lf.registry.live {
+		desc := b.describe()
+		desc.CreatedAt = createdAt
+		builders = append(builders, desc)
+	}
+	lf.registry.mu.Unlock()
+
+	sort.Slice(builders, func(i, j int) bool {
+		return builders[i].CreatedAt.Before(builders[j].CreatedAt)
+	})
+
+	return builders
+}
+
+func (tb *TraceBuilder) describe() LiveBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return LiveBuilder{Kind: "trace", ID: tb.id, TraceID: tb.id, Name: tb.name}
+}
+
+func (sb *SpanBuilder) describe() LiveBuilder {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return LiveBuilder{Kind: "span", ID: sb.id, TraceID: sb.traceID, Name: sb.name}
+}
+
+func (gb *GenerationBuilder) describe() LiveBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	return LiveBuilder{Kind: "generation", ID: gb.id, TraceID: gb.traceID, Name: gb.name}
+}
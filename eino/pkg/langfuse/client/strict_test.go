@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func newStrictTestLangfuse(t *testing.T, strict bool) *Langfuse {
+	return newTestLangfuse(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.StrictMode = strict
+	})
+}
+
+func TestStrictMode_ModifyAfterEnd(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, true)
+
+		trace := lf.Trace("trace")
+		require.NoError(t, trace.End(ctx))
+		trace.WithInput("late input").AddTag("late")
+		require.Error(t, trace.Err())
+		assert.True(t, errors.Is(trace.Err(), ErrModifiedAfterEnd))
+		assert.Contains(t, trace.Err().Error(), "Input")
+		assert.Contains(t, trace.Err().Error(), "AddTag")
+		assert.Nil(t, trace.input, "modification is still ignored")
+
+		span := trace.Span("span")
+		require.NoError(t, span.End(ctx))
+		span.Output("late")
+		assert.ErrorIs(t, span.Err(), ErrModifiedAfterEnd)
+
+		generation := NewGenerationBuilder(lf, trace.GetID()).Name("generation")
+		require.NoError(t, generation.End(ctx))
+		generation.Model("gpt-4")
+		assert.ErrorIs(t, generation.Err(), ErrModifiedAfterEnd)
+	})
+
+	t.Run("non-strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, false)
+
+		trace := lf.Trace("trace")
+		require.NoError(t, trace.End(ctx))
+		trace.WithInput("late input")
+		assert.NoError(t, trace.Err())
+		assert.Nil(t, trace.input)
+
+		span := trace.Span("span")
+		require.NoError(t, span.End(ctx))
+		span.Output("late")
+		assert.NoError(t, span.Err())
+	})
+}
+
+func TestStrictMode_EndTwice(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, true)
+
+		trace := lf.Trace("trace")
+		require.NoError(t, trace.End(ctx))
+		assert.ErrorIs(t, trace.End(ctx), ErrAlreadyEnded)
+		assert.ErrorIs(t, trace.Submit(ctx), ErrAlreadyEnded)
+
+		span := trace.Span("span")
+		require.NoError(t, span.End(ctx))
+		assert.ErrorIs(t, span.End(ctx), ErrAlreadyEnded)
+		assert.NoError(t, span.Err(), "ending twice is reported by End, not recorded as a modification")
+
+		generation := lf.Generation("generation")
+		require.NoError(t, generation.End(ctx))
+		assert.ErrorIs(t, generation.End(ctx), ErrAlreadyEnded)
+	})
+
+	t.Run("non-strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, false)
+
+		trace := lf.Trace("trace")
+		require.NoError(t, trace.End(ctx))
+		err := trace.End(ctx)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrAlreadyEnded))
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+
+		span := trace.Span("span")
+		require.NoError(t, span.End(ctx))
+		err = span.End(ctx)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrAlreadyEnded))
+	})
+}
+
+func TestStrictMode_UnendedBuildersOnShutdown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, true)
+
+		trace := lf.Trace("never-ended")
+		span := trace.Span("ended-span")
+		require.NoError(t, span.End(ctx))
+
+		unended := lf.UnendedBuilders()
+		require.Len(t, unended, 1)
+		assert.Equal(t, "trace", unended[0].Kind)
+		assert.Equal(t, trace.GetID(), unended[0].ID)
+		assert.Equal(t, "never-ended", unended[0].Name)
+
+		err := lf.Shutdown(ctx)
+		var unendedErr *UnendedBuildersError
+		require.ErrorAs(t, err, &unendedErr)
+		require.Len(t, unendedErr.Builders, 1)
+		assert.Contains(t, err.Error(), "never-ended")
+	})
+
+	t.Run("non-strict", func(t *testing.T) {
+		lf := newStrictTestLangfuse(t, false)
+
+		lf.Trace("never-ended")
+		assert.Nil(t, lf.UnendedBuilders())
+		assert.NoError(t, lf.Shutdown(ctx))
+	})
+}
+
+func TestStrictMode_RegistryReleasedAfterEnd(t *testing.T) {
+	ctx := context.Background()
+	lf := newStrictTestLangfuse(t, true)
+
+	for i := 0; i < 100; i++ {
+		trace := lf.Trace("trace")
+		span := trace.Span("span")
+		generation := NewGenerationBuilder(lf, trace.GetID()).Name("generation")
+		require.NoError(t, generation.End(ctx))
+		require.NoError(t, span.End(ctx))
+		require.NoError(t, trace.End(ctx))
+	}
+
+	assert.Empty(t, lf.UnendedBuilders())
+	lf.registry.mu.Lock()
+	assert.Empty(t, lf.registry.live)
+	lf.registry.mu.Unlock()
+
+	assert.NoError(t, lf.Shutdown(ctx))
+}
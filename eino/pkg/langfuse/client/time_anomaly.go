@@ -0,0 +1,150 @@
+package client
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"eino/pkg/langfuse/config"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// TimeAnomalyMetadataKey is the metadata key listing the times fixed when a builder was
+// ended, see Config.TimeAnomalyPolicy
+const TimeAnomalyMetadataKey = "time_anomaly"
+
+// timeAnomalyPolicy returns the configured policy and clock skew, with their defaults for
+// clients created without them. Strict mode always rejects.
+func (lf *Langfuse) timeAnomalyPolicy() (TimeAnomalyPolicy, time.Duration) {
+	policy, maxSkew := TimeAnomalyClamp, config.DefaultMaxClockSkew
+	if lf == nil || lf.config == nil {
+		return policy, maxSkew
+	}
+	if lf.config.TimeAnomalyPolicy != "" {
+		policy = lf.config.TimeAnomalyPolicy
+	}
+	if lf.config.MaxClockSkew > 0 {
+		maxSkew = lf.config.MaxClockSkew
+	}
+	if lf.strictMode() {
+		policy = TimeAnomalyReject
+	}
+	return policy, maxSkew
+}
+
+// normalizeTimes fixes the start and, when set, end time of a builder being ended:
+// times with a wrong epoch magnitude are rescaled, times more than the allowed clock skew
+// ahead are clamped to now (a start to the end, so the duration is not negative), and an
+// end before the start is clamped or swapped per the policy. It returns a description of every fix, or with TimeAnomalyReject a
+// ValidationError for the first anomaly, leaving the times untouched.
+func (lf *Langfuse) normalizeTimes(startField string, start *time.Time, end *time.Time) ([]string, error) {
+	policy, maxSkew := lf.timeAnomalyPolicy()
+	now := time.Now().UTC()
+
+	fixedStart, fixedEnd := *start, time.Time{}
+	if end != nil {
+		fixedEnd = *end
+	}
+
+	var fixes []string
+	fixTime := func(field string, t *time.Time, clampTo time.Time) error {
+		fixed, fix, problem := normalizeTime(*t, now.Add(maxSkew), clampTo)
+		if problem == "" {
+			return nil
+		}
+		if policy == TimeAnomalyReject {
+			return &ValidationError{Field: field, Message: problem}
+		}
+		*t = fixed
+		fixes = append(fixes, fmt.Sprintf("%s %s, %s", field, problem, fix))
+		return nil
+	}
+	clampStartTo := now
+	if end != nil {
+		if err := fixTime("endTime", &fixedEnd, now); err != nil {
+			return nil, err
+		}
+		clampStartTo = fixedEnd
+	}
+	if err := fixTime(startField, &fixedStart, clampStartTo); err != nil {
+		return nil, err
+	}
+
+	if end != nil && fixedEnd.Before(fixedStart) {
+		switch policy {
+		case TimeAnomalyReject:
+			return nil, &ValidationError{Field: "endTime", Message: "end time cannot be before " + startField}
+		case TimeAnomalySwap:
+			fixedStart, fixedEnd = fixedEnd, fixedStart
+			fixes = append(fixes, fmt.Sprintf("endTime was before %s, swapped them", startField))
+		default:
+			fixedEnd = fixedStart
+			fixes = append(fixes, fmt.Sprintf("endTime was before %s, clamped it to %s", startField, startField))
+		}
+	}
+
+	*start = fixedStart
+	if end != nil {
+		*end = fixedEnd
+	}
+	return fixes, nil
+}
+
+// normalizeTime corrects a single time, clamping it to clampTo when it is after limit.
+// It returns the corrected time, what was done and what was wrong; the descriptions are
+// empty when the time is plausible.
+func normalizeTime(t, limit, clampTo time.Time) (time.Time, string, string) {
+	if t.IsZero() {
+		return t, "", ""
+	}
+	if utils.ValidateTimestamp(t, "") != nil {
+		if rescaled, ok := rescaleEpoch(t); ok {
+			return rescaled, fmt.Sprintf("rescaled from %s", t.Format(time.RFC3339)), "had a wrong epoch magnitude"
+		}
+	}
+	if utils.ValidateTimeRange(t, "", time.Time{}, limit) != nil {
+		return clampTo, fmt.Sprintf("clamped from %s to %s", t.Format(time.RFC3339), clampTo.Format(time.RFC3339)), "was too far in the future"
+	}
+	return t, "", ""
+}
+
+// rescaleEpoch corrects a time built from an epoch count of the wrong unit, such as
+// milliseconds read as seconds (far in the future) or seconds read as milliseconds
+// (early 1970). It returns the first rescaled time that ValidateTimestamp accepts.
+func rescaleEpoch(t time.Time) (time.Time, bool) {
+	var candidates []time.Time
+	if seconds := t.Unix(); seconds > 0 && t.Year() >= 2000 {
+		// Milliseconds, microseconds or nanoseconds read as seconds
+		candidates = []time.Time{time.UnixMilli(seconds), time.UnixMicro(seconds), time.Unix(0, seconds)}
+	} else if t.Year() == 1970 {
+		// Seconds or milliseconds read as a smaller unit
+		candidates = []time.Time{
+			time.Unix(t.UnixMilli(), 0),
+			time.Unix(t.UnixMicro(), 0),
+			time.UnixMilli(t.UnixMicro()),
+			time.Unix(t.UnixNano(), 0),
+			time.UnixMilli(t.UnixNano()),
+		}
+	}
+
+	for _, candidate := range candidates {
+		if utils.ValidateTimestamp(candidate, "") == nil {
+			return candidate.UTC(), true
+		}
+	}
+	return t, false
+}
+
+// withTimeAnomalies returns a copy of metadata recording the time fixes, leaving the
+// caller's map untouched
+func withTimeAnomalies(metadata map[string]interface{}, fixes []string) map[string]interface{} {
+	if len(fixes) == 0 {
+		return metadata
+	}
+	metadata = maps.Clone(metadata)
+	if metadata == nil {
+		metadata = make(map[string]interface{}, 1)
+	}
+	metadata[TimeAnomalyMetadataKey] = fixes
+	return metadata
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+// parseBodyTime parses a time field of a recorded event body
+func parseBodyTime(t *testing.T, body map[string]interface{}, field string) time.Time {
+	t.Helper()
+	value, ok := body[field].(string)
+	require.True(t, ok, "%s is not set", field)
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	require.NoError(t, err)
+	return parsed
+}
+
+// timeAnomalies returns the time fixes recorded in the metadata of a recorded event body
+func timeAnomalies(body map[string]interface{}) []interface{} {
+	metadata, _ := body["metadata"].(map[string]interface{})
+	anomalies, _ := metadata[TimeAnomalyMetadataKey].([]interface{})
+	return anomalies
+}
+
+func TestTimeAnomaly_EndBeforeStart(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	end := start.Add(-10 * time.Second)
+
+	t.Run("clamp", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		require.NoError(t, lf.Span("retrieve").StartTime(start).EndAt(context.Background(), end))
+
+		body := flushedBodies(t, lf, recorder)["span-update"]
+		assert.Equal(t, start, parseBodyTime(t, body, "startTime"))
+		assert.Equal(t, start, parseBodyTime(t, body, "endTime"))
+		anomalies := timeAnomalies(body)
+		require.Len(t, anomalies, 1)
+		assert.Contains(t, anomalies[0], "endTime was before startTime")
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t, func(cfg *config.Config) {
+			require.NoError(t, WithTimeAnomalyPolicy(TimeAnomalySwap, time.Minute)(cfg))
+		})
+		require.NoError(t, lf.Generation("llm").StartTime(start).EndAt(context.Background(), end))
+
+		body := flushedBodies(t, lf, recorder)["generation-update"]
+		assert.Equal(t, end, parseBodyTime(t, body, "startTime"))
+		assert.Equal(t, start, parseBodyTime(t, body, "endTime"))
+		assert.Len(t, timeAnomalies(body), 1)
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+		require.NoError(t, lf.Trace("chat").Timestamp(start).EndAt(context.Background(), end))
+
+		body := flushedBodies(t, lf, recorder)["trace-update"]
+		assert.Equal(t, start, parseBodyTime(t, body, "timestamp"))
+		anomalies := timeAnomalies(body)
+		require.Len(t, anomalies, 1)
+		assert.Contains(t, anomalies[0], "endTime was before timestamp")
+	})
+}
+
+func TestTimeAnomaly_FutureTimesAreClamped(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	before := time.Now().UTC()
+
+	userMetadata := map[string]interface{}{"tenant": "acme"}
+	year2092 := time.Date(2092, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, lf.Trace("chat").Timestamp(year2092).Metadata(userMetadata).End(context.Background()))
+	require.NoError(t, lf.Span("retrieve").EndAt(context.Background(), before.Add(time.Hour)))
+
+	bodies := flushedBodies(t, lf, recorder)
+	after := time.Now().UTC()
+
+	timestamp := parseBodyTime(t, bodies["trace-update"], "timestamp")
+	assert.WithinRange(t, timestamp, before, after)
+	require.Len(t, timeAnomalies(bodies["trace-update"]), 1)
+	assert.Contains(t, timeAnomalies(bodies["trace-update"])[0], "too far in the future")
+	assert.Equal(t, "acme", bodies["trace-update"]["metadata"].(map[string]interface{})["tenant"])
+	assert.NotContains(t, userMetadata, TimeAnomalyMetadataKey, "the caller's metadata is left untouched")
+
+	assert.WithinRange(t, parseBodyTime(t, bodies["span-update"], "endTime"), before, after)
+	assert.Len(t, timeAnomalies(bodies["span-update"]), 1)
+}
+
+func TestTimeAnomaly_WithinSkewIsKept(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	end := time.Now().UTC().Add(time.Minute).Truncate(time.Millisecond)
+	require.NoError(t, lf.Span("retrieve").EndAt(context.Background(), end))
+
+	body := flushedBodies(t, lf, recorder)["span-update"]
+	assert.Equal(t, end, parseBodyTime(t, body, "endTime"))
+	assert.Empty(t, timeAnomalies(body))
+	assert.Nil(t, body["metadata"])
+}
+
+func TestTimeAnomaly_EpochMagnitude(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	end := start.Add(30 * time.Second)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"milliseconds read as seconds", time.Unix(start.UnixMilli(), 0), end},
+		{"seconds read as milliseconds", start, time.UnixMilli(end.Unix())},
+		{"microseconds read as seconds", time.Unix(start.UnixMicro(), 0), end},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf, recorder := newPayloadTestLangfuse(t)
+			require.NoError(t, lf.Generation("llm").StartTime(tt.start).EndAt(context.Background(), tt.end))
+
+			body := flushedBodies(t, lf, recorder)["generation-update"]
+			assert.Equal(t, start, parseBodyTime(t, body, "startTime"))
+			assert.Equal(t, end, parseBodyTime(t, body, "endTime"))
+			anomalies := timeAnomalies(body)
+			require.Len(t, anomalies, 1)
+			assert.Contains(t, anomalies[0], "wrong epoch magnitude")
+		})
+	}
+}
+
+func TestTimeAnomaly_Reject(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+	}{
+		{"reject policy", func(cfg *config.Config) { cfg.TimeAnomalyPolicy = TimeAnomalyReject }},
+		{"strict mode", func(cfg *config.Config) { cfg.StrictMode = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf, recorder := newPayloadTestLangfuse(t, tt.configure)
+			ctx := context.Background()
+
+			var validationErr *ValidationError
+			span := lf.Span("retrieve").StartTime(start)
+			require.ErrorAs(t, span.EndAt(ctx, start.Add(-time.Second)), &validationErr)
+			assert.Equal(t, "endTime", validationErr.Field)
+
+			trace := lf.Trace("chat").Timestamp(time.Date(2092, 1, 1, 0, 0, 0, 0, time.UTC))
+			require.ErrorAs(t, trace.End(ctx), &validationErr)
+			assert.Equal(t, "timestamp", validationErr.Field)
+
+			generation := lf.Generation("llm").StartTime(time.Unix(start.UnixMilli(), 0))
+			require.ErrorAs(t, generation.End(ctx), &validationErr)
+			assert.Contains(t, validationErr.Message, "epoch magnitude")
+
+			assert.Empty(t, flushedBodies(t, lf, recorder), "nothing is sent")
+			assert.Equal(t, start, span.startTime, "rejected times are left untouched")
+		})
+	}
+}
+
+func TestWithTimeAnomalyPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, TimeAnomalyClamp, cfg.TimeAnomalyPolicy)
+	assert.Equal(t, 5*time.Minute, cfg.MaxClockSkew)
+
+	require.NoError(t, WithTimeAnomalyPolicy(TimeAnomalyReject, time.Minute)(cfg))
+	assert.Equal(t, TimeAnomalyReject, cfg.TimeAnomalyPolicy)
+	assert.Equal(t, time.Minute, cfg.MaxClockSkew)
+
+	assert.Error(t, WithTimeAnomalyPolicy("fix", time.Minute)(cfg))
+	assert.Error(t, WithTimeAnomalyPolicy(TimeAnomalySwap, 0)(cfg))
+}
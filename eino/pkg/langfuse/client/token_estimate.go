@@ -0,0 +1,64 @@
+package client
+
+import (
+	"eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// TokenCounter estimates how many tokens a model splits text into
+type TokenCounter = utils.TokenCounter
+
+// EstimatedInputTokensMetadataKey is the generation metadata key holding the input
+// tokens estimated by WithEstimatedTokens
+const EstimatedInputTokensMetadataKey = "estimated_input_tokens"
+
+// tokenCounter estimates the tokens of EstimateTokens and WithEstimatedTokens
+var tokenCounter TokenCounter = utils.NewTikTokenCounter()
+
+// EstimateTokens estimates the number of tokens model splits text into, for example to
+// check a prompt against the context window or estimate its cost before calling the
+// model. The estimate approximates tiktoken's counts; use the usage reported by the
+// model for the actual count.
+func (lf *Langfuse) EstimateTokens(text, model string) (int, error) {
+	return tokenCounter.Count(text, model)
+}
+
+// WithEstimatedTokens estimates the input tokens of text, the prompt about to be sent,
+// for the generation's model and records them under EstimatedInputTokensMetadataKey.
+// Until usage is reported the estimate is also sent as the input usage, so Langfuse can
+// price a generation that never completes; Usage and UsageTokens replace it. Set the
+// model first.
+//
+// Example:
+//
+//	gen := trace.Generation("answer").Model("gpt-4o").WithEstimatedTokens(prompt).Input(prompt)
+//	resp, err := llm.Complete(ctx, prompt)
+//	gen.UsageTokens(resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+func (gb *GenerationBuilder) WithEstimatedTokens(text string) *GenerationBuilder {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	if gb.submitted {
+		gb.recordMisuse("WithEstimatedTokens")
+		return gb
+	}
+
+	model := ""
+	if gb.model != nil {
+		model = *gb.model
+	}
+	tokens, err := tokenCounter.Count(text, model)
+	if err != nil {
+		return gb
+	}
+
+	if gb.metadata == nil {
+		gb.metadata = make(map[string]interface{})
+	}
+	gb.metadata[EstimatedInputTokensMetadataKey] = tokens
+	if gb.usage == nil || gb.usage == gb.estimatedUsage {
+		gb.estimatedUsage = types.NewUsage(tokens, 0)
+		gb.usage = gb.estimatedUsage
+		gb.client.recordGenerationUsage(gb)
+	}
+	return gb
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLangfuse_EstimateTokens(t *testing.T) {
+	lf, _ := newPayloadTestLangfuse(t)
+
+	tokens, err := lf.EstimateTokens("Hello, world!", "gpt-4o")
+	require.NoError(t, err)
+	assert.Equal(t, 4, tokens)
+}
+
+func TestGenerationBuilder_WithEstimatedTokens(t *testing.T) {
+	lf, recorder := newPayloadTestLangfuse(t)
+	ctx := context.Background()
+	trace := lf.Trace("chat")
+
+	estimated := trace.Generation("estimated").Model("gpt-4o").
+		WithEstimatedTokens("Hello, world!").
+		WithEstimatedTokens("Hello, world! How are you?")
+	require.NoError(t, estimated.End(ctx))
+
+	reported := trace.Generation("reported").Model("gpt-4o").WithEstimatedTokens("Hello, world!").UsageTokens(5, 12)
+	reported.WithEstimatedTokens("Hello")
+	require.NoError(t, reported.End(ctx))
+	require.NoError(t, trace.Submit(ctx))
+
+	bodies := flushedBodiesByID(t, lf, recorder)
+
+	body := bodies[estimated.GetID()]
+	require.NotNil(t, body)
+	assert.Equal(t, float64(8), body["metadata"].(map[string]interface{})[EstimatedInputTokensMetadataKey])
+	assert.Equal(t, float64(8), body["usage"].(map[string]interface{})["input"], "the latest estimate is the input usage")
+
+	body = bodies[reported.GetID()]
+	require.NotNil(t, body)
+	assert.Equal(t, float64(1), body["metadata"].(map[string]interface{})[EstimatedInputTokensMetadataKey])
+	assert.Equal(t, float64(5), body["usage"].(map[string]interface{})["input"], "reported usage replaces the estimate")
+}
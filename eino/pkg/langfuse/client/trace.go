@@ -2,10 +2,16 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"time"
 
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
 	"eino/pkg/langfuse/api/resources/ingestion/types"
 	"eino/pkg/langfuse/internal/utils"
+	"eino/pkg/langfuse/internal/utils/idconv"
 )
 
 // TraceBuilder provides a fluent API for building and configuring trace events.
@@ -14,8 +20,10 @@ import (
 // or high-level operations. The builder pattern allows for easy configuration of
 // trace properties before submission to Langfuse.
 //
-// The builder is NOT thread-safe and should not be shared across goroutines.
-// Each trace should be built and submitted from a single goroutine.
+// The builder is safe for concurrent use: setters, Submit and End may be called from
+// different goroutines, e.g. a streaming goroutine setting the output while the request
+// goroutine adds metadata. End is exactly-once; later calls fail with ErrAlreadyEnded in
+// strict mode and a ValidationError otherwise.
 //
 // Example usage:
 //
@@ -39,6 +47,7 @@ import (
 //		log.Printf("Failed to submit trace: %v", err)
 //	}
 type TraceBuilder struct {
+	mu          sync.Mutex               // Guards every field below
 	id          string                    // Unique identifier for the trace
 	name        string                    // Human-readable name describing the operation
 	userID      *string                  // Optional user identifier
@@ -50,9 +59,23 @@ type TraceBuilder struct {
 	version     *string                  // Optional version identifier
 	release     *string                  // Optional release identifier
 	public      *bool                    // Whether the trace should be publicly visible
+	bookmarked  *bool                    // Whether the trace is bookmarked in the UI
 	timestamp   time.Time                // When the trace was created
 	client      *Langfuse               // Reference to parent client
 	submitted   bool                     // Whether this trace has been submitted
+	err         error                    // Misuse recorded in strict mode
+	idErr       *ValidationError         // Invalid ID supplied via WithTraceID, reported on submit
+	children    int                      // Number of spans and generations created from this trace
+	snapshot    deltaSnapshot            // Fields sent on create, kept for delta updates
+	payloadMode PayloadMode              // Per-trace payload mode, inherited by spans and generations
+	environment string                   // Environment routing the trace to a project, inherited by spans and generations
+	anonymousUser bool                   // Whether the user ID is sent as a salted hash
+	begun       bool                     // Whether Begin has sent the create event
+	heartbeat   time.Duration            // Interval of heartbeat updates after Begin, 0 for none
+	stopHeartbeat func()                 // Stops the running heartbeat
+	childErrors int                      // Number of spans and generations that ended with level ERROR
+	childLevel  commonTypes.ObservationLevel // Highest level a span or generation of the trace ended with
+	noop        bool                     // Rate-limited: every operation succeeds without sending anything
 }
 
 // NewTraceBuilder creates a new TraceBuilder instance with default settings.
@@ -66,13 +89,17 @@ type TraceBuilder struct {
 // This function is typically called internally by Langfuse.Trace() rather
 // than directly by application code.
 func NewTraceBuilder(client *Langfuse) *TraceBuilder {
-	return &TraceBuilder{
+	tb := &TraceBuilder{
 		id:        utils.GenerateTraceID(),
 		timestamp: time.Now().UTC(),
 		client:    client,
 		metadata:  make(map[string]interface{}),
 		tags:      make([]string, 0),
 	}
+
+	client.registerBuilder(tb)
+	client.openUsageRollup(tb.id)
+	return tb
 }
 
 // ID sets a custom trace ID, overriding the auto-generated one.
@@ -89,10 +116,68 @@ func NewTraceBuilder(client *Langfuse) *TraceBuilder {
 // If the trace has already been submitted, this method has no effect and returns
 // the builder unchanged to maintain the fluent interface.
 func (tb *TraceBuilder) ID(id string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("ID")
+		return tb
+	}
+	tb.client.renameUsageRollup(tb.id, id)
+	tb.id = id
+	return tb
+}
+
+// WithTraceID sets an externally supplied trace ID, such as a request ID from your own
+// system, so that Langfuse traces can be cross-referenced with logs.
+//
+// Unlike ID, the value is normalized and validated. IDs in a recognised format (32-char
+// hex, UUID, W3C traceparent or padded base64; see package idconv) are converted to
+// 32-char lowercase hex, so "A1B2C3D4-..." and "a1b2c3d4..." map to the same trace.
+// Any other ID is used as is and must be 1-200 characters of letters, digits, '.', '_',
+// ':' or '-'. An invalid ID is reported by Submit and the generated ID is kept.
+// Spans and generations created from the trace afterwards inherit the ID, so call
+// WithTraceID before creating any children; changing the ID afterwards is reported by
+// Submit as well.
+//
+// Langfuse treats the trace ID as the primary key: reusing an ID merges the new events
+// into the existing trace instead of creating a new one. Only use IDs that are unique
+// per operation (per request, not per user or session) and consider prefixing them
+// when several systems share a project.
+//
+// Example:
+//
+//	trace := client.Trace("checkout").WithTraceID(requestID)
+//	span := trace.Span("charge-card") // span.GetTraceID() == requestID
+func (tb *TraceBuilder) WithTraceID(id string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("WithTraceID")
+		return tb
+	}
+
+	normalized, err := idconv.ToLangfuseTraceID(id)
+	switch {
+	case err == nil:
+		id = normalized
+	case !errors.Is(err, idconv.ErrUnrecognizedFormat):
+		// Looks like a hex, UUID, traceparent or base64 ID but is malformed
+		tb.idErr = &ValidationError{Field: "id", Message: err.Error()}
+		return tb
+	}
+
+	if err := validateTraceID(id); err != nil {
+		tb.idErr = err
 		return tb
 	}
+	if tb.children > 0 && id != tb.id {
+		tb.idErr = &ValidationError{Field: "id", Message: "trace id changed after spans or generations were created"}
+		return tb
+	}
+
+	tb.client.renameUsageRollup(tb.id, id)
 	tb.id = id
+	tb.idErr = nil
 	return tb
 }
 
@@ -115,7 +200,10 @@ func (tb *TraceBuilder) ID(id string) *TraceBuilder {
 //
 // If the trace has already been submitted, this method has no effect.
 func (tb *TraceBuilder) Name(name string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Name")
 		return tb
 	}
 	tb.name = name
@@ -124,7 +212,10 @@ func (tb *TraceBuilder) Name(name string) *TraceBuilder {
 
 // UserID sets the user ID
 func (tb *TraceBuilder) UserID(userID string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("UserID")
 		return tb
 	}
 	tb.userID = &userID
@@ -133,7 +224,10 @@ func (tb *TraceBuilder) UserID(userID string) *TraceBuilder {
 
 // SessionID sets the session ID
 func (tb *TraceBuilder) SessionID(sessionID string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("SessionID")
 		return tb
 	}
 	tb.sessionID = &sessionID
@@ -142,7 +236,10 @@ func (tb *TraceBuilder) SessionID(sessionID string) *TraceBuilder {
 
 // Input sets the input data
 func (tb *TraceBuilder) Input(input interface{}) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Input")
 		return tb
 	}
 	tb.input = input
@@ -151,7 +248,10 @@ func (tb *TraceBuilder) Input(input interface{}) *TraceBuilder {
 
 // Output sets the output data
 func (tb *TraceBuilder) Output(output interface{}) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Output")
 		return tb
 	}
 	tb.output = output
@@ -160,7 +260,10 @@ func (tb *TraceBuilder) Output(output interface{}) *TraceBuilder {
 
 // Metadata sets the metadata map
 func (tb *TraceBuilder) Metadata(metadata map[string]interface{}) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Metadata")
 		return tb
 	}
 	tb.metadata = metadata
@@ -169,7 +272,10 @@ func (tb *TraceBuilder) Metadata(metadata map[string]interface{}) *TraceBuilder
 
 // AddMetadata adds a single metadata key-value pair
 func (tb *TraceBuilder) AddMetadata(key string, value interface{}) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("AddMetadata")
 		return tb
 	}
 	if tb.metadata == nil {
@@ -181,7 +287,10 @@ func (tb *TraceBuilder) AddMetadata(key string, value interface{}) *TraceBuilder
 
 // Tags sets the tags
 func (tb *TraceBuilder) Tags(tags ...string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Tags")
 		return tb
 	}
 	tb.tags = tags
@@ -190,7 +299,10 @@ func (tb *TraceBuilder) Tags(tags ...string) *TraceBuilder {
 
 // AddTag adds a single tag
 func (tb *TraceBuilder) AddTag(tag string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("AddTag")
 		return tb
 	}
 	tb.tags = append(tb.tags, tag)
@@ -199,7 +311,10 @@ func (tb *TraceBuilder) AddTag(tag string) *TraceBuilder {
 
 // Version sets the version
 func (tb *TraceBuilder) Version(version string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Version")
 		return tb
 	}
 	tb.version = &version
@@ -208,7 +323,10 @@ func (tb *TraceBuilder) Version(version string) *TraceBuilder {
 
 // Release sets the release
 func (tb *TraceBuilder) Release(release string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Release")
 		return tb
 	}
 	tb.release = &release
@@ -217,34 +335,118 @@ func (tb *TraceBuilder) Release(release string) *TraceBuilder {
 
 // Public sets the public flag
 func (tb *TraceBuilder) Public(public bool) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Public")
 		return tb
 	}
 	tb.public = &public
 	return tb
 }
 
+// WithBookmarked bookmarks the trace when it is created, e.g. to flag anomalies for analysts
+func (tb *TraceBuilder) WithBookmarked(bookmarked bool) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("WithBookmarked")
+		return tb
+	}
+	tb.bookmarked = &bookmarked
+	return tb
+}
+
 // Timestamp sets the timestamp
 func (tb *TraceBuilder) Timestamp(timestamp time.Time) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		tb.recordMisuse("Timestamp")
 		return tb
 	}
 	tb.timestamp = timestamp.UTC()
 	return tb
 }
 
+// WithPayloadMode sets which payloads this trace and the spans and generations created
+// from it afterwards send. Unless the client allows overrides with
+// WithPayloadModeOverrideAllowed, the more restrictive of this mode and the configured
+// one applies, so a trace can withhold more but not send more than configured.
+func (tb *TraceBuilder) WithPayloadMode(mode PayloadMode) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("WithPayloadMode")
+		return tb
+	}
+	tb.payloadMode = mode
+	return tb
+}
+
+// WithEnvironment sets the trace's environment. When the client has credentials for the
+// environment's project (see WithProjectCredentials), the events of this trace and of the
+// spans and generations created from it afterwards are sent to that project; other
+// environments go to the default project.
+func (tb *TraceBuilder) WithEnvironment(environment string) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("WithEnvironment")
+		return tb
+	}
+	tb.environment = environment
+	return tb
+}
+
+// WithAnonymousUser, when anonymized is true, sends the trace's user ID as an HMAC-SHA256
+// hash keyed with the configured UserIDHashSalt instead of the ID itself. The hash is
+// the same for every anonymized trace of a user, so they can still be grouped, but
+// lookups by the original user ID, such as user statistics or filtering traces by user
+// in Langfuse, will not find them. Submitting fails if no salt is configured.
+func (tb *TraceBuilder) WithAnonymousUser(anonymized bool) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("WithAnonymousUser")
+		return tb
+	}
+	tb.anonymousUser = anonymized
+	return tb
+}
+
+// WithHeartbeat makes a trace started with Begin update its HeartbeatMetadataKey
+// metadata every interval until it ends, so long runs show up as alive in Langfuse.
+// Heartbeat updates only carry that metadata key.
+func (tb *TraceBuilder) WithHeartbeat(interval time.Duration) *TraceBuilder {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.submitted {
+		tb.recordMisuse("WithHeartbeat")
+		return tb
+	}
+	tb.heartbeat = interval
+	return tb
+}
+
 // GetID returns the trace ID
 func (tb *TraceBuilder) GetID() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	return tb.id
 }
 
 // GetName returns the trace name
 func (tb *TraceBuilder) GetName() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	return tb.name
 }
 
 // GetUserID returns the user ID
 func (tb *TraceBuilder) GetUserID() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.userID == nil {
 		return ""
 	}
@@ -253,6 +455,8 @@ func (tb *TraceBuilder) GetUserID() string {
 
 // GetSessionID returns the session ID
 func (tb *TraceBuilder) GetSessionID() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.sessionID == nil {
 		return ""
 	}
@@ -269,6 +473,16 @@ func (tb *TraceBuilder) WithSession(sessionID string) *TraceBuilder {
 	return tb.SessionID(sessionID)
 }
 
+// WithUserID is an alias for UserID, overriding any default set on the client
+func (tb *TraceBuilder) WithUserID(userID string) *TraceBuilder {
+	return tb.UserID(userID)
+}
+
+// WithSessionID is an alias for SessionID, overriding any default set on the client
+func (tb *TraceBuilder) WithSessionID(sessionID string) *TraceBuilder {
+	return tb.SessionID(sessionID)
+}
+
 // WithInput is an alias for Input for fluent API
 func (tb *TraceBuilder) WithInput(input interface{}) *TraceBuilder {
 	return tb.Input(input)
@@ -279,6 +493,14 @@ func (tb *TraceBuilder) WithOutput(output interface{}) *TraceBuilder {
 	return tb.Output(output)
 }
 
+// WithErrorOutput sets the output to the message of err; a nil err is ignored
+func (tb *TraceBuilder) WithErrorOutput(err error) *TraceBuilder {
+	if err == nil {
+		return tb
+	}
+	return tb.Output(errorOutput(err))
+}
+
 // WithMetadata is an alias for Metadata for fluent API
 func (tb *TraceBuilder) WithMetadata(metadata map[string]interface{}) *TraceBuilder {
 	return tb.Metadata(metadata)
@@ -291,15 +513,78 @@ func (tb *TraceBuilder) WithTags(tags ...string) *TraceBuilder {
 
 // Span creates a new span within this trace
 func (tb *TraceBuilder) Span(name string) *SpanBuilder {
+	if tb.noop {
+		return newNoopSpanBuilder(tb.id, name)
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.sampled()
+	tb.children++
+	tb.client.touchUsageRollup(tb.id)
 	span := NewSpanBuilder(tb.client, tb.id)
+	span.payloadMode = tb.payloadMode
+	span.environment = tb.environment
+	span.trace = tb
 	return span.Name(name)
 }
 
+// Generation creates a new generation within this trace
+func (tb *TraceBuilder) Generation(name string) *GenerationBuilder {
+	if tb.noop {
+		return newNoopGenerationBuilder(tb.id, name)
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.client == nil {
+		return newDisabledGenerationBuilder(name)
+	}
+	tb.sampled()
+	tb.children++
+	tb.client.touchUsageRollup(tb.id)
+	generation := NewGenerationBuilder(tb.client, tb.id)
+	generation.payloadMode = tb.payloadMode
+	generation.environment = tb.environment
+	generation.trace = tb
+	return generation.Name(name)
+}
+
+// Err returns misuse recorded in strict mode, such as modifying the trace after it was ended
+func (tb *TraceBuilder) Err() error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.err
+}
+
+// recordMisuse records a call to method after submission when strict mode is enabled
+func (tb *TraceBuilder) recordMisuse(method string) {
+	if !tb.client.strictMode() {
+		return
+	}
+	tb.err = errors.Join(tb.err, fmt.Errorf("%w: %s called on trace %s", ErrModifiedAfterEnd, method, tb.id))
+}
+
+// alreadyEnded returns the strict mode error for ending the trace more than once
+func (tb *TraceBuilder) alreadyEnded() error {
+	return fmt.Errorf("trace %s: %w", tb.id, ErrAlreadyEnded)
+}
+
 // validate performs validation on the trace builder
 func (tb *TraceBuilder) validate() error {
+	if tb.idErr != nil {
+		return tb.idErr
+	}
+
 	if tb.id == "" {
 		return &ValidationError{Field: "id", Message: "trace id is required"}
 	}
+
+	if tb.payloadMode != "" && !tb.payloadMode.IsValid() {
+		return &ValidationError{Field: "payloadMode", Message: fmt.Sprintf("unsupported payload mode %q", tb.payloadMode)}
+	}
+
+	if tb.anonymousUser && tb.userID != nil && tb.client.userIDHashSalt() == "" {
+		return &ValidationError{Field: "userId", Message: "anonymizing the user ID requires a user ID hash salt"}
+	}
 	
 	if tb.name == "" {
 		return &ValidationError{Field: "name", Message: "trace name is required"}
@@ -312,22 +597,81 @@ func (tb *TraceBuilder) validate() error {
 	return nil
 }
 
+// eventUserID returns the user ID to send, hashed for anonymous users. Without a salt
+// the ID is withheld, which validate reports.
+func (tb *TraceBuilder) eventUserID() *string {
+	if tb.userID == nil || !tb.anonymousUser {
+		return tb.userID
+	}
+	salt := tb.client.userIDHashSalt()
+	if salt == "" {
+		return nil
+	}
+	hashed := utils.HashUserID(*tb.userID, salt)
+	return &hashed
+}
+
 // toTraceEvent converts the builder to a TraceEvent
 func (tb *TraceBuilder) toTraceEvent() *types.TraceEvent {
 	return &types.TraceEvent{
-		ID:        tb.id,
-		Name:      tb.name,
-		UserID:    tb.userID,
-		SessionID: tb.sessionID,
-		Input:     tb.input,
-		Output:    tb.output,
-		Metadata:  tb.metadata,
-		Tags:      tb.tags,
-		Version:   tb.version,
-		Release:   tb.release,
-		Public:    tb.public,
-		Timestamp: tb.timestamp,
+		ID:          tb.id,
+		Name:        tb.name,
+		UserID:      tb.eventUserID(),
+		SessionID:   tb.sessionID,
+		Input:       tb.client.serializeInput(tb.input, tb.payloadMode),
+		Output:      tb.client.serializeOutput(tb.output, tb.payloadMode),
+		Metadata:    tb.client.serializeMetadata(tb.metadata),
+		Tags:        tb.client.withDefaultTags(tb.tags),
+		Version:     tb.version,
+		Release:     tb.release,
+		Public:      tb.public,
+		Bookmarked:  tb.bookmarked,
+		Timestamp:   tb.timestamp,
+		Environment: tb.environment,
+	}
+}
+
+// withDefaultTags returns the configured default tags followed by tags, without
+// duplicates. tags is returned unchanged when no default tags are configured.
+func (lf *Langfuse) withDefaultTags(tags []string) []string {
+	if lf == nil || lf.config == nil || len(lf.config.DefaultTags) == 0 {
+		return tags
+	}
+
+	merged := make([]string, 0, len(lf.config.DefaultTags)+len(tags))
+	for _, tag := range slices.Concat(lf.config.DefaultTags, tags) {
+		if !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// sampled reports whether the trace is sent under the client's sampling rules. The
+// first call decides, matching the rules on the trace as it is then; children call it
+// when they are created so their events share the decision. The caller must hold tb.mu.
+func (tb *TraceBuilder) sampled() bool {
+	if tb.client == nil || tb.client.sampler == nil {
+		return true
+	}
+	target := &samplingTarget{
+		name:        tb.name,
+		tags:        tb.client.withDefaultTags(tb.tags),
+		environment: tb.environment,
 	}
+	if tb.userID != nil {
+		target.userID = *tb.userID
+	}
+	return tb.client.keepTrace(tb.id, target)
+}
+
+// enqueue queues an event of the trace unless the trace is sampled out. The caller must
+// hold tb.mu.
+func (tb *TraceBuilder) enqueue(event types.IngestionEvent) error {
+	if !tb.sampled() {
+		return nil
+	}
+	return tb.client.enqueue(event)
 }
 
 // toTraceCreateEvent converts the builder to a TraceCreateEvent
@@ -340,7 +684,18 @@ func (tb *TraceBuilder) toTraceCreateEvent() *types.TraceCreateEvent {
 
 // Submit submits the trace to the ingestion queue
 func (tb *TraceBuilder) Submit(ctx context.Context) error {
+	if tb.noop {
+		return nil
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.begun {
+		return &ValidationError{Field: "state", Message: "trace already begun"}
+	}
 	if tb.submitted {
+		if tb.client.strictMode() {
+			return tb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "trace already submitted"}
 	}
 	
@@ -349,19 +704,79 @@ func (tb *TraceBuilder) Submit(ctx context.Context) error {
 	}
 	
 	event := tb.toTraceCreateEvent()
-	ingestionEvent := event.ToIngestionEvent()
+	ingestionEvent, err := types.NewTraceCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid trace %s: %w", tb.id, err)
+	}
+	ingestionEvent.Source = types.EventSourceTrace
+	ingestionEvent.Project = tb.environment
 	
-	if err := tb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := tb.enqueue(ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue trace %s: %w", tb.id, err)
 	}
 	
 	tb.submitted = true
+	tb.client.deregisterBuilder(tb)
+	tb.client.closeUsageRollup(tb.id)
+	return nil
+}
+
+// Begin enqueues the trace-create event right away and keeps the trace open, so a
+// long-running trace is visible in Langfuse before it ends. End, EndAt or Update then
+// send the output and final metadata as a trace-update. A heartbeat configured with
+// WithHeartbeat starts here.
+func (tb *TraceBuilder) Begin(ctx context.Context) error {
+	if tb.noop {
+		return nil
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.begun {
+		return &ValidationError{Field: "state", Message: "trace already begun"}
+	}
+	if tb.submitted {
+		if tb.client.strictMode() {
+			return tb.alreadyEnded()
+		}
+		return &ValidationError{Field: "state", Message: "trace already submitted"}
+	}
+	
+	if err := tb.validate(); err != nil {
+		return err
+	}
+	
+	event := tb.toTraceCreateEvent()
+	ingestionEvent, err := types.NewTraceCreateIngestionEvent(event)
+	if err != nil {
+		return fmt.Errorf("invalid trace %s: %w", tb.id, err)
+	}
+	ingestionEvent.Source = types.EventSourceTrace
+	ingestionEvent.Project = tb.environment
+	if err := tb.enqueue(ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue trace %s: %w", tb.id, err)
+	}
+	
+	tb.begun = true
+	if tb.client.deltaUpdates() {
+		tb.snapshot = takeDeltaSnapshot(event)
+	}
+	if tb.sampled() {
+		tb.startHeartbeat()
+	}
 	return nil
 }
 
 // Update updates an existing trace
 func (tb *TraceBuilder) Update(ctx context.Context) error {
+	if tb.noop {
+		return nil
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
+		if tb.client.strictMode() {
+			return tb.alreadyEnded()
+		}
 		return &ValidationError{Field: "state", Message: "trace already submitted"}
 	}
 	
@@ -370,51 +785,107 @@ func (tb *TraceBuilder) Update(ctx context.Context) error {
 	}
 	
 	traceEvent := tb.toTraceEvent()
+	traceEvent.Output = tb.client.serializeFinalOutput(tb.output, tb.payloadMode)
+	traceEvent.Metadata = withUsageTotals(traceEvent.Metadata, tb.client.usageTotals(tb.id))
 	updateEvent := &types.TraceUpdateEvent{
 		TraceEvent: *traceEvent,
 		Type:       "trace-update",
 	}
 	
-	ingestionEvent := updateEvent.ToIngestionEvent()
+	ingestionEvent, err := types.NewTraceUpdateIngestionEvent(updateEvent)
+	if err != nil {
+		return fmt.Errorf("invalid trace %s: %w", tb.id, err)
+	}
+	ingestionEvent.Body = tb.snapshot.diff(ingestionEvent.Body)
+	ingestionEvent.Source = types.EventSourceTrace
+	ingestionEvent.Project = tb.environment
 	
-	if err := tb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	if err := tb.enqueue(ingestionEvent); err != nil {
+		return fmt.Errorf("failed to enqueue trace %s: %w", tb.id, err)
 	}
 	
 	tb.submitted = true
+	tb.endHeartbeat()
+	tb.client.deregisterBuilder(tb)
+	tb.client.closeUsageRollup(tb.id)
 	return nil
 }
 
-// End marks the trace as ended with the current timestamp
+// End marks the trace as ended with the current timestamp.
+//
+// The usage and cost recorded so far on the trace's generations, including ones that
+// have not ended yet, are summed and reported under the UsageTotalsMetadataKey metadata key.
+// Spans and generations that failed are reported with ErrorTag; see HasErrors.
 func (tb *TraceBuilder) End(ctx context.Context) error {
 	return tb.EndAt(ctx, time.Now().UTC())
 }
 
 // EndAt marks the trace as ended with a specific timestamp
 func (tb *TraceBuilder) EndAt(ctx context.Context, endTime time.Time) error {
+	_, err := tb.endAt(endTime, false)
+	return err
+}
+
+// endAt enqueues the final trace-update. With ack set, it returns a channel receiving the
+// outcome of the event's submission, or nil when the event is not queued for submission.
+func (tb *TraceBuilder) endAt(endTime time.Time, ack bool) (<-chan error, error) {
+	if tb.noop {
+		return nil, nil
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 	if tb.submitted {
-		return &ValidationError{Field: "state", Message: "trace already submitted"}
+		if tb.client.strictMode() {
+			return nil, tb.alreadyEnded()
+		}
+		return nil, &ValidationError{Field: "state", Message: "trace already submitted"}
 	}
+
+	endTime = endTime.UTC()
+	fixes, err := tb.client.normalizeTimes("timestamp", &tb.timestamp, &endTime)
+	if err != nil {
+		return nil, err
+	}
+	tb.metadata = withTimeAnomalies(tb.metadata, fixes)
 	
 	if err := tb.validate(); err != nil {
-		return err
+		return nil, err
 	}
 	
 	traceEvent := tb.toTraceEvent()
+	traceEvent.Output = tb.client.serializeFinalOutput(tb.output, tb.payloadMode)
+	traceEvent.Metadata = withUsageTotals(traceEvent.Metadata, tb.client.usageTotals(tb.id))
 	updateEvent := &types.TraceUpdateEvent{
 		TraceEvent: *traceEvent,
 		Type:       "trace-update",
 		EndTime:    &endTime,
 	}
 	
-	ingestionEvent := updateEvent.ToIngestionEvent()
+	ingestionEvent, err := types.NewTraceUpdateIngestionEvent(updateEvent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace %s: %w", tb.id, err)
+	}
+	ingestionEvent.Body = tb.snapshot.diff(ingestionEvent.Body)
+	ingestionEvent.Source = types.EventSourceTrace
+	ingestionEvent.Project = tb.environment
 	
-	if err := tb.client.queue.Enqueue(ingestionEvent); err != nil {
-		return err
+	var submitted <-chan error
+	switch {
+	case !tb.sampled():
+	case ack:
+		submitted, err = tb.client.enqueueWithAck(ingestionEvent)
+	default:
+		err = tb.client.enqueue(ingestionEvent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue trace %s: %w", tb.id, err)
 	}
 	
 	tb.submitted = true
-	return nil
+	tb.endHeartbeat()
+	tb.client.deregisterBuilder(tb)
+	tb.client.closeUsageRollup(tb.id)
+	return submitted, nil
 }
 
 // ValidationError represents a validation error
@@ -426,4 +897,26 @@ type ValidationError struct {
 // Error implements the error interface
 func (e *ValidationError) Error() string {
 	return e.Field + ": " + e.Message
-}
\ No newline at end of file
+}
+
+// maxTraceIDLength bounds externally supplied trace IDs
+const maxTraceIDLength = 200
+
+// validateTraceID checks the format of an externally supplied trace ID
+func validateTraceID(id string) *ValidationError {
+	if id == "" {
+		return &ValidationError{Field: "id", Message: "trace id cannot be empty"}
+	}
+	if len(id) > maxTraceIDLength {
+		return &ValidationError{Field: "id", Message: fmt.Sprintf("trace id must be at most %d characters", maxTraceIDLength)}
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == ':', c == '-':
+		default:
+			return &ValidationError{Field: "id", Message: fmt.Sprintf("trace id contains invalid character %q", c)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	scoreTypes "eino/pkg/langfuse/api/resources/scores/types"
+)
+
+// exportScoresPageSize is the page size used to fetch the scores of an exported trace
+const exportScoresPageSize = 100
+
+// TraceExport is a self-contained document of one trace with all of its observations
+// and scores, as written by ExportTrace
+type TraceExport struct {
+	ExportedAt   time.Time                 `json:"exportedAt"`
+	Trace        commonTypes.Trace         `json:"trace"`
+	Observations []commonTypes.Observation `json:"observations"`
+	Scores       []commonTypes.Score       `json:"scores"`
+}
+
+// ExportTrace fetches a trace with its observations and scores and returns them as one
+// indented JSON document, for offline analysis or attaching to a bug report.
+//
+// Example:
+//
+//	data, err := langfuse.ExportTrace(ctx, "trace-123")
+//	if err != nil {
+//		return err
+//	}
+//	os.WriteFile("trace-123.json", data, 0o644)
+//
+// If the client is disabled, returns an error.
+func (lf *Langfuse) ExportTrace(ctx context.Context, traceID string) ([]byte, error) {
+	export, err := lf.fetchTraceExport(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trace export: %w", err)
+	}
+	return data, nil
+}
+
+// ExportTraceToFile writes the ExportTrace document of traceID to path
+func (lf *Langfuse) ExportTraceToFile(ctx context.Context, traceID, path string) error {
+	data, err := lf.ExportTrace(ctx, traceID)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write trace export: %w", err)
+	}
+	return nil
+}
+
+// fetchTraceExport assembles the export document of traceID, reading every page of
+// its scores
+func (lf *Langfuse) fetchTraceExport(ctx context.Context, traceID string) (*TraceExport, error) {
+	if traceID == "" {
+		return nil, fmt.Errorf("trace ID cannot be empty")
+	}
+	if lf.isDisabled() {
+		return nil, fmt.Errorf("client is disabled")
+	}
+
+	trace, err := lf.apiClient.Traces.GetWithObservations(ctx, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trace: %w", err)
+	}
+
+	scores, err := commonTypes.NewPagePaginator(func(page, limit int) ([]commonTypes.Score, int, error) {
+		resp, err := lf.apiClient.Scores.List(ctx, &scoreTypes.GetScoresRequest{
+			TraceID: &traceID,
+			Page:    &page,
+			Limit:   &limit,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Data, resp.Meta.TotalPages, nil
+	}, exportScoresPageSize).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trace scores: %w", err)
+	}
+
+	export := &TraceExport{
+		ExportedAt:   time.Now().UTC(),
+		Trace:        trace.Trace,
+		Observations: trace.Observations,
+		Scores:       scores,
+	}
+	if export.Observations == nil {
+		export.Observations = []commonTypes.Observation{}
+	}
+	if export.Scores == nil {
+		export.Scores = []commonTypes.Score{}
+	}
+	return export, nil
+}
+
+// ImportTraceFile reads a document written by ExportTrace from path and imports its
+// trace and observations with ImportTrace, keeping their original IDs. Scores are not
+// imported.
+//
+// Example:
+//
+//	err := langfuse.ImportTraceFile(ctx, "trace-123.json", client.WithSynchronousImport())
+func (lf *Langfuse) ImportTraceFile(ctx context.Context, path string, opts ...ImportOption) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read trace export: %w", err)
+	}
+
+	var export TraceExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse trace export %s: %w", path, err)
+	}
+
+	spec, err := export.importSpec()
+	if err != nil {
+		return err
+	}
+	return lf.ImportTrace(ctx, spec, opts...)
+}
+
+// importSpec converts the export back into an ImportTraceSpec
+func (e *TraceExport) importSpec() (*ImportTraceSpec, error) {
+	input, err := decodeExportedJSON(e.Trace.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode trace input: %w", err)
+	}
+	output, err := decodeExportedJSON(e.Trace.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode trace output: %w", err)
+	}
+
+	spec := &ImportTraceSpec{
+		ID:           e.Trace.ID,
+		UserID:       e.Trace.UserID,
+		SessionID:    e.Trace.SessionID,
+		Input:        input,
+		Output:       output,
+		Metadata:     e.Trace.Metadata,
+		Tags:         e.Trace.Tags,
+		Release:      e.Trace.Release,
+		Version:      e.Trace.Version,
+		Public:       e.Trace.Public,
+		StartTime:    e.Trace.Timestamp,
+		Observations: make([]ImportObservation, 0, len(e.Observations)),
+	}
+	if e.Trace.Name != nil {
+		spec.Name = *e.Trace.Name
+	}
+
+	for i := range e.Observations {
+		obs := &e.Observations[i]
+		input, err := decodeExportedJSON(obs.Input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode input of observation %s: %w", obs.ID, err)
+		}
+		output, err := decodeExportedJSON(obs.Output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode output of observation %s: %w", obs.ID, err)
+		}
+
+		imported := ImportObservation{
+			ID:                  obs.ID,
+			Type:                obs.Type,
+			StartTime:           obs.StartTime,
+			EndTime:             obs.EndTime,
+			CompletionStartTime: obs.CompletionStartTime,
+			Model:               obs.Model,
+			ModelParameters:     obs.ModelParameters,
+			Input:               input,
+			Output:              output,
+			Usage:               obs.Usage,
+			StatusMessage:       obs.StatusMessage,
+			Version:             obs.Version,
+			Metadata:            obs.Metadata,
+		}
+		if obs.Name != nil {
+			imported.Name = *obs.Name
+		}
+		if obs.ParentObservationID != nil {
+			imported.ParentID = *obs.ParentObservationID
+		}
+		if obs.Level != nil {
+			imported.Level = *obs.Level
+		}
+		spec.Observations = append(spec.Observations, imported)
+	}
+	return spec, nil
+}
+
+// decodeExportedJSON decodes an exported input or output, returning nil when absent
+func decodeExportedJSON(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestMux serves trace-1 with a span and a generation below it, and its three
+// scores paged by the requested limit
+func newExportTestMux(t *testing.T) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/traces/trace-1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("includeObservations"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":        "trace-1",
+			"name":      "checkout",
+			"timestamp": "2024-05-01T10:00:00Z",
+			"input":     map[string]interface{}{"question": "where is my order?"},
+			"tags":      []string{"support"},
+			"observations": []map[string]interface{}{
+				{"id": "span-1", "traceId": "trace-1", "type": "SPAN", "name": "retrieve",
+					"startTime": "2024-05-01T10:00:01Z", "endTime": "2024-05-01T10:00:02Z"},
+				{"id": "gen-1", "traceId": "trace-1", "type": "GENERATION", "name": "answer",
+					"parentObservationId": "span-1", "model": "gpt-4o", "level": "WARNING",
+					"startTime": "2024-05-01T10:00:01.5Z", "output": "it ships today"},
+			},
+		})
+	})
+	mux.HandleFunc("/api/public/scores", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "trace-1", r.URL.Query().Get("traceId"))
+		scores := []map[string]interface{}{
+			{"id": "score-1", "traceId": "trace-1", "name": "helpful", "value": 1, "dataType": "BOOLEAN"},
+			{"id": "score-2", "traceId": "trace-1", "name": "accuracy", "value": 0.8, "dataType": "NUMERIC"},
+			{"id": "score-3", "traceId": "trace-1", "observationId": "gen-1", "name": "tone", "value": "polite", "dataType": "CATEGORICAL"},
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start, end := min((page-1)*limit, len(scores)), min(page*limit, len(scores))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": scores[start:end],
+			"meta": map[string]interface{}{"page": page, "limit": limit, "totalItems": len(scores), "totalPages": (len(scores) + limit - 1) / limit},
+		})
+	})
+	return mux
+}
+
+func TestLangfuse_ExportTrace(t *testing.T) {
+	lf := newTestLangfuse(t, newExportTestMux(t))
+
+	data, err := lf.ExportTrace(context.Background(), "trace-1")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"trace\": {", "the document is indented")
+
+	var export TraceExport
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.False(t, export.ExportedAt.IsZero())
+	assert.Equal(t, "trace-1", export.Trace.ID)
+	assert.Equal(t, "checkout", *export.Trace.Name)
+	assert.JSONEq(t, `{"question":"where is my order?"}`, string(export.Trace.Input))
+
+	require.Len(t, export.Observations, 2)
+	assert.Equal(t, "span-1", export.Observations[0].ID)
+	assert.Equal(t, "span-1", *export.Observations[1].ParentObservationID)
+	assert.Equal(t, "gpt-4o", *export.Observations[1].Model)
+
+	var scoreIDs []string
+	for _, score := range export.Scores {
+		scoreIDs = append(scoreIDs, score.ID)
+	}
+	assert.Equal(t, []string{"score-1", "score-2", "score-3"}, scoreIDs)
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := lf.ExportTrace(context.Background(), "")
+		assert.EqualError(t, err, "trace ID cannot be empty")
+
+		_, err = lf.ExportTrace(context.Background(), "missing")
+		assert.ErrorContains(t, err, "failed to fetch trace")
+
+		disabled, err := NewWithOptions(WithCredentials("pk-lf-test", "sk-lf-test"), WithEnabled(false))
+		require.NoError(t, err)
+		_, err = disabled.ExportTrace(context.Background(), "trace-1")
+		assert.EqualError(t, err, "client is disabled")
+	})
+}
+
+func TestLangfuse_ImportTraceFile(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := newExportTestMux(t)
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "trace-1.json")
+	require.NoError(t, lf.ExportTraceToFile(ctx, "trace-1", path))
+	require.NoError(t, lf.ImportTraceFile(ctx, path, WithSynchronousImport()))
+
+	recorder.mu.Lock()
+	events := recorder.events
+	recorder.mu.Unlock()
+	require.Len(t, events, 3, "the trace and its observations; scores are not imported")
+
+	trace := events[0]["body"].(map[string]interface{})
+	assert.Equal(t, "trace-create", events[0]["type"])
+	assert.Equal(t, "trace-1", trace["id"])
+	assert.Equal(t, "checkout", trace["name"])
+	assert.Equal(t, map[string]interface{}{"question": "where is my order?"}, trace["input"])
+
+	generation := events[2]["body"].(map[string]interface{})
+	assert.Equal(t, "generation-create", events[2]["type"])
+	assert.Equal(t, "gen-1", generation["id"])
+	assert.Equal(t, "span-1", generation["parentObservationId"])
+	assert.Equal(t, "WARNING", generation["level"])
+	assert.Equal(t, "it ships today", generation["output"])
+
+	assert.ErrorContains(t, lf.ImportTraceFile(ctx, filepath.Join(t.TempDir(), "missing.json")), "failed to read trace export")
+}
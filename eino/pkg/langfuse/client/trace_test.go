@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -358,4 +360,124 @@ func createTestClient(t *testing.T) *Langfuse {
 	}
 	
 	return client
-}
\ No newline at end of file
+}
+func TestTraceBuilder_WithTraceID(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	const requestID = "req-2024-01-01:abc_123"
+	trace := lf.Trace("checkout").WithTraceID(requestID)
+	assert.Equal(t, requestID, trace.GetID())
+
+	span := trace.Span("charge-card")
+	child := span.ChildSpan("call-provider")
+	generation := trace.Generation("summarize")
+	nested := span.ChildGeneration("classify")
+
+	require.NoError(t, trace.Submit(ctx))
+	require.NoError(t, span.End(ctx))
+	require.NoError(t, child.End(ctx))
+	require.NoError(t, generation.End(ctx))
+	require.NoError(t, nested.End(ctx))
+	require.NoError(t, lf.Flush(ctx))
+
+	recorder.mu.Lock()
+	events := recorder.events
+	recorder.mu.Unlock()
+	require.Len(t, events, 5)
+
+	for _, event := range events {
+		body := event["body"].(map[string]interface{})
+		if event["type"] == "trace-create" {
+			assert.Equal(t, requestID, body["id"])
+			continue
+		}
+		assert.Equal(t, requestID, body["traceId"], "event %s", event["type"])
+	}
+}
+
+func TestTraceBuilder_WithTraceID_Invalid(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "empty", id: ""},
+		{name: "whitespace", id: "req 123"},
+		{name: "slash", id: "req/123"},
+		{name: "too long", id: strings.Repeat("a", maxTraceIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := lf.Trace("invalid")
+			generatedID := trace.GetID()
+
+			trace.WithTraceID(tt.id)
+			assert.Equal(t, generatedID, trace.GetID())
+
+			err := trace.Submit(ctx)
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "id", validationErr.Field)
+		})
+	}
+
+	// Changing the ID after children exist would orphan them
+	trace := lf.Trace("late-id")
+	trace.Span("early-child")
+	trace.WithTraceID("req-late")
+	assert.NotEqual(t, "req-late", trace.GetID())
+	assert.Error(t, trace.Submit(ctx))
+}
+
+func TestTraceBuilder_WithTraceID_Normalization(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	const expected = "a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	for _, id := range []string{
+		"A1B2C3D4-E5F6-0718-293A-4B5C6D7E8F90",
+		"a1b2c3d4e5f60718293a4b5c6d7e8f90",
+		"00-a1b2c3d4e5f60718293a4b5c6d7e8f90-00f067aa0ba902b7-01",
+	} {
+		trace := lf.Trace("normalized").WithTraceID(id)
+		assert.Equal(t, expected, trace.GetID(), "input %q", id)
+		assert.Equal(t, expected, trace.Span("child").GetTraceID())
+	}
+
+	// Inputs that look like hex IDs but cannot be normalized are rejected
+	trace := lf.Trace("all-zero").WithTraceID("00000000-0000-0000-0000-000000000000")
+	assert.Error(t, trace.Submit(context.Background()))
+}
+
+func TestTraceBuilder_WithBookmarked(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	bookmarked := lf.Trace("anomaly").WithBookmarked(true)
+	require.NoError(t, bookmarked.Submit(ctx))
+	plain := lf.Trace("routine")
+	require.NoError(t, plain.Submit(ctx))
+	require.NoError(t, lf.Flush(ctx))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range recorder.events {
+		assert.Equal(t, "trace-create", event["type"])
+		body := event["body"].(map[string]interface{})
+		bodies[body["id"].(string)] = body
+	}
+	require.Len(t, bodies, 2)
+	assert.Equal(t, true, bodies[bookmarked.GetID()]["bookmarked"])
+	assert.NotContains(t, bodies[plain.GetID()], "bookmarked")
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// EndAndURL ends the trace, waits until the ingestion API has accepted it and returns its
+// URL in the Langfuse UI, e.g. to log a link for operators after an agent run.
+//
+// The URL has the form {host}/project/{projectID}/traces/{traceID}, so the project ID
+// must be configured with WithProjectID or LANGFUSE_PROJECT_ID; without it the trace is
+// left open and an error is returned. The queue is flushed right away rather than at the
+// next interval. If the trace's batch fails or ctx is done first, the error is returned
+// instead of the URL.
+//
+// The trace of a Transaction is only buffered, so its URL is returned without waiting
+// and resolves once the transaction is committed. A trace dropped by WithRateLimit is
+// never sent and returns an empty URL without error.
+func (tb *TraceBuilder) EndAndURL(ctx context.Context) (string, error) {
+	if tb.noop {
+		// A rate-limited trace is never sent, so it has no URL
+		return "", nil
+	}
+	lf := tb.client
+	if lf == nil {
+		// Builders of a disabled client are never sent
+		return "", &ValidationError{Field: "state", Message: "client is disabled"}
+	}
+
+	projectURL, err := lf.projectURL()
+	if err != nil {
+		return "", err
+	}
+
+	submitted, err := tb.endAt(time.Now().UTC(), true)
+	if err != nil {
+		return "", err
+	}
+
+	traceID := tb.GetID()
+	if submitted != nil {
+		if err := lf.Flush(ctx); err != nil {
+			return "", err
+		}
+		select {
+		case err := <-submitted:
+			if err != nil {
+				return "", fmt.Errorf("failed to submit trace %s: %w", traceID, err)
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	return projectURL + "/traces/" + url.PathEscape(traceID), nil
+}
+
+// projectURL returns the URL of the configured project in the Langfuse UI
+func (lf *Langfuse) projectURL() (string, error) {
+	lf.mu.RLock()
+	host, projectID := lf.config.Host, lf.config.ProjectID
+	lf.mu.RUnlock()
+
+	if projectID == "" {
+		return "", utils.NewConfigurationError("projectId", "project ID is required to build trace URLs")
+	}
+	return strings.TrimSuffix(host, "/") + "/project/" + url.PathEscape(projectID), nil
+}
+
+// enqueueWithAck is enqueue returning a channel that receives the outcome of the event's
+// submission, or nil when the event is buffered in a transaction
+func (lf *Langfuse) enqueueWithAck(event types.IngestionEvent) (<-chan error, error) {
+	if lf.tx != nil {
+		return nil, lf.tx.Add(event)
+	}
+	return lf.queue.EnqueueWithAck(event)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/config"
+)
+
+func TestTraceBuilder_EndAndURL(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A slow API shows EndAndURL waits for the batch rather than the flush interval
+		time.Sleep(200 * time.Millisecond)
+		recorder.ServeHTTP(w, r)
+	}))
+	var host string
+	lf := newTestLangfuse(t, mux, func(cfg *config.Config) {
+		cfg.FlushInterval = time.Hour
+		host = cfg.Host
+		require.NoError(t, WithProjectID("proj-1")(cfg))
+	})
+
+	traceURL, err := lf.Trace("agent-run").ID("trace-1").EndAndURL(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, host+"/project/proj-1/traces/trace-1", traceURL)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.events, 1, "the trace is submitted before the URL is returned")
+	assert.Equal(t, "trace-update", recorder.events[0]["type"])
+	assert.Equal(t, "trace-1", recorder.events[0]["body"].(map[string]interface{})["id"])
+}
+
+func TestTraceBuilder_EndAndURL_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("without project ID", func(t *testing.T) {
+		lf, recorder := newPayloadTestLangfuse(t)
+
+		trace := lf.Trace("agent-run")
+		_, err := trace.EndAndURL(ctx)
+		assert.ErrorContains(t, err, "project ID is required")
+
+		// The trace is still open
+		require.NoError(t, trace.End(ctx))
+		assert.Contains(t, flushedBodies(t, lf, recorder), "trace-update")
+	})
+
+	t.Run("failed submission", func(t *testing.T) {
+		lf := newTestLangfuse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}), func(cfg *config.Config) {
+			cfg.ProjectID = "proj-1"
+		})
+
+		traceURL, err := lf.Trace("agent-run").EndAndURL(ctx)
+		assert.Error(t, err)
+		assert.Empty(t, traceURL)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		lf := newTestLangfuse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}), func(cfg *config.Config) {
+			cfg.ProjectID = "proj-1"
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		defer cancel()
+		_, err := lf.Trace("agent-run").EndAndURL(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestWithProjectID(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, WithProjectID("proj-1")(cfg))
+	assert.Equal(t, "proj-1", cfg.ProjectID)
+	assert.Error(t, WithProjectID("")(cfg))
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// ErrTransactionClosed is returned when events are added to, or a transaction is
+// committed after, a Commit or Rollback
+var ErrTransactionClosed = errors.New("transaction already committed or rolled back")
+
+// Transaction groups the events of several traces so they are submitted together.
+//
+// Traces created with Transaction.Trace, and the spans and generations created from
+// them, add their events to the transaction instead of the ingestion queue. Commit sends
+// all of them in a single batch; Rollback discards them. Nothing is sent before Commit,
+// so a workflow either appears in Langfuse with all its traces or not at all.
+//
+// The Langfuse ingestion API processes a batch event by event, so Commit reports events
+// the API rejects but cannot undo the ones it accepted.
+//
+// Example:
+//
+//	tx, err := client.StartTransaction(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer tx.Rollback()
+//
+//	workflow := tx.Trace("workflow")
+//	task := tx.Trace("sub-task").WithMetadata(map[string]interface{}{"parent": workflow.GetID()})
+//	// ... end the traces
+//
+//	return tx.Commit(ctx)
+type Transaction struct {
+	// client is a derived client whose builders add their events to the transaction
+	client *Langfuse
+
+	mu     sync.Mutex
+	events []types.IngestionEvent
+	closed bool
+}
+
+// StartTransaction starts a transaction for submitting several traces as one batch.
+// It fails when ctx is done or the client has been shut down.
+func (lf *Langfuse) StartTransaction(ctx context.Context) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	root := lf.root()
+	root.mu.RLock()
+	closed := root.closed
+	root.mu.RUnlock()
+	if closed {
+		return nil, ErrQueueClosed
+	}
+
+	tx := &Transaction{}
+	tx.client = lf.derive()
+	tx.client.tx = tx
+	return tx, nil
+}
+
+// Trace creates a trace whose events, and those of its spans and generations, are
+// buffered in the transaction
+func (tx *Transaction) Trace(name string) *TraceBuilder {
+	return tx.client.Trace(name)
+}
+
+// Add buffers an ingestion event. The client's event middleware is applied and the event
+// is validated right away, so an invalid event fails the builder call that produced it
+// rather than Commit.
+func (tx *Transaction) Add(event types.IngestionEvent) error {
+	event = tx.client.queue.ApplyMiddleware(event)
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("event validation failed: %w", err)
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	tx.events = append(tx.events, event)
+	return nil
+}
+
+// enqueue adds a builder event to the client's transaction, if it has one, or to the
+// ingestion queue
+func (lf *Langfuse) enqueue(event types.IngestionEvent) error {
+	if lf.tx != nil {
+		return lf.tx.Add(event)
+	}
+	return lf.queue.Enqueue(event)
+}
+
+// Len returns the number of buffered events
+func (tx *Transaction) Len() int {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return len(tx.events)
+}
+
+// Commit submits all buffered events in a single batch and closes the transaction. It
+// returns an error when the batch could not be sent or the API rejected any of its
+// events. A transaction without events commits without a request.
+func (tx *Transaction) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	if tx.closed {
+		tx.mu.Unlock()
+		return ErrTransactionClosed
+	}
+	tx.closed = true
+	events := tx.events
+	tx.events = nil
+	tx.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	response, err := tx.client.transport.SubmitBatch(ctx, events)
+	if err == nil && response != nil && response.HasErrors() {
+		err = fmt.Errorf("%d of %d events were rejected", response.ErrorCount(), len(events))
+	}
+
+	root := tx.client.root()
+	root.stats.recordSubmission(len(events), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Rollback discards the buffered events and closes the transaction. It is a no-op after
+// Commit, so it can be deferred right after StartTransaction.
+func (tx *Transaction) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.closed = true
+	tx.events = nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder records the event types of every ingestion batch separately
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (r *batchRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Batch []map[string]interface{} `json:"batch"`
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	var eventTypes []string
+	for _, event := range body.Batch {
+		eventTypes = append(eventTypes, event["type"].(string))
+	}
+
+	r.mu.Lock()
+	r.batches = append(r.batches, eventTypes)
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"success":true,"timestamp":"2024-01-01T12:00:00Z"}`))
+}
+
+func (r *batchRecorder) snapshot() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.batches...)
+}
+
+func newTransactionTestLangfuse(t *testing.T) (*Langfuse, *batchRecorder) {
+	recorder := &batchRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	return newTestLangfuse(t, mux), recorder
+}
+
+func TestTransaction_Commit(t *testing.T) {
+	lf, recorder := newTransactionTestLangfuse(t)
+	ctx := context.Background()
+
+	tx, err := lf.StartTransaction(ctx)
+	require.NoError(t, err)
+
+	workflow := tx.Trace("workflow")
+	require.NoError(t, workflow.Span("plan").Submit(ctx))
+	require.NoError(t, workflow.Submit(ctx))
+	require.NoError(t, tx.Trace("sub-task").Submit(ctx))
+	assert.Equal(t, 3, tx.Len())
+
+	// Nothing reaches the API before Commit
+	require.NoError(t, lf.Flush(ctx))
+	assert.Empty(t, recorder.snapshot())
+
+	require.NoError(t, tx.Commit(ctx))
+	batches := recorder.snapshot()
+	require.Len(t, batches, 1, "all events are sent in a single batch")
+	assert.ElementsMatch(t, []string{"span-create", "trace-create", "trace-create"}, batches[0])
+	assert.Equal(t, int64(3), lf.GetStats().EventsSubmitted)
+
+	assert.ErrorIs(t, tx.Commit(ctx), ErrTransactionClosed)
+	assert.ErrorIs(t, tx.Trace("late").Submit(ctx), ErrTransactionClosed)
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	lf, recorder := newTransactionTestLangfuse(t)
+	ctx := context.Background()
+
+	tx, err := lf.StartTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.Trace("workflow").Submit(ctx))
+	require.NoError(t, lf.Trace("outside").Submit(ctx), "traces of the client itself are not part of the transaction")
+
+	tx.Rollback()
+	assert.Zero(t, tx.Len())
+	assert.ErrorIs(t, tx.Commit(ctx), ErrTransactionClosed)
+
+	require.NoError(t, lf.Flush(ctx))
+	assert.Equal(t, [][]string{{"trace-create"}}, recorder.snapshot())
+}
+
+func TestStartTransaction_Errors(t *testing.T) {
+	lf, _ := newTransactionTestLangfuse(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := lf.StartTransaction(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, lf.Shutdown(context.Background()))
+	_, err = lf.StartTransaction(context.Background())
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// HealthChecker can optionally be implemented by a custom IngestionTransport so that
+// HealthCheck and WaitForHealthy report on the transport (e.g. broker connectivity)
+// instead of being skipped.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// waitForTransportHealthy polls the custom transport's health check until it succeeds,
+// mirroring the REST health client. Transports without a health check are healthy.
+func (lf *Langfuse) waitForTransportHealthy(ctx context.Context, checkInterval time.Duration) error {
+	checker, ok := lf.config.IngestionTransport.(HealthChecker)
+	if !ok {
+		return nil
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if checker.HealthCheck(ctx) == nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/config"
+)
+
+// memoryTransport collects submitted events in memory
+type memoryTransport struct {
+	mu     sync.Mutex
+	events []ingestiontypes.IngestionEvent
+}
+
+func (m *memoryTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return &ingestiontypes.IngestionResponse{Success: true, Timestamp: time.Now()}, nil
+}
+
+func (m *memoryTransport) eventTypes() []ingestiontypes.EventType {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	types := make([]ingestiontypes.EventType, 0, len(m.events))
+	for _, event := range m.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// checkedTransport is a memoryTransport that also implements HealthChecker
+type checkedTransport struct {
+	memoryTransport
+	err error
+}
+
+func (c *checkedTransport) HealthCheck(ctx context.Context) error {
+	return c.err
+}
+
+// newTransportTestLangfuse creates a client using transport, failing the test if the REST
+// ingestion endpoint is ever called
+func newTransportTestLangfuse(t *testing.T, transport IngestionTransport, configure ...func(*config.Config)) *Langfuse {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/public/ingestion", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("REST ingestion endpoint called despite custom transport")
+	})
+
+	return newTestLangfuse(t, mux, append([]func(*config.Config){func(cfg *config.Config) {
+		require.NoError(t, config.WithIngestionTransport(transport)(cfg))
+	}}, configure...)...)
+}
+
+func TestLangfuse_IngestionTransport(t *testing.T) {
+	transport := &memoryTransport{}
+	lf := newTransportTestLangfuse(t, transport)
+	ctx := context.Background()
+
+	assert.Nil(t, lf.API().Ingestion, "the REST ingestion client should not be constructed")
+
+	trace := lf.Trace("kafka")
+	require.NoError(t, trace.Submit(ctx))
+	require.NoError(t, lf.Shutdown(ctx))
+
+	assert.Equal(t, []ingestiontypes.EventType{ingestiontypes.EventTypeTraceCreate}, transport.eventTypes())
+}
+
+func TestLangfuse_IngestionTransport_SynchronousImport(t *testing.T) {
+	transport := &memoryTransport{}
+	lf := newTransportTestLangfuse(t, transport)
+
+	spec := importFixture(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, lf.ImportTrace(context.Background(), spec, WithSynchronousImport()))
+
+	assert.Len(t, transport.eventTypes(), 1+len(spec.Observations))
+}
+
+func TestLangfuse_IngestionTransport_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	// Without a HealthChecker the check is skipped
+	lf := newTransportTestLangfuse(t, &memoryTransport{})
+	assert.NoError(t, lf.HealthCheck(ctx))
+	assert.NoError(t, lf.WaitForHealthy(ctx, time.Millisecond))
+
+	errBroker := errors.New("broker unreachable")
+	checked := &checkedTransport{err: errBroker}
+	lf = newTransportTestLangfuse(t, checked)
+	assert.ErrorIs(t, lf.HealthCheck(ctx), errBroker)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, lf.WaitForHealthy(timeoutCtx, time.Millisecond), context.DeadlineExceeded)
+
+	checked.err = nil
+	assert.NoError(t, lf.HealthCheck(ctx))
+}
+
+func TestWithIngestionTransport_Nil(t *testing.T) {
+	assert.Error(t, config.WithIngestionTransport(nil)(config.DefaultConfig()))
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// UsageTotalsMetadataKey is the trace metadata key under which the summed usage and
+// cost of the trace's generations is reported when the trace ends
+const UsageTotalsMetadataKey = "usageTotals"
+
+// UsageTotals is the usage and cost of all generations of a trace, summed when the
+// trace ends. Cost fields are omitted when no generation reported a cost.
+type UsageTotals struct {
+	Generations int      `json:"generations"`
+	Input       int      `json:"input"`
+	Output      int      `json:"output"`
+	Total       int      `json:"total"`
+	InputCost   *float64 `json:"inputCost,omitempty"`
+	OutputCost  *float64 `json:"outputCost,omitempty"`
+	TotalCost   *float64 `json:"totalCost,omitempty"`
+}
+
+// usageRollupMaxAge bounds how long the usage of a trace that is never ended is kept
+// after its last activity: a generation reporting usage, a span or generation created
+// under it, an update or a heartbeat. Stale entries are dropped at most once per
+// usageRollupSweepInterval, when a trace is opened.
+const (
+	usageRollupMaxAge        = time.Hour
+	usageRollupSweepInterval = time.Minute
+)
+
+// usageRollup tracks the latest usage of every generation per open trace builder.
+// Entries are created with the trace builder, so generations of traces built elsewhere
+// are not tracked, and removed once the trace is submitted for the last time, on
+// Shutdown, or usageRollupMaxAge after its last activity when the trace is abandoned.
+type usageRollup struct {
+	mu        sync.Mutex
+	traces    map[string]*traceUsage
+	lastSweep time.Time
+}
+
+// traceUsage is the latest usage of a trace's generations, keyed by generation ID
+type traceUsage struct {
+	lastActive  time.Time
+	generations map[string]types.Usage
+}
+
+func newUsageRollup() *usageRollup {
+	return &usageRollup{traces: make(map[string]*traceUsage), lastSweep: time.Now()}
+}
+
+// openUsageRollup starts tracking generations created under traceID
+func (lf *Langfuse) openUsageRollup(traceID string) {
+	if lf == nil || lf.usage == nil {
+		return
+	}
+
+	now := time.Now()
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	lf.usage.sweep(now)
+	if _, ok := lf.usage.traces[traceID]; !ok {
+		lf.usage.traces[traceID] = &traceUsage{lastActive: now, generations: make(map[string]types.Usage)}
+	}
+}
+
+// sweep drops the traces without activity for more than usageRollupMaxAge, which were
+// abandoned without being ended; the caller holds r.mu
+func (r *usageRollup) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < usageRollupSweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for traceID, usage := range r.traces {
+		if now.Sub(usage.lastActive) > usageRollupMaxAge {
+			delete(r.traces, traceID)
+		}
+	}
+}
+
+// touchUsageRollup records activity on traceID, so its usage is kept while it runs
+func (lf *Langfuse) touchUsageRollup(traceID string) {
+	if lf == nil || lf.usage == nil {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	if usage, ok := lf.usage.traces[traceID]; ok {
+		usage.lastActive = time.Now()
+	}
+}
+
+// renameUsageRollup moves tracking to a trace's new ID
+func (lf *Langfuse) renameUsageRollup(oldID, newID string) {
+	if lf == nil || lf.usage == nil || oldID == newID {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	if usage, ok := lf.usage.traces[oldID]; ok {
+		delete(lf.usage.traces, oldID)
+		lf.usage.traces[newID] = usage
+	}
+}
+
+// renameGenerationUsage moves the usage recorded for a generation to its new ID
+func (lf *Langfuse) renameGenerationUsage(traceID, oldID, newID string) {
+	if lf == nil || lf.usage == nil || oldID == newID {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	usage, ok := lf.usage.traces[traceID]
+	if !ok {
+		return
+	}
+	if recorded, ok := usage.generations[oldID]; ok {
+		delete(usage.generations, oldID)
+		usage.generations[newID] = recorded
+	}
+}
+
+// closeUsageRollup stops tracking traceID
+func (lf *Langfuse) closeUsageRollup(traceID string) {
+	if lf == nil || lf.usage == nil {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	delete(lf.usage.traces, traceID)
+	lf.usage.mu.Unlock()
+}
+
+// clearUsageRollup stops tracking every trace, at shutdown
+func (lf *Langfuse) clearUsageRollup() {
+	if lf == nil || lf.usage == nil {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	lf.usage.traces = make(map[string]*traceUsage)
+	lf.usage.mu.Unlock()
+}
+
+// recordGenerationUsage stores a copy of the generation's current usage if its trace
+// is tracked. Values recorded later replace earlier ones, so the totals reflect what
+// was known when the trace ended even if the generation itself ends afterwards.
+func (lf *Langfuse) recordGenerationUsage(gb *GenerationBuilder) {
+	if lf == nil || lf.usage == nil {
+		return
+	}
+
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	usage, ok := lf.usage.traces[gb.traceID]
+	if !ok {
+		return
+	}
+	usage.lastActive = time.Now()
+	if gb.usage == nil {
+		delete(usage.generations, gb.id)
+		return
+	}
+	usage.generations[gb.id] = *gb.usage
+}
+
+// usageTotals sums the recorded usage of traceID's generations, returning nil when
+// none reported usage
+func (lf *Langfuse) usageTotals(traceID string) *UsageTotals {
+	if lf == nil || lf.usage == nil {
+		return nil
+	}
+
+	lf.usage.mu.Lock()
+	defer lf.usage.mu.Unlock()
+	traceUsage := lf.usage.traces[traceID]
+	if traceUsage == nil || len(traceUsage.generations) == 0 {
+		return nil
+	}
+
+	totals := &UsageTotals{Generations: len(traceUsage.generations)}
+	for _, usage := range traceUsage.generations {
+		input, output := intValue(usage.Input), intValue(usage.Output)
+		totals.Input += input
+		totals.Output += output
+		if usage.Total != nil {
+			totals.Total += *usage.Total
+		} else {
+			totals.Total += input + output
+		}
+
+		addCost(&totals.InputCost, usage.InputCost)
+		addCost(&totals.OutputCost, usage.OutputCost)
+		switch {
+		case usage.TotalCost != nil:
+			addCost(&totals.TotalCost, usage.TotalCost)
+		case usage.InputCost != nil || usage.OutputCost != nil:
+			cost := floatValue(usage.InputCost) + floatValue(usage.OutputCost)
+			addCost(&totals.TotalCost, &cost)
+		}
+	}
+	return totals
+}
+
+// withUsageTotals returns metadata with the trace's usage totals added, leaving the
+// original map untouched
+func withUsageTotals(metadata map[string]interface{}, totals *UsageTotals) map[string]interface{} {
+	if totals == nil {
+		return metadata
+	}
+
+	stamped := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		stamped[k] = v
+	}
+	stamped[UsageTotalsMetadataKey] = totals
+	return stamped
+}
+
+func addCost(sum **float64, cost *float64) {
+	if cost == nil {
+		return
+	}
+	if *sum == nil {
+		*sum = new(float64)
+	}
+	**sum += *cost
+}
+
+func intValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func floatValue(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// traceUpdateTotals returns the usage totals stamped on the trace-update event of traceID
+func traceUpdateTotals(t *testing.T, recorder *ingestionRecorder, traceID string) map[string]interface{} {
+	t.Helper()
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	for _, event := range recorder.events {
+		body := event["body"].(map[string]interface{})
+		if event["type"] != "trace-update" || body["id"] != traceID {
+			continue
+		}
+		metadata, _ := body["metadata"].(map[string]interface{})
+		totals, _ := metadata[UsageTotalsMetadataKey].(map[string]interface{})
+		return totals
+	}
+	t.Fatalf("no trace-update event for %s", traceID)
+	return nil
+}
+
+func TestTraceBuilder_End_RollsUpGenerationUsage(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	trace := lf.Trace("agent-run")
+	require.NoError(t, trace.Generation("plan").UsageWithCost(100, 20, 0.01, 0.002).End(ctx))
+	require.NoError(t, trace.Span("tools").ChildGeneration("summarize").UsageWithCost(300, 50, 0.03, 0.005).End(ctx))
+	require.NoError(t, trace.Generation("classify").UsageTokens(10, 1).End(ctx))
+
+	// Still running when the trace ends: the usage known at that point is included
+	streaming := trace.Generation("stream").UsageTokens(5, 0)
+
+	require.NoError(t, trace.End(ctx))
+	streaming.UsageTokens(5, 500)
+	require.NoError(t, streaming.End(ctx))
+	require.NoError(t, lf.Shutdown(ctx))
+
+	totals := traceUpdateTotals(t, recorder, trace.GetID())
+	require.NotNil(t, totals)
+	assert.EqualValues(t, 4, totals["generations"])
+	assert.EqualValues(t, 415, totals["input"])
+	assert.EqualValues(t, 71, totals["output"])
+	assert.EqualValues(t, 486, totals["total"])
+	assert.InDelta(t, 0.04, totals["inputCost"], 1e-9)
+	assert.InDelta(t, 0.007, totals["outputCost"], 1e-9)
+	assert.InDelta(t, 0.047, totals["totalCost"], 1e-9)
+}
+
+func TestTraceBuilder_End_WithoutGenerationUsage(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	trace := lf.Trace("no-llm")
+	require.NoError(t, trace.Generation("no-usage").End(ctx))
+	require.NoError(t, trace.End(ctx))
+	require.NoError(t, lf.Shutdown(ctx))
+
+	assert.Nil(t, traceUpdateTotals(t, recorder, trace.GetID()))
+	assert.Empty(t, lf.usage.traces, "ended traces should no longer be tracked")
+}
+
+func TestLangfuse_UsageTotals(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	trace := lf.Trace("totals").WithTraceID("request-42")
+	trace.Generation("a").Usage(&types.Usage{Input: intPtr(3), Output: intPtr(4), Total: intPtr(9)})
+	trace.Generation("b").Usage(&types.Usage{Input: intPtr(1), TotalCost: floatPtr(0.5)})
+
+	// Generations of other traces are not counted
+	lf.Generation("standalone").UsageTokens(1000, 1000)
+
+	totals := lf.usageTotals("request-42")
+	require.NotNil(t, totals)
+	assert.Equal(t, 2, totals.Generations)
+	assert.Equal(t, 4, totals.Input)
+	assert.Equal(t, 4, totals.Output)
+	assert.Equal(t, 10, totals.Total)
+	assert.Nil(t, totals.InputCost)
+	require.NotNil(t, totals.TotalCost)
+	assert.Equal(t, 0.5, *totals.TotalCost)
+}
+
+func TestLangfuse_UsageRollup_DropsAbandonedTraces(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	abandoned := lf.Trace("abandoned")
+	abandoned.Generation("llm").UsageTokens(10, 5)
+	require.Contains(t, lf.usage.traces, abandoned.GetID())
+
+	// Opening a trace once the sweep interval has passed drops traces without activity
+	// for longer than the maximum age
+	lf.usage.mu.Lock()
+	lf.usage.traces[abandoned.GetID()].lastActive = time.Now().Add(-usageRollupMaxAge - time.Minute)
+	lf.usage.lastSweep = time.Now().Add(-usageRollupSweepInterval)
+	lf.usage.mu.Unlock()
+
+	recent := lf.Trace("recent")
+	assert.NotContains(t, lf.usage.traces, abandoned.GetID())
+	assert.Contains(t, lf.usage.traces, recent.GetID())
+
+	// Shutdown drops the traces that are still open
+	require.NoError(t, lf.Shutdown(context.Background()))
+	assert.Empty(t, lf.usage.traces)
+}
+
+func TestLangfuse_UsageRollup_KeepsLongRunningTraces(t *testing.T) {
+	recorder := &ingestionRecorder{}
+	mux := http.NewServeMux()
+	mux.Handle("/api/public/ingestion", recorder)
+	lf := newTestLangfuse(t, mux)
+	ctx := context.Background()
+
+	trace := lf.Trace("long-run")
+	require.NoError(t, trace.Begin(ctx))
+	require.NoError(t, trace.Generation("first").UsageTokens(100, 10).End(ctx))
+
+	// The trace was opened long ago, but a generation still reports usage under it
+	lf.usage.mu.Lock()
+	lf.usage.traces[trace.GetID()].lastActive = time.Now().Add(-2 * usageRollupMaxAge)
+	lf.usage.mu.Unlock()
+	require.NoError(t, trace.Generation("second").UsageTokens(200, 20).End(ctx))
+
+	lf.usage.mu.Lock()
+	lf.usage.lastSweep = time.Now().Add(-usageRollupSweepInterval)
+	lf.usage.mu.Unlock()
+	lf.Trace("sweeps")
+
+	require.NoError(t, trace.End(ctx))
+	require.NoError(t, lf.Shutdown(ctx))
+
+	totals := traceUpdateTotals(t, recorder, trace.GetID())
+	require.NotNil(t, totals)
+	assert.EqualValues(t, 2, totals["generations"])
+	assert.EqualValues(t, 330, totals["total"])
+}
+
+func TestGenerationBuilder_ID_KeepsRecordedUsage(t *testing.T) {
+	lf := newTestLangfuse(t, http.NewServeMux())
+
+	trace := lf.Trace("renamed")
+	trace.Generation("llm").UsageTokens(10, 5).ID("custom-generation-id")
+
+	totals := lf.usageTotals(trace.GetID())
+	require.NotNil(t, totals)
+	assert.Equal(t, 1, totals.Generations)
+	assert.Equal(t, 15, totals.Total)
+}
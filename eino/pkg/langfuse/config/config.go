@@ -1,11 +1,18 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-resty/resty/v2"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
 	"eino/pkg/langfuse/internal/utils"
 )
 
@@ -21,6 +28,8 @@ import (
 //   - LANGFUSE_HOST: API endpoint URL (default: "https://cloud.langfuse.com")
 //   - LANGFUSE_PUBLIC_KEY: API public key (required)
 //   - LANGFUSE_SECRET_KEY: API secret key (required)
+//   - LANGFUSE_PUBLIC_KEY_FILE, LANGFUSE_SECRET_KEY_FILE: Files holding the API keys,
+//     taking precedence over LANGFUSE_PUBLIC_KEY and LANGFUSE_SECRET_KEY (optional)
 //   - LANGFUSE_DEBUG: Enable debug logging (default: false)
 //   - LANGFUSE_ENABLED: Enable/disable SDK (default: true)
 //   - LANGFUSE_FLUSH_AT: Batch size for auto-flush (default: 15)
@@ -28,6 +37,9 @@ import (
 //   - LANGFUSE_TIMEOUT: Request timeout (default: 10s)
 //   - LANGFUSE_ENVIRONMENT: Environment name for traces (optional)
 //   - LANGFUSE_RELEASE: Release version for traces (optional)
+//   - LANGFUSE_PROJECT_ID: Project ID used to build trace URLs (optional)
+//   - LANGFUSE_API_VERSION: API version to request (default: "v1")
+//   - LANGFUSE_PAYLOAD_MODE: Which inputs and outputs are sent (default: "full")
 type Config struct {
 	// API Configuration - Connection settings for the Langfuse service
 
@@ -40,7 +52,8 @@ type Config struct {
 	// SecretKey is the API secret key for authentication
 	SecretKey string
 
-	// APIVersion specifies the API version to use (currently unused)
+	// APIVersion is the Langfuse API version requested with every call, sent in the
+	// X-Langfuse-Api-Version header (default APIVersionV1)
 	APIVersion string
 
 	// HTTP Client Configuration - Settings for the underlying HTTP transport
@@ -48,7 +61,9 @@ type Config struct {
 	// Timeout is the timeout for individual HTTP requests (deprecated, use RequestTimeout)
 	Timeout time.Duration
 
-	// RetryCount is the maximum number of retry attempts for failed requests
+	// RetryCount is the number of times the HTTP client retries a request that failed
+	// with a network error or a 408, 429 or 5xx response. Batches are also resubmitted by
+	// the queue, see BatchRetries and RetryAttempts.
 	RetryCount int
 
 	// RetryDelay is the initial delay between retry attempts (deprecated, use RetryWaitTime)
@@ -60,6 +75,22 @@ type Config struct {
 	// HTTPUserAgent is the User-Agent header for HTTP requests (deprecated, use UserAgent)
 	HTTPUserAgent string
 
+	// MaxConnsPerHost limits the total connections to the Langfuse host (0 keeps the
+	// HTTP transport default of no limit)
+	MaxConnsPerHost int
+
+	// MaxIdleConns is the number of idle connections kept open for reuse (0 keeps the
+	// HTTP transport default)
+	MaxIdleConns int
+
+	// IdleConnTimeout is how long an idle connection is kept before it is closed
+	// (0 keeps the HTTP transport default)
+	IdleConnTimeout time.Duration
+
+	// TCPKeepAlive is the interval between TCP keep-alive probes. Zero uses the Go
+	// default and a negative value disables keep-alive probes.
+	TCPKeepAlive time.Duration
+
 	// Queue Configuration - Settings for async event processing and batching
 
 	// FlushAt is the number of events that triggers an automatic flush to the API
@@ -71,7 +102,37 @@ type Config struct {
 	// QueueSize is the maximum number of events to buffer in memory
 	QueueSize int
 
-	// WorkerCount is the number of background workers for processing events (currently unused)
+	// BatchRetries is the number of times the queue resubmits a whole batch once its
+	// request failed, HTTP retries included
+	BatchRetries int
+
+	// MaxBatchAttempts caps the HTTP requests sent for one batch across HTTP retries and
+	// batch retries (0 means no cap), see RetryAttempts
+	MaxBatchAttempts int
+
+	// RejectWhenQueueFull makes builders return ErrQueueFull when QueueSize events are
+	// buffered, instead of dropping the oldest queued event
+	RejectWhenQueueFull bool
+
+	// ShutdownGracePeriod is how long Shutdown waits for builders that were created but not
+	// yet ended before flushing (zero means no wait)
+	ShutdownGracePeriod time.Duration
+
+	// ForceEndOnShutdown makes Shutdown end any builders still open after the grace period,
+	// marking them with "forcedEnd": "shutdown" metadata so in-progress traces are not lost
+	ForceEndOnShutdown bool
+
+	// SignalShutdownTimeout bounds the Shutdown triggered by ShutdownOnSignal
+	SignalShutdownTimeout time.Duration
+
+	// ExitAfterShutdown makes ShutdownOnSignal exit the process with ExitCode once shutdown
+	// completes, instead of re-raising the signal
+	ExitAfterShutdown bool
+
+	// ExitCode is the process exit code used when ExitAfterShutdown is set
+	ExitCode int
+
+	// WorkerCount is the number of workers submitting batches to the ingestion API concurrently
 	WorkerCount int
 
 	// Feature Flags - Enable/disable SDK features
@@ -79,6 +140,10 @@ type Config struct {
 	// Debug enables verbose logging for troubleshooting
 	Debug bool
 
+	// DebugRingBufferSize is the number of recently enqueued events kept in memory for
+	// Langfuse.RecentEvents and the debug handler (0 disables the buffer)
+	DebugRingBufferSize int
+
 	// Enabled controls whether the SDK performs any actual work (allows conditional disabling)
 	Enabled bool
 
@@ -93,6 +158,14 @@ type Config struct {
 	// Environment identifies the deployment environment in traces (e.g., "production", "staging")
 	Environment string
 
+	// ProjectID is the ID of the Langfuse project the keys belong to, used to build links
+	// to traces in the Langfuse UI
+	ProjectID string
+
+	// DefaultTags are added to every trace, before the trace's own tags and without
+	// duplicates (e.g. "team:platform")
+	DefaultTags []string
+
 	// RequestTimeout is the timeout for API requests
 	RequestTimeout time.Duration
 
@@ -104,9 +177,15 @@ type Config struct {
 
 	// Performance and Reliability Configuration
 
-	// SampleRate controls what fraction of events to actually submit (0.0-1.0, default 1.0)
+	// SampleRate is the fraction of traces sent, from 0.0 to 1.0 (default 1.0), for traces
+	// matching none of SamplingRules. Whether a trace is kept is derived from its ID, so
+	// every event of a trace gets the same decision.
 	SampleRate float64
 
+	// SamplingRules choose the sample rate of the traces they match; the first matching
+	// rule wins
+	SamplingRules []SamplingRule
+
 	// UserAgent is the User-Agent header value for HTTP requests
 	UserAgent string
 
@@ -120,17 +199,211 @@ type Config struct {
 	RetryMaxWaitTime       time.Duration
 	SkipInitialHealthCheck bool
 	RequireHealthyStart    bool
+
+	// HealthThresholds decide the overall status of Langfuse.HealthReport (default
+	// DefaultHealthThresholds)
+	HealthThresholds HealthThresholds
+
+	// HealthPollInterval, when positive, polls the health endpoint at this interval and
+	// pauses batch submission while the API reports itself unhealthy
+	HealthPollInterval time.Duration
+
+	// Extensibility - Hooks into the shared HTTP client used by every resource client
+
+	// RequestInterceptors run in order before each API request; an error aborts the request
+	RequestInterceptors []RequestInterceptor
+
+	// ResponseInterceptors run in order after each API response; an error is returned to the caller
+	ResponseInterceptors []ResponseInterceptor
+
+	// HTTPTransport replaces the HTTP transport of API requests, e.g. to record or replay
+	// them in tests. Connection pool settings cannot be applied to a custom transport.
+	HTTPTransport http.RoundTripper
+
+	// EventMiddleware runs in order on every ingestion event before it is queued
+	EventMiddleware []EventMiddleware
+
+	// ContextExtractors run in order on the context of Langfuse.WithContext(ctx).Trace to
+	// seed the trace metadata; later extractors win on conflicting keys
+	ContextExtractors []ContextExtractor
+
+	// SecondaryHost, when set, receives a copy of every event batch, authenticated with
+	// SecondaryPublicKey and SecondarySecretKey, e.g. while migrating between Langfuse
+	// instances. Only the primary destination decides whether a batch succeeded.
+	SecondaryHost      string
+	SecondaryPublicKey string
+	SecondarySecretKey string
+
+	// Projects maps environment names to the credentials of the Langfuse project that
+	// receives the traces of that environment, see WithProjectCredentials. Traces of
+	// other environments are sent with PublicKey and SecretKey.
+	Projects map[string]ProjectCredentials
+
+	// IngestionTransport replaces the REST ingestion endpoint as the destination of queued
+	// event batches, e.g. to publish them to a message broker relayed to Langfuse
+	IngestionTransport IngestionTransport
+
+	// StrictMode surfaces builder misuse (modifying or ending a builder twice, never ending it)
+	// as errors instead of silently ignoring it, and rejects unregistered score names
+	StrictMode bool
+
+	// PromptCacheTTL keeps prompt versions fetched by name and version in memory for this
+	// long (default 0, not cached)
+	PromptCacheTTL time.Duration
+
+	// SkipInvalidEvents drops events missing a required field from a batch, logging them,
+	// instead of failing the whole batch before it is sent
+	SkipInvalidEvents bool
+
+	// DeltaUpdates makes update events carry only the fields that changed since the
+	// builder's create event, instead of the full object. It applies to builders opened
+	// with Begin; Submit still sends the whole object and ends the builder.
+	DeltaUpdates bool
+
+	// CoalesceUpdates merges update events for the same trace or observation that are
+	// still queued at flush time into a single event
+	CoalesceUpdates bool
+
+	// ErrorPropagation marks a trace built with a TraceBuilder as failed when one of its
+	// spans or generations ends with level ERROR (default true)
+	ErrorPropagation bool
+
+	// Serialization
+
+	// MetadataTimeFormat controls how time.Time values inside metadata, input and output
+	// are serialized (default TimeFormatRFC3339Nano)
+	MetadataTimeFormat TimeFormat
+
+	// OutputFormatter normalizes the output of traces, spans and generations when they
+	// are ended (default identity)
+	OutputFormatter OutputFormatter
+
+	// PayloadMode selects which inputs and outputs are sent; withheld payloads are
+	// replaced by {"redacted": true} (default PayloadModeFull)
+	PayloadMode PayloadMode
+
+	// PayloadModeOverrideAllowed lets a per-trace payload mode replace PayloadMode
+	// instead of only narrowing it
+	PayloadModeOverrideAllowed bool
+
+	// TimeAnomalyPolicy selects how an end time before the start time is fixed when a
+	// builder is ended; TimeAnomalyReject, like StrictMode, rejects every time anomaly
+	// instead (default TimeAnomalyClamp)
+	TimeAnomalyPolicy TimeAnomalyPolicy
+
+	// MaxClockSkew is how far into the future a time may be before it is clamped to the
+	// current time when a builder is ended (default DefaultMaxClockSkew)
+	MaxClockSkew time.Duration
+
+	// UserIDHashSalt keys the hash that replaces the user ID of traces marked with
+	// TraceBuilder.WithAnonymousUser. Keep it secret and stable across a deployment.
+	UserIDHashSalt string
+
+	// Scores
+
+	// AllowedScoreNames registers the score names that may be created; other names are
+	// rejected in strict mode and reported with the closest registered name otherwise
+	AllowedScoreNames []string
+
+	// ScoreNamesRefreshInterval, when positive, also registers the names of the
+	// project's score configs, reloaded once they are older than the interval
+	ScoreNamesRefreshInterval time.Duration
+
+	// Diagnostics
+
+	// ErrorHandler receives the errors of asynchronous ingestion: failed batch
+	// submissions, dropped events and batches given up on after their last retry. It is
+	// called from a single goroutine separate from the flush loop.
+	ErrorHandler ErrorHandler
+
+	// Warnings lists adjustments made while loading the configuration, such as a path
+	// stripped from Host
+	Warnings []string
 }
 
 // ConfigOption represents a configuration option function
 type ConfigOption func(*Config) error
 
+// RequestInterceptor mutates or inspects an outgoing API request
+type RequestInterceptor func(*resty.Request) error
+
+// ResponseInterceptor inspects an API response before it is returned to the caller
+type ResponseInterceptor func(*resty.Response) error
+
+// EventMiddleware inspects or transforms an ingestion event before it is queued
+type EventMiddleware func(event ingestiontypes.IngestionEvent) ingestiontypes.IngestionEvent
+
+// ContextExtractor returns request-scoped values, such as a tenant or correlation ID,
+// to attach to the metadata of traces created with a context
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// OutputFormatter transforms a non-nil output before it is sent with the end of a trace,
+// span or generation
+type OutputFormatter func(output interface{}) interface{}
+
+// ErrorHandler receives an error of asynchronous ingestion
+type ErrorHandler func(err error)
+
+// IngestionTransport delivers batches of ingestion events. The REST ingestion client is
+// the default implementation.
+type IngestionTransport interface {
+	SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error)
+}
+
+// ProjectCredentials authenticate the events sent to a Langfuse project other than the
+// default one
+type ProjectCredentials struct {
+	PublicKey string
+	SecretKey string
+}
+
+// DefaultProject names the project of the client's own credentials, which receives the
+// traces of environments without project credentials
+const DefaultProject = "default"
+
+// APIVersionV1 is the current version of the Langfuse public API
+const APIVersionV1 = "v1"
+
+// supportedAPIVersions lists the API versions this SDK can talk to
+var supportedAPIVersions = []string{APIVersionV1}
+
+// SupportedAPIVersions returns the API versions accepted for Config.APIVersion
+func SupportedAPIVersions() []string {
+	return append([]string(nil), supportedAPIVersions...)
+}
+
+// IsSupportedAPIVersion reports whether version is one of the supported API versions
+func IsSupportedAPIVersion(version string) bool {
+	for _, supported := range supportedAPIVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeFormat selects how time.Time values are serialized in event payloads
+type TimeFormat string
+
+const (
+	// TimeFormatRFC3339Nano serializes times as RFC 3339 strings with nanosecond precision
+	TimeFormatRFC3339Nano TimeFormat = "rfc3339nano"
+
+	// TimeFormatEpochMillis serializes times as milliseconds since the Unix epoch
+	TimeFormatEpochMillis TimeFormat = "epoch_millis"
+)
+
+// IsValid reports whether the format is one of the supported time formats
+func (f TimeFormat) IsValid() bool {
+	return f == TimeFormatRFC3339Nano || f == TimeFormatEpochMillis
+}
+
 // DefaultConfig returns a Config with default values
 func DefaultConfig() *Config {
 	return &Config{
 		// API defaults
 		Host:       "https://cloud.langfuse.com",
-		APIVersion: "v1",
+		APIVersion: APIVersionV1,
 
 		// HTTP defaults
 		Timeout:       30 * time.Second,
@@ -140,16 +413,24 @@ func DefaultConfig() *Config {
 		HTTPUserAgent: "langfuse-go-sdk",
 
 		// Queue defaults
-		FlushAt:       100,
-		FlushInterval: 10 * time.Second,
-		QueueSize:     1000,
-		WorkerCount:   1,
+		FlushAt:          100,
+		FlushInterval:    10 * time.Second,
+		QueueSize:        1000,
+		WorkerCount:      1,
+		BatchRetries:     1,
+		MaxBatchAttempts: 8,
+
+		// Shutdown defaults
+		SignalShutdownTimeout: 10 * time.Second,
 
 		// Feature flags
 		Debug:     false,
 		Enabled:   true,
 		BatchMode: true,
 
+		// Trace defaults
+		ErrorPropagation: true,
+
 		// Advanced defaults
 		RequestTimeout: 10 * time.Second,
 		SDKName:        "langfuse-go",
@@ -163,6 +444,15 @@ func DefaultConfig() *Config {
 		RetryMaxWaitTime:       10 * time.Second,
 		SkipInitialHealthCheck: false,
 		RequireHealthyStart:    false,
+		HealthThresholds:       DefaultHealthThresholds(),
+
+		// Serialization defaults
+		MetadataTimeFormat: TimeFormatRFC3339Nano,
+		PayloadMode:        PayloadModeFull,
+
+		// Time normalization defaults
+		TimeAnomalyPolicy: TimeAnomalyClamp,
+		MaxClockSkew:      DefaultMaxClockSkew,
 	}
 }
 
@@ -190,18 +480,66 @@ func NewConfig(options ...ConfigOption) (*Config, error) {
 	return config, nil
 }
 
+// Clone returns a deep copy of the configuration, so changes to the copy's slices such
+// as RequestInterceptors or AllowedScoreNames never affect the original. Interceptors,
+// middleware and the ingestion transport themselves are shared.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.RequestInterceptors = cloneSlice(c.RequestInterceptors)
+	clone.ResponseInterceptors = cloneSlice(c.ResponseInterceptors)
+	clone.EventMiddleware = cloneSlice(c.EventMiddleware)
+	clone.ContextExtractors = cloneSlice(c.ContextExtractors)
+	clone.AllowedScoreNames = cloneSlice(c.AllowedScoreNames)
+	clone.SamplingRules = cloneSlice(c.SamplingRules)
+	clone.DefaultTags = cloneSlice(c.DefaultTags)
+	clone.Warnings = cloneSlice(c.Warnings)
+	clone.Projects = maps.Clone(c.Projects)
+	return &clone
+}
+
+// CloneWithOptions returns a deep copy of the configuration with options applied and
+// validated, leaving the original untouched
+func (c *Config) CloneWithOptions(options ...ConfigOption) (*Config, error) {
+	clone := c.Clone()
+	for _, option := range options {
+		if err := option(clone); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := clone.Validate(); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// cloneSlice copies s, keeping nil slices nil
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
 // LoadFromEnvironment loads configuration from environment variables
 func (c *Config) LoadFromEnvironment() error {
 	// API Configuration
 	if host := os.Getenv("LANGFUSE_HOST"); host != "" {
-		c.Host = strings.TrimSuffix(host, "/")
+		c.setHost(host)
 	}
 	if publicKey := os.Getenv("LANGFUSE_PUBLIC_KEY"); publicKey != "" {
 		c.PublicKey = publicKey
 	}
+	if apiVersion := os.Getenv("LANGFUSE_API_VERSION"); apiVersion != "" {
+		c.APIVersion = strings.ToLower(strings.TrimSpace(apiVersion))
+	}
 	if secretKey := os.Getenv("LANGFUSE_SECRET_KEY"); secretKey != "" {
 		c.SecretKey = secretKey
 	}
+	if err := c.loadCredentialFiles(); err != nil {
+		return err
+	}
 
 	// HTTP Configuration
 	if timeout := os.Getenv("LANGFUSE_TIMEOUT"); timeout != "" {
@@ -215,6 +553,12 @@ func (c *Config) LoadFromEnvironment() error {
 		}
 	}
 
+	if batchRetries := os.Getenv("LANGFUSE_BATCH_RETRIES"); batchRetries != "" {
+		if count, err := strconv.Atoi(batchRetries); err == nil && count >= 0 {
+			c.BatchRetries = count
+		}
+	}
+
 	// Queue Configuration
 	if flushAt := os.Getenv("LANGFUSE_FLUSH_AT"); flushAt != "" {
 		if count, err := strconv.Atoi(flushAt); err == nil && count > 0 {
@@ -255,62 +599,205 @@ func (c *Config) LoadFromEnvironment() error {
 	if environment := os.Getenv("LANGFUSE_ENVIRONMENT"); environment != "" {
 		c.Environment = environment
 	}
+	if projectID := os.Getenv("LANGFUSE_PROJECT_ID"); projectID != "" {
+		c.ProjectID = projectID
+	}
+
+	// Serialization
+	if payloadMode := os.Getenv("LANGFUSE_PAYLOAD_MODE"); payloadMode != "" {
+		// Unknown values are kept so that Validate rejects them rather than silently
+		// falling back to sending full payloads
+		c.PayloadMode = parsePayloadMode(payloadMode)
+	}
+	if salt := os.Getenv("LANGFUSE_USER_ID_HASH_SALT"); salt != "" {
+		c.UserIDHashSalt = salt
+	}
 
 	return nil
 }
 
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
+// Validate checks if the configuration is valid, collecting every invalid field
+// rather than stopping at the first. It returns nil when the configuration is valid.
+//
+// The result is a concrete pointer, so check it for nil before converting it to error:
+// a nil *ValidationErrors stored in an error variable is not a nil error. Callers given
+// an error, such as from NewConfig, reach the field list with errors.As.
+func (c *Config) Validate() *utils.ValidationErrors {
+	var errs utils.ValidationErrors
+
 	if c.PublicKey == "" {
-		return utils.NewConfigurationError("publicKey", "public key is required")
+		errs.Add("publicKey", "public key is required")
+	} else if err := validatePublicKey(c.PublicKey); err != nil {
+		errs.AddError(keyValidationError("publicKey", err))
 	}
 	if c.SecretKey == "" {
-		return utils.NewConfigurationError("secretKey", "secret key is required")
+		errs.Add("secretKey", "secret key is required")
+	} else if err := validateSecretKey(c.SecretKey); err != nil {
+		errs.AddError(keyValidationError("secretKey", err))
 	}
 	if c.Host == "" {
-		return utils.NewConfigurationError("host", "host is required")
-	}
-	if !strings.HasPrefix(c.Host, "http://") && !strings.HasPrefix(c.Host, "https://") {
-		return utils.NewConfigurationError("host", "host must include protocol (http:// or https://)")
+		errs.Add("host", "host is required")
+	} else if !strings.HasPrefix(c.Host, "http://") && !strings.HasPrefix(c.Host, "https://") {
+		errs.AddError(utils.ValidationError{Field: "host", Message: "host must include protocol (http:// or https://)", Value: c.Host})
 	}
 	if c.Timeout <= 0 {
-		return utils.NewConfigurationErrorWithExpected("timeout", "timeout must be positive", "> 0", c.Timeout.String())
+		errs.AddError(utils.ValidationError{Field: "timeout", Message: "timeout must be positive", Value: c.Timeout.String()})
 	}
 	if c.FlushAt <= 0 {
-		return utils.NewConfigurationErrorWithExpected("flushAt", "flush at must be positive", "> 0", strconv.Itoa(c.FlushAt))
+		errs.AddError(utils.ValidationError{Field: "flushAt", Message: "flush at must be positive", Value: strconv.Itoa(c.FlushAt)})
 	}
 	if c.FlushInterval <= 0 {
-		return utils.NewConfigurationErrorWithExpected("flushInterval", "flush interval must be positive", "> 0", c.FlushInterval.String())
+		errs.AddError(utils.ValidationError{Field: "flushInterval", Message: "flush interval must be positive", Value: c.FlushInterval.String()})
+	}
+	if c.MaxConnsPerHost != 0 || c.MaxIdleConns != 0 {
+		if c.MaxIdleConns < 1 {
+			errs.AddError(utils.ValidationError{Field: "maxIdleConns", Message: "max idle connections must be at least 1", Value: strconv.Itoa(c.MaxIdleConns)})
+		} else if c.MaxConnsPerHost < c.MaxIdleConns {
+			errs.AddError(utils.ValidationError{Field: "maxConnsPerHost", Message: "max connections must be >= max idle connections", Value: strconv.Itoa(c.MaxConnsPerHost)})
+		}
+	}
+	if c.IdleConnTimeout < 0 {
+		errs.AddError(utils.ValidationError{Field: "idleConnTimeout", Message: "idle connection timeout cannot be negative", Value: c.IdleConnTimeout.String()})
 	}
 	if c.QueueSize <= 0 {
-		return utils.NewConfigurationErrorWithExpected("queueSize", "queue size must be positive", "> 0", strconv.Itoa(c.QueueSize))
+		errs.AddError(utils.ValidationError{Field: "queueSize", Message: "queue size must be positive", Value: strconv.Itoa(c.QueueSize)})
 	}
 	if c.WorkerCount <= 0 {
-		return utils.NewConfigurationErrorWithExpected("workerCount", "worker count must be positive", "> 0", strconv.Itoa(c.WorkerCount))
+		errs.AddError(utils.ValidationError{Field: "workerCount", Message: "worker count must be positive", Value: strconv.Itoa(c.WorkerCount)})
+	}
+	if c.BatchRetries < 0 {
+		errs.AddError(utils.ValidationError{Field: "batchRetries", Message: "batch retries cannot be negative", Value: strconv.Itoa(c.BatchRetries)})
+	}
+	if c.MaxBatchAttempts < 0 {
+		errs.AddError(utils.ValidationError{Field: "maxBatchAttempts", Message: "max batch attempts cannot be negative", Value: strconv.Itoa(c.MaxBatchAttempts)})
+	}
+	if c.ShutdownGracePeriod < 0 {
+		errs.AddError(utils.ValidationError{Field: "shutdownGracePeriod", Message: "shutdown grace period cannot be negative", Value: c.ShutdownGracePeriod.String()})
+	}
+	if c.DebugRingBufferSize < 0 {
+		errs.AddError(utils.ValidationError{Field: "debugRingBufferSize", Message: "debug ring buffer size cannot be negative", Value: strconv.Itoa(c.DebugRingBufferSize)})
+	}
+	if c.SignalShutdownTimeout < 0 {
+		errs.AddError(utils.ValidationError{Field: "signalShutdownTimeout", Message: "signal shutdown timeout cannot be negative", Value: c.SignalShutdownTimeout.String()})
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs.AddError(utils.ValidationError{Field: "sampleRate", Message: "sample rate must be between 0 and 1", Value: fmt.Sprint(c.SampleRate)})
+	}
+	for i, rule := range c.SamplingRules {
+		if err := rule.validate(i); err != nil {
+			errs.AddError(*err)
+		}
+	}
+	if c.MetadataTimeFormat != "" && !c.MetadataTimeFormat.IsValid() {
+		errs.AddError(utils.ValidationError{Field: "metadataTimeFormat", Message: "unsupported metadata time format", Value: string(c.MetadataTimeFormat)})
+	}
+	if c.APIVersion != "" && !IsSupportedAPIVersion(c.APIVersion) {
+		errs.AddError(utils.ValidationError{Field: "apiVersion", Message: "unsupported API version", Value: c.APIVersion})
+	}
+	if c.PayloadMode != "" && !c.PayloadMode.IsValid() {
+		errs.AddError(utils.ValidationError{Field: "payloadMode", Message: "unsupported payload mode", Value: string(c.PayloadMode)})
+	}
+	if c.TimeAnomalyPolicy != "" && !c.TimeAnomalyPolicy.IsValid() {
+		errs.AddError(utils.ValidationError{Field: "timeAnomalyPolicy", Message: "unsupported time anomaly policy", Value: string(c.TimeAnomalyPolicy)})
+	}
+	if c.HealthPollInterval < 0 {
+		errs.AddError(utils.ValidationError{Field: "healthPollInterval", Message: "health poll interval cannot be negative", Value: c.HealthPollInterval.String()})
+	}
+	for _, err := range c.HealthThresholds.validate() {
+		errs.AddError(err)
+	}
+	if c.MaxClockSkew < 0 {
+		errs.AddError(utils.ValidationError{Field: "maxClockSkew", Message: "max clock skew cannot be negative", Value: c.MaxClockSkew.String()})
+	}
+	if c.ScoreNamesRefreshInterval < 0 {
+		errs.AddError(utils.ValidationError{Field: "scoreNamesRefreshInterval", Message: "score names refresh interval cannot be negative", Value: c.ScoreNamesRefreshInterval.String()})
+	}
+	if err := utils.ValidateTags(c.DefaultTags, "defaultTags", 0, 0); err != nil {
+		errs.AddError(*err)
+	}
+	if c.SecondaryHost != "" {
+		if !strings.HasPrefix(c.SecondaryHost, "http://") && !strings.HasPrefix(c.SecondaryHost, "https://") {
+			errs.AddError(utils.ValidationError{Field: "secondaryHost", Message: "secondary host must include protocol (http:// or https://)", Value: c.SecondaryHost})
+		}
+		if c.SecondaryPublicKey == "" || c.SecondarySecretKey == "" {
+			errs.Add("secondaryHost", "secondary host requires a public and a secret key")
+		} else {
+			if err := validatePublicKey(c.SecondaryPublicKey); err != nil {
+				errs.AddError(keyValidationError("secondaryPublicKey", err))
+			}
+			if err := validateSecretKey(c.SecondarySecretKey); err != nil {
+				errs.AddError(keyValidationError("secondarySecretKey", err))
+			}
+		}
+	}
+	for environment, credentials := range c.Projects {
+		if environment == "" || environment == DefaultProject {
+			errs.AddError(utils.ValidationError{Field: "projects", Message: "project environment must be set and cannot be " + DefaultProject, Value: environment})
+		}
+		if credentials.PublicKey == "" || credentials.SecretKey == "" {
+			errs.AddError(utils.ValidationError{Field: "projects", Message: "project requires a public and a secret key", Value: environment})
+			continue
+		}
+		if err := validatePublicKey(credentials.PublicKey); err != nil {
+			errs.AddError(utils.ValidationError{Field: "projects", Message: err.Message, Value: environment})
+		}
+		if err := validateSecretKey(credentials.SecretKey); err != nil {
+			errs.AddError(utils.ValidationError{Field: "projects", Message: err.Message, Value: environment})
+		}
 	}
 
-	return nil
+	if !errs.HasErrors() {
+		return nil
+	}
+	return &errs
 }
 
-// WithHost sets the Langfuse API host
+// WithHost sets the Langfuse API host. Any path, query or fragment is stripped, since
+// the SDK adds the API paths itself, and a warning is recorded in Config.Warnings.
 func WithHost(host string) ConfigOption {
 	return func(c *Config) error {
 		if host == "" {
 			return utils.NewConfigurationError("host", "host cannot be empty")
 		}
-		c.Host = strings.TrimSuffix(host, "/")
+		c.setHost(host)
+		return nil
+	}
+}
+
+// WithRegion sets the host of a Langfuse Cloud region, RegionEU or RegionUS
+func WithRegion(region string) ConfigOption {
+	return func(c *Config) error {
+		host, ok := regionHosts[strings.ToLower(region)]
+		if !ok {
+			return utils.NewConfigurationErrorWithExpected("region", "use WithHost for self-hosted deployments",
+				`"eu" or "us"`, fmt.Sprintf("%q", region))
+		}
+		c.Host = host
 		return nil
 	}
 }
 
-// WithCredentials sets the API credentials
+// WithAPIVersion sets the Langfuse API version to request
+func WithAPIVersion(version string) ConfigOption {
+	return func(c *Config) error {
+		if !IsSupportedAPIVersion(version) {
+			return utils.NewConfigurationErrorWithExpected("apiVersion", "unsupported API version",
+				strings.Join(supportedAPIVersions, ", "), version)
+		}
+		c.APIVersion = version
+		return nil
+	}
+}
+
+// WithCredentials sets the API credentials. The keys must carry the "pk-"/"sk-" prefixes
+// of Langfuse API keys.
 func WithCredentials(publicKey, secretKey string) ConfigOption {
 	return func(c *Config) error {
-		if publicKey == "" {
-			return utils.NewConfigurationError("publicKey", "public key cannot be empty")
+		if err := validatePublicKey(publicKey); err != nil {
+			return err
 		}
-		if secretKey == "" {
-			return utils.NewConfigurationError("secretKey", "secret key cannot be empty")
+		if err := validateSecretKey(secretKey); err != nil {
+			return err
 		}
 		c.PublicKey = publicKey
 		c.SecretKey = secretKey
@@ -321,8 +808,8 @@ func WithCredentials(publicKey, secretKey string) ConfigOption {
 // WithPublicKey sets the public key
 func WithPublicKey(publicKey string) ConfigOption {
 	return func(c *Config) error {
-		if publicKey == "" {
-			return utils.NewConfigurationError("publicKey", "public key cannot be empty")
+		if err := validatePublicKey(publicKey); err != nil {
+			return err
 		}
 		c.PublicKey = publicKey
 		return nil
@@ -332,8 +819,8 @@ func WithPublicKey(publicKey string) ConfigOption {
 // WithSecretKey sets the secret key
 func WithSecretKey(secretKey string) ConfigOption {
 	return func(c *Config) error {
-		if secretKey == "" {
-			return utils.NewConfigurationError("secretKey", "secret key cannot be empty")
+		if err := validateSecretKey(secretKey); err != nil {
+			return err
 		}
 		c.SecretKey = secretKey
 		return nil
@@ -370,6 +857,84 @@ func WithRetryConfig(count int, delay, maxDelay time.Duration) ConfigOption {
 	}
 }
 
+// WithBatchRetries sets how many times the queue resubmits a batch whose request failed
+// after its HTTP retries. See RetryAttempts.
+func WithBatchRetries(n int) ConfigOption {
+	return func(c *Config) error {
+		if n < 0 {
+			return utils.NewConfigurationError("batchRetries", "batch retries cannot be negative")
+		}
+		c.BatchRetries = n
+		return nil
+	}
+}
+
+// WithMaxBatchAttempts caps the HTTP requests sent for one batch across HTTP retries and
+// batch retries; 0 removes the cap. See RetryAttempts.
+func WithMaxBatchAttempts(n int) ConfigOption {
+	return func(c *Config) error {
+		if n < 0 {
+			return utils.NewConfigurationError("maxBatchAttempts", "max batch attempts cannot be negative")
+		}
+		c.MaxBatchAttempts = n
+		return nil
+	}
+}
+
+// RetryAttempts returns the HTTP retries per request and the queue retries per batch in
+// effect. Every batch attempt of the queue is a request with its own HTTP retries, so a
+// batch is sent at most (httpRetries+1) * (batchRetries+1) times. When MaxBatchAttempts
+// is set, HTTP retries are first reduced to fit in it, then batch retries so that the
+// product does not exceed it; with the defaults a batch is sent at most 8 times.
+func (c *Config) RetryAttempts() (httpRetries, batchRetries int) {
+	httpRetries = max(c.RetryCount, 0)
+	batchRetries = max(c.BatchRetries, 0)
+	if c.MaxBatchAttempts <= 0 {
+		return httpRetries, batchRetries
+	}
+
+	httpRetries = min(httpRetries, c.MaxBatchAttempts-1)
+	batchRetries = min(batchRetries, c.MaxBatchAttempts/(httpRetries+1)-1)
+	return httpRetries, batchRetries
+}
+
+// WithConnectionPoolConfig sets the HTTP connection pool limits used to reach the Langfuse host
+func WithConnectionPoolConfig(maxConns, maxIdleConns int, idleTimeout time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if maxIdleConns < 1 {
+			return utils.NewConfigurationErrorWithExpected("maxIdleConns", "max idle connections must be at least 1",
+				">= 1", strconv.Itoa(maxIdleConns))
+		}
+		if maxConns < maxIdleConns {
+			return utils.NewConfigurationErrorWithExpected("maxConns", "max connections must be >= max idle connections",
+				">= "+strconv.Itoa(maxIdleConns), strconv.Itoa(maxConns))
+		}
+		if idleTimeout < 0 {
+			return utils.NewConfigurationError("idleTimeout", "idle timeout cannot be negative")
+		}
+		c.MaxConnsPerHost = maxConns
+		c.MaxIdleConns = maxIdleConns
+		c.IdleConnTimeout = idleTimeout
+		return nil
+	}
+}
+
+// WithKeepAlive enables or disables TCP keep-alive probes. A zero probeInterval keeps
+// the Go default interval.
+func WithKeepAlive(enabled bool, probeInterval time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if !enabled {
+			c.TCPKeepAlive = -1
+			return nil
+		}
+		if probeInterval < 0 {
+			return utils.NewConfigurationError("probeInterval", "keep-alive probe interval cannot be negative")
+		}
+		c.TCPKeepAlive = probeInterval
+		return nil
+	}
+}
+
 // WithQueueConfig sets queue configuration
 func WithQueueConfig(flushAt int, flushInterval time.Duration, queueSize, workerCount int) ConfigOption {
 	return func(c *Config) error {
@@ -401,6 +966,18 @@ func WithDebug(enabled bool) ConfigOption {
 	}
 }
 
+// WithDebugRingBuffer keeps the last size enqueued events in memory, available from
+// Langfuse.RecentEvents and the debug handler. A size of 0 disables the buffer.
+func WithDebugRingBuffer(size int) ConfigOption {
+	return func(c *Config) error {
+		if size < 0 {
+			return utils.NewConfigurationError("debugRingBufferSize", "debug ring buffer size cannot be negative")
+		}
+		c.DebugRingBufferSize = size
+		return nil
+	}
+}
+
 // WithEnabled enables or disables the SDK
 func WithEnabled(enabled bool) ConfigOption {
 	return func(c *Config) error {
@@ -409,6 +986,98 @@ func WithEnabled(enabled bool) ConfigOption {
 	}
 }
 
+// WithStrictMode enables or disables strict builder misuse checks
+func WithStrictMode(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.StrictMode = enabled
+		return nil
+	}
+}
+
+// WithDeltaUpdates enables or disables sending only changed fields in update events
+func WithDeltaUpdates(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.DeltaUpdates = enabled
+		return nil
+	}
+}
+
+// WithPromptCacheTTL caches pinned prompt versions in memory for ttl
+func WithPromptCacheTTL(ttl time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if ttl < 0 {
+			return utils.NewConfigurationError("promptCacheTTL", "prompt cache TTL cannot be negative")
+		}
+		c.PromptCacheTTL = ttl
+		return nil
+	}
+}
+
+// WithSkipInvalidEvents makes batches drop and log invalid events instead of failing
+func WithSkipInvalidEvents(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.SkipInvalidEvents = enabled
+		return nil
+	}
+}
+
+// WithShutdownGracePeriod sets how long Shutdown waits for open builders to be ended
+func WithShutdownGracePeriod(period time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if period < 0 {
+			return utils.NewConfigurationError("shutdownGracePeriod", "shutdown grace period cannot be negative")
+		}
+		c.ShutdownGracePeriod = period
+		return nil
+	}
+}
+
+// WithSignalShutdown configures ShutdownOnSignal: how long the shutdown may take and
+// whether the process then exits with exitCode instead of re-raising the signal
+func WithSignalShutdown(timeout time.Duration, exitAfterShutdown bool, exitCode int) ConfigOption {
+	return func(c *Config) error {
+		if timeout < 0 {
+			return utils.NewConfigurationError("signalShutdownTimeout", "signal shutdown timeout cannot be negative")
+		}
+		c.SignalShutdownTimeout = timeout
+		c.ExitAfterShutdown = exitAfterShutdown
+		c.ExitCode = exitCode
+		return nil
+	}
+}
+
+// WithForceEndOnShutdown enables or disables ending builders that are still open at shutdown
+func WithForceEndOnShutdown(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.ForceEndOnShutdown = enabled
+		return nil
+	}
+}
+
+// WithCoalesceUpdates enables or disables merging queued update events for the same object
+func WithCoalesceUpdates(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.CoalesceUpdates = enabled
+		return nil
+	}
+}
+
+// WithErrorPropagation enables or disables tagging traces whose spans or generations failed
+func WithErrorPropagation(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.ErrorPropagation = enabled
+		return nil
+	}
+}
+
+// WithRejectWhenQueueFull makes a full queue reject new events instead of dropping the oldest
+func WithRejectWhenQueueFull(enabled bool) ConfigOption {
+	return func(c *Config) error {
+		c.RejectWhenQueueFull = enabled
+		return nil
+	}
+}
+
 // WithBatchMode enables or disables batch mode
 func WithBatchMode(enabled bool) ConfigOption {
 	return func(c *Config) error {
@@ -433,6 +1102,65 @@ func WithEnvironment(environment string) ConfigOption {
 	}
 }
 
+// WithDefaultTags sets the tags added to every trace, replacing earlier default tags
+func WithDefaultTags(tags ...string) ConfigOption {
+	return func(c *Config) error {
+		if err := utils.ValidateTags(tags, "defaultTags", 0, 0); err != nil {
+			return utils.NewConfigurationError(err.Field, err.Message)
+		}
+		c.DefaultTags = append([]string(nil), tags...)
+		return nil
+	}
+}
+
+// WithProjectID sets the ID of the Langfuse project, shown in the project's URL in the
+// Langfuse UI
+func WithProjectID(projectID string) ConfigOption {
+	return func(c *Config) error {
+		if projectID == "" {
+			return utils.NewConfigurationError("projectId", "project ID cannot be empty")
+		}
+		c.ProjectID = projectID
+		return nil
+	}
+}
+
+// WithUserIDHashSalt sets the salt used to hash the user ID of anonymized traces
+func WithUserIDHashSalt(salt string) ConfigOption {
+	return func(c *Config) error {
+		if salt == "" {
+			return utils.NewConfigurationError("userIdHashSalt", "user ID hash salt cannot be empty")
+		}
+		c.UserIDHashSalt = salt
+		return nil
+	}
+}
+
+// WithAllowedScoreNames registers the score names that may be created
+func WithAllowedScoreNames(names []string) ConfigOption {
+	return func(c *Config) error {
+		for _, name := range names {
+			if name == "" {
+				return utils.NewConfigurationError("allowedScoreNames", "score names cannot be empty")
+			}
+		}
+		c.AllowedScoreNames = append([]string(nil), names...)
+		return nil
+	}
+}
+
+// WithScoreConfigNames also registers the names of the project's score configs,
+// reloading them every refreshInterval
+func WithScoreConfigNames(refreshInterval time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if refreshInterval <= 0 {
+			return utils.NewConfigurationError("scoreNamesRefreshInterval", "score names refresh interval must be positive")
+		}
+		c.ScoreNamesRefreshInterval = refreshInterval
+		return nil
+	}
+}
+
 // WithUserAgent sets the HTTP user agent
 func WithUserAgent(userAgent string) ConfigOption {
 	return func(c *Config) error {
@@ -443,3 +1171,159 @@ func WithUserAgent(userAgent string) ConfigOption {
 		return nil
 	}
 }
+
+// WithMetadataTimeFormat sets how time.Time values inside metadata, input and output are serialized
+func WithMetadataTimeFormat(format TimeFormat) ConfigOption {
+	return func(c *Config) error {
+		if !format.IsValid() {
+			return utils.NewConfigurationErrorWithExpected("metadataTimeFormat", "unsupported metadata time format",
+				string(TimeFormatRFC3339Nano)+" or "+string(TimeFormatEpochMillis), string(format))
+		}
+		c.MetadataTimeFormat = format
+		return nil
+	}
+}
+
+// WithRequestInterceptor registers a function that can mutate every outgoing API request,
+// for example to add signing headers required by a proxy
+func WithRequestInterceptor(interceptor func(*resty.Request) error) ConfigOption {
+	return func(c *Config) error {
+		if interceptor == nil {
+			return utils.NewConfigurationError("requestInterceptors", "request interceptor cannot be nil")
+		}
+		c.RequestInterceptors = append(c.RequestInterceptors, interceptor)
+		return nil
+	}
+}
+
+// WithResponseInterceptor registers a function that runs on every API response
+func WithResponseInterceptor(interceptor func(*resty.Response) error) ConfigOption {
+	return func(c *Config) error {
+		if interceptor == nil {
+			return utils.NewConfigurationError("responseInterceptors", "response interceptor cannot be nil")
+		}
+		c.ResponseInterceptors = append(c.ResponseInterceptors, interceptor)
+		return nil
+	}
+}
+
+// WithHTTPTransport sends API requests through transport instead of the default HTTP transport
+func WithHTTPTransport(transport http.RoundTripper) ConfigOption {
+	return func(c *Config) error {
+		if transport == nil {
+			return utils.NewConfigurationError("httpTransport", "HTTP transport cannot be nil")
+		}
+		c.HTTPTransport = transport
+		return nil
+	}
+}
+
+// WithSecondaryHost also sends every event batch to a second Langfuse host with its own
+// credentials. Failures on the secondary host are logged and counted but never fail
+// the batch.
+func WithSecondaryHost(host, publicKey, secretKey string) ConfigOption {
+	return func(c *Config) error {
+		if host == "" {
+			return utils.NewConfigurationError("secondaryHost", "secondary host cannot be empty")
+		}
+		if err := validatePublicKey(publicKey); err != nil {
+			return err
+		}
+		if err := validateSecretKey(secretKey); err != nil {
+			return err
+		}
+		normalized, warning := normalizeHost(host)
+		if warning != "" {
+			c.Warnings = append(c.Warnings, "secondary "+warning)
+		}
+		c.SecondaryHost = normalized
+		c.SecondaryPublicKey = publicKey
+		c.SecondarySecretKey = secretKey
+		return nil
+	}
+}
+
+// WithProjectCredentials sends the traces of an environment, set with
+// TraceBuilder.WithEnvironment, to the Langfuse project of publicKey and secretKey
+// instead of the default project. Register it once per environment; traces of other
+// environments go to the default project.
+func WithProjectCredentials(environment, publicKey, secretKey string) ConfigOption {
+	return func(c *Config) error {
+		if environment == "" {
+			return utils.NewConfigurationError("projects", "project environment cannot be empty")
+		}
+		if environment == DefaultProject {
+			return utils.NewConfigurationError("projects", "the "+DefaultProject+" project uses the client's credentials; set them with WithCredentials")
+		}
+		if err := validatePublicKey(publicKey); err != nil {
+			return err
+		}
+		if err := validateSecretKey(secretKey); err != nil {
+			return err
+		}
+		if c.Projects == nil {
+			c.Projects = make(map[string]ProjectCredentials)
+		}
+		c.Projects[environment] = ProjectCredentials{PublicKey: publicKey, SecretKey: secretKey}
+		return nil
+	}
+}
+
+// WithIngestionTransport sends queued events through transport instead of the REST ingestion API
+func WithIngestionTransport(transport IngestionTransport) ConfigOption {
+	return func(c *Config) error {
+		if transport == nil {
+			return utils.NewConfigurationError("ingestionTransport", "ingestion transport cannot be nil")
+		}
+		c.IngestionTransport = transport
+		return nil
+	}
+}
+
+// WithOutputFormatter normalizes outputs when traces, spans and generations are ended
+func WithOutputFormatter(formatter func(output interface{}) interface{}) ConfigOption {
+	return func(c *Config) error {
+		if formatter == nil {
+			return utils.NewConfigurationError("outputFormatter", "output formatter cannot be nil")
+		}
+		c.OutputFormatter = formatter
+		return nil
+	}
+}
+
+// WithEventMiddleware registers a function that runs on every ingestion event before it is queued
+func WithEventMiddleware(middleware EventMiddleware) ConfigOption {
+	return func(c *Config) error {
+		if middleware == nil {
+			return utils.NewConfigurationError("eventMiddleware", "event middleware cannot be nil")
+		}
+		c.EventMiddleware = append(c.EventMiddleware, middleware)
+		return nil
+	}
+}
+
+// WithContextExtractor registers a function whose values seed the metadata of traces
+// created through Langfuse.WithContext
+func WithContextExtractor(extractor ContextExtractor) ConfigOption {
+	return func(c *Config) error {
+		if extractor == nil {
+			return utils.NewConfigurationError("contextExtractor", "context extractor cannot be nil")
+		}
+		c.ContextExtractors = append(c.ContextExtractors, extractor)
+		return nil
+	}
+}
+
+// WithErrorHandler registers a function called with every asynchronous ingestion error,
+// such as a failed flush or a dropped event, so they can be logged or alerted on in one
+// place. Errors are delivered in order from a bounded buffer and discarded when the
+// handler falls behind, so a slow handler never stalls the queue.
+func WithErrorHandler(handler func(err error)) ConfigOption {
+	return func(c *Config) error {
+		if handler == nil {
+			return utils.NewConfigurationError("errorHandler", "error handler cannot be nil")
+		}
+		c.ErrorHandler = handler
+		return nil
+	}
+}
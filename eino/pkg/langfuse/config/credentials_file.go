@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// Environment variables naming files that hold the API keys, as with secrets mounted as
+// files by Docker or Kubernetes. They take precedence over LANGFUSE_PUBLIC_KEY and
+// LANGFUSE_SECRET_KEY.
+const (
+	PublicKeyFileEnv = "LANGFUSE_PUBLIC_KEY_FILE"
+	SecretKeyFileEnv = "LANGFUSE_SECRET_KEY_FILE"
+)
+
+// readCredentialFile reads a key from path, trimming surrounding whitespace such as the
+// trailing newline most editors and secret stores add
+func readCredentialFile(parameter, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", utils.NewConfigurationError(parameter, fmt.Sprintf("failed to read key file: %v", err))
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", utils.NewConfigurationError(parameter, fmt.Sprintf("key file %s is empty", path))
+	}
+	return key, nil
+}
+
+// loadCredentialFiles sets the keys from the files named by PublicKeyFileEnv and
+// SecretKeyFileEnv, when set
+func (c *Config) loadCredentialFiles() error {
+	if path := os.Getenv(PublicKeyFileEnv); path != "" {
+		publicKey, err := readCredentialFile("publicKey", path)
+		if err != nil {
+			return err
+		}
+		c.PublicKey = publicKey
+	}
+	if path := os.Getenv(SecretKeyFileEnv); path != "" {
+		secretKey, err := readCredentialFile("secretKey", path)
+		if err != nil {
+			return err
+		}
+		c.SecretKey = secretKey
+	}
+	return nil
+}
+
+// WithCredentialsFromFiles sets the API credentials from the files at publicKeyPath and
+// secretKeyPath, trimming surrounding whitespace. The keys are checked like those given
+// to WithCredentials.
+func WithCredentialsFromFiles(publicKeyPath, secretKeyPath string) ConfigOption {
+	return func(c *Config) error {
+		publicKey, err := readCredentialFile("publicKey", publicKeyPath)
+		if err != nil {
+			return err
+		}
+		secretKey, err := readCredentialFile("secretKey", secretKeyPath)
+		if err != nil {
+			return err
+		}
+		return WithCredentials(publicKey, secretKey)(c)
+	}
+}
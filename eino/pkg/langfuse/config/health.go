@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// HealthThresholds decide when the SDK health report turns degraded or unhealthy. A zero
+// threshold is not checked.
+type HealthThresholds struct {
+	// QueueDegradedFill and QueueUnhealthyFill are fractions of QueueSize, from 0 to 1
+	QueueDegradedFill  float64
+	QueueUnhealthyFill float64
+
+	// FlushFailuresDegraded and FlushFailuresUnhealthy count consecutive failed batch
+	// submissions
+	FlushFailuresDegraded  int
+	FlushFailuresUnhealthy int
+
+	// APILatencyDegraded is the health endpoint round trip above which the API is
+	// considered degraded
+	APILatencyDegraded time.Duration
+}
+
+// DefaultHealthThresholds returns the thresholds used unless WithHealthThresholds is set
+func DefaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		QueueDegradedFill:      0.8,
+		QueueUnhealthyFill:     1,
+		FlushFailuresDegraded:  1,
+		FlushFailuresUnhealthy: 5,
+		APILatencyDegraded:     2 * time.Second,
+	}
+}
+
+// validate checks that fills are fractions and nothing is negative
+func (t HealthThresholds) validate() []utils.ValidationError {
+	var errs []utils.ValidationError
+	for field, fill := range map[string]float64{
+		"healthThresholds.queueDegradedFill":  t.QueueDegradedFill,
+		"healthThresholds.queueUnhealthyFill": t.QueueUnhealthyFill,
+	} {
+		if fill < 0 || fill > 1 {
+			errs = append(errs, utils.ValidationError{Field: field, Message: "queue fill must be between 0 and 1", Value: fmt.Sprint(fill)})
+		}
+	}
+	for field, count := range map[string]int{
+		"healthThresholds.flushFailuresDegraded":  t.FlushFailuresDegraded,
+		"healthThresholds.flushFailuresUnhealthy": t.FlushFailuresUnhealthy,
+	} {
+		if count < 0 {
+			errs = append(errs, utils.ValidationError{Field: field, Message: "flush failures cannot be negative", Value: strconv.Itoa(count)})
+		}
+	}
+	if t.APILatencyDegraded < 0 {
+		errs = append(errs, utils.ValidationError{Field: "healthThresholds.apiLatencyDegraded", Message: "API latency cannot be negative", Value: t.APILatencyDegraded.String()})
+	}
+	return errs
+}
+
+// WithHealthThresholds sets the thresholds of the SDK health report
+func WithHealthThresholds(thresholds HealthThresholds) ConfigOption {
+	return func(c *Config) error {
+		if errs := thresholds.validate(); len(errs) > 0 {
+			return utils.NewConfigurationError(errs[0].Field, errs[0].Message)
+		}
+		c.HealthThresholds = thresholds
+		return nil
+	}
+}
+
+// WithHealthPolling polls the health endpoint every interval and pauses batch submission
+// while the API reports itself unhealthy or cannot be reached, resuming once it is
+// healthy again. Events keep buffering up to QueueSize meanwhile.
+func WithHealthPolling(interval time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if interval <= 0 {
+			return utils.NewConfigurationError("healthPollInterval", "health poll interval must be positive")
+		}
+		c.HealthPollInterval = interval
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// PayloadMode selects which payloads (inputs and outputs) of traces, spans and
+// generations are sent to Langfuse. Withheld payloads are replaced by
+// {"redacted": true} before the event is queued.
+type PayloadMode string
+
+const (
+	// PayloadModeFull sends inputs and outputs
+	PayloadModeFull PayloadMode = "full"
+
+	// PayloadModeMetadataOnly withholds inputs and outputs, keeping timing, usage and metadata
+	PayloadModeMetadataOnly PayloadMode = "metadata_only"
+
+	// PayloadModeInputOnly sends inputs and withholds outputs
+	PayloadModeInputOnly PayloadMode = "input_only"
+
+	// PayloadModeOutputOnly sends outputs and withholds inputs
+	PayloadModeOutputOnly PayloadMode = "output_only"
+)
+
+// IsValid reports whether the mode is one of the supported payload modes
+func (m PayloadMode) IsValid() bool {
+	switch m {
+	case PayloadModeFull, PayloadModeMetadataOnly, PayloadModeInputOnly, PayloadModeOutputOnly:
+		return true
+	}
+	return false
+}
+
+// SendsInput reports whether inputs are sent in this mode. The empty mode sends
+// everything; an unknown mode sends nothing.
+func (m PayloadMode) SendsInput() bool {
+	return m == "" || m == PayloadModeFull || m == PayloadModeInputOnly
+}
+
+// SendsOutput reports whether outputs are sent in this mode. The empty mode sends
+// everything; an unknown mode sends nothing.
+func (m PayloadMode) SendsOutput() bool {
+	return m == "" || m == PayloadModeFull || m == PayloadModeOutputOnly
+}
+
+// Restrict returns the mode that sends only the payloads both m and other send, so
+// combining PayloadModeInputOnly with PayloadModeOutputOnly yields PayloadModeMetadataOnly
+func (m PayloadMode) Restrict(other PayloadMode) PayloadMode {
+	input := m.SendsInput() && other.SendsInput()
+	output := m.SendsOutput() && other.SendsOutput()
+	switch {
+	case input && output:
+		return PayloadModeFull
+	case input:
+		return PayloadModeInputOnly
+	case output:
+		return PayloadModeOutputOnly
+	}
+	return PayloadModeMetadataOnly
+}
+
+// parsePayloadMode accepts a payload mode in any case, with "-" in place of "_"
+func parsePayloadMode(value string) PayloadMode {
+	return PayloadMode(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "-", "_"))
+}
+
+// WithPayloadMode sets which payloads are sent (default PayloadModeFull)
+func WithPayloadMode(mode PayloadMode) ConfigOption {
+	return func(c *Config) error {
+		if !mode.IsValid() {
+			return utils.NewConfigurationErrorWithExpected("payloadMode", "unsupported payload mode",
+				"full, metadata_only, input_only or output_only", string(mode))
+		}
+		c.PayloadMode = mode
+		return nil
+	}
+}
+
+// WithPayloadModeOverrideAllowed lets TraceBuilder.WithPayloadMode replace the configured
+// payload mode, including with a less restrictive one. Without it the more restrictive of
+// the two applies.
+func WithPayloadModeOverrideAllowed(allowed bool) ConfigOption {
+	return func(c *Config) error {
+		c.PayloadModeOverrideAllowed = allowed
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// Langfuse Cloud regions accepted by WithRegion
+const (
+	RegionEU = "eu"
+	RegionUS = "us"
+)
+
+// Langfuse Cloud hosts of each region
+const (
+	HostEU = "https://cloud.langfuse.com"
+	HostUS = "https://us.cloud.langfuse.com"
+)
+
+// regionHosts maps each Langfuse Cloud region to its host
+var regionHosts = map[string]string{
+	RegionEU: HostEU,
+	RegionUS: HostUS,
+}
+
+// Accepted API key prefixes. Langfuse issues "pk-lf-"/"sk-lf-" keys; the underscore
+// variants are accepted for keys issued by older or self-hosted deployments.
+var (
+	publicKeyPrefixes = []string{"pk-", "pk_"}
+	secretKeyPrefixes = []string{"sk-", "sk_"}
+)
+
+// RegionForHost returns the Langfuse Cloud region served by host, or "" for any other
+// host, e.g. a self-hosted deployment
+func RegionForHost(host string) string {
+	parsed, err := url.Parse(strings.TrimSuffix(host, "/"))
+	if err != nil {
+		return ""
+	}
+	for region, regionHost := range regionHosts {
+		if strings.EqualFold(parsed.Host, strings.TrimPrefix(regionHost, "https://")) {
+			return region
+		}
+	}
+	return ""
+}
+
+// normalizeHost strips the trailing slash, path, query and fragment from host. Hosts
+// that cannot be parsed or have no scheme are returned as-is for Validate to report.
+// The returned warning is empty unless something besides a trailing slash was removed.
+func normalizeHost(host string) (normalized, warning string) {
+	host = strings.TrimSuffix(host, "/")
+
+	parsed, err := url.Parse(host)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return host, ""
+	}
+	if parsed.Path == "" && parsed.RawQuery == "" && parsed.Fragment == "" {
+		return host, ""
+	}
+
+	normalized = parsed.Scheme + "://" + parsed.Host
+	warning = fmt.Sprintf("host %q contains a path, query or fragment; using %q since API paths are added by the SDK", host, normalized)
+	return normalized, warning
+}
+
+// setHost sets the normalized host and records a warning if it had to be changed
+func (c *Config) setHost(host string) {
+	normalized, warning := normalizeHost(host)
+	c.Host = normalized
+	if warning != "" {
+		c.Warnings = append(c.Warnings, warning)
+	}
+}
+
+// validatePublicKey checks that publicKey looks like a Langfuse public key
+func validatePublicKey(publicKey string) *utils.ConfigurationError {
+	if publicKey == "" {
+		return utils.NewConfigurationError("publicKey", "public key cannot be empty")
+	}
+	if hasAnyPrefix(publicKey, secretKeyPrefixes) {
+		return utils.NewConfigurationError("publicKey", "public key looks like a secret key; are the public and secret keys swapped?")
+	}
+	if !hasAnyPrefix(publicKey, publicKeyPrefixes) {
+		return utils.NewConfigurationErrorWithExpected("publicKey", "copy the public key from the project's API keys settings",
+			`a key starting with "pk-lf-"`, redactKey(publicKey))
+	}
+	return nil
+}
+
+// validateSecretKey checks that secretKey looks like a Langfuse secret key
+func validateSecretKey(secretKey string) *utils.ConfigurationError {
+	if secretKey == "" {
+		return utils.NewConfigurationError("secretKey", "secret key cannot be empty")
+	}
+	if hasAnyPrefix(secretKey, publicKeyPrefixes) {
+		return utils.NewConfigurationError("secretKey", "secret key looks like a public key; are the public and secret keys swapped?")
+	}
+	if !hasAnyPrefix(secretKey, secretKeyPrefixes) {
+		return utils.NewConfigurationErrorWithExpected("secretKey", "copy the secret key from the project's API keys settings",
+			`a key starting with "sk-lf-"`, redactKey(secretKey))
+	}
+	return nil
+}
+
+// keyValidationError reports a failed key check as one of the fields listed by Validate
+func keyValidationError(field string, err *utils.ConfigurationError) utils.ValidationError {
+	message := err.Message
+	if err.Expected != "" {
+		message = fmt.Sprintf("expected %s; %s", err.Expected, message)
+	}
+	return utils.ValidationError{Field: field, Message: message, Value: err.Actual}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactKey keeps only the first few characters of a key so errors don't leak it
+func redactKey(key string) string {
+	const visible = 3
+	if len(key) <= visible {
+		return fmt.Sprintf("%q", key)
+	}
+	return fmt.Sprintf("%q", key[:visible]+"...")
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// SamplingMatch selects the traces a SamplingRule applies to. A trace must match every
+// field that is set; a match with no field set matches every trace.
+type SamplingMatch struct {
+	// NamePattern is a regular expression matched against the trace name. It matches
+	// anywhere in the name unless anchored, e.g. "^checkout$".
+	NamePattern string
+
+	// Tag matches traces carrying this tag
+	Tag string
+
+	// UserIDPrefix matches traces whose user ID starts with this prefix
+	UserIDPrefix string
+
+	// Environment matches traces of this environment
+	Environment string
+}
+
+// SamplingRule keeps Rate of the traces its Match selects, from 0 (none) to 1 (all)
+type SamplingRule struct {
+	Match SamplingMatch
+	Rate  float64
+}
+
+// validate checks the rule at index i of SamplingRules
+func (r SamplingRule) validate(i int) *utils.ValidationError {
+	field := "samplingRules[" + strconv.Itoa(i) + "]"
+	if r.Rate < 0 || r.Rate > 1 {
+		return &utils.ValidationError{Field: field + ".rate", Message: "sample rate must be between 0 and 1", Value: fmt.Sprint(r.Rate)}
+	}
+	if r.Match.NamePattern != "" {
+		if _, err := regexp.Compile(r.Match.NamePattern); err != nil {
+			return &utils.ValidationError{Field: field + ".match.namePattern", Message: "invalid regular expression: " + err.Error(), Value: r.Match.NamePattern}
+		}
+	}
+	return nil
+}
+
+// WithSampleRate sets the fraction of traces that are sent, from 0 (none) to 1 (all).
+// Traces matching a sampling rule use the rule's rate instead.
+func WithSampleRate(rate float64) ConfigOption {
+	return func(c *Config) error {
+		if rate < 0 || rate > 1 {
+			return utils.NewConfigurationErrorWithExpected("sampleRate", "sample rate out of range", "between 0 and 1", fmt.Sprint(rate))
+		}
+		c.SampleRate = rate
+		return nil
+	}
+}
+
+// WithSamplingRules sets the rules choosing the sample rate of each trace, replacing
+// any set before. Rules are evaluated in order and the first one matching a trace
+// decides its rate; traces matching none use SampleRate.
+//
+// Example:
+//
+//	config.WithSamplingRules([]config.SamplingRule{
+//		{Match: config.SamplingMatch{NamePattern: "^checkout"}, Rate: 1},
+//		{Match: config.SamplingMatch{NamePattern: "^healthcheck$"}, Rate: 0.01},
+//	})
+func WithSamplingRules(rules []SamplingRule) ConfigOption {
+	return func(c *Config) error {
+		for i, rule := range rules {
+			if err := rule.validate(i); err != nil {
+				return utils.NewConfigurationError(err.Field, err.Message)
+			}
+		}
+		c.SamplingRules = cloneSlice(rules)
+		return nil
+	}
+}
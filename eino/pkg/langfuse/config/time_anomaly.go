@@ -0,0 +1,55 @@
+package config
+
+import (
+	"time"
+
+	"eino/pkg/langfuse/internal/utils"
+)
+
+// DefaultMaxClockSkew is how far into the future a time may be before builders clamp it
+// to the current time
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// TimeAnomalyPolicy selects how builders handle an end time before the start time when
+// they are ended. Times too far in the future and times with a wrong epoch magnitude
+// (seconds read as milliseconds or the reverse) are corrected by every policy but
+// TimeAnomalyReject.
+type TimeAnomalyPolicy string
+
+const (
+	// TimeAnomalyClamp moves the end time to the start time
+	TimeAnomalyClamp TimeAnomalyPolicy = "clamp"
+
+	// TimeAnomalySwap exchanges the start and end times
+	TimeAnomalySwap TimeAnomalyPolicy = "swap"
+
+	// TimeAnomalyReject makes End fail with a validation error instead of fixing times
+	TimeAnomalyReject TimeAnomalyPolicy = "reject"
+)
+
+// IsValid reports whether the policy is one of the supported time anomaly policies
+func (p TimeAnomalyPolicy) IsValid() bool {
+	switch p {
+	case TimeAnomalyClamp, TimeAnomalySwap, TimeAnomalyReject:
+		return true
+	}
+	return false
+}
+
+// WithTimeAnomalyPolicy sets how builders fix inconsistent times when they are ended
+// (default TimeAnomalyClamp) and how far into the future a time may be before it is
+// clamped to the current time (default DefaultMaxClockSkew)
+func WithTimeAnomalyPolicy(policy TimeAnomalyPolicy, maxClockSkew time.Duration) ConfigOption {
+	return func(c *Config) error {
+		if !policy.IsValid() {
+			return utils.NewConfigurationErrorWithExpected("timeAnomalyPolicy", "unsupported time anomaly policy",
+				"clamp, swap or reject", string(policy))
+		}
+		if maxClockSkew <= 0 {
+			return utils.NewConfigurationError("maxClockSkew", "max clock skew must be positive")
+		}
+		c.TimeAnomalyPolicy = policy
+		c.MaxClockSkew = maxClockSkew
+		return nil
+	}
+}
@@ -45,6 +45,8 @@
 //	LANGFUSE_FLUSH_AT      - Batch size for auto-flush (default: 15)
 //	LANGFUSE_FLUSH_INTERVAL - Time interval for auto-flush (default: 10s)
 //	LANGFUSE_TIMEOUT       - Request timeout (default: 10s)
+//	LANGFUSE_RETRY_COUNT   - HTTP retries per request (default: 3)
+//	LANGFUSE_BATCH_RETRIES - Queue resubmissions of a failed batch (default: 1)
 //	LANGFUSE_ENVIRONMENT   - Environment name for traces
 //	LANGFUSE_RELEASE       - Release version for traces
 //
@@ -61,6 +63,32 @@
 //		client.WithRetrySettings(5, 2*time.Second),
 //	)
 //
+// Traces of an environment can be sent to a project of their own; traces of other
+// environments use the main credentials:
+//
+//	langfuse, err := client.NewWithOptions(
+//		client.WithCredentials("pk_...", "sk_..."),
+//		client.WithProjectCredentials("staging", "pk_staging...", "sk_staging..."),
+//	)
+//
+//	trace := langfuse.Trace("checkout").WithEnvironment("staging")
+//
+// A batch that keeps failing is sent (RetryCount+1) * (BatchRetries+1) times, HTTP
+// retries being repeated on every resubmission of the batch, and never more than
+// MaxBatchAttempts times (default 8); see Config.RetryAttempts.
+//
+// # Default Client
+//
+// Small tools can use the package-level client, created from the environment on first
+// use. Ready reports why it could not be created, in which case the helpers are no-ops:
+//
+//	if err := client.Ready(); err != nil {
+//		log.Printf("tracing disabled: %v", err)
+//	}
+//	defer client.CloseDefault(context.Background())
+//
+//	trace := client.Trace("my-operation")
+//
 // # Core Concepts
 //
 // ## Traces
@@ -237,8 +265,16 @@
 //
 // ## gRPC Interceptors
 //
+//	interceptors := middleware.DefaultGRPCInterceptorConfig(langfuse)
 //	server := grpc.NewServer(
-//		grpc.UnaryInterceptor(middleware.UnaryServerInterceptor(langfuse)),
+//		grpc.UnaryInterceptor(middleware.UnaryServerInterceptor(interceptors)),
+//	)
+//
+// The client interceptor propagates the trace ID in the outgoing metadata, so the server
+// records the call as a span of the caller's trace:
+//
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithUnaryInterceptor(middleware.UnaryClientInterceptor(interceptors)),
 //	)
 //
 // # Best Practices
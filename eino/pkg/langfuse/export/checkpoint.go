@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Checkpoint records the progress of an export so a later run can resume it. Traces are
+// exported in timestamp order, so the progress is the timestamp of the last exported
+// trace and the IDs of the traces exported at exactly that timestamp.
+type Checkpoint struct {
+	// Columns are the columns of the rows written so far; resumed runs keep them
+	Columns []Column `json:"columns"`
+
+	// LastTimestamp is the timestamp of the last exported trace
+	LastTimestamp time.Time `json:"lastTimestamp"`
+
+	// LastIDs are the IDs of the exported traces whose timestamp is LastTimestamp
+	LastIDs []string `json:"lastIds"`
+
+	// Rows is the number of rows exported by all runs
+	Rows int64 `json:"rows"`
+}
+
+// LoadCheckpoint reads the checkpoint at path. It returns nil without error when the
+// file does not exist, as for a first run.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// save atomically replaces the checkpoint at path, so an interrupted save leaves the
+// previous checkpoint intact
+func (c *Checkpoint) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// exported reports whether a trace was already exported by an earlier run or page
+func (c *Checkpoint) exported(id string, timestamp time.Time) bool {
+	if timestamp.Before(c.LastTimestamp) {
+		return true
+	}
+	if !timestamp.Equal(c.LastTimestamp) {
+		return false
+	}
+	return slices.Contains(c.LastIDs, id)
+}
+
+// advance records a trace as exported
+func (c *Checkpoint) advance(id string, timestamp time.Time) {
+	if timestamp.After(c.LastTimestamp) {
+		c.LastTimestamp = timestamp
+		c.LastIDs = c.LastIDs[:0]
+	}
+	c.LastIDs = append(c.LastIDs, id)
+	c.Rows++
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"strings"
+
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+// ColumnType is the type of the values of a column
+type ColumnType string
+
+const (
+	ColumnString ColumnType = "string"
+	ColumnInt    ColumnType = "int"
+	ColumnFloat  ColumnType = "float"
+	ColumnTime   ColumnType = "time"
+)
+
+// Column is a column of an export. Values are strings, int64, float64 or time.Time per
+// the column type, or nil when the trace has no value.
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+
+	value func(row *traceRow) interface{}
+}
+
+// traceRow is a trace with the totals computed from its generations
+type traceRow struct {
+	trace       *commonTypes.Trace
+	totalTokens *int64
+}
+
+// TraceColumns are the columns of a trace export, in order. Tags are flattened into a
+// single comma-separated column.
+var TraceColumns = []Column{
+	{Name: "trace_id", Type: ColumnString, value: func(r *traceRow) interface{} { return r.trace.ID }},
+	{Name: "name", Type: ColumnString, value: func(r *traceRow) interface{} { return stringValue(r.trace.Name) }},
+	{Name: "user_id", Type: ColumnString, value: func(r *traceRow) interface{} { return stringValue(r.trace.UserID) }},
+	{Name: "session_id", Type: ColumnString, value: func(r *traceRow) interface{} { return stringValue(r.trace.SessionID) }},
+	{Name: "timestamp", Type: ColumnTime, value: func(r *traceRow) interface{} { return r.trace.Timestamp.UTC() }},
+	{Name: "latency_seconds", Type: ColumnFloat, value: func(r *traceRow) interface{} { return floatValue(r.trace.Latency) }},
+	{Name: "total_tokens", Type: ColumnInt, value: func(r *traceRow) interface{} { return intValue(r.totalTokens) }},
+	{Name: "total_cost", Type: ColumnFloat, value: func(r *traceRow) interface{} { return floatValue(r.trace.TotalCost) }},
+	{Name: "tags", Type: ColumnString, value: func(r *traceRow) interface{} {
+		if len(r.trace.Tags) == 0 {
+			return nil
+		}
+		return strings.Join(r.trace.Tags, ",")
+	}},
+}
+
+// resolveColumns returns the columns named by a checkpoint, so a resumed export keeps the
+// layout of the rows already written. Columns this version no longer knows are written
+// empty, and columns added since are left out until a fresh export.
+func resolveColumns(names []Column) []Column {
+	columns := make([]Column, len(names))
+	for i, saved := range names {
+		columns[i] = Column{Name: saved.Name, Type: saved.Type, value: func(*traceRow) interface{} { return nil }}
+		for _, known := range TraceColumns {
+			if known.Name == saved.Name {
+				columns[i] = known
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// rowValues returns the values of row for columns
+func rowValues(columns []Column, row *traceRow) []interface{} {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = column.value(row)
+	}
+	return values
+}
+
+func stringValue(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func floatValue(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func intValue(value *int64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
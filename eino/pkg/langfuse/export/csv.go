@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer receives the rows of an export
+type Writer interface {
+	// WriteHeader is called once per run, before any row, with the columns of every row.
+	// resumed is true when the run continues an export whose earlier rows were already
+	// written by a previous run.
+	WriteHeader(columns []Column, resumed bool) error
+
+	// WriteRow writes the values of one row, in column order
+	WriteRow(values []interface{}) error
+
+	// Flush makes the rows written so far durable. It is called before every checkpoint,
+	// so a checkpoint never covers rows that were not flushed.
+	Flush() error
+}
+
+// CSVWriter writes an export as CSV. To resume an export, open the same file for
+// appending: the header is only written by runs that do not resume.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+// NewCSVWriter creates a CSV writer writing to w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// WriteHeader writes the column names unless the export is resumed
+func (c *CSVWriter) WriteHeader(columns []Column, resumed bool) error {
+	c.columns = columns
+	if resumed {
+		return nil
+	}
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return c.w.Write(names)
+}
+
+// WriteRow writes one record; nil values are written as empty fields
+func (c *CSVWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		field, err := formatCSVValue(value)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", c.columns[i].Name, err)
+		}
+		record[i] = field
+	}
+	return c.w.Write(record)
+}
+
+// Flush flushes the buffered records to the underlying writer
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatCSVValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,252 @@
+// Package export streams traces out of Langfuse into files for analytics warehouses.
+//
+// Exports are resumable: with a checkpoint file, every page of rows is flushed and then
+// recorded in the checkpoint, so a run that is interrupted, or a later run looking for
+// new traces, continues after the last exported trace without duplicating or skipping
+// rows.
+//
+// Example, appending new traces to a CSV file on every run:
+//
+//	file, err := os.OpenFile("traces.csv", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	if err != nil {
+//		return err
+//	}
+//	defer file.Close()
+//
+//	result, err := export.Traces(ctx, apiClient, export.Filter{
+//		Since:      time.Now().AddDate(0, 0, -30),
+//		Checkpoint: "traces.checkpoint.json",
+//	}, export.NewCSVWriter(file))
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eino/pkg/langfuse/api"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	observationTypes "eino/pkg/langfuse/api/resources/observations/types"
+	traceTypes "eino/pkg/langfuse/api/resources/traces/types"
+)
+
+const (
+	// DefaultPageSize is the number of traces listed per request when Filter.PageSize is unset
+	DefaultPageSize = 100
+
+	// maxPageSize is the largest page the list endpoints accept
+	maxPageSize = 1000
+)
+
+// Filter selects the traces to export and where the export's progress is kept
+type Filter struct {
+	Name        string
+	UserID      string
+	SessionID   string
+	Environment string
+	Tags        []string
+
+	// Since exports only traces at or after this time. With a checkpoint, the export
+	// starts from whichever of Since and the checkpoint is later, so the same Since can
+	// be passed on every incremental run.
+	Since time.Time
+
+	// Until exports only traces at or before this time (optional)
+	Until time.Time
+
+	// PageSize is the number of traces listed per request, DefaultPageSize when zero.
+	// Memory use is bounded by one page of traces.
+	PageSize int
+
+	// Checkpoint is the path of the checkpoint file. It is created by the first run and
+	// read by later ones to resume; without it every run exports from Since.
+	Checkpoint string
+}
+
+// Result summarizes an export run
+type Result struct {
+	// Rows is the number of rows written by this run
+	Rows int64
+
+	// Resumed is true when the run continued from a checkpoint
+	Resumed bool
+
+	// Checkpoint is the progress after the run, also saved to Filter.Checkpoint when set
+	Checkpoint *Checkpoint
+}
+
+// Traces exports the traces matching filter to w, oldest first, one row per trace with
+// the TraceColumns. The total tokens of each trace are summed from its generations, which
+// takes one extra request per trace.
+//
+// When ctx is cancelled, the run stops without writing the page it was fetching and
+// returns the error along with the result. Every row written has been flushed and
+// checkpointed, so the next run resumes right after it.
+func Traces(ctx context.Context, apiClient *api.APIClient, filter Filter, w Writer) (*Result, error) {
+	if apiClient == nil {
+		return nil, fmt.Errorf("api client cannot be nil")
+	}
+	if w == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > maxPageSize {
+		return nil, fmt.Errorf("page size must be at most %d", maxPageSize)
+	}
+
+	result := &Result{}
+	checkpoint := &Checkpoint{Columns: TraceColumns}
+	if filter.Checkpoint != "" {
+		saved, err := LoadCheckpoint(filter.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil {
+			checkpoint = saved
+			result.Resumed = true
+		}
+	}
+	result.Checkpoint = checkpoint
+
+	columns := resolveColumns(checkpoint.Columns)
+	if err := w.WriteHeader(columns, result.Resumed); err != nil {
+		return result, fmt.Errorf("failed to write header: %w", err)
+	}
+	if !result.Resumed && filter.Checkpoint != "" {
+		// Save right away so a run interrupted before its first page is still resumed, and
+		// does not write the header twice
+		if err := w.Flush(); err != nil {
+			return result, fmt.Errorf("failed to flush header: %w", err)
+		}
+		if err := checkpoint.save(filter.Checkpoint); err != nil {
+			return result, err
+		}
+	}
+
+	from := filter.Since
+	if checkpoint.LastTimestamp.After(from) {
+		from = checkpoint.LastTimestamp
+	}
+
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		response, err := apiClient.Traces.List(ctx, listRequest(filter, from, page, pageSize))
+		if err != nil {
+			return result, fmt.Errorf("failed to list traces page %d: %w", page, err)
+		}
+
+		// Fetch everything the page needs before writing it, so a cancelled run never
+		// leaves rows written past its checkpoint
+		rows := make([]*traceRow, 0, len(response.Data))
+		for i := range response.Data {
+			trace := &response.Data[i]
+			if checkpoint.exported(trace.ID, trace.Timestamp) {
+				continue
+			}
+			totalTokens, err := traceTokens(ctx, apiClient, trace.ID)
+			if err != nil {
+				return result, err
+			}
+			rows = append(rows, &traceRow{trace: trace, totalTokens: totalTokens})
+		}
+
+		for _, row := range rows {
+			if err := w.WriteRow(rowValues(columns, row)); err != nil {
+				return result, fmt.Errorf("failed to write trace %s: %w", row.trace.ID, err)
+			}
+			checkpoint.advance(row.trace.ID, row.trace.Timestamp)
+			result.Rows++
+		}
+
+		if err := w.Flush(); err != nil {
+			return result, fmt.Errorf("failed to flush rows: %w", err)
+		}
+		if filter.Checkpoint != "" {
+			if err := checkpoint.save(filter.Checkpoint); err != nil {
+				return result, err
+			}
+		}
+
+		if len(response.Data) < pageSize || page >= response.Meta.TotalPages {
+			return result, nil
+		}
+	}
+}
+
+// listRequest builds the request of one page of traces, sorted oldest first
+func listRequest(filter Filter, from time.Time, page, pageSize int) *traceTypes.GetTracesRequest {
+	req := &traceTypes.GetTracesRequest{
+		Page:      &page,
+		Limit:     &pageSize,
+		Tags:      filter.Tags,
+		SortBy:    commonTypes.SortFieldTimestamp,
+		SortOrder: commonTypes.SortOrderAsc,
+	}
+	if filter.Name != "" {
+		req.Name = &filter.Name
+	}
+	if filter.UserID != "" {
+		req.UserID = &filter.UserID
+	}
+	if filter.SessionID != "" {
+		req.SessionID = &filter.SessionID
+	}
+	if filter.Environment != "" {
+		req.Environment = &filter.Environment
+	}
+	if !from.IsZero() {
+		// The API compares at millisecond precision, so round down to include from itself
+		fromTimestamp := from.UTC().Truncate(time.Millisecond)
+		req.FromTimestamp = &fromTimestamp
+	}
+	if !filter.Until.IsZero() {
+		until := filter.Until.UTC()
+		req.ToTimestamp = &until
+	}
+	return req
+}
+
+// traceTokens sums the token usage of the generations of a trace, or returns nil when
+// none of them reports usage
+func traceTokens(ctx context.Context, apiClient *api.APIClient, traceID string) (*int64, error) {
+	generation := commonTypes.ObservationTypeGeneration
+	limit := maxPageSize
+
+	var total *int64
+	for page := 1; ; page++ {
+		response, err := apiClient.Observations.List(ctx, &observationTypes.GetObservationsRequest{
+			TraceID: &traceID,
+			Type:    &generation,
+			Page:    &page,
+			Limit:   &limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list generations of trace %s: %w", traceID, err)
+		}
+
+		for _, observation := range response.Data {
+			if observation.Usage == nil {
+				continue
+			}
+			usage := *observation.Usage
+			usage.CalculateTotalTokens()
+			if usage.Total == nil {
+				continue
+			}
+			if total == nil {
+				total = new(int64)
+			}
+			*total += int64(*usage.Total)
+		}
+
+		if len(response.Data) < limit || page >= response.Meta.TotalPages {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/config"
+)
+
+var baseTime = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+// fixtureServer serves traces sorted by timestamp, filtered by fromTimestamp and paged
+// like the public API, and two generations of 10 and 5 tokens per trace
+type fixtureServer struct {
+	mu     sync.Mutex
+	traces []commonTypes.Trace
+
+	// onGenerations is called with the number of generation requests served so far
+	onGenerations func(count int)
+	generations   int
+}
+
+// addTraces appends n traces; two consecutive traces share each timestamp so that pages
+// end in the middle of a timestamp
+func (s *fixtureServer) addTraces(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		index := len(s.traces)
+		name, user := "checkout", fmt.Sprintf("user-%d", index%3)
+		latency, cost := float64(index)/10, 0.002
+		s.traces = append(s.traces, commonTypes.Trace{
+			ID:        fmt.Sprintf("trace-%02d", index),
+			Name:      &name,
+			UserID:    &user,
+			Timestamp: baseTime.Add(time.Duration(index/2) * time.Second),
+			Tags:      []string{"prod", "eu"},
+			Latency:   &latency,
+			TotalCost: &cost,
+		})
+	}
+}
+
+func (s *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.URL.Path {
+	case "/api/public/traces":
+		if query.Get("sortBy") != "timestamp" || query.Get("order") != "asc" {
+			http.Error(w, "export must sort by timestamp", http.StatusBadRequest)
+			return
+		}
+		var from time.Time
+		if value := query.Get("fromTimestamp"); value != "" {
+			from, _ = time.Parse(time.RFC3339Nano, value)
+		}
+
+		s.mu.Lock()
+		var matching []commonTypes.Trace
+		for _, trace := range s.traces {
+			if !trace.Timestamp.Before(from) {
+				matching = append(matching, trace)
+			}
+		}
+		s.mu.Unlock()
+
+		start := min((page-1)*limit, len(matching))
+		end := min(start+limit, len(matching))
+		totalPages := (len(matching) + limit - 1) / limit
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": matching[start:end],
+			"meta": map[string]interface{}{"page": page, "limit": limit, "totalItems": len(matching), "totalPages": totalPages},
+		})
+
+	case "/api/public/observations":
+		s.mu.Lock()
+		s.generations++
+		count, onGenerations := s.generations, s.onGenerations
+		s.mu.Unlock()
+		if onGenerations != nil {
+			onGenerations(count)
+		}
+
+		input, output, total := 6, 4, 5
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []commonTypes.Observation{
+				{ID: "gen-1", TraceID: query.Get("traceId"), Type: commonTypes.ObservationTypeGeneration, StartTime: baseTime,
+					Usage: &commonTypes.Usage{Input: &input, Output: &output}},
+				{ID: "gen-2", TraceID: query.Get("traceId"), Type: commonTypes.ObservationTypeGeneration, StartTime: baseTime,
+					Usage: &commonTypes.Usage{Total: &total}},
+			},
+			"meta": map[string]interface{}{"page": 1, "limit": limit, "totalItems": 2, "totalPages": 1},
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFixture(t *testing.T, traces int) (*fixtureServer, *api.APIClient) {
+	t.Helper()
+	fixture := &fixtureServer{}
+	fixture.addTraces(traces)
+	server := httptest.NewServer(fixture)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.RetryCount = 0
+	cfg.SkipInitialHealthCheck = true
+	apiClient, err := api.NewAPIClient(cfg)
+	require.NoError(t, err)
+	return fixture, apiClient
+}
+
+// appendCSV runs an export appending to the CSV file at path, as a scheduled job would
+func appendCSV(ctx context.Context, t *testing.T, apiClient *api.APIClient, path string, filter Filter) (*Result, error) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	defer file.Close()
+	return Traces(ctx, apiClient, filter, NewCSVWriter(file))
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func traceIDs(records [][]string) []string {
+	ids := make([]string, 0, len(records))
+	for _, record := range records[1:] {
+		ids = append(ids, record[0])
+	}
+	return ids
+}
+
+func TestTraces_CSV(t *testing.T) {
+	_, apiClient := newFixture(t, 3)
+	var buf bytes.Buffer
+
+	result, err := Traces(context.Background(), apiClient, Filter{}, NewCSVWriter(&buf))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Rows)
+	assert.False(t, result.Resumed)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trace_id", "name", "user_id", "session_id", "timestamp",
+		"latency_seconds", "total_tokens", "total_cost", "tags"}, records[0])
+	assert.Equal(t, []string{"trace-02", "checkout", "user-2", "", "2024-05-01T09:00:01Z",
+		"0.2", "15", "0.002", "prod,eu"}, records[3])
+}
+
+func TestTraces_ResumeAfterCancel(t *testing.T) {
+	fixture, apiClient := newFixture(t, 25)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.csv")
+	filter := Filter{PageSize: 10, Checkpoint: filepath.Join(dir, "checkpoint.json")}
+
+	// Cancel in the middle of the second page
+	ctx, cancel := context.WithCancel(context.Background())
+	fixture.onGenerations = func(count int) {
+		if count == 14 {
+			cancel()
+		}
+	}
+	result, err := appendCSV(ctx, t, apiClient, path, filter)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(10), result.Rows)
+	assert.Len(t, readCSV(t, path), 11, "only the first page is written")
+
+	fixture.onGenerations = nil
+	result, err = appendCSV(context.Background(), t, apiClient, path, filter)
+	require.NoError(t, err)
+	assert.True(t, result.Resumed)
+	assert.Equal(t, int64(15), result.Rows)
+
+	expected := make([]string, 25)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("trace-%02d", i)
+	}
+	records := readCSV(t, path)
+	assert.Equal(t, "trace_id", records[0][0], "the header is written once")
+	assert.Equal(t, expected, traceIDs(records), "no duplicate or missing rows")
+
+	// An incremental run only appends traces added since
+	fixture.addTraces(3)
+	result, err = appendCSV(context.Background(), t, apiClient, path, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Rows)
+	assert.Equal(t, append(expected, "trace-25", "trace-26", "trace-27"), traceIDs(readCSV(t, path)))
+	assert.Equal(t, int64(28), result.Checkpoint.Rows)
+}
+
+func TestTraces_Since(t *testing.T) {
+	_, apiClient := newFixture(t, 10)
+	var buf bytes.Buffer
+
+	result, err := Traces(context.Background(), apiClient, Filter{Since: baseTime.Add(3 * time.Second)}, NewCSVWriter(&buf))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.Rows)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trace-06", "trace-07", "trace-08", "trace-09"}, traceIDs(records))
+}
+
+func TestTraces_ResumeKeepsCheckpointColumns(t *testing.T) {
+	_, apiClient := newFixture(t, 4)
+	dir := t.TempDir()
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+
+	// A checkpoint written by a version with fewer columns and one since removed
+	saved := &Checkpoint{
+		Columns: []Column{
+			{Name: "trace_id", Type: ColumnString},
+			{Name: "legacy_score", Type: ColumnFloat},
+			{Name: "user_id", Type: ColumnString},
+		},
+		LastTimestamp: baseTime,
+		LastIDs:       []string{"trace-00", "trace-01"},
+		Rows:          2,
+	}
+	require.NoError(t, saved.save(checkpointPath))
+
+	var buf bytes.Buffer
+	result, err := Traces(context.Background(), apiClient, Filter{Checkpoint: checkpointPath}, NewCSVWriter(&buf))
+	require.NoError(t, err)
+	assert.True(t, result.Resumed)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"trace-02", "", "user-2"}, {"trace-03", "", "user-0"}}, records)
+
+	loaded, err := LoadCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	assert.Equal(t, saved.Columns, loaded.Columns)
+	assert.Equal(t, int64(4), loaded.Rows)
+	assert.Equal(t, []string{"trace-02", "trace-03"}, loaded.LastIDs)
+}
+
+func TestLoadCheckpoint_Missing(t *testing.T) {
+	checkpoint, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
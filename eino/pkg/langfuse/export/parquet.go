@@ -0,0 +1,128 @@
+//go:build parquet
+
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetWriter writes an export as Parquet, every column optional. Parquet files cannot
+// be appended to, so each run, resumed or not, needs a file of its own (one part file
+// per run), and a file is only readable once Close has written its footer.
+//
+// It is built with the parquet build tag, keeping the dependency out of default builds:
+//
+//	go get github.com/parquet-go/parquet-go
+//	go build -tags parquet ./...
+type ParquetWriter struct {
+	out    io.Writer
+	writer *parquet.Writer
+
+	// leaves holds the leaf column index of each export column; parquet orders the
+	// columns of a group by name
+	leaves []int
+}
+
+// NewParquetWriter creates a Parquet writer writing to w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{out: w}
+}
+
+// WriteHeader creates the schema of the file from columns
+func (p *ParquetWriter) WriteHeader(columns []Column, resumed bool) error {
+	group := parquet.Group{}
+	for _, column := range columns {
+		node, err := parquetNode(column.Type)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		group[column.Name] = parquet.Optional(node)
+	}
+	schema := parquet.NewSchema("trace", group)
+
+	p.leaves = make([]int, len(columns))
+	for i, column := range columns {
+		leaf, ok := schema.Lookup(column.Name)
+		if !ok {
+			return fmt.Errorf("column %s is missing from the parquet schema", column.Name)
+		}
+		p.leaves[i] = leaf.ColumnIndex
+	}
+	p.writer = parquet.NewWriter(p.out, schema)
+	return nil
+}
+
+// WriteRow writes one row; nil values are written as nulls
+func (p *ParquetWriter) WriteRow(values []interface{}) error {
+	if p.writer == nil {
+		return fmt.Errorf("parquet writer has no schema, WriteHeader was not called")
+	}
+
+	row := make(parquet.Row, len(values))
+	for i, value := range values {
+		column := p.leaves[i]
+		parquetValue, err := toParquetValue(value)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			row[column] = parquetValue.Level(0, 0, column)
+		} else {
+			row[column] = parquetValue.Level(0, 1, column)
+		}
+	}
+	_, err := p.writer.WriteRows([]parquet.Row{row})
+	return err
+}
+
+// Flush writes the buffered rows as a row group
+func (p *ParquetWriter) Flush() error {
+	if p.writer == nil {
+		return nil
+	}
+	return p.writer.Flush()
+}
+
+// Close writes the footer of the file. It does not close the underlying writer.
+func (p *ParquetWriter) Close() error {
+	if p.writer == nil {
+		return nil
+	}
+	return p.writer.Close()
+}
+
+func parquetNode(columnType ColumnType) (parquet.Node, error) {
+	switch columnType {
+	case ColumnString:
+		return parquet.String(), nil
+	case ColumnInt:
+		return parquet.Int(64), nil
+	case ColumnFloat:
+		return parquet.Leaf(parquet.DoubleType), nil
+	case ColumnTime:
+		return parquet.Timestamp(parquet.Nanosecond), nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %q", columnType)
+	}
+}
+
+func toParquetValue(value interface{}) (parquet.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return parquet.NullValue(), nil
+	case string:
+		return parquet.ByteArrayValue([]byte(v)), nil
+	case int64:
+		return parquet.Int64Value(v), nil
+	case float64:
+		return parquet.DoubleValue(v), nil
+	case time.Time:
+		return parquet.Int64Value(v.UnixNano()), nil
+	default:
+		return parquet.Value{}, fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,508 @@
+// Package generations provides analysis helpers for generation observations, such as
+// comparing two generations while iterating on a prompt.
+package generations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"eino/pkg/langfuse/api"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+)
+
+const (
+	observationByIDPath = "/api/public/observations/%s"
+
+	// maxDiffBodyBytes is the size at which an input or output is truncated before diffing
+	maxDiffBodyBytes = 64 * 1024
+
+	// maxDiffLines bounds the number of lines compared by the line diff
+	maxDiffLines = 2000
+)
+
+// Observation is the observation shape compared by DiffLocal
+type Observation = commonTypes.Observation
+
+// BodyKind describes how an input or output was compared
+type BodyKind string
+
+const (
+	// BodyKindText bodies are compared line by line
+	BodyKindText BodyKind = "text"
+
+	// BodyKindJSON bodies are JSON objects compared key by key
+	BodyKindJSON BodyKind = "json"
+
+	// BodyKindBinary bodies are not compared; only a note is reported
+	BodyKindBinary BodyKind = "binary"
+)
+
+// LineOp is the operation of a line in a line diff
+type LineOp string
+
+// Line operations, rendered as the prefix of each line by BodyDiff.String
+const (
+	LineEqual   LineOp = " "
+	LineRemoved LineOp = "-"
+	LineAdded   LineOp = "+"
+)
+
+// KeyChangeType is the kind of change of a key in a key-level diff
+type KeyChangeType string
+
+// Key changes reported by a key-level diff
+const (
+	KeyAdded   KeyChangeType = "added"
+	KeyRemoved KeyChangeType = "removed"
+	KeyChanged KeyChangeType = "changed"
+)
+
+// DiffResult describes the differences between generation A and generation B. Fields
+// are nil or empty when both generations agree, so identical generations produce an
+// empty result (see IsEmpty). Deltas are B minus A.
+type DiffResult struct {
+	ObservationIDA string `json:"observationIdA"`
+	ObservationIDB string `json:"observationIdB"`
+
+	// Model is set when the model names differ
+	Model *ValueChange `json:"model,omitempty"`
+
+	// Parameters lists the model parameters that differ, sorted by name
+	Parameters []ParameterChange `json:"parameters,omitempty"`
+
+	// Usage is set when token counts or costs differ
+	Usage *UsageDelta `json:"usage,omitempty"`
+
+	// Latency is set when the latencies differ
+	Latency *LatencyDelta `json:"latency,omitempty"`
+
+	// Input and Output are set when the bodies differ
+	Input  *BodyDiff `json:"input,omitempty"`
+	Output *BodyDiff `json:"output,omitempty"`
+}
+
+// IsEmpty reports whether no differences were found
+func (r *DiffResult) IsEmpty() bool {
+	return r.Model == nil && len(r.Parameters) == 0 && r.Usage == nil && r.Latency == nil &&
+		r.Input == nil && r.Output == nil
+}
+
+// ValueChange is a value that differs between A and B
+type ValueChange struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// ParameterChange is a model parameter that differs between A and B. A or B is nil when
+// the parameter is only set on the other generation.
+type ParameterChange struct {
+	Name string      `json:"name"`
+	A    interface{} `json:"a"`
+	B    interface{} `json:"b"`
+}
+
+// UsageDelta is the difference in token usage and cost. Missing counts are treated as 0;
+// cost deltas are only set when both generations report the cost.
+type UsageDelta struct {
+	Input      int      `json:"input"`
+	Output     int      `json:"output"`
+	Total      int      `json:"total"`
+	InputCost  *float64 `json:"inputCost,omitempty"`
+	OutputCost *float64 `json:"outputCost,omitempty"`
+	TotalCost  *float64 `json:"totalCost,omitempty"`
+}
+
+// LatencyDelta is the difference in latency, from start to end of each generation.
+// A or B is nil when that generation has not ended; Delta is then nil as well.
+type LatencyDelta struct {
+	A     *time.Duration `json:"a,omitempty"`
+	B     *time.Duration `json:"b,omitempty"`
+	Delta *time.Duration `json:"delta,omitempty"`
+}
+
+// BodyDiff is the difference between two inputs or two outputs
+type BodyDiff struct {
+	Kind BodyKind `json:"kind"`
+
+	// Lines is the line diff of text bodies, including unchanged lines
+	Lines []LineDiff `json:"lines,omitempty"`
+
+	// Keys lists the top-level keys that differ between JSON object bodies, sorted by key
+	Keys []KeyChange `json:"keys,omitempty"`
+
+	// Truncated is set when a body was too large to diff completely
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Note explains truncation or why the bodies were not compared
+	Note string `json:"note,omitempty"`
+}
+
+// LineDiff is a line of a line diff
+type LineDiff struct {
+	Op   LineOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// KeyChange is a top-level key that differs between two JSON object bodies
+type KeyChange struct {
+	Key    string        `json:"key"`
+	Change KeyChangeType `json:"change"`
+	A      interface{}   `json:"a,omitempty"`
+	B      interface{}   `json:"b,omitempty"`
+}
+
+// String renders the line diff in unified style, one line per entry
+func (d *BodyDiff) String() string {
+	var b strings.Builder
+	for _, line := range d.Lines {
+		b.WriteString(string(line.Op))
+		b.WriteString(line.Text)
+		b.WriteByte('\n')
+	}
+	for _, key := range d.Keys {
+		fmt.Fprintf(&b, "%s %s: %v -> %v\n", key.Change, key.Key, key.A, key.B)
+	}
+	if d.Note != "" {
+		fmt.Fprintf(&b, "(%s)\n", d.Note)
+	}
+	return b.String()
+}
+
+// Diff fetches two observations and compares them with DiffLocal.
+//
+// Example:
+//
+//	result, err := generations.Diff(ctx, apiClient, baselineID, candidateID)
+//	if err != nil {
+//		return err
+//	}
+//	if result.Output != nil {
+//		fmt.Print(result.Output)
+//	}
+func Diff(ctx context.Context, apiClient *api.APIClient, observationIDA, observationIDB string) (*DiffResult, error) {
+	if apiClient == nil {
+		return nil, fmt.Errorf("api client cannot be nil")
+	}
+
+	a, err := getObservation(ctx, apiClient, observationIDA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := getObservation(ctx, apiClient, observationIDB)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffLocal(a, b)
+}
+
+// getObservation fetches a single observation by ID
+func getObservation(ctx context.Context, apiClient *api.APIClient, observationID string) (*Observation, error) {
+	if observationID == "" {
+		return nil, fmt.Errorf("observation ID cannot be empty")
+	}
+
+	observation := &Observation{}
+	path := fmt.Sprintf(observationByIDPath, url.PathEscape(observationID))
+	if err := apiClient.RoundTrip(ctx, http.MethodGet, path, nil, observation); err != nil {
+		return nil, fmt.Errorf("failed to get observation %s: %w", observationID, err)
+	}
+	return observation, nil
+}
+
+// DiffLocal compares two already-fetched observations: model and model parameters,
+// token usage, latency, and the input and output bodies. String bodies get a line diff,
+// JSON object bodies a key-level diff, and other JSON values a line diff of their
+// indented form. Bodies over 64 KiB are truncated and binary bodies are not compared;
+// both cases are explained by BodyDiff.Note.
+func DiffLocal(a, b *Observation) (*DiffResult, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("observations cannot be nil")
+	}
+
+	result := &DiffResult{
+		ObservationIDA: a.ID,
+		ObservationIDB: b.ID,
+		Parameters:     diffParameters(a.ModelParameters, b.ModelParameters),
+		Usage:          diffUsage(a.Usage, b.Usage),
+		Latency:        diffLatency(a, b),
+		Input:          diffBodies(a.Input, b.Input),
+		Output:         diffBodies(a.Output, b.Output),
+	}
+	if modelA, modelB := derefString(a.Model), derefString(b.Model); modelA != modelB {
+		result.Model = &ValueChange{A: modelA, B: modelB}
+	}
+
+	return result, nil
+}
+
+// diffParameters lists the parameters whose values differ
+func diffParameters(a, b map[string]interface{}) []ParameterChange {
+	names := make(map[string]struct{}, len(a)+len(b))
+	for name := range a {
+		names[name] = struct{}{}
+	}
+	for name := range b {
+		names[name] = struct{}{}
+	}
+
+	var changes []ParameterChange
+	for name := range names {
+		if !reflect.DeepEqual(a[name], b[name]) {
+			changes = append(changes, ParameterChange{Name: name, A: a[name], B: b[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// diffUsage returns the usage delta, or nil if usage is the same
+func diffUsage(a, b *commonTypes.Usage) *UsageDelta {
+	if a == nil {
+		a = &commonTypes.Usage{}
+	}
+	if b == nil {
+		b = &commonTypes.Usage{}
+	}
+
+	delta := UsageDelta{
+		Input:      derefInt(b.Input) - derefInt(a.Input),
+		Output:     derefInt(b.Output) - derefInt(a.Output),
+		Total:      derefInt(b.Total) - derefInt(a.Total),
+		InputCost:  costDelta(a.InputCost, b.InputCost),
+		OutputCost: costDelta(a.OutputCost, b.OutputCost),
+		TotalCost:  costDelta(a.TotalCost, b.TotalCost),
+	}
+	if delta == (UsageDelta{}) {
+		return nil
+	}
+	return &delta
+}
+
+// costDelta returns b - a when both costs are known and differ
+func costDelta(a, b *float64) *float64 {
+	if a == nil || b == nil || *a == *b {
+		return nil
+	}
+	delta := *b - *a
+	return &delta
+}
+
+// diffLatency returns the latency delta, or nil if the latencies are the same
+func diffLatency(a, b *Observation) *LatencyDelta {
+	latencyA, latencyB := latency(a), latency(b)
+	if latencyA == nil && latencyB == nil {
+		return nil
+	}
+	if latencyA != nil && latencyB != nil && *latencyA == *latencyB {
+		return nil
+	}
+
+	result := &LatencyDelta{A: latencyA, B: latencyB}
+	if latencyA != nil && latencyB != nil {
+		delta := *latencyB - *latencyA
+		result.Delta = &delta
+	}
+	return result
+}
+
+func latency(o *Observation) *time.Duration {
+	if o.EndTime == nil || o.StartTime.IsZero() {
+		return nil
+	}
+	d := o.EndTime.Sub(o.StartTime)
+	return &d
+}
+
+// diffBodies compares two raw JSON bodies, returning nil if they are equivalent
+func diffBodies(a, b json.RawMessage) *BodyDiff {
+	valueA, errA := decodeBody(a)
+	valueB, errB := decodeBody(b)
+	if errA != nil || errB != nil {
+		// Not valid JSON, compare the raw bytes as text
+		if bytes.Equal(a, b) {
+			return nil
+		}
+		return diffText(string(a), string(b))
+	}
+	if reflect.DeepEqual(valueA, valueB) {
+		return nil
+	}
+
+	objectA, isObjectA := valueA.(map[string]interface{})
+	objectB, isObjectB := valueB.(map[string]interface{})
+	if isObjectA && isObjectB {
+		return &BodyDiff{Kind: BodyKindJSON, Keys: diffKeys(objectA, objectB)}
+	}
+
+	return diffText(bodyText(valueA), bodyText(valueB))
+}
+
+// decodeBody decodes a JSON body; an empty body decodes to nil
+func decodeBody(raw json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+// bodyText returns the text compared by the line diff: strings as-is and other values
+// as indented JSON
+func bodyText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		indented, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(indented)
+	}
+}
+
+// diffKeys lists the top-level keys that differ between two JSON objects
+func diffKeys(a, b map[string]interface{}) []KeyChange {
+	var changes []KeyChange
+	for key, valueA := range a {
+		valueB, ok := b[key]
+		switch {
+		case !ok:
+			changes = append(changes, KeyChange{Key: key, Change: KeyRemoved, A: valueA})
+		case !reflect.DeepEqual(valueA, valueB):
+			changes = append(changes, KeyChange{Key: key, Change: KeyChanged, A: valueA, B: valueB})
+		}
+	}
+	for key, valueB := range b {
+		if _, ok := a[key]; !ok {
+			changes = append(changes, KeyChange{Key: key, Change: KeyAdded, B: valueB})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// diffText computes the line diff of two texts, truncating large texts and skipping
+// binary content
+func diffText(a, b string) *BodyDiff {
+	if isBinary(a) || isBinary(b) {
+		return &BodyDiff{
+			Kind: BodyKindBinary,
+			Note: fmt.Sprintf("binary content not compared (%d bytes vs %d bytes)", len(a), len(b)),
+		}
+	}
+
+	diff := &BodyDiff{Kind: BodyKindText}
+	var truncated []string
+	if len(a) > maxDiffBodyBytes || len(b) > maxDiffBodyBytes {
+		truncated = append(truncated, fmt.Sprintf("bodies truncated to %d bytes (%d bytes vs %d bytes)", maxDiffBodyBytes, len(a), len(b)))
+		a, b = truncateText(a, maxDiffBodyBytes), truncateText(b, maxDiffBodyBytes)
+	}
+
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(linesA) > maxDiffLines || len(linesB) > maxDiffLines {
+		truncated = append(truncated, fmt.Sprintf("only the first %d lines compared (%d lines vs %d lines)", maxDiffLines, len(linesA), len(linesB)))
+		linesA, linesB = truncateLines(linesA), truncateLines(linesB)
+	}
+
+	if len(truncated) > 0 {
+		diff.Truncated = true
+		diff.Note = strings.Join(truncated, "; ")
+	}
+	diff.Lines = diffLines(linesA, linesB)
+	return diff
+}
+
+// isBinary reports whether text looks like binary data rather than text
+func isBinary(text string) bool {
+	return !utf8.ValidString(text) || strings.ContainsRune(text, 0)
+}
+
+// truncateText cuts text to at most limit bytes without splitting a UTF-8 sequence
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
+func truncateLines(lines []string) []string {
+	if len(lines) > maxDiffLines {
+		return lines[:maxDiffLines]
+	}
+	return lines
+}
+
+// diffLines computes a line diff from the longest common subsequence of the two texts
+func diffLines(a, b []string) []LineDiff {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]LineDiff, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, LineDiff{Op: LineEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, LineDiff{Op: LineRemoved, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, LineDiff{Op: LineAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, LineDiff{Op: LineRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, LineDiff{Op: LineAdded, Text: b[j]})
+	}
+	return lines
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,249 @@
+package generations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api"
+	commonTypes "eino/pkg/langfuse/api/resources/commons/types"
+	"eino/pkg/langfuse/config"
+)
+
+var baseTime = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func newGeneration(id string, mutate func(o *Observation)) *Observation {
+	model := "gpt-4o"
+	input, output := 100, 20
+	end := baseTime.Add(800 * time.Millisecond)
+	o := &Observation{
+		ID:              id,
+		TraceID:         "trace-1",
+		Type:            commonTypes.ObservationTypeGeneration,
+		StartTime:       baseTime,
+		EndTime:         &end,
+		Model:           &model,
+		ModelParameters: map[string]interface{}{"temperature": 0.7, "max_tokens": float64(256)},
+		Input:           json.RawMessage(`"Summarize the following text.\nKeep it short."`),
+		Output:          json.RawMessage(`"A short summary."`),
+		Usage:           &commonTypes.Usage{Input: &input, Output: &output},
+	}
+	if mutate != nil {
+		mutate(o)
+	}
+	return o
+}
+
+func TestDiffLocal_Identical(t *testing.T) {
+	result, err := DiffLocal(newGeneration("a", nil), newGeneration("b", nil))
+	require.NoError(t, err)
+
+	assert.True(t, result.IsEmpty())
+	assert.Equal(t, "a", result.ObservationIDA)
+	assert.Equal(t, "b", result.ObservationIDB)
+	assert.Nil(t, result.Input)
+	assert.Nil(t, result.Output)
+}
+
+func TestDiffLocal_StringBodies(t *testing.T) {
+	b := newGeneration("b", func(o *Observation) {
+		o.Input = json.RawMessage(`"Summarize the following text.\nKeep it under 20 words."`)
+	})
+
+	result, err := DiffLocal(newGeneration("a", nil), b)
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Input)
+	assert.Equal(t, BodyKindText, result.Input.Kind)
+	assert.Equal(t, []LineDiff{
+		{Op: LineEqual, Text: "Summarize the following text."},
+		{Op: LineRemoved, Text: "Keep it short."},
+		{Op: LineAdded, Text: "Keep it under 20 words."},
+	}, result.Input.Lines)
+	assert.Equal(t, " Summarize the following text.\n-Keep it short.\n+Keep it under 20 words.\n", result.Input.String())
+	assert.Nil(t, result.Output)
+	assert.False(t, result.IsEmpty())
+}
+
+func TestDiffLocal_JSONBodies(t *testing.T) {
+	a := newGeneration("a", func(o *Observation) {
+		o.Input = json.RawMessage(`{"system": "You are terse.", "user": "Hi", "tools": ["search"]}`)
+	})
+	b := newGeneration("b", func(o *Observation) {
+		o.Input = json.RawMessage(`{"system": "You are helpful.", "user": "Hi", "examples": 3}`)
+	})
+
+	result, err := DiffLocal(a, b)
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Input)
+	assert.Equal(t, BodyKindJSON, result.Input.Kind)
+	assert.Empty(t, result.Input.Lines)
+	assert.Equal(t, []KeyChange{
+		{Key: "examples", Change: KeyAdded, B: float64(3)},
+		{Key: "system", Change: KeyChanged, A: "You are terse.", B: "You are helpful."},
+		{Key: "tools", Change: KeyRemoved, A: []interface{}{"search"}},
+	}, result.Input.Keys)
+
+	t.Run("formatting differences are ignored", func(t *testing.T) {
+		b := newGeneration("b", func(o *Observation) {
+			o.Input = json.RawMessage(`{ "tools":["search"],"user":"Hi","system":"You are terse." }`)
+		})
+		result, err := DiffLocal(a, b)
+		require.NoError(t, err)
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("string compared with object", func(t *testing.T) {
+		result, err := DiffLocal(newGeneration("a", nil), b)
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Input)
+		assert.Equal(t, BodyKindText, result.Input.Kind)
+		assert.Contains(t, result.Input.Lines, LineDiff{Op: LineAdded, Text: `  "system": "You are helpful.",`})
+	})
+}
+
+func TestDiffLocal_ModelAndParameters(t *testing.T) {
+	b := newGeneration("b", func(o *Observation) {
+		model := "gpt-4o-mini"
+		o.Model = &model
+		o.ModelParameters = map[string]interface{}{"temperature": 0.2, "max_tokens": float64(256), "top_p": 0.9}
+	})
+
+	result, err := DiffLocal(newGeneration("a", nil), b)
+	require.NoError(t, err)
+
+	assert.Equal(t, &ValueChange{A: "gpt-4o", B: "gpt-4o-mini"}, result.Model)
+	assert.Equal(t, []ParameterChange{
+		{Name: "temperature", A: 0.7, B: 0.2},
+		{Name: "top_p", A: nil, B: 0.9},
+	}, result.Parameters)
+}
+
+func TestDiffLocal_UsageAndLatency(t *testing.T) {
+	b := newGeneration("b", func(o *Observation) {
+		input, output := 140, 15
+		o.Usage = &commonTypes.Usage{Input: &input, Output: &output}
+		end := baseTime.Add(500 * time.Millisecond)
+		o.EndTime = &end
+	})
+
+	result, err := DiffLocal(newGeneration("a", nil), b)
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, 40, result.Usage.Input)
+	assert.Equal(t, -5, result.Usage.Output)
+	assert.Nil(t, result.Usage.TotalCost)
+
+	require.NotNil(t, result.Latency)
+	assert.Equal(t, 800*time.Millisecond, *result.Latency.A)
+	assert.Equal(t, 500*time.Millisecond, *result.Latency.B)
+	assert.Equal(t, -300*time.Millisecond, *result.Latency.Delta)
+
+	t.Run("unfinished generation", func(t *testing.T) {
+		b := newGeneration("b", func(o *Observation) { o.EndTime = nil })
+		result, err := DiffLocal(newGeneration("a", nil), b)
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Latency)
+		assert.Nil(t, result.Latency.B)
+		assert.Nil(t, result.Latency.Delta)
+	})
+}
+
+func TestDiffLocal_LargeAndBinaryBodies(t *testing.T) {
+	t.Run("overlong bodies are truncated", func(t *testing.T) {
+		long := strings.Repeat("x", maxDiffBodyBytes+100)
+		a := newGeneration("a", func(o *Observation) { o.Output = mustJSON(t, long+"a") })
+		b := newGeneration("b", func(o *Observation) { o.Output = mustJSON(t, long+"b") })
+
+		result, err := DiffLocal(a, b)
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Output)
+		assert.True(t, result.Output.Truncated)
+		assert.Contains(t, result.Output.Note, "truncated")
+		for _, line := range result.Output.Lines {
+			assert.LessOrEqual(t, len(line.Text), maxDiffBodyBytes)
+		}
+	})
+
+	t.Run("binary bodies are not compared", func(t *testing.T) {
+		a := newGeneration("a", func(o *Observation) { o.Output = mustJSON(t, "\x00\x01PNG") })
+
+		result, err := DiffLocal(a, newGeneration("b", nil))
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Output)
+		assert.Equal(t, BodyKindBinary, result.Output.Kind)
+		assert.Empty(t, result.Output.Lines)
+		assert.Contains(t, result.Output.Note, "binary content not compared")
+	})
+}
+
+func TestDiffLocal_NilObservations(t *testing.T) {
+	_, err := DiffLocal(nil, newGeneration("b", nil))
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	observations := map[string]*Observation{
+		"/api/public/observations/gen-a": newGeneration("gen-a", nil),
+		"/api/public/observations/gen-b": newGeneration("gen-b", func(o *Observation) {
+			o.Output = json.RawMessage(`"A shorter summary."`)
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		observation, ok := observations[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(observation)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Host = server.URL
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.RetryCount = 0
+	cfg.SkipInitialHealthCheck = true
+	apiClient, err := api.NewAPIClient(cfg)
+	require.NoError(t, err)
+
+	result, err := Diff(context.Background(), apiClient, "gen-a", "gen-b")
+	require.NoError(t, err)
+	assert.Equal(t, "gen-a", result.ObservationIDA)
+	require.NotNil(t, result.Output)
+	assert.Equal(t, []LineDiff{
+		{Op: LineRemoved, Text: "A short summary."},
+		{Op: LineAdded, Text: "A shorter summary."},
+	}, result.Output.Lines)
+	assert.Nil(t, result.Input)
+
+	_, err = Diff(context.Background(), apiClient, "gen-a", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get observation missing")
+
+	_, err = Diff(context.Background(), apiClient, "", "gen-b")
+	assert.Error(t, err)
+}
+
+func mustJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	return raw
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// rejectingClient fails every batch
+type rejectingClient struct{}
+
+func (rejectingClient) SubmitBatch(ctx context.Context, events []types.IngestionEvent) (*types.IngestionResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+// receiveAck waits for the outcome sent on ack
+func receiveAck(t *testing.T, ack <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ack:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("no acknowledgment received")
+		return nil
+	}
+}
+
+func TestIngestionQueue_EnqueueWithAck(t *testing.T) {
+	t.Run("submitted", func(t *testing.T) {
+		client := &batchRecorder{}
+		q := newWorkerQueue(client, 1000, 1)
+		defer q.Shutdown(context.Background())
+
+		ack, err := q.EnqueueWithAck(traceEvent("trace-1"))
+		require.NoError(t, err)
+		require.NoError(t, q.Flush())
+
+		assert.NoError(t, receiveAck(t, ack))
+		client.mu.Lock()
+		assert.Len(t, client.batches, 1, "the acknowledgment follows the submission")
+		client.mu.Unlock()
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		q := newWorkerQueue(rejectingClient{}, 1000, 1)
+		defer q.Shutdown(context.Background())
+
+		ack, err := q.EnqueueWithAck(traceEvent("trace-1"))
+		require.NoError(t, err)
+		require.NoError(t, q.Flush())
+
+		assert.ErrorContains(t, receiveAck(t, ack), "connection refused")
+	})
+
+	t.Run("coalesced", func(t *testing.T) {
+		q := newCoalescingQueue(&batchRecorder{})
+
+		first, err := q.EnqueueWithAck(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "first"}))
+		require.NoError(t, err)
+		second, err := q.EnqueueWithAck(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "second"}))
+		require.NoError(t, err)
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		assert.NoError(t, receiveAck(t, first), "merged updates are acknowledged with the event replacing them")
+		assert.NoError(t, receiveAck(t, second))
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		q := NewIngestionQueue(&batchRecorder{}, &QueueConfig{
+			FlushAt:       1000,
+			FlushInterval: time.Hour,
+			MaxQueueSize:  1,
+		})
+		defer q.Shutdown(context.Background())
+
+		ack, err := q.EnqueueWithAck(traceEvent("trace-1"))
+		require.NoError(t, err)
+		require.NoError(t, q.Enqueue(traceEvent("trace-2")))
+
+		assert.ErrorIs(t, receiveAck(t, ack), ErrQueueFull)
+	})
+}
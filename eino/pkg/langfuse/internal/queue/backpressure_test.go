@@ -0,0 +1,176 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// gatedClient holds every batch until gate is closed
+type gatedClient struct {
+	batchRecorder
+	gate       chan struct{}
+	submitting chan struct{}
+}
+
+func (c *gatedClient) SubmitBatch(ctx context.Context, events []types.IngestionEvent) (*types.IngestionResponse, error) {
+	c.submitting <- struct{}{}
+	<-c.gate
+	return c.batchRecorder.SubmitBatch(ctx, events)
+}
+
+func (c *gatedClient) eventIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids []string
+	for _, batch := range c.batches {
+		for _, event := range batch {
+			ids = append(ids, event.ID)
+		}
+	}
+	return ids
+}
+
+// newStalledQueue returns a full queue of capacity 2 whose single worker and flush loop
+// are both blocked until the client's gate is closed, so nothing makes room
+func newStalledQueue(t *testing.T, config *QueueConfig) (*IngestionQueue, *gatedClient) {
+	client := &gatedClient{gate: make(chan struct{}), submitting: make(chan struct{}, 10)}
+	config.FlushAt = 1000
+	config.FlushInterval = time.Hour
+	config.MaxQueueSize = 2
+	config.WorkerCount = 1
+	q := NewIngestionQueue(client, config)
+
+	// The worker blocks on the first batch...
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	require.NoError(t, q.Flush())
+	<-client.submitting
+
+	// ...and the flush loop on handing it the second
+	require.NoError(t, q.Enqueue(traceEvent("trace-2")))
+	require.NoError(t, q.Enqueue(traceEvent("trace-3")))
+	require.NoError(t, q.Flush())
+
+	require.NoError(t, q.Enqueue(traceEvent("trace-4")))
+	require.NoError(t, q.Enqueue(traceEvent("trace-5")))
+	require.Equal(t, 2, q.Size())
+	return q, client
+}
+
+// enqueueAsync runs enqueue in a goroutine and returns a channel receiving its result
+func enqueueAsync(enqueue func() error) <-chan error {
+	result := make(chan error, 1)
+	go func() { result <- enqueue() }()
+	return result
+}
+
+func TestIngestionQueue_BackpressureHandling(t *testing.T) {
+	t.Run("waits for space", func(t *testing.T) {
+		q, client := newStalledQueue(t, &QueueConfig{})
+
+		result := enqueueAsync(func() error {
+			return q.EnqueueWithBackpressure(context.Background(), traceEvent("trace-6"))
+		})
+		select {
+		case err := <-result:
+			t.Fatalf("enqueue returned while the queue was full: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		assert.Equal(t, 2, q.Size(), "no event was dropped to make room")
+
+		close(client.gate)
+		select {
+		case err := <-result:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("enqueue still waiting after the queue was flushed")
+		}
+
+		require.NoError(t, q.Shutdown(context.Background()))
+		assert.ElementsMatch(t, []string{"trace-1", "trace-2", "trace-3", "trace-4", "trace-5", "trace-6"}, client.eventIDs())
+		assert.Zero(t, q.Stats().EventsDropped)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		q, client := newStalledQueue(t, &QueueConfig{})
+		defer q.Shutdown(context.Background())
+		defer close(client.gate)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := q.EnqueueWithBackpressure(ctx, traceEvent("trace-6"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 2, q.Size(), "the event is not queued")
+
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, q.EnqueueWithBackpressure(canceled, traceEvent("trace-7")), context.Canceled)
+	})
+
+	t.Run("shutdown while waiting", func(t *testing.T) {
+		q, client := newStalledQueue(t, &QueueConfig{})
+		defer close(client.gate)
+
+		result := enqueueAsync(func() error {
+			return q.EnqueueWithBackpressure(context.Background(), traceEvent("trace-6"))
+		})
+		time.Sleep(20 * time.Millisecond)
+
+		// The flush loop is blocked, so Shutdown itself times out
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		q.Shutdown(ctx)
+
+		select {
+		case err := <-result:
+			assert.ErrorIs(t, err, ErrQueueClosed)
+		case <-time.After(5 * time.Second):
+			t.Fatal("enqueue still waiting after shutdown")
+		}
+	})
+
+	t.Run("block on full", func(t *testing.T) {
+		var dropped []string
+		q, client := newStalledQueue(t, (&QueueConfig{
+			RejectWhenFull: true,
+			OnEventDrop: func(event types.IngestionEvent, reason string) {
+				dropped = append(dropped, event.ID)
+			},
+		}).WithBlockOnFull(true))
+
+		results := []<-chan error{
+			enqueueAsync(func() error { return q.Enqueue(traceEvent("trace-6")) }),
+			enqueueAsync(func() error {
+				_, err := q.EnqueueWithAck(traceEvent("trace-7"))
+				return err
+			}),
+		}
+		time.Sleep(50 * time.Millisecond)
+		for _, result := range results {
+			select {
+			case err := <-result:
+				t.Fatalf("enqueue returned while the queue was full: %v", err)
+			default:
+			}
+		}
+
+		close(client.gate)
+		for _, result := range results {
+			select {
+			case err := <-result:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("enqueue still waiting after the queue was flushed")
+			}
+		}
+
+		require.NoError(t, q.Shutdown(context.Background()))
+		assert.Empty(t, dropped)
+		assert.Len(t, client.eventIDs(), 7)
+	})
+}
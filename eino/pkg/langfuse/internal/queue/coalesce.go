@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// coalescableTypes are the event types whose pending events can be merged per object ID
+var coalescableTypes = map[types.EventType]bool{
+	types.EventTypeTraceUpdate:       true,
+	types.EventTypeObservationUpdate: true,
+	types.EventTypeSpanUpdate:        true,
+	types.EventTypeGenerationUpdate:  true,
+}
+
+// coalesceKey identifies the object an update event applies to
+type coalesceKey struct {
+	eventType types.EventType
+	id        string
+}
+
+// coalesceEvents merges update events of the same type for the same object ID into a
+// single event. Fields from later events overwrite earlier ones, except metadata which
+// is deep-merged. The merged event takes the position and timestamp of the last update,
+// so it still follows the create event it applies to, but keeps the enqueue time of the
+// first update so latency is measured from when the object was first changed, and the
+// acknowledgment channels of every update it replaces. Events
+// whose body cannot be encoded are left untouched. It returns the resulting events and
+// how many were removed.
+func coalesceEvents(events []queuedEvent) ([]queuedEvent, int) {
+	// Find the last position of every object that has more than one pending update
+	last := make(map[coalesceKey]int)
+	counts := make(map[coalesceKey]int)
+	first := make(map[coalesceKey]time.Time)
+	acks := make(map[coalesceKey][]chan<- error)
+	for i, queued := range events {
+		key, ok := coalesceKeyFor(queued.event)
+		if !ok {
+			continue
+		}
+		if counts[key] == 0 {
+			first[key] = queued.enqueuedAt
+		}
+		last[key] = i
+		counts[key]++
+		acks[key] = append(acks[key], queued.acks...)
+	}
+
+	merged := make(map[coalesceKey]map[string]json.RawMessage)
+	failed := make(map[coalesceKey]bool)
+	for _, queued := range events {
+		key, ok := coalesceKeyFor(queued.event)
+		if !ok || counts[key] < 2 || failed[key] {
+			continue
+		}
+		fields, err := encodeBody(queued.event.Body)
+		if err != nil {
+			failed[key] = true
+			continue
+		}
+		merged[key] = mergeFields(merged[key], fields)
+	}
+
+	result := make([]queuedEvent, 0, len(events))
+	for i, queued := range events {
+		key, ok := coalesceKeyFor(queued.event)
+		if !ok || counts[key] < 2 || failed[key] {
+			result = append(result, queued)
+			continue
+		}
+		if i != last[key] {
+			continue
+		}
+		queued.event.Body = merged[key]
+		queued.enqueuedAt = first[key]
+		queued.acks = acks[key]
+		result = append(result, queued)
+	}
+
+	return result, len(events) - len(result)
+}
+
+// coalesceKeyFor returns the key of an update event, or false for events that are never merged
+func coalesceKeyFor(event types.IngestionEvent) (coalesceKey, bool) {
+	if !coalescableTypes[event.Type] || event.ID == "" {
+		return coalesceKey{}, false
+	}
+	return coalesceKey{eventType: event.Type, id: event.ID}, true
+}
+
+// mergeFields applies the fields of next on top of base
+func mergeFields(base, next map[string]json.RawMessage) map[string]json.RawMessage {
+	if base == nil {
+		return next
+	}
+	for name, value := range next {
+		if name == "metadata" {
+			if previous, ok := base[name]; ok {
+				value = mergeJSON(previous, value)
+			}
+		}
+		base[name] = value
+	}
+	return base
+}
+
+// mergeJSON deep-merges two JSON objects, with values from next taking precedence.
+// If either value is not an object, next replaces base.
+func mergeJSON(base, next json.RawMessage) json.RawMessage {
+	var baseFields, nextFields map[string]json.RawMessage
+	if json.Unmarshal(base, &baseFields) != nil || baseFields == nil {
+		return next
+	}
+	if json.Unmarshal(next, &nextFields) != nil || nextFields == nil {
+		return next
+	}
+
+	for name, value := range nextFields {
+		if previous, ok := baseFields[name]; ok {
+			value = mergeJSON(previous, value)
+		}
+		baseFields[name] = value
+	}
+
+	data, err := json.Marshal(baseFields)
+	if err != nil {
+		return next
+	}
+	return data
+}
+
+// encodeBody encodes an event body into its top-level JSON fields
+func encodeBody(body interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,147 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// batchRecorder records submitted batches, optionally blocking until released
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]types.IngestionEvent
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *batchRecorder) SubmitBatch(ctx context.Context, events []types.IngestionEvent) (*types.IngestionResponse, error) {
+	if r.started != nil {
+		r.started <- struct{}{}
+		<-r.release
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, events)
+	return &types.IngestionResponse{Success: true}, nil
+}
+
+func newCoalescingQueue(client IngestionClient) *IngestionQueue {
+	return NewIngestionQueue(client, &QueueConfig{
+		FlushAt:         1000,
+		FlushInterval:   time.Hour,
+		MaxQueueSize:    1000,
+		CoalesceUpdates: true,
+	})
+}
+
+func updateEvent(eventType types.EventType, id string, body map[string]interface{}) types.IngestionEvent {
+	body["id"] = id
+	return types.IngestionEvent{ID: id, Type: eventType, Timestamp: time.Now(), Body: body}
+}
+
+// decodeBody returns the JSON form of an event body
+func decodeBody(t *testing.T, event types.IngestionEvent) map[string]interface{} {
+	data, err := json.Marshal(event.Body)
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	return body
+}
+
+func TestIngestionQueue_CoalesceUpdates(t *testing.T) {
+	client := &batchRecorder{}
+	q := newCoalescingQueue(client)
+
+	require.NoError(t, q.Enqueue(types.IngestionEvent{ID: "obs-1", Type: types.EventTypeSpanCreate, Timestamp: time.Now(), Body: map[string]interface{}{"id": "obs-1", "name": "step"}}))
+	updates := []map[string]interface{}{
+		{"output": "first", "metadata": map[string]interface{}{"attempt": 1, "nested": map[string]interface{}{"a": 1}}},
+		{"level": "WARNING"},
+		{"output": "second", "metadata": map[string]interface{}{"nested": map[string]interface{}{"b": 2}}},
+		{"statusMessage": "retrying", "metadata": map[string]interface{}{"attempt": 2}},
+		{"output": "final", "endTime": "2024-01-01T12:00:00Z"},
+	}
+	for _, update := range updates {
+		require.NoError(t, q.Enqueue(updateEvent(types.EventTypeSpanUpdate, "obs-1", update)))
+	}
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeSpanUpdate, "obs-2", map[string]interface{}{"output": "other"})))
+
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	require.Len(t, client.batches, 1)
+	batch := client.batches[0]
+	require.Len(t, batch, 3)
+
+	// The create event still comes before the merged update
+	assert.Equal(t, types.EventTypeSpanCreate, batch[0].Type)
+
+	assert.Equal(t, types.EventTypeSpanUpdate, batch[1].Type)
+	assert.Equal(t, map[string]interface{}{
+		"id":            "obs-1",
+		"output":        "final",
+		"level":         "WARNING",
+		"statusMessage": "retrying",
+		"endTime":       "2024-01-01T12:00:00Z",
+		"metadata": map[string]interface{}{
+			"attempt": float64(2),
+			"nested":  map[string]interface{}{"a": float64(1), "b": float64(2)},
+		},
+	}, decodeBody(t, batch[1]))
+
+	assert.Equal(t, types.EventTypeSpanUpdate, batch[2].Type)
+	assert.Equal(t, map[string]interface{}{"id": "obs-2", "output": "other"}, decodeBody(t, batch[2]))
+
+	stats := q.Stats()
+	assert.Equal(t, int64(4), stats.EventsCoalesced)
+	assert.Equal(t, int64(3), stats.EventsProcessed)
+}
+
+func TestIngestionQueue_CoalesceUpdates_InFlightBatch(t *testing.T) {
+	client := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+	q := newCoalescingQueue(client)
+
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "first"})))
+	require.NoError(t, q.Flush())
+	<-client.started
+
+	// These arrive while the first batch is being submitted
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "second"})))
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"tags": []string{"late"}})))
+
+	done := make(chan error, 1)
+	go func() { done <- q.Shutdown(context.Background()) }()
+	client.release <- struct{}{}
+	<-client.started
+	client.release <- struct{}{}
+	require.NoError(t, <-done)
+
+	require.Len(t, client.batches, 2)
+	require.Len(t, client.batches[0], 1)
+	assert.Equal(t, map[string]interface{}{"id": "trace-1", "output": "first"}, decodeBody(t, client.batches[0][0]))
+
+	require.Len(t, client.batches[1], 1)
+	assert.Equal(t, map[string]interface{}{"id": "trace-1", "output": "second", "tags": []interface{}{"late"}}, decodeBody(t, client.batches[1][0]))
+	assert.Equal(t, int64(1), q.Stats().EventsCoalesced)
+}
+
+func TestIngestionQueue_CoalesceUpdatesDisabled(t *testing.T) {
+	client := &batchRecorder{}
+	q := NewIngestionQueue(client, &QueueConfig{FlushAt: 1000, FlushInterval: time.Hour, MaxQueueSize: 1000})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Enqueue(updateEvent(types.EventTypeGenerationUpdate, "gen-1", map[string]interface{}{"output": i})))
+	}
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	require.Len(t, client.batches, 1)
+	assert.Len(t, client.batches[0], 3)
+	assert.Zero(t, q.Stats().EventsCoalesced)
+}
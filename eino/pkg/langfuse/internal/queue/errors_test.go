@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func TestIngestionQueue_SentinelErrors(t *testing.T) {
+	newEvent := func(id string) types.IngestionEvent {
+		return updateEvent(types.EventTypeTraceCreate, id, map[string]interface{}{"name": id})
+	}
+
+	t.Run("full", func(t *testing.T) {
+		var dropped []string
+		q := NewIngestionQueue(&batchRecorder{}, &QueueConfig{
+			FlushAt:        1000,
+			FlushInterval:  time.Hour,
+			MaxQueueSize:   2,
+			RejectWhenFull: true,
+			OnEventDrop: func(event types.IngestionEvent, reason string) {
+				dropped = append(dropped, event.ID)
+			},
+		})
+		defer q.Shutdown(context.Background())
+
+		require.NoError(t, q.Enqueue(newEvent("trace-1")))
+		require.NoError(t, q.Enqueue(newEvent("trace-2")))
+
+		err := q.Enqueue(newEvent("trace-3"))
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.False(t, errors.Is(err, ErrQueueClosed))
+		assert.Equal(t, []string{"trace-3"}, dropped, "the rejected event is reported, queued ones are kept")
+		assert.Equal(t, 2, q.Size())
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		q := NewIngestionQueue(&batchRecorder{}, DefaultQueueConfig())
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		err := q.Enqueue(newEvent("trace-1"))
+		assert.ErrorIs(t, err, ErrQueueClosed)
+		assert.False(t, errors.Is(err, ErrQueueFull))
+	})
+}
+
+func TestIngestionQueue_OnSubmitError(t *testing.T) {
+	type attempt struct {
+		attempt, batchSize int
+		err                string
+	}
+	var mu sync.Mutex
+	var attempts []attempt
+	var flushEnd error
+
+	q := NewIngestionQueue(rejectingClient{}, &QueueConfig{
+		FlushAt:       1000,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+		MaxRetries:    2,
+		OnSubmitError: func(n, batchSize int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts = append(attempts, attempt{n, batchSize, err.Error()})
+		},
+		OnFlushEnd: func(batchSize int, success bool, err error, _ time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			flushEnd = err
+		},
+	})
+
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	require.NoError(t, q.Enqueue(traceEvent("trace-2")))
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []attempt{
+		{0, 2, "connection refused"},
+		{1, 2, "connection refused"},
+		{2, 2, "connection refused"},
+	}, attempts)
+	assert.EqualError(t, flushEnd, "connection refused", "attempts are reported before the batch is given up on")
+}
+
+// invalidBatchClient fails every batch client-side validation, counting the attempts
+type invalidBatchClient struct {
+	attempts atomic.Int32
+}
+
+func (c *invalidBatchClient) SubmitBatch(ctx context.Context, events []types.IngestionEvent) (*types.IngestionResponse, error) {
+	c.attempts.Add(1)
+	return nil, fmt.Errorf("request validation failed: %w", &types.RequestValidationError{Field: "batch[0]", Message: "event is invalid"})
+}
+
+func TestIngestionQueue_InvalidBatchIsNotRetried(t *testing.T) {
+	client := &invalidBatchClient{}
+	var mu sync.Mutex
+	var flushEnd error
+	q := NewIngestionQueue(client, &QueueConfig{
+		FlushAt:       1000,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+		MaxRetries:    3,
+		RetryBackoff:  time.Second,
+		OnFlushEnd: func(batchSize int, success bool, err error, _ time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			flushEnd = err
+		},
+	})
+
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	start := time.Now()
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	assert.Equal(t, int32(1), client.attempts.Load(), "an invalid batch is dead-lettered after one attempt")
+	assert.Less(t, time.Since(start), time.Second, "no retry backoff is waited")
+	assert.Equal(t, int64(1), q.Stats().BatchesFailed)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var validationErr *types.RequestValidationError
+	assert.ErrorAs(t, flushEnd, &validationErr)
+}
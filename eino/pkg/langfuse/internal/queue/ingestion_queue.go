@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"eino/pkg/langfuse/api/resources/ingestion/types"
@@ -12,7 +14,12 @@ import (
 
 // Common queue errors
 var (
+	// ErrQueueClosed is returned when enqueueing into a queue that has been shut down
 	ErrQueueClosed = errors.New("queue is closed")
+
+	// ErrQueueFull is returned when the queue is at capacity and configured to reject
+	// new events instead of dropping the oldest one
+	ErrQueueFull = errors.New("queue is full")
 )
 
 // IngestionClient interface defines the methods needed to submit ingestion requests
@@ -23,7 +30,7 @@ type IngestionClient interface {
 // IngestionQueue manages batching and async submission of ingestion events
 type IngestionQueue struct {
 	client        IngestionClient
-	buffer        []types.IngestionEvent
+	buffer        []queuedEvent
 	mu            sync.RWMutex
 	flushAt       int
 	flushInterval time.Duration
@@ -35,11 +42,32 @@ type IngestionQueue struct {
 	shutdownCh chan struct{}
 	wg         sync.WaitGroup
 
+	// Submission workers, fed batches by the flush loop
+	workCh      chan workItem
+	workerCount int
+	workerWg    sync.WaitGroup
+
 	// State management
 	closed bool
 
+	// paused holds flushes back, keeping events buffered, until Resume is called
+	paused bool
+
+	// now returns the current time, used to measure how long events wait
+	now func() time.Time
+
 	// Statistics
-	stats *QueueStats
+	stats *queueCounters
+
+	// maxQueueSize caps the buffered events
+	maxQueueSize int
+
+	// eventsBySource counts the events queued per source, guarded by mu as it is only
+	// updated while enqueueing
+	eventsBySource map[string]int64
+
+	// latencies holds recent enqueue-to-acknowledgment latencies, guarded by stats.mu
+	latencies *latencyWindow
 
 	// Configuration
 	maxRetries   int
@@ -47,17 +75,51 @@ type IngestionQueue struct {
 
 	// Event hooks
 	onFlushStart func(batchSize int)
-	onFlushEnd   func(batchSize int, success bool, err error)
+	onFlushEnd   func(batchSize int, success bool, err error, oldestEventAge time.Duration)
 	onEventDrop  func(event types.IngestionEvent, reason string)
+	onEnqueue    func(event types.IngestionEvent)
+	onSubmitErr  func(attempt, batchSize int, err error)
+	middleware   []EventMiddleware
+
+	// coalesceUpdates merges pending update events for the same object at flush time
+	coalesceUpdates bool
+
+	// rejectWhenFull returns ErrQueueFull instead of dropping the oldest event
+	rejectWhenFull bool
+
+	// blockOnFull makes Enqueue wait for space instead of dropping or rejecting events
+	blockOnFull bool
+
+	// spaceCh is closed and replaced, under mu, whenever the buffer is drained or the
+	// queue is closed, waking producers waiting for space
+	spaceCh chan struct{}
+}
+
+// queuedEvent is a buffered event with the time it was enqueued
+type queuedEvent struct {
+	event      types.IngestionEvent
+	enqueuedAt time.Time
+
+	// acks receive the outcome of the batch the event is submitted in
+	acks []chan<- error
+}
+
+// workItem is a batch handed to a submission worker; done is called once it has been submitted
+type workItem struct {
+	events []queuedEvent
+	done   func()
 }
 
+// EventMiddleware inspects or transforms an event before it is added to the queue
+type EventMiddleware func(event types.IngestionEvent) types.IngestionEvent
+
 // QueueStats tracks queue performance metrics
 type QueueStats struct {
-	mu               sync.RWMutex
 	EventsQueued     int64
 	EventsProcessed  int64
 	EventsFailed     int64
 	EventsDropped    int64
+	EventsCoalesced  int64
 	BatchesSubmitted int64
 	BatchesFailed    int64
 	TotalFlushTime   time.Duration
@@ -65,18 +127,83 @@ type QueueStats struct {
 	LastFlushTime    time.Time
 	QueueSize        int
 	MaxQueueSize     int
+	ActiveWorkers    int
+
+	// End-to-end latency from enqueue to acknowledgment by the ingestion API, over the
+	// most recently acknowledged events
+	EventLatencyP50 time.Duration
+	EventLatencyP95 time.Duration
+	EventLatencyMax time.Duration
+
+	// EventsBySource counts the events queued per IngestionEvent.Source; events without
+	// a source are not counted
+	EventsBySource map[string]int64
+}
+
+// queueCounters holds the statistics Stats reports. The counters are updated atomically
+// so enqueueing does not take a second lock; the flush timings and latencies, which are
+// updated together once per batch, are guarded by mu.
+type queueCounters struct {
+	eventsQueued     atomic.Int64
+	eventsProcessed  atomic.Int64
+	eventsFailed     atomic.Int64
+	eventsDropped    atomic.Int64
+	eventsCoalesced  atomic.Int64
+	batchesSubmitted atomic.Int64
+	batchesFailed    atomic.Int64
+	activeWorkers    atomic.Int64
+
+	mu               sync.RWMutex
+	totalFlushTime   time.Duration
+	averageFlushTime time.Duration
+	lastFlushTime    time.Time
+	eventLatencyP50  time.Duration
+	eventLatencyP95  time.Duration
+	eventLatencyMax  time.Duration
 }
 
 // QueueConfig holds configuration for the ingestion queue
 type QueueConfig struct {
 	FlushAt       int
 	FlushInterval time.Duration
-	MaxRetries    int
-	RetryBackoff  time.Duration
-	MaxQueueSize  int
-	OnFlushStart  func(batchSize int)
-	OnFlushEnd    func(batchSize int, success bool, err error)
-	OnEventDrop   func(event types.IngestionEvent, reason string)
+
+	// MaxRetries is the number of times a failed batch is resubmitted. Each attempt is a
+	// single call of the ingestion client, whatever retries the client does itself.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	MaxQueueSize int
+	OnFlushStart func(batchSize int)
+	OnFlushEnd   func(batchSize int, success bool, err error, oldestEventAge time.Duration)
+	OnEventDrop  func(event types.IngestionEvent, reason string)
+	OnEnqueue    func(event types.IngestionEvent)
+	Middleware   []EventMiddleware
+
+	// OnSubmitError is called after every failed submission attempt of a batch, numbered
+	// from 0, before the batch is retried or given up on. err is never nil.
+	OnSubmitError func(attempt, batchSize int, err error)
+
+	// CoalesceUpdates merges update events for the same trace or observation that are
+	// pending at flush time into a single event (last writer wins, metadata deep-merged)
+	CoalesceUpdates bool
+
+	// RejectWhenFull makes Enqueue return ErrQueueFull when MaxQueueSize events are
+	// buffered, instead of dropping the oldest event to make room
+	RejectWhenFull bool
+
+	// BlockOnFull makes Enqueue and EnqueueWithAck wait until MaxQueueSize leaves room
+	// for the event, like EnqueueWithBackpressure without a deadline. It takes precedence
+	// over RejectWhenFull.
+	BlockOnFull bool
+
+	// WorkerCount is the number of goroutines submitting batches concurrently (default 1).
+	// Batches may reach the ingestion API out of order when it is above 1.
+	WorkerCount int
+}
+
+// WithBlockOnFull sets BlockOnFull and returns the configuration for chaining
+func (c *QueueConfig) WithBlockOnFull(block bool) *QueueConfig {
+	c.BlockOnFull = block
+	return c
 }
 
 // DefaultQueueConfig returns a default queue configuration
@@ -87,6 +214,7 @@ func DefaultQueueConfig() *QueueConfig {
 		MaxRetries:    3,
 		RetryBackoff:  1 * time.Second,
 		MaxQueueSize:  1000,
+		WorkerCount:   1,
 	}
 }
 
@@ -96,9 +224,14 @@ func NewIngestionQueue(client IngestionClient, config *QueueConfig) *IngestionQu
 		config = DefaultQueueConfig()
 	}
 
+	workerCount := config.WorkerCount
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
 	queue := &IngestionQueue{
 		client:        client,
-		buffer:        make([]types.IngestionEvent, 0, config.FlushAt),
+		buffer:        make([]queuedEvent, 0, config.FlushAt),
 		flushAt:       config.FlushAt,
 		flushInterval: config.FlushInterval,
 		maxRetries:    config.MaxRetries,
@@ -106,44 +239,114 @@ func NewIngestionQueue(client IngestionClient, config *QueueConfig) *IngestionQu
 		stopCh:        make(chan struct{}),
 		flushCh:       make(chan struct{}, 1),
 		shutdownCh:    make(chan struct{}),
+		workCh:        make(chan workItem),
+		workerCount:   workerCount,
 		closed:        false,
-		stats:         &QueueStats{MaxQueueSize: config.MaxQueueSize},
+		now:           time.Now,
+		stats:         &queueCounters{},
+		maxQueueSize:  config.MaxQueueSize,
+		latencies:     newLatencyWindow(latencyWindowSize),
 		onFlushStart:  config.OnFlushStart,
 		onFlushEnd:    config.OnFlushEnd,
 		onEventDrop:   config.OnEventDrop,
+		onEnqueue:     config.OnEnqueue,
+		onSubmitErr:   config.OnSubmitError,
+		middleware:    config.Middleware,
+
+		coalesceUpdates: config.CoalesceUpdates,
+		rejectWhenFull:  config.RejectWhenFull,
+		blockOnFull:     config.BlockOnFull,
+		spaceCh:         make(chan struct{}),
 	}
 
-	// Start background worker
-	queue.startWorker()
+	// Start the flush loop and submission workers
+	queue.startWorkers()
 
 	return queue
 }
 
-// Enqueue adds an event to the queue for processing
+// Enqueue adds an event to the queue for processing. When the queue is full the oldest
+// event is dropped, or the event is rejected with ErrQueueFull with RejectWhenFull, or
+// Enqueue waits for space with BlockOnFull.
 func (q *IngestionQueue) Enqueue(event types.IngestionEvent) error {
+	return q.enqueue(context.Background(), event, nil, q.blockOnFull)
+}
+
+// EnqueueWithBackpressure adds an event to the queue, waiting while it is full until a
+// flush makes room or ctx is done, in which case ctx.Err() is returned and the event is
+// not queued. Unlike Enqueue it never drops events, so callers can slow down instead.
+func (q *IngestionQueue) EnqueueWithBackpressure(ctx context.Context, event types.IngestionEvent) error {
+	return q.enqueue(ctx, event, nil, true)
+}
+
+// EnqueueWithAck adds an event to the queue like Enqueue and returns a channel that
+// receives the outcome of its submission: nil once the ingestion API accepted the batch
+// containing it, or an error when the batch failed after all retries or the event was
+// dropped to make room.
+func (q *IngestionQueue) EnqueueWithAck(event types.IngestionEvent) (<-chan error, error) {
+	ack := make(chan error, 1)
+	if err := q.enqueue(context.Background(), event, ack, q.blockOnFull); err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// enqueue buffers event, first waiting for space until ctx is done if block is set
+func (q *IngestionQueue) enqueue(ctx context.Context, event types.IngestionEvent, ack chan<- error, block bool) error {
+	// Middleware runs outside the lock so slow callbacks don't block other producers
+	for _, mw := range q.middleware {
+		event = mw(event)
+	}
+
 	q.mu.Lock()
+	for block && !q.closed && q.maxQueueSize > 0 && len(q.buffer) >= q.maxQueueSize {
+		space := q.spaceCh
+		q.mu.Unlock()
+
+		// Make room now rather than at the next interval, in case FlushAt is above
+		// MaxQueueSize
+		select {
+		case q.flushCh <- struct{}{}:
+		default:
+			// Channel full, flush already triggered
+		}
+
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
 	defer q.mu.Unlock()
 
 	if q.closed {
-		return fmt.Errorf("queue is closed")
+		return ErrQueueClosed
 	}
 
 	// Validate the event before queueing
 	if err := event.Validate(); err != nil {
-		q.stats.mu.Lock()
-		q.stats.EventsFailed++
-		q.stats.mu.Unlock()
+		q.stats.eventsFailed.Add(1)
 		return fmt.Errorf("event validation failed: %w", err)
 	}
 
 	// Check queue size limits
-	if len(q.buffer) >= q.stats.MaxQueueSize {
+	if len(q.buffer) >= q.maxQueueSize {
+		if q.rejectWhenFull {
+			q.stats.eventsDropped.Add(1)
+
+			if q.onEventDrop != nil {
+				q.onEventDrop(event, "queue_full")
+			}
+			return ErrQueueFull
+		}
+
 		// Drop the oldest event to make room
-		droppedEvent := q.buffer[0]
+		dropped := q.buffer[0]
+		droppedEvent := dropped.event
 		q.buffer = q.buffer[1:]
-		q.stats.mu.Lock()
-		q.stats.EventsDropped++
-		q.stats.mu.Unlock()
+		notifyAcks(dropped.acks, ErrQueueFull)
+		q.stats.eventsDropped.Add(1)
 
 		if q.onEventDrop != nil {
 			q.onEventDrop(droppedEvent, "queue_full")
@@ -151,14 +354,22 @@ func (q *IngestionQueue) Enqueue(event types.IngestionEvent) error {
 	}
 
 	// Add event to buffer
-	q.buffer = append(q.buffer, event)
-	q.stats.mu.Lock()
-	q.stats.EventsQueued++
-	q.stats.QueueSize = len(q.buffer)
-	if q.stats.QueueSize > q.stats.MaxQueueSize {
-		q.stats.MaxQueueSize = q.stats.QueueSize
+	queued := queuedEvent{event: event, enqueuedAt: q.now()}
+	if ack != nil {
+		queued.acks = []chan<- error{ack}
+	}
+	q.buffer = append(q.buffer, queued)
+	q.stats.eventsQueued.Add(1)
+	if event.Source != "" {
+		if q.eventsBySource == nil {
+			q.eventsBySource = make(map[string]int64)
+		}
+		q.eventsBySource[event.Source]++
+	}
+
+	if q.onEnqueue != nil {
+		q.onEnqueue(event)
 	}
-	q.stats.mu.Unlock()
 
 	// Trigger flush if buffer is full
 	if len(q.buffer) >= q.flushAt {
@@ -172,6 +383,15 @@ func (q *IngestionQueue) Enqueue(event types.IngestionEvent) error {
 	return nil
 }
 
+// ApplyMiddleware runs the configured event middleware on event without queueing it, for
+// events that are submitted outside the queue
+func (q *IngestionQueue) ApplyMiddleware(event types.IngestionEvent) types.IngestionEvent {
+	for _, mw := range q.middleware {
+		event = mw(event)
+	}
+	return event
+}
+
 // Flush forces an immediate flush of all pending events
 func (q *IngestionQueue) Flush() error {
 	// Trigger flush and wait for completion
@@ -194,13 +414,47 @@ func (q *IngestionQueue) Size() int {
 	return len(q.buffer)
 }
 
-// Stats returns a copy of the current queue statistics
+// OldestEventAge returns how long the oldest buffered event has been waiting to be
+// flushed, or zero when the queue is empty
+func (q *IngestionQueue) OldestEventAge() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if len(q.buffer) == 0 {
+		return 0
+	}
+	return q.now().Sub(q.buffer[0].enqueuedAt)
+}
+
+// Stats returns a copy of the current queue statistics. The counters are read one at a
+// time while the queue keeps running, so they are not a consistent snapshot of a single
+// moment.
 func (q *IngestionQueue) Stats() QueueStats {
-	q.stats.mu.RLock()
-	defer q.stats.mu.RUnlock()
+	c := q.stats
+	stats := QueueStats{
+		EventsQueued:     c.eventsQueued.Load(),
+		EventsProcessed:  c.eventsProcessed.Load(),
+		EventsFailed:     c.eventsFailed.Load(),
+		EventsDropped:    c.eventsDropped.Load(),
+		EventsCoalesced:  c.eventsCoalesced.Load(),
+		BatchesSubmitted: c.batchesSubmitted.Load(),
+		BatchesFailed:    c.batchesFailed.Load(),
+		ActiveWorkers:    int(c.activeWorkers.Load()),
+	}
 
-	// Create a copy to avoid data races
-	stats := *q.stats
+	c.mu.RLock()
+	stats.TotalFlushTime = c.totalFlushTime
+	stats.AverageFlushTime = c.averageFlushTime
+	stats.LastFlushTime = c.lastFlushTime
+	stats.EventLatencyP50 = c.eventLatencyP50
+	stats.EventLatencyP95 = c.eventLatencyP95
+	stats.EventLatencyMax = c.eventLatencyMax
+	c.mu.RUnlock()
+
+	q.mu.RLock()
+	stats.QueueSize = len(q.buffer)
+	stats.MaxQueueSize = q.maxQueueSize
+	stats.EventsBySource = maps.Clone(q.eventsBySource)
+	q.mu.RUnlock()
 	return stats
 }
 
@@ -212,6 +466,7 @@ func (q *IngestionQueue) Shutdown(ctx context.Context) error {
 		return nil
 	}
 	q.closed = true
+	q.signalSpace()
 	q.mu.Unlock()
 
 	// Stop the ticker
@@ -237,15 +492,21 @@ func (q *IngestionQueue) Shutdown(ctx context.Context) error {
 	}
 }
 
-// startWorker starts the background worker goroutine
-func (q *IngestionQueue) startWorker() {
+// startWorkers starts the flush loop and the submission workers
+func (q *IngestionQueue) startWorkers() {
 	q.ticker = time.NewTicker(q.flushInterval)
-	q.wg.Add(1)
 
+	q.workerWg.Add(q.workerCount)
+	for i := 0; i < q.workerCount; i++ {
+		go q.submitWorker()
+	}
+
+	q.wg.Add(1)
 	go q.worker()
 }
 
-// worker is the main background processing loop
+// worker is the main background processing loop, handing flushed batches to the
+// submission workers
 func (q *IngestionQueue) worker() {
 	defer q.wg.Done()
 	defer q.ticker.Stop()
@@ -258,15 +519,39 @@ func (q *IngestionQueue) worker() {
 			q.forceFlush()
 		case <-q.shutdownCh:
 			q.finalFlush()
+			close(q.workCh)
+			q.workerWg.Wait()
 			return
 		}
 	}
 }
 
+// submitWorker submits batches from the work channel until it is closed
+func (q *IngestionQueue) submitWorker() {
+	defer q.workerWg.Done()
+
+	for item := range q.workCh {
+		q.stats.activeWorkers.Add(1)
+		q.submitBatch(item.events)
+		q.stats.activeWorkers.Add(-1)
+		item.done()
+	}
+}
+
+// batchesPerFlush is how many batches of a single flush may be submitted at once. With
+// several workers one is always left for the next flush, so a large flush cannot hold
+// up events flushed after it.
+func (q *IngestionQueue) batchesPerFlush() int {
+	if q.workerCount <= 1 {
+		return 1
+	}
+	return q.workerCount - 1
+}
+
 // periodicFlush performs a periodic flush if there are events in the buffer
 func (q *IngestionQueue) periodicFlush() {
 	q.mu.RLock()
-	hasEvents := len(q.buffer) > 0
+	hasEvents := len(q.buffer) > 0 && !q.paused
 	q.mu.RUnlock()
 
 	if hasEvents {
@@ -274,8 +559,11 @@ func (q *IngestionQueue) periodicFlush() {
 	}
 }
 
-// forceFlush performs an immediate flush
+// forceFlush performs an immediate flush unless the queue is paused
 func (q *IngestionQueue) forceFlush() {
+	if q.IsPaused() {
+		return
+	}
 	q.flushBuffer()
 }
 
@@ -284,7 +572,8 @@ func (q *IngestionQueue) finalFlush() {
 	q.flushBuffer()
 }
 
-// flushBuffer sends the current buffer to the ingestion client
+// flushBuffer takes the current buffer and hands it to the submission workers in
+// batches of at most flushAt events of the same project
 func (q *IngestionQueue) flushBuffer() {
 	q.mu.Lock()
 	if len(q.buffer) == 0 {
@@ -293,17 +582,53 @@ func (q *IngestionQueue) flushBuffer() {
 	}
 
 	// Take a copy of the buffer and clear it
-	events := make([]types.IngestionEvent, len(q.buffer))
+	events := make([]queuedEvent, len(q.buffer))
 	copy(events, q.buffer)
 	q.buffer = q.buffer[:0] // Clear buffer but keep capacity
-	batchSize := len(events)
+	q.signalSpace()
 	q.mu.Unlock()
 
-	// Update stats
-	q.stats.mu.Lock()
-	q.stats.QueueSize = 0
-	q.stats.BatchesSubmitted++
-	q.stats.mu.Unlock()
+	// Only the events taken from the buffer above are merged, never events of a batch
+	// that is already being submitted
+	coalesced := 0
+	if q.coalesceUpdates {
+		events, coalesced = coalesceEvents(events)
+	}
+
+	q.stats.eventsCoalesced.Add(int64(coalesced))
+
+	batchSize := q.flushAt
+	if batchSize <= 0 {
+		batchSize = len(events)
+	}
+	sem := make(chan struct{}, q.batchesPerFlush())
+	for _, group := range groupByProject(events) {
+		for start := 0; start < len(group); start += batchSize {
+			end := start + batchSize
+			if end > len(group) {
+				end = len(group)
+			}
+			sem <- struct{}{}
+			q.workCh <- workItem{events: group[start:end], done: func() { <-sem }}
+		}
+	}
+}
+
+// signalSpace wakes the producers waiting for space; q.mu must be held
+func (q *IngestionQueue) signalSpace() {
+	close(q.spaceCh)
+	q.spaceCh = make(chan struct{})
+}
+
+// submitBatch submits a batch to the ingestion client, retrying failed attempts
+func (q *IngestionQueue) submitBatch(batch []queuedEvent) {
+	batchSize := len(batch)
+	events := make([]types.IngestionEvent, batchSize)
+	for i, queued := range batch {
+		events[i] = queued.event
+	}
+
+	q.stats.batchesSubmitted.Add(1)
 
 	// Call flush start hook
 	if q.onFlushStart != nil {
@@ -324,12 +649,17 @@ func (q *IngestionQueue) flushBuffer() {
 		response, err := q.client.SubmitBatch(ctx, events)
 		if err == nil && response != nil && response.Success {
 			// Success
+			acknowledgedAt := q.now()
+			q.stats.eventsProcessed.Add(int64(batchSize))
 			q.stats.mu.Lock()
-			q.stats.EventsProcessed += int64(batchSize)
-			q.stats.LastFlushTime = time.Now()
+			q.stats.lastFlushTime = time.Now()
 			flushTime := time.Since(startTime)
-			q.stats.TotalFlushTime += flushTime
-			q.stats.AverageFlushTime = q.stats.TotalFlushTime / time.Duration(q.stats.BatchesSubmitted)
+			q.stats.totalFlushTime += flushTime
+			q.stats.averageFlushTime = q.stats.totalFlushTime / time.Duration(q.stats.batchesSubmitted.Load())
+			for _, queued := range batch {
+				q.latencies.add(acknowledgedAt.Sub(queued.enqueuedAt))
+			}
+			q.stats.eventLatencyP50, q.stats.eventLatencyP95, q.stats.eventLatencyMax = q.latencies.percentiles()
 			q.stats.mu.Unlock()
 
 			success = true
@@ -341,14 +671,25 @@ func (q *IngestionQueue) flushBuffer() {
 			// Handle partial failures
 			q.handlePartialFailure(response, events)
 		}
+		if q.onSubmitErr != nil {
+			attemptErr := err
+			if attemptErr == nil {
+				attemptErr = fmt.Errorf("batch of %d events was rejected", batchSize)
+			}
+			q.onSubmitErr(attempt, batchSize, attemptErr)
+		}
+
+		// A batch that failed client-side validation would fail every retry the same way
+		var validationErr *types.RequestValidationError
+		if errors.As(err, &validationErr) {
+			break
+		}
 	}
 
 	if !success {
 		// All retries failed
-		q.stats.mu.Lock()
-		q.stats.EventsFailed += int64(batchSize)
-		q.stats.BatchesFailed++
-		q.stats.mu.Unlock()
+		q.stats.eventsFailed.Add(int64(batchSize))
+		q.stats.batchesFailed.Add(1)
 
 		// Drop events that couldn't be processed
 		for _, event := range events {
@@ -360,17 +701,47 @@ func (q *IngestionQueue) flushBuffer() {
 
 	// Call flush end hook
 	if q.onFlushEnd != nil {
-		q.onFlushEnd(batchSize, success, flushErr)
+		q.onFlushEnd(batchSize, success, flushErr, q.oldestEventAge(batch))
+	}
+
+	var ackErr error
+	if !success {
+		ackErr = flushErr
+		if ackErr == nil {
+			ackErr = fmt.Errorf("batch of %d events was rejected", batchSize)
+		}
+	}
+	for _, queued := range batch {
+		notifyAcks(queued.acks, ackErr)
+	}
+}
+
+// notifyAcks reports the outcome of an event's submission to everyone waiting for it
+func notifyAcks(acks []chan<- error, err error) {
+	for _, ack := range acks {
+		ack <- err
 	}
 }
 
+// oldestEventAge returns how long ago the earliest event of a batch was enqueued
+func (q *IngestionQueue) oldestEventAge(batch []queuedEvent) time.Duration {
+	if len(batch) == 0 {
+		return 0
+	}
+	oldest := batch[0].enqueuedAt
+	for _, queued := range batch[1:] {
+		if queued.enqueuedAt.Before(oldest) {
+			oldest = queued.enqueuedAt
+		}
+	}
+	return q.now().Sub(oldest)
+}
+
 // handlePartialFailure handles cases where some events succeeded and some failed
 func (q *IngestionQueue) handlePartialFailure(response *types.IngestionResponse, events []types.IngestionEvent) {
 	if response.Usage != nil {
-		q.stats.mu.Lock()
-		q.stats.EventsProcessed += int64(response.Usage.EventsProcessed)
-		q.stats.EventsFailed += int64(response.Usage.EventsFailed)
-		q.stats.mu.Unlock()
+		q.stats.eventsProcessed.Add(int64(response.Usage.EventsProcessed))
+		q.stats.eventsFailed.Add(int64(response.Usage.EventsFailed))
 	}
 
 	// Log failed events for debugging
@@ -389,6 +760,38 @@ func (q *IngestionQueue) handlePartialFailure(response *types.IngestionResponse,
 	}
 }
 
+// Pause stops submitting batches, for example while the ingestion API is known to be
+// down. Events stay buffered up to MaxQueueSize, where the queue's full policy applies;
+// with BlockOnFull producers wait until Resume. Shutdown still flushes a paused queue.
+func (q *IngestionQueue) Pause() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.paused = true
+}
+
+// Resume restarts submission after Pause and flushes the events buffered meanwhile
+func (q *IngestionQueue) Resume() {
+	q.mu.Lock()
+	wasPaused := q.paused
+	q.paused = false
+	q.mu.Unlock()
+
+	if wasPaused {
+		select {
+		case q.flushCh <- struct{}{}:
+		default:
+			// Channel full, flush already triggered
+		}
+	}
+}
+
+// IsPaused returns true if submission is paused
+func (q *IngestionQueue) IsPaused() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.paused
+}
+
 // IsEmpty returns true if the queue is empty
 func (q *IngestionQueue) IsEmpty() bool {
 	q.mu.RLock()
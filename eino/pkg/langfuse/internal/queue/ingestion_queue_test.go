@@ -14,9 +14,6 @@ import (
 	"eino/pkg/langfuse/api/resources/ingestion/types"
 )
 
-// ErrQueueClosed is used when trying to operate on a closed queue
-var ErrQueueClosed = fmt.Errorf("queue is closed")
-
 // MockIngestionClient implements IngestionClient interface for testing
 type MockIngestionClient struct {
 	mu             sync.RWMutex
@@ -314,7 +311,7 @@ func TestIngestionQueue_ErrorHandling(t *testing.T) {
 
 	var flushResults []bool
 	var flushErrors []error
-	config.OnFlushEnd = func(batchSize int, success bool, err error) {
+	config.OnFlushEnd = func(batchSize int, success bool, err error, _ time.Duration) {
 		flushResults = append(flushResults, success)
 		flushErrors = append(flushErrors, err)
 	}
@@ -416,8 +413,7 @@ func TestIngestionQueue_ShutdownBehavior(t *testing.T) {
 		// Verify enqueue fails on closed queue
 		event := CreateTestIngestionEvent("after-shutdown", "trace-create")
 		err = queue.Enqueue(event)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "queue is closed")
+		assert.ErrorIs(t, err, ErrQueueClosed)
 	})
 
 	t.Run("shutdown timeout", func(t *testing.T) {
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"sort"
+	"time"
+)
+
+// latencyWindowSize is the number of most recent event latencies percentiles are
+// computed over
+const latencyWindowSize = 1024
+
+// latencyWindow keeps the latencies of the most recently acknowledged events in a fixed
+// ring, so memory stays bounded however long the queue runs
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, 0, size)}
+}
+
+// add records a latency, replacing the oldest one once the window is full
+func (w *latencyWindow) add(latency time.Duration) {
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % len(w.samples)
+}
+
+// percentiles returns the nearest-rank p50 and p95 and the maximum of the window
+func (w *latencyWindow) percentiles() (p50, p95, max time.Duration) {
+	if len(w.samples) == 0 {
+		return 0, 0, 0
+	}
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return nearestRank(sorted, 50), nearestRank(sorted, 95), sorted[len(sorted)-1]
+}
+
+// nearestRank returns the pth percentile of sorted values
+func nearestRank(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+// fakeClock is a manually advanced clock for the queue's now function
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestIngestionQueue_EventLatency(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	var oldestAges []time.Duration
+	q := NewIngestionQueue(&batchRecorder{}, &QueueConfig{
+		FlushAt:       1000,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+		OnFlushEnd: func(batchSize int, success bool, err error, oldestEventAge time.Duration) {
+			oldestAges = append(oldestAges, oldestEventAge)
+		},
+	})
+	q.now = clock.Now
+
+	// Twenty events enqueued one second apart, acknowledged ten seconds after the last
+	for i := 0; i < 20; i++ {
+		require.NoError(t, q.Enqueue(types.IngestionEvent{ID: "event", Type: types.EventTypeTraceCreate, Timestamp: clock.Now(), Body: map[string]interface{}{}}))
+		clock.Advance(time.Second)
+	}
+	assert.Equal(t, 20*time.Second, q.OldestEventAge())
+	clock.Advance(9 * time.Second)
+
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	stats := q.Stats()
+	assert.Equal(t, 19*time.Second, stats.EventLatencyP50)
+	assert.Equal(t, 28*time.Second, stats.EventLatencyP95)
+	assert.Equal(t, 29*time.Second, stats.EventLatencyMax)
+	assert.Equal(t, []time.Duration{29 * time.Second}, oldestAges)
+}
+
+func TestIngestionQueue_EventLatency_Coalesced(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	q := newCoalescingQueue(&batchRecorder{})
+	q.now = clock.Now
+
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "first"})))
+	clock.Advance(5 * time.Second)
+	require.NoError(t, q.Enqueue(updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"output": "second"})))
+	clock.Advance(time.Second)
+
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	// The merged event is measured from the first update
+	stats := q.Stats()
+	assert.Equal(t, 6*time.Second, stats.EventLatencyMax)
+	assert.Equal(t, 6*time.Second, stats.EventLatencyP50)
+}
+
+func TestLatencyWindow(t *testing.T) {
+	w := newLatencyWindow(4)
+	p50, p95, max := w.percentiles()
+	assert.Zero(t, p50+p95+max)
+
+	for _, ms := range []int{40, 10, 30, 20} {
+		w.add(time.Duration(ms) * time.Millisecond)
+	}
+	p50, p95, max = w.percentiles()
+	assert.Equal(t, 20*time.Millisecond, p50)
+	assert.Equal(t, 40*time.Millisecond, p95)
+	assert.Equal(t, 40*time.Millisecond, max)
+
+	// The oldest samples are replaced once the window is full
+	w.add(5 * time.Millisecond)
+	w.add(5 * time.Millisecond)
+	assert.Len(t, w.samples, 4)
+	p50, _, max = w.percentiles()
+	assert.Equal(t, 5*time.Millisecond, p50)
+	assert.Equal(t, 30*time.Millisecond, max)
+}
+
+func TestIngestionQueue_OldestEventAge(t *testing.T) {
+	q := NewIngestionQueue(&batchRecorder{}, &QueueConfig{FlushAt: 1000, FlushInterval: time.Hour, MaxQueueSize: 1000})
+	defer q.Shutdown(context.Background())
+	assert.Zero(t, q.OldestEventAge())
+
+	require.NoError(t, q.Enqueue(types.IngestionEvent{ID: "event-1", Type: types.EventTypeTraceCreate, Timestamp: time.Now(), Body: map[string]interface{}{}}))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, q.Enqueue(types.IngestionEvent{ID: "event-2", Type: types.EventTypeTraceCreate, Timestamp: time.Now(), Body: map[string]interface{}{}}))
+
+	// The age is measured from the front-most event, not the latest one
+	age := q.OldestEventAge()
+	assert.GreaterOrEqual(t, age, 50*time.Millisecond)
+	assert.Less(t, age, time.Second)
+}
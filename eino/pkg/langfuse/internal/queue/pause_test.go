@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *batchRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, batch := range r.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestIngestionQueue_PauseResume(t *testing.T) {
+	client := &batchRecorder{}
+	q := NewIngestionQueue(client, &QueueConfig{
+		FlushAt:       2,
+		FlushInterval: 10 * time.Millisecond,
+		MaxQueueSize:  3,
+	})
+
+	q.Pause()
+	assert.True(t, q.IsPaused())
+	for _, id := range []string{"trace-1", "trace-2", "trace-3", "trace-4"} {
+		require.NoError(t, q.Enqueue(traceEvent(id)))
+	}
+	require.NoError(t, q.Flush())
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Zero(t, client.count(), "neither FlushAt, the interval nor Flush submit while paused")
+	assert.Equal(t, 3, q.Size(), "events are buffered up to capacity")
+	assert.Equal(t, int64(1), q.Stats().EventsDropped)
+
+	q.Resume()
+	assert.False(t, q.IsPaused())
+	require.Eventually(t, func() bool { return client.count() == 3 }, time.Second, 5*time.Millisecond)
+	assert.True(t, q.IsEmpty())
+}
+
+func TestIngestionQueue_ShutdownFlushesPausedQueue(t *testing.T) {
+	client := &batchRecorder{}
+	q := NewIngestionQueue(client, &QueueConfig{FlushAt: 100, FlushInterval: time.Hour, MaxQueueSize: 100})
+
+	q.Pause()
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	require.NoError(t, q.Shutdown(context.Background()))
+	assert.Equal(t, 1, client.count())
+}
@@ -0,0 +1,32 @@
+package queue
+
+// groupByProject splits events into one group per IngestionEvent.Project, in the order
+// in which each project first appears, keeping the order of events within a group. A
+// batch is sent with the credentials of a single project, so events of different
+// projects are never batched together. When all events belong to the same project, the
+// usual case, they are returned as a single group.
+func groupByProject(events []queuedEvent) [][]queuedEvent {
+	mixed := false
+	for _, queued := range events {
+		if queued.event.Project != events[0].event.Project {
+			mixed = true
+			break
+		}
+	}
+	if !mixed {
+		return [][]queuedEvent{events}
+	}
+
+	index := make(map[string]int)
+	var groups [][]queuedEvent
+	for _, queued := range events {
+		i, ok := index[queued.event.Project]
+		if !ok {
+			i = len(groups)
+			index[queued.event.Project] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], queued)
+	}
+	return groups
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func TestIngestionQueue_BatchesPerProject(t *testing.T) {
+	recorder := &batchRecorder{}
+	q := NewIngestionQueue(recorder, &QueueConfig{
+		FlushAt:       1000,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+	})
+
+	projects := []string{"", "staging", "", "production", "staging", "staging", ""}
+	for i, project := range projects {
+		id := fmt.Sprintf("trace-%d", i)
+		event := updateEvent(types.EventTypeTraceUpdate, id, map[string]interface{}{"name": "chat"})
+		event.Project = project
+		require.NoError(t, q.Enqueue(event))
+	}
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	batches := make(map[string][][]string)
+	for _, batch := range recorder.batches {
+		project := batch[0].Project
+		var ids []string
+		for _, event := range batch {
+			assert.Equal(t, project, event.Project, "a batch only holds events of one project")
+			ids = append(ids, event.ID)
+		}
+		batches[project] = append(batches[project], ids)
+	}
+
+	assert.Equal(t, [][]string{{"trace-0", "trace-2", "trace-6"}}, batches[""])
+	assert.Equal(t, [][]string{{"trace-1", "trace-4", "trace-5"}}, batches["staging"])
+	assert.Equal(t, [][]string{{"trace-3"}}, batches["production"])
+}
+
+func TestGroupByProject(t *testing.T) {
+	event := func(id, project string) queuedEvent {
+		return queuedEvent{event: types.IngestionEvent{ID: id, Project: project}}
+	}
+
+	single := []queuedEvent{event("a", "staging"), event("b", "staging")}
+	assert.Equal(t, [][]queuedEvent{single}, groupByProject(single))
+
+	groups := groupByProject([]queuedEvent{event("a", ""), event("b", "staging"), event("c", "")})
+	assert.Equal(t, [][]queuedEvent{
+		{event("a", ""), event("c", "")},
+		{event("b", "staging")},
+	}, groups)
+}
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func TestIngestionQueue_EventsBySource(t *testing.T) {
+	q := NewIngestionQueue(&batchRecorder{}, &QueueConfig{
+		FlushAt:       1000,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+	})
+	defer q.Shutdown(context.Background())
+
+	assert.Nil(t, q.Stats().EventsBySource)
+
+	sources := []string{types.EventSourceTrace, types.EventSourceGeneration, types.EventSourceGeneration, ""}
+	for i, source := range sources {
+		event := updateEvent(types.EventTypeTraceUpdate, "trace-1", map[string]interface{}{"name": "chat"})
+		event.ID = string(rune('a' + i))
+		event.Source = source
+		require.NoError(t, q.Enqueue(event))
+	}
+
+	stats := q.Stats()
+	assert.Equal(t, int64(4), stats.EventsQueued)
+	assert.Equal(t, map[string]int64{
+		types.EventSourceTrace:      1,
+		types.EventSourceGeneration: 2,
+	}, stats.EventsBySource)
+
+	t.Run("stats hold a copy", func(t *testing.T) {
+		stats.EventsBySource[types.EventSourceSpan] = 10
+		assert.NotContains(t, q.Stats().EventsBySource, types.EventSourceSpan)
+	})
+}
@@ -158,9 +158,9 @@ func (wp *WorkerPool) SubmitWork(events []types.IngestionEvent) error {
 		wp.stats.mu.Unlock()
 		return nil
 	case <-wp.ctx.Done():
-		return fmt.Errorf("worker pool is shutting down")
+		return fmt.Errorf("worker pool is shutting down: %w", ErrQueueClosed)
 	default:
-		return fmt.Errorf("work queue is full")
+		return fmt.Errorf("worker pool: %w", ErrQueueFull)
 	}
 }
 
@@ -396,7 +396,7 @@ func (aq *AdvancedIngestionQueue) Enqueue(event types.IngestionEvent) error {
 	defer aq.mu.RUnlock()
 	
 	if aq.closed {
-		return fmt.Errorf("advanced queue is closed")
+		return fmt.Errorf("advanced queue: %w", ErrQueueClosed)
 	}
 	
 	// Use worker pool for high load scenarios
@@ -337,8 +337,7 @@ func TestWorkerPool_Shutdown(t *testing.T) {
 			TestIngestionEvent("after-shutdown", "trace-create"),
 		}
 		err = pool.SubmitWork(events)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "shutting down")
+		assert.ErrorIs(t, err, ErrQueueClosed)
 	})
 
 	t.Run("shutdown timeout", func(t *testing.T) {
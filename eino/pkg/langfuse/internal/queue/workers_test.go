@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"eino/pkg/langfuse/api/resources/ingestion/types"
+)
+
+func newWorkerQueue(client IngestionClient, flushAt, workerCount int) *IngestionQueue {
+	return NewIngestionQueue(client, &QueueConfig{
+		FlushAt:       flushAt,
+		FlushInterval: time.Hour,
+		MaxQueueSize:  1000,
+		WorkerCount:   workerCount,
+	})
+}
+
+func traceEvent(id string) types.IngestionEvent {
+	return updateEvent(types.EventTypeTraceCreate, id, map[string]interface{}{"name": id})
+}
+
+func TestIngestionQueue_ConcurrentWorkers(t *testing.T) {
+	client := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+	q := newWorkerQueue(client, 1000, 2)
+
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	require.NoError(t, q.Flush())
+	<-client.started
+
+	// The first batch is still being submitted when the second flush happens
+	require.NoError(t, q.Enqueue(traceEvent("trace-2")))
+	require.NoError(t, q.Flush())
+	select {
+	case <-client.started:
+	case <-time.After(time.Second):
+		t.Fatal("second worker did not pick up the second batch")
+	}
+	assert.Equal(t, 2, q.Stats().ActiveWorkers)
+
+	client.release <- struct{}{}
+	client.release <- struct{}{}
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	require.Len(t, client.batches, 2)
+	stats := q.Stats()
+	assert.Equal(t, 0, stats.ActiveWorkers)
+	assert.Equal(t, int64(2), stats.BatchesSubmitted)
+	assert.Equal(t, int64(2), stats.EventsProcessed)
+}
+
+func TestIngestionQueue_WorkersShareOneFlush(t *testing.T) {
+	client := &batchRecorder{started: make(chan struct{}), release: make(chan struct{})}
+	q := newWorkerQueue(client, 1, 2)
+
+	for _, id := range []string{"trace-1", "trace-2", "trace-3"} {
+		require.NoError(t, q.Enqueue(traceEvent(id)))
+	}
+	require.NoError(t, q.Flush())
+
+	// The flush is split into batches of FlushAt events, submitted one at a time so
+	// that a worker stays free for the next flush
+	for i := 0; i < 3; i++ {
+		<-client.started
+		select {
+		case <-client.started:
+			t.Fatal("two batches of the same flush were submitted at once")
+		case <-time.After(50 * time.Millisecond):
+		}
+		assert.Equal(t, 1, q.Stats().ActiveWorkers)
+		client.release <- struct{}{}
+	}
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	require.Len(t, client.batches, 3)
+	for _, batch := range client.batches {
+		assert.Len(t, batch, 1)
+	}
+}
+
+func TestIngestionQueue_WorkerCountDefault(t *testing.T) {
+	client := &batchRecorder{}
+	q := newWorkerQueue(client, 1000, 0)
+	assert.Equal(t, 1, q.workerCount)
+
+	require.NoError(t, q.Enqueue(traceEvent("trace-1")))
+	require.NoError(t, q.Shutdown(context.Background()))
+	assert.Len(t, client.batches, 1)
+}
@@ -334,6 +334,47 @@ func CloneMetadata(metadata map[string]interface{}) map[string]interface{} {
 	return result
 }
 
+// ConvertTimes returns a copy of value in which every time.Time (or non-nil *time.Time)
+// nested inside maps and slices is replaced with the result of convert. Maps and slices
+// are copied so the original value is never modified; other values are returned as is.
+func ConvertTimes(value interface{}, convert func(time.Time) interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return convert(v)
+	case *time.Time:
+		if v == nil {
+			return v
+		}
+		return convert(*v)
+	case map[string]interface{}:
+		return ConvertMetadataTimes(v, convert)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = ConvertTimes(item, convert)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// ConvertMetadataTimes applies ConvertTimes to every value of a metadata map
+func ConvertMetadataTimes(metadata map[string]interface{}, convert func(time.Time) interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		result[k] = ConvertTimes(v, convert)
+	}
+	return result
+}
+
 // FilterMetadata filters metadata by keys
 func FilterMetadata(metadata map[string]interface{}, keys []string) map[string]interface{} {
 	if metadata == nil || len(keys) == 0 {
@@ -0,0 +1,235 @@
+// Package idconv converts request and trace IDs from external systems into Langfuse
+// trace IDs and back.
+//
+// The canonical form produced by this package is the W3C trace-id format: 32 lowercase
+// hexadecimal characters encoding 16 bytes, not all zero. Accepted inputs are:
+//
+//   - 32 hex characters in any case ("A1B2C3D4...")
+//   - UUIDs in any case, with or without hyphens, braces or a "urn:uuid:" prefix
+//   - W3C traceparent headers ("00-<trace-id>-<parent-id>-<flags>")
+//   - padded standard or URL-safe base64 encoding exactly 16 bytes
+//   - 16 hex characters (64-bit IDs), via FromHexTraceID only
+//
+// Collision properties: normalization is deliberately many-to-one. The same 16 bytes
+// written as upper or lower case hex, as a UUID or as base64 all map to the same trace
+// ID, so the same request seen by different systems lands on one trace. Distinct 16-byte
+// values never map to the same ID. 64-bit IDs are left-padded with zeros, so they can
+// only collide with a 128-bit ID whose upper half is zero. Unpadded base64 is rejected
+// because many opaque IDs (e.g. 22-character slugs) would otherwise be silently
+// reinterpreted as binary trace IDs.
+package idconv
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TraceIDLength is the length of a canonical trace ID in hex characters
+	TraceIDLength = 32
+
+	// SpanIDLength is the length of a span ID in hex characters
+	SpanIDLength = 16
+
+	// traceIDBytes is the number of bytes encoded by a trace ID
+	traceIDBytes = 16
+)
+
+var (
+	// ErrInvalidTraceID is returned for inputs that cannot be safely normalized
+	ErrInvalidTraceID = errors.New("invalid trace id")
+
+	// ErrUnrecognizedFormat is returned when the input matches none of the accepted
+	// formats; it wraps ErrInvalidTraceID. Callers may still treat such input as an
+	// opaque ID, which is what the trace builders do.
+	ErrUnrecognizedFormat = fmt.Errorf("%w: unrecognized format", ErrInvalidTraceID)
+)
+
+// ToLangfuseTraceID normalizes a trace ID in any accepted format into the canonical
+// 32-character lowercase hex form.
+func ToLangfuseTraceID(input string) (string, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return "", fmt.Errorf("%w: empty input", ErrInvalidTraceID)
+	}
+
+	// W3C traceparent: version-traceid-parentid-flags
+	if parts := strings.Split(s, "-"); len(parts) == 4 && len(parts[0]) == 2 && len(parts[1]) == TraceIDLength {
+		return parseTraceparent(parts)
+	}
+
+	lower := strings.ToLower(s)
+	lower = strings.TrimPrefix(lower, "urn:uuid:")
+	if strings.HasPrefix(lower, "{") && strings.HasSuffix(lower, "}") {
+		lower = lower[1 : len(lower)-1]
+	}
+
+	// UUID with hyphens in the 8-4-4-4-12 layout
+	if len(lower) == 36 && isUUIDLayout(lower) {
+		return canonicalHex(strings.ReplaceAll(lower, "-", ""))
+	}
+
+	if len(lower) == TraceIDLength && isHex(lower) {
+		return canonicalHex(lower)
+	}
+
+	// Padded base64 of exactly 16 bytes is 24 characters ending in "=="
+	if len(s) == 24 && strings.HasSuffix(s, "==") {
+		return fromBase64(s)
+	}
+
+	return "", fmt.Errorf("%w %q", ErrUnrecognizedFormat, truncate(s))
+}
+
+// FromHexTraceID converts a 16- or 32-character hex trace ID, as used by W3C trace
+// context, Jaeger and Zipkin, into the canonical form. 64-bit IDs are left-padded with
+// zeros as specified by W3C trace context.
+func FromHexTraceID(hexID string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(hexID))
+	if !isHex(s) {
+		return "", fmt.Errorf("%w: not a hex string", ErrInvalidTraceID)
+	}
+
+	switch len(s) {
+	case TraceIDLength:
+		return canonicalHex(s)
+	case SpanIDLength:
+		return canonicalHex(strings.Repeat("0", TraceIDLength-SpanIDLength) + s)
+	default:
+		return "", fmt.Errorf("%w: hex trace id must be %d or %d characters, got %d",
+			ErrInvalidTraceID, SpanIDLength, TraceIDLength, len(s))
+	}
+}
+
+// IsHexTraceID reports whether id is already a canonical trace ID:
+// 32 lowercase hex characters, not all zero
+func IsHexTraceID(id string) bool {
+	return len(id) == TraceIDLength && isLowerHex(id) && !isAllZero(id)
+}
+
+// ToUUID formats a canonical trace ID as a lowercase hyphenated UUID, the inverse of
+// ToLangfuseTraceID for UUID inputs
+func ToUUID(traceID string) (string, error) {
+	if !IsHexTraceID(traceID) {
+		return "", fmt.Errorf("%w: not a canonical trace id", ErrInvalidTraceID)
+	}
+	return traceID[0:8] + "-" + traceID[8:12] + "-" + traceID[12:16] + "-" + traceID[16:20] + "-" + traceID[20:32], nil
+}
+
+// NewSpanIDFromTraceID derives a deterministic 16-character hex observation ID from a
+// trace ID and an index, so re-importing the same trace yields the same observation IDs.
+//
+// The ID is the first 8 bytes of SHA-256(traceID ":" index). Different indexes under
+// the same trace collide with probability around n²/2⁶⁵ for n observations.
+func NewSpanIDFromTraceID(traceID string, index int) string {
+	sum := sha256.Sum256([]byte(traceID + ":" + strconv.Itoa(index)))
+	id := hex.EncodeToString(sum[:SpanIDLength/2])
+
+	// An all-zero span ID is invalid in W3C trace context
+	if isAllZero(id) {
+		id = id[:SpanIDLength-1] + "1"
+	}
+	return id
+}
+
+// parseTraceparent extracts the trace ID from a split traceparent header
+func parseTraceparent(parts []string) (string, error) {
+	version, traceID, parentID, flags := strings.ToLower(parts[0]), strings.ToLower(parts[1]), strings.ToLower(parts[2]), strings.ToLower(parts[3])
+	if !isHex(version) || version == "ff" {
+		return "", fmt.Errorf("%w: invalid traceparent version", ErrInvalidTraceID)
+	}
+	if len(parentID) != SpanIDLength || !isHex(parentID) || isAllZero(parentID) {
+		return "", fmt.Errorf("%w: invalid traceparent parent id", ErrInvalidTraceID)
+	}
+	if len(flags) != 2 || !isHex(flags) {
+		return "", fmt.Errorf("%w: invalid traceparent flags", ErrInvalidTraceID)
+	}
+	if !isHex(traceID) {
+		return "", fmt.Errorf("%w: invalid traceparent trace id", ErrInvalidTraceID)
+	}
+	return canonicalHex(traceID)
+}
+
+// fromBase64 decodes a padded standard or URL-safe base64 trace ID
+func fromBase64(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		data, err = base64.URLEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid base64", ErrInvalidTraceID)
+	}
+	if len(data) != traceIDBytes {
+		return "", fmt.Errorf("%w: base64 trace id must encode %d bytes, got %d", ErrInvalidTraceID, traceIDBytes, len(data))
+	}
+	return canonicalHex(hex.EncodeToString(data))
+}
+
+// canonicalHex validates a 32-character lowercase hex string
+func canonicalHex(s string) (string, error) {
+	if isAllZero(s) {
+		return "", fmt.Errorf("%w: all-zero trace id", ErrInvalidTraceID)
+	}
+	return s, nil
+}
+
+// isUUIDLayout reports whether s is lowercase hex in the 8-4-4-4-12 layout
+func isUUIDLayout(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isLowerHexChar(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isLowerHexChar(c) && !(c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isLowerHexChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerHexChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f')
+}
+
+func isAllZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// truncate shortens input quoted in error messages
+func truncate(s string) string {
+	const max = 64
+	if len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
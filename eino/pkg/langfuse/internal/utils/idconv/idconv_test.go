@@ -0,0 +1,191 @@
+package idconv
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const canonical = "a1b2c3d4e5f60718293a4b5c6d7e8f90"
+
+func TestToLangfuseTraceID(t *testing.T) {
+	raw, err := hex.DecodeString(canonical)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "canonical", input: canonical},
+		{name: "upper case hex", input: strings.ToUpper(canonical)},
+		{name: "surrounding whitespace", input: "  " + canonical + "\n"},
+		{name: "uuid", input: "a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90"},
+		{name: "upper case uuid", input: "A1B2C3D4-E5F6-0718-293A-4B5C6D7E8F90"},
+		{name: "braced uuid", input: "{a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90}"},
+		{name: "urn uuid", input: "urn:uuid:a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90"},
+		{name: "traceparent", input: "00-" + canonical + "-00f067aa0ba902b7-01"},
+		{name: "upper case traceparent", input: "00-" + strings.ToUpper(canonical) + "-00F067AA0BA902B7-01"},
+		{name: "standard base64", input: base64.StdEncoding.EncodeToString(raw)},
+		{name: "url-safe base64", input: base64.URLEncoding.EncodeToString(raw)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToLangfuseTraceID(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, canonical, got)
+			assert.True(t, IsHexTraceID(got))
+		})
+	}
+}
+
+func TestToLangfuseTraceID_Rejects(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		unrecognized bool
+	}{
+		{name: "empty", input: ""},
+		{name: "whitespace", input: "   "},
+		{name: "all zero hex", input: strings.Repeat("0", 32)},
+		{name: "all zero uuid", input: "00000000-0000-0000-0000-000000000000"},
+		{name: "invalid traceparent parent id", input: "00-" + canonical + "-0000000000000000-01"},
+		{name: "invalid traceparent version", input: "ff-" + canonical + "-00f067aa0ba902b7-01"},
+		{name: "non-hex traceparent trace id", input: "00-" + strings.Repeat("z", 32) + "-00f067aa0ba902b7-01"},
+		{name: "invalid base64", input: strings.Repeat("!", 22) + "=="},
+		{name: "short hex", input: canonical[:31], unrecognized: true},
+		{name: "long hex", input: canonical + "0", unrecognized: true},
+		{name: "misplaced uuid hyphens", input: "a1b2c3d4e-5f6-0718-293a-4b5c6d7e8f90", unrecognized: true},
+		{name: "unpadded base64", input: strings.TrimRight(base64.StdEncoding.EncodeToString(make([]byte, 16)), "="), unrecognized: true},
+		{name: "opaque request id", input: "req-2024-01-01:abc", unrecognized: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToLangfuseTraceID(tt.input)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidTraceID)
+			assert.Equal(t, tt.unrecognized, errors.Is(err, ErrUnrecognizedFormat))
+		})
+	}
+}
+
+func TestFromHexTraceID(t *testing.T) {
+	got, err := FromHexTraceID(strings.ToUpper(canonical))
+	require.NoError(t, err)
+	assert.Equal(t, canonical, got)
+
+	// 64-bit IDs are left-padded
+	got, err = FromHexTraceID("00f067aa0ba902b7")
+	require.NoError(t, err)
+	assert.Equal(t, "000000000000000000f067aa0ba902b7", got)
+
+	for _, input := range []string{"", "xyz", canonical[:20], strings.Repeat("0", 16), "a1b2-c3d4"} {
+		_, err := FromHexTraceID(input)
+		assert.ErrorIs(t, err, ErrInvalidTraceID, "input %q", input)
+	}
+}
+
+func TestIsHexTraceID(t *testing.T) {
+	assert.True(t, IsHexTraceID(canonical))
+	assert.False(t, IsHexTraceID(strings.ToUpper(canonical)))
+	assert.False(t, IsHexTraceID(canonical[:31]))
+	assert.False(t, IsHexTraceID(strings.Repeat("0", 32)))
+	assert.False(t, IsHexTraceID("a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90"))
+}
+
+func TestToUUID_RoundTrip(t *testing.T) {
+	uuid, err := ToUUID(canonical)
+	require.NoError(t, err)
+	assert.Equal(t, "a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90", uuid)
+
+	back, err := ToLangfuseTraceID(uuid)
+	require.NoError(t, err)
+	assert.Equal(t, canonical, back)
+
+	_, err = ToUUID("not-a-trace-id")
+	assert.ErrorIs(t, err, ErrInvalidTraceID)
+}
+
+func TestNewSpanIDFromTraceID(t *testing.T) {
+	first := NewSpanIDFromTraceID(canonical, 0)
+	assert.Len(t, first, SpanIDLength)
+	assert.Equal(t, first, NewSpanIDFromTraceID(canonical, 0), "span IDs must be deterministic")
+
+	seen := map[string]bool{first: true}
+	for i := 1; i < 1000; i++ {
+		id := NewSpanIDFromTraceID(canonical, i)
+		assert.False(t, seen[id], "duplicate span id at index %d", i)
+		seen[id] = true
+	}
+
+	assert.NotEqual(t, first, NewSpanIDFromTraceID("b"+canonical[1:], 0))
+}
+
+func FuzzToLangfuseTraceID(f *testing.F) {
+	for _, seed := range []string{
+		"", canonical, strings.ToUpper(canonical), "a1b2c3d4-e5f6-0718-293a-4b5c6d7e8f90",
+		"00-" + canonical + "-00f067aa0ba902b7-01", "obMsLx8d5ikfOTpLXG1+kA==", "{}", "urn:uuid:", "--------",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := ToLangfuseTraceID(input)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidTraceID) {
+				t.Fatalf("error %v does not wrap ErrInvalidTraceID", err)
+			}
+			return
+		}
+
+		if !IsHexTraceID(got) {
+			t.Fatalf("ToLangfuseTraceID(%q) = %q, not a canonical trace id", input, got)
+		}
+
+		// Normalization is idempotent
+		again, err := ToLangfuseTraceID(got)
+		if err != nil || again != got {
+			t.Fatalf("normalizing %q again gave %q, %v", got, again, err)
+		}
+
+		// Canonical IDs survive a UUID round trip
+		uuid, err := ToUUID(got)
+		if err != nil {
+			t.Fatalf("ToUUID(%q): %v", got, err)
+		}
+		if back, err := ToLangfuseTraceID(uuid); err != nil || back != got {
+			t.Fatalf("UUID round trip of %q gave %q, %v", got, back, err)
+		}
+	})
+}
+
+func FuzzFromHexTraceID(f *testing.F) {
+	for _, seed := range []string{"", canonical, "00f067aa0ba902b7", "zz", strings.Repeat("0", 32)} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := FromHexTraceID(input)
+		if err == nil && !IsHexTraceID(got) {
+			t.Fatalf("FromHexTraceID(%q) = %q, not a canonical trace id", input, got)
+		}
+	})
+}
+
+func FuzzNewSpanIDFromTraceID(f *testing.F) {
+	f.Add(canonical, 0)
+	f.Add("", -1)
+
+	f.Fuzz(func(t *testing.T, traceID string, index int) {
+		id := NewSpanIDFromTraceID(traceID, index)
+		if len(id) != SpanIDLength || !isLowerHex(id) || isAllZero(id) {
+			t.Fatalf("NewSpanIDFromTraceID(%q, %d) = %q, not a valid span id", traceID, index, id)
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"math/big"
@@ -235,4 +238,12 @@ func GenerateNumericID(length int) string {
 func GenerateAlphaID(length int) string {
 	alphaAlphabet := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	return GenerateNanoidWithOptions(alphaAlphabet, length)
-}
\ No newline at end of file
+}
+// HashUserID returns the hex-encoded HMAC-SHA256 of a user ID keyed with salt. The same
+// user and salt always give the same hash, but the user ID cannot be recovered from it
+// without the salt.
+func HashUserID(userID, salt string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
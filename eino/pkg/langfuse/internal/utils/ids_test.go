@@ -453,6 +453,15 @@ func TestConcurrentGeneration(t *testing.T) {
 	}
 }
 
+func TestHashUserID(t *testing.T) {
+	hash := HashUserID("user-123", "salt-a")
+	assert.Equal(t, "dde1ae4f16099e046f4060de27ead2c24f56d02a7c56ea4f337b9808cf6dd92d", hash)
+	assert.Equal(t, hash, HashUserID("user-123", "salt-a"), "hash is stable")
+	assert.NotEqual(t, hash, HashUserID("user-124", "salt-a"))
+	assert.NotEqual(t, hash, HashUserID("user-123", "salt-b"), "hash depends on the salt")
+	assert.NotContains(t, hash, "user-123")
+}
+
 func BenchmarkGenerateNanoid(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		GenerateNanoid()
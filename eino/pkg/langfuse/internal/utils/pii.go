@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PIIType identifies a category of personally identifiable information
+type PIIType string
+
+const (
+	EmailAddress PIIType = "email_address"
+	PhoneNumber  PIIType = "phone_number"
+	CreditCard   PIIType = "credit_card"
+	SSN          PIIType = "ssn"
+	IPAddress    PIIType = "ip_address"
+)
+
+// piiPattern pairs a PII type with its detection regex and an optional extra check
+type piiPattern struct {
+	piiType PIIType
+	regex   *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// piiPatterns are evaluated in order and matched text is consumed, so more specific
+// patterns come first. Phone numbers are matched before card numbers so that long
+// international numbers which happen to pass the Luhn check are not reported as cards.
+var piiPatterns = []piiPattern{
+	{
+		piiType: SSN,
+		regex:   regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	},
+	{
+		piiType: EmailAddress,
+		regex:   regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`),
+	},
+	{
+		piiType: IPAddress,
+		regex:   regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+	},
+	{
+		piiType: PhoneNumber,
+		regex:   regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b|\+\d{10,15}\b`),
+	},
+	{
+		piiType: CreditCard,
+		regex:   regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid:   luhnValid,
+	},
+}
+
+// piiTypeOrder is the order in which detected types are reported
+var piiTypeOrder = []PIIType{EmailAddress, PhoneNumber, CreditCard, SSN, IPAddress}
+
+// ContainsPII reports whether the text contains any recognised PII pattern
+func ContainsPII(text string) bool {
+	return len(ExtractPIITypes(text)) > 0
+}
+
+// ExtractPIITypes returns the distinct PII types found in the text
+func ExtractPIITypes(text string) []PIIType {
+	if text == "" {
+		return nil
+	}
+
+	found := make(map[PIIType]bool)
+	scanPII(text, func(p piiPattern, match string) string {
+		found[p.piiType] = true
+		return strings.Repeat(" ", len(match))
+	})
+
+	var types []PIIType
+	for _, t := range piiTypeOrder {
+		if found[t] {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// RedactPII replaces every detected PII value in the text with replacement
+func RedactPII(text string, replacement string) string {
+	if text == "" {
+		return text
+	}
+
+	return scanPII(text, func(p piiPattern, match string) string {
+		return replacement
+	})
+}
+
+// scanPII applies each pattern in order, replacing valid matches with the result of fn
+func scanPII(text string, fn func(p piiPattern, match string) string) string {
+	for _, p := range piiPatterns {
+		text = p.regex.ReplaceAllStringFunc(text, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+			return fn(p, match)
+		})
+	}
+	return text
+}
+
+// luhnValid checks a candidate card number against the Luhn checksum
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	digits := 0
+
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+
+	return digits >= 13 && sum%10 == 0
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPIITypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []PIIType
+	}{
+		{"empty", "", nil},
+		{"no pii", "The quick brown fox jumps over 13 lazy dogs", nil},
+		{"email", "contact jane.doe@example.com for access", []PIIType{EmailAddress}},
+		{"us phone", "call (555) 123-4567 tomorrow", []PIIType{PhoneNumber}},
+		{"dashed phone", "call 555-123-4567", []PIIType{PhoneNumber}},
+		{"international phone", "call +1 555 123 4567 or +4915112345678", []PIIType{PhoneNumber}},
+		{"credit card", "card 4111 1111 1111 1111 on file", []PIIType{CreditCard}},
+		{"credit card no separators", "card 4111111111111111", []PIIType{CreditCard}},
+		{"luhn invalid number", "order 4111111111111112", nil},
+		{"ssn", "ssn 123-45-6789", []PIIType{SSN}},
+		{"ip address", "request from 192.168.1.42", []PIIType{IPAddress}},
+		{"invalid ip", "version 999.1.2.3", nil},
+		{
+			"multiple",
+			"mail bob@example.org from 10.0.0.1, ssn 123-45-6789, phone 555.123.4567",
+			[]PIIType{EmailAddress, PhoneNumber, SSN, IPAddress},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExtractPIITypes(tt.text))
+			assert.Equal(t, len(tt.expected) > 0, ContainsPII(tt.text))
+		})
+	}
+}
+
+func TestRedactPII(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		replacement string
+		expected    string
+	}{
+		{"empty", "", "[REDACTED]", ""},
+		{"no pii", "nothing to see here", "[REDACTED]", "nothing to see here"},
+		{"email", "email jane@example.com now", "[REDACTED]", "email [REDACTED] now"},
+		{"card and phone", "card 4111-1111-1111-1111, phone (555) 123-4567", "***", "card ***, phone ***"},
+		{"ssn and ip", "ssn 123-45-6789 from 8.8.8.8", "<pii>", "ssn <pii> from <pii>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactPII(tt.text, tt.replacement))
+		})
+	}
+}
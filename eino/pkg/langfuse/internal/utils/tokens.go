@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenCounter estimates how many tokens model splits text into
+type TokenCounter interface {
+	Count(text, model string) (int, error)
+}
+
+// tokenPieces splits text the way tiktoken's cl100k_base and o200k_base encodings do
+// before merging byte pairs: contractions, words and punctuation runs with their leading
+// space, numbers of up to three digits, and whitespace runs
+var tokenPieces = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)| ?\p{L}+| ?\p{N}{1,3}| ?[^\s\p{L}\p{N}]+|\s+`)
+
+// Rough average characters per token of the pieces byte pair merging keeps together
+const (
+	latinCharsPerToken       = 6
+	punctuationCharsPerToken = 2
+)
+
+// TikTokenCounter approximates tiktoken's token counts without its vocabulary: text is
+// split into the same pieces tiktoken merges within, and each piece is counted from its
+// length. The same estimate is used for every model, and Count never returns an error.
+type TikTokenCounter struct{}
+
+// NewTikTokenCounter returns a TikTokenCounter
+func NewTikTokenCounter() *TikTokenCounter {
+	return &TikTokenCounter{}
+}
+
+// Count estimates the number of tokens in text
+func (c *TikTokenCounter) Count(text, model string) (int, error) {
+	tokens := 0
+	for _, piece := range tokenPieces.FindAllString(text, -1) {
+		tokens += pieceTokens(piece)
+	}
+	return tokens, nil
+}
+
+// pieceTokens estimates the tokens of a single piece. Whitespace runs and numbers are
+// one token; a leading space is merged into the piece it precedes.
+func pieceTokens(piece string) int {
+	first, size := utf8.DecodeRuneInString(piece)
+	if unicode.IsSpace(first) {
+		next, _ := utf8.DecodeRuneInString(piece[size:])
+		if size == len(piece) || unicode.IsSpace(next) {
+			return 1
+		}
+		piece = piece[size:]
+		first = next
+	}
+
+	switch {
+	case unicode.IsNumber(first):
+		return 1
+	case unicode.IsLetter(first):
+		latin, other := 0, 0
+		for _, r := range piece {
+			if r < unicode.MaxLatin1 {
+				latin++
+			} else {
+				other++
+			}
+		}
+		// Scripts outside Latin-1, such as CJK, rarely merge beyond a single character
+		return ceilDiv(latin, latinCharsPerToken) + other
+	default:
+		return ceilDiv(utf8.RuneCountInString(piece), punctuationCharsPerToken)
+	}
+}
+
+// ceilDiv divides n by d, rounding up
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTikTokenCounter_Count(t *testing.T) {
+	counter := NewTikTokenCounter()
+
+	tests := []struct {
+		text   string
+		tokens int
+	}{
+		{"", 0},
+		{"hello", 1},
+		{"Hello world", 2},
+		{"Hello, world!", 4},
+		{"internationalization", 4},
+		{"It's 2024", 4},
+		{"line one\n\nline two", 5},
+		{"你好世界", 4},
+	}
+	for _, tt := range tests {
+		tokens, err := counter.Count(tt.text, "gpt-4o")
+		require.NoError(t, err)
+		assert.Equal(t, tt.tokens, tokens, "%q", tt.text)
+	}
+}
+
+func TestTikTokenCounter_Prose(t *testing.T) {
+	text := "Langfuse is an open source LLM engineering platform. It helps teams collaboratively " +
+		"debug, analyze, and iterate on their LLM applications. All platform features are " +
+		"natively integrated to accelerate the development workflow."
+
+	tokens, err := NewTikTokenCounter().Count(text, "gpt-4")
+	require.NoError(t, err)
+
+	// English prose runs between one token per word and one per three characters
+	assert.GreaterOrEqual(t, tokens, len(strings.Fields(text)))
+	assert.LessOrEqual(t, tokens, len(text)/3)
+}
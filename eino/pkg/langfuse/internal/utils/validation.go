@@ -284,6 +284,9 @@ func ValidateEnvironment(env, fieldName string) *ValidationError {
 	return nil
 }
 
+// tagPattern matches valid tags; ':' allows "key:value" tags such as "team:platform"
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9._:-]+$`)
+
 // ValidateTags validates tags array
 func ValidateTags(tags []string, fieldName string, maxTags, maxTagLength int) *ValidationError {
 	if len(tags) == 0 {
@@ -304,8 +307,7 @@ func ValidateTags(tags []string, fieldName string, maxTags, maxTagLength int) *V
 		}
 
 		// Tags should not contain special characters that might cause issues
-		tagRegex := regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
-		if !tagRegex.MatchString(tag) {
+		if !tagPattern.MatchString(tag) {
 			return &ValidationError{Field: fieldName, Message: fmt.Sprintf("tag at index %d contains invalid characters", i)}
 		}
 	}
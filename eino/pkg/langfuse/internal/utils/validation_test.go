@@ -524,6 +524,7 @@ func TestValidateTags(t *testing.T) {
 		{"tag too long", []string{"tag1", string(make([]byte, 51))}, "tags", 10, 50, true, "tag at index 1 must be at most 50 characters"},
 		{"invalid tag characters", []string{"tag1", "tag@2"}, "tags", 10, 50, true, "tag at index 1 contains invalid characters"},
 		{"valid tag with allowed characters", []string{"tag_1", "tag-2", "tag.3"}, "tags", 10, 50, false, ""},
+		{"key:value tags", []string{"team:platform", "service:agent"}, "tags", 10, 50, false, ""},
 	}
 
 	for _, tt := range tests {
@@ -170,18 +170,18 @@ func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 
 // ContextWithTrace adds a trace builder to the context
 func ContextWithTrace(ctx context.Context, trace *client.TraceBuilder) context.Context {
-	return context.WithValue(ctx, traceBuilderContextKey, trace)
+	return client.ContextWithTrace(ctx, trace)
 }
 
 // ContextWithSpan adds a span builder to the context
 func ContextWithSpan(ctx context.Context, span *client.SpanBuilder) context.Context {
-	return context.WithValue(ctx, spanBuilderContextKey, span)
+	return client.ContextWithSpan(ctx, span)
 }
 
 // ContextWithTraceAndSpan adds both trace and span builders to the context
 func ContextWithTraceAndSpan(ctx context.Context, trace *client.TraceBuilder, span *client.SpanBuilder) context.Context {
-	ctx = context.WithValue(ctx, traceBuilderContextKey, trace)
-	ctx = context.WithValue(ctx, spanBuilderContextKey, span)
+	ctx = client.ContextWithTrace(ctx, trace)
+	ctx = client.ContextWithSpan(ctx, span)
 	return ctx
 }
 
@@ -189,7 +189,7 @@ func ContextWithTraceAndSpan(ctx context.Context, trace *client.TraceBuilder, sp
 func StartSpanFromContext(ctx context.Context, name string) (*client.SpanBuilder, context.Context) {
 	if traceBuilder := GetTraceFromContext(ctx); traceBuilder != nil {
 		spanBuilder := traceBuilder.Span(name)
-		newCtx := context.WithValue(ctx, spanBuilderContextKey, spanBuilder)
+		newCtx := client.ContextWithSpan(ctx, spanBuilder)
 		return spanBuilder, newCtx
 	}
 	return nil, ctx
@@ -200,7 +200,7 @@ func StartChildSpanFromContext(ctx context.Context, name string) (*client.SpanBu
 	if parentSpan := GetSpanFromContext(ctx); parentSpan != nil {
 		// Create a child span (this would require extending SpanBuilder to support children)
 		childSpan := parentSpan.ChildSpan(name)
-		newCtx := context.WithValue(ctx, spanBuilderContextKey, childSpan)
+		newCtx := client.ContextWithSpan(ctx, childSpan)
 		return childSpan, newCtx
 	}
 	
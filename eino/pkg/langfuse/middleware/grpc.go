@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"time"
 
@@ -51,6 +50,12 @@ type GRPCInterceptorConfig struct {
 	// CaptureResponse determines whether to capture response message as output
 	// Default: false (can be memory intensive and may contain sensitive data)
 	CaptureResponse bool
+
+	// Propagation names the metadata keys carrying the trace context between services.
+	// gRPC metadata keys are lowercase, so the default keys are "x-trace-id", "x-span-id",
+	// "x-user-id" and "x-session-id".
+	// Default: DefaultContextPropagationConfig()
+	Propagation *ContextPropagationConfig
 }
 
 // DefaultGRPCInterceptorConfig returns a default configuration
@@ -65,10 +70,13 @@ func DefaultGRPCInterceptorConfig(langfuseClient *client.Langfuse) *GRPCIntercep
 		TagExtractor:           defaultGRPCTagExtractor,
 		CaptureRequest:         false,
 		CaptureResponse:        false,
+		Propagation:            DefaultContextPropagationConfig(),
 	}
 }
 
-// UnaryServerInterceptor returns a gRPC unary server interceptor that traces requests
+// UnaryServerInterceptor returns a gRPC unary server interceptor that traces requests.
+// When the caller propagated its trace context, e.g. through UnaryClientInterceptor, the
+// request is recorded as a child span of the caller's span instead of a new trace.
 func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInterceptor {
 	if config == nil {
 		panic("GRPCInterceptorConfig cannot be nil")
@@ -83,6 +91,14 @@ func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInter
 			return handler(ctx, req)
 		}
 
+		// Extract metadata from gRPC context
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		// Continue the caller's trace when its context was propagated
+		if traceCtx := extractGRPCTraceContext(md, config.Propagation); traceCtx.TraceID != "" && config.Client.IsEnabled() {
+			return continueUnaryTrace(ctx, req, info, handler, config, traceCtx)
+		}
+
 		// Start timing
 		startTime := time.Now()
 
@@ -90,9 +106,6 @@ func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInter
 		traceName := config.TraceNameFunc(info.FullMethod)
 		traceBuilder := config.Client.Trace(traceName)
 
-		// Extract metadata from gRPC context
-		md, _ := metadata.FromIncomingContext(ctx)
-
 		// Set trace metadata
 		if metadata := config.MetadataExtractor(ctx, info.FullMethod); metadata != nil {
 			traceBuilder.WithMetadata(metadata)
@@ -130,8 +143,8 @@ func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInter
 			WithStartTime(startTime)
 
 		// Add trace and span to context
-		ctx = context.WithValue(ctx, traceBuilderContextKey, traceBuilder)
-		ctx = context.WithValue(ctx, spanBuilderContextKey, spanBuilder)
+		ctx = client.ContextWithTrace(ctx, traceBuilder)
+		ctx = client.ContextWithSpan(ctx, spanBuilder)
 
 		// Execute the handler
 		resp, err := handler(ctx, req)
@@ -173,7 +186,7 @@ func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInter
 			spanBuilder.WithStatusMessage(statusMessage)
 		}
 
-		if spanErr := spanBuilder.End(); spanErr != nil {
+		if spanErr := spanBuilder.End(ctx); spanErr != nil {
 			// Log error but don't affect the actual RPC
 		}
 
@@ -194,7 +207,7 @@ func UnaryServerInterceptor(config *GRPCInterceptorConfig) grpc.UnaryServerInter
 
 		traceBuilder.WithOutput(traceOutput)
 
-		if traceErr := traceBuilder.End(); traceErr != nil {
+		if traceErr := traceBuilder.End(ctx); traceErr != nil {
 			// Log error but don't affect the actual RPC
 		}
 
@@ -270,8 +283,8 @@ func StreamServerInterceptor(config *GRPCInterceptorConfig) grpc.StreamServerInt
 		}
 
 		// Add trace and span to context
-		ctx = context.WithValue(ctx, traceBuilderContextKey, traceBuilder)
-		ctx = context.WithValue(ctx, spanBuilderContextKey, spanBuilder)
+		ctx = client.ContextWithTrace(ctx, traceBuilder)
+		ctx = client.ContextWithSpan(ctx, spanBuilder)
 		wrappedStream.ctx = ctx
 
 		// Execute the handler
@@ -312,7 +325,7 @@ func StreamServerInterceptor(config *GRPCInterceptorConfig) grpc.StreamServerInt
 			spanBuilder.WithStatusMessage(statusMessage)
 		}
 
-		if spanErr := spanBuilder.End(); spanErr != nil {
+		if spanErr := spanBuilder.End(ctx); spanErr != nil {
 			// Log error but don't affect the actual RPC
 		}
 
@@ -331,7 +344,7 @@ func StreamServerInterceptor(config *GRPCInterceptorConfig) grpc.StreamServerInt
 
 		traceBuilder.WithOutput(traceOutput)
 
-		if traceErr := traceBuilder.End(); traceErr != nil {
+		if traceErr := traceBuilder.End(ctx); traceErr != nil {
 			// Log error but don't affect the actual RPC
 		}
 
@@ -369,7 +382,10 @@ func (s *tracedServerStream) RecvMsg(m interface{}) error {
 	return err
 }
 
-// UnaryClientInterceptor returns a gRPC unary client interceptor that traces outgoing requests
+// UnaryClientInterceptor returns a gRPC unary client interceptor that traces outgoing
+// requests as spans of the trace in the context, or of a new trace if there is none, and
+// propagates the trace and span IDs in the outgoing metadata so that UnaryServerInterceptor
+// continues the trace on the server.
 func UnaryClientInterceptor(config *GRPCInterceptorConfig) grpc.UnaryClientInterceptor {
 	if config == nil {
 		panic("GRPCInterceptorConfig cannot be nil")
@@ -389,11 +405,13 @@ func UnaryClientInterceptor(config *GRPCInterceptorConfig) grpc.UnaryClientInter
 
 		// Create trace (or get from context if already exists)
 		var traceBuilder *client.TraceBuilder
+		ownTrace := false
 		if existingTrace := GetTraceFromContext(ctx); existingTrace != nil {
 			traceBuilder = existingTrace
 		} else {
 			traceName := config.TraceNameFunc(method) + "_client"
 			traceBuilder = config.Client.Trace(traceName)
+			ownTrace = true
 		}
 
 		// Create span for the outgoing gRPC call
@@ -409,12 +427,12 @@ func UnaryClientInterceptor(config *GRPCInterceptorConfig) grpc.UnaryClientInter
 		}
 		spanBuilder.WithMetadata(spanMetadata)
 
-		// Set span tags
+		// Spans have no tags, so they are recorded in the metadata
 		tags := []string{"grpc", "client"}
 		if serviceName := extractServiceName(method); serviceName != "" {
 			tags = append(tags, serviceName)
 		}
-		spanBuilder.WithTags(tags...)
+		spanBuilder.AddMetadata("tags", tags)
 
 		// Capture request input if configured
 		if config.CaptureRequest {
@@ -427,6 +445,14 @@ func UnaryClientInterceptor(config *GRPCInterceptorConfig) grpc.UnaryClientInter
 			})
 		}
 
+		// Propagate the trace context so the server continues this trace under the span
+		ctx = injectGRPCTraceContext(ctx, &TraceContext{
+			TraceID:   traceBuilder.GetID(),
+			SpanID:    spanBuilder.GetID(),
+			UserID:    traceBuilder.GetUserID(),
+			SessionID: traceBuilder.GetSessionID(),
+		}, config.Propagation)
+
 		// Execute the call
 		err := invoker(ctx, method, req, reply, cc, opts...)
 
@@ -471,14 +497,126 @@ func UnaryClientInterceptor(config *GRPCInterceptorConfig) grpc.UnaryClientInter
 			spanBuilder.WithStatusMessage(statusMessage)
 		}
 
-		if spanErr := spanBuilder.End(); spanErr != nil {
+		if spanErr := spanBuilder.End(ctx); spanErr != nil {
 			// Log error but don't affect the actual RPC
 		}
 
+		if ownTrace {
+			traceBuilder.WithOutput(spanOutput)
+			if traceErr := traceBuilder.End(ctx); traceErr != nil {
+				// Log error but don't affect the actual RPC
+			}
+		}
+
 		return err
 	}
 }
 
+// continueUnaryTrace handles a request whose caller propagated its trace context: instead
+// of starting a trace, the request is recorded as a span of the caller's trace, under the
+// caller's span. Handlers find the span, but no trace builder, in the context.
+func continueUnaryTrace(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler, config *GRPCInterceptorConfig, traceCtx *TraceContext) (interface{}, error) {
+	startTime := time.Now()
+
+	spanBuilder := client.NewSpanBuilder(config.Client, traceCtx.TraceID).
+		Name(config.TraceNameFunc(info.FullMethod)).
+		WithStartTime(startTime)
+	if traceCtx.SpanID != "" {
+		spanBuilder.ParentObservationID(traceCtx.SpanID)
+	}
+	if metadata := config.MetadataExtractor(ctx, info.FullMethod); metadata != nil {
+		spanBuilder.WithMetadata(metadata)
+	}
+	if config.CaptureRequest {
+		spanBuilder.WithInput(req)
+	} else {
+		spanBuilder.WithInput(map[string]interface{}{
+			"method":  info.FullMethod,
+			"service": extractServiceName(info.FullMethod),
+			"rpc":     extractRPCName(info.FullMethod),
+		})
+	}
+
+	ctx = client.ContextWithSpan(ctx, spanBuilder)
+	resp, err := handler(ctx, req)
+
+	endTime := time.Now()
+	grpcStatus := status.Code(err)
+	spanOutput := map[string]interface{}{
+		"status_code": grpcStatus.String(),
+		"duration_ms": float64(endTime.Sub(startTime).Nanoseconds()) / 1e6,
+		"success":     err == nil,
+	}
+	if config.CaptureResponse && resp != nil {
+		spanOutput["response"] = resp
+	}
+
+	level := "DEFAULT"
+	if err != nil {
+		if grpcStatus == codes.Canceled || grpcStatus == codes.DeadlineExceeded {
+			level = "WARNING"
+		} else {
+			level = "ERROR"
+		}
+		spanOutput["error"] = err.Error()
+		spanBuilder.WithStatusMessage(status.Convert(err).Message())
+	}
+
+	spanBuilder.
+		WithEndTime(endTime).
+		WithOutput(spanOutput).
+		WithLevel(level)
+
+	if spanErr := spanBuilder.End(ctx); spanErr != nil {
+		// Log error but don't affect the actual RPC
+	}
+
+	return resp, err
+}
+
+// extractGRPCTraceContext reads the propagated trace context from incoming metadata
+func extractGRPCTraceContext(md metadata.MD, config *ContextPropagationConfig) *TraceContext {
+	if config == nil {
+		config = DefaultContextPropagationConfig()
+	}
+
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return &TraceContext{
+		TraceID:   first(config.TraceIDHeader),
+		SpanID:    first(config.SpanIDHeader),
+		UserID:    first(config.UserIDHeader),
+		SessionID: first(config.SessionIDHeader),
+	}
+}
+
+// injectGRPCTraceContext adds the trace context to the outgoing metadata of ctx
+func injectGRPCTraceContext(ctx context.Context, traceCtx *TraceContext, config *ContextPropagationConfig) context.Context {
+	if config == nil {
+		config = DefaultContextPropagationConfig()
+	}
+
+	var kv []string
+	for _, field := range []struct{ key, value string }{
+		{config.TraceIDHeader, traceCtx.TraceID},
+		{config.SpanIDHeader, traceCtx.SpanID},
+		{config.UserIDHeader, traceCtx.UserID},
+		{config.SessionIDHeader, traceCtx.SessionID},
+	} {
+		if field.value != "" {
+			kv = append(kv, field.key, field.value)
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
 // Default extractor functions
 
 func defaultGRPCTraceNameFunc(fullMethod string) string {
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	ingestiontypes "eino/pkg/langfuse/api/resources/ingestion/types"
+	"eino/pkg/langfuse/client"
+	"eino/pkg/langfuse/config"
+)
+
+const checkMethod = "/grpc.health.v1.Health/Check"
+
+// recordingTransport keeps the submitted events in memory
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []ingestiontypes.IngestionEvent
+}
+
+func (r *recordingTransport) SubmitBatch(ctx context.Context, events []ingestiontypes.IngestionEvent) (*ingestiontypes.IngestionResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, events...)
+	return &ingestiontypes.IngestionResponse{Success: true, Timestamp: time.Now()}, nil
+}
+
+// bodies flushes lf and returns the recorded event bodies by ID, the fields of later
+// events overriding earlier ones
+func (r *recordingTransport) bodies(t *testing.T, lf *client.Langfuse) map[string]map[string]interface{} {
+	t.Helper()
+	require.NoError(t, lf.Flush(context.Background()))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bodies := make(map[string]map[string]interface{})
+	for _, event := range r.events {
+		var body map[string]interface{}
+		require.NoError(t, event.DecodeBody(&body))
+		id := body["id"].(string)
+		if bodies[id] == nil {
+			bodies[id] = make(map[string]interface{})
+		}
+		for k, v := range body {
+			bodies[id][k] = v
+		}
+	}
+	return bodies
+}
+
+func newRecordingLangfuse(t *testing.T) (*client.Langfuse, *recordingTransport) {
+	t.Helper()
+
+	transport := &recordingTransport{}
+	cfg := config.DefaultConfig()
+	cfg.Host = "http://localhost"
+	cfg.PublicKey = "pk-test"
+	cfg.SecretKey = "sk-test"
+	cfg.SkipInitialHealthCheck = true
+	cfg.FlushInterval = time.Hour
+	cfg.IngestionTransport = transport
+
+	lf, err := client.New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { lf.Shutdown(context.Background()) })
+	return lf, transport
+}
+
+// healthServer records the trace context its handler sees
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	spanID  string
+	traceID string
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.spanID = GetSpanIDFromContext(ctx)
+	s.traceID = GetTraceIDFromContext(ctx)
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// startHealthServer serves health checks in process through the server interceptor and
+// returns a connection, using the client interceptor when withClientInterceptor is set
+func startHealthServer(t *testing.T, lf *client.Langfuse, withClientInterceptor bool) (*healthServer, *grpc.ClientConn) {
+	t.Helper()
+
+	interceptorConfig := DefaultGRPCInterceptorConfig(lf)
+	// Health checks are not traced by default
+	interceptorConfig.ShouldTraceFunc = func(string) bool { return true }
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(interceptorConfig)))
+	health := &healthServer{}
+	healthpb.RegisterHealthServer(server, health)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	opts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	if withClientInterceptor {
+		opts = append(opts, grpc.WithUnaryInterceptor(UnaryClientInterceptor(interceptorConfig)))
+	}
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return health, conn
+}
+
+// findByName returns the body named name
+func findByName(t *testing.T, bodies map[string]map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+	for _, body := range bodies {
+		if body["name"] == name {
+			return body
+		}
+	}
+	require.Failf(t, "no event body named %s", name)
+	return nil
+}
+
+func TestGRPCInterceptors_PropagateTraceToServerSpan(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	health, conn := startHealthServer(t, lf, true)
+
+	trace := lf.Trace("caller")
+	ctx := ContextWithTrace(context.Background(), trace)
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.NoError(t, trace.End(context.Background()))
+
+	bodies := transport.bodies(t, lf)
+	clientSpan := findByName(t, bodies, "grpc_client")
+	serverSpan := findByName(t, bodies, checkMethod)
+
+	assert.Equal(t, trace.GetID(), clientSpan["traceId"])
+	assert.Equal(t, trace.GetID(), serverSpan["traceId"], "the server continues the caller's trace")
+	assert.Equal(t, clientSpan["id"], serverSpan["parentObservationId"])
+	assert.Equal(t, serverSpan["id"], health.spanID, "the handler sees the server span")
+	assert.Empty(t, health.traceID, "the caller's trace builder stays on the caller's side")
+
+	assert.Len(t, bodies, 3, "no trace is created on the server")
+}
+
+func TestGRPCInterceptors_ClientStartsTrace(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	_, conn := startHealthServer(t, lf, true)
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	bodies := transport.bodies(t, lf)
+	clientTrace := findByName(t, bodies, checkMethod+"_client")
+	serverSpan := findByName(t, bodies, checkMethod)
+
+	assert.Equal(t, clientTrace["id"], serverSpan["traceId"])
+	assert.Equal(t, findByName(t, bodies, "grpc_client")["id"], serverSpan["parentObservationId"])
+	assert.NotNil(t, clientTrace["output"], "the client's own trace is ended")
+}
+
+func TestGRPCServerInterceptor_StartsTraceWithoutPropagation(t *testing.T) {
+	lf, transport := newRecordingLangfuse(t)
+	health, conn := startHealthServer(t, lf, false)
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	bodies := transport.bodies(t, lf)
+	serverTrace := findByName(t, bodies, checkMethod)
+	serverSpan := findByName(t, bodies, "grpc_unary")
+
+	assert.Equal(t, serverTrace["id"], health.traceID)
+	assert.Equal(t, serverTrace["id"], serverSpan["traceId"])
+	assert.Nil(t, serverSpan["parentObservationId"])
+}
+
+func TestGRPCTraceContext_RoundTrip(t *testing.T) {
+	traceCtx := &TraceContext{TraceID: "trace-1", SpanID: "span-1", UserID: "user-1"}
+
+	ctx := injectGRPCTraceContext(context.Background(), traceCtx, nil)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []string{"trace-1"}, md.Get("x-trace-id"))
+	assert.Empty(t, md.Get("x-session-id"), "empty values are not sent")
+
+	assert.Equal(t, traceCtx, extractGRPCTraceContext(md, nil))
+	_, ok = metadata.FromOutgoingContext(injectGRPCTraceContext(context.Background(), &TraceContext{}, nil))
+	assert.False(t, ok, "nothing is sent without a trace context")
+}
@@ -3,7 +3,6 @@ package middleware
 import (
 	"context"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -68,14 +67,6 @@ func DefaultHTTPMiddlewareConfig(langfuseClient *client.Langfuse) *HTTPMiddlewar
 	}
 }
 
-// contextKey is used for storing trace information in request context
-type contextKey string
-
-const (
-	traceBuilderContextKey contextKey = "langfuse_trace_builder"
-	spanBuilderContextKey  contextKey = "langfuse_span_builder"
-)
-
 // responseWriter wraps http.ResponseWriter to capture response details
 type responseWriter struct {
 	http.ResponseWriter
@@ -164,8 +155,8 @@ func HTTPMiddleware(config *HTTPMiddlewareConfig) func(http.Handler) http.Handle
 				WithStartTime(startTime)
 
 			// Add trace and span to context
-			ctx := context.WithValue(r.Context(), traceBuilderContextKey, traceBuilder)
-			ctx = context.WithValue(ctx, spanBuilderContextKey, spanBuilder)
+			ctx := client.ContextWithTrace(r.Context(), traceBuilder)
+			ctx = client.ContextWithSpan(ctx, spanBuilder)
 			r = r.WithContext(ctx)
 
 			// Wrap response writer to capture response details
@@ -239,18 +230,12 @@ func HTTPMiddleware(config *HTTPMiddlewareConfig) func(http.Handler) http.Handle
 
 // GetTraceFromContext retrieves the current trace builder from request context
 func GetTraceFromContext(ctx context.Context) *client.TraceBuilder {
-	if trace, ok := ctx.Value(traceBuilderContextKey).(*client.TraceBuilder); ok {
-		return trace
-	}
-	return nil
+	return client.TraceFromContext(ctx)
 }
 
 // GetSpanFromContext retrieves the current span builder from request context
 func GetSpanFromContext(ctx context.Context) *client.SpanBuilder {
-	if span, ok := ctx.Value(spanBuilderContextKey).(*client.SpanBuilder); ok {
-		return span
-	}
-	return nil
+	return client.SpanFromContext(ctx)
 }
 
 // Default extractor functions
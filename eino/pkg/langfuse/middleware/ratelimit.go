@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"eino/pkg/langfuse/client"
+)
+
+// RateLimitedLangfuse wraps lf so that its Trace, Span and Generation calls create
+// at most rps builders per second, protecting the ingestion queue when many request
+// handlers trace concurrently. Calls over the limit get a no-op builder and are counted
+// in ClientStats.RateLimitedEvents. See client.Langfuse.WithRateLimit.
+func RateLimitedLangfuse(lf *client.Langfuse, rps float64) *client.Langfuse {
+	return lf.WithRateLimit(rps)
+}
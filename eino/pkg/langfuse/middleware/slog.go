@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHandler is a slog.Handler that adds the trace_id and observation_id of the trace
+// and span in the context of each record, so log lines written with the request context
+// (logger.InfoContext(ctx, ...)) can be correlated with the trace. Records logged without
+// a trace in their context are passed through unchanged.
+//
+// Like any attribute added when handling a record, the IDs are nested under the groups
+// opened with WithGroup.
+//
+// Example:
+//
+//	logger := slog.New(middleware.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+type LogHandler struct {
+	next slog.Handler
+}
+
+// NewLogHandler wraps next so that records get the trace attributes of their context
+func NewLogHandler(next slog.Handler) *LogHandler {
+	return &LogHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the trace attributes of ctx to the record and passes it on
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := LogAttrsFromContext(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a LogHandler wrapping the wrapped handler with attrs
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a LogHandler wrapping the wrapped handler with the group
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{next: h.next.WithGroup(name)}
+}
+
+// LogAttrsFromContext returns the log attributes of the current span in the context, or
+// of the trace when there is no span, or nil without either
+func LogAttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	if span := GetSpanFromContext(ctx); span != nil {
+		return span.LogAttrs()
+	}
+	if trace := GetTraceFromContext(ctx); trace != nil {
+		return trace.LogAttrs()
+	}
+	return nil
+}
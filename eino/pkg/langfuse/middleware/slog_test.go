@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLine logs msg with ctx through a LogHandler and returns the decoded JSON line
+func logLine(t *testing.T, ctx context.Context, configure func(*slog.Logger) *slog.Logger) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+	if configure != nil {
+		logger = configure(logger)
+	}
+	logger.InfoContext(ctx, "handled")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	return line
+}
+
+func TestLogHandler_InjectsTraceFromContext(t *testing.T) {
+	lf, _ := newRecordingLangfuse(t)
+	trace := lf.Trace("request")
+	span := trace.Span("handler")
+
+	line := logLine(t, ContextWithTrace(context.Background(), trace), nil)
+	assert.Equal(t, trace.GetID(), line["trace_id"])
+	assert.NotContains(t, line, "observation_id")
+
+	line = logLine(t, ContextWithTraceAndSpan(context.Background(), trace, span), func(logger *slog.Logger) *slog.Logger {
+		return logger.With("component", "api")
+	})
+	assert.Equal(t, trace.GetID(), line["trace_id"])
+	assert.Equal(t, span.GetID(), line["observation_id"])
+	assert.Equal(t, "api", line["component"])
+}
+
+func TestLogHandler_WithoutTrace(t *testing.T) {
+	line := logLine(t, context.Background(), nil)
+	assert.NotContains(t, line, "trace_id")
+	assert.Equal(t, "handled", line["msg"])
+	assert.Nil(t, LogAttrsFromContext(context.Background()))
+}